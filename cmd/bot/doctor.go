@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/config"
+	"github.com/cuichanghe/daily-reminder-bot/internal/doctor"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/openai"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// checkTimeout limits each dependency check
+const checkTimeout = 15 * time.Second
+
+// runDoctor checks every dependency, prints the report to stdout and returns the exit code:
+// 0 when all checks pass, 1 otherwise
+func runDoctor(cfg *config.Config) int {
+	var checks []doctor.Check
+
+	checks = append(checks, telegramChecks(&cfg.Telegram)...)
+	if cfg.Telegram.LocalFiles.Dir != "" {
+		checks = append(checks, localFilesCheck(&cfg.Telegram))
+	}
+
+	if client, err := initQWeatherClient(&cfg.QWeather); err != nil {
+		checks = append(checks, doctor.Failed("QWeather", false, err))
+	} else {
+		checks = append(checks, doctor.QWeather(client))
+	}
+
+	if cfg.OpenAI.Enabled {
+		checks = append(checks, doctor.OpenAI(newOpenAIClient(&cfg.OpenAI)))
+	} else {
+		checks = append(checks, doctor.Skipped("OpenAI", "openai.enabled is false"))
+	}
+
+	if db, err := initDatabase(&cfg.Database); err != nil {
+		checks = append(checks, doctor.Failed("Database", true, err))
+	} else {
+		checks = append(checks, doctor.Database(db))
+	}
+
+	checks = append(checks, doctor.Timezone(cfg.Scheduler.Timezone))
+
+	results := doctor.Run(context.Background(), checks, checkTimeout)
+	doctor.Print(os.Stdout, results)
+	if required, optional := doctor.Failures(results); required+optional > 0 {
+		return 1
+	}
+	return 0
+}
+
+// telegramChecks checks the primary bot token and those of the additional bots
+func telegramChecks(cfg *config.TelegramConfig) []doctor.Check {
+	checks := []doctor.Check{doctor.Telegram(cfg.Token, telegramEndpoints(cfg))}
+	for _, b := range cfg.Bots {
+		check := doctor.Telegram(b.Token, extraBotEndpoints(cfg, b))
+		check.Name += " (" + b.Name + ")"
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// localFilesCheck checks that files can be sent through the directory shared with a local Bot API
+// server; when they cannot, the bot uploads files instead
+func localFilesCheck(cfg *config.TelegramConfig) doctor.Check {
+	return doctor.Check{Name: "Local Bot API files", Run: func(ctx context.Context) (string, error) {
+		if !selfHostedEndpoints(telegramEndpoints(cfg)) {
+			return "", fmt.Errorf("telegram.local_files requires a self-hosted Bot API server")
+		}
+		files, err := notify.NewLocalFiles(cfg.LocalFiles.Dir, cfg.LocalFiles.ServerDir)
+		if err != nil {
+			return "", err
+		}
+		if err := files.Check(); err != nil {
+			return "", err
+		}
+		return cfg.LocalFiles.Dir + " writable", nil
+	}}
+}
+
+// runStartupChecks runs the doctor checks at startup, exiting when a required check fails
+func runStartupChecks(cfg *config.Config, db *gorm.DB, qweatherClient *qweather.Client, openaiClient *openai.Client) {
+	checks := telegramChecks(&cfg.Telegram)
+	checks = append(checks, doctor.QWeather(qweatherClient))
+	if openaiClient != nil {
+		checks = append(checks, doctor.OpenAI(openaiClient))
+	} else {
+		checks = append(checks, doctor.Skipped("OpenAI", "openai.enabled is false"))
+	}
+	checks = append(checks, doctor.Database(db), doctor.Timezone(cfg.Scheduler.Timezone))
+
+	results := doctor.Run(context.Background(), checks, checkTimeout)
+	doctor.Log(results)
+	if required, _ := doctor.Failures(results); required > 0 {
+		logger.Fatal("Startup self-check failed; run the doctor command for a full report", zap.Int("failed", required))
+	}
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"os/signal"
+	"regexp"
+	"strings"
 	"syscall"
 	"time"
 
@@ -12,12 +16,23 @@ import (
 	"github.com/cuichanghe/daily-reminder-bot/internal/config"
 	"github.com/cuichanghe/daily-reminder-bot/internal/migration"
 	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
 	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/internal/server"
 	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/advice"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/feed"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/fieldcrypt"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/holiday"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/horoscope"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/ics"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/imagery"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/openai"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/rates"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/sdnotify"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/tts"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/sqlite"
@@ -27,7 +42,17 @@ import (
 func main() {
 	// Parse command-line flags
 	configPath := flag.String("config", "configs/config.yaml", "Path to configuration file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-config path] [doctor]\n\n", os.Args[0])
+		fmt.Fprintln(flag.CommandLine.Output(), "  doctor\tcheck Telegram, QWeather, OpenAI, the database and timezone data, then exit")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
+	command := flag.Arg(0)
+	if flag.NArg() > 1 || (command != "" && command != "doctor") {
+		flag.Usage()
+		os.Exit(2)
+	}
 
 	// Load configuration
 	cfg, err := config.Load(*configPath)
@@ -45,36 +70,46 @@ func main() {
 		}
 	}()
 
+	if command == "doctor" {
+		os.Exit(runDoctor(cfg))
+	}
+
 	// Initialize database
 	db, err := initDatabase(&cfg.Database)
 	if err != nil {
 		logger.Fatal("Failed to initialize database", zap.Error(err))
 	}
 
+	// Encrypt sensitive columns at rest, converting rows written before the setting changed
+	fieldCipher, err := initFieldCipher(&cfg.Encryption)
+	if err != nil {
+		logger.Fatal("Failed to initialize encryption", zap.Error(err))
+	}
+	if fieldCipher != nil {
+		model.SetFieldCipher(fieldCipher, cfg.Encryption.Todos)
+		if err := migration.SyncFieldEncryption(db, cfg.Encryption.Todos); err != nil {
+			logger.Fatal("Failed to migrate encrypted columns", zap.Error(err))
+		}
+		logger.Info("Field encryption configured", zap.Bool("todos", cfg.Encryption.Todos))
+	} else if cfg.Encryption.Todos {
+		logger.Fatal("encryption.todos requires encryption.key (generate one with `openssl rand -base64 32`)")
+	}
+
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
 	subRepo := repository.NewSubscriptionRepository(db)
 	todoRepo := repository.NewTodoRepository(db)
 	warningRepo := repository.NewWarningLogRepository(db)
+	warningMuteRepo := repository.NewWarningMuteRepository(db)
+	deliveryRepo := repository.NewDeliveryLogRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	channelRepo := repository.NewNotificationChannelRepository(db)
+	announcementRepo := repository.NewAnnouncementRepository(db)
 
 	// Initialize QWeather client
-	var qweatherClient *qweather.Client
-	switch cfg.QWeather.AuthMode {
-	case "jwt":
-		qweatherClient, err = qweather.NewClientWithJWT(
-			cfg.QWeather.PrivateKeyPath,
-			cfg.QWeather.KeyID,
-			cfg.QWeather.ProjectID,
-			cfg.QWeather.BaseURL,
-		)
-		if err != nil {
-			logger.Fatal("Failed to create QWeather JWT client", zap.Error(err))
-		}
-		logger.Info("QWeather client initialized with JWT authentication")
-	default:
-		// Default to API Key mode for backward compatibility
-		qweatherClient = qweather.NewClient(cfg.QWeather.APIKey, cfg.QWeather.BaseURL)
-		logger.Info("QWeather client initialized with API Key authentication")
+	qweatherClient, err := initQWeatherClient(&cfg.QWeather)
+	if err != nil {
+		logger.Fatal("Failed to create QWeather JWT client", zap.Error(err))
 	}
 
 	// Initialize services
@@ -84,71 +119,428 @@ func main() {
 
 	// Initialize AI service
 	var aiSvc *service.AIService
+	var openaiClient *openai.Client
+	var aiProbe *openai.HealthProbe
 	if cfg.OpenAI.Enabled {
-		openaiClient := openai.NewClient(
-			cfg.OpenAI.APIKey,
-			cfg.OpenAI.BaseURL,
-			cfg.OpenAI.Model,
-			cfg.OpenAI.MaxTokens,
-			cfg.OpenAI.Temperature,
-			time.Duration(cfg.OpenAI.Timeout)*time.Second,
-		)
-		aiSvc = service.NewAIService(openaiClient, cfg.OpenAI.MaxRetries, true)
+		openaiClient = newOpenAIClient(&cfg.OpenAI)
+		aiCacheTTL := time.Duration(cfg.OpenAI.CacheMinutes) * time.Minute
+		if cfg.OpenAI.CacheMinutes == 0 {
+			aiCacheTTL = 10 * time.Minute
+		}
+		aiRegenQuota := cfg.OpenAI.RegenQuota
+		if aiRegenQuota == 0 {
+			aiRegenQuota = 3
+		} else if aiRegenQuota < 0 {
+			aiRegenQuota = 0
+		}
+		aiSvc = service.NewAIService(openaiClient, cfg.OpenAI.MaxRetries, true, cfg.OpenAI.TodoPlanning, cfg.OpenAI.LiteModel, cfg.OpenAI.OptIn, aiCacheTTL, aiRegenQuota)
+		aiSvc.SetPromptBudget(cfg.OpenAI.PromptBudget)
+		if isLocalOpenAIProvider(cfg.OpenAI.Provider) {
+			// Local servers go down with the machine they run on: skip requests while it is unreachable
+			probeInterval := time.Duration(cfg.OpenAI.ProbeSeconds) * time.Second
+			if probeInterval <= 0 {
+				probeInterval = 30 * time.Second
+			}
+			aiProbe = openai.NewHealthProbe(openaiClient, probeInterval)
+			aiSvc.SetHealthProbe(aiProbe)
+		}
 		logger.Info("AI service initialized",
+			zap.String("provider", cfg.OpenAI.Provider),
 			zap.String("model", cfg.OpenAI.Model),
+			zap.String("lite_model", cfg.OpenAI.LiteModel),
+			zap.Bool("opt_in", cfg.OpenAI.OptIn),
+			zap.Duration("cache_ttl", aiCacheTTL),
+			zap.Int("regenerate_quota", aiRegenQuota),
+			zap.Int("context_window", openaiClient.ContextWindow()),
+			zap.Int("prompt_budget", cfg.OpenAI.PromptBudget),
 			zap.String("base_url", cfg.OpenAI.BaseURL))
 	} else {
-		aiSvc = service.NewAIService(nil, 0, false)
+		aiSvc = service.NewAIService(nil, 0, false, false, "", false, 0, 0)
 		logger.Info("AI service disabled")
 	}
 
+	// Check the dependencies before going any further
+	runStartupChecks(cfg, db, qweatherClient, openaiClient)
+	if aiProbe != nil {
+		aiProbe.Start()
+		defer aiProbe.Stop()
+	}
+
 	// Initialize Holiday client and Calendar service
 	loc, err := time.LoadLocation(cfg.Scheduler.Timezone)
 	if err != nil {
 		logger.Fatal("Failed to load timezone", zap.Error(err))
 	}
+	airSvc.SetTimezone(loc)
+
+	// Store raw QWeather responses for the yesterday comparison, offline re-rendering and debugging
+	var snapshotSvc *service.SnapshotService
+	if cfg.QWeather.SnapshotDays > 0 {
+		snapshotSvc = service.NewSnapshotService(repository.NewAPISnapshotRepository(db), cfg.QWeather.SnapshotDays, loc)
+		qweatherClient.SetRecorder(snapshotSvc)
+		logger.Info("QWeather response snapshots enabled", zap.Int("retention_days", cfg.QWeather.SnapshotDays))
+	}
 
 	var holidayClient *holiday.Client
+	var holidayProviders []holiday.Provider
 	if cfg.Holiday.APIURL != "" {
+		holidayProviders = append(holidayProviders, holiday.NewTimorProvider(cfg.Holiday.APIURL))
+	}
+	if cfg.Holiday.DatasetURL != "" {
+		holidayProviders = append(holidayProviders, holiday.NewDatasetProvider(cfg.Holiday.DatasetURL))
+	}
+	if len(holidayProviders) > 0 {
 		cacheTTL := time.Duration(cfg.Holiday.CacheTTL) * time.Second
 		if cacheTTL == 0 {
 			cacheTTL = 24 * time.Hour
 		}
-		holidayClient = holiday.NewClient(cfg.Holiday.APIURL, cacheTTL)
-		logger.Info("Holiday API client initialized", zap.String("api_url", cfg.Holiday.APIURL))
+		holidayClient = holiday.NewChain(cacheTTL, holidayProviders...)
+		logger.Info("Holiday API client initialized",
+			zap.String("api_url", cfg.Holiday.APIURL),
+			zap.String("dataset_url", cfg.Holiday.DatasetURL))
 	} else {
 		logger.Info("Holiday API not configured, using built-in festival data only")
 	}
 
 	calendarSvc := service.NewCalendarService(loc, holidayClient)
+	customFestivalRepo := repository.NewCustomFestivalRepository(db)
+	calendarSvc.SetCustomFestivals(customFestivalRepo)
+
+	// Initialize webhook service
+	var webhookSvc *service.WebhookService
+	if cfg.Webhook.Enabled {
+		webhookSvc = initWebhookService(&cfg.Webhook, webhookRepo)
+	} else {
+		logger.Info("Webhooks disabled")
+	}
+
+	// Initialize MQTT publisher
+	var mqttSvc *service.MQTTService
+	if cfg.MQTT.Enabled {
+		mqttSvc, err = initMQTTService(&cfg.MQTT, subRepo, weatherSvc)
+		if err != nil {
+			logger.Fatal("Failed to initialize MQTT", zap.Error(err))
+		}
+	} else {
+		logger.Info("MQTT disabled")
+	}
 
 	// Initialize bot
-	teleBot, err := bot.NewBot(cfg.Telegram.Token, cfg.Telegram.APIEndpoint)
+	teleBot, err := bot.NewBot(cfg.Telegram.Token, telegramEndpoints(&cfg.Telegram), telegramProbeInterval(&cfg.Telegram))
 	if err != nil {
 		logger.Fatal("Failed to create bot", zap.Error(err))
 	}
+	extraBots, err := initExtraBots(&cfg.Telegram)
+	if err != nil {
+		logger.Fatal("Failed to create additional bots", zap.Error(err))
+	}
+
+	// Initialize notification service (Telegram plus operator-enabled channels and broadcast targets)
+	notifyRouter := initNotifyRouter(&cfg.Notify)
+	telegramNotifier := notify.NewTelegramNotifier(teleBot.Bot)
+	for name, b := range extraBots {
+		telegramNotifier.AddBot(name, b.Bot)
+	}
+	if localFiles := initLocalFiles(&cfg.Telegram); localFiles != nil {
+		telegramNotifier.SetLocalFiles(localFiles)
+		// Additional bots on api.telegram.org keep uploading files
+		for _, c := range cfg.Telegram.Bots {
+			if selfHostedEndpoints(extraBotEndpoints(&cfg.Telegram, c)) {
+				telegramNotifier.For(c.Name).SetLocalFiles(localFiles)
+			}
+		}
+	}
+	var voiceSvc *service.VoiceService
+	if cfg.TTS.Enabled {
+		voiceSvc, err = initVoiceService(&cfg.TTS, &cfg.OpenAI, telegramNotifier)
+		if err != nil {
+			logger.Fatal("Failed to initialize TTS", zap.Error(err))
+		}
+	} else {
+		logger.Info("Voice reminders disabled")
+	}
+	notifySvc := service.NewNotificationService(
+		telegramNotifier,
+		notifyRouter,
+		channelRepo,
+		initBroadcastTargets(cfg.Notify.Broadcasts, notifyRouter),
+		voiceSvc,
+	)
+	deliveryConcurrency := cfg.Notify.Concurrency
+	if deliveryConcurrency == 0 {
+		deliveryConcurrency = 8
+	}
+	notifySvc.SetConcurrency(deliveryConcurrency)
+
+	// Initialize warning service (needs notification service for pushes)
+	warningCatalog := service.NewWarningCatalog(qweatherClient, repository.NewWarningTypeRepository(db))
+	warningSvc := service.NewWarningService(qweatherClient, warningRepo, subRepo, warningMuteRepo, warningCatalog, webhookSvc, notifySvc, mqttSvc)
+
+	// Record business events for analytics, separate from the logs
+	var eventLog *service.EventLogService
+	if cfg.Events.Enabled {
+		eventLog, err = initEventLog(&cfg.Events, db)
+		if err != nil {
+			logger.Fatal("Failed to initialize event log", zap.Error(err))
+		}
+		defer func() {
+			if err := eventLog.Close(); err != nil {
+				logger.Warn("Failed to close event log", zap.Error(err))
+			}
+		}()
+		warningSvc.SetEventLog(eventLog)
+	}
+
+	// Initialize weather-matched images for reminders
+	var images imagery.Provider
+	if cfg.Image.Enabled {
+		images, err = initImageProvider(&cfg.Image)
+		if err != nil {
+			logger.Fatal("Failed to initialize reminder images", zap.Error(err))
+		}
+	} else {
+		logger.Info("Reminder images disabled")
+	}
+
+	// Initialize reading todos from photos
+	var ocrSvc *service.OCRService
+	if cfg.OCR.Enabled {
+		ocrSvc, err = initOCRService(&cfg.OCR, &cfg.OpenAI)
+		if err != nil {
+			logger.Fatal("Failed to initialize photo OCR", zap.Error(err))
+		}
+	} else {
+		logger.Info("Photo OCR disabled")
+	}
+
+	// Initialize scheduler (rendered city digests are cached for the RSS feeds)
+	digestCache := service.NewDigestCache()
+	announcementSvc := service.NewAnnouncementService(announcementRepo, userRepo, telegramNotifier)
+	todoStatsSvc := service.NewTodoStatsService(todoRepo, repository.NewTodoStatsRepository(db), subRepo, loc)
+	indexWatchSvc := service.NewIndexWatchService(repository.NewIndexWatchRepository(db), notifySvc)
+	preAlertSvc := service.NewPreAlertService(qweatherClient, repository.NewPreAlertLogRepository(db), subRepo, warningSvc, notifySvc)
+	adviceSvc := service.NewAdviceService(advice.NewEngine(advice.DefaultRules()...), repository.NewSeasonalEventRepository(db), loc)
+	experimentSvc := service.NewExperimentService(repository.NewExperimentRepository(db), repository.NewFeedbackRepository(db))
+
+	// Private health reminders, whose plans are encrypted at rest
+	var healthSvc *service.HealthReminderService
+	if cfg.Health.Enabled {
+		healthSvc, err = initHealthReminderService(&cfg.Health, fieldCipher, db, telegramNotifier)
+		if err != nil {
+			logger.Fatal("Failed to initialize health reminders", zap.Error(err))
+		}
+	} else {
+		logger.Info("Health reminders disabled")
+	}
 
-	// Initialize warning service (needs bot for notifications)
-	warningSvc := service.NewWarningService(qweatherClient, warningRepo, subRepo, teleBot.Bot)
+	// Learn the tone of AI reminders from the users' 👍/👎 votes
+	var toneSvc *service.ToneService
+	if aiSvc.IsEnabled() {
+		toneSvc = service.NewToneService(repository.NewReminderVoteRepository(db), userRepo)
+	}
+
+	// Close daily reminders with a weather or solar term trivia
+	var triviaSvc *service.TriviaService
+	if cfg.Trivia.Enabled {
+		triviaSvc, err = initTriviaService(&cfg.Trivia, aiSvc, loc)
+		if err != nil {
+			logger.Fatal("Failed to initialize trivia", zap.Error(err))
+		}
+	} else {
+		logger.Info("Trivia disabled")
+	}
+
+	// Crowd-sourced weather observations, shown in daily reminders of the same city
+	var obsSvc *service.ObservationService
+	if cfg.Observations.Enabled {
+		obsSvc = initObservationService(&cfg.Observations, db, userRepo)
+	} else {
+		logger.Info("Observations disabled")
+	}
+
+	// Scheduled job runs, shown by /jobs and the admin API
+	jobSvc := service.NewJobService(repository.NewJobRunRepository(db))
 
-	// Initialize scheduler
 	schedulerSvc, err := service.NewSchedulerService(
 		subRepo,
+		deliveryRepo,
 		weatherSvc,
 		todoSvc,
+		todoStatsSvc,
 		aiSvc,
 		calendarSvc,
 		warningSvc,
-		teleBot.Bot,
+		webhookSvc,
+		notifySvc,
+		digestCache,
+		announcementSvc,
+		indexWatchSvc,
+		snapshotSvc,
+		images,
+		preAlertSvc,
+		adviceSvc,
+		experimentSvc,
+		healthSvc,
+		toneSvc,
+		triviaSvc,
+		obsSvc,
+		jobSvc,
 		cfg.Scheduler.Timezone,
 	)
 	if err != nil {
 		logger.Fatal("Failed to create scheduler", zap.Error(err))
 	}
+	schedulerSvc.SetReminderSpread(reminderSpread(cfg.Scheduler.Spread))
+	schedulerSvc.SetEventLog(eventLog)
+
+	if obsSvc != nil {
+		if err := schedulerSvc.Sections().Register(obsSvc); err != nil {
+			logger.Fatal("Failed to register observations section", zap.Error(err))
+		}
+	}
+
+	// Add the news headlines section to daily reminders
+	var newsSvc *service.NewsService
+	if cfg.News.Enabled {
+		newsSvc = initNewsService(&cfg.News, aiSvc)
+		if err := schedulerSvc.Sections().Register(newsSvc); err != nil {
+			logger.Fatal("Failed to register news section", zap.Error(err))
+		}
+	} else {
+		logger.Info("News section disabled")
+	}
+
+	// Add the exchange rate and metal price section to daily reminders
+	var ratesSvc *service.RatesService
+	if cfg.Rates.Enabled {
+		ratesSvc, err = initRatesService(&cfg.Rates)
+		if err != nil {
+			logger.Fatal("Failed to initialize rates section", zap.Error(err))
+		}
+		if err := schedulerSvc.Sections().Register(ratesSvc); err != nil {
+			logger.Fatal("Failed to register rates section", zap.Error(err))
+		}
+	} else {
+		logger.Info("Rates section disabled")
+	}
+
+	// Add the opt-in horoscope section to daily reminders
+	var horoscopeSvc *service.HoroscopeService
+	if cfg.Horoscope.Enabled {
+		horoscopeSvc, err = initHoroscopeService(&cfg.Horoscope, aiSvc)
+		if err != nil {
+			logger.Fatal("Failed to initialize horoscope section", zap.Error(err))
+		}
+		if err := schedulerSvc.Sections().Register(horoscopeSvc); err != nil {
+			logger.Fatal("Failed to register horoscope section", zap.Error(err))
+		}
+	} else {
+		logger.Info("Horoscope section disabled")
+	}
+
+	// Add the agenda of the users' iCalendar feeds to daily reminders
+	var calendarFeedSvc *service.CalendarFeedService
+	if cfg.ICS.Enabled {
+		calendarFeedSvc = initCalendarFeedService(&cfg.ICS, db, loc)
+		if err := schedulerSvc.Sections().Register(calendarFeedSvc); err != nil {
+			logger.Fatal("Failed to register agenda section", zap.Error(err))
+		}
+		schedulerSvc.SetCalendarFeeds(calendarFeedSvc)
+	} else {
+		logger.Info("Calendar feeds disabled")
+	}
+
+	// Add the care tip of the day's solar term to daily reminders
+	var solarTermSvc *service.SolarTermService
+	if cfg.JieQi.Enabled {
+		solarTermSvc, err = initSolarTermService(&cfg.JieQi, aiSvc, loc)
+		if err != nil {
+			logger.Fatal("Failed to initialize solar term tips", zap.Error(err))
+		}
+		if err := schedulerSvc.Sections().Register(solarTermSvc); err != nil {
+			logger.Fatal("Failed to register solar term section", zap.Error(err))
+		}
+	} else {
+		logger.Info("Solar term tips disabled")
+	}
+
+	// Water and stretch reminders, scheduled on their own cron from user settings
+	var intervalSvc *service.IntervalReminderService
+	if cfg.Interval.Enabled {
+		intervalSvc = service.NewIntervalReminderService(repository.NewIntervalReminderRepository(db), calendarSvc, telegramNotifier, loc)
+	} else {
+		logger.Info("Interval reminders disabled")
+	}
 
 	// Register handlers
-	handlers := bot.NewHandlers(userRepo, subRepo, todoRepo, weatherSvc, todoSvc, airSvc, warningSvc)
-	handlers.RegisterHandlers(teleBot.Bot)
+	var userWebhookSvc *service.WebhookService
+	if cfg.Webhook.UserWebhooks {
+		userWebhookSvc = webhookSvc
+	}
+	maxWebhooksPerUser := cfg.Webhook.MaxPerUser
+	if maxWebhooksPerUser == 0 {
+		maxWebhooksPerUser = 3
+	}
+	maxChannels := cfg.Notify.MaxChannels
+	if maxChannels == 0 {
+		maxChannels = 3
+	}
+	// Subscription transfers between chats, signed with the primary bot token
+	botUsernames := map[string]string{"": teleBot.Me.Username}
+	for name, b := range extraBots {
+		botUsernames[name] = b.Me.Username
+	}
+	transferSvc := service.NewTransferService(subRepo, userRepo, todoRepo, repository.NewUsedTransferTokenRepository(db), cfg.Telegram.Token, botUsernames)
+
+	tierSvc := initTierService(&cfg.Tiers, aiSvc.RegenerateQuota(), userRepo)
+	paymentRepo := repository.NewPaymentRepository(db)
+	auditSvc := service.NewAuditService(repository.NewAuditLogRepository(db))
+	roleSvc := initRoleService(&cfg.Telegram, cfg.Observations.Moderators, db)
+	var paymentSvc *service.PaymentService
+	if cfg.Payments.Enabled {
+		paymentSvc = initPaymentService(&cfg.Payments, paymentRepo)
+	} else {
+		logger.Info("Payments disabled")
+	}
+	var dashboardSvc *service.DashboardService
+	if cfg.Server.Dashboard.Enabled {
+		dashboardSvc = initDashboardService(&cfg.Server.Dashboard, db, userRepo, subRepo, todoSvc, deliveryRepo)
+	}
+	var miniAppSvc *service.MiniAppService
+	if cfg.Server.MiniApp.Enabled {
+		miniAppSvc = initMiniAppService(&cfg.Server.MiniApp, &cfg.Telegram, userRepo, subRepo, todoRepo, todoSvc)
+	}
+
+	handlers := bot.NewHandlers(userRepo, subRepo, todoRepo, webhookRepo, channelRepo, repository.NewConversationRepository(db), repository.NewTodoInviteRepository(db), repository.NewTodoProposalRepository(db), repository.NewTodoMessageRepository(db), weatherSvc, todoSvc, airSvc, warningSvc, userWebhookSvc, notifySvc, ocrSvc, indexWatchSvc, schedulerSvc, experimentSvc, newsSvc, ratesSvc, horoscopeSvc, healthSvc, intervalSvc, aiSvc, toneSvc, obsSvc, transferSvc, tierSvc, paymentSvc, auditSvc, roleSvc, dashboardSvc, miniAppSvc, jobSvc, service.NewTripService(weatherSvc, calendarSvc, aiSvc), maxWebhooksPerUser, maxChannels, loc)
+	handlers.SetEventLog(eventLog)
+	handlers.SetCustomFestivals(customFestivalRepo, calendarSvc)
+	handlers.SetCalendarFeeds(calendarFeedSvc)
+	handlers.SetSolarTerms(solarTermSvc)
+	processedUpdateRepo := repository.NewProcessedUpdateRepository(db)
+	teleBot.Use(bot.NewUpdateDeduplicator(processedUpdateRepo, "", 1024).Middleware())
+	handlers.RegisterHandlers("", teleBot.Bot)
+	for name, b := range extraBots {
+		b.Use(bot.NewUpdateDeduplicator(processedUpdateRepo, name, 1024).Middleware())
+		handlers.RegisterHandlers(name, b.Bot)
+	}
+
+	// Backfill profiles of users created before profiles were recorded
+	go func() {
+		lookup := func(name string, chatID int64) (model.UserProfile, error) {
+			b := teleBot
+			if extra, ok := extraBots[name]; ok {
+				b = extra
+			}
+			chat, err := b.ChatByID(chatID)
+			if err != nil {
+				return model.UserProfile{}, err
+			}
+			return model.UserProfile{Username: chat.Username, FirstName: chat.FirstName, LastName: chat.LastName}, nil
+		}
+		if err := migration.BackfillUserProfiles(db, lookup, 100*time.Millisecond); err != nil {
+			logger.Warn("Failed to backfill user profiles", zap.Error(err))
+		}
+	}()
 
 	// Start scheduler
 	if err := schedulerSvc.Start(); err != nil {
@@ -156,28 +548,868 @@ func main() {
 	}
 	defer schedulerSvc.Stop()
 
+	if intervalSvc != nil {
+		if err := intervalSvc.Start(); err != nil {
+			logger.Fatal("Failed to start interval reminders", zap.Error(err))
+		}
+		defer intervalSvc.Stop()
+	}
+
+	if mqttSvc != nil {
+		mqttSvc.Start()
+	}
+
+	// Initialize HTTP servers (health check, debug endpoints)
+	adminAPI := server.NewAdminAPI(cfg.Server.Admin.Token, userRepo, subRepo, todoRepo, deliveryRepo, announcementRepo, snapshotSvc, schedulerSvc, experimentSvc, transferSvc, tierSvc, paymentRepo, auditSvc, jobSvc)
+	adminAPI.SetEventLog(eventLog)
+	httpServers, err := initHTTPServers(&cfg.Server, adminAPI, digestCache, dashboardSvc, miniAppSvc)
+	if err != nil {
+		logger.Fatal("Failed to initialize HTTP servers", zap.Error(err))
+	}
+	for _, srv := range httpServers {
+		if err := srv.Start(); err != nil {
+			logger.Fatal("Failed to start HTTP server", zap.Error(err))
+		}
+	}
+
+	// Report readiness to systemd and keep its watchdog fed while the scheduler ticks
+	watchdogCtx, stopWatchdog := context.WithCancel(context.Background())
+	defer stopWatchdog()
+	startWatchdog(watchdogCtx, &cfg.Scheduler, schedulerSvc)
+
 	// Handle graceful shutdown
 	go func() {
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		<-sigChan
 		logger.Info("Received shutdown signal")
+		notifySystemd(sdnotify.StateStopping)
+		stopWatchdog()
 		schedulerSvc.Stop()
+		if intervalSvc != nil {
+			intervalSvc.Stop()
+		}
+		if mqttSvc != nil {
+			mqttSvc.Stop()
+		}
+		if aiProbe != nil {
+			aiProbe.Stop()
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		for _, srv := range httpServers {
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				logger.Warn("Failed to shut down HTTP server", zap.Error(err))
+			}
+		}
+		cancel()
+		for _, b := range extraBots {
+			b.Stop()
+		}
 		teleBot.Stop()
 		os.Exit(0)
 	}()
 
-	// Start bot
+	// Start bots
+	for name, b := range extraBots {
+		go b.Start()
+		logger.Info("Additional bot started", zap.String("name", name), zap.String("username", b.Me.Username))
+	}
 	logger.Info("Bot started successfully")
+	notifySystemd(sdnotify.StateReady + "\n" + sdnotify.Status("running"))
 	teleBot.Start()
 }
 
+// botNamePattern restricts the names of additional bots, which are stored with their users
+var botNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// initExtraBots creates the additional bots served by this process, keyed by name
+func initExtraBots(cfg *config.TelegramConfig) (map[string]*bot.Bot, error) {
+	bots := make(map[string]*bot.Bot, len(cfg.Bots))
+	for i, c := range cfg.Bots {
+		if !botNamePattern.MatchString(c.Name) {
+			return nil, fmt.Errorf("telegram.bots[%d].name must be 1-32 lowercase letters, digits, '_' or '-'", i)
+		}
+		if _, ok := bots[c.Name]; ok {
+			return nil, fmt.Errorf("duplicate bot name: %s", c.Name)
+		}
+		if c.Token == "" || c.Token == cfg.Token {
+			return nil, fmt.Errorf("telegram.bots[%d].token must be set and differ from telegram.token", i)
+		}
+		b, err := bot.NewBot(c.Token, extraBotEndpoints(cfg, c), telegramProbeInterval(cfg))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bot %s: %w", c.Name, err)
+		}
+		bots[c.Name] = b
+		logger.Info("Additional bot initialized", zap.String("name", c.Name), zap.String("username", b.Me.Username))
+	}
+	return bots, nil
+}
+
+// telegramEndpoints returns the Bot API endpoints of the primary bot in order of preference:
+// telegram.api_endpoints, else telegram.api_endpoint ("" = api.telegram.org)
+func telegramEndpoints(cfg *config.TelegramConfig) []string {
+	if len(cfg.APIEndpoints) > 0 {
+		return cfg.APIEndpoints
+	}
+	return []string{cfg.APIEndpoint}
+}
+
+// extraBotEndpoints returns the Bot API endpoints of an additional bot, defaulting to those of the
+// primary bot
+func extraBotEndpoints(cfg *config.TelegramConfig, c config.BotConfig) []string {
+	if len(c.APIEndpoints) > 0 {
+		return c.APIEndpoints
+	}
+	if c.APIEndpoint != "" {
+		return []string{c.APIEndpoint}
+	}
+	return telegramEndpoints(cfg)
+}
+
+// telegramProbeInterval returns the interval between health probes of the Bot API endpoints
+func telegramProbeInterval(cfg *config.TelegramConfig) time.Duration {
+	if cfg.ProbeInterval <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(cfg.ProbeInterval) * time.Second
+}
+
+// initLocalFiles detects whether generated files can be sent through the directory shared with a
+// local Bot API server, returning nil (files are uploaded) when it is not configured or usable
+func initLocalFiles(cfg *config.TelegramConfig) *notify.LocalFiles {
+	if cfg.LocalFiles.Dir == "" {
+		return nil
+	}
+	if !selfHostedEndpoints(telegramEndpoints(cfg)) {
+		logger.Warn("telegram.local_files requires a self-hosted Bot API server, uploading files instead")
+		return nil
+	}
+	files, err := notify.NewLocalFiles(cfg.LocalFiles.Dir, cfg.LocalFiles.ServerDir)
+	if err == nil {
+		err = files.Check()
+	}
+	if err != nil {
+		logger.Warn("Local Bot API file support disabled, uploading files instead", zap.Error(err))
+		return nil
+	}
+	logger.Info("Local Bot API file support enabled", zap.String("dir", cfg.LocalFiles.Dir))
+	return files
+}
+
+// selfHostedEndpoints reports whether all Bot API endpoints are self-hosted servers rather than
+// api.telegram.org
+func selfHostedEndpoints(endpoints []string) bool {
+	for _, endpoint := range endpoints {
+		u, err := url.Parse(endpoint)
+		if endpoint == "" || err != nil || strings.EqualFold(u.Hostname(), "api.telegram.org") {
+			return false
+		}
+	}
+	return len(endpoints) > 0
+}
+
+// initQWeatherClient creates the QWeather client for the configured authentication mode
+func initQWeatherClient(cfg *config.QWeatherConfig) (*qweather.Client, error) {
+	var client *qweather.Client
+	switch cfg.AuthMode {
+	case "jwt":
+		var err error
+		client, err = qweather.NewClientWithJWT(
+			cfg.PrivateKeyPath,
+			cfg.KeyID,
+			cfg.ProjectID,
+			cfg.BaseURL,
+		)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("QWeather client initialized with JWT authentication")
+	default:
+		// Default to API Key mode for backward compatibility
+		client = qweather.NewClient(cfg.APIKey, cfg.BaseURL)
+		logger.Info("QWeather client initialized with API Key authentication")
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	client.SetTimeout(timeout)
+	return client, nil
+}
+
+// newOpenAIClient creates the OpenAI-compatible client of the AI reminders
+func newOpenAIClient(cfg *config.OpenAIConfig) *openai.Client {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout == 0 && isLocalOpenAIProvider(cfg.Provider) {
+		// Local models answer slowly, and the first request also loads the model
+		timeout = 120 * time.Second
+	}
+	client := openai.NewClient(
+		cfg.APIKey,
+		cfg.BaseURL,
+		cfg.Model,
+		cfg.MaxTokens,
+		cfg.Temperature,
+		timeout,
+	).WithContextWindow(cfg.ContextSize)
+	return withOpenAIProvider(client, cfg)
+}
+
+// withOpenAIProvider adapts a client to the API flavour of openai.provider
+func withOpenAIProvider(client *openai.Client, cfg *config.OpenAIConfig) *openai.Client {
+	switch cfg.Provider {
+	case "", "openai":
+		return client
+	case "azure":
+		return client.WithAzure(cfg.APIVersion)
+	case "local", "ollama":
+		return client.WithLocal()
+	default:
+		logger.Fatal("Unsupported openai.provider", zap.String("provider", cfg.Provider))
+		return nil
+	}
+}
+
+// isLocalOpenAIProvider reports whether openai.provider names a local server
+func isLocalOpenAIProvider(provider string) bool {
+	return provider == "local" || provider == "ollama"
+}
+
+// initNotifyRouter registers the additional notification channels enabled in the configuration
+func initNotifyRouter(cfg *config.NotifyConfig) *notify.Router {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = 15 * time.Second
+	}
+
+	router := notify.NewRouter()
+	if cfg.Email.Enabled {
+		port := cfg.Email.Port
+		if port == 0 {
+			port = 465
+		}
+		router.Register(notify.NewEmailNotifier(cfg.Email.Host, port, cfg.Email.Username, cfg.Email.Password, cfg.Email.From, timeout))
+	}
+	if cfg.Ntfy.Enabled {
+		router.Register(notify.NewNtfyNotifier(cfg.Ntfy.Server, cfg.Ntfy.Token, timeout))
+	}
+	if cfg.Bark.Enabled {
+		router.Register(notify.NewBarkNotifier(cfg.Bark.Server, timeout))
+	}
+	if cfg.WeCom.Enabled {
+		router.Register(notify.NewWeComNotifier(cfg.WeCom.Server, timeout))
+	}
+	if cfg.DingTalk.Enabled {
+		router.Register(notify.NewDingTalkNotifier(cfg.DingTalk.Server, timeout))
+	}
+
+	logger.Info("Notification channels initialized", zap.Strings("channels", router.Channels()))
+	return router
+}
+
+// initBroadcastTargets converts and validates the configured broadcast targets, skipping invalid entries
+func initBroadcastTargets(cfgs []config.BroadcastConfig, router *notify.Router) []service.BroadcastTarget {
+	var targets []service.BroadcastTarget
+	for i, c := range cfgs {
+		n, ok := router.Get(c.Channel)
+		if !ok {
+			logger.Warn("Broadcast target uses a disabled channel, skipping",
+				zap.Int("index", i),
+				zap.String("channel", c.Channel))
+			continue
+		}
+		if err := n.ValidateTarget(c.Target); err != nil {
+			logger.Warn("Invalid broadcast target, skipping",
+				zap.Int("index", i),
+				zap.String("channel", c.Channel),
+				zap.Error(err))
+			continue
+		}
+		targets = append(targets, service.BroadcastTarget{
+			Channel: c.Channel,
+			Target:  c.Target,
+			Events:  c.Events,
+			Cities:  c.Cities,
+		})
+	}
+	if len(targets) > 0 {
+		logger.Info("Broadcast targets initialized", zap.Int("count", len(targets)))
+	}
+	return targets
+}
+
+// initMQTTService creates the MQTT publisher, applying defaults for unset values
+func initMQTTService(cfg *config.MQTTConfig, subRepo *repository.SubscriptionRepository, weatherSvc *service.WeatherService) (*service.MQTTService, error) {
+	if cfg.Broker == "" {
+		return nil, fmt.Errorf("mqtt.broker is required")
+	}
+	if cfg.QoS < 0 || cfg.QoS > 2 {
+		return nil, fmt.Errorf("mqtt.qos must be 0, 1 or 2")
+	}
+
+	opts := service.MQTTOptions{
+		Broker:      cfg.Broker,
+		ClientID:    cfg.ClientID,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		TopicPrefix: strings.TrimSuffix(cfg.TopicPrefix, "/"),
+		QoS:         byte(cfg.QoS),
+		Interval:    time.Duration(cfg.Interval) * time.Minute,
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+	}
+	if opts.ClientID == "" {
+		opts.ClientID = "daily-reminder-bot"
+	}
+	if opts.TopicPrefix == "" {
+		opts.TopicPrefix = "reminderbot"
+	}
+	if opts.Interval == 0 {
+		opts.Interval = 30 * time.Minute
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	return service.NewMQTTService(opts, subRepo, weatherSvc), nil
+}
+
+// initVoiceService creates the voice reminder service, applying defaults for unset values.
+// The openai provider reuses the OpenAI credentials unless TTS-specific ones are set.
+func initVoiceService(cfg *config.TTSConfig, openaiCfg *config.OpenAIConfig, telegram *notify.TelegramNotifier) (*service.VoiceService, error) {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+
+	var synth tts.Synthesizer
+	switch cfg.Provider {
+	case "", "openai":
+		apiKey, baseURL := cfg.APIKey, cfg.BaseURL
+		if apiKey == "" {
+			apiKey = openaiCfg.APIKey
+		}
+		if baseURL == "" {
+			baseURL = openaiCfg.BaseURL
+		}
+		if baseURL == "" {
+			return nil, fmt.Errorf("tts.base_url is required")
+		}
+		speechModel, voice := cfg.Model, cfg.Voice
+		if speechModel == "" {
+			speechModel = "tts-1"
+		}
+		if voice == "" {
+			voice = "alloy"
+		}
+		synth = tts.NewOpenAISynthesizer(apiKey, baseURL, speechModel, voice, timeout)
+		logger.Info("Voice reminders enabled",
+			zap.String("provider", "openai"),
+			zap.String("model", speechModel),
+			zap.String("voice", voice),
+			zap.String("base_url", baseURL))
+	case "command":
+		mime := cfg.MIME
+		if mime == "" {
+			mime = "audio/mpeg"
+		}
+		cmd, err := tts.NewCommandSynthesizer(cfg.Command, mime)
+		if err != nil {
+			return nil, err
+		}
+		synth = cmd
+		logger.Info("Voice reminders enabled",
+			zap.String("provider", "command"),
+			zap.String("program", cfg.Command[0]))
+	default:
+		return nil, fmt.Errorf("unknown tts.provider: %s", cfg.Provider)
+	}
+
+	return service.NewVoiceService(synth, telegram, timeout), nil
+}
+
+// initNewsService creates the news headlines section, applying defaults
+func initNewsService(cfg *config.NewsConfig, aiSvc *service.AIService) *service.NewsService {
+	maxItems := cfg.MaxItems
+	if maxItems <= 0 {
+		maxItems = 3
+	}
+	cacheTTL := time.Duration(cfg.CacheMinutes) * time.Minute
+	if cacheTTL <= 0 {
+		cacheTTL = time.Hour
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	if cfg.FeedURL == "" && !cfg.UserFeeds {
+		logger.Warn("News section enabled without news.feed_url or news.user_feeds; no headlines will be shown")
+	}
+	logger.Info("News section enabled",
+		zap.Bool("default_feed", cfg.FeedURL != ""),
+		zap.Bool("user_feeds", cfg.UserFeeds),
+		zap.Bool("ai_summary", aiSvc.IsEnabled()),
+		zap.Int("max_items", maxItems))
+	return service.NewNewsService(feed.NewClient(timeout, true), feed.NewClient(timeout, false), aiSvc, cfg.FeedURL, cfg.UserFeeds, maxItems, cacheTTL)
+}
+
+// initRatesService creates the exchange rate and metal price section, applying defaults
+func initRatesService(cfg *config.RatesConfig) (*service.RatesService, error) {
+	pairs := []rates.Pair{{Base: "USD", Quote: "CNY"}}
+	if len(cfg.Pairs) > 0 {
+		var err error
+		if pairs, err = service.ParseRatePairs(strings.Join(cfg.Pairs, ",")); err != nil {
+			return nil, fmt.Errorf("invalid rates.pairs: %w", err)
+		}
+	}
+	maxPairs := cfg.MaxPairs
+	if maxPairs <= 0 {
+		maxPairs = 5
+	}
+	cacheTTL := time.Duration(cfg.CacheMinutes) * time.Minute
+	if cacheTTL <= 0 {
+		cacheTTL = time.Hour
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	fiat := rates.NewFrankfurter(cfg.FiatURL, timeout)
+	provider := rates.NewRouter(fiat, rates.NewGoldAPI(cfg.MetalURL, timeout, fiat))
+	logger.Info("Rates section enabled",
+		zap.String("pairs", service.FormatRatePairs(pairs)),
+		zap.Int("max_pairs", maxPairs))
+	return service.NewRatesService(provider, pairs, maxPairs, cacheTTL), nil
+}
+
+// initCalendarFeedService creates the users' iCalendar feeds and their agenda section, applying
+// defaults
+func initCalendarFeedService(cfg *config.ICSConfig, db *gorm.DB, loc *time.Location) *service.CalendarFeedService {
+	maxPerUser := cfg.MaxPerUser
+	if maxPerUser <= 0 {
+		maxPerUser = 3
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	logger.Info("Calendar feeds enabled",
+		zap.Int("max_per_user", maxPerUser))
+	return service.NewCalendarFeedService(repository.NewCalendarFeedRepository(db), ics.NewClient(timeout, false), maxPerUser, loc)
+}
+
+// initHoroscopeService creates the horoscope section; the ai source has the AI write the summaries
+func initHoroscopeService(cfg *config.HoroscopeConfig, aiSvc *service.AIService) (*service.HoroscopeService, error) {
+	switch cfg.Source {
+	case "", "builtin":
+		logger.Info("Horoscope section enabled", zap.String("source", "builtin"))
+		return service.NewHoroscopeService(horoscope.NewGenerator(), nil), nil
+	case "ai":
+		if !aiSvc.IsEnabled() {
+			logger.Warn("horoscope.source is ai but openai is disabled; using built-in summaries")
+		}
+		logger.Info("Horoscope section enabled", zap.String("source", "ai"))
+		return service.NewHoroscopeService(horoscope.NewGenerator(), aiSvc), nil
+	default:
+		return nil, fmt.Errorf("unknown horoscope.source %q (expected builtin or ai)", cfg.Source)
+	}
+}
+
+// initTriviaService creates the trivia of daily reminders from the configured source
+func initTriviaService(cfg *config.TriviaConfig, aiSvc *service.AIService, loc *time.Location) (*service.TriviaService, error) {
+	switch cfg.Source {
+	case "", "builtin":
+		logger.Info("Trivia enabled", zap.String("source", "builtin"))
+		return service.NewTriviaService(nil, loc), nil
+	case "ai":
+		if !aiSvc.IsEnabled() {
+			logger.Warn("trivia.source is ai but openai is disabled; using the curated text")
+		}
+		logger.Info("Trivia enabled", zap.String("source", "ai"))
+		return service.NewTriviaService(aiSvc, loc), nil
+	default:
+		return nil, fmt.Errorf("unknown trivia.source %q (expected builtin or ai)", cfg.Source)
+	}
+}
+
+// initSolarTermService creates the solar term care tips from the configured source
+func initSolarTermService(cfg *config.JieQiConfig, aiSvc *service.AIService, loc *time.Location) (*service.SolarTermService, error) {
+	switch cfg.Source {
+	case "", "builtin":
+		logger.Info("Solar term tips enabled", zap.String("source", "builtin"))
+		return service.NewSolarTermService(nil, loc), nil
+	case "ai":
+		if !aiSvc.IsEnabled() {
+			logger.Warn("jieqi.source is ai but openai is disabled; using the curated text")
+		}
+		logger.Info("Solar term tips enabled", zap.String("source", "ai"))
+		return service.NewSolarTermService(aiSvc, loc), nil
+	default:
+		return nil, fmt.Errorf("unknown jieqi.source %q (expected builtin or ai)", cfg.Source)
+	}
+}
+
+// initTierService creates the service tier limits, applying defaults for unset limits; free users
+// get the operator's regenerate quota by default
+func initTierService(cfg *config.TiersConfig, regenQuota int, userRepo *repository.UserRepository) *service.TierService {
+	free := tierLimits(&cfg.Free, service.TierLimits{Subscriptions: 5, Regenerations: regenQuota, IndexWatches: 10, APICalls: 100})
+	premium := tierLimits(&cfg.Premium, service.TierLimits{Subscriptions: 20, Regenerations: max(10, regenQuota), IndexWatches: 50, APICalls: 1000})
+	logger.Info("Service tiers",
+		zap.Any("free", free),
+		zap.Any("premium", premium))
+	return service.NewTierService(userRepo, free, premium)
+}
+
+// tierLimits applies defaults to the configured limits of a tier (0 = default, -1 = none allowed)
+func tierLimits(cfg *config.TierLimitsConfig, defaults service.TierLimits) service.TierLimits {
+	limit := func(v, def int) int {
+		if v == 0 {
+			return def
+		}
+		return max(v, 0)
+	}
+	return service.TierLimits{
+		Subscriptions: limit(cfg.Subscriptions, defaults.Subscriptions),
+		Regenerations: limit(cfg.Regenerations, defaults.Regenerations),
+		IndexWatches:  limit(cfg.IndexWatches, defaults.IndexWatches),
+		APICalls:      limit(cfg.APICalls, defaults.APICalls),
+	}
+}
+
+// initRoleService creates the staff roles; observation moderators get the support role
+func initRoleService(cfg *config.TelegramConfig, moderators []int64, db *gorm.DB) *service.RoleService {
+	if len(cfg.Owners) == 0 {
+		logger.Warn("telegram.owners is empty; admins can only be set in the configuration")
+	}
+	logger.Info("Staff roles",
+		zap.Int("owners", len(cfg.Owners)),
+		zap.Int("admins", len(cfg.Admins)),
+		zap.Int("support", len(moderators)))
+	return service.NewRoleService(repository.NewStaffRoleRepository(db), cfg.Owners, cfg.Admins, moderators)
+}
+
+// initPaymentService creates the premium payment service, applying defaults for unset options;
+// without a provider token premium is sold in Telegram Stars. Returns nil when misconfigured.
+func initPaymentService(cfg *config.PaymentsConfig, paymentRepo *repository.PaymentRepository) *service.PaymentService {
+	currency := strings.ToUpper(cfg.Currency)
+	if cfg.ProviderToken == "" {
+		if currency != "" && currency != service.CurrencyStars {
+			logger.Warn("payments.currency is ignored without a provider token; selling in Telegram Stars",
+				zap.String("currency", currency))
+		}
+		currency = service.CurrencyStars
+	} else if currency == "" || currency == service.CurrencyStars {
+		logger.Warn("payments.currency must be the provider's currency (e.g. USD); payments disabled")
+		return nil
+	}
+	var plans []service.PaymentPlan
+	for _, p := range cfg.Plans {
+		if p.Days <= 0 || p.Price <= 0 {
+			logger.Warn("Ignoring invalid payment plan", zap.Int("days", p.Days), zap.Int("price", p.Price))
+			continue
+		}
+		plans = append(plans, service.PaymentPlan{Days: p.Days, Price: p.Price})
+	}
+	if len(plans) == 0 {
+		plans = []service.PaymentPlan{{Days: 30, Price: 100}, {Days: 365, Price: 900}}
+	}
+	logger.Info("Payments enabled",
+		zap.String("currency", currency),
+		zap.Any("plans", plans))
+	return service.NewPaymentService(paymentRepo, cfg.ProviderToken, currency, plans)
+}
+
+// reminderSpread returns the spread mode of reminders, defaulting to one second per subscription
+func reminderSpread(mode string) string {
+	switch mode {
+	case service.ReminderSpreadSecond, service.ReminderSpreadHalfMinute, service.ReminderSpreadOff:
+		return mode
+	case "":
+		return service.ReminderSpreadSecond
+	}
+	logger.Warn("Unknown scheduler.spread, spreading reminders by second", zap.String("spread", mode))
+	return service.ReminderSpreadSecond
+}
+
+// initDashboardService creates the web dashboard service, applying defaults for unset options
+func initDashboardService(cfg *config.DashboardConfig, db *gorm.DB, userRepo *repository.UserRepository, subRepo *repository.SubscriptionRepository, todoSvc *service.TodoService, deliveryRepo *repository.DeliveryLogRepository) *service.DashboardService {
+	sessionHours := cfg.SessionHours
+	if sessionHours <= 0 {
+		sessionHours = 168
+	}
+	url := strings.TrimSpace(cfg.PublicURL)
+	if url == "" {
+		logger.Warn("server.dashboard.public_url is not set; /dashboard will not show where to log in")
+	}
+	return service.NewDashboardService(repository.NewDashboardRepository(db), userRepo, subRepo, todoSvc, deliveryRepo,
+		time.Duration(sessionHours)*time.Hour, url)
+}
+
+// initMiniAppService creates the Mini App service for all bots. Returns nil when public_url is not
+// an HTTPS address, which Telegram requires of Mini Apps.
+func initMiniAppService(cfg *config.MiniAppConfig, telegram *config.TelegramConfig, userRepo *repository.UserRepository, subRepo *repository.SubscriptionRepository, todoRepo *repository.TodoRepository, todoSvc *service.TodoService) *service.MiniAppService {
+	url := strings.TrimSpace(cfg.PublicURL)
+	if !strings.HasPrefix(url, "https://") {
+		logger.Warn("server.miniapp.public_url must be an HTTPS address; Mini App disabled", zap.String("public_url", url))
+		return nil
+	}
+	tokens := map[string]string{"": telegram.Token}
+	for _, b := range telegram.Bots {
+		tokens[b.Name] = b.Token
+	}
+	return service.NewMiniAppService(tokens, userRepo, subRepo, todoRepo, todoSvc, url)
+}
+
+// initObservationService creates the crowd observation service, applying defaults for unset limits
+func initObservationService(cfg *config.ObservationsConfig, db *gorm.DB, userRepo *repository.UserRepository) *service.ObservationService {
+	minReporters := cfg.MinReporters
+	if minReporters <= 0 {
+		minReporters = 3
+	}
+	windowMinutes := cfg.WindowMinutes
+	if windowMinutes <= 0 {
+		windowMinutes = 60
+	}
+	maxPerDay := cfg.MaxPerDay
+	if maxPerDay <= 0 {
+		maxPerDay = 5
+	}
+	logger.Info("Observations enabled",
+		zap.Int("min_reporters", minReporters),
+		zap.Int("window_minutes", windowMinutes),
+		zap.Int("max_per_day", maxPerDay))
+	return service.NewObservationService(repository.NewObservationRepository(db), userRepo,
+		minReporters, time.Duration(windowMinutes)*time.Minute, maxPerDay)
+}
+
+// initFieldCipher creates the cipher of field-level encryption from encryption.key, or nil when no key is set
+func initFieldCipher(cfg *config.EncryptionConfig) (*fieldcrypt.Cipher, error) {
+	if cfg.Key == "" {
+		return nil, nil
+	}
+	key, err := fieldcrypt.ParseKey(cfg.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption.key: %w", err)
+	}
+	return fieldcrypt.New(key)
+}
+
+// initHealthReminderService creates the private health reminders, which require the encryption key
+func initHealthReminderService(cfg *config.HealthConfig, cipher *fieldcrypt.Cipher, db *gorm.DB, telegram *notify.TelegramNotifier) (*service.HealthReminderService, error) {
+	if cipher == nil {
+		return nil, fmt.Errorf("health reminders require encryption.key (generate one with `openssl rand -base64 32`)")
+	}
+
+	maxPerUser := cfg.MaxPerUser
+	if maxPerUser <= 0 {
+		maxPerUser = 5
+	}
+	logger.Info("Health reminders enabled", zap.Int("max_per_user", maxPerUser))
+	return service.NewHealthReminderService(repository.NewHealthReminderRepository(db), cipher, telegram, maxPerUser), nil
+}
+
+// initImageProvider creates the cached image source of reminder images, applying defaults for unset values
+func initImageProvider(cfg *config.ImageConfig) (imagery.Provider, error) {
+	cacheTTL := time.Duration(cfg.CacheTTL) * time.Second
+	if cacheTTL == 0 {
+		cacheTTL = 24 * time.Hour
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = 15 * time.Second
+	}
+
+	var provider imagery.Provider
+	switch cfg.Source {
+	case "", "builtin":
+		provider = imagery.NewBuiltinProvider()
+	case "dir":
+		if cfg.Dir == "" {
+			return nil, fmt.Errorf("image.dir is required")
+		}
+		provider = imagery.NewDirProvider(cfg.Dir)
+	case "url":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("image.url is required")
+		}
+		provider = imagery.NewURLProvider(cfg.URL, timeout)
+	default:
+		return nil, fmt.Errorf("unknown image.source: %s", cfg.Source)
+	}
+
+	logger.Info("Reminder images enabled",
+		zap.String("source", cfg.Source),
+		zap.Duration("cache_ttl", cacheTTL))
+	return imagery.NewCache(provider, cacheTTL), nil
+}
+
+// initOCRService creates the service reading todos from photos, falling back to the openai
+// settings and applying defaults for unset values
+func initOCRService(cfg *config.OCRConfig, openaiCfg *config.OpenAIConfig) (*service.OCRService, error) {
+	apiKey, baseURL, visionModel := cfg.APIKey, cfg.BaseURL, cfg.Model
+	if apiKey == "" {
+		apiKey = openaiCfg.APIKey
+	}
+	if baseURL == "" {
+		baseURL = openaiCfg.BaseURL
+	}
+	if visionModel == "" {
+		visionModel = openaiCfg.Model
+	}
+	if baseURL == "" || visionModel == "" {
+		return nil, fmt.Errorf("ocr.base_url and ocr.model are required")
+	}
+	maxTokens := cfg.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 1000
+	}
+	maxItems := cfg.MaxItems
+	if maxItems == 0 {
+		maxItems = 10
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = 60 * time.Second
+	}
+
+	client := openai.NewClient(apiKey, baseURL, visionModel, maxTokens, 0.2, timeout)
+	if cfg.BaseURL == "" {
+		// The OCR shares the AI service's endpoint, and with it its API flavour
+		client = withOpenAIProvider(client, openaiCfg)
+	}
+	logger.Info("Photo OCR enabled",
+		zap.String("model", visionModel),
+		zap.String("base_url", baseURL))
+	return service.NewOCRService(client, maxItems), nil
+}
+
+// initWebhookService creates the webhook service, applying defaults for unset values
+func initWebhookService(cfg *config.WebhookConfig, repo *repository.WebhookRepository) *service.WebhookService {
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = 6
+	}
+
+	endpoints := make([]service.WebhookEndpoint, 0, len(cfg.Endpoints))
+	for _, ep := range cfg.Endpoints {
+		if err := service.ValidateWebhookURL(ep.URL); err != nil {
+			logger.Warn("Skipping invalid webhook endpoint", zap.String("url", ep.URL), zap.Error(err))
+			continue
+		}
+		endpoints = append(endpoints, service.WebhookEndpoint{URL: ep.URL, Secret: ep.Secret, Events: ep.Events})
+	}
+
+	logger.Info("Webhook service initialized",
+		zap.Int("endpoints", len(endpoints)),
+		zap.Bool("user_webhooks", cfg.UserWebhooks))
+	return service.NewWebhookService(repo, endpoints, timeout, maxAttempts, cfg.AllowPrivateTargets)
+}
+
+// initEventLog creates the business event log writing to the configured sink
+func initEventLog(cfg *config.EventsConfig, db *gorm.DB) (*service.EventLogService, error) {
+	switch cfg.Sink {
+	case "", service.EventSinkFile:
+		path := cfg.Path
+		if path == "" {
+			path = "./data/events.jsonl"
+		}
+		sink, err := service.NewFileEventSink(path)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Business events recorded to file", zap.String("path", path))
+		return service.NewEventLogService(sink), nil
+	case service.EventSinkDB:
+		logger.Info("Business events recorded to the event_logs table")
+		return service.NewEventLogService(service.NewDBEventSink(repository.NewEventLogRepository(db))), nil
+	}
+	return nil, fmt.Errorf("unknown event sink %q (file or db)", cfg.Sink)
+}
+
+// initHTTPServers creates the configured HTTP servers without starting them
+func initHTTPServers(cfg *config.ServerConfig, adminAPI *server.AdminAPI, digestCache *service.DigestCache, dashboardSvc *service.DashboardService, miniAppSvc *service.MiniAppService) ([]*server.Server, error) {
+	var servers []*server.Server
+
+	if cfg.Admin.Enabled && !cfg.Enabled {
+		return nil, fmt.Errorf("admin API requires server.enabled")
+	}
+	if cfg.Feed.Enabled && !cfg.Enabled {
+		return nil, fmt.Errorf("RSS feeds require server.enabled")
+	}
+	if cfg.Dashboard.Enabled && !cfg.Enabled {
+		return nil, fmt.Errorf("web dashboard requires server.enabled")
+	}
+	if cfg.MiniApp.Enabled && !cfg.Enabled {
+		return nil, fmt.Errorf("Mini App requires server.enabled")
+	}
+	if cfg.GraphQL.Enabled && (!cfg.Enabled || !cfg.Dashboard.Enabled) {
+		return nil, fmt.Errorf("GraphQL API requires server.enabled and server.dashboard.enabled")
+	}
+
+	if cfg.Enabled {
+		addr := cfg.ListenAddr
+		if addr == "" {
+			addr = "127.0.0.1:8080"
+		}
+		mainServer := server.New("main", addr)
+		if cfg.Admin.Enabled {
+			if cfg.Admin.Token == "" {
+				return nil, fmt.Errorf("admin API requires server.admin.token")
+			}
+			adminAPI.Register(mainServer)
+			logger.Info("Admin API enabled", zap.String("addr", addr))
+		}
+		if cfg.Feed.Enabled {
+			mainServer.RegisterFeed(digestCache)
+			logger.Info("RSS feeds enabled", zap.String("addr", addr))
+		}
+		if cfg.Dashboard.Enabled {
+			mainServer.RegisterDashboard(dashboardSvc)
+			logger.Info("Web dashboard enabled", zap.String("addr", addr))
+		}
+		if cfg.GraphQL.Enabled {
+			if err := mainServer.RegisterGraphQL(dashboardSvc); err != nil {
+				return nil, err
+			}
+			logger.Info("GraphQL API enabled", zap.String("addr", addr))
+		}
+		if miniAppSvc != nil {
+			mainServer.RegisterMiniApp(miniAppSvc)
+			logger.Info("Mini App enabled", zap.String("addr", addr), zap.String("url", miniAppSvc.URL()))
+		}
+		servers = append(servers, mainServer)
+	}
+
+	if cfg.Debug.Enabled {
+		addr := cfg.Debug.ListenAddr
+		if addr == "" {
+			addr = "127.0.0.1:6060"
+		}
+		if !server.IsLoopbackAddr(addr) {
+			if !cfg.Debug.AllowPublic {
+				return nil, fmt.Errorf("debug endpoint must bind to a loopback address (got %s); set server.debug.allow_public to override", addr)
+			}
+			logger.Warn("Debug endpoint exposed on a non-loopback address", zap.String("addr", addr))
+		}
+		debugServer := server.New("debug", addr)
+		debugServer.RegisterDebug()
+		servers = append(servers, debugServer)
+	}
+
+	return servers, nil
+}
+
 // initDatabase initializes the database and runs migrations
 func initDatabase(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 	var db *gorm.DB
 	var err error
 
-	gormLogger := logger.NewGormAdapter(logger.Get(), 200*time.Millisecond)
+	gormLogger := logger.NewGormAdapter(logger.Get().Named("gorm"), 200*time.Millisecond)
 
 	switch cfg.Type {
 	case "mysql":
@@ -198,19 +1430,9 @@ func initDatabase(cfg *config.DatabaseConfig) (*gorm.DB, error) {
 		return nil, fmt.Errorf("unsupported database type: %s (must be 'sqlite' or 'mysql')", cfg.Type)
 	}
 
-	// Auto migrate models
-	if err := db.AutoMigrate(
-		&model.User{},
-		&model.Subscription{},
-		&model.Todo{},
-		&model.WarningLog{},
-	); err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %w", err)
-	}
-
-	// Run data migration to multi-subscription model
-	if err := migration.MigrateToMultiSubscription(db); err != nil {
-		return nil, fmt.Errorf("failed to run data migration: %w", err)
+	// Auto migrate models and run data migrations
+	if err := migration.Run(db); err != nil {
+		return nil, err
 	}
 
 	logger.Info("Database initialized successfully")
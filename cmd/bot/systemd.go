@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/config"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/sdnotify"
+	"go.uber.org/zap"
+)
+
+// notifySystemd sends a state to systemd, logging failures
+func notifySystemd(state string) {
+	if _, err := sdnotify.Notify(state); err != nil {
+		logger.Warn("Failed to notify systemd", zap.String("state", state), zap.Error(err))
+	}
+}
+
+// startWatchdog sends systemd watchdog keep-alives at half the watchdog timeout for as long as the
+// scheduler keeps ticking. It does nothing unless systemd enabled the watchdog (WatchdogSec=).
+func startWatchdog(ctx context.Context, cfg *config.SchedulerConfig, scheduler *service.SchedulerService) {
+	interval := sdnotify.WatchdogInterval()
+	if interval == 0 {
+		return
+	}
+	maxAge := time.Duration(cfg.HeartbeatTimeout) * time.Second
+	if maxAge == 0 {
+		maxAge = 180 * time.Second
+	}
+	logger.Info("Systemd watchdog enabled",
+		zap.Duration("timeout", interval),
+		zap.Duration("heartbeat_timeout", maxAge))
+
+	go func() {
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+		stale := false
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			age := time.Since(scheduler.LastHeartbeat())
+			if age > maxAge {
+				// Withhold keep-alives so that systemd restarts the bot
+				if !stale {
+					logger.Error("Scheduler heartbeat is stale, stopping watchdog keep-alives", zap.Duration("age", age))
+					notifySystemd(sdnotify.Status("scheduler stalled"))
+					stale = true
+				}
+				continue
+			}
+			if stale {
+				logger.Info("Scheduler heartbeat recovered", zap.Duration("age", age))
+				notifySystemd(sdnotify.Status("running"))
+				stale = false
+			}
+			notifySystemd(sdnotify.StateWatchdog)
+		}
+	}()
+}
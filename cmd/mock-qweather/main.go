@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/mockqweather"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func main() {
+	// Parse command-line flags
+	addr := flag.String("addr", ":8088", "Listen address")
+	latency := flag.Duration("latency", 0, "Fixed latency added to every response (e.g. 200ms)")
+	jitter := flag.Duration("jitter", 0, "Random extra latency in [0, jitter)")
+	errorRate := flag.Float64("error-rate", 0, "Fraction of requests (0-1) answered with an error code")
+	errorCode := flag.String("error-code", "500", "QWeather error code returned on injected failures")
+	failPaths := flag.String("fail", "", "Comma-separated path prefixes that always fail (e.g. /v7/warning/now)")
+	knownOnly := flag.Bool("known-cities-only", false, "Return 404 for cities other than the built-in fixtures")
+	flag.Parse()
+
+	var fail []string
+	for _, p := range strings.Split(*failPaths, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			fail = append(fail, p)
+		}
+	}
+
+	server := mockqweather.New(mockqweather.Options{
+		Latency:         *latency,
+		Jitter:          *jitter,
+		ErrorRate:       *errorRate,
+		ErrorCode:       *errorCode,
+		FailPaths:       fail,
+		KnownCitiesOnly: *knownOnly,
+	})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		server.ServeHTTP(w, r)
+		logger.Info("Mock QWeather request",
+			zap.String("path", r.URL.Path),
+			zap.String("query", logger.MaskURL(r.URL.RawQuery)),
+			zap.Duration("duration", time.Since(start)))
+	})
+
+	logger.Info("Mock QWeather server listening",
+		zap.String("addr", *addr),
+		zap.Duration("latency", *latency),
+		zap.Float64("error_rate", *errorRate),
+		zap.Strings("fail_paths", fail))
+	if err := http.ListenAndServe(*addr, handler); err != nil {
+		logger.Fatal("Mock QWeather server failed", zap.Error(err))
+	}
+}
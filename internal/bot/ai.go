@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// aiModeArgs maps the options accepted by /ai to AI modes
+var aiModeArgs = map[string]string{
+	"on": model.AIModeFull, "full": model.AIModeFull, "开": model.AIModeFull, "开启": model.AIModeFull,
+	"lite": model.AIModeLite, "简洁": model.AIModeLite, "简洁模式": model.AIModeLite,
+	"off": model.AIModeOff, "关": model.AIModeOff, "关闭": model.AIModeOff,
+	"default": model.AIModeDefault, "默认": model.AIModeDefault,
+}
+
+// aiUsage is the usage of the /ai command
+const aiUsage = `用法:
+/ai [城市] on - AI 撰写每日提醒
+/ai [城市] lite - 简洁模式：更短的 AI 提醒（使用轻量模型）
+/ai [城市] off - 使用固定模板
+/ai [城市] default - 恢复默认设置`
+
+// HandleAI handles the /ai [city] [on|lite|off|default] command, choosing how a subscription's
+// daily reminder is written
+func (h *Handlers) HandleAI(c tele.Context) error {
+	if h.aiSvc == nil || !h.aiSvc.IsEnabled() {
+		return c.Send("❌ AI 功能未开启，每日提醒使用固定模板")
+	}
+	user := userFrom(c)
+	args := c.Args()
+
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市\n请先使用 /subscribe <城市> <时间> 创建订阅")
+	}
+	if len(args) == 0 {
+		return c.Send(h.formatAIStatus(subs))
+	}
+
+	isMode := func(arg string) bool {
+		_, ok := aiModeArgs[strings.ToLower(arg)]
+		return ok
+	}
+	targetSub, args, err := h.targetSubscription(c, cityChoiceAI, subs, args, isMode, "/ai "+subs[0].City+" lite")
+	if targetSub == nil {
+		return err
+	}
+	if len(args) == 0 {
+		return c.Send(h.formatAIStatus([]model.Subscription{*targetSub}))
+	}
+
+	mode, ok := aiModeArgs[strings.ToLower(args[0])]
+	if !ok {
+		return c.Send("❌ 无效的选项\n" + aiUsage)
+	}
+	if err := h.subRepo.SetAIMode(targetSub.ID, mode); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	targetSub.AIMode = mode
+
+	logger.Info("AI mode updated", zap.Uint("subscription_id", targetSub.ID), zap.String("mode", mode))
+	return c.Send(fmt.Sprintf("✅ %s 每日提醒：%s", targetSub.City, h.describeAIMode(*targetSub)))
+}
+
+// formatAIStatus lists how each subscription's daily reminder is written
+func (h *Handlers) formatAIStatus(subs []model.Subscription) string {
+	var b strings.Builder
+	b.WriteString("🤖 AI 每日提醒\n\n")
+	for _, sub := range subs {
+		b.WriteString(fmt.Sprintf("• %s：%s\n", sub.City, h.describeAIMode(sub)))
+	}
+	b.WriteString("\n" + aiUsage)
+	return b.String()
+}
+
+// describeAIMode describes the effective AI mode of a subscription
+func (h *Handlers) describeAIMode(sub model.Subscription) string {
+	var name string
+	switch h.aiSvc.ReminderMode(sub) {
+	case model.AIModeFull:
+		name = "AI 撰写"
+	case model.AIModeLite:
+		name = "AI 简洁模式"
+	default:
+		name = "固定模板"
+	}
+	if sub.AIMode == model.AIModeDefault {
+		name += "（默认）"
+	}
+	return name
+}
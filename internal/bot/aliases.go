@@ -0,0 +1,125 @@
+package bot
+
+import (
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// commandAliases maps Chinese words and short aliases to the commands they trigger, so that
+// "天气 北京" or "/tq 北京" works like "/weather 北京". Slash-less words are only routed in
+// private chats; aliases with a slash work everywhere.
+var commandAliases = map[string]string{
+	"天气":   "/weather",
+	"查天气":  "/weather",
+	"tq":   "/weather",
+	"空气":   "/air",
+	"空气质量": "/air",
+	"紫外线":  "/uv",
+	"待办":   "/todo",
+	"todo": "/todo",
+	"订阅":   "/subscribe",
+	"取消订阅": "/unsubscribe",
+	"退订":   "/unsubscribe",
+	"我的订阅": "/mystatus",
+	"订阅状态": "/mystatus",
+	"预警":   "/warning",
+	"晾晒":   "/laundry",
+	"晾衣服":  "/laundry",
+	"出行":   "/trip",
+	"行程":   "/trip",
+	"城市":   "/cities",
+	"节日":   "/festivals",
+	"日程":   "/ics",
+	"节气":   "/jieqi",
+	"命名":   "/rename",
+	"帮助":   "/help",
+	"菜单":   "/help",
+	"取消":   "/cancel",
+}
+
+// todoActionAliases maps Chinese /todo actions to the actions they stand for ("待办 添加 买菜")
+var todoActionAliases = map[string]string{
+	"添加": "add",
+	"加":  "add",
+	"完成": "done",
+	"删除": "delete",
+	"导出": "export",
+	"共享": "share",
+	"成员": "members",
+	"退出": "leave",
+}
+
+// aliasHandlers returns the handlers of the commands aliases stand for. None of them is
+// restricted to a role, as the Permissions middleware only sees the alias.
+func (h *Handlers) aliasHandlers() map[string]tele.HandlerFunc {
+	return map[string]tele.HandlerFunc{
+		"/weather":     h.HandleWeather,
+		"/air":         h.HandleAir,
+		"/uv":          h.HandleUV,
+		"/todo":        h.HandleTodo,
+		"/subscribe":   h.HandleSubscribe,
+		"/unsubscribe": h.HandleUnsubscribe,
+		"/mystatus":    h.HandleMyStatus,
+		"/warning":     h.HandleWarning,
+		"/laundry":     h.HandleLaundry,
+		"/trip":        h.HandleTrip,
+		"/cities":      h.HandleCities,
+		"/festivals":   h.HandleFestivals,
+		"/ics":         h.HandleICS,
+		"/jieqi":       h.HandleJieQi,
+		"/rename":      h.HandleRename,
+		"/help":        h.HandleHelp,
+		"/cancel":      h.HandleCancel,
+	}
+}
+
+// routeAlias runs the command a text starting with an alias stands for; other texts are ignored.
+// The message is rewritten to the command so that the handler reads its arguments as usual.
+func (h *Handlers) routeAlias(c tele.Context) error {
+	msg := c.Message()
+	if msg == nil {
+		return nil
+	}
+	fields := strings.Fields(msg.Text)
+	if len(fields) == 0 {
+		return nil
+	}
+	word, slashed := strings.CutPrefix(fields[0], "/")
+	word, _, _ = strings.Cut(word, "@")
+	command, ok := commandAliases[strings.ToLower(word)]
+	if !ok || (!slashed && (c.Chat() == nil || c.Chat().Type != tele.ChatPrivate)) {
+		return nil
+	}
+	handler, ok := h.aliasHandlers()[command]
+	if !ok {
+		return nil
+	}
+
+	args := fields[1:]
+	if command == "/todo" {
+		args = translateTodoAction(args)
+	}
+	msg.Payload = strings.Join(args, " ")
+	msg.Text = strings.TrimSpace(command + " " + msg.Payload)
+	logger.Debug("Routing command alias",
+		zap.Int64("chat_id", chatIDOf(c)),
+		zap.String("alias", word),
+		zap.String("command", command))
+	return handler(c)
+}
+
+// translateTodoAction replaces a Chinese /todo action, given first or after the city, by the
+// action it stands for
+func translateTodoAction(args []string) []string {
+	args = append([]string(nil), args...)
+	for i := 0; i < len(args) && i < 2; i++ {
+		if action, ok := todoActionAliases[args[i]]; ok {
+			args[i] = action
+			break
+		}
+	}
+	return args
+}
@@ -0,0 +1,115 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/export"
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	tele "gopkg.in/telebot.v3"
+)
+
+// Limits of /audit
+const (
+	auditListDefault   = 20
+	auditListMax       = 100
+	auditExportDays    = 30
+	auditExportMaxRows = 10000
+)
+
+// auditUsage is the usage of /audit
+const auditUsage = `用法（管理员）:
+/audit [条数] - 最近的审计日志（默认 20 条）
+/audit <对象> - 某个对象的审计日志，如 user:12
+/audit export [csv|md] [天数] - 导出最近的审计日志（默认 30 天）`
+
+// HandleAudit handles the admin command /audit (checked by Permissions): lists recent audit log entries, the entries of a
+// target, or exports them as a file
+func (h *Handlers) HandleAudit(c tele.Context) error {
+	args := c.Args()
+	if len(args) > 0 && args[0] == "export" {
+		return h.exportAudit(c, args[1:])
+	}
+	if len(args) > 1 {
+		return c.Send(auditUsage)
+	}
+
+	limit := auditListDefault
+	var filter repository.AuditLogFilter
+	if len(args) == 1 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			if n <= 0 {
+				return c.Send(auditUsage)
+			}
+			limit = min(n, auditListMax)
+		} else if strings.Contains(args[0], ":") {
+			filter.Target = args[0]
+		} else {
+			return c.Send(auditUsage)
+		}
+	}
+
+	entries, total, err := h.auditSvc.List(filter, 0, limit)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(entries) == 0 {
+		return c.Send("暂无审计日志")
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🧾 审计日志（最近 %d 条，共 %d 条）\n", len(entries), total))
+	for _, e := range entries {
+		b.WriteString("\n" + h.formatAuditEntry(e))
+	}
+	return c.Send(b.String())
+}
+
+// exportAudit sends the audit log of the last days as a document: export [csv|md] [天数]
+func (h *Handlers) exportAudit(c tele.Context, args []string) error {
+	formatName := ""
+	days := auditExportDays
+	for _, arg := range args {
+		if n, err := strconv.Atoi(arg); err == nil && n > 0 {
+			days = n
+		} else {
+			formatName = arg
+		}
+	}
+	format, ok := export.ParseFormat(formatName)
+	if !ok {
+		return c.Send(exportFormatUsage)
+	}
+
+	now := time.Now()
+	entries, _, err := h.auditSvc.List(repository.AuditLogFilter{Since: now.AddDate(0, 0, -days)}, 0, auditExportMaxRows)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(entries) == 0 {
+		return c.Send(fmt.Sprintf("最近 %d 天暂无审计日志", days))
+	}
+	h.auditSvc.Record(service.TelegramActor(c.Sender().ID), model.AuditExport, "",
+		fmt.Sprintf("days=%d format=%s entries=%d", days, format, len(entries)))
+
+	name := "audit-" + now.In(h.timezone).Format("20060102")
+	return h.sendExport(c, format, name, fmt.Sprintf("🧾 最近 %d 天的审计日志（共 %d 条）", days, len(entries)),
+		export.AuditTable(entries, h.timezone))
+}
+
+// formatAuditEntry formats an audit log entry on one line
+func (h *Handlers) formatAuditEntry(e model.AuditLog) string {
+	line := fmt.Sprintf("%s %s %s", e.CreatedAt.In(h.timezone).Format("01-02 15:04"), e.Actor, e.Action)
+	if e.Target != "" {
+		line += " " + e.Target
+	}
+	if detail := []rune(e.Detail); len(detail) > 80 {
+		line += "（" + string(detail[:79]) + "…）"
+	} else if len(detail) > 0 {
+		line += "（" + e.Detail + "）"
+	}
+	return line
+}
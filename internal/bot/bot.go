@@ -6,21 +6,55 @@ import (
 	tele "gopkg.in/telebot.v3"
 )
 
+// allowedUpdates are Telegram's default update types plus message reactions,
+// which Telegram only sends when requested explicitly
+var allowedUpdates = []string{
+	"message",
+	"edited_message",
+	"channel_post",
+	"edited_channel_post",
+	"message_reaction",
+	"inline_query",
+	"chosen_inline_result",
+	"callback_query",
+	"shipping_query",
+	"pre_checkout_query",
+	"poll",
+	"poll_answer",
+	"my_chat_member",
+	"chat_join_request",
+	"chat_boost",
+	"removed_chat_boost",
+}
+
 // Bot represents the Telegram bot
 type Bot struct {
 	*tele.Bot
+	endpoints *EndpointPool // Failover between Bot API endpoints; nil with a single endpoint
 }
 
-// NewBot creates a new Bot instance
-func NewBot(token, apiEndpoint string) (*Bot, error) {
+// NewBot creates a new Bot instance. apiEndpoints lists the Bot API endpoints in order of
+// preference ("" or none = api.telegram.org); with more than one, requests fail over between
+// them and the endpoints are probed every probeInterval while the bot runs.
+func NewBot(token string, apiEndpoints []string, probeInterval time.Duration) (*Bot, error) {
 	pref := tele.Settings{
 		Token:  token,
-		Poller: &tele.LongPoller{Timeout: 10 * time.Second},
+		Poller: &tele.LongPoller{Timeout: 10 * time.Second, AllowedUpdates: allowedUpdates},
 	}
 
-	// Set custom API endpoint if provided
-	if apiEndpoint != "" {
-		pref.URL = apiEndpoint
+	var pool *EndpointPool
+	switch {
+	case len(apiEndpoints) > 1:
+		var err error
+		pool, err = NewEndpointPool(apiEndpoints, token, probeInterval)
+		if err != nil {
+			return nil, err
+		}
+		pref.URL = pool.URL()
+		pref.Client = pool.Client(time.Minute)
+	case len(apiEndpoints) == 1 && apiEndpoints[0] != "":
+		// Set custom API endpoint if provided
+		pref.URL = apiEndpoints[0]
 	}
 
 	b, err := tele.NewBot(pref)
@@ -28,15 +62,29 @@ func NewBot(token, apiEndpoint string) (*Bot, error) {
 		return nil, err
 	}
 
-	return &Bot{Bot: b}, nil
+	return &Bot{Bot: b, endpoints: pool}, nil
+}
+
+// Endpoints returns the health of the Bot API endpoints, or nil without failover
+func (b *Bot) Endpoints() []EndpointStatus {
+	if b.endpoints == nil {
+		return nil
+	}
+	return b.endpoints.Status()
 }
 
 // Start starts the bot
 func (b *Bot) Start() {
+	if b.endpoints != nil {
+		b.endpoints.Start()
+	}
 	b.Bot.Start()
 }
 
 // Stop stops the bot
 func (b *Bot) Stop() {
 	b.Bot.Stop()
+	if b.endpoints != nil {
+		b.endpoints.Stop()
+	}
 }
@@ -0,0 +1,161 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// reportCacheTTL is how long an on-demand report is kept to answer users over their API budget
+const reportCacheTTL = 6 * time.Hour
+
+// reportCacheUserLimit is the most reports kept per user; the oldest is dropped beyond it
+const reportCacheUserLimit = 20
+
+// cachedReport is an on-demand report and when it was fetched
+type cachedReport struct {
+	text      string
+	fetchedAt time.Time
+}
+
+// reportCache keeps the latest on-demand reports of each user by kind and query, so that a user
+// over the daily API budget still gets recent data without calling the external APIs. A put only
+// prunes the user's own reports; users whose reports all expired are swept once per TTL.
+type reportCache struct {
+	mu      sync.Mutex
+	reports map[uint]map[string]cachedReport // Keyed by user ID, then reportKey
+	sweptAt time.Time
+}
+
+// newReportCache creates a new reportCache
+func newReportCache() *reportCache {
+	return &reportCache{reports: make(map[uint]map[string]cachedReport)}
+}
+
+// reportKey identifies a report by kind ("weather", "air", ...) and query
+func reportKey(kind string, query ...string) string {
+	return kind + "|" + strings.Join(query, " ")
+}
+
+// get returns an unexpired cached report of the user
+func (r *reportCache) get(userID uint, key string, now time.Time) (cachedReport, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report, ok := r.reports[userID][key]
+	if !ok || now.Sub(report.fetchedAt) >= reportCacheTTL {
+		return cachedReport{}, false
+	}
+	return report, true
+}
+
+// put caches a report of the user, dropping the user's expired reports and, at
+// reportCacheUserLimit, the oldest one
+func (r *reportCache) put(userID uint, key, text string, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if now.Sub(r.sweptAt) >= reportCacheTTL {
+		for id, reports := range r.reports {
+			if allExpired(reports, now) {
+				delete(r.reports, id)
+			}
+		}
+		r.sweptAt = now
+	}
+
+	reports := r.reports[userID]
+	if reports == nil {
+		reports = make(map[string]cachedReport)
+		r.reports[userID] = reports
+	}
+	oldest := ""
+	for k, report := range reports {
+		if now.Sub(report.fetchedAt) >= reportCacheTTL {
+			delete(reports, k)
+		} else if oldest == "" || report.fetchedAt.Before(reports[oldest].fetchedAt) {
+			oldest = k
+		}
+	}
+	if _, ok := reports[key]; !ok && len(reports) >= reportCacheUserLimit {
+		delete(reports, oldest)
+	}
+	reports[key] = cachedReport{text: text, fetchedAt: now}
+}
+
+// allExpired reports whether all the reports have expired
+func allExpired(reports map[string]cachedReport, now time.Time) bool {
+	for _, report := range reports {
+		if now.Sub(report.fetchedAt) < reportCacheTTL {
+			return false
+		}
+	}
+	return true
+}
+
+// rememberReport caches an on-demand report of the user for when they are over their API budget
+func (h *Handlers) rememberReport(c tele.Context, key, report string) {
+	if user := userFrom(c); user != nil {
+		h.reports.put(user.ID, key, report, time.Now())
+	}
+}
+
+// claimAPICall counts an on-demand query of the user against the daily API budget of their tier,
+// returning false and the budget when it is used up. Queries are let through when the count fails.
+func (h *Handlers) claimAPICall(c tele.Context) (bool, int) {
+	user := userFrom(c)
+	if user == nil {
+		return true, 0
+	}
+	ok, limit, err := h.tierSvc.ClaimAPICall(user, time.Now().In(h.timezone))
+	if err != nil {
+		logger.Error("Failed to claim API call", zap.Uint("user_id", user.ID), zap.Error(err))
+		return true, limit
+	}
+	return ok, limit
+}
+
+// spendAPICall counts an on-demand query against the user's daily API budget. Over the budget it
+// replies with the report cached under key, or only a notice when there is none, and returns
+// false; the caller then stops without querying.
+func (h *Handlers) spendAPICall(c tele.Context, key string) (bool, error) {
+	ok, limit := h.claimAPICall(c)
+	if ok {
+		return true, nil
+	}
+	if c.Callback() != nil {
+		_ = c.Respond()
+	}
+	logger.Info("API budget used up, replying from cache",
+		zap.Int64("chat_id", chatIDOf(c)),
+		zap.String("report", key))
+
+	notice := h.budgetNotice(userFrom(c), limit)
+	if report, found := h.reports.get(userFrom(c).ID, key, time.Now()); found {
+		return false, c.Send(fmt.Sprintf("%s\n以下是 %s 的缓存数据，可能不是最新：\n\n%s",
+			notice, report.fetchedAt.In(h.timezone).Format("15:04"), report.text))
+	}
+	return false, c.Send(notice + "\n暂无该查询的缓存数据，每日提醒不受影响，明天恢复实时查询")
+}
+
+// budgetNotice tells a user their daily API budget is used up, pointing free users to /tier
+func (h *Handlers) budgetNotice(user *model.User, limit int) string {
+	notice := fmt.Sprintf("⏳ 今日实时查询次数已用完（每天 %d 次）", limit)
+	if user != nil && user.EffectiveTier(time.Now()) == model.TierFree &&
+		h.tierSvc.LimitsOf(model.TierPremium).APICalls > limit {
+		notice += "，发送 /tier 查看套餐"
+	}
+	return notice
+}
+
+// budgetAlert is the callback alert shown when a button query exceeds the daily API budget
+func budgetAlert(limit int) *tele.CallbackResponse {
+	return &tele.CallbackResponse{
+		Text:      fmt.Sprintf("⏳ 今日实时查询次数已用完（每天 %d 次），明天再来吧", limit),
+		ShowAlert: true,
+	}
+}
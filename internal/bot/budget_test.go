@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReportCache(t *testing.T) {
+	cache := newReportCache()
+	now := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	key := reportKey("weather", "北京", "")
+
+	cache.put(1, key, "user 1", now)
+	if _, ok := cache.get(2, key, now); ok {
+		t.Error("a report of user 1 was returned to user 2")
+	}
+	if report, ok := cache.get(1, key, now.Add(time.Hour)); !ok || report.text != "user 1" {
+		t.Errorf("get(1) = %q, %v, want the cached report", report.text, ok)
+	}
+	if _, ok := cache.get(1, key, now.Add(reportCacheTTL)); ok {
+		t.Error("an expired report was returned")
+	}
+
+	// The oldest report is dropped beyond the per-user limit
+	for i := 1; i <= reportCacheUserLimit; i++ {
+		cache.put(1, reportKey("air", fmt.Sprint(i)), "air", now.Add(time.Duration(i)*time.Minute))
+	}
+	if _, ok := cache.get(1, key, now.Add(time.Hour)); ok {
+		t.Error("the oldest report was kept beyond the per-user limit")
+	}
+	if n := len(cache.reports[1]); n != reportCacheUserLimit {
+		t.Errorf("user 1 has %d reports, want %d", n, reportCacheUserLimit)
+	}
+
+	// Users whose reports all expired are swept by a later put
+	cache.put(2, key, "user 2", now.Add(2*reportCacheTTL))
+	if _, ok := cache.reports[1]; ok {
+		t.Error("user 1 with only expired reports was not swept")
+	}
+	if _, ok := cache.get(2, key, now.Add(2*reportCacheTTL)); !ok {
+		t.Error("the report of user 2 was not cached")
+	}
+}
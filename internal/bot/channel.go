@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// channelNames maps channel types to display names
+var channelNames = map[string]string{
+	notify.ChannelEmail:    "📧 邮件",
+	notify.ChannelNtfy:     "🔔 ntfy",
+	notify.ChannelBark:     "🍎 Bark",
+	notify.ChannelWeCom:    "💼 企业微信群机器人",
+	notify.ChannelDingTalk: "📌 钉钉群机器人",
+}
+
+// HandleChannel handles the /channel command
+// Usage: /channel | /channel [city] add <type> <target> | /channel [city] delete <id>
+func (h *Handlers) HandleChannel(c tele.Context) error {
+	chatID := c.Sender().ID
+	args := c.Args()
+
+	available := h.notifySvc.Router().Channels()
+	if len(available) == 0 {
+		return c.Send("❌ 暂无可用的额外通知渠道，请联系管理员开启")
+	}
+
+	user := userFrom(c)
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Int64("chat_id", chatID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市\n请先使用 /subscribe <城市> <时间> 创建订阅")
+	}
+
+	if len(args) == 0 {
+		return h.listChannels(c, subs, available)
+	}
+
+	isAction := func(arg string) bool {
+		return arg == "add" || arg == "delete" || arg == "del"
+	}
+	targetSub, args, err := h.targetSubscription(c, cityChoiceChannel, subs, args, isAction, "/channel "+subs[0].City+" add ntfy my-topic")
+	if targetSub == nil {
+		return err
+	}
+	if len(args) == 0 {
+		return h.listChannels(c, []model.Subscription{*targetSub}, available)
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			return c.Send(fmt.Sprintf("❌ 用法: /channel %s add <%s> <目标>", targetSub.City, strings.Join(available, "|")))
+		}
+		return h.addChannel(c, targetSub, strings.ToLower(args[1]), args[2])
+	case "delete", "del":
+		if len(args) < 2 {
+			return c.Send(fmt.Sprintf("❌ 用法: /channel %s delete <编号>", targetSub.City))
+		}
+		id, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return c.Send("❌ 编号格式错误")
+		}
+		if err := h.channelRepo.DeleteBySubscription(uint(id), targetSub.ID); err != nil {
+			return c.Send("❌ 未找到该通知渠道")
+		}
+		return c.Send(fmt.Sprintf("✅ 已删除 %s 的通知渠道 #%d", targetSub.City, id))
+	default:
+		return c.Send("❌ 未知操作\n用法: /channel [城市] add <类型> <目标> | delete <编号>")
+	}
+}
+
+// listChannels lists the additional channels of the given subscriptions
+func (h *Handlers) listChannels(c tele.Context, subs []model.Subscription, available []string) error {
+	ids := make([]uint, 0, len(subs))
+	for _, sub := range subs {
+		ids = append(ids, sub.ID)
+	}
+	bySub, err := h.channelRepo.FindBySubscriptionIDs(ids)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	var msg strings.Builder
+	msg.WriteString("📣 通知渠道\n")
+	for _, sub := range subs {
+		msg.WriteString(fmt.Sprintf("\n📍 %s\n   ✈️ Telegram（默认）\n", sub.City))
+		for _, ch := range bySub[sub.ID] {
+			msg.WriteString(fmt.Sprintf("   #%d %s：%s\n", ch.ID, channelDisplayName(ch.Type), maskChannelTarget(ch)))
+		}
+	}
+	msg.WriteString(fmt.Sprintf("\n可用渠道：%s\n💡 /channel <城市> add <类型> <目标>", strings.Join(available, "、")))
+	return c.Send(msg.String())
+}
+
+// addChannel validates and attaches a channel to a subscription
+func (h *Handlers) addChannel(c tele.Context, sub *model.Subscription, channelType, target string) error {
+	notifier, ok := h.notifySvc.Router().Get(channelType)
+	if !ok {
+		return c.Send(fmt.Sprintf("❌ 渠道 %s 未开启\n可用渠道：%s", channelType, strings.Join(h.notifySvc.Router().Channels(), "、")))
+	}
+	if err := notifier.ValidateTarget(target); err != nil {
+		return c.Send(fmt.Sprintf("❌ 目标格式错误：%s", channelTargetHint(channelType)))
+	}
+
+	existing, err := h.channelRepo.FindBySubscriptionID(sub.ID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(existing) >= h.maxChannels {
+		return c.Send(fmt.Sprintf("❌ 每个订阅最多添加 %d 个通知渠道", h.maxChannels))
+	}
+	for _, ch := range existing {
+		if ch.Type == channelType && ch.Target == target {
+			return c.Send("ℹ️ 该通知渠道已存在")
+		}
+	}
+
+	ch := &model.NotificationChannel{SubscriptionID: sub.ID, Type: channelType, Target: target}
+	if err := h.channelRepo.Create(ch); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	return c.Send(fmt.Sprintf("✅ 已为 %s 添加通知渠道 #%d（%s）\n每日提醒和天气预警将同时推送到该渠道", sub.City, ch.ID, channelDisplayName(channelType)))
+}
+
+// channelDisplayName returns a display name for a channel type
+func channelDisplayName(channelType string) string {
+	if name, ok := channelNames[channelType]; ok {
+		return name
+	}
+	return channelType
+}
+
+// channelTargetHint describes the expected target format of a channel type
+func channelTargetHint(channelType string) string {
+	switch channelType {
+	case notify.ChannelEmail:
+		return "请输入邮箱地址，如 me@example.com"
+	case notify.ChannelNtfy:
+		return "请输入 ntfy 主题名（字母、数字、-、_）"
+	case notify.ChannelBark:
+		return "请输入 Bark 设备 Key"
+	case notify.ChannelWeCom:
+		return "请输入企业微信群机器人 Webhook 地址中的 key"
+	case notify.ChannelDingTalk:
+		return "请输入钉钉机器人 access_token，开启加签时使用 access_token:SEC密钥"
+	default:
+		return "目标无效"
+	}
+}
+
+// maskChannelTarget hides most of a secret-like target (Bark keys, ntfy topics act as passwords)
+func maskChannelTarget(ch model.NotificationChannel) string {
+	if ch.Type == notify.ChannelEmail {
+		return ch.Target
+	}
+	runes := []rune(ch.Target)
+	if len(runes) <= 4 {
+		return "****"
+	}
+	return string(runes[:2]) + "****" + string(runes[len(runes)-2:])
+}
@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// btnCitySubscribe is the callback button that subscribes to a suggested city; data is the city name
+var btnCitySubscribe = &tele.Btn{Unique: "city_sub"}
+
+// HandleCities handles /cities [关键词]: suggests cities matching a (possibly misspelled or pinyin)
+// keyword, or lists popular cities, with a button per city to subscribe directly
+func (h *Handlers) HandleCities(c tele.Context) error {
+	keyword := strings.Join(c.Args(), " ")
+	cities, err := h.weatherSvc.SearchCities(keyword)
+	if err != nil {
+		logger.Error("Failed to search cities",
+			zap.Int64("chat_id", chatIDOf(c)),
+			zap.String("keyword", keyword),
+			zap.Error(err))
+		return c.Send("❌ 城市查询失败，请稍后再试")
+	}
+	if len(cities) == 0 {
+		return c.Send(fmt.Sprintf("🔍 未找到与「%s」相关的城市\n\n💡 可输入城市名的一部分或拼音，例如：/cities 苏州、/cities suzhou", keyword))
+	}
+
+	var b strings.Builder
+	if keyword == "" {
+		b.WriteString("🏙 热门城市：\n\n")
+	} else {
+		b.WriteString(fmt.Sprintf("🔍 与「%s」相关的城市：\n\n", keyword))
+	}
+	for i, city := range cities {
+		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, cityDisplayName(city)))
+	}
+	b.WriteString("\n点击下方按钮即可订阅该城市的每日提醒")
+	return c.Send(b.String(), citiesMarkup(cities))
+}
+
+// HandleCitySubscribe starts the guided subscription for a suggested city, asking for the time
+func (h *Handlers) HandleCitySubscribe(c tele.Context) error {
+	city := c.Data()
+	if city == "" {
+		return c.Respond()
+	}
+	if err := h.conversations.Start(botOf(c), chatIDOf(c), flowSubscribe, "time", map[string]string{"city": city}); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+	_ = c.Respond(&tele.CallbackResponse{Text: "📍 " + city})
+	return c.Send(subscribeTimePrompt(city))
+}
+
+// cityDisplayName shows a city with its province and country when they differ from its name
+func cityDisplayName(city qweather.GeoLocation) string {
+	var parts []string
+	if city.Adm2 != "" && city.Adm2 != city.Name {
+		parts = append(parts, city.Adm2)
+	}
+	if city.Adm1 != "" && city.Adm1 != city.Adm2 && city.Adm1 != city.Name+"市" {
+		parts = append(parts, city.Adm1)
+	}
+	if city.Country != "" && city.Country != "中国" {
+		parts = append(parts, city.Country)
+	}
+	if len(parts) == 0 {
+		return city.Name
+	}
+	return fmt.Sprintf("%s（%s）", city.Name, strings.Join(parts, "，"))
+}
+
+// citiesMarkup builds one subscribe button per suggested city, two per row
+func citiesMarkup(cities []qweather.GeoLocation) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	var rows []tele.Row
+	var row tele.Row
+	for _, city := range cities {
+		row = append(row, markup.Data("➕ "+cityDisplayName(city), btnCitySubscribe.Unique, city.Name))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	markup.Inline(rows...)
+	return markup
+}
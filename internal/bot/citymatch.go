@@ -0,0 +1,215 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// flowCityChoice asks which subscription an ambiguous city argument meant, then reruns the command
+const flowCityChoice = "city_choice"
+
+// Commands rerun by the city choice dialog once the user picked a subscription
+const (
+	cityChoiceTodo        = "todo"
+	cityChoiceUnsubscribe = "unsubscribe"
+	cityChoiceAI          = "ai"
+	cityChoicePin         = "pin"
+	cityChoiceOutdoor     = "outdoor"
+	cityChoiceIndex       = "index"
+	cityChoiceChannel     = "channel"
+)
+
+// normalizeSubscriptionName folds a city or label for matching: surrounding spaces and a trailing
+// "市" are dropped and pinyin is lowercased without spaces or apostrophes ("北京市" -> "北京",
+// "Xi'an" -> "xian")
+func normalizeSubscriptionName(name string) string {
+	name = strings.TrimSuffix(strings.TrimSpace(name), "市")
+	return strings.ToLower(strings.NewReplacer(" ", "", "'", "", "-", "").Replace(name))
+}
+
+// isPinyin reports whether a name is written in latin letters, as pinyin city names are
+func isPinyin(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || r == ' ' || r == '\'' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// matchSubscription finds the subscription a city argument refers to. The city or label is
+// matched exactly, then normalized ("北京市" = "北京"), then as pinyin via the city search; a single
+// hit is returned as the match. Several hits, or cities merely containing the argument ("北" in
+// "北京"), are returned as suggestions to ask about instead.
+func (h *Handlers) matchSubscription(subs []model.Subscription, name string) (*model.Subscription, []model.Subscription) {
+	matched := namedSubscriptions(subs, name)
+	if len(matched) == 0 && isPinyin(name) {
+		matched = h.matchPinyin(subs, name)
+	}
+	switch {
+	case len(matched) == 1:
+		return &matched[0], nil
+	case len(matched) > 1:
+		return nil, matched
+	}
+	return nil, similarSubscriptions(subs, name)
+}
+
+// targetSubscription resolves the subscription a "/command [城市] <选项>..." command acts on. The
+// first argument, unless it is one of the command's own options (isOption), is matched as a city
+// or label with matchSubscription; otherwise a user with a single subscription acts on it. It
+// returns the subscription and the remaining arguments, or nil once it has replied: asking which
+// city an ambiguous argument meant (rerunning command, see cityChoiceStep), or asking a user with
+// several subscriptions to name one, showing example.
+func (h *Handlers) targetSubscription(c tele.Context, command string, subs []model.Subscription, args []string, isOption func(arg string) bool, example string) (*model.Subscription, []string, error) {
+	if len(args) > 0 && !isOption(args[0]) {
+		sub, suggestions := h.matchSubscription(subs, args[0])
+		if sub != nil {
+			return sub, args[1:], nil
+		}
+		if len(suggestions) > 0 {
+			return nil, nil, h.askCityChoice(c, command, args[0], suggestions, args[1:])
+		}
+	}
+	if len(subs) == 1 {
+		return &subs[0], args, nil
+	}
+	return nil, nil, c.Send(fmt.Sprintf("❌ 请指定城市\n您的订阅：%s\n示例: %s", h.formatCityList(subs), example))
+}
+
+// namedSubscriptions returns the subscription whose city or label is exactly name, or else
+// those whose normalized city or label equals it
+func namedSubscriptions(subs []model.Subscription, name string) []model.Subscription {
+	for i := range subs {
+		if subs[i].MatchesName(name) {
+			return subs[i : i+1]
+		}
+	}
+	key := normalizeSubscriptionName(name)
+	if key == "" {
+		return nil
+	}
+	return filterSubscriptions(subs, func(sub *model.Subscription) bool {
+		return normalizeSubscriptionName(sub.City) == key ||
+			(sub.Label != "" && normalizeSubscriptionName(sub.Label) == key)
+	})
+}
+
+// similarSubscriptions returns the subscriptions whose city contains name or is contained in it,
+// or whose label contains it
+func similarSubscriptions(subs []model.Subscription, name string) []model.Subscription {
+	key := normalizeSubscriptionName(name)
+	if key == "" {
+		return nil
+	}
+	return filterSubscriptions(subs, func(sub *model.Subscription) bool {
+		city := normalizeSubscriptionName(sub.City)
+		return strings.Contains(city, key) || strings.Contains(key, city) ||
+			(sub.Label != "" && strings.Contains(normalizeSubscriptionName(sub.Label), key))
+	})
+}
+
+// matchPinyin returns the subscriptions whose city is among the cities found for a pinyin name
+func (h *Handlers) matchPinyin(subs []model.Subscription, name string) []model.Subscription {
+	cities, err := h.weatherSvc.SearchCities(name)
+	if err != nil {
+		logger.Warn("Failed to search cities for pinyin", zap.String("name", name), zap.Error(err))
+		return nil
+	}
+	found := make(map[string]bool, len(cities))
+	for _, city := range cities {
+		found[normalizeSubscriptionName(city.Name)] = true
+	}
+	return filterSubscriptions(subs, func(sub *model.Subscription) bool {
+		return found[normalizeSubscriptionName(sub.City)]
+	})
+}
+
+// filterSubscriptions returns the subscriptions matching keep
+func filterSubscriptions(subs []model.Subscription, keep func(sub *model.Subscription) bool) []model.Subscription {
+	var matched []model.Subscription
+	for i := range subs {
+		if keep(&subs[i]) {
+			matched = append(matched, subs[i])
+		}
+	}
+	return matched
+}
+
+// askCityChoice asks which of the suggested subscriptions a city argument meant, with a keyboard
+// button per city; the reply reruns command with the city followed by the remaining arguments
+func (h *Handlers) askCityChoice(c tele.Context, command, name string, suggestions []model.Subscription, rest []string) error {
+	data := map[string]string{"command": command, "args": strings.Join(rest, " ")}
+	if err := h.conversations.Start(botOf(c), chatIDOf(c), flowCityChoice, "pick", data); err != nil {
+		logger.Error("Failed to start city choice", zap.Int64("chat_id", chatIDOf(c)), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	markup := &tele.ReplyMarkup{ResizeKeyboard: true, OneTimeKeyboard: true}
+	var rows []tele.Row
+	var names []string
+	for _, sub := range suggestions {
+		rows = append(rows, markup.Row(markup.Text(sub.City)))
+		names = append(names, sub.DisplayName())
+	}
+	markup.Reply(rows...)
+	return c.Send(fmt.Sprintf("🤔 没有找到「%s」的订阅，您是指：%s？\n请点击下方按钮或发送城市名称（发送 /cancel 取消）", name, strings.Join(names, "、")), markup)
+}
+
+// cityChoiceStep receives the city picked in the city choice dialog and reruns the command
+func (h *Handlers) cityChoiceStep(c tele.Context, state *ConversationState) error {
+	user := userFrom(c)
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	sub, _ := h.matchSubscription(subs, strings.TrimSpace(c.Text()))
+	if sub == nil {
+		return c.Send(fmt.Sprintf("❌ 请发送您订阅的城市之一：%s（发送 /cancel 取消）", h.formatCityList(subs)))
+	}
+	state.Finish()
+
+	if err := c.Send("📍 "+sub.DisplayName(), &tele.ReplyMarkup{RemoveKeyboard: true}); err != nil {
+		return err
+	}
+	args := append([]string{sub.City}, strings.Fields(state.Data["args"])...)
+	switch command := state.Data["command"]; command {
+	case cityChoiceTodo:
+		return h.todo(c, args)
+	case cityChoiceUnsubscribe:
+		return h.unsubscribe(c, args)
+	case cityChoiceAI:
+		return rerunCommand(c, command, args, h.HandleAI)
+	case cityChoicePin:
+		return rerunCommand(c, command, args, h.HandlePin)
+	case cityChoiceOutdoor:
+		return rerunCommand(c, command, args, h.HandleOutdoor)
+	case cityChoiceIndex:
+		return rerunCommand(c, command, args, h.HandleIndex)
+	case cityChoiceChannel:
+		return rerunCommand(c, command, args, h.HandleChannel)
+	}
+	return nil
+}
+
+// rerunCommand runs handler as if "/command args..." had been sent, rewriting the message the
+// way command aliases are routed
+func rerunCommand(c tele.Context, command string, args []string, handler tele.HandlerFunc) error {
+	msg := c.Message()
+	if msg == nil {
+		return nil
+	}
+	msg.Payload = strings.Join(args, " ")
+	msg.Text = "/" + command + " " + msg.Payload
+	return handler(c)
+}
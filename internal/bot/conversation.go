@@ -0,0 +1,229 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// defaultConversationTimeout is how long a conversation waits for the next reply
+	defaultConversationTimeout = 10 * time.Minute
+	// conversationPruneInterval is the minimum time between purges of expired conversations
+	conversationPruneInterval = time.Hour
+)
+
+// ConversationState is the state of an active conversation passed to step handlers
+type ConversationState struct {
+	Flow string
+	Step string
+	Data map[string]string
+
+	finished bool
+}
+
+// Next moves the conversation to the given step
+func (s *ConversationState) Next(step string) {
+	s.Step = step
+}
+
+// Finish ends the conversation once the step handler returns
+func (s *ConversationState) Finish() {
+	s.finished = true
+}
+
+// StepHandler handles a reply sent while a conversation is at its step.
+// It advances with Next, ends the dialog with Finish, or leaves the state unchanged to ask again.
+type StepHandler func(c tele.Context, state *ConversationState) error
+
+// Flow describes a multi-step dialog
+type Flow struct {
+	Name    string
+	Timeout time.Duration // Idle timeout between replies (default: 10 minutes)
+	Steps   map[string]StepHandler
+}
+
+// Conversations runs multi-step dialogs. State is stored in the database per chat so that
+// a dialog survives restarts; a chat has at most one active conversation.
+type Conversations struct {
+	repo  *repository.ConversationRepository
+	flows map[string]Flow
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewConversations creates a new Conversations instance
+func NewConversations(repo *repository.ConversationRepository) *Conversations {
+	return &Conversations{
+		repo:  repo,
+		flows: make(map[string]Flow),
+	}
+}
+
+// Register adds a flow that can be started with Start
+func (m *Conversations) Register(flow Flow) {
+	if flow.Timeout <= 0 {
+		flow.Timeout = defaultConversationTimeout
+	}
+	m.flows[flow.Name] = flow
+}
+
+// Start begins a flow at the given step, replacing any conversation the chat already had with
+// the bot. The caller is responsible for sending the first prompt.
+func (m *Conversations) Start(bot string, chatID int64, flowName, step string, data map[string]string) error {
+	flow, ok := m.flows[flowName]
+	if !ok {
+		return fmt.Errorf("unknown conversation flow: %s", flowName)
+	}
+	if _, ok := flow.Steps[step]; !ok {
+		return fmt.Errorf("unknown step %q in conversation flow %s", step, flowName)
+	}
+
+	m.pruneExpired()
+
+	if data == nil {
+		data = map[string]string{}
+	}
+	return m.save(bot, chatID, flow, &ConversationState{Flow: flowName, Step: step, Data: data})
+}
+
+// Cancel ends the chat's conversation with the bot, reporting whether one was active
+func (m *Conversations) Cancel(bot string, chatID int64) (bool, error) {
+	return m.repo.Delete(bot, chatID)
+}
+
+// Handle passes a reply to the chat's active conversation.
+// It reports false when the chat has no conversation, leaving the message to other handlers.
+func (m *Conversations) Handle(c tele.Context) (bool, error) {
+	bot, chatID := botOf(c), chatIDOf(c)
+	conv, err := m.repo.FindByChatID(bot, chatID)
+	if err != nil {
+		return false, err
+	}
+	if conv == nil {
+		return false, nil
+	}
+
+	flow, ok := m.flows[conv.Flow]
+	handler := flow.Steps[conv.Step]
+	if !ok || handler == nil {
+		// The flow was renamed or removed by an upgrade
+		logger.Warn("Dropping conversation with unknown flow or step",
+			zap.Int64("chat_id", chatID),
+			zap.String("flow", conv.Flow),
+			zap.String("step", conv.Step))
+		_, _ = m.repo.Delete(bot, chatID)
+		return true, c.Send("⚠️ 之前的操作已失效，请重新开始。")
+	}
+
+	if time.Now().After(conv.ExpiresAt) {
+		logger.Debug("Conversation timed out",
+			zap.Int64("chat_id", chatID),
+			zap.String("flow", conv.Flow),
+			zap.String("step", conv.Step))
+		if _, err := m.repo.Delete(bot, chatID); err != nil {
+			return true, err
+		}
+		return true, c.Send("⌛ 上一个操作已超时并自动取消，请重新开始。")
+	}
+
+	state := &ConversationState{Flow: conv.Flow, Step: conv.Step, Data: map[string]string{}}
+	if conv.Data != "" {
+		if err := json.Unmarshal([]byte(conv.Data), &state.Data); err != nil {
+			logger.Warn("Failed to decode conversation data",
+				zap.Int64("chat_id", chatID),
+				zap.String("flow", conv.Flow),
+				zap.Error(err))
+		}
+	}
+	if state.Data == nil {
+		state.Data = map[string]string{}
+	}
+
+	handlerErr := handler(c, state)
+
+	if state.finished {
+		if _, err := m.repo.Delete(bot, chatID); err != nil {
+			return true, err
+		}
+		return true, handlerErr
+	}
+	if err := m.save(bot, chatID, flow, state); err != nil {
+		return true, err
+	}
+	return true, handlerErr
+}
+
+// save persists the state and restarts the idle timeout
+func (m *Conversations) save(bot string, chatID int64, flow Flow, state *ConversationState) error {
+	data, err := json.Marshal(state.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation data: %w", err)
+	}
+	return m.repo.Save(&model.Conversation{
+		Bot:       bot,
+		ChatID:    chatID,
+		Flow:      state.Flow,
+		Step:      state.Step,
+		Data:      string(data),
+		ExpiresAt: time.Now().Add(flow.Timeout),
+	})
+}
+
+// pruneExpired removes abandoned conversations, at most once per conversationPruneInterval
+func (m *Conversations) pruneExpired() {
+	m.mu.Lock()
+	if time.Since(m.lastPrune) < conversationPruneInterval {
+		m.mu.Unlock()
+		return
+	}
+	m.lastPrune = time.Now()
+	m.mu.Unlock()
+
+	_ = m.repo.DeleteExpired(time.Now())
+}
+
+// HandleText routes plain text replies to the sender's active conversation, and otherwise texts
+// starting with a command alias ("天气 北京") to the command
+func (h *Handlers) HandleText(c tele.Context) error {
+	// Unknown commands are not conversation replies, but may be aliases ("/tq")
+	if strings.HasPrefix(c.Text(), "/") {
+		return h.routeAlias(c)
+	}
+	// "取消" ends the conversation rather than answering it
+	if strings.TrimSpace(c.Text()) == "取消" {
+		return h.routeAlias(c)
+	}
+	handled, err := h.conversations.Handle(c)
+	if err != nil {
+		logger.Error("Failed to handle conversation reply",
+			zap.Int64("chat_id", chatIDOf(c)),
+			zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if !handled {
+		return h.routeAlias(c)
+	}
+	return nil
+}
+
+// HandleCancel handles the /cancel command, ending the active conversation
+func (h *Handlers) HandleCancel(c tele.Context) error {
+	cancelled, err := h.conversations.Cancel(botOf(c), chatIDOf(c))
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if !cancelled {
+		return c.Send("当前没有进行中的操作。")
+	}
+	return c.Send("🚫 已取消当前操作。")
+}
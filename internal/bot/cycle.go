@@ -0,0 +1,194 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/timeparse"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// healthLabelMaxLen limits the label of a health reminder, in characters
+const healthLabelMaxLen = 32
+
+// healthKinds maps the kind words accepted by /cycle add to health reminder kinds
+var healthKinds = map[string]string{
+	"cycle": service.HealthKindCycle, "周期": service.HealthKindCycle, "经期": service.HealthKindCycle,
+	"med": service.HealthKindMedication, "medication": service.HealthKindMedication, "服药": service.HealthKindMedication, "药": service.HealthKindMedication,
+}
+
+// cycleUsage is the usage of the /cycle command
+const cycleUsage = `用法:
+/cycle add cycle <周期天数> <开始日期> <时间> [名称] - 周期提醒（预计开始前 2 天和当天提醒）
+  示例: /cycle add cycle 28 2026-10-01 08:00
+/cycle add med <间隔天数> <开始日期> <时间> [名称] - 服药提醒（每隔 N 天提醒）
+  示例: /cycle add med 1 今天 21:30 维生素
+/cycle start <编号> [日期] - 更新开始日期（默认今天）
+/cycle delete <编号> - 删除提醒
+
+🔒 提醒内容加密保存，只在私聊中单独发送，且无法转发`
+
+// HandleCycle handles the /cycle command, managing private health reminders. It only works in
+// private chats so that health data never appears in groups.
+func (h *Handlers) HandleCycle(c tele.Context) error {
+	if h.healthSvc == nil {
+		return c.Send("❌ 私人提醒功能未开启，请联系管理员")
+	}
+	if c.Chat() == nil || c.Chat().Type != tele.ChatPrivate {
+		return c.Send("🔒 为保护隐私，请在与机器人的私聊中使用 /cycle")
+	}
+	user := userFrom(c)
+	private := &tele.SendOptions{Protected: true}
+
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(h.cycleList(user.ID), private)
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "add":
+		return h.handleCycleAdd(c, user.ID, args[1:], private)
+	case "start":
+		if len(args) < 2 {
+			return c.Send("用法: /cycle start <编号> [日期]")
+		}
+		date := time.Now().In(h.timezone)
+		if len(args) > 2 {
+			parsed, err := h.parseHealthDate(args[2])
+			if err != nil {
+				return c.Send("❌ 日期格式错误，请使用 2026-10-01、10-01 或 今天")
+			}
+			date = parsed
+		}
+		id, ok := h.cycleEntryID(user.ID, args[1])
+		if !ok {
+			return c.Send("❌ 编号无效，发送 /cycle 查看提醒列表")
+		}
+		if _, err := h.healthSvc.Restart(user.ID, id, date.Format("2006-01-02")); err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		return c.Send(fmt.Sprintf("✅ 已将开始日期更新为 %s", date.Format("2006-01-02")), private)
+	case "delete":
+		if len(args) < 2 {
+			return c.Send("用法: /cycle delete <编号>")
+		}
+		id, ok := h.cycleEntryID(user.ID, args[1])
+		if !ok {
+			return c.Send("❌ 编号无效，发送 /cycle 查看提醒列表")
+		}
+		if _, err := h.healthSvc.Delete(user.ID, id); err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		logger.Info("Health reminder deleted", zap.Uint("user_id", user.ID))
+		return c.Send("✅ 已删除该提醒")
+	default:
+		return c.Send(cycleUsage)
+	}
+}
+
+// handleCycleAdd handles /cycle add <kind> <days> <start> <time> [label]
+func (h *Handlers) handleCycleAdd(c tele.Context, userID uint, args []string, private *tele.SendOptions) error {
+	if len(args) < 4 {
+		return c.Send(cycleUsage)
+	}
+	kind, ok := healthKinds[strings.ToLower(args[0])]
+	if !ok {
+		return c.Send("❌ 类型只能是 cycle（周期）或 med（服药）")
+	}
+
+	minDays, maxDays := 1, 90
+	if kind == service.HealthKindCycle {
+		minDays, maxDays = 15, 60
+	}
+	days, err := strconv.Atoi(args[1])
+	if err != nil || days < minDays || days > maxDays {
+		return c.Send(fmt.Sprintf("❌ 天数应为 %d 到 %d 之间的整数", minDays, maxDays))
+	}
+	start, err := h.parseHealthDate(args[2])
+	if err != nil {
+		return c.Send("❌ 日期格式错误，请使用 2026-10-01、10-01 或 今天")
+	}
+	reminderTime, err := timeparse.Parse(args[3])
+	if err != nil {
+		return c.Send("❌ 时间格式错误，例如 08:00 或 晚上9点")
+	}
+	label := strings.Join(args[4:], " ")
+	if utf8.RuneCountInString(label) > healthLabelMaxLen {
+		return c.Send(fmt.Sprintf("❌ 名称最多 %d 个字", healthLabelMaxLen))
+	}
+
+	plan := service.HealthPlan{Kind: kind, Label: label, CycleDays: days, StartDate: start.Format("2006-01-02")}
+	if err := h.healthSvc.Add(userID, reminderTime, plan); err != nil {
+		if errors.Is(err, service.ErrHealthReminderLimit) {
+			return c.Send(fmt.Sprintf("❌ 最多只能设置 %d 个私人提醒", h.healthSvc.MaxPerUser()))
+		}
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Health reminder added", zap.Uint("user_id", userID))
+
+	next := plan.Next(time.Now().In(h.timezone))
+	return c.Send(fmt.Sprintf("✅ 已添加私人提醒「%s」，每天 %s 检查，下次：%s", plan.DisplayLabel(), reminderTime, next), private)
+}
+
+// cycleList lists the user's health reminders with the command usage
+func (h *Handlers) cycleList(userID uint) string {
+	entries, err := h.healthSvc.List(userID)
+	if err != nil {
+		return "抱歉,系统出现错误,请稍后再试。"
+	}
+	if len(entries) == 0 {
+		return "🔒 私人提醒\n\n暂无提醒\n\n" + cycleUsage
+	}
+
+	now := time.Now().In(h.timezone)
+	var list strings.Builder
+	list.WriteString("🔒 私人提醒\n\n")
+	for i, entry := range entries {
+		kind := "服药"
+		if entry.Plan.Kind == service.HealthKindCycle {
+			kind = "周期"
+		}
+		list.WriteString(fmt.Sprintf("%d. 「%s」%s · 每 %d 天 · %s · 下次 %s\n",
+			i+1, entry.Plan.DisplayLabel(), kind, entry.Plan.CycleDays, entry.ReminderTime, entry.Plan.Next(now)))
+	}
+	return list.String() + "\n" + cycleUsage
+}
+
+// cycleEntryID resolves a list number to the ID of the user's health reminder
+func (h *Handlers) cycleEntryID(userID uint, number string) (uint, bool) {
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	entries, err := h.healthSvc.List(userID)
+	if err != nil || n > len(entries) {
+		return 0, false
+	}
+	return entries[n-1].ID, true
+}
+
+// parseHealthDate parses 今天, YYYY-MM-DD or MM-DD (this year) in the bot's timezone
+func (h *Handlers) parseHealthDate(s string) (time.Time, error) {
+	now := time.Now().In(h.timezone)
+	switch s {
+	case "今天", "today":
+		return now, nil
+	case "昨天", "yesterday":
+		return now.AddDate(0, 0, -1), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, h.timezone); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("01-02", s, h.timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(now.Year(), t.Month(), t.Day(), 0, 0, 0, 0, h.timezone), nil
+}
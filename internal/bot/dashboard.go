@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// registerDashboardHandlers registers /dashboard when the web dashboard is enabled
+func (h *Handlers) registerDashboardHandlers(bot *tele.Bot) {
+	if h.dashboardSvc == nil {
+		return
+	}
+	bot.Handle("/dashboard", h.HandleDashboard)
+}
+
+// HandleDashboard handles /dashboard: issues a one-time code for logging in to the web dashboard
+func (h *Handlers) HandleDashboard(c tele.Context) error {
+	if c.Chat() == nil || c.Chat().Type != tele.ChatPrivate {
+		return c.Send("🔒 为保护隐私，请在与机器人的私聊中使用 /dashboard")
+	}
+	user := userFrom(c)
+	code, _, err := h.dashboardSvc.IssueCode(user, time.Now())
+	if err != nil {
+		logger.Error("Failed to issue dashboard login code", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	text := fmt.Sprintf("🖥 网页面板登录码：<code>%s</code>\n\n", code)
+	if url := h.dashboardSvc.URL(); url != "" {
+		text += fmt.Sprintf("在浏览器打开 %s 并输入登录码，即可查看您的订阅和待办。\n", url)
+	} else {
+		text += "在网页面板输入登录码，即可查看您的订阅和待办。\n"
+	}
+	text += "登录码 5 分钟内有效且只能使用一次，再次发送 /dashboard 会使旧登录码失效。\n⚠️ 请勿将登录码告诉他人。"
+	return c.Send(text, tele.ModeHTML)
+}
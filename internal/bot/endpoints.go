@@ -0,0 +1,304 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// endpointProbeTimeout bounds each getMe health probe
+const endpointProbeTimeout = 5 * time.Second
+
+// EndpointPool routes Bot API requests to one of several endpoints (e.g. api.telegram.org and a
+// self-hosted telegram-bot-api server or a reverse proxy), in order of preference. Requests fail
+// over to the next endpoint on network errors and gateway errors (502/503/504); a background
+// getMe probe marks endpoints healthy again, so the preferred endpoint is used once it recovers.
+type EndpointPool struct {
+	endpoints     []*apiEndpoint
+	token         string
+	transport     http.RoundTripper
+	probeInterval time.Duration
+
+	mu     sync.RWMutex
+	active int // Index of the endpoint requests go to first
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// apiEndpoint is a Bot API endpoint with its last known health
+type apiEndpoint struct {
+	base    string // Base URL without trailing slash, e.g. "https://api.telegram.org"
+	healthy bool
+	lastErr string
+}
+
+// EndpointStatus is the health of an endpoint of an EndpointPool
+type EndpointStatus struct {
+	URL     string
+	Healthy bool
+	Active  bool
+	Error   string // Last failure, if unhealthy
+}
+
+// NewEndpointPool creates a pool of Bot API endpoints in order of preference, probed every
+// probeInterval once started
+func NewEndpointPool(urls []string, token string, probeInterval time.Duration) (*EndpointPool, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no Bot API endpoints")
+	}
+	endpoints := make([]*apiEndpoint, 0, len(urls))
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid Bot API endpoint %q", raw)
+		}
+		endpoints = append(endpoints, &apiEndpoint{base: strings.TrimRight(raw, "/"), healthy: true})
+	}
+	return &EndpointPool{
+		endpoints:     endpoints,
+		token:         token,
+		transport:     http.DefaultTransport,
+		probeInterval: probeInterval,
+	}, nil
+}
+
+// URL returns the URL the bot is configured with; requests to it are routed to the active endpoint
+func (p *EndpointPool) URL() string {
+	return p.endpoints[0].base
+}
+
+// Client returns an HTTP client sending requests through the pool
+func (p *EndpointPool) Client(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: p, Timeout: timeout}
+}
+
+// Start probes the endpoints in the background until Stop is called
+func (p *EndpointPool) Start() {
+	p.stop = make(chan struct{})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.probeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.Probe(context.Background())
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops probing
+func (p *EndpointPool) Stop() {
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+	p.stop = nil
+}
+
+// Status returns the health of each endpoint in order of preference
+func (p *EndpointPool) Status() []EndpointStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	statuses := make([]EndpointStatus, 0, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		statuses = append(statuses, EndpointStatus{URL: ep.base, Healthy: ep.healthy, Active: i == p.active, Error: ep.lastErr})
+	}
+	return statuses
+}
+
+// Probe calls getMe on every endpoint and switches to the most preferred healthy one
+func (p *EndpointPool) Probe(ctx context.Context) {
+	results := make([]error, len(p.endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range p.endpoints {
+		wg.Add(1)
+		go func(i int, base string) {
+			defer wg.Done()
+			results[i] = p.probe(ctx, base)
+		}(i, ep.base)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, err := range results {
+		p.setHealth(i, err)
+	}
+	for i, ep := range p.endpoints {
+		if ep.healthy {
+			p.activate(i)
+			break
+		}
+	}
+}
+
+// probe checks an endpoint with getMe
+func (p *EndpointPool) probe(ctx context.Context, base string) error {
+	ctx, cancel := context.WithTimeout(ctx, endpointProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/bot"+p.token+"/getMe", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<16)).Decode(&result); err != nil {
+		return fmt.Errorf("HTTP %d: invalid response", resp.StatusCode)
+	}
+	if !result.OK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, result.Description)
+	}
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper: it sends the request to the active endpoint and, when
+// the body can be replayed, retries on the next endpoints after a network or gateway error
+func (p *EndpointPool) RoundTrip(req *http.Request) (*http.Response, error) {
+	path, ok := strings.CutPrefix(req.URL.String(), p.endpoints[0].base)
+	if !ok {
+		return p.transport.RoundTrip(req)
+	}
+
+	p.mu.RLock()
+	start := p.active
+	p.mu.RUnlock()
+
+	var lastResp *http.Response
+	var lastErr error
+	for n := 0; n < len(p.endpoints); n++ {
+		i := (start + n) % len(p.endpoints)
+		if n > 0 {
+			// Multipart uploads stream their body and cannot be sent twice
+			if req.Body != nil && req.GetBody == nil {
+				break
+			}
+			if req.Context().Err() != nil {
+				break
+			}
+		}
+
+		attempt, err := p.rewrite(req, p.endpoints[i].base+path)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := p.transport.RoundTrip(attempt)
+		if err == nil && !isGatewayError(resp.StatusCode) {
+			if n > 0 {
+				p.mu.Lock()
+				p.activate(i)
+				p.mu.Unlock()
+			}
+			return resp, nil
+		}
+
+		if err == nil {
+			err = fmt.Errorf("HTTP %d", resp.StatusCode)
+			if lastResp != nil {
+				_ = lastResp.Body.Close()
+			}
+			lastResp = resp
+		}
+		lastErr = err
+		if errors.Is(err, context.Canceled) {
+			// The bot is stopping or the caller gave up; the endpoint is not at fault
+			break
+		}
+		p.mu.Lock()
+		p.setHealth(i, err)
+		p.mu.Unlock()
+	}
+
+	if lastResp != nil {
+		return lastResp, nil
+	}
+	return nil, lastErr
+}
+
+// rewrite returns a copy of req sent to target, with a fresh body for retries
+func (p *EndpointPool) rewrite(req *http.Request, target string) (*http.Request, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	attempt := req.Clone(req.Context())
+	attempt.URL = u
+	attempt.Host = ""
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		attempt.Body = body
+	}
+	return attempt, nil
+}
+
+// setHealth records the result of a request or probe of an endpoint; p.mu must be held
+func (p *EndpointPool) setHealth(i int, err error) {
+	ep := p.endpoints[i]
+	healthy := err == nil
+	if healthy != ep.healthy {
+		if healthy {
+			logger.Info("Bot API endpoint recovered", zap.String("endpoint", logger.MaskURL(ep.base)))
+		} else {
+			logger.Warn("Bot API endpoint unhealthy", zap.String("endpoint", logger.MaskURL(ep.base)), zap.Error(err))
+		}
+	}
+	ep.healthy = healthy
+	ep.lastErr = ""
+	if err != nil {
+		ep.lastErr = err.Error()
+	}
+	if !healthy && i == p.active {
+		for n := 1; n < len(p.endpoints); n++ {
+			next := (i + n) % len(p.endpoints)
+			if p.endpoints[next].healthy {
+				p.activate(next)
+				return
+			}
+		}
+	}
+}
+
+// activate makes an endpoint the first one requests go to; p.mu must be held
+func (p *EndpointPool) activate(i int) {
+	if i == p.active {
+		return
+	}
+	logger.Warn("Switching Bot API endpoint",
+		zap.String("from", logger.MaskURL(p.endpoints[p.active].base)),
+		zap.String("to", logger.MaskURL(p.endpoints[i].base)))
+	p.active = i
+}
+
+// isGatewayError reports whether a status means the endpoint, rather than the request, failed
+func isGatewayError(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
@@ -0,0 +1,110 @@
+package bot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/export"
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// exportFormatUsage is sent when an unknown export format is requested
+const exportFormatUsage = "❌ 不支持的格式，可选：csv（默认）、md"
+
+// exportTodos sends all todos of a subscription, completed ones included, as a document
+func (h *Handlers) exportTodos(c tele.Context, sub *model.Subscription, formatName string) error {
+	format, ok := export.ParseFormat(formatName)
+	if !ok {
+		return c.Send(exportFormatUsage)
+	}
+
+	todos, err := h.todoSvc.GetSubscriptionTodos(sub.TodoListID())
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(todos) == 0 {
+		return c.Send(fmt.Sprintf("📝 %s - 暂无待办事项可导出", sub.City))
+	}
+
+	name := fmt.Sprintf("todos-%s-%s", sub.City, time.Now().In(h.timezone).Format("20060102"))
+	return h.sendExport(c, format, name, fmt.Sprintf("📤 %s 待办事项（共 %d 项）", sub.City, len(todos)),
+		export.TodoTable(sub.City, todos, h.timezone))
+}
+
+// HandleExport handles the /export [csv|md] command, sending all of the user's subscriptions and todos
+func (h *Handlers) HandleExport(c tele.Context) error {
+	user := userFrom(c)
+
+	var formatName string
+	if args := c.Args(); len(args) > 0 {
+		formatName = args[0]
+	}
+	format, ok := export.ParseFormat(formatName)
+	if !ok {
+		return c.Send(exportFormatUsage)
+	}
+
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市，暂无数据可导出")
+	}
+
+	todos := make(map[uint][]model.Todo, len(subs))
+	for _, sub := range subs {
+		subTodos, err := h.todoSvc.GetSubscriptionTodos(sub.TodoListID())
+		if err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		todos[sub.ID] = subTodos
+	}
+
+	name := "daily-reminder-" + time.Now().In(h.timezone).Format("20060102")
+	return h.sendExport(c, format, name, "📤 我的订阅与待办数据",
+		export.SubscriptionTable(subs, h.timezone),
+		export.AllTodosTable(subs, todos, h.timezone))
+}
+
+// sendExport renders tables and sends them as a document
+func (h *Handlers) sendExport(c tele.Context, format export.Format, name, caption string, tables ...export.Table) error {
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, tables...); err != nil {
+		logger.Error("Failed to render export",
+			zap.Int64("chat_id", chatIDOf(c)),
+			zap.String("format", string(format)),
+			zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	doc := notify.Document{
+		Data:     buf.Bytes(),
+		FileName: format.FileName(name),
+		MIME:     format.MIME(),
+		Caption:  caption,
+	}
+	if err := h.notifySvc.SendDocument(botOf(c), c.Recipient(), doc); err != nil {
+		logger.Error("Failed to send export",
+			zap.Int64("chat_id", chatIDOf(c)),
+			zap.Int("bytes", buf.Len()),
+			zap.Error(err))
+		if errors.Is(err, notify.ErrFileTooLarge) {
+			return c.Send("❌ 导出文件过大，请联系管理员")
+		}
+		return err
+	}
+
+	logger.Info("Data exported",
+		zap.Int64("chat_id", chatIDOf(c)),
+		zap.String("file", doc.FileName),
+		zap.Int("bytes", buf.Len()))
+	return nil
+}
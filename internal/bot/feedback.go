@@ -0,0 +1,42 @@
+package bot
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// maxFeedbackRunes bounds the length of a /feedback message
+const maxFeedbackRunes = 1000
+
+// HandleFeedback handles /feedback <内容>: stores the user's opinion of the reminders, which
+// also counts towards the format experiments they take part in
+func (h *Handlers) HandleFeedback(c tele.Context) error {
+	if h.experiments == nil {
+		return c.Send("❌ 反馈功能未开启")
+	}
+	text := strings.TrimSpace(c.Message().Payload)
+	if text == "" {
+		return c.Send("💬 用法：/feedback <内容>\n例如：/feedback 提醒很实用，就是有点长")
+	}
+	if utf8.RuneCountInString(text) > maxFeedbackRunes {
+		return c.Send("❌ 反馈内容过长，请控制在 1000 字以内")
+	}
+
+	user := userFrom(c)
+	sentiment, err := h.experiments.SubmitFeedback(user.ID, text)
+	if err != nil {
+		logger.Error("Failed to save feedback", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	switch {
+	case sentiment > 0:
+		return c.Send("🙏 感谢反馈，很高兴提醒对你有帮助！")
+	case sentiment < 0:
+		return c.Send("🙏 感谢反馈，我们会继续改进提醒内容。")
+	}
+	return c.Send("🙏 感谢反馈！")
+}
@@ -0,0 +1,269 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/calendar"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// Limits of custom festivals
+const (
+	maxCustomFestivals     = 20 // Custom festivals per user
+	customFestivalNameMax  = 20 // Characters of a custom festival name
+	customFestivalListHint = "发送 /festivals add <名称> <日期> 添加自己的节日"
+)
+
+// SetCustomFestivals enables custom festivals in /festivals; calendarSvc computes their next dates
+func (h *Handlers) SetCustomFestivals(repo *repository.CustomFestivalRepository, calendarSvc *service.CalendarService) {
+	h.festivalRepo = repo
+	h.calendarSvc = calendarSvc
+}
+
+// HandleFestivals handles the /festivals [<类别>... on|off|reset | add <名称> <日期> | delete <编号>]
+// command, choosing which festival categories the calendar of the user's daily reminders shows
+// and managing the user's own festivals
+func (h *Handlers) HandleFestivals(c tele.Context) error {
+	user := userFrom(c)
+	hidden := calendar.ParseCategories(user.HiddenFestivals)
+
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(h.festivalsStatus(user, hidden))
+	}
+	switch strings.ToLower(args[0]) {
+	case "add", "添加":
+		return h.addCustomFestival(c, user, args[1:])
+	case "delete", "del", "删除":
+		return h.deleteCustomFestival(c, user, args[1:])
+	}
+
+	var categories []calendar.FestivalCategory
+	var action string
+	for _, arg := range args {
+		switch lower := strings.ToLower(arg); lower {
+		case "on", "开", "显示", "off", "关", "隐藏", "reset", "all", "全部":
+			action = lower
+			continue
+		}
+		category, ok := calendar.ParseCategory(arg)
+		if !ok {
+			return c.Send(fmt.Sprintf("❌ 无法识别的节日类别「%s」\n\n%s", arg, festivalsUsage()))
+		}
+		categories = append(categories, category)
+	}
+
+	var reply string
+	switch action {
+	case "reset", "all", "全部":
+		hidden = nil
+		reply = "✅ 每日提醒将显示全部节日类别"
+	case "on", "开", "显示", "off", "关", "隐藏":
+		if len(categories) == 0 {
+			return c.Send(festivalsUsage())
+		}
+		off := action == "off" || action == "关" || action == "隐藏"
+		for _, category := range categories {
+			hidden = withoutCategory(hidden, category)
+			if off {
+				hidden = append(hidden, category)
+			}
+		}
+		names := make([]string, 0, len(categories))
+		for _, category := range categories {
+			names = append(names, category.Name())
+		}
+		if off {
+			reply = fmt.Sprintf("🔕 每日提醒将不再显示%s", strings.Join(names, "、"))
+		} else {
+			reply = fmt.Sprintf("✅ 每日提醒将显示%s", strings.Join(names, "、"))
+		}
+	default:
+		return c.Send(festivalsUsage())
+	}
+
+	value := calendar.JoinCategories(hidden)
+	if err := h.userRepo.SetHiddenFestivals(user.ID, value); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Festival categories updated",
+		zap.Uint("user_id", user.ID),
+		zap.String("hidden", value))
+	return c.Send(reply + "\n\n" + h.festivalsStatus(user, calendar.ParseCategories(value)))
+}
+
+// addCustomFestival adds a festival of the user from "<名称> <日期>", the date being solar
+// ("3-15") or lunar ("农历二月初二", also written "农历 二月初二")
+func (h *Handlers) addCustomFestival(c tele.Context, user *model.User, args []string) error {
+	if h.festivalRepo == nil {
+		return c.Send("❌ 自定义节日功能未开启")
+	}
+	if len(args) >= 3 && args[len(args)-2] == "农历" {
+		args = append(args[:len(args)-2:len(args)-2], "农历"+args[len(args)-1])
+	}
+	if len(args) < 2 {
+		return c.Send("用法: /festivals add <名称> <日期>\n示例: /festivals add 公司周年庆 3-15\n/festivals add 家乡庙会 农历二月初二")
+	}
+	month, day, lunar, err := calendar.ParseCustomDate(args[len(args)-1])
+	if err != nil {
+		return c.Send("❌ 无法识别日期，支持如 3-15、3月15日、农历2-2、农历二月初二")
+	}
+	name := strings.Join(args[:len(args)-1], " ")
+	if reason := invalidFestivalName(name); reason != "" {
+		return c.Send("❌ " + reason)
+	}
+
+	existing, err := h.festivalRepo.FindByUser(user.ID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(existing) >= maxCustomFestivals {
+		return c.Send(fmt.Sprintf("❌ 最多添加 %d 个自己的节日，可先用 /festivals delete <编号> 删除", maxCustomFestivals))
+	}
+	festival := &model.CustomFestival{UserID: user.ID, Name: name, Lunar: lunar, Month: month, Day: day}
+	if err := h.festivalRepo.Create(festival); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Custom festival added",
+		zap.Uint("user_id", user.ID),
+		zap.Uint("festival_id", festival.ID),
+		zap.Bool("lunar", lunar))
+
+	custom := service.CustomFestivalOf(*festival)
+	reply := fmt.Sprintf("✅ 已添加 ⭐ %s（每年%s）", name, custom.DateString())
+	if _, days := h.calendarSvc.NextCustomFestival(custom, time.Now().In(h.timezone)); days >= 0 {
+		reply += fmt.Sprintf("，还有 %d 天", days)
+	}
+	return c.Send(reply + "\n将与其他节日一起出现在每日提醒的日历中")
+}
+
+// deleteCustomFestival deletes a festival of the user by its number in the /festivals list
+func (h *Handlers) deleteCustomFestival(c tele.Context, user *model.User, args []string) error {
+	if h.festivalRepo == nil {
+		return c.Send("❌ 自定义节日功能未开启")
+	}
+	if len(args) != 1 {
+		return c.Send("用法: /festivals delete <编号>")
+	}
+	index, err := strconv.Atoi(args[0])
+	if err != nil || index < 1 {
+		return c.Send("❌ 请输入有效的编号")
+	}
+	festivals, err := h.festivalRepo.FindByUser(user.ID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if index > len(festivals) {
+		return c.Send("❌ 没有这个编号的节日，发送 /festivals 查看列表")
+	}
+	festival := festivals[index-1]
+	if _, err := h.festivalRepo.Delete(user.ID, festival.ID); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Custom festival deleted",
+		zap.Uint("user_id", user.ID),
+		zap.Uint("festival_id", festival.ID))
+	return c.Send(fmt.Sprintf("🗑 已删除 %s", festival.Name))
+}
+
+// invalidFestivalName returns why a custom festival name is rejected, or "" when it is valid.
+// Names are shown in reminders and fed to the AI, so only short plain text is accepted.
+func invalidFestivalName(name string) string {
+	if name == "" {
+		return "节日名称不能为空"
+	}
+	if utf8.RuneCountInString(name) > customFestivalNameMax {
+		return fmt.Sprintf("节日名称最多 %d 个字", customFestivalNameMax)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) || strings.ContainsRune("<>【】/\\", r) {
+			return "节日名称不能包含特殊符号"
+		}
+	}
+	if strings.Contains(strings.ToLower(name), "http") {
+		return "节日名称不能包含链接"
+	}
+	return ""
+}
+
+// withoutCategory returns the categories except c
+func withoutCategory(list []calendar.FestivalCategory, c calendar.FestivalCategory) []calendar.FestivalCategory {
+	var out []calendar.FestivalCategory
+	for _, item := range list {
+		if item != c {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// festivalsStatus lists which festival categories the user's calendar shows and the user's own
+// festivals, with the usage
+func (h *Handlers) festivalsStatus(user *model.User, hidden []calendar.FestivalCategory) string {
+	var b strings.Builder
+	b.WriteString("📅 每日提醒显示的节日类别：\n")
+	for _, category := range calendar.Categories {
+		mark := "✅"
+		for _, h := range hidden {
+			if h == category {
+				mark = "🚫"
+				break
+			}
+		}
+		b.WriteString(fmt.Sprintf("%s %s\n", mark, category.Name()))
+	}
+	if h.festivalRepo != nil {
+		b.WriteString("\n" + h.customFestivalList(user))
+	}
+	b.WriteString("\n")
+	b.WriteString(festivalsUsage())
+	return b.String()
+}
+
+// customFestivalList lists the user's own festivals with their dates and countdowns
+func (h *Handlers) customFestivalList(user *model.User) string {
+	festivals, err := h.festivalRepo.FindByUser(user.ID)
+	if err != nil {
+		logger.Warn("Failed to list custom festivals", zap.Uint("user_id", user.ID), zap.Error(err))
+		return "⭐ 我的节日：暂时无法获取\n"
+	}
+	if len(festivals) == 0 {
+		return "⭐ 我的节日：暂无\n" + customFestivalListHint + "\n"
+	}
+	var b strings.Builder
+	b.WriteString("⭐ 我的节日：\n")
+	now := time.Now().In(h.timezone)
+	for i, row := range festivals {
+		custom := service.CustomFestivalOf(row)
+		line := fmt.Sprintf("%d. %s - 每年%s", i+1, row.Name, custom.DateString())
+		if _, days := h.calendarSvc.NextCustomFestival(custom, now); days == 0 {
+			line += "（今天）"
+		} else if days > 0 {
+			line += fmt.Sprintf("（还有 %d 天）", days)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// festivalsUsage describes the /festivals command
+func festivalsUsage() string {
+	return `用法:
+/festivals <类别>... off - 隐藏，如 /festivals 西方 节气 off
+/festivals <类别>... on - 重新显示
+/festivals reset - 显示全部
+/festivals add <名称> <日期> - 添加自己的节日，如 /festivals add 家乡庙会 农历二月初二
+/festivals delete <编号> - 删除自己的节日
+
+类别：法定、传统、西方、节气、纪念日、我的节日`
+}
@@ -1,27 +1,76 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/cuichanghe/daily-reminder-bot/internal/model"
 	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
 	"github.com/cuichanghe/daily-reminder-bot/internal/service"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/timeparse"
 	"go.uber.org/zap"
 	tele "gopkg.in/telebot.v3"
 )
 
+// flowSubscribe is the guided /subscribe dialog asking for the city and then the time
+const flowSubscribe = "subscribe"
+
+// invalidTimeMessage is sent when a reminder time cannot be parsed
+const invalidTimeMessage = "❌ 无法识别时间，支持如 08:00、8点半、早上8点、晚上9点、8am，或预设 早/午/晚"
+
 // Handlers holds all service dependencies for bot handlers
 type Handlers struct {
-	userRepo   *repository.UserRepository
-	subRepo    *repository.SubscriptionRepository
-	todoRepo   *repository.TodoRepository
-	weatherSvc *service.WeatherService
-	todoSvc    *service.TodoService
-	airSvc     *service.AirQualityService
-	warningSvc *service.WarningService
+	userRepo     *repository.UserRepository
+	subRepo      *repository.SubscriptionRepository
+	todoRepo     *repository.TodoRepository
+	webhookRepo  *repository.WebhookRepository
+	channelRepo  *repository.NotificationChannelRepository
+	inviteRepo   *repository.TodoInviteRepository
+	proposalRepo *repository.TodoProposalRepository
+	todoMsgRepo  *repository.TodoMessageRepository
+	weatherSvc   *service.WeatherService
+	todoSvc      *service.TodoService
+	airSvc       *service.AirQualityService
+	warningSvc   *service.WarningService
+	webhookSvc   *service.WebhookService // nil when user webhooks are disabled
+	notifySvc    *service.NotificationService
+	ocrSvc       *service.OCRService // nil when reading todos from photos is disabled
+	indexWatch   *service.IndexWatchService
+	scheduler    *service.SchedulerService
+	experiments  *service.ExperimentService       // nil when format experiments are disabled
+	newsSvc      *service.NewsService             // nil when the news section is disabled
+	ratesSvc     *service.RatesService            // nil when the rates section is disabled
+	horoscopeSvc *service.HoroscopeService        // nil when the horoscope section is disabled
+	healthSvc    *service.HealthReminderService   // nil when health reminders are disabled
+	intervalSvc  *service.IntervalReminderService // nil when interval reminders are disabled
+	aiSvc        *service.AIService
+	toneSvc      *service.ToneService        // nil when AI is disabled
+	obsSvc       *service.ObservationService // nil when weather observations are disabled
+	transferSvc  *service.TransferService
+	tierSvc      *service.TierService
+	paymentSvc   *service.PaymentService // nil when payments are disabled
+	auditSvc     *service.AuditService
+	roleSvc      *service.RoleService
+	dashboardSvc *service.DashboardService // nil when the web dashboard is disabled
+	miniAppSvc   *service.MiniAppService   // nil when the Mini App is disabled
+	jobSvc       *service.JobService
+	tripSvc      *service.TripService
+	maxWebhooks  int
+	maxChannels  int
+	timezone     *time.Location // Timezone of exported timestamps
+
+	conversations *Conversations
+	refreshes     *refreshCooldown
+	reports       *reportCache                         // Latest on-demand reports, for users over their API budget
+	events        *service.EventLogService             // nil when business events are disabled
+	festivalRepo  *repository.CustomFestivalRepository // nil when custom festivals are disabled
+	calendarSvc   *service.CalendarService
+	calendarFeeds *service.CalendarFeedService // nil when calendar feeds are disabled
+	solarTerms    *service.SolarTermService    // nil when solar term tips are disabled
 }
 
 // NewHandlers creates a new Handlers instance
@@ -29,48 +78,188 @@ func NewHandlers(
 	userRepo *repository.UserRepository,
 	subRepo *repository.SubscriptionRepository,
 	todoRepo *repository.TodoRepository,
+	webhookRepo *repository.WebhookRepository,
+	channelRepo *repository.NotificationChannelRepository,
+	convRepo *repository.ConversationRepository,
+	inviteRepo *repository.TodoInviteRepository,
+	proposalRepo *repository.TodoProposalRepository,
+	todoMsgRepo *repository.TodoMessageRepository,
 	weatherSvc *service.WeatherService,
 	todoSvc *service.TodoService,
 	airSvc *service.AirQualityService,
 	warningSvc *service.WarningService,
+	webhookSvc *service.WebhookService,
+	notifySvc *service.NotificationService,
+	ocrSvc *service.OCRService,
+	indexWatch *service.IndexWatchService,
+	scheduler *service.SchedulerService,
+	experiments *service.ExperimentService,
+	newsSvc *service.NewsService,
+	ratesSvc *service.RatesService,
+	horoscopeSvc *service.HoroscopeService,
+	healthSvc *service.HealthReminderService,
+	intervalSvc *service.IntervalReminderService,
+	aiSvc *service.AIService,
+	toneSvc *service.ToneService,
+	obsSvc *service.ObservationService,
+	transferSvc *service.TransferService,
+	tierSvc *service.TierService,
+	paymentSvc *service.PaymentService,
+	auditSvc *service.AuditService,
+	roleSvc *service.RoleService,
+	dashboardSvc *service.DashboardService,
+	miniAppSvc *service.MiniAppService,
+	jobSvc *service.JobService,
+	tripSvc *service.TripService,
+	maxWebhooks int,
+	maxChannels int,
+	timezone *time.Location,
 ) *Handlers {
-	return &Handlers{
-		userRepo:   userRepo,
-		subRepo:    subRepo,
-		todoRepo:   todoRepo,
-		weatherSvc: weatherSvc,
-		todoSvc:    todoSvc,
-		airSvc:     airSvc,
-		warningSvc: warningSvc,
+	h := &Handlers{
+		userRepo:     userRepo,
+		subRepo:      subRepo,
+		todoRepo:     todoRepo,
+		webhookRepo:  webhookRepo,
+		channelRepo:  channelRepo,
+		inviteRepo:   inviteRepo,
+		proposalRepo: proposalRepo,
+		todoMsgRepo:  todoMsgRepo,
+		weatherSvc:   weatherSvc,
+		todoSvc:      todoSvc,
+		airSvc:       airSvc,
+		warningSvc:   warningSvc,
+		webhookSvc:   webhookSvc,
+		notifySvc:    notifySvc,
+		ocrSvc:       ocrSvc,
+		indexWatch:   indexWatch,
+		scheduler:    scheduler,
+		experiments:  experiments,
+		newsSvc:      newsSvc,
+		ratesSvc:     ratesSvc,
+		horoscopeSvc: horoscopeSvc,
+		healthSvc:    healthSvc,
+		intervalSvc:  intervalSvc,
+		aiSvc:        aiSvc,
+		toneSvc:      toneSvc,
+		obsSvc:       obsSvc,
+		transferSvc:  transferSvc,
+		tierSvc:      tierSvc,
+		paymentSvc:   paymentSvc,
+		auditSvc:     auditSvc,
+		roleSvc:      roleSvc,
+		dashboardSvc: dashboardSvc,
+		miniAppSvc:   miniAppSvc,
+		jobSvc:       jobSvc,
+		tripSvc:      tripSvc,
+		maxWebhooks:  maxWebhooks,
+		maxChannels:  maxChannels,
+		timezone:     timezone,
+
+		conversations: NewConversations(convRepo),
+		refreshes:     newRefreshCooldown(reminderRefreshCooldown),
+		reports:       newReportCache(),
 	}
+	h.registerFlows()
+	return h
 }
 
-// RegisterHandlers registers all command handlers
-func (h *Handlers) RegisterHandlers(bot *tele.Bot) {
+// SetEventLog makes the handlers emit a subscription_created event for subscriptions created
+// with /subscribe
+func (h *Handlers) SetEventLog(events *service.EventLogService) {
+	h.events = events
+}
+
+// RegisterHandlers installs the command middleware chain and registers all command handlers on a
+// bot; name identifies the bot's users ("" = the primary bot)
+func (h *Handlers) RegisterHandlers(name string, bot *tele.Bot) {
+	bot.Use(h.Middlewares(name)...)
 	bot.Handle("/start", h.HandleStart)
 	bot.Handle("/subscribe", h.HandleSubscribe)
+	bot.Handle("/cities", h.HandleCities)
+	bot.Handle(btnCitySubscribe, h.HandleCitySubscribe)
 	bot.Handle("/mystatus", h.HandleMyStatus)
 	bot.Handle("/unsubscribe", h.HandleUnsubscribe)
 	bot.Handle("/weather", h.HandleWeather)
 	bot.Handle("/air", h.HandleAir)
+	bot.Handle(btnRefreshWeather, h.HandleRefreshWeather)
+	bot.Handle(btnRefreshAir, h.HandleRefreshAir)
+	bot.Handle("/uv", h.HandleUV)
+	bot.Handle("/index", h.HandleIndex)
+	bot.Handle("/mountain", h.HandleMountain)
+	bot.Handle("/sea", h.HandleSea)
+	bot.Handle("/outdoor", h.HandleOutdoor)
+	bot.Handle("/laundry", h.HandleLaundry)
+	bot.Handle("/trip", h.HandleTrip)
 	bot.Handle("/warning", h.HandleWarning)
 	bot.Handle("/warning_toggle", h.HandleWarningToggle)
+	bot.Handle("/warning_filter", h.HandleWarningFilter)
+	bot.Handle("/announcement_toggle", h.HandleAnnouncementToggle)
+	bot.Handle("/voice", h.HandleVoice)
+	bot.Handle("/combine", h.HandleCombine)
+	bot.Handle("/rename", h.HandleRename)
+	bot.Handle("/news", h.HandleNews)
+	bot.Handle("/rates", h.HandleRates)
+	bot.Handle("/zodiac", h.HandleZodiac)
+	bot.Handle("/festivals", h.HandleFestivals)
+	bot.Handle("/ics", h.HandleICS)
+	bot.Handle("/jieqi", h.HandleJieQi)
+	bot.Handle("/cycle", h.HandleCycle)
+	bot.Handle("/interval", h.HandleInterval)
+	bot.Handle("/pin", h.HandlePin)
+	bot.Handle("/ai", h.HandleAI)
+	bot.Handle(btnRegenerateReminder, h.HandleRegenerateReminder)
+	bot.Handle(btnVoteReminder, h.HandleVoteReminder)
+	bot.Handle(btnVoteReason, h.HandleVoteReason)
 	bot.Handle("/todo", h.HandleTodo)
+	bot.Handle("/webhook", h.HandleWebhook)
+	bot.Handle("/channel", h.HandleChannel)
+	bot.Handle("/share", h.HandleShare)
+	bot.Handle("/export", h.HandleExport)
+	bot.Handle("/transfer", h.HandleTransfer)
+	bot.Handle("/tier", h.HandleTier)
+	bot.Handle("/audit", h.HandleAudit)
+	bot.Handle("/jobs", h.HandleJobs)
+	bot.Handle("/grant", h.HandleGrant)
+	bot.Handle("/revoke", h.HandleRevoke)
+	bot.Handle(btnTransferConfirm, h.HandleTransferConfirm)
+	bot.Handle(btnTransferCancel, h.HandleTransferCancel)
+	bot.Handle("/cancel", h.HandleCancel)
+	bot.Handle("/feedback", h.HandleFeedback)
 	bot.Handle("/help", h.HandleHelp)
+	bot.Handle(tele.OnText, h.HandleText)
+	bot.Handle(tele.OnLocation, h.HandleLocation)
+	h.registerOCRHandlers(bot)
+	h.registerObservationHandlers(bot)
+	h.registerPaymentHandlers(bot)
+	h.registerDashboardHandlers(bot)
+	h.registerMiniAppHandlers(bot)
+	h.registerReactionHandlers(name, bot)
+	h.registerWarningActionHandlers(bot)
+	h.registerWarningFilterHandlers(bot)
 }
 
-// HandleStart handles the /start command
+// registerFlows registers the multi-step dialogs handled by HandleText
+func (h *Handlers) registerFlows() {
+	h.conversations.Register(Flow{
+		Name: flowSubscribe,
+		Steps: map[string]StepHandler{
+			"city":    h.subscribeCityStep,
+			"time":    h.subscribeTimeStep,
+			"confirm": h.subscribeConfirmStep,
+		},
+	})
+	h.conversations.Register(Flow{
+		Name:  flowCityChoice,
+		Steps: map[string]StepHandler{"pick": h.cityChoiceStep},
+	})
+}
+
+// HandleStart handles the /start command, including deep link payloads from /share
 func (h *Handlers) HandleStart(c tele.Context) error {
 	chatID := c.Sender().ID
-	logger.Debug("Received /start command", zap.Int64("chat_id", chatID))
-
-	// Get or create user
-	_, err := h.userRepo.GetOrCreate(chatID)
-	if err != nil {
-		logger.Error("Failed to create user",
-			zap.Int64("chat_id", chatID),
-			zap.Error(err))
-		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	link := parseStartPayload(c.Message().Payload)
+	if link.ReferrerID != 0 {
+		h.applyReferral(c, userFrom(c), link.ReferrerID)
 	}
 
 	message := `👋 欢迎使用每日提醒机器人！
@@ -82,47 +271,69 @@ func (h *Handlers) HandleStart(c tele.Context) error {
 
 使用 /help 查看所有命令`
 
-	logger.Info("User started bot", zap.Int64("chat_id", chatID))
-	return c.Send(message)
+	logger.Info("User started bot",
+		zap.Int64("chat_id", chatID),
+		zap.String("name", userFrom(c).DisplayName()))
+	if err := c.Send(message); err != nil {
+		return err
+	}
+
+	if link.TodoInvite != "" {
+		return h.acceptTodoInvite(c, link.TodoInvite)
+	}
+	if link.Transfer != "" {
+		return h.promptTransfer(c, link.Transfer)
+	}
+	if link.City != "" {
+		return h.promptSharedSubscription(c, link)
+	}
+	return nil
 }
 
 // HandleSubscribe handles the /subscribe command
 func (h *Handlers) HandleSubscribe(c tele.Context) error {
 	chatID := c.Sender().ID
-	logger.Debug("Received /subscribe command",
-		zap.Int64("chat_id", chatID),
-		zap.Strings("args", c.Args()))
-
-	// Get or create user
-	user, err := h.userRepo.GetOrCreate(chatID)
-	if err != nil {
-		logger.Error("Failed to get user",
-			zap.Int64("chat_id", chatID),
-			zap.Error(err))
-		return c.Send("抱歉,系统出现错误,请稍后再试。")
-	}
+	user := userFrom(c)
 
 	// Parse arguments: /subscribe <city> <time>
 	// Example: /subscribe 北京 08:00
+	// Missing arguments are asked for step by step
 	args := c.Args()
-	if len(args) < 2 {
-		logger.Debug("Invalid subscribe arguments",
-			zap.Int64("chat_id", chatID),
-			zap.Int("args_count", len(args)))
-		return c.Send("❌ 用法: /subscribe <城市> <时间>\n示例: /subscribe 北京 08:00")
+	switch len(args) {
+	case 0:
+		if err := h.conversations.Start(botOf(c), chatID, flowSubscribe, "city", nil); err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		return c.Send("📍 请发送要订阅的城市名称\n（发送 /cancel 取消）")
+	case 1:
+		if err := h.conversations.Start(botOf(c), chatID, flowSubscribe, "time", map[string]string{"city": args[0]}); err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		return c.Send(subscribeTimePrompt(args[0]))
 	}
 
 	city := args[0]
-	reminderTime := args[1]
+	rawTime := strings.Join(args[1:], " ")
 
-	// Validate time format (HH:MM)
-	if !isValidTimeFormat(reminderTime) {
+	// Parse the time loosely (08:00, 8点半, 早上8点, 8am, presets 早/午/晚) into HH:MM
+	reminderTime, err := timeparse.Parse(rawTime)
+	if err != nil {
 		logger.Debug("Invalid time format",
 			zap.Int64("chat_id", chatID),
-			zap.String("time", reminderTime))
-		return c.Send("❌ 时间格式错误，请使用 HH:MM 格式（如 08:00）")
+			zap.String("time", rawTime),
+			zap.Error(err))
+		return c.Send(invalidTimeMessage)
 	}
 
+	return h.subscribe(c, user, city, reminderTime, "", "")
+}
+
+// subscribe creates, updates or restores the user's subscription for a city.
+// lat and lon are set for subscriptions created from a shared location, switching them to grid
+// weather; empty coordinates leave an existing subscription's coordinates unchanged.
+func (h *Handlers) subscribe(c tele.Context, user *model.User, city, reminderTime, lat, lon string) error {
+	chatID := c.Sender().ID
+
 	// Check if user already has this city subscribed
 	existingSub, err := h.subRepo.FindByUserAndCity(user.ID, city)
 	if err != nil {
@@ -138,6 +349,9 @@ func (h *Handlers) HandleSubscribe(c tele.Context) error {
 		// Update existing subscription for this city
 		existingSub.ReminderTime = reminderTime
 		existingSub.Active = true
+		if lat != "" && lon != "" {
+			existingSub.Lat, existingSub.Lon = lat, lon
+		}
 		if err := h.subRepo.Update(existingSub); err != nil {
 			logger.Error("Failed to update subscription",
 				zap.Int64("chat_id", chatID),
@@ -150,10 +364,11 @@ func (h *Handlers) HandleSubscribe(c tele.Context) error {
 			zap.Uint("subscription_id", existingSub.ID),
 			zap.String("city", city),
 			zap.String("reminder_time", reminderTime))
-		return c.Send(fmt.Sprintf("✅ 订阅已更新！\n📍 城市：%s\n⏰ 新时间：%s", city, reminderTime))
+		return c.Send(fmt.Sprintf("✅ 订阅已更新！\n📍 城市：%s\n⏰ 新时间：%s", city, reminderTime) + gridWeatherNote(existingSub))
 	}
 
-	// Check subscription limit (max 5)
+	// Check the subscription limit of the user's tier
+	limit := h.tierSvc.Limits(user, time.Now()).Subscriptions
 	count, err := h.subRepo.CountActiveByUser(user.ID)
 	if err != nil {
 		logger.Error("Failed to count subscriptions",
@@ -162,12 +377,47 @@ func (h *Handlers) HandleSubscribe(c tele.Context) error {
 			zap.Error(err))
 		return c.Send("抱歉,系统出现错误,请稍后再试。")
 	}
-	if count >= 5 {
+	if count >= int64(limit) {
 		logger.Warn("Subscription limit reached",
 			zap.Int64("chat_id", chatID),
 			zap.Uint("user_id", user.ID),
 			zap.Int64("count", count))
-		return c.Send("❌ 订阅数量已达上限（5个）\n请先使用 /unsubscribe <城市> 取消部分订阅")
+		return c.Send(fmt.Sprintf("❌ 订阅数量已达上限（%d个）\n请先使用 /unsubscribe <城市> 取消部分订阅，或发送 /tier 查看套餐", limit))
+	}
+
+	// Restore a previously cancelled subscription for this city instead of inserting a duplicate
+	dormantSub, err := h.subRepo.FindByUserAndCityUnscoped(user.ID, city)
+	if err != nil {
+		logger.Error("Failed to find cancelled subscription",
+			zap.Int64("chat_id", chatID),
+			zap.Uint("user_id", user.ID),
+			zap.String("city", city),
+			zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if dormantSub != nil {
+		dormantSub.ReminderTime = reminderTime
+		if lat != "" && lon != "" {
+			dormantSub.Lat, dormantSub.Lon = lat, lon
+		}
+		if err := h.subRepo.Restore(dormantSub); err != nil {
+			logger.Error("Failed to restore subscription",
+				zap.Int64("chat_id", chatID),
+				zap.Uint("subscription_id", dormantSub.ID),
+				zap.Error(err))
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		if h.events != nil {
+			h.events.Emit(service.SubscriptionCreatedEvent(dormantSub, "bot", true))
+		}
+		logger.Info("Subscription restored",
+			zap.Int64("chat_id", chatID),
+			zap.Uint("subscription_id", dormantSub.ID),
+			zap.String("city", city),
+			zap.String("reminder_time", reminderTime))
+		err = c.Send(fmt.Sprintf("✅ 订阅已恢复！\n📍 城市：%s\n⏰ 时间：%s\n\n之前的待办事项和设置已一并恢复。", city, reminderTime) + gridWeatherNote(dormantSub))
+		go h.sendActiveWarnings(dormantSub.ID)
+		return err
 	}
 
 	// Create new subscription
@@ -176,6 +426,8 @@ func (h *Handlers) HandleSubscribe(c tele.Context) error {
 		City:         city,
 		ReminderTime: reminderTime,
 		Active:       true,
+		Lat:          lat,
+		Lon:          lon,
 	}
 	if err := h.subRepo.Create(sub); err != nil {
 		logger.Error("Failed to create subscription",
@@ -184,27 +436,62 @@ func (h *Handlers) HandleSubscribe(c tele.Context) error {
 			zap.Error(err))
 		return c.Send("抱歉,系统出现错误,请稍后再试。")
 	}
+	if h.events != nil {
+		h.events.Emit(service.SubscriptionCreatedEvent(sub, "bot", false))
+	}
 	logger.Info("Subscription created",
 		zap.Int64("chat_id", chatID),
 		zap.Uint("user_id", user.ID),
 		zap.String("city", city),
 		zap.String("reminder_time", reminderTime))
 
-	return c.Send(fmt.Sprintf("✅ 订阅成功！\n📍 城市：%s\n⏰ 时间：%s\n\n每天将在该时间为您推送天气和待办提醒。\n\n💡 提示：您可以订阅多个城市（最多5个），每个城市的待办事项独立管理。", city, reminderTime))
+	err = c.Send(fmt.Sprintf("✅ 订阅成功！\n📍 城市：%s\n⏰ 时间：%s\n\n每天将在该时间为您推送天气和待办提醒。\n\n💡 提示：您可以订阅多个城市（最多%d个），每个城市的待办事项独立管理。", city, reminderTime, limit) + gridWeatherNote(sub))
+	go h.sendActiveWarnings(sub.ID)
+	return err
 }
 
-// HandleMyStatus handles the /mystatus command
-func (h *Handlers) HandleMyStatus(c tele.Context) error {
-	chatID := c.Sender().ID
-	logger.Debug("Received /mystatus command", zap.Int64("chat_id", chatID))
+// sendActiveWarnings sends the warnings in effect in the city to a new or restored subscription,
+// after the confirmation, so that subscribers don't wait for the next warning change
+func (h *Handlers) sendActiveWarnings(subscriptionID uint) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	if _, err := h.warningSvc.NotifyActive(ctx, subscriptionID); err != nil {
+		logger.Warn("Failed to send active warnings to subscription",
+			zap.Uint("subscription_id", subscriptionID),
+			zap.Error(err))
+	}
+}
+
+// subscribeCityStep receives the city in the guided /subscribe flow
+func (h *Handlers) subscribeCityStep(c tele.Context, state *ConversationState) error {
+	city := strings.TrimSpace(c.Text())
+	if city == "" || strings.ContainsAny(city, " \n") {
+		return c.Send("❌ 请只发送一个城市名称，例如：北京")
+	}
+	state.Data["city"] = city
+	state.Next("time")
+	return c.Send(subscribeTimePrompt(city))
+}
 
-	user, err := h.userRepo.GetOrCreate(chatID)
+// subscribeTimeStep receives the reminder time in the guided /subscribe flow
+func (h *Handlers) subscribeTimeStep(c tele.Context, state *ConversationState) error {
+	reminderTime, err := timeparse.Parse(c.Text())
 	if err != nil {
-		logger.Error("Failed to get user",
-			zap.Int64("chat_id", chatID),
-			zap.Error(err))
-		return c.Send("抱歉,系统出现错误,请稍后再试。")
+		return c.Send(invalidTimeMessage + "\n请重新发送时间（发送 /cancel 取消）")
 	}
+	state.Finish()
+	return h.subscribe(c, userFrom(c), state.Data["city"], reminderTime, state.Data["lat"], state.Data["lon"])
+}
+
+// subscribeTimePrompt asks for the reminder time of a city
+func subscribeTimePrompt(city string) string {
+	return fmt.Sprintf("⏰ 请发送 %s 的每日提醒时间\n支持如 08:00、8点半、早上7点、8am，或预设 早/午/晚\n（发送 /cancel 取消）", city)
+}
+
+// HandleMyStatus handles the /mystatus command
+func (h *Handlers) HandleMyStatus(c tele.Context) error {
+	chatID := c.Sender().ID
+	user := userFrom(c)
 
 	subs, err := h.subRepo.FindByUserID(user.ID)
 	if err != nil {
@@ -226,12 +513,17 @@ func (h *Handlers) HandleMyStatus(c tele.Context) error {
 	var status strings.Builder
 	status.WriteString(fmt.Sprintf("📬 您的订阅状态（共 %d 个）\n\n", len(subs)))
 	for i, sub := range subs {
-		status.WriteString(fmt.Sprintf("%d. 📍 %s - ⏰ %s\n", i+1, sub.City, sub.ReminderTime))
+		status.WriteString(fmt.Sprintf("%d. 📍 %s - ⏰ %s", i+1, sub.DisplayName(), sub.ReminderTime))
+		if sub.HasCoordinates() {
+			status.WriteString(" 🛰 格点天气")
+		}
+		status.WriteString("\n")
 	}
 	status.WriteString("\n💡 提示：\n")
 	status.WriteString("• 使用 /unsubscribe <城市> 取消指定订阅\n")
 	status.WriteString("• 使用 /weather <城市> 查询天气\n")
-	status.WriteString("• 使用 /todo <城市> 管理待办")
+	status.WriteString("• 使用 /todo <城市> 管理待办\n")
+	status.WriteString("• 使用 /rename <城市> <名称> 为订阅命名（如 家、公司）")
 
 	logger.Debug("Subscription status queried",
 		zap.Int64("chat_id", chatID),
@@ -241,19 +533,13 @@ func (h *Handlers) HandleMyStatus(c tele.Context) error {
 
 // HandleUnsubscribe handles the /unsubscribe command
 func (h *Handlers) HandleUnsubscribe(c tele.Context) error {
-	chatID := c.Sender().ID
-	args := c.Args()
-	logger.Debug("Received /unsubscribe command",
-		zap.Int64("chat_id", chatID),
-		zap.Strings("args", args))
+	return h.unsubscribe(c, c.Args())
+}
 
-	user, err := h.userRepo.GetOrCreate(chatID)
-	if err != nil {
-		logger.Error("Failed to get user",
-			zap.Int64("chat_id", chatID),
-			zap.Error(err))
-		return c.Send("抱歉,系统出现错误,请稍后再试。")
-	}
+// unsubscribe cancels the subscription named by the first argument, or the only subscription
+func (h *Handlers) unsubscribe(c tele.Context, args []string) error {
+	chatID := c.Sender().ID
+	user := userFrom(c)
 
 	subs, err := h.subRepo.FindByUserID(user.ID)
 	if err != nil {
@@ -273,18 +559,14 @@ func (h *Handlers) HandleUnsubscribe(c tele.Context) error {
 
 	// Case 1: City specified in arguments
 	if len(args) > 0 {
-		city := args[0]
-		sub, err := h.subRepo.FindByUserAndCity(user.ID, city)
-		if err != nil {
-			logger.Error("Failed to find subscription by city",
-				zap.Int64("chat_id", chatID),
-				zap.String("city", city),
-				zap.Error(err))
-			return c.Send("抱歉,系统出现错误,请稍后再试。")
-		}
+		sub, suggestions := h.matchSubscription(subs, args[0])
 		if sub == nil {
-			return c.Send(fmt.Sprintf("❌ 未找到 %s 的订阅", city))
+			if len(suggestions) > 0 {
+				return h.askCityChoice(c, cityChoiceUnsubscribe, args[0], suggestions, nil)
+			}
+			return c.Send(fmt.Sprintf("❌ 未找到 %s 的订阅\n您的订阅：%s", args[0], h.formatCityList(subs)))
 		}
+		city := sub.City
 
 		if err := h.subRepo.Delete(sub.ID); err != nil {
 			logger.Error("Failed to delete subscription",
@@ -331,28 +613,27 @@ func (h *Handlers) HandleUnsubscribe(c tele.Context) error {
 // HandleWeather handles the /weather command
 func (h *Handlers) HandleWeather(c tele.Context) error {
 	chatID := c.Sender().ID
-	logger.Debug("Received /weather command",
-		zap.Int64("chat_id", chatID),
-		zap.Strings("args", c.Args()))
+	user := userFrom(c)
 
-	// Get user
-	user, err := h.userRepo.GetOrCreate(chatID)
-	if err != nil {
-		logger.Error("Failed to get user",
-			zap.Int64("chat_id", chatID),
-			zap.Error(err))
-		return c.Send("抱歉,系统出现错误,请稍后再试。")
-	}
-
-	// Get city from args or subscription
-	var city string
+	// Get city (or scenic spot, with an optional type hint) from args or subscription
+	var city, hint string
+	var subs []model.Subscription
 	args := c.Args()
 	if len(args) > 0 {
-		city = args[0]
-		logger.Debug("City from args", zap.String("city", city))
+		city, hint = parseWeatherQuery(strings.Join(args, " "))
+		logger.Debug("City from args", zap.String("city", city), zap.String("hint", hint))
+
+		// A subscription's label or another spelling of its city ("家", "北京市") means that city
+		var err error
+		if subs, err = h.subRepo.FindByUserID(user.ID); err == nil && hint == "" {
+			if matched := namedSubscriptions(subs, city); len(matched) == 1 {
+				city = matched[0].City
+			}
+		}
 	} else {
 		// Try to get from subscriptions
-		subs, err := h.subRepo.FindByUserID(user.ID)
+		var err error
+		subs, err = h.subRepo.FindByUserID(user.ID)
 		if err != nil {
 			logger.Error("Failed to find subscriptions",
 				zap.Int64("chat_id", chatID),
@@ -389,36 +670,57 @@ func (h *Handlers) HandleWeather(c tele.Context) error {
 		}
 	}
 
+	query := strings.Join(args, " ")
+	if len(args) > 0 && hint == "" {
+		query = city
+	}
+	key := reportKey("weather", city, hint)
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
+	}
+
 	// Get full weather report with warnings and air quality
-	report, err := h.weatherSvc.GetFullWeatherReport(city, h.airSvc, h.warningSvc)
+	report, err := h.weatherSvc.GetFullWeatherReport(city, hint, h.airSvc, h.warningSvc)
 	if err != nil {
 		logger.Error("Failed to get weather report",
 			zap.Int64("chat_id", chatID),
 			zap.String("city", city),
 			zap.Error(err))
-		return c.Send(fmt.Sprintf("❌ 无法获取 %s 的天气信息，请检查城市名称是否正确。", city))
+		message := fmt.Sprintf("❌ 无法获取 %s 的天气信息，请检查城市或地点名称是否正确。", city)
+		if similar := similarSubscriptions(subs, city); len(similar) > 0 {
+			message += fmt.Sprintf("\n💡 您是指：%s？", h.formatCityList(similar))
+		}
+		return c.Send(message)
 	}
 
 	logger.Info("Weather report sent",
 		zap.Int64("chat_id", chatID),
 		zap.String("city", city))
-	return c.Send(report)
+	h.rememberReport(c, key, report)
+	return c.Send(h.withUpdatedAt(report), refreshMarkup(btnRefreshWeather, query))
+}
+
+// parseWeatherQuery splits a /weather query into the place name and the lookup selected by an
+// optional trailing type hint (e.g. "故宫 景点"); without a known hint the whole query is the place
+func parseWeatherQuery(query string) (place, hint string) {
+	fields := strings.Fields(query)
+	if len(fields) > 1 {
+		if hint, ok := service.ParseLocationHint(fields[len(fields)-1]); ok {
+			return strings.Join(fields[:len(fields)-1], " "), hint
+		}
+	}
+	return strings.Join(fields, " "), ""
 }
 
 // HandleTodo handles the /todo command with multi-subscription support
 func (h *Handlers) HandleTodo(c tele.Context) error {
-	chatID := c.Sender().ID
-	args := c.Args()
-	logger.Debug("Received /todo command",
-		zap.Int64("chat_id", chatID),
-		zap.Strings("args", args))
+	return h.todo(c, c.Args())
+}
 
-	// Get user
-	user, err := h.userRepo.GetOrCreate(chatID)
-	if err != nil {
-		logger.Error("Failed to get user", zap.Int64("chat_id", chatID), zap.Error(err))
-		return c.Send("抱歉,系统出现错误,请稍后再试。")
-	}
+// todo runs /todo with the given arguments: [城市] [操作] [参数...]
+func (h *Handlers) todo(c tele.Context, args []string) error {
+	chatID := c.Sender().ID
+	user := userFrom(c)
 
 	// Get user's subscriptions
 	subs, err := h.subRepo.FindByUserID(user.ID)
@@ -435,7 +737,7 @@ func (h *Handlers) HandleTodo(c tele.Context) error {
 		var result strings.Builder
 		totalTodos := 0
 		for _, sub := range subs {
-			todos, err := h.todoSvc.GetSubscriptionTodos(sub.ID)
+			todos, err := h.todoSvc.GetSubscriptionTodos(sub.TodoListID())
 			if err != nil {
 				logger.Warn("Failed to get todos for subscription",
 					zap.Uint("subscription_id", sub.ID),
@@ -443,7 +745,7 @@ func (h *Handlers) HandleTodo(c tele.Context) error {
 				continue
 			}
 			if len(todos) > 0 {
-				result.WriteString(h.todoSvc.FormatTodoListWithCity(todos, sub.City))
+				result.WriteString(h.todoSvc.FormatTodoListWithCity(todos, todoListTitle(&sub)))
 				result.WriteString("\n")
 				totalTodos += len(todos)
 			}
@@ -460,9 +762,9 @@ func (h *Handlers) HandleTodo(c tele.Context) error {
 	var action string
 	var actionArgs []string
 
-	// Check if first argument is a city name
+	// Check if first argument is a city name or a subscription's label
 	for i := range subs {
-		if subs[i].City == firstArg {
+		if subs[i].MatchesName(firstArg) {
 			targetSub = &subs[i]
 			if len(args) > 1 {
 				action = args[1]
@@ -472,6 +774,20 @@ func (h *Handlers) HandleTodo(c tele.Context) error {
 		}
 	}
 
+	// Otherwise match it loosely ("北京市", pinyin), asking when it may mean several cities
+	if targetSub == nil && !todoActions[firstArg] {
+		sub, suggestions := h.matchSubscription(subs, firstArg)
+		if sub != nil {
+			targetSub = sub
+			if len(args) > 1 {
+				action = args[1]
+				actionArgs = args[2:]
+			}
+		} else if len(suggestions) > 0 {
+			return h.askCityChoice(c, cityChoiceTodo, firstArg, suggestions, args[1:])
+		}
+	}
+
 	// If not a city name, treat as action (only works with single subscription)
 	if targetSub == nil {
 		if len(subs) == 1 {
@@ -483,14 +799,17 @@ func (h *Handlers) HandleTodo(c tele.Context) error {
 		}
 	}
 
+	// Members of a shared list work on the owner's todos
+	listID := targetSub.TodoListID()
+
 	// If no action, list todos for the specified city
 	if action == "" {
-		todos, err := h.todoSvc.GetSubscriptionTodos(targetSub.ID)
+		todos, err := h.todoSvc.GetSubscriptionTodos(listID)
 		if err != nil {
-			logger.Error("Failed to get todos", zap.Uint("subscription_id", targetSub.ID), zap.Error(err))
+			logger.Error("Failed to get todos", zap.Uint("subscription_id", listID), zap.Error(err))
 			return c.Send("抱歉,系统出现错误,请稍后再试。")
 		}
-		return c.Send(h.todoSvc.FormatTodoListWithCity(todos, targetSub.City))
+		return c.Send(h.todoSvc.FormatTodoListWithCity(todos, todoListTitle(targetSub)))
 	}
 
 	// Handle actions
@@ -500,18 +819,24 @@ func (h *Handlers) HandleTodo(c tele.Context) error {
 			return c.Send("❌ 用法: /todo " + targetSub.City + " add <内容>")
 		}
 		content := strings.Join(actionArgs, " ")
-		if err := h.todoSvc.AddTodo(targetSub.ID, content); err != nil {
+		todo, err := h.todoSvc.AddTodo(listID, content)
+		if err != nil {
 			logger.Error("Failed to add todo", zap.Error(err))
 			return c.Send("抱歉,系统出现错误,请稍后再试。")
 		}
-		logger.Info("Todo added", zap.String("city", targetSub.City), zap.String("content", content))
-		return c.Send(fmt.Sprintf("✅ 已为 %s 添加待办：%s", targetSub.City, content))
+		logger.Info("Todo added", zap.String("city", targetSub.City), zap.Int("content_len", len(content)))
+		reply, err := c.Bot().Send(c.Recipient(), fmt.Sprintf("✅ 已为 %s 添加待办：%s\n\n💡 对本消息回应 👍 即可完成", targetSub.DisplayName(), content))
+		if err != nil {
+			return err
+		}
+		h.linkTodoMessages(c, todo.ID, reply)
+		return nil
 
 	case "done":
 		if len(actionArgs) == 0 {
 			return c.Send("❌ 用法: /todo " + targetSub.City + " done <编号>")
 		}
-		todos, err := h.todoSvc.GetSubscriptionTodos(targetSub.ID)
+		todos, err := h.todoSvc.GetSubscriptionTodos(listID)
 		if err != nil {
 			return c.Send("抱歉,系统出现错误,请稍后再试。")
 		}
@@ -525,13 +850,14 @@ func (h *Handlers) HandleTodo(c tele.Context) error {
 			return c.Send("❌ 无法完成该待办事项")
 		}
 		logger.Info("Todo completed", zap.Uint("todo_id", todoID))
+		h.notifyTodoCompleted(targetSub, user, todos[idx-1].Content)
 		return c.Send("✅ 待办事项已完成")
 
 	case "delete", "del":
 		if len(actionArgs) == 0 {
 			return c.Send("❌ 用法: /todo " + targetSub.City + " delete <编号>")
 		}
-		todos, err := h.todoSvc.GetSubscriptionTodos(targetSub.ID)
+		todos, err := h.todoSvc.GetSubscriptionTodos(listID)
 		if err != nil {
 			return c.Send("抱歉,系统出现错误,请稍后再试。")
 		}
@@ -547,11 +873,36 @@ func (h *Handlers) HandleTodo(c tele.Context) error {
 		logger.Info("Todo deleted", zap.Uint("todo_id", todoID))
 		return c.Send("✅ 待办事项已删除")
 
+	case "share":
+		return h.shareTodoList(c, targetSub)
+
+	case "members":
+		return h.listTodoMembers(c, targetSub)
+
+	case "remove":
+		return h.removeTodoMember(c, targetSub, actionArgs)
+
+	case "leave":
+		return h.leaveTodoList(c, targetSub)
+
+	case "export":
+		format := ""
+		if len(actionArgs) > 0 {
+			format = actionArgs[0]
+		}
+		return h.exportTodos(c, targetSub, format)
+
 	default:
-		return c.Send("❌ 未知操作: " + action + "\n\n可用操作：add, done, delete")
+		return c.Send("❌ 未知操作: " + action + "\n\n可用操作：add, done, delete, export, share, members, remove, leave")
 	}
 }
 
+// todoActions are the actions of /todo, which are never taken for a misspelled city
+var todoActions = map[string]bool{
+	"add": true, "done": true, "delete": true, "del": true, "share": true,
+	"members": true, "remove": true, "leave": true, "export": true,
+}
+
 // formatCityList formats a list of cities for display
 func (h *Handlers) formatCityList(subs []model.Subscription) string {
 	var cities []string
@@ -563,34 +914,67 @@ func (h *Handlers) formatCityList(subs []model.Subscription) string {
 
 // HandleHelp handles the /help command
 func (h *Handlers) HandleHelp(c tele.Context) error {
-	chatID := c.Sender().ID
-	logger.Debug("Received /help command", zap.Int64("chat_id", chatID))
-
 	message := `📖 命令帮助
 
 🔔 订阅管理
 /subscribe <城市> <时间> - 订阅每日提醒
   示例: /subscribe 北京 08:00
-  💡 可订阅多个城市（最多5个），每个城市独立管理
+  时间也可写作 8点半、早上7点、晚上9点、8am，或预设 早/午/晚
+  💡 可订阅多个城市（数量上限见 /tier），每个城市独立管理
+  💡 只发送 /subscribe 将逐步询问城市和时间
+  💡 直接发送位置即可按所在位置订阅（格点天气，更精确）
+/cities [关键词] - 搜索城市，点击按钮直接订阅
+  示例: /cities suzhou、/cities 苏
+  💡 支持拼音和模糊匹配，不带关键词列出热门城市
 /mystatus - 查询所有订阅状态
+/rename [城市] <名称|off> - 为订阅命名（如 家、公司），命令中可代替城市
+  示例: /rename 北京 家
 /unsubscribe [城市] - 取消订阅
   示例: /unsubscribe 北京
   💡 不指定城市时，单订阅直接取消，多订阅需选择
 
 ☁️ 天气查询
-/weather [城市] - 查询综合天气报告（含预警和空气质量）
-  示例: /weather 上海
+/weather [城市或景点] [类型] - 查询综合天气报告（含预警和空气质量）
+  示例: /weather 上海、/weather 故宫、/weather 九寨沟 景点
+  类型可选：城市、景点、潮汐、海流
   💡 不指定城市时使用第一个订阅
+  💡 点击报告下方的「🔄 刷新」可原地更新数据
 
 🌫️ 空气质量
 /air [城市] - 查询空气质量详情
   示例: /air 北京
   💡 包含 AQI、污染物浓度、未来预报
 
+☀️ 紫外线
+/uv [城市] - 逐小时紫外线曲线与防晒建议
+  示例: /uv 北京
+
+🧳 出行
+/trip <城市> <开始日期> <结束日期> - 行程天气、行李建议与节假日提醒
+  示例: /trip 杭州 10-01 10-05、/trip 成都 明天 后天
+
+👕 晾晒
+/laundry [城市] - 未来 48 小时晾晒指数与最佳晾晒日
+/laundry [城市] on|off - 每日提醒附上晾晒建议
+
+🔔 生活指数提醒
+/index [城市] <指数> [好|较好] - 指数达标的早上单独提醒
+  示例: /index 洗车、/index 北京 运动 较好
+/index del <编号> - 删除指数提醒
+
+🏕 户外
+/mountain <山名> - 登山天气与风险提示
+  示例: /mountain 泰山
+/sea <沿海地点> - 潮汐与海面风力
+  示例: /sea 青岛
+/outdoor [城市] <登山|出海> <地点> - 每日提醒附上户外预报
+  示例: /outdoor 登山 泰山、/outdoor off
+
 ⚠️ 天气预警
 /warning [城市] - 查询当前天气预警
   示例: /warning 深圳
 /warning_toggle - 开启/关闭预警主动推送
+/warning_filter [城市] - 按预警类型屏蔽推送（如只屏蔽大雾）
   💡 开启后会自动推送所订阅城市的新预警
 
 📝 待办事项（按城市分组）
@@ -600,54 +984,75 @@ func (h *Handlers) HandleHelp(c tele.Context) error {
   示例: /todo 北京 add 买菜
 /todo <城市> done <编号> - 完成待办
 /todo <城市> delete <编号> - 删除待办
+/todo <城市> export [csv|md] - 导出全部待办（含已完成）为文件
+/todo <城市> share - 生成邀请链接，与家人共同管理该城市待办
+/todo <城市> members - 查看共享成员
+/todo <城市> remove <编号> - 移除共享成员（创建者）
+/todo <城市> leave - 退出共享清单（成员）
   💡 单订阅时可省略城市名
+  💡 对添加待办的消息回应 👍 即可完成；对每日提醒回应 🔁 可刷新
+  💡 发送课程表、通知等图片可识别并添加待办（需管理员开启）
+
+📣 额外通知渠道（邮件/ntfy/Bark，需管理员开启）
+/channel - 列出所有订阅的通知渠道
+/channel <城市> add <email|ntfy|bark> <目标> - 添加渠道
+  示例: /channel 北京 add ntfy my-weather-topic
+/channel <城市> delete <编号> - 删除渠道
+  💡 提醒和预警会同时推送到 Telegram 与所有渠道
+
+🔗 Webhook（需管理员开启）
+/webhook - 列出已注册的 Webhook
+/webhook add <URL> [reminder,warning] - 注册 Webhook
+/webhook delete <编号> - 删除 Webhook
 
 ❓ 其他
 /start - 开始使用机器人
-/help - 显示此帮助信息`
+/share [城市] - 生成邀请好友订阅同一城市的链接
+/export [csv|md] - 导出我的全部订阅和待办
+/transfer - 生成链接，把订阅和待办转移到新的 Telegram 账号
+/app - 打开 Telegram 小程序管理待办与设置（仅私聊，需管理员开启）
+/dashboard - 获取网页面板登录码，在浏览器查看订阅和待办（仅私聊，需管理员开启）
+/tier - 查看我的套餐（免费版/高级版）与用量
+/premium - 购买高级版（需管理员开启）
+/announcement_toggle - 开启/关闭管理员公告推送
+/voice [off|both|only] - 设置每日提醒的语音播报（需管理员开启）
+/combine [on|off] - 合并推送：提醒时间相同的多个城市合并为一条消息
+/news [on|off|default|<RSS地址>] - 设置每日提醒的新闻要闻（需管理员开启）
+/rates [on|off|default|<货币对>...] - 设置每日提醒的汇率金价（需管理员开启）
+/zodiac [<星座>|<生日>|off] - 在每日提醒中附上星座运势（需管理员开启）
+/festivals [<类别>... on|off|reset] - 选择每日提醒显示的节日类别（法定、传统、西方、节气、纪念日）
+/festivals add <名称> <日期> - 添加自己的节日（如公司周年庆 3-15、家乡庙会 农历二月初二）
+/ics [add <地址> [名称]|delete <编号>] - 订阅 ICS 日历（课表、团队日历），当天日程附在每日提醒中（需管理员开启）
+/jieqi [节气] - 查看当前或指定节气的养生小贴士（需管理员开启）
+/cycle - 私人周期/服药提醒，加密保存、单独发送（仅私聊，需管理员开启）
+/interval - 工作时段喝水、久坐活动间隔提醒，可设免打扰时段
+/pin [城市] [on|off] - 自动置顶每日提醒（取消置顶前一天的）
+/ai [城市] [on|lite|off|default] - AI 撰写每日提醒（lite 为简洁模式；提醒下可点 👍/👎 反馈或「换一条 🔁」重写）
+/observe [城市] [现象] [描述] - 实况打卡，上报所在城市正在下雨、下雪等（也可发送图片并以 #实况 开头写说明；需管理员开启）
+/feedback <内容> - 对每日提醒提意见或建议
+/cancel - 取消当前进行中的操作
+/help - 显示此帮助信息
+
+💬 中文快捷指令（私聊中无需 /）
+天气 [城市]、空气、紫外线、预警、晾晒、出行、城市、节日、日程、节气
+待办 [城市] [添加|完成|删除] [内容或编号]
+订阅 北京 08:00、取消订阅 北京、我的订阅、命名、帮助、取消
+  示例: 待办 添加 买菜、待办 完成 1`
 
 	return c.Send(message)
 }
 
-// isValidTimeFormat validates HH:MM time format
-func isValidTimeFormat(timeStr string) bool {
-	parts := strings.Split(timeStr, ":")
-	if len(parts) != 2 {
-		return false
-	}
-
-	hour, err := strconv.Atoi(parts[0])
-	if err != nil || hour < 0 || hour > 23 {
-		return false
-	}
-
-	minute, err := strconv.Atoi(parts[1])
-	if err != nil || minute < 0 || minute > 59 {
-		return false
-	}
-
-	return true
-}
-
 // HandleAir handles the /air command
 func (h *Handlers) HandleAir(c tele.Context) error {
 	chatID := c.Sender().ID
-	logger.Debug("Received /air command",
-		zap.Int64("chat_id", chatID),
-		zap.Strings("args", c.Args()))
-
-	// Get user
-	user, err := h.userRepo.GetOrCreate(chatID)
-	if err != nil {
-		logger.Error("Failed to get user",
-			zap.Int64("chat_id", chatID),
-			zap.Error(err))
-		return c.Send("抱歉,系统出现错误,请稍后再试。")
-	}
+	user := userFrom(c)
 
 	// Get city from args or subscription
 	var city string
 	args := c.Args()
+	if n := len(args); n > 0 && (args[n-1] == "stations" || args[n-1] == "监测站") {
+		return h.airStations(c, args[:n-1])
+	}
 	if len(args) > 0 {
 		city = args[0]
 		logger.Debug("City from args", zap.String("city", city))
@@ -690,6 +1095,11 @@ func (h *Handlers) HandleAir(c tele.Context) error {
 		}
 	}
 
+	key := reportKey("air", city)
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
+	}
+
 	// Get air quality report
 	report, err := h.airSvc.GetAirQualityReport(city)
 	if err != nil {
@@ -703,21 +1113,80 @@ func (h *Handlers) HandleAir(c tele.Context) error {
 	logger.Info("Air quality report sent",
 		zap.Int64("chat_id", chatID),
 		zap.String("city", city))
-	return c.Send(report)
+	h.rememberReport(c, key, report)
+	return c.Send(h.withUpdatedAt(report), refreshMarkup(btnRefreshAir, city))
 }
 
-// HandleWarning handles the /warning [city] command
-func (h *Handlers) HandleWarning(c tele.Context) error {
+// airStations handles /air [city] stations: the monitoring stations near the coordinates of the
+// user's subscription to the city when it has them, else near the city center
+func (h *Handlers) airStations(c tele.Context, args []string) error {
 	chatID := c.Sender().ID
-	logger.Debug("Received /warning command", zap.Int64("chat_id", chatID))
+	user := userFrom(c)
+
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions",
+			zap.Int64("chat_id", chatID),
+			zap.Uint("user_id", user.ID),
+			zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	var city string
+	var sub *model.Subscription
+	if len(args) > 0 {
+		city = args[0]
+	} else if len(subs) > 0 {
+		city = subs[0].City
+	} else {
+		return c.Send("❌ 请指定城市或先使用 /subscribe 订阅\n用法: /air <城市> stations")
+	}
+	for i := range subs {
+		if subs[i].City == city && subs[i].HasCoordinates() {
+			sub = &subs[i]
+			break
+		}
+	}
 
-	// Get user
-	user, err := h.userRepo.FindByChatID(chatID)
-	if err != nil || user == nil {
-		logger.Error("Failed to get user", zap.Int64("chat_id", chatID), zap.Error(err))
-		return c.Send("获取用户信息失败，请先使用 /start 命令注册")
+	key := reportKey("stations", city)
+	if sub != nil {
+		key = reportKey("stations", sub.Lat, sub.Lon)
+	}
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
 	}
 
+	place, lat, lon := city, "", ""
+	if sub != nil {
+		place = fmt.Sprintf("%s（定位 %s,%s）", city, sub.Lat, sub.Lon)
+		lat, lon = sub.Lat, sub.Lon
+	} else {
+		loc, err := h.weatherSvc.ResolveLocation(city, service.LocationHintCity)
+		if err != nil {
+			return c.Send(fmt.Sprintf("❌ 无法获取 %s 的空气质量信息，请检查城市名称是否正确。", city))
+		}
+		lat, lon = loc.Lat, loc.Lon
+	}
+
+	report, err := h.airSvc.GetStationsReport(place, lat, lon)
+	if err != nil {
+		logger.Warn("Failed to get air stations report",
+			zap.Int64("chat_id", chatID),
+			zap.String("city", city),
+			zap.Error(err))
+		return c.Send(fmt.Sprintf("❌ 暂时无法获取 %s 附近监测站的数据，请稍后再试。", city))
+	}
+	logger.Info("Air stations report sent",
+		zap.Int64("chat_id", chatID),
+		zap.String("city", city))
+	h.rememberReport(c, key, report)
+	return c.Send(h.withUpdatedAt(report))
+}
+
+// HandleWarning handles the /warning [city] command
+func (h *Handlers) HandleWarning(c tele.Context) error {
+	chatID := c.Sender().ID
+	user := userFrom(c)
+
 	// Determine city to query
 	var city string
 	args := c.Args()
@@ -748,6 +1217,11 @@ func (h *Handlers) HandleWarning(c tele.Context) error {
 		zap.Int64("chat_id", chatID),
 		zap.String("city", city))
 
+	key := reportKey("warning", city)
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
+	}
+
 	// Get warning report
 	report, err := h.warningSvc.GetWarningReport(city)
 	if err != nil {
@@ -761,20 +1235,13 @@ func (h *Handlers) HandleWarning(c tele.Context) error {
 	logger.Info("Weather warning report sent",
 		zap.Int64("chat_id", chatID),
 		zap.String("city", city))
+	h.rememberReport(c, key, report)
 	return c.Send(report)
 }
 
 // HandleWarningToggle handles the /warning_toggle command
 func (h *Handlers) HandleWarningToggle(c tele.Context) error {
-	chatID := c.Sender().ID
-	logger.Debug("Received /warning_toggle command", zap.Int64("chat_id", chatID))
-
-	// Get user
-	user, err := h.userRepo.FindByChatID(chatID)
-	if err != nil || user == nil {
-		logger.Error("Failed to get user", zap.Int64("chat_id", chatID), zap.Error(err))
-		return c.Send("获取用户信息失败，请先使用 /start 命令注册")
-	}
+	user := userFrom(c)
 
 	// Get all active subscriptions
 	subs, err := h.subRepo.FindByUserID(user.ID)
@@ -829,3 +1296,100 @@ func (h *Handlers) HandleWarningToggle(c tele.Context) error {
 
 	return c.Send(response.String())
 }
+
+// HandleAnnouncementToggle handles the /announcement_toggle command
+func (h *Handlers) HandleAnnouncementToggle(c tele.Context) error {
+	user := userFrom(c)
+
+	off := !user.AnnouncementsOff
+	if err := h.userRepo.SetAnnouncementsOff(user.ID, off); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	logger.Info("Announcement preference toggled",
+		zap.Uint("user_id", user.ID),
+		zap.Bool("announcements_off", off))
+
+	if off {
+		return c.Send("🔕 已关闭公告推送\n再次发送 /announcement_toggle 可重新开启")
+	}
+	return c.Send("🔔 已开启公告推送\n维护通知、新功能等公告将推送给您")
+}
+
+// voiceModeLabels describes each voice mode for /voice replies
+var voiceModeLabels = map[string]string{
+	model.VoiceModeOff:  "仅文字",
+	model.VoiceModeBoth: "文字 + 语音",
+	model.VoiceModeOnly: "仅语音",
+}
+
+// HandleVoice handles the /voice [off|both|only] command, choosing how daily reminders are delivered
+func (h *Handlers) HandleVoice(c tele.Context) error {
+	user := userFrom(c)
+
+	if !h.notifySvc.VoiceEnabled() {
+		return c.Send("❌ 语音播报功能未开启，请联系管理员")
+	}
+
+	current := user.VoiceMode
+	if current == "" {
+		current = model.VoiceModeOff
+	}
+
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(fmt.Sprintf("🔊 每日提醒播报方式：%s\n\n用法:\n/voice off - 仅文字\n/voice both - 文字后附带语音消息\n/voice only - 仅发送语音消息",
+			voiceModeLabels[current]))
+	}
+
+	mode := strings.ToLower(args[0])
+	if _, ok := voiceModeLabels[mode]; !ok {
+		return c.Send("❌ 无效的选项\n用法: /voice [off|both|only]")
+	}
+	if err := h.userRepo.SetVoiceMode(user.ID, mode); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	logger.Info("Voice mode updated",
+		zap.Uint("user_id", user.ID),
+		zap.String("voice_mode", mode))
+
+	return c.Send(fmt.Sprintf("✅ 每日提醒播报方式已设置为：%s", voiceModeLabels[mode]))
+}
+
+// HandleCombine handles the /combine [on|off] command, choosing whether subscriptions due at the
+// same time share one combined reminder
+func (h *Handlers) HandleCombine(c tele.Context) error {
+	user := userFrom(c)
+
+	args := c.Args()
+	if len(args) == 0 {
+		status := "未开启"
+		if user.CombinedDigest {
+			status = "已开启"
+		}
+		return c.Send(fmt.Sprintf("📦 合并推送：%s\n\n开启后，提醒时间相同的多个城市订阅将合并为一条消息（各城市天气一览 + 统一的待办清单）\n\n用法:\n/combine on - 开启合并推送\n/combine off - 每个城市单独推送", status))
+	}
+
+	var combined bool
+	switch strings.ToLower(args[0]) {
+	case "on", "开启":
+		combined = true
+	case "off", "关闭":
+		combined = false
+	default:
+		return c.Send("❌ 无效的选项\n用法: /combine [on|off]")
+	}
+	if err := h.userRepo.SetCombinedDigest(user.ID, combined); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	logger.Info("Combined digest preference updated",
+		zap.Uint("user_id", user.ID),
+		zap.Bool("combined", combined))
+
+	if combined {
+		return c.Send("✅ 已开启合并推送\n提醒时间相同的城市将合并为一条消息；合并消息使用固定模板，不含 AI 文案和生活指数详情")
+	}
+	return c.Send("✅ 已关闭合并推送，每个城市将单独推送")
+}
@@ -0,0 +1,150 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// Limits of the users' calendar feeds
+const (
+	calendarFeedURLMaxLen = 512 // Length of a feed URL
+	calendarFeedNameMax   = 20  // Length of a feed name
+)
+
+// SetCalendarFeeds enables the /ics command managing the users' iCalendar feeds
+func (h *Handlers) SetCalendarFeeds(feeds *service.CalendarFeedService) {
+	h.calendarFeeds = feeds
+}
+
+// HandleICS handles the /ics [add <地址> [名称] | delete <编号>] command, managing the iCalendar
+// feeds (school timetables, team calendars) whose events of the day are listed in the user's
+// daily reminders
+func (h *Handlers) HandleICS(c tele.Context) error {
+	if h.calendarFeeds == nil {
+		return c.Send("❌ 日历订阅功能未开启，请联系管理员")
+	}
+	user := userFrom(c)
+
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(h.calendarFeedsStatus(user), &tele.SendOptions{DisableWebPagePreview: true})
+	}
+	switch strings.ToLower(args[0]) {
+	case "add", "添加":
+		return h.addCalendarFeed(c, user, args[1:])
+	case "delete", "del", "删除":
+		return h.deleteCalendarFeed(c, user, args[1:])
+	}
+	return c.Send(calendarFeedsUsage(h.calendarFeeds.MaxPerUser()))
+}
+
+// addCalendarFeed subscribes the user to a calendar from "<地址> [名称]"
+func (h *Handlers) addCalendarFeed(c tele.Context, user *model.User, args []string) error {
+	if len(args) == 0 {
+		return c.Send("用法: /ics add <地址> [名称]\n示例: /ics add https://example.com/timetable.ics 课表")
+	}
+	rawURL := args[0]
+	if len(rawURL) > calendarFeedURLMaxLen {
+		return c.Send(fmt.Sprintf("❌ 日历地址过长（最多 %d 个字符）", calendarFeedURLMaxLen))
+	}
+	name := strings.Join(args[1:], " ")
+	if utf8.RuneCountInString(name) > calendarFeedNameMax {
+		return c.Send(fmt.Sprintf("❌ 日历名称最多 %d 个字", calendarFeedNameMax))
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return c.Send("❌ 日历名称不能包含特殊符号")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	feed, err := h.calendarFeeds.Add(ctx, user.ID, rawURL, name)
+	if errors.Is(err, service.ErrCalendarFeedLimit) {
+		return c.Send(fmt.Sprintf("❌ 最多订阅 %d 个日历，可先用 /ics delete <编号> 删除", h.calendarFeeds.MaxPerUser()))
+	}
+	if err != nil {
+		logger.Info("Rejected calendar feed", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("❌ 无法读取该日历，请确认是以 http://、https:// 或 webcal:// 开头的 ICS 订阅地址")
+	}
+	return c.Send(fmt.Sprintf("✅ 已订阅日历「%s」\n每天清晨同步一次，当天的日程将出现在每日提醒的日历之后", feed.Name))
+}
+
+// deleteCalendarFeed unsubscribes the user from a calendar by its number in the /ics list
+func (h *Handlers) deleteCalendarFeed(c tele.Context, user *model.User, args []string) error {
+	if len(args) != 1 {
+		return c.Send("用法: /ics delete <编号>")
+	}
+	index, err := strconv.Atoi(args[0])
+	if err != nil || index < 1 {
+		return c.Send("❌ 请输入有效的编号")
+	}
+	feeds, err := h.calendarFeeds.List(user.ID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if index > len(feeds) {
+		return c.Send("❌ 没有这个编号的日历，发送 /ics 查看列表")
+	}
+	feed := feeds[index-1]
+	if _, err := h.calendarFeeds.Delete(user.ID, feed.ID); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	return c.Send(fmt.Sprintf("🗑 已取消订阅日历「%s」", feed.Name))
+}
+
+// calendarFeedsStatus lists the user's calendar feeds with their last sync, and the usage. URLs
+// often carry a private token, so only their host is shown.
+func (h *Handlers) calendarFeedsStatus(user *model.User) string {
+	feeds, err := h.calendarFeeds.List(user.ID)
+	if err != nil {
+		logger.Warn("Failed to list calendar feeds", zap.Uint("user_id", user.ID), zap.Error(err))
+		return "抱歉,系统出现错误,请稍后再试。"
+	}
+	var b strings.Builder
+	if len(feeds) == 0 {
+		b.WriteString("🗓 日历订阅：暂无\n")
+	} else {
+		b.WriteString("🗓 日历订阅：\n")
+		for i, feed := range feeds {
+			host := feed.URL
+			if u, err := url.Parse(feed.URL); err == nil && u.Host != "" {
+				host = u.Host
+			}
+			line := fmt.Sprintf("%d. %s（%s）", i+1, feed.Name, host)
+			switch {
+			case feed.SyncError != "":
+				line += " - ⚠️ 最近一次同步失败"
+				if feed.SyncedAt != nil {
+					line += fmt.Sprintf("，沿用 %s 的日程", feed.SyncedAt.In(h.timezone).Format("01-02 15:04"))
+				}
+			case feed.SyncedAt != nil:
+				line += " - 同步于 " + feed.SyncedAt.In(h.timezone).Format("01-02 15:04")
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(calendarFeedsUsage(h.calendarFeeds.MaxPerUser()))
+	return b.String()
+}
+
+// calendarFeedsUsage describes the /ics command
+func calendarFeedsUsage(max int) string {
+	return fmt.Sprintf(`用法:
+/ics add <地址> [名称] - 订阅 ICS 日历（课表、团队日历等），如 /ics add https://example.com/timetable.ics 课表
+/ics delete <编号> - 取消订阅
+
+每天清晨同步一次，当天的日程列在每日提醒的日历之后；最多订阅 %d 个日历`, max)
+}
@@ -0,0 +1,103 @@
+package bot
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// processedUpdateRetention is how long update IDs are remembered in the database;
+	// Telegram keeps unconfirmed updates for at most 24 hours
+	processedUpdateRetention = 48 * time.Hour
+	// pruneEvery is the number of recorded updates between database prunes
+	pruneEvery = 1000
+)
+
+// UpdateDeduplicator drops Telegram updates that were already handled, e.g. when a long-poll
+// request times out after the updates were processed and Telegram delivers them again.
+// Recent IDs are kept in an in-memory ring buffer; the optional repository persists them so
+// that updates redelivered after a restart are also ignored. Update IDs are per bot, so each bot
+// has its own deduplicator.
+type UpdateDeduplicator struct {
+	repo *repository.ProcessedUpdateRepository
+	bot  string // Name of the bot ("" = the primary bot)
+
+	mu      sync.Mutex
+	ring    []int
+	next    int
+	seen    map[int]bool
+	records int
+}
+
+// NewUpdateDeduplicator creates an UpdateDeduplicator of a bot's updates remembering the last size
+// update IDs in memory
+func NewUpdateDeduplicator(repo *repository.ProcessedUpdateRepository, bot string, size int) *UpdateDeduplicator {
+	return &UpdateDeduplicator{
+		repo: repo,
+		bot:  bot,
+		ring: make([]int, size),
+		seen: make(map[int]bool, size),
+	}
+}
+
+// Middleware returns a telebot middleware that skips already processed updates.
+// It must be installed with Bot.Use before handlers are registered.
+func (d *UpdateDeduplicator) Middleware() tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			updateID := c.Update().ID
+			if !d.firstSeen(updateID) {
+				logger.Info("Ignoring duplicate update", zap.Int("update_id", updateID))
+				return nil
+			}
+			return next(c)
+		}
+	}
+}
+
+// firstSeen records the update ID and reports whether it had not been processed before.
+// Storage errors fail open: processing an update twice is better than dropping it.
+func (d *UpdateDeduplicator) firstSeen(updateID int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.seen[updateID] {
+		return false
+	}
+
+	if d.repo != nil {
+		isNew, err := d.repo.MarkProcessed(d.bot, updateID)
+		if err == nil && !isNew {
+			d.remember(updateID)
+			return false
+		}
+
+		d.records++
+		if d.records%pruneEvery == 0 {
+			go func() {
+				_ = d.repo.DeleteBefore(time.Now().Add(-processedUpdateRetention))
+			}()
+		}
+	}
+
+	d.remember(updateID)
+	return true
+}
+
+// remember adds an update ID to the ring buffer, evicting the oldest entry
+func (d *UpdateDeduplicator) remember(updateID int) {
+	if len(d.ring) == 0 {
+		return
+	}
+	if evicted := d.ring[d.next]; evicted != 0 {
+		delete(d.seen, evicted)
+	}
+	d.ring[d.next] = updateID
+	d.seen[updateID] = true
+	d.next = (d.next + 1) % len(d.ring)
+}
@@ -0,0 +1,165 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// indexUsage is the usage of /index
+const indexUsage = "用法:\n/index [城市] <指数> [好|较好|级别] - 指数达标的早上单独提醒\n/index del <编号> - 删除指数提醒\n示例: /index 洗车、/index 北京 运动 较好"
+
+// HandleIndex handles /index: lists, adds ([城市] <指数> [条件]) or deletes (del <编号>) life
+// index watches, which push a standalone message on days the index reaches the wanted level
+func (h *Handlers) HandleIndex(c tele.Context) error {
+	user := userFrom(c)
+	args := c.Args()
+	repo := h.indexWatch.Repository()
+
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	if len(args) == 0 {
+		watches, err := repo.FindByUser(user.ID)
+		if err != nil {
+			logger.Error("Failed to find index watches", zap.Uint("user_id", user.ID), zap.Error(err))
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		return c.Send(formatIndexWatches(watches, subs))
+	}
+
+	if args[0] == "del" || args[0] == "删除" {
+		if len(args) < 2 {
+			return c.Send("❌ 请指定要删除的编号\n示例: /index del 1")
+		}
+		id, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return c.Send("❌ 编号无效，请使用 /index 查看编号")
+		}
+		deleted, err := repo.Delete(uint(id), user.ID)
+		if err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		if !deleted {
+			return c.Send("❌ 未找到该指数提醒，请使用 /index 查看编号")
+		}
+		logger.Info("Index watch deleted", zap.Uint("user_id", user.ID), zap.Uint64("id", id))
+		return c.Send("✅ 已删除该指数提醒")
+	}
+
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市\n请先使用 /subscribe <城市> <时间> 创建订阅")
+	}
+
+	isIndex := func(arg string) bool {
+		_, ok := service.ParseLifeIndex(arg)
+		return ok
+	}
+	targetSub, args, err := h.targetSubscription(c, cityChoiceIndex, subs, args, isIndex, "/index "+subs[0].City+" 洗车")
+	if targetSub == nil {
+		return err
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return c.Send("❌ " + indexUsage)
+	}
+
+	indexType, ok := service.ParseLifeIndex(args[0])
+	if !ok {
+		return c.Send(fmt.Sprintf("❌ 未知的指数：%s\n可选：%s", args[0], lifeIndexList()))
+	}
+	cond := ""
+	if len(args) == 2 {
+		cond = args[1]
+	}
+	maxLevel, ok := service.ParseIndexCondition(cond)
+	if !ok {
+		return c.Send("❌ 条件无效，可用：好、较好或级别数字（1 为最好）")
+	}
+
+	// Changing the condition of an existing alert does not count against the tier's limit
+	watches, err := repo.FindByUser(user.ID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	exists := false
+	for _, w := range watches {
+		if w.SubscriptionID == targetSub.ID && w.IndexType == indexType {
+			exists = true
+		}
+	}
+	if limit := h.tierSvc.Limits(user, time.Now()).IndexWatches; !exists && len(watches) >= limit {
+		return c.Send(fmt.Sprintf("❌ 生活指数提醒已达上限（%d个）\n请先使用 /index del <编号> 删除部分提醒，或发送 /tier 查看套餐", limit))
+	}
+
+	watch := &model.IndexWatch{
+		UserID:         user.ID,
+		SubscriptionID: targetSub.ID,
+		IndexType:      indexType,
+		MaxLevel:       maxLevel,
+	}
+	if err := repo.Upsert(watch); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Index watch saved",
+		zap.Uint("subscription_id", targetSub.ID),
+		zap.String("index_type", indexType),
+		zap.Int("max_level", maxLevel))
+	return c.Send(fmt.Sprintf("✅ 已设置：%s 的%s指数%s时，在 %s 的每日提醒后单独提醒您",
+		targetSub.City, service.LifeIndexName(indexType), indexLevelText(maxLevel), targetSub.ReminderTime))
+}
+
+// formatIndexWatches lists a user's index watches with the numbers used by /index del
+func formatIndexWatches(watches []model.IndexWatch, subs []model.Subscription) string {
+	cities := make(map[uint]string, len(subs))
+	for _, sub := range subs {
+		cities[sub.ID] = sub.City
+	}
+
+	var b strings.Builder
+	b.WriteString("🔔 生活指数提醒\n\n")
+	shown := 0
+	for _, w := range watches {
+		city, ok := cities[w.SubscriptionID]
+		if !ok {
+			continue // subscription cancelled
+		}
+		b.WriteString(fmt.Sprintf("%d. %s · %s指数%s\n", w.ID, city, service.LifeIndexName(w.IndexType), indexLevelText(w.MaxLevel)))
+		shown++
+	}
+	if shown == 0 {
+		b.WriteString("暂无指数提醒\n")
+	}
+	b.WriteString("\n" + indexUsage + "\n\n可选指数：" + lifeIndexList())
+	return b.String()
+}
+
+// indexLevelText describes the wanted level of a watch
+func indexLevelText(maxLevel int) string {
+	switch maxLevel {
+	case 1:
+		return "为最好等级"
+	case 2:
+		return "为较好及以上"
+	default:
+		return fmt.Sprintf("等级不超过 %d 级", maxLevel)
+	}
+}
+
+// lifeIndexList lists the names of the supported life indices
+func lifeIndexList() string {
+	names := make([]string, 0, len(service.LifeIndexNames))
+	for _, idx := range service.LifeIndexNames {
+		names = append(names, idx.Name)
+	}
+	return strings.Join(names, "、")
+}
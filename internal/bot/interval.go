@@ -0,0 +1,220 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/timeparse"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// Defaults of /interval when the window is not given
+const (
+	defaultIntervalStart = "09:00"
+	defaultIntervalEnd   = "18:00"
+)
+
+// intervalKinds maps the kind words accepted by /interval to interval reminder kinds
+var intervalKinds = map[string]string{
+	"water": service.IntervalKindWater, "喝水": service.IntervalKindWater, "水": service.IntervalKindWater,
+	"stretch": service.IntervalKindStretch, "活动": service.IntervalKindStretch, "拉伸": service.IntervalKindStretch, "久坐": service.IntervalKindStretch,
+}
+
+// intervalUsage is the usage of the /interval command
+const intervalUsage = `用法:
+/interval water <间隔> [开始-结束] [workdays|daily] - 喝水提醒
+/interval stretch <间隔> [开始-结束] [workdays|daily] - 久坐活动提醒
+  示例: /interval water 2h 09:00-18:00 workdays
+  间隔为 15 分钟的整数倍，如 30m、90m、2h；时段默认 09:00-18:00；workdays 仅工作日（含调休）
+/interval water off - 关闭喝水提醒（/interval off 全部关闭）
+/interval quiet 12:00-13:30 - 设置免打扰时段，期间不发送（/interval quiet off 取消）`
+
+// HandleInterval handles the /interval command, managing the user's water and stretch reminders
+func (h *Handlers) HandleInterval(c tele.Context) error {
+	if h.intervalSvc == nil {
+		return c.Send("❌ 间隔提醒功能未开启，请联系管理员")
+	}
+	user := userFrom(c)
+
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(h.intervalStatus(user))
+	}
+
+	switch arg := strings.ToLower(args[0]); arg {
+	case "off":
+		if _, err := h.intervalSvc.Remove(user.ID, ""); err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		logger.Info("Interval reminders removed", zap.Uint("user_id", user.ID))
+		return c.Send("🔕 已关闭全部间隔提醒")
+	case "quiet":
+		return h.handleIntervalQuiet(c, user, args[1:])
+	default:
+		kind, ok := intervalKinds[arg]
+		if !ok {
+			return c.Send(intervalUsage)
+		}
+		return h.handleIntervalSet(c, user, kind, args[1:])
+	}
+}
+
+// handleIntervalSet handles /interval <kind> <interval>|off [start-end] [workdays|daily]
+func (h *Handlers) handleIntervalSet(c tele.Context, user *model.User, kind string, args []string) error {
+	if len(args) == 0 {
+		return c.Send(intervalUsage)
+	}
+	name := service.IntervalKindName(kind)
+	if strings.EqualFold(args[0], "off") {
+		removed, err := h.intervalSvc.Remove(user.ID, kind)
+		if err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		if !removed {
+			return c.Send(fmt.Sprintf("ℹ️ 没有设置%s提醒", name))
+		}
+		logger.Info("Interval reminder removed", zap.Uint("user_id", user.ID), zap.String("kind", kind))
+		return c.Send(fmt.Sprintf("🔕 已关闭%s提醒", name))
+	}
+
+	minutes, err := parseIntervalMinutes(args[0])
+	if err != nil {
+		return c.Send("❌ 间隔格式错误，例如 30m、90m 或 2h")
+	}
+	start, end := defaultIntervalStart, defaultIntervalEnd
+	workdaysOnly := false
+	for _, arg := range args[1:] {
+		switch strings.ToLower(arg) {
+		case "workdays", "workday", "工作日":
+			workdaysOnly = true
+		case "daily", "每天":
+			workdaysOnly = false
+		default:
+			start, end, err = parseTimeRange(arg)
+			if err != nil {
+				return c.Send("❌ 时段格式错误，例如 09:00-18:00")
+			}
+		}
+	}
+
+	reminder, err := h.intervalSvc.Set(user.ID, kind, minutes, start, end, workdaysOnly)
+	if err != nil {
+		return c.Send("❌ 设置失败：间隔需为 15 分钟的整数倍（15 分钟到 4 小时），结束时间需晚于开始时间")
+	}
+	logger.Info("Interval reminder set",
+		zap.Uint("user_id", user.ID),
+		zap.String("kind", kind),
+		zap.Int("interval_minutes", minutes))
+
+	slots, _ := service.IntervalSlots(*reminder)
+	reply := fmt.Sprintf("✅ 已开启%s提醒：%s\n提醒时间：%s", name, describeInterval(*reminder), strings.Join(slots, "、"))
+	if user.QuietHours != "" {
+		reply += fmt.Sprintf("\n免打扰时段 %s 内不会发送", user.QuietHours)
+	}
+	return c.Send(reply)
+}
+
+// handleIntervalQuiet handles /interval quiet <start-end>|off
+func (h *Handlers) handleIntervalQuiet(c tele.Context, user *model.User, args []string) error {
+	if len(args) == 0 {
+		return c.Send("用法: /interval quiet 12:00-13:30 或 /interval quiet off")
+	}
+	quiet := ""
+	if !strings.EqualFold(args[0], "off") {
+		start, end, err := parseTimeRange(args[0])
+		if err != nil || start == end {
+			return c.Send("❌ 时段格式错误，例如 12:00-13:30 或 22:00-07:00")
+		}
+		quiet = start + "-" + end
+	}
+	if err := h.userRepo.SetQuietHours(user.ID, quiet); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Quiet hours updated", zap.Uint("user_id", user.ID), zap.String("quiet_hours", quiet))
+	if quiet == "" {
+		return c.Send("✅ 已取消免打扰时段")
+	}
+	return c.Send(fmt.Sprintf("✅ 免打扰时段已设为 %s，期间不发送间隔提醒", quiet))
+}
+
+// intervalStatus describes the user's interval reminders and the command usage
+func (h *Handlers) intervalStatus(user *model.User) string {
+	reminders, err := h.intervalSvc.List(user.ID)
+	if err != nil {
+		return "抱歉,系统出现错误,请稍后再试。"
+	}
+
+	var status strings.Builder
+	status.WriteString("⏰ 间隔提醒\n\n")
+	if len(reminders) == 0 {
+		status.WriteString("暂未开启\n")
+	}
+	for _, reminder := range reminders {
+		status.WriteString(fmt.Sprintf("• %s：%s\n", service.IntervalKindName(reminder.Kind), describeInterval(reminder)))
+	}
+	if user.QuietHours != "" {
+		status.WriteString(fmt.Sprintf("🤫 免打扰：%s\n", user.QuietHours))
+	}
+	return status.String() + "\n" + intervalUsage
+}
+
+// describeInterval describes the schedule of a reminder, e.g. "工作日 09:00-18:00 每 2 小时"
+func describeInterval(reminder model.IntervalReminder) string {
+	days := "每天"
+	if reminder.WorkdaysOnly {
+		days = "工作日"
+	}
+	every := fmt.Sprintf("%d 分钟", reminder.IntervalMinutes)
+	if reminder.IntervalMinutes%60 == 0 {
+		every = fmt.Sprintf("%d 小时", reminder.IntervalMinutes/60)
+	}
+	return fmt.Sprintf("%s %s-%s 每 %s", days, reminder.StartTime, reminder.EndTime, every)
+}
+
+// parseIntervalMinutes parses an interval such as "30m", "90min", "2h", "1.5h", "2小时" or "30分钟";
+// a bare number is minutes
+func parseIntervalMinutes(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	unit := 1.0
+	for _, suffix := range []struct {
+		text  string
+		scale float64
+	}{{"小时", 60}, {"分钟", 1}, {"min", 1}, {"h", 60}, {"m", 1}} {
+		if strings.HasSuffix(s, suffix.text) {
+			s = strings.TrimSuffix(s, suffix.text)
+			unit = suffix.scale
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+	return int(value*unit + 0.5), nil
+}
+
+// parseTimeRange parses a daily window such as "09:00-18:00", "9点~18点" or "9:00到18:00" into
+// two HH:MM times
+func parseTimeRange(s string) (string, string, error) {
+	for _, sep := range []string{"-", "~", "～", "到", "至"} {
+		from, to, ok := strings.Cut(s, sep)
+		if !ok {
+			continue
+		}
+		start, err := timeparse.Parse(from)
+		if err != nil {
+			return "", "", err
+		}
+		end, err := timeparse.Parse(to)
+		if err != nil {
+			return "", "", err
+		}
+		return start, end, nil
+	}
+	return "", "", fmt.Errorf("invalid time range %q", s)
+}
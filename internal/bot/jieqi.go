@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/jieqi"
+	tele "gopkg.in/telebot.v3"
+)
+
+// SetSolarTerms enables the /jieqi command looking up the care tips of the solar terms
+func (h *Handlers) SetSolarTerms(solarTerms *service.SolarTermService) {
+	h.solarTerms = solarTerms
+}
+
+// HandleJieQi handles the /jieqi [节气] command: the care tips of the current solar term, or of
+// the named one, with the date it next starts
+func (h *Handlers) HandleJieQi(c tele.Context) error {
+	if h.solarTerms == nil {
+		return c.Send("❌ 节气养生功能未开启，请联系管理员")
+	}
+	now := time.Now().In(h.timezone)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, h.timezone)
+
+	args := c.Args()
+	if len(args) == 0 {
+		name, start := h.solarTerms.Current(now)
+		term, ok := jieqi.Lookup(name)
+		if !ok {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		var b strings.Builder
+		if start.Equal(today) {
+			b.WriteString(fmt.Sprintf("🌿 今天是%s\n", term.Name))
+		} else {
+			b.WriteString(fmt.Sprintf("🌿 当前节气：%s（%d月%d日起）\n", term.Name, start.Month(), start.Day()))
+		}
+		b.WriteString(formatSolarTerm(term))
+		if next, date := h.solarTerms.Next(now); next != "" {
+			b.WriteString(fmt.Sprintf("\n⏭ 下一个节气：%s（%d月%d日，还有 %d 天）\n", next, date.Month(), date.Day(), daysUntil(today, date)))
+		}
+		b.WriteString("\n查看其他节气: /jieqi <节气>，如 /jieqi 冬至")
+		return c.Send(b.String())
+	}
+
+	term, ok := jieqi.Lookup(strings.TrimSpace(args[0]))
+	if !ok {
+		return c.Send("❌ 未知的节气，可选：" + strings.Join(jieqi.Names(), "、"))
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🌿 %s\n", term.Name))
+	b.WriteString(formatSolarTerm(term))
+	if date := h.solarTerms.NextDate(term.Name, today); !date.IsZero() {
+		if date.Equal(today) {
+			b.WriteString("\n📅 就是今天\n")
+		} else {
+			b.WriteString(fmt.Sprintf("\n📅 下次%s：%d年%d月%d日（还有 %d 天）\n", term.Name, date.Year(), date.Month(), date.Day(), daysUntil(today, date)))
+		}
+	}
+	return c.Send(b.String())
+}
+
+// formatSolarTerm writes what a solar term marks and its care tips
+func formatSolarTerm(term jieqi.Term) string {
+	var b strings.Builder
+	b.WriteString(term.Summary + "\n\n养生小贴士：\n")
+	for _, tip := range term.Tips {
+		b.WriteString("• " + tip + "\n")
+	}
+	return b.String()
+}
+
+// daysUntil returns the number of days from today to a date (both midnights)
+func daysUntil(today, date time.Time) int {
+	return int(date.Sub(today).Round(24*time.Hour) / (24 * time.Hour))
+}
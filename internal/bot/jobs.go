@@ -0,0 +1,182 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	tele "gopkg.in/telebot.v3"
+)
+
+// Limits of /jobs failed
+const (
+	jobFailedDefault = 10
+	jobFailedMax     = 50
+)
+
+// jobsUsage is the usage of /jobs
+const jobsUsage = `用法（管理员）:
+/jobs - 各定时任务最近一次运行
+/jobs failed [条数] - 最近失败的运行（默认 10 条）
+/jobs retry <编号> - 在后台重新运行一次失败的任务`
+
+// jobNames are the display names of job types
+var jobNames = map[string]string{
+	model.JobTypeWarningSweep:       "预警巡检",
+	model.JobTypeWarningTypes:       "预警类型同步",
+	model.JobTypePreAlerts:          "预报预警",
+	model.JobTypeTodoStats:          "待办统计",
+	model.JobTypeWebhookCleanup:     "Webhook 清理",
+	model.JobTypeSnapshotCleanup:    "天气响应清理",
+	model.JobTypeToneHints:          "AI 语气学习",
+	model.JobTypeObservationCleanup: "实况清理",
+	model.JobTypeJobCleanup:         "任务记录清理",
+	model.JobTypeCalendarFeeds:      "日历订阅同步",
+}
+
+// cityJobs are the job types whose items are cities
+var cityJobs = map[string]bool{
+	model.JobTypeWarningSweep: true,
+	model.JobTypePreAlerts:    true,
+}
+
+// HandleJobs handles the admin command /jobs (checked by Permissions): shows the most recent run of
+// each scheduled job, lists failed runs, or requeues a failed run
+func (h *Handlers) HandleJobs(c tele.Context) error {
+	args := c.Args()
+	if len(args) == 0 {
+		return h.sendLatestJobs(c)
+	}
+	switch args[0] {
+	case "failed":
+		limit := jobFailedDefault
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				return c.Send(jobsUsage)
+			}
+			limit = min(n, jobFailedMax)
+		}
+		return h.sendFailedJobs(c, limit)
+	case "retry":
+		if len(args) != 2 {
+			return c.Send(jobsUsage)
+		}
+		id, err := strconv.ParseUint(strings.TrimPrefix(args[1], "#"), 10, 64)
+		if err != nil || id == 0 {
+			return c.Send(jobsUsage)
+		}
+		return h.requeueJob(c, uint(id))
+	}
+	return c.Send(jobsUsage)
+}
+
+// sendLatestJobs sends the most recent run of each job type
+func (h *Handlers) sendLatestJobs(c tele.Context) error {
+	runs, err := h.jobSvc.Latest()
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(runs) == 0 {
+		return c.Send("暂无定时任务运行记录")
+	}
+	var b strings.Builder
+	b.WriteString("⚙️ 定时任务（各任务最近一次运行）\n")
+	for _, run := range runs {
+		b.WriteString("\n" + h.formatJobRun(run))
+	}
+	b.WriteString("\n\n/jobs failed 查看失败记录，/jobs retry <编号> 重新运行")
+	return c.Send(b.String())
+}
+
+// sendFailedJobs sends the most recent failed runs
+func (h *Handlers) sendFailedJobs(c tele.Context, limit int) error {
+	runs, total, err := h.jobSvc.List(repository.JobRunFilter{Status: model.JobStatusFailed}, 0, limit)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(runs) == 0 {
+		return c.Send("✅ 最近没有失败的定时任务")
+	}
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("❌ 失败的定时任务（最近 %d 条，共 %d 条）\n", len(runs), total))
+	for _, run := range runs {
+		b.WriteString("\n" + h.formatJobRun(run))
+	}
+	return c.Send(b.String())
+}
+
+// requeueJob runs a failed run again in the background
+func (h *Handlers) requeueJob(c tele.Context, id uint) error {
+	run, err := h.jobSvc.Requeue(id)
+	switch {
+	case errors.Is(err, service.ErrJobRunNotFound):
+		return c.Send(fmt.Sprintf("❌ 找不到运行记录 #%d", id))
+	case errors.Is(err, service.ErrJobRunNotFailed):
+		return c.Send(fmt.Sprintf("❌ 运行记录 #%d 没有失败，无需重新运行", id))
+	case errors.Is(err, service.ErrJobUnknown):
+		return c.Send("❌ 该任务当前未启用，无法重新运行")
+	case errors.Is(err, service.ErrJobRunning):
+		return c.Send("⏳ 该任务正在运行，请稍后再试")
+	case err != nil:
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	h.auditSvc.Record(service.TelegramActor(c.Sender().ID), model.AuditJobRequeue, fmt.Sprintf("job:%d", id), "")
+	return c.Send(fmt.Sprintf("🔁 已在后台重新运行 %s（#%d），稍后发送 /jobs 查看结果", jobName(run.Type), run.ID))
+}
+
+// formatJobRun formats a job run on one line, e.g. "✅ 预警巡检 10:45，12 个城市，1 个失败"
+func (h *Handlers) formatJobRun(run model.JobRun) string {
+	icon := "✅"
+	switch run.Status {
+	case model.JobStatusRunning:
+		icon = "⏳"
+	case model.JobStatusFailed:
+		icon = "❌"
+	}
+
+	started := run.StartedAt.In(h.timezone)
+	layout := "01-02 15:04"
+	if started.Format(time.DateOnly) == time.Now().In(h.timezone).Format(time.DateOnly) {
+		layout = "15:04"
+	}
+	line := fmt.Sprintf("%s %s #%d %s", icon, jobName(run.Type), run.ID, started.Format(layout))
+	if run.City != "" {
+		line += "（" + run.City + "）"
+	}
+	if cityJobs[run.Type] && (run.Items > 0 || run.Status == model.JobStatusSucceeded) {
+		line += fmt.Sprintf("，%d 个城市", run.Items)
+		if run.Failures > 0 {
+			line += fmt.Sprintf("，%d 个失败", run.Failures)
+		}
+	} else if run.Items > 0 {
+		line += fmt.Sprintf("，处理 %d 项", run.Items)
+	}
+	if run.Status == model.JobStatusRunning {
+		line += "，运行中"
+	}
+	if run.RequeuedFrom != nil {
+		line += fmt.Sprintf("，重试 #%d", *run.RequeuedFrom)
+	}
+	if run.Error != "" {
+		if detail := []rune(run.Error); len(detail) > 80 {
+			line += "\n   " + string(detail[:79]) + "…"
+		} else {
+			line += "\n   " + run.Error
+		}
+	}
+	return line
+}
+
+// jobName returns the display name of a job type
+func jobName(jobType string) string {
+	if name, ok := jobNames[jobType]; ok {
+		return name
+	}
+	return jobType
+}
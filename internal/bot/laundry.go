@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// laundrySwitches maps the words accepted by /laundry to turning the digest advice on or off
+var laundrySwitches = map[string]bool{
+	"on":  true,
+	"开启":  true,
+	"off": false,
+	"关闭":  false,
+}
+
+// HandleLaundry handles /laundry [城市] [on|off], the drying forecast of the next 48 hours, or
+// turning the drying advice of a subscription's daily reminder on or off
+func (h *Handlers) HandleLaundry(c tele.Context) error {
+	args := c.Args()
+	if len(args) > 0 {
+		if enabled, ok := laundrySwitches[strings.ToLower(args[len(args)-1])]; ok {
+			return h.setLaundry(c, args[:len(args)-1], enabled)
+		}
+	}
+
+	city := strings.Join(args, " ")
+	if city == "" {
+		subs, err := h.subRepo.FindByUserID(userFrom(c).ID)
+		if err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		if len(subs) == 0 {
+			return c.Send("❌ 请指定城市或先使用 /subscribe 订阅\n用法: /laundry <城市>")
+		}
+		city = subs[0].City
+	}
+	key := reportKey("laundry", city)
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
+	}
+	_ = c.Notify(tele.Typing)
+
+	report, err := h.weatherSvc.GetLaundryReport(city, time.Now().In(h.timezone))
+	if err != nil {
+		logger.Error("Failed to get laundry report", zap.String("city", city), zap.Error(err))
+		return c.Send(fmt.Sprintf("❌ 无法获取 %s 的晾晒预报，请稍后再试。", city))
+	}
+	h.rememberReport(c, key, report)
+	return c.Send(report)
+}
+
+// setLaundry turns the drying advice of a subscription's daily reminder on or off; the optional
+// city argument picks the subscription
+func (h *Handlers) setLaundry(c tele.Context, args []string, enabled bool) error {
+	user := userFrom(c)
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市\n请先使用 /subscribe <城市> <时间> 创建订阅")
+	}
+
+	var targetSub *model.Subscription
+	if city := strings.Join(args, " "); city != "" {
+		for i := range subs {
+			if subs[i].MatchesName(city) {
+				targetSub = &subs[i]
+				break
+			}
+		}
+		if targetSub == nil {
+			return c.Send(fmt.Sprintf("❌ 您没有订阅 %s\n您的订阅：%s", city, h.formatCityList(subs)))
+		}
+	} else if len(subs) > 1 {
+		return c.Send(fmt.Sprintf("❌ 请指定城市\n您的订阅：%s\n示例: /laundry %s on", h.formatCityList(subs), subs[0].City))
+	} else {
+		targetSub = &subs[0]
+	}
+
+	if err := h.subRepo.SetLaundry(targetSub.ID, enabled); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Laundry advice toggled",
+		zap.Uint("subscription_id", targetSub.ID),
+		zap.Bool("enabled", enabled))
+	if enabled {
+		return c.Send(fmt.Sprintf("✅ %s 的每日提醒将附上晾晒建议", targetSub.City))
+	}
+	return c.Send(fmt.Sprintf("✅ 已关闭 %s 每日提醒中的晾晒建议", targetSub.City))
+}
@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// HandleLocation starts a subscription for a shared location. The location is resolved to its
+// city or district, and the daily reminder uses grid weather for the exact coordinates.
+func (h *Handlers) HandleLocation(c tele.Context) error {
+	loc := c.Message().Location
+	if loc == nil {
+		return nil
+	}
+	if loc.LivePeriod > 0 {
+		return c.Send("💡 请发送普通位置（而非实时位置）来订阅该位置的天气")
+	}
+
+	// QWeather accepts at most two decimal places (about 1km)
+	lat := fmt.Sprintf("%.2f", loc.Lat)
+	lon := fmt.Sprintf("%.2f", loc.Lng)
+
+	location, err := h.weatherSvc.Client().GetLocationByCoordinates(lat, lon)
+	if err != nil {
+		logger.Warn("Failed to resolve shared location",
+			zap.Int64("chat_id", chatIDOf(c)),
+			zap.String("lat", lat),
+			zap.String("lon", lon),
+			zap.Error(err))
+		return c.Send("❌ 无法识别该位置所在的城市，请稍后再试或使用 /subscribe <城市> <时间> 订阅")
+	}
+
+	data := map[string]string{"city": location.Name, "lat": lat, "lon": lon}
+	if err := h.conversations.Start(botOf(c), chatIDOf(c), flowSubscribe, "time", data); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	return c.Send(fmt.Sprintf("📍 已定位到 %s（%s）\n将使用该位置的格点天气（约 3-5 公里精度）\n\n%s",
+		location.Name, location.Adm1, subscribeTimePrompt(location.Name)))
+}
+
+// gridWeatherNote returns the subscription confirmation note for coordinate subscriptions
+func gridWeatherNote(sub *model.Subscription) string {
+	if !sub.HasCoordinates() {
+		return ""
+	}
+	return fmt.Sprintf("\n\n🛰 已启用格点天气：%s, %s", sub.Lat, sub.Lon)
+}
@@ -0,0 +1,429 @@
+package bot
+
+import (
+	"expvar"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// ctxKeyUser stores the *model.User resolved by LoadUser
+	ctxKeyUser = "user"
+	// ctxKeyBot stores the name of the bot handling the update, set by LoadUser
+	ctxKeyBot = "bot"
+	// ctxKeyLocale stores the locale resolved by Locale
+	ctxKeyLocale = "locale"
+
+	// rateLimitWindow and rateLimitPerWindow bound how many commands a chat may send
+	rateLimitWindow    = time.Minute
+	rateLimitPerWindow = 30
+)
+
+// Command metrics, exposed on the debug listener at /debug/vars
+var (
+	commandCount    = expvar.NewMap("bot_commands_total")
+	commandErrors   = expvar.NewMap("bot_command_errors_total")
+	commandPanics   = expvar.NewMap("bot_command_panics_total")
+	commandDuration = expvar.NewMap("bot_command_duration_ms_total")
+	commandLimited  = expvar.NewMap("bot_command_rate_limited_total")
+	commandDenied   = expvar.NewMap("bot_command_denied_total")
+)
+
+// Middleware-level messages per locale
+const (
+	msgInternalError = "internal_error"
+	msgRateLimited   = "rate_limited"
+	msgDenied        = "denied"
+)
+
+var messages = map[string]map[string]string{
+	"zh": {
+		msgInternalError: "抱歉,系统出现错误,请稍后再试。",
+		msgRateLimited:   "⏳ 操作太频繁，请稍后再试。",
+		msgDenied:        "❌ 您没有使用此命令的权限",
+	},
+	"en": {
+		msgInternalError: "Sorry, something went wrong. Please try again later.",
+		msgRateLimited:   "⏳ Too many requests, please slow down.",
+		msgDenied:        "❌ You are not allowed to use this command.",
+	},
+}
+
+// redactedCommands carry URLs, tokens, addresses or private text (todos, health plans) in their
+// arguments, which are not logged
+var redactedCommands = map[string]bool{
+	"/webhook": true,
+	"/channel": true,
+	"/todo":    true,
+	"/cycle":   true,
+	"/ics":     true, // Calendar URLs often carry private tokens
+}
+
+// redactedCommand reports whether a command, or the command a "/待办"-style alias stands for,
+// has its arguments left out of the logs
+func redactedCommand(command string) bool {
+	return redactedCommands[command] || redactedCommands[commandAliases[strings.TrimPrefix(command, "/")]]
+}
+
+// commandRoles are the roles needed to run staff commands; other commands are open to everyone
+var commandRoles = map[string]string{
+	"/grant":  model.RoleAdmin,
+	"/revoke": model.RoleAdmin,
+	"/audit":  model.RoleAdmin,
+	"/jobs":   model.RoleAdmin,
+	"/obsmod": model.RoleSupport,
+}
+
+// argRole is the role needed to run a command with at least args arguments
+type argRole struct {
+	args int
+	role string
+}
+
+// commandArgRoles are the roles needed to run commands that are open to everyone without
+// arguments but act on other users with them, the strictest matching role applying
+var commandArgRoles = map[string][]argRole{
+	// /tier shows the user's own tier; /tier <chat_id> another user's, /tier <chat_id> <tier> sets it
+	"/tier": {{args: 1, role: model.RoleSupport}, {args: 2, role: model.RoleAdmin}},
+}
+
+// requiredRole returns the role needed to run a command (see commandRoles and commandArgRoles),
+// or false for commands open to everyone
+func requiredRole(command string, args []string) (string, bool) {
+	if role, ok := commandRoles[command]; ok {
+		return role, true
+	}
+	role := ""
+	for _, r := range commandArgRoles[command] {
+		if len(args) >= r.args {
+			role = r.role
+		}
+	}
+	return role, role != ""
+}
+
+// Middlewares returns the command middleware chain, outermost first: long message splitting,
+// panic recovery, metrics, logging, rate limiting, user loading, locale resolution, permission
+// checks and experiment click tracking.
+// Handlers registered after Bot.Use(h.Middlewares(name)...) only contain business logic and
+// read the resolved user with userFrom.
+func (h *Handlers) Middlewares(bot string) []tele.MiddlewareFunc {
+	return []tele.MiddlewareFunc{
+		LongMessages(),
+		Recover(),
+		Metrics(),
+		Logging(),
+		RateLimit(rateLimitPerWindow, rateLimitWindow),
+		LoadUser(h.userRepo, bot),
+		Locale(),
+		Permissions(h.roleSvc),
+		TrackClicks(h.experiments),
+	}
+}
+
+// longMessageContext sends text replies through notify.SendText so they never exceed the Telegram limit
+type longMessageContext struct {
+	tele.Context
+}
+
+// Send splits text replies over the Telegram limit; other content is sent unchanged
+func (c longMessageContext) Send(what interface{}, opts ...interface{}) error {
+	if text, ok := what.(string); ok {
+		_, err := notify.SendText(c.Bot(), c.Recipient(), text, opts...)
+		return err
+	}
+	return c.Context.Send(what, opts...)
+}
+
+// LongMessages lets handlers reply with texts of any length: replies over 4096 characters are
+// split on paragraph and line boundaries, or attached as a document when very long
+func LongMessages() tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			return next(longMessageContext{Context: c})
+		}
+	}
+}
+
+// Recover converts a handler panic into a logged error and an apology to the user
+func Recover() tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					command := commandName(c)
+					commandPanics.Add(command, 1)
+					logger.Error("Handler panicked",
+						zap.String("command", command),
+						zap.Int64("chat_id", chatIDOf(c)),
+						zap.Any("panic", r),
+						zap.ByteString("stack", debug.Stack()))
+					err = c.Send(tr(c, msgInternalError))
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// Metrics counts handled commands, errors and cumulative handling time per command
+func Metrics() tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			command := commandName(c)
+			start := time.Now()
+			err := next(c)
+			commandCount.Add(command, 1)
+			commandDuration.Add(command, time.Since(start).Milliseconds())
+			if err != nil {
+				commandErrors.Add(command, 1)
+			}
+			return err
+		}
+	}
+}
+
+// Logging logs every received command with its arguments and outcome
+func Logging() tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			command := commandName(c)
+			chatID := chatIDOf(c)
+			argsField := zap.Strings("args", c.Args())
+			if redactedCommand(command) {
+				argsField = zap.Int("args_count", len(c.Args()))
+			}
+			var username string
+			if sender := c.Sender(); sender != nil {
+				username = sender.Username
+			}
+			logger.Debug("Received command",
+				zap.String("command", command),
+				zap.Int64("chat_id", chatID),
+				zap.String("username", username),
+				argsField)
+
+			start := time.Now()
+			err := next(c)
+			if err != nil {
+				logger.Error("Command failed",
+					zap.String("command", command),
+					zap.Int64("chat_id", chatID),
+					zap.Duration("duration", time.Since(start)),
+					zap.Error(err))
+				return err
+			}
+			logger.Debug("Command handled",
+				zap.String("command", command),
+				zap.Int64("chat_id", chatID),
+				zap.Duration("duration", time.Since(start)))
+			return nil
+		}
+	}
+}
+
+// Locale resolves the sender's language and stores it in the context.
+// The language reported by the client wins; the stored profile language is the fallback.
+func Locale() tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			var code string
+			if sender := c.Sender(); sender != nil {
+				code = sender.LanguageCode
+			}
+			if user := userFrom(c); code == "" && user != nil {
+				code = user.LanguageCode
+			}
+			c.Set(ctxKeyLocale, localeFor(code))
+			return next(c)
+		}
+	}
+}
+
+// Permissions stops staff commands (see requiredRole) sent by users without the role they need
+func Permissions(roles *service.RoleService) tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			command := commandName(c)
+			role, ok := requiredRole(command, c.Args())
+			if !ok || roles.Has(chatIDOf(c), role) {
+				return next(c)
+			}
+			commandDenied.Add(command, 1)
+			logger.Warn("Permission denied",
+				zap.Int64("chat_id", chatIDOf(c)),
+				zap.String("command", command),
+				zap.String("role", role))
+			return c.Send(tr(c, msgDenied))
+		}
+	}
+}
+
+// TrackClicks records inline button clicks as engagement in the user's running experiments
+func TrackClicks(experiments *service.ExperimentService) tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			if cb := c.Callback(); cb != nil && experiments != nil {
+				if user := userFrom(c); user != nil {
+					experiments.TrackClick(user.ID, cb.Unique)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// RateLimit allows at most limit commands per chat within each fixed window
+func RateLimit(limit int, window time.Duration) tele.MiddlewareFunc {
+	type counter struct {
+		start time.Time
+		count int
+	}
+	var (
+		mu       sync.Mutex
+		counters = make(map[int64]*counter)
+	)
+
+	allow := func(chatID int64, now time.Time) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		cnt, ok := counters[chatID]
+		if !ok || now.Sub(cnt.start) >= window {
+			// Drop expired windows so idle chats do not accumulate
+			for id, other := range counters {
+				if now.Sub(other.start) >= window {
+					delete(counters, id)
+				}
+			}
+			counters[chatID] = &counter{start: now, count: 1}
+			return true
+		}
+		cnt.count++
+		return cnt.count <= limit
+	}
+
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			chatID := chatIDOf(c)
+			if !allow(chatID, time.Now()) {
+				commandLimited.Add(commandName(c), 1)
+				logger.Warn("Rate limit exceeded",
+					zap.Int64("chat_id", chatID),
+					zap.String("command", commandName(c)))
+				return c.Send(tr(c, msgRateLimited))
+			}
+			return next(c)
+		}
+	}
+}
+
+// LoadUser gets or creates the sender's user record in the named bot, refreshes its Telegram
+// profile when it changed, and stores it and the bot name in the context
+func LoadUser(userRepo *repository.UserRepository, bot string) tele.MiddlewareFunc {
+	return func(next tele.HandlerFunc) tele.HandlerFunc {
+		return func(c tele.Context) error {
+			c.Set(ctxKeyBot, bot)
+			chatID := chatIDOf(c)
+			user, err := userRepo.GetOrCreate(bot, chatID)
+			if err != nil {
+				logger.Error("Failed to get user",
+					zap.String("bot", bot),
+					zap.Int64("chat_id", chatID),
+					zap.Error(err))
+				return c.Send(tr(c, msgInternalError))
+			}
+
+			if sender := c.Sender(); sender != nil && sender.ID == chatID {
+				profile := model.UserProfile{
+					Username:     sender.Username,
+					FirstName:    sender.FirstName,
+					LastName:     sender.LastName,
+					LanguageCode: sender.LanguageCode,
+				}
+				// Not every update carries the client language; keep the last known one
+				if profile.LanguageCode == "" {
+					profile.LanguageCode = user.LanguageCode
+				}
+				if user.ProfileSyncedAt == nil || profile != user.UserProfile {
+					// A failed refresh is retried on the next update and must not block the command
+					if err := userRepo.UpdateProfile(user.ID, profile); err == nil {
+						now := time.Now()
+						user.UserProfile = profile
+						user.ProfileSyncedAt = &now
+					}
+				}
+			}
+
+			c.Set(ctxKeyUser, user)
+			return next(c)
+		}
+	}
+}
+
+// userFrom returns the user resolved by LoadUser
+func userFrom(c tele.Context) *model.User {
+	user, _ := c.Get(ctxKeyUser).(*model.User)
+	return user
+}
+
+// botOf returns the name of the bot handling the update, as stored by LoadUser
+func botOf(c tele.Context) string {
+	bot, _ := c.Get(ctxKeyBot).(string)
+	return bot
+}
+
+// localeFor maps a Telegram language code to a supported locale
+func localeFor(code string) string {
+	if strings.HasPrefix(code, "en") {
+		return "en"
+	}
+	return "zh"
+}
+
+// tr returns a middleware-level message in the locale resolved by Locale,
+// or in the sender's client language if Locale has not run yet
+func tr(c tele.Context, key string) string {
+	locale, _ := c.Get(ctxKeyLocale).(string)
+	if locale == "" && c.Sender() != nil {
+		locale = localeFor(c.Sender().LanguageCode)
+	}
+	if msg, ok := messages[locale][key]; ok {
+		return msg
+	}
+	return messages["zh"][key]
+}
+
+// commandName returns the command of a text update without its @botname suffix
+func commandName(c tele.Context) string {
+	if cb := c.Callback(); cb != nil {
+		return "callback"
+	}
+	fields := strings.Fields(c.Text())
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "text"
+	}
+	command, _, _ := strings.Cut(fields[0], "@")
+	return command
+}
+
+// chatIDOf returns the ID used to key users: the sender, falling back to the chat
+func chatIDOf(c tele.Context) int64 {
+	if sender := c.Sender(); sender != nil {
+		return sender.ID
+	}
+	if chat := c.Chat(); chat != nil {
+		return chat.ID
+	}
+	return 0
+}
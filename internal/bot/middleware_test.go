@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"testing"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+)
+
+func TestRedactedCommand(t *testing.T) {
+	tests := map[string]bool{
+		"/webhook":   true,
+		"/todo":      true,
+		"/待办":        true,
+		"/cycle":     true,
+		"/ics":       true,
+		"/日程":        true,
+		"/weather":   false,
+		"/天气":        false,
+		"text":       false,
+		"callback":   false,
+		"/unknown":   false,
+		"/festivals": false,
+	}
+	for command, want := range tests {
+		if got := redactedCommand(command); got != want {
+			t.Errorf("redactedCommand(%q) = %v, want %v", command, got, want)
+		}
+	}
+}
+
+func TestRequiredRole(t *testing.T) {
+	tests := []struct {
+		command string
+		args    []string
+		want    string
+	}{
+		{command: "/grant", args: []string{"123", "admin"}, want: model.RoleAdmin},
+		{command: "/obsmod", want: model.RoleSupport},
+		{command: "/tier"},
+		{command: "/tier", args: []string{"123"}, want: model.RoleSupport},
+		{command: "/tier", args: []string{"123", "premium", "30"}, want: model.RoleAdmin},
+		{command: "/weather", args: []string{"北京"}},
+	}
+	for _, tt := range tests {
+		role, ok := requiredRole(tt.command, tt.args)
+		if role != tt.want || ok != (tt.want != "") {
+			t.Errorf("requiredRole(%s %v) = %q, %v, want %q", tt.command, tt.args, role, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package bot
+
+import (
+	tele "gopkg.in/telebot.v3"
+)
+
+// registerMiniAppHandlers registers /app when the Mini App is enabled
+func (h *Handlers) registerMiniAppHandlers(bot *tele.Bot) {
+	if h.miniAppSvc == nil {
+		return
+	}
+	bot.Handle("/app", h.HandleApp)
+}
+
+// HandleApp handles /app: shows a keyboard button opening the Mini App for todos and settings
+func (h *Handlers) HandleApp(c tele.Context) error {
+	// Keyboard buttons can only open Mini Apps in private chats
+	if c.Chat() == nil || c.Chat().Type != tele.ChatPrivate {
+		return c.Send("🔒 请在与机器人的私聊中使用 /app")
+	}
+	markup := &tele.ReplyMarkup{ResizeKeyboard: true}
+	markup.Reply(markup.Row(markup.WebApp("📱 待办与设置", &tele.WebApp{URL: h.miniAppSvc.URL()})))
+	return c.Send("📱 点击下方键盘中的「待办与设置」按钮打开小程序，可拖动调整待办顺序、勾选完成，并管理订阅与设置", markup)
+}
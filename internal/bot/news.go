@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// newsFeedMaxLen limits the length of users' own feed URLs
+const newsFeedMaxLen = 512
+
+// HandleNews handles the /news [on|off|default|<url>] command, choosing the news headlines of the
+// user's daily reminders
+func (h *Handlers) HandleNews(c tele.Context) error {
+	if h.newsSvc == nil {
+		return c.Send("❌ 新闻要闻功能未开启，请联系管理员")
+	}
+	user := userFrom(c)
+
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(h.newsStatus(user.NewsFeed, user.NewsOff), &tele.SendOptions{DisableWebPagePreview: true})
+	}
+
+	feed, off := user.NewsFeed, user.NewsOff
+	var reply string
+	switch arg := args[0]; strings.ToLower(arg) {
+	case "on":
+		if (feed == "" || !h.newsSvc.UserFeedsAllowed()) && h.newsSvc.DefaultFeed() == "" {
+			return c.Send("❌ 尚未设置新闻源\n用法: /news <RSS地址>")
+		}
+		off = false
+		reply = "✅ 已开启新闻要闻，每日提醒将附带今日要闻"
+	case "off":
+		off = true
+		reply = "🔕 已关闭新闻要闻\n发送 /news on 可重新开启"
+	case "default":
+		if h.newsSvc.DefaultFeed() == "" {
+			feed, off = "", true
+			reply = "✅ 已清除自定义新闻源（管理员未设置默认新闻源，新闻要闻已关闭）"
+		} else {
+			feed, off = "", false
+			reply = "✅ 已恢复使用默认新闻源"
+		}
+	default:
+		if !h.newsSvc.UserFeedsAllowed() {
+			return c.Send("❌ 管理员未开启自定义新闻源\n用法: /news [on|off]")
+		}
+		if len(arg) > newsFeedMaxLen {
+			return c.Send(fmt.Sprintf("❌ RSS 地址过长（最多 %d 个字符）", newsFeedMaxLen))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+		title, err := h.newsSvc.Validate(ctx, arg)
+		if err != nil {
+			logger.Info("Rejected news feed", zap.Uint("user_id", user.ID), zap.Error(err))
+			return c.Send("❌ 无法读取该 RSS/Atom 地址，请确认是以 http:// 或 https:// 开头、包含条目的订阅源")
+		}
+		if title == "" {
+			title = arg
+		}
+		feed, off = arg, false
+		reply = fmt.Sprintf("✅ 新闻源已设置为「%s」，每日提醒将附带今日要闻", title)
+	}
+
+	if err := h.userRepo.SetNews(user.ID, feed, off); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("News preference updated",
+		zap.Uint("user_id", user.ID),
+		zap.Bool("own_feed", feed != ""),
+		zap.Bool("news_off", off))
+	return c.Send(reply, &tele.SendOptions{DisableWebPagePreview: true})
+}
+
+// newsStatus describes the user's news setting and the command usage
+func (h *Handlers) newsStatus(feed string, off bool) string {
+	var status string
+	switch {
+	case off:
+		status = "已关闭"
+	case feed != "" && h.newsSvc.UserFeedsAllowed():
+		status = "自定义新闻源 " + feed
+	case h.newsSvc.DefaultFeed() != "":
+		status = "默认新闻源"
+	default:
+		status = "未设置新闻源"
+	}
+
+	usage := "/news on - 开启\n/news off - 关闭"
+	if h.newsSvc.UserFeedsAllowed() {
+		usage += "\n/news <RSS地址> - 使用自己的 RSS/Atom 新闻源\n/news default - 恢复默认新闻源"
+	}
+	return fmt.Sprintf("📰 每日提醒新闻要闻：%s\n\n用法:\n%s", status, usage)
+}
@@ -0,0 +1,290 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	tele "gopkg.in/telebot.v3"
+)
+
+// observationButtonsPerRow is the number of phenomenon buttons per keyboard row
+const observationButtonsPerRow = 3
+
+// obsmodListLimit is the number of observations listed by /obsmod
+const obsmodListLimit = 10
+
+// btnObserve reports a phenomenon from the /observe keyboard; data is "<kind>|<subscription id>"
+var btnObserve = &tele.Btn{Unique: "observe"}
+
+// observationCaptionPrefixes start the captions of photos submitted as observations
+var observationCaptionPrefixes = []string{"/observe", "#实况"}
+
+// registerObservationHandlers registers the observation commands and buttons when observations are enabled
+func (h *Handlers) registerObservationHandlers(bot *tele.Bot) {
+	if h.obsSvc == nil {
+		return
+	}
+	bot.Handle("/observe", h.HandleObserve)
+	bot.Handle("/obsmod", h.HandleObsmod)
+	bot.Handle(btnObserve, h.HandleObserveButton)
+}
+
+// HandleObserve handles the /observe command: "/observe [城市] <现象> [描述]" reports the weather
+// where the user is; without a phenomenon it shows a keyboard of phenomena
+func (h *Handlers) HandleObserve(c tele.Context) error {
+	return h.submitObservation(c, c.Args(), "")
+}
+
+// isObservationCaption reports whether a photo caption submits the photo as an observation
+func isObservationCaption(caption string) bool {
+	caption = strings.TrimSpace(caption)
+	for _, prefix := range observationCaptionPrefixes {
+		if strings.HasPrefix(caption, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleObservationPhoto records a photo captioned "/observe <现象> [描述]" or "#实况 <现象> [描述]"
+func (h *Handlers) HandleObservationPhoto(c tele.Context) error {
+	args := strings.Fields(c.Message().Caption)
+	if len(args) > 0 {
+		// The first word is the caption prefix, possibly "/observe@botname"
+		args = args[1:]
+	}
+	return h.submitObservation(c, args, c.Message().Photo.FileID)
+}
+
+// submitObservation records an observation from command or caption arguments
+func (h *Handlers) submitObservation(c tele.Context, args []string, photoFileID string) error {
+	user := userFrom(c)
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市\n请先使用 /subscribe <城市> <时间> 创建订阅，即可上报所在城市的实况")
+	}
+
+	sub := &subs[0]
+	if len(args) > 0 {
+		for i := range subs {
+			if subs[i].MatchesName(args[0]) {
+				sub, args = &subs[i], args[1:]
+				break
+			}
+		}
+	}
+
+	if len(args) == 0 {
+		if photoFileID != "" {
+			return c.Send("💡 请在图片说明中写明天气现象，如：/observe 下雪 雪很大\n可选：" + observationLabels())
+		}
+		return c.Send(fmt.Sprintf("📍 %s 现在的天气怎么样？点击上报实况：", sub.City), observationKeyboard(sub.ID))
+	}
+
+	kind := service.FindObservationKind(args[0])
+	if kind == nil {
+		return c.Send("❌ 无法识别天气现象\n可选：" + observationLabels() + "\n示例：/observe 下雪 雪很大")
+	}
+	return c.Send(h.recordObservation(user, sub.City, kind, strings.Join(args[1:], " "), photoFileID))
+}
+
+// HandleObserveButton records the phenomenon chosen on the /observe keyboard
+func (h *Handlers) HandleObserveButton(c tele.Context) error {
+	kindName, idText, _ := strings.Cut(c.Data(), "|")
+	kind := service.FindObservationKind(kindName)
+	subID, err := strconv.ParseUint(idText, 10, 64)
+	if kind == nil || err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 无效的选项"})
+	}
+	user := userFrom(c)
+	sub, err := h.subRepo.FindByID(uint(subID))
+	if err != nil || sub == nil || sub.UserID != user.ID {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 订阅不存在或已取消"})
+	}
+
+	_ = c.Respond()
+	return c.Edit(h.recordObservation(user, sub.City, kind, "", ""))
+}
+
+// recordObservation submits an observation and returns the reply to the user
+func (h *Handlers) recordObservation(user *model.User, city string, kind *service.ObservationKind, text, photoFileID string) string {
+	now := time.Now()
+	_, err := h.obsSvc.Submit(user, city, kind.Kind, text, photoFileID, now)
+	switch {
+	case errors.Is(err, service.ErrObservationBanned):
+		return "❌ 您已被禁止提交实况"
+	case errors.Is(err, service.ErrObservationCooldown):
+		return "⏳ 提交太频繁了，请 10 分钟后再试"
+	case errors.Is(err, service.ErrObservationLimit):
+		return fmt.Sprintf("⏳ 今天的实况打卡次数已用完（每天 %d 次），明天再来吧", h.obsSvc.MaxPerDay())
+	case errors.Is(err, service.ErrObservationText):
+		return "❌ 描述中不能包含链接"
+	case err != nil:
+		return "抱歉,系统出现错误,请稍后再试。"
+	}
+
+	reply := fmt.Sprintf("✅ 已记录 %s 的实况：%s %s", city, kind.Emoji, kind.Label)
+	if counts, err := h.obsSvc.Reporters(city, now); err == nil && counts[kind.Kind] > 1 {
+		reply += fmt.Sprintf("\n👥 最近已有 %d 位用户报告%s", counts[kind.Kind], kind.Phrase)
+	}
+	return reply + "\n感谢分享，人数足够时会出现在同城用户的每日提醒中"
+}
+
+// observationKeyboard builds the phenomenon buttons of /observe for a subscription
+func observationKeyboard(subID uint) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	var rows []tele.Row
+	var row tele.Row
+	for _, k := range service.ObservationKinds {
+		row = append(row, markup.Data(k.Emoji+" "+k.Label, btnObserve.Unique, fmt.Sprintf("%s|%d", k.Kind, subID)))
+		if len(row) == observationButtonsPerRow {
+			rows, row = append(rows, row), nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	markup.Inline(rows...)
+	return markup
+}
+
+// observationLabels lists the phenomena users can report
+func observationLabels() string {
+	labels := make([]string, 0, len(service.ObservationKinds))
+	for _, k := range service.ObservationKinds {
+		labels = append(labels, k.Label)
+	}
+	return strings.Join(labels, "、")
+}
+
+// HandleObsmod handles the support command /obsmod (checked by Permissions):
+// list [城市] | photo <编号> | hide <编号> | show <编号> | ban <编号> | unban <chat_id>
+func (h *Handlers) HandleObsmod(c tele.Context) error {
+	args := c.Args()
+	if len(args) == 0 || args[0] == "list" {
+		city := ""
+		if len(args) > 1 {
+			city = args[1]
+		}
+		return h.listObservations(c, city)
+	}
+	if len(args) < 2 {
+		return c.Send(obsmodUsage)
+	}
+	if args[0] == "unban" {
+		// Chat IDs of groups are negative
+		chatID, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return c.Send(obsmodUsage)
+		}
+		target, err := h.userRepo.FindByChatID(botOf(c), chatID)
+		if err != nil || target == nil {
+			return c.Send("❌ 用户不存在")
+		}
+		if err := h.obsSvc.Unban(target.ID); err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		h.auditSvc.Record(service.TelegramActor(c.Sender().ID), model.AuditObservationUnban,
+			fmt.Sprintf("user:%d", target.ID), "")
+		return c.Send(fmt.Sprintf("✅ 已允许用户 %d 提交实况（已隐藏的实况需用 show 逐条恢复）", target.ChatID))
+	}
+	id, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return c.Send(obsmodUsage)
+	}
+
+	switch args[0] {
+	case "photo":
+		obs, err := h.obsSvc.Find(uint(id))
+		if err != nil {
+			return c.Send("❌ 实况不存在")
+		}
+		if obs.PhotoFileID == "" {
+			return c.Send("❌ 该实况没有图片")
+		}
+		return c.Send(&tele.Photo{File: tele.File{FileID: obs.PhotoFileID}, Caption: formatObservation(obs)})
+	case "hide", "show":
+		found, err := h.obsSvc.SetHidden(uint(id), args[0] == "hide")
+		if err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		if !found {
+			return c.Send("❌ 实况不存在")
+		}
+		action := model.AuditObservationShow
+		if args[0] == "hide" {
+			action = model.AuditObservationHide
+		}
+		h.auditSvc.Record(service.TelegramActor(c.Sender().ID), action, fmt.Sprintf("observation:%d", id), "")
+		if args[0] == "hide" {
+			return c.Send(fmt.Sprintf("✅ 已隐藏实况 #%d", id))
+		}
+		return c.Send(fmt.Sprintf("✅ 已恢复显示实况 #%d", id))
+	case "ban":
+		author, err := h.obsSvc.BanAuthor(uint(id))
+		if err != nil {
+			return c.Send("❌ 实况不存在或操作失败")
+		}
+		h.auditSvc.Record(service.TelegramActor(c.Sender().ID), model.AuditObservationBan,
+			fmt.Sprintf("user:%d", author.ID), fmt.Sprintf("observation=%d", id))
+		return c.Send(fmt.Sprintf("✅ 已禁止用户 %d 提交实况，并隐藏其全部实况\n解除：/obsmod unban %d", author.ChatID, author.ChatID))
+	}
+	return c.Send(obsmodUsage)
+}
+
+// obsmodUsage describes the /obsmod subcommands
+const obsmodUsage = `用法:
+/obsmod [list] [城市] - 最近的实况
+/obsmod photo <编号> - 查看实况图片
+/obsmod hide <编号> / show <编号> - 隐藏/恢复实况
+/obsmod ban <编号> - 禁止该实况的作者提交并隐藏其全部实况
+/obsmod unban <chat_id> - 解除禁止`
+
+// listObservations sends the latest observations of a city ("" = all cities) to a moderator
+func (h *Handlers) listObservations(c tele.Context, city string) error {
+	observations, err := h.obsSvc.Recent(city, obsmodListLimit)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(observations) == 0 {
+		return c.Send("暂无实况")
+	}
+	var b strings.Builder
+	b.WriteString("🗂 最近的实况：\n")
+	for i := range observations {
+		b.WriteString("\n" + formatObservation(&observations[i]) + "\n")
+	}
+	b.WriteString("\n" + obsmodUsage)
+	return c.Send(b.String())
+}
+
+// formatObservation formats an observation for moderators
+func formatObservation(obs *model.Observation) string {
+	label := obs.Kind
+	if kind := service.FindObservationKind(obs.Kind); kind != nil {
+		label = kind.Emoji + " " + kind.Label
+	}
+	line := fmt.Sprintf("#%d %s %s · %s · %s（%d）",
+		obs.ID, obs.CreatedAt.Format("01-02 15:04"), obs.City, label, obs.User.DisplayName(), obs.User.ChatID)
+	if obs.PhotoFileID != "" {
+		line += " 📷"
+	}
+	if obs.Hidden {
+		line += " [已隐藏]"
+	}
+	if obs.User.ObservationsBan {
+		line += " [已禁止]"
+	}
+	if obs.Text != "" {
+		line += "\n   " + obs.Text
+	}
+	return line
+}
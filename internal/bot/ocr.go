@@ -0,0 +1,316 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// ocrProposalTTL is how long todos extracted from a photo can be confirmed
+	ocrProposalTTL = 24 * time.Hour
+	// ocrTimeout bounds downloading and reading a photo
+	ocrTimeout = 2 * time.Minute
+	// maxOCRPhotoBytes is the largest photo sent to the vision model
+	maxOCRPhotoBytes = 10 << 20
+	// ocrButtonsPerRow is the number of "add one" buttons per keyboard row
+	ocrButtonsPerRow = 5
+)
+
+// Callback button endpoints for confirming extracted todos; data is "<proposal id>|<item number>"
+var (
+	btnOCRAdd     = &tele.Btn{Unique: "ocr_add"}
+	btnOCRAll     = &tele.Btn{Unique: "ocr_all"}
+	btnOCRDismiss = &tele.Btn{Unique: "ocr_dismiss"}
+)
+
+// ocrItem is a proposed todo stored in a TodoProposal
+type ocrItem struct {
+	Content string `json:"content"`
+	Added   bool   `json:"added"`
+}
+
+// registerOCRHandlers registers the photo handler when OCR or weather observations are enabled,
+// and the confirmation buttons when OCR is enabled
+func (h *Handlers) registerOCRHandlers(bot *tele.Bot) {
+	if h.ocrSvc != nil || h.obsSvc != nil {
+		bot.Handle(tele.OnPhoto, h.HandlePhoto)
+	}
+	if h.ocrSvc == nil {
+		return
+	}
+	bot.Handle(btnOCRAdd, h.HandleOCRAdd)
+	bot.Handle(btnOCRAll, h.HandleOCRAddAll)
+	bot.Handle(btnOCRDismiss, h.HandleOCRDismiss)
+}
+
+// HandlePhoto reads todos and events from a photo (e.g. a class schedule or notice) and
+// proposes them for one-tap confirmation. The caption may name the target city. Photos captioned
+// as weather observations are recorded as such instead.
+func (h *Handlers) HandlePhoto(c tele.Context) error {
+	if h.obsSvc != nil && isObservationCaption(c.Message().Caption) {
+		return h.HandleObservationPhoto(c)
+	}
+	if h.ocrSvc == nil {
+		return nil
+	}
+	user := userFrom(c)
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市\n请先使用 /subscribe <城市> <时间> 创建订阅")
+	}
+
+	caption := strings.TrimSpace(c.Message().Caption)
+	sub := &subs[0]
+	if len(subs) > 1 {
+		sub = nil
+		for i := range subs {
+			if strings.Contains(caption, subs[i].City) {
+				sub = &subs[i]
+				break
+			}
+		}
+		if sub == nil {
+			return c.Send("💡 您订阅了多个城市，请在图片说明中注明要添加到哪个城市的待办（如：北京）")
+		}
+	}
+
+	photo := c.Message().Photo
+	if photo.FileSize > maxOCRPhotoBytes {
+		return c.Send("❌ 图片太大，请发送 10MB 以内的图片")
+	}
+	if ok, limit := h.claimAPICall(c); !ok {
+		return c.Send(h.budgetNotice(user, limit) + "\n图片识别明天恢复，也可以用 /todo add 手动添加")
+	}
+	_ = c.Notify(tele.Typing)
+
+	ctx, cancel := context.WithTimeout(context.Background(), ocrTimeout)
+	defer cancel()
+
+	reader, err := c.Bot().File(&photo.File)
+	if err != nil {
+		logger.Error("Failed to download photo", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("❌ 图片下载失败，请稍后再试")
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxOCRPhotoBytes+1))
+	_ = reader.Close()
+	if err != nil || len(data) > maxOCRPhotoBytes {
+		return c.Send("❌ 图片下载失败，请稍后再试")
+	}
+
+	// Telegram re-encodes photos as JPEG
+	extracted, err := h.ocrSvc.ExtractTodos(ctx, data, "image/jpeg", caption, time.Now().In(h.timezone))
+	if err != nil {
+		logger.Error("Failed to extract todos from photo", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("❌ 图片识别失败，请稍后再试")
+	}
+	if len(extracted) == 0 {
+		return c.Send("🔍 未在图片中识别到待办事项\n\n💡 可以发送课程表、通知、海报等包含时间和事项的图片")
+	}
+
+	items := make([]ocrItem, len(extracted))
+	for i, todo := range extracted {
+		items[i] = ocrItem{Content: todo.Text()}
+	}
+	encoded, err := json.Marshal(items)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	proposal := &model.TodoProposal{
+		UserID:         user.ID,
+		SubscriptionID: sub.ID,
+		Items:          string(encoded),
+		ExpiresAt:      time.Now().Add(ocrProposalTTL),
+	}
+	if err := h.proposalRepo.Create(proposal); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	return c.Send(ocrProposalText(sub.City, items), ocrProposalMarkup(proposal.ID, items))
+}
+
+// HandleOCRAdd adds one proposed todo to the list
+func (h *Handlers) HandleOCRAdd(c tele.Context) error {
+	return h.confirmOCRItems(c, false)
+}
+
+// HandleOCRAddAll adds all remaining proposed todos to the list
+func (h *Handlers) HandleOCRAddAll(c tele.Context) error {
+	return h.confirmOCRItems(c, true)
+}
+
+// HandleOCRDismiss discards a proposal
+func (h *Handlers) HandleOCRDismiss(c tele.Context) error {
+	proposal, _, ok := h.loadOCRProposal(c)
+	if !ok {
+		return nil
+	}
+	if err := h.proposalRepo.Delete(proposal.ID); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+	_ = c.Respond(&tele.CallbackResponse{Text: "已忽略"})
+	return c.Edit("🔍 已忽略图片中识别到的待办")
+}
+
+// confirmOCRItems adds the todo numbered in the callback data, or all remaining todos
+func (h *Handlers) confirmOCRItems(c tele.Context, all bool) error {
+	proposal, items, ok := h.loadOCRProposal(c)
+	if !ok {
+		return nil
+	}
+
+	sub, err := h.subRepo.FindByID(proposal.SubscriptionID)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+	if sub == nil || sub.UserID != proposal.UserID {
+		_ = h.proposalRepo.Delete(proposal.ID)
+		_ = c.Respond(&tele.CallbackResponse{Text: "该城市的订阅已取消"})
+		return c.Edit("❌ 该城市的订阅已取消，无法添加待办")
+	}
+
+	var selected []int
+	if all {
+		for i := range items {
+			if !items[i].Added {
+				selected = append(selected, i)
+			}
+		}
+	} else {
+		args := c.Args()
+		idx := 0
+		if len(args) > 1 {
+			idx, _ = strconv.Atoi(args[1])
+		}
+		if idx < 1 || idx > len(items) || items[idx-1].Added {
+			return c.Respond(&tele.CallbackResponse{Text: "该待办已添加"})
+		}
+		selected = []int{idx - 1}
+	}
+
+	added := 0
+	for _, i := range selected {
+		if _, err := h.todoSvc.AddTodo(sub.TodoListID(), items[i].Content); err != nil {
+			break
+		}
+		items[i].Added = true
+		added++
+	}
+
+	remaining := 0
+	for _, item := range items {
+		if !item.Added {
+			remaining++
+		}
+	}
+	if remaining == 0 {
+		err = h.proposalRepo.Delete(proposal.ID)
+	} else if encoded, encErr := json.Marshal(items); encErr == nil {
+		err = h.proposalRepo.UpdateItems(proposal.ID, string(encoded))
+	} else {
+		err = encErr
+	}
+	if err != nil {
+		logger.Error("Failed to update todo proposal", zap.Uint("proposal_id", proposal.ID), zap.Error(err))
+	}
+
+	logger.Info("Todos added from photo",
+		zap.Uint("proposal_id", proposal.ID),
+		zap.Uint("subscription_id", sub.ID),
+		zap.Int("added", added))
+
+	if added < len(selected) {
+		_ = c.Respond(&tele.CallbackResponse{Text: "部分待办添加失败，请稍后重试"})
+	} else {
+		_ = c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("✅ 已添加 %d 项待办", added)})
+	}
+	if remaining == 0 {
+		return c.Edit(ocrProposalText(sub.City, items) + fmt.Sprintf("\n✅ 已全部添加，使用 /todo %s 查看", sub.City))
+	}
+	return c.Edit(ocrProposalText(sub.City, items), ocrProposalMarkup(proposal.ID, items))
+}
+
+// loadOCRProposal loads the sender's proposal referenced by the callback data, answering the
+// callback itself when the proposal is gone
+func (h *Handlers) loadOCRProposal(c tele.Context) (*model.TodoProposal, []ocrItem, bool) {
+	args := c.Args()
+	var id uint64
+	if len(args) > 0 {
+		id, _ = strconv.ParseUint(args[0], 10, 64)
+	}
+
+	proposal, err := h.proposalRepo.FindActive(uint(id), userFrom(c).ID, time.Now())
+	if err != nil {
+		_ = c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+		return nil, nil, false
+	}
+	if proposal == nil {
+		_ = c.Respond(&tele.CallbackResponse{Text: "识别结果已过期，请重新发送图片"})
+		_ = c.Edit("⌛ 识别结果已过期，请重新发送图片")
+		return nil, nil, false
+	}
+
+	var items []ocrItem
+	if err := json.Unmarshal([]byte(proposal.Items), &items); err != nil {
+		logger.Error("Invalid todo proposal items", zap.Uint("proposal_id", proposal.ID), zap.Error(err))
+		_ = c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+		return nil, nil, false
+	}
+	return proposal, items, true
+}
+
+// ocrProposalText lists proposed todos, marking those already added
+func ocrProposalText(city string, items []ocrItem) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🔍 从图片中识别到 %d 项待办（%s）：\n\n", len(items), city))
+	for i, item := range items {
+		status := "⬜"
+		if item.Added {
+			status = "✅"
+		}
+		b.WriteString(fmt.Sprintf("%d. %s %s\n", i+1, status, item.Content))
+	}
+	return b.String()
+}
+
+// ocrProposalMarkup builds the confirmation keyboard: one button per todo not yet added,
+// then "add all" and "dismiss"
+func ocrProposalMarkup(proposalID uint, items []ocrItem) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	id := strconv.FormatUint(uint64(proposalID), 10)
+
+	var rows []tele.Row
+	var row tele.Row
+	for i, item := range items {
+		if item.Added {
+			continue
+		}
+		n := strconv.Itoa(i + 1)
+		row = append(row, markup.Data("➕ "+n, btnOCRAdd.Unique, id, n))
+		if len(row) == ocrButtonsPerRow {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, markup.Row(
+		markup.Data("✅ 全部添加", btnOCRAll.Unique, id),
+		markup.Data("❌ 忽略", btnOCRDismiss.Unique, id),
+	))
+	markup.Inline(rows...)
+	return markup
+}
@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// outdoorKinds maps the activity words accepted by /outdoor to outdoor kinds
+var outdoorKinds = map[string]string{
+	"登山":       model.OutdoorMountain,
+	"爬山":       model.OutdoorMountain,
+	"徒步":       model.OutdoorMountain,
+	"mountain": model.OutdoorMountain,
+	"出海":       model.OutdoorSea,
+	"航海":       model.OutdoorSea,
+	"赶海":       model.OutdoorSea,
+	"sea":      model.OutdoorSea,
+}
+
+// HandleMountain handles /mountain <山名或景区>, the hiking forecast of a mountain
+func (h *Handlers) HandleMountain(c tele.Context) error {
+	place := strings.Join(c.Args(), " ")
+	if place == "" {
+		return c.Send("❌ 请指定山名或景区\n用法: /mountain <山名>\n示例: /mountain 泰山")
+	}
+	_ = c.Notify(tele.Typing)
+
+	key := reportKey("mountain", place)
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
+	}
+	report, err := h.weatherSvc.GetMountainReport(place)
+	if err != nil {
+		logger.Error("Failed to get mountain report", zap.String("place", place), zap.Error(err))
+		return c.Send(fmt.Sprintf("❌ 无法获取 %s 的登山天气，请检查山名或景区名称是否正确。", place))
+	}
+	h.rememberReport(c, key, report)
+	return c.Send(report)
+}
+
+// HandleSea handles /sea <潮汐站点或沿海地名>, the tide table and sailing forecast of a coast
+func (h *Handlers) HandleSea(c tele.Context) error {
+	place := strings.Join(c.Args(), " ")
+	if place == "" {
+		return c.Send("❌ 请指定沿海地点或潮汐站\n用法: /sea <地点>\n示例: /sea 青岛")
+	}
+	_ = c.Notify(tele.Typing)
+
+	key := reportKey("sea", place)
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
+	}
+	report, err := h.weatherSvc.GetSeaReport(place, time.Now().In(h.timezone))
+	if err != nil {
+		logger.Error("Failed to get sea report", zap.String("place", place), zap.Error(err))
+		return c.Send(fmt.Sprintf("❌ 未找到 %s 附近的潮汐站，请换一个沿海地名试试。", place))
+	}
+	h.rememberReport(c, key, report)
+	return c.Send(report)
+}
+
+// HandleOutdoor handles /outdoor [城市] <登山|出海> <地点> | off, adding a hiking or sailing
+// forecast section to a subscription's daily reminder
+func (h *Handlers) HandleOutdoor(c tele.Context) error {
+	user := userFrom(c)
+	args := c.Args()
+
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市\n请先使用 /subscribe <城市> <时间> 创建订阅")
+	}
+	if len(args) == 0 {
+		return c.Send(formatOutdoorStatus(subs))
+	}
+
+	isOption := func(arg string) bool {
+		_, ok := outdoorKinds[strings.ToLower(arg)]
+		return ok || strings.ToLower(arg) == "off"
+	}
+	targetSub, args, err := h.targetSubscription(c, cityChoiceOutdoor, subs, args, isOption, "/outdoor "+subs[0].City+" 登山 泰山")
+	if targetSub == nil {
+		return err
+	}
+	if len(args) == 0 {
+		return c.Send(formatOutdoorStatus([]model.Subscription{*targetSub}))
+	}
+
+	if strings.ToLower(args[0]) == "off" {
+		if err := h.subRepo.SetOutdoor(targetSub.ID, "", ""); err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		logger.Info("Outdoor section disabled", zap.Uint("subscription_id", targetSub.ID))
+		return c.Send(fmt.Sprintf("✅ 已关闭 %s 每日提醒中的户外板块", targetSub.City))
+	}
+
+	kind, ok := outdoorKinds[strings.ToLower(args[0])]
+	if !ok || len(args) < 2 {
+		return c.Send("❌ 用法: /outdoor [城市] <登山|出海> <地点>\n示例: /outdoor 登山 泰山、/outdoor 出海 青岛")
+	}
+	place := strings.Join(args[1:], " ")
+
+	// Check the place resolves now rather than failing silently in every reminder
+	_ = c.Notify(tele.Typing)
+	if kind == model.OutdoorMountain {
+		_, err = h.weatherSvc.GetMountainReport(place)
+	} else {
+		_, err = h.weatherSvc.GetSeaReport(place, time.Now().In(h.timezone))
+	}
+	if err != nil {
+		logger.Debug("Outdoor place not found", zap.String("kind", kind), zap.String("place", place), zap.Error(err))
+		return c.Send(fmt.Sprintf("❌ 无法获取 %s 的%s预报，请检查地点名称", place, outdoorKindName(kind)))
+	}
+
+	if err := h.subRepo.SetOutdoor(targetSub.ID, kind, place); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Outdoor section enabled",
+		zap.Uint("subscription_id", targetSub.ID),
+		zap.String("kind", kind),
+		zap.String("place", place))
+	return c.Send(fmt.Sprintf("✅ %s 的每日提醒将附上 %s 的%s预报", targetSub.City, place, outdoorKindName(kind)))
+}
+
+// outdoorKindName names an outdoor activity
+func outdoorKindName(kind string) string {
+	if kind == model.OutdoorSea {
+		return "出海"
+	}
+	return "登山"
+}
+
+// formatOutdoorStatus lists the outdoor section of each subscription
+func formatOutdoorStatus(subs []model.Subscription) string {
+	var b strings.Builder
+	b.WriteString("🏕 每日提醒户外板块\n\n")
+	for _, sub := range subs {
+		status := "未开启"
+		if sub.OutdoorKind != "" {
+			status = fmt.Sprintf("%s · %s", outdoorKindName(sub.OutdoorKind), sub.OutdoorPlace)
+		}
+		b.WriteString(fmt.Sprintf("• %s：%s\n", sub.City, status))
+	}
+	b.WriteString("\n用法:\n/outdoor [城市] 登山 <山名> - 附上登山天气与风险提示\n/outdoor [城市] 出海 <沿海地点> - 附上潮汐与海面风力\n/outdoor [城市] off - 关闭户外板块")
+	return b.String()
+}
@@ -0,0 +1,105 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// HandlePin handles the /pin [city] [on|off] command, choosing whether daily reminders are
+// pinned in the chat (replacing the previous day's pin)
+func (h *Handlers) HandlePin(c tele.Context) error {
+	user := userFrom(c)
+	args := c.Args()
+
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市\n请先使用 /subscribe <城市> <时间> 创建订阅")
+	}
+	if len(args) == 0 {
+		return c.Send(formatPinStatus(subs))
+	}
+
+	isOption := func(arg string) bool {
+		arg = strings.ToLower(arg)
+		return arg == "on" || arg == "off"
+	}
+	targetSub, args, err := h.targetSubscription(c, cityChoicePin, subs, args, isOption, "/pin "+subs[0].City+" on")
+	if targetSub == nil {
+		return err
+	}
+	if len(args) == 0 {
+		return c.Send(formatPinStatus([]model.Subscription{*targetSub}))
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "on":
+		return h.enablePin(c, targetSub)
+	case "off":
+		return h.disablePin(c, targetSub)
+	default:
+		return c.Send("❌ 无效的选项\n用法: /pin [城市] [on|off]")
+	}
+}
+
+// enablePin turns on reminder pinning after checking that the bot may pin in the chat
+func (h *Handlers) enablePin(c tele.Context, sub *model.Subscription) error {
+	user := userFrom(c)
+	sub.User = *user
+	allowed, err := h.notifySvc.CanPinReminder(*sub)
+	if err != nil {
+		logger.Warn("Failed to check pin permission", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+		return c.Send("❌ 无法获取机器人在当前聊天中的权限，请稍后再试")
+	}
+	if !allowed {
+		return c.Send("❌ 机器人没有置顶消息的权限\n请将机器人设为管理员并授予「置顶消息」权限后重试")
+	}
+	if err := h.subRepo.SetPinReminder(sub.ID, true); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	logger.Info("Reminder pinning enabled", zap.Uint("subscription_id", sub.ID))
+	return c.Send(fmt.Sprintf("📌 已开启 %s 每日提醒置顶\n从下一次提醒起自动置顶，并取消置顶前一天的提醒", sub.City))
+}
+
+// disablePin turns off reminder pinning and unpins the current reminder
+func (h *Handlers) disablePin(c tele.Context, sub *model.Subscription) error {
+	if err := h.subRepo.SetPinReminder(sub.ID, false); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if sub.PinnedMessageID != 0 {
+		sub.User = *userFrom(c)
+		if err := h.notifySvc.UnpinReminder(*sub); err != nil {
+			logger.Debug("Failed to unpin reminder", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+		}
+		if err := h.subRepo.SetPinnedMessage(sub.ID, 0); err != nil {
+			logger.Warn("Failed to clear pinned reminder", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+		}
+	}
+
+	logger.Info("Reminder pinning disabled", zap.Uint("subscription_id", sub.ID))
+	return c.Send(fmt.Sprintf("✅ 已关闭 %s 每日提醒置顶", sub.City))
+}
+
+// formatPinStatus lists whether each subscription's reminder is pinned
+func formatPinStatus(subs []model.Subscription) string {
+	var b strings.Builder
+	b.WriteString("📌 每日提醒置顶\n\n")
+	for _, sub := range subs {
+		status := "未开启"
+		if sub.PinReminder {
+			status = "已开启"
+		}
+		b.WriteString(fmt.Sprintf("• %s：%s\n", sub.City, status))
+	}
+	b.WriteString("\n用法:\n/pin [城市] on - 自动置顶每日提醒，并取消置顶前一天的提醒\n/pin [城市] off - 关闭置顶\n💡 在群组中需授予机器人「置顶消息」管理员权限")
+	return b.String()
+}
@@ -0,0 +1,146 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// btnBuyPremium sends the invoice of a premium plan; data is the plan's days
+var btnBuyPremium = &tele.Btn{Unique: "buy_premium"}
+
+// registerPaymentHandlers registers /premium and the payment updates when payments are enabled
+func (h *Handlers) registerPaymentHandlers(bot *tele.Bot) {
+	if h.paymentSvc == nil {
+		return
+	}
+	bot.Handle("/premium", h.HandlePremium)
+	bot.Handle(btnBuyPremium, h.HandleBuyPremium)
+	bot.Handle(tele.OnCheckout, h.HandleCheckout)
+	bot.Handle(tele.OnPayment, h.HandlePayment)
+}
+
+// formatPrice formats an amount in the smallest units of a currency
+func formatPrice(amount int, currency string) string {
+	if currency == service.CurrencyStars {
+		return fmt.Sprintf("%d ⭐", amount)
+	}
+	return fmt.Sprintf("%d.%02d %s", amount/100, amount%100, currency)
+}
+
+// HandlePremium handles /premium: shows the premium tier's limits and the plans on sale
+func (h *Handlers) HandlePremium(c tele.Context) error {
+	user := userFrom(c)
+	premium := h.tierSvc.LimitsOf(model.TierPremium)
+
+	var msg strings.Builder
+	msg.WriteString("⭐ 升级高级版\n\n")
+	msg.WriteString(fmt.Sprintf("📍 最多订阅 %d 个城市\n", premium.Subscriptions))
+	msg.WriteString(fmt.Sprintf("🔔 最多 %d 个生活指数提醒\n", premium.IndexWatches))
+	msg.WriteString(fmt.Sprintf("🌐 每天 %d 次实时查询\n", premium.APICalls))
+	if h.aiSvc.RegenerateQuota() > 0 {
+		msg.WriteString(fmt.Sprintf("🔁 AI 提醒每天换一条 %d 次\n", premium.Regenerations))
+	}
+	if user.EffectiveTier(time.Now()) == model.TierPremium {
+		if user.TierUntil == nil {
+			msg.WriteString("\n✅ 您已是高级版（长期有效）")
+			return c.Send(msg.String())
+		}
+		msg.WriteString(fmt.Sprintf("\n✅ 您的高级版有效期至 %s，续费将顺延", user.TierUntil.In(h.timezone).Format("2006-01-02 15:04")))
+	}
+
+	markup := &tele.ReplyMarkup{}
+	var rows []tele.Row
+	for _, plan := range h.paymentSvc.Plans() {
+		label := fmt.Sprintf("%d 天 · %s", plan.Days, formatPrice(plan.Price, h.paymentSvc.Currency()))
+		rows = append(rows, markup.Row(markup.Data(label, btnBuyPremium.Unique, strconv.Itoa(plan.Days))))
+	}
+	markup.Inline(rows...)
+	msg.WriteString("\n\n选择套餐：")
+	return c.Send(msg.String(), markup)
+}
+
+// HandleBuyPremium sends the invoice of the chosen premium plan
+func (h *Handlers) HandleBuyPremium(c tele.Context) error {
+	days, _ := strconv.Atoi(c.Data())
+	plan, ok := h.paymentSvc.Plan(days)
+	if !ok {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 该套餐已下架，请重新发送 /premium", ShowAlert: true})
+	}
+	_ = c.Respond()
+
+	title := fmt.Sprintf("高级版 %d 天", plan.Days)
+	premium := h.tierSvc.LimitsOf(model.TierPremium)
+	invoice := &tele.Invoice{
+		Title:       title,
+		Description: fmt.Sprintf("每日提醒机器人高级版 %d 天：最多订阅 %d 个城市、%d 个生活指数提醒", plan.Days, premium.Subscriptions, premium.IndexWatches),
+		Payload:     h.paymentSvc.Payload(userFrom(c), plan),
+		Currency:    h.paymentSvc.Currency(),
+		Prices:      []tele.Price{{Label: title, Amount: plan.Price}},
+		Token:       h.paymentSvc.ProviderToken(),
+	}
+	if err := c.Send(invoice); err != nil {
+		logger.Error("Failed to send invoice", zap.Uint("user_id", userFrom(c).ID), zap.Error(err))
+		return c.Send("❌ 发起支付失败，请稍后再试")
+	}
+	return nil
+}
+
+// HandleCheckout answers the pre-checkout query of a premium invoice, refusing stale invoices
+// and invoices of other users
+func (h *Handlers) HandleCheckout(c tele.Context) error {
+	user := userFrom(c)
+	query := c.PreCheckoutQuery()
+	if _, err := h.paymentSvc.Validate(user, query.Payload, query.Currency, query.Total); err != nil {
+		logger.Warn("Pre-checkout refused",
+			zap.Uint("user_id", user.ID),
+			zap.String("payload", query.Payload),
+			zap.String("currency", query.Currency),
+			zap.Int("total", query.Total))
+		return c.Accept("该账单已失效或不属于您，请重新发送 /premium 购买")
+	}
+	return c.Accept()
+}
+
+// HandlePayment records a successful payment and upgrades the user to premium
+func (h *Handlers) HandlePayment(c tele.Context) error {
+	user := userFrom(c)
+	payment := c.Message().Payment
+	plan, err := h.paymentSvc.Validate(user, payment.Payload, payment.Currency, payment.Total)
+	if err != nil {
+		// Pre-checkout refuses such payments, so this only happens when the plans changed in between
+		logger.Error("Payment does not match a plan",
+			zap.Uint("user_id", user.ID),
+			zap.String("payload", payment.Payload),
+			zap.String("charge_id", payment.TelegramChargeID))
+		return c.Send("⚠️ 支付已收到，但套餐信息有变，请发送 /feedback 联系管理员处理")
+	}
+
+	recorded, err := h.paymentSvc.Complete(user, &model.Payment{
+		Days:             plan.Days,
+		Currency:         payment.Currency,
+		Amount:           payment.Total,
+		Payload:          payment.Payload,
+		TelegramChargeID: payment.TelegramChargeID,
+		ProviderChargeID: payment.ProviderChargeID,
+	}, time.Now())
+	if err != nil {
+		return c.Send("⚠️ 支付已收到，但开通时出现错误，请发送 /feedback 联系管理员处理")
+	}
+	if !recorded {
+		return nil
+	}
+	h.auditSvc.Record(service.TelegramActor(c.Sender().ID), model.AuditTierPurchase, fmt.Sprintf("user:%d", user.ID),
+		fmt.Sprintf("days=%d amount=%d %s charge=%s", plan.Days, payment.Total, payment.Currency, payment.TelegramChargeID))
+	if user.TierUntil == nil {
+		return c.Send("✅ 支付成功，您已是高级版（长期有效）。发送 /tier 查看用量")
+	}
+	return c.Send(fmt.Sprintf("✅ 支付成功，高级版有效期至 %s\n发送 /tier 查看用量", user.TierUntil.In(h.timezone).Format("2006-01-02 15:04")))
+}
@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// HandleRates handles the /rates [on|off|default|<货币对>...] command, choosing the exchange rates
+// and metal prices of the user's daily reminders
+func (h *Handlers) HandleRates(c tele.Context) error {
+	if h.ratesSvc == nil {
+		return c.Send("❌ 汇率金价功能未开启，请联系管理员")
+	}
+	user := userFrom(c)
+
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(h.ratesStatus(user.RatePairs, user.RatesOff))
+	}
+
+	pairs, off := user.RatePairs, user.RatesOff
+	var reply string
+	switch strings.ToLower(args[0]) {
+	case "on":
+		if pairs == "" && len(h.ratesSvc.DefaultPairs()) == 0 {
+			return c.Send("❌ 尚未设置货币对\n用法: /rates USD/CNY XAU/CNY")
+		}
+		off = false
+		reply = "✅ 已开启汇率金价，每日提醒将附带最新行情"
+	case "off":
+		off = true
+		reply = "🔕 已关闭汇率金价\n发送 /rates on 可重新开启"
+	case "default":
+		if len(h.ratesSvc.DefaultPairs()) == 0 {
+			pairs, off = "", true
+			reply = "✅ 已清除自定义货币对（管理员未设置默认货币对，汇率金价已关闭）"
+		} else {
+			pairs, off = "", false
+			reply = "✅ 已恢复默认货币对：" + service.FormatRatePairs(h.ratesSvc.DefaultPairs())
+		}
+	default:
+		parsed, err := service.ParseRatePairs(strings.Join(args, " "))
+		if err != nil {
+			return c.Send("❌ 货币对格式错误，请使用 基础货币/计价货币 的形式\n示例: /rates USD/CNY EUR/CNY XAU/CNY")
+		}
+		if len(parsed) > h.ratesSvc.MaxPairs() {
+			return c.Send(fmt.Sprintf("❌ 最多只能设置 %d 个货币对", h.ratesSvc.MaxPairs()))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+		quotes, err := h.ratesSvc.Quotes(ctx, parsed)
+		if err != nil {
+			logger.Info("Rejected rate pairs", zap.Uint("user_id", user.ID), zap.Error(err))
+			return c.Send("❌ 无法获取这些货币对的行情，请确认货币代码正确（如 USD、EUR、JPY，黄金为 XAU）")
+		}
+
+		pairs, off = service.FormatRatePairs(parsed), false
+		var preview strings.Builder
+		for _, q := range quotes {
+			preview.WriteString("\n" + service.FormatQuote(q))
+		}
+		reply = "✅ 货币对已设置，每日提醒将附带最新行情：" + preview.String()
+	}
+
+	if err := h.userRepo.SetRates(user.ID, pairs, off); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Rates preference updated",
+		zap.Uint("user_id", user.ID),
+		zap.String("pairs", pairs),
+		zap.Bool("rates_off", off))
+	return c.Send(reply)
+}
+
+// ratesStatus describes the user's rates setting and the command usage
+func (h *Handlers) ratesStatus(pairs string, off bool) string {
+	var status string
+	switch {
+	case off:
+		status = "已关闭"
+	case pairs != "":
+		status = "自定义 " + pairs
+	case len(h.ratesSvc.DefaultPairs()) > 0:
+		status = "默认 " + service.FormatRatePairs(h.ratesSvc.DefaultPairs())
+	default:
+		status = "未设置货币对"
+	}
+
+	return fmt.Sprintf(`💱 每日提醒汇率金价：%s
+
+用法:
+/rates on - 开启
+/rates off - 关闭
+/rates <货币对>... - 设置自己的货币对（最多 %d 个）
+  示例: /rates USD/CNY JPY/CNY XAU/CNY
+/rates default - 恢复默认货币对
+
+💡 黄金 XAU、白银 XAG 以美元计价时为每盎司价格，其他货币为每克价格`, status, h.ratesSvc.MaxPairs())
+}
@@ -0,0 +1,184 @@
+package bot
+
+import (
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// reactionDone completes the todo shown by the reacted message
+	reactionDone = "👍"
+	// reminderRefreshCooldown is the minimum time between two refreshes of a chat's reminder
+	reminderRefreshCooldown = time.Minute
+)
+
+// refreshReactions resend the reacted daily reminder with fresh data. 🔁 is not among
+// Telegram's default reactions, so ⚡ is accepted as well.
+var refreshReactions = map[string]bool{"🔁": true, "⚡": true}
+
+// refreshCooldown limits how often each chat can refresh its reminder
+type refreshCooldown struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[int64]time.Time
+}
+
+// newRefreshCooldown creates a new refreshCooldown
+func newRefreshCooldown(interval time.Duration) *refreshCooldown {
+	return &refreshCooldown{interval: interval, last: make(map[int64]time.Time)}
+}
+
+// ready reports whether the chat may refresh again
+func (r *refreshCooldown) ready(chatID int64, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return now.Sub(r.last[chatID]) >= r.interval
+}
+
+// mark records a refresh of the chat's reminder
+func (r *refreshCooldown) mark(chatID int64, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, t := range r.last {
+		if now.Sub(t) >= r.interval {
+			delete(r.last, id)
+		}
+	}
+	r.last[chatID] = now
+}
+
+// registerReactionHandlers routes message reaction updates, which telebot does not dispatch to
+// handlers, to handleReaction. Telegram only sends them when requested in allowed_updates.
+func (h *Handlers) registerReactionHandlers(name string, bot *tele.Bot) {
+	bot.Poller = tele.NewMiddlewarePoller(bot.Poller, func(u *tele.Update) bool {
+		if u.MessageReaction == nil {
+			return true
+		}
+		go h.handleReaction(name, bot, u.MessageReaction)
+		return false
+	})
+}
+
+// handleReaction acts on reactions newly added to a message: 👍 on a todo message completes
+// the todo, 🔁 on a daily reminder resends it with fresh data
+func (h *Handlers) handleReaction(name string, b *tele.Bot, r *tele.MessageReaction) {
+	defer func() {
+		if p := recover(); p != nil {
+			logger.Error("Reaction handler panicked",
+				zap.Any("panic", p),
+				zap.ByteString("stack", debug.Stack()))
+		}
+	}()
+
+	// Anonymous reactions (on behalf of a chat) cannot be attributed to a user
+	if r.User == nil || r.Chat == nil {
+		return
+	}
+	added := addedReactions(r)
+	if len(added) == 0 {
+		return
+	}
+
+	user, err := h.userRepo.FindByChatID(name, r.User.ID)
+	if err != nil || user == nil {
+		return
+	}
+
+	for _, emoji := range added {
+		switch {
+		case emoji == reactionDone:
+			h.completeTodoByReaction(b, user, r)
+		case refreshReactions[emoji]:
+			h.refreshReminderByReaction(b, user, r)
+		}
+	}
+}
+
+// completeTodoByReaction completes the todo shown by the reacted message, if any
+func (h *Handlers) completeTodoByReaction(b *tele.Bot, user *model.User, r *tele.MessageReaction) {
+	todoID, err := h.todoMsgRepo.FindTodoID(r.Chat.ID, r.MessageID)
+	if err != nil {
+		logger.Warn("Failed to look up todo message", zap.Int("message_id", r.MessageID), zap.Error(err))
+		return
+	}
+	if todoID == 0 {
+		return
+	}
+	todo, err := h.todoRepo.FindByID(todoID)
+	if err != nil || todo == nil || todo.Completed {
+		return
+	}
+	if err := h.todoSvc.CompleteTodo(todo.ID, user.ID); err != nil {
+		logger.Warn("Failed to complete todo by reaction", zap.Uint("todo_id", todo.ID), zap.Error(err))
+		return
+	}
+
+	logger.Info("Todo completed by reaction", zap.Uint("todo_id", todo.ID), zap.Uint("user_id", user.ID))
+	if sub, err := h.subRepo.FindByID(todo.SubscriptionID); err == nil && sub != nil {
+		h.notifyTodoCompleted(sub, user, todo.Content)
+	}
+	reply := &tele.SendOptions{ReplyTo: &tele.Message{ID: r.MessageID, Chat: r.Chat}}
+	if _, err := b.Send(r.Chat, "✅ 待办已完成："+todo.Content, reply); err != nil {
+		logger.Warn("Failed to confirm todo completed by reaction", zap.Error(err))
+	}
+}
+
+// refreshReminderByReaction resends the reacted daily reminder with fresh data
+func (h *Handlers) refreshReminderByReaction(b *tele.Bot, user *model.User, r *tele.MessageReaction) {
+	if h.scheduler == nil {
+		return
+	}
+	reply := &tele.SendOptions{ReplyTo: &tele.Message{ID: r.MessageID, Chat: r.Chat}}
+	now := time.Now()
+	if !h.refreshes.ready(r.Chat.ID, now) {
+		_, _ = b.Send(r.Chat, "⏳ 提醒刚刚刷新过，请稍后再试", reply)
+		return
+	}
+
+	_ = b.Notify(r.Chat, tele.Typing)
+	found, err := h.scheduler.ResendReminder(r.Chat.ID, r.MessageID, user.ID)
+	if !found {
+		if err != nil {
+			logger.Warn("Failed to look up reminder message", zap.Int("message_id", r.MessageID), zap.Error(err))
+		}
+		return
+	}
+	h.refreshes.mark(r.Chat.ID, now)
+	if err != nil {
+		logger.Error("Failed to refresh reminder", zap.Uint("user_id", user.ID), zap.Error(err))
+		_, _ = b.Send(r.Chat, "❌ 刷新提醒失败，请稍后再试", reply)
+	}
+}
+
+// addedReactions returns the emoji reactions in the new reaction list that were not set before
+func addedReactions(r *tele.MessageReaction) []string {
+	old := make(map[string]bool, len(r.OldReaction))
+	for _, reaction := range r.OldReaction {
+		old[reaction.Emoji] = true
+	}
+	var added []string
+	for _, reaction := range r.NewReaction {
+		if reaction.Type == "emoji" && !old[reaction.Emoji] {
+			added = append(added, reaction.Emoji)
+		}
+	}
+	return added
+}
+
+// linkTodoMessages lets the user complete a just added todo by reacting to the /todo add
+// command or the bot's confirmation
+func (h *Handlers) linkTodoMessages(c tele.Context, todoID uint, reply *tele.Message) {
+	ids := []int{c.Message().ID}
+	if reply != nil {
+		ids = append(ids, reply.ID)
+	}
+	if err := h.todoMsgRepo.Create(todoID, c.Chat().ID, ids...); err != nil {
+		logger.Warn("Failed to link todo messages", zap.Uint("todo_id", todoID), zap.Error(err))
+	}
+}
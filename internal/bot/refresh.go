@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// maxRefreshCityBytes keeps the city within Telegram's 64-byte callback data limit
+const maxRefreshCityBytes = 40
+
+// Callback button endpoints that re-fetch a report in place; data is the city
+var (
+	btnRefreshWeather = &tele.Btn{Unique: "refresh_weather"}
+	btnRefreshAir     = &tele.Btn{Unique: "refresh_air"}
+)
+
+// HandleRefreshWeather re-fetches the weather report of a /weather reply and edits it in place;
+// data is the /weather query, including any type hint
+func (h *Handlers) HandleRefreshWeather(c tele.Context) error {
+	city := c.Data()
+	place, hint := parseWeatherQuery(city)
+	if ok, limit := h.claimAPICall(c); !ok {
+		return c.Respond(budgetAlert(limit))
+	}
+	report, err := h.weatherSvc.GetFullWeatherReport(place, hint, h.airSvc, h.warningSvc)
+	if err != nil {
+		logger.Error("Failed to refresh weather report", zap.String("city", city), zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 刷新失败，请稍后再试"})
+	}
+	h.rememberReport(c, reportKey("weather", place, hint), report)
+	return h.editRefreshedReport(c, btnRefreshWeather, city, report)
+}
+
+// HandleRefreshAir re-fetches the air quality report of an /air reply and edits it in place
+func (h *Handlers) HandleRefreshAir(c tele.Context) error {
+	city := c.Data()
+	if ok, limit := h.claimAPICall(c); !ok {
+		return c.Respond(budgetAlert(limit))
+	}
+	report, err := h.airSvc.GetAirQualityReport(city)
+	if err != nil {
+		logger.Error("Failed to refresh air quality report", zap.String("city", city), zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 刷新失败，请稍后再试"})
+	}
+	h.rememberReport(c, reportKey("air", city), report)
+	return h.editRefreshedReport(c, btnRefreshAir, city, report)
+}
+
+// editRefreshedReport replaces the message with the refreshed report
+func (h *Handlers) editRefreshedReport(c tele.Context, btn *tele.Btn, city, report string) error {
+	err := c.Edit(h.withUpdatedAt(report), refreshMarkup(btn, city))
+	if errors.Is(err, tele.ErrSameMessageContent) || errors.Is(err, tele.ErrMessageNotModified) {
+		return c.Respond(&tele.CallbackResponse{Text: "已是最新数据"})
+	}
+	if err != nil {
+		return err
+	}
+	logger.Info("Report refreshed", zap.String("report", btn.Unique), zap.String("city", city))
+	return c.Respond(&tele.CallbackResponse{Text: "✅ 已刷新"})
+}
+
+// withUpdatedAt appends the "updated at" footer to a report
+func (h *Handlers) withUpdatedAt(report string) string {
+	return fmt.Sprintf("%s\n\n🕐 更新于 %s", strings.TrimRight(report, "\n"), time.Now().In(h.timezone).Format("15:04"))
+}
+
+// refreshMarkup builds the "🔄 刷新" button of a report, or nil when the city does not fit
+// in the callback data
+func refreshMarkup(btn *tele.Btn, city string) *tele.ReplyMarkup {
+	if len(city) > maxRefreshCityBytes {
+		return nil
+	}
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(markup.Data("🔄 刷新", btn.Unique, city)))
+	return markup
+}
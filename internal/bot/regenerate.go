@@ -0,0 +1,72 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// btnRegenerateReminder is the "换一条" button under AI reminders (see service.ReminderActionRegenerate)
+var btnRegenerateReminder = &tele.Btn{Unique: service.ReminderActionRegenerate}
+
+// HandleRegenerateReminder replaces an AI reminder with a newly written version, counting against
+// the user's daily regeneration quota
+func (h *Handlers) HandleRegenerateReminder(c tele.Context) error {
+	if h.scheduler == nil || h.aiSvc.RegenerateQuota() == 0 {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 换一条功能未开启"})
+	}
+	user := userFrom(c)
+	quota := h.tierSvc.Limits(user, time.Now()).Regenerations
+	msg := c.Message()
+	date := time.Now().In(h.timezone).Format("2006-01-02")
+
+	ok, err := h.userRepo.ClaimRegeneration(user.ID, date, quota)
+	if err != nil {
+		logger.Error("Failed to claim regeneration", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+	if !ok {
+		text := fmt.Sprintf("今日换一条次数已用完（每天 %d 次），明天再来吧", quota)
+		if quota == 0 {
+			text = "您的套餐不含换一条，发送 /tier 查看套餐"
+		}
+		return c.Respond(&tele.CallbackResponse{Text: text, ShowAlert: true})
+	}
+	_ = c.Respond(&tele.CallbackResponse{Text: "✍️ 正在换一条..."})
+
+	text, markup, found, err := h.scheduler.RegenerateReminder(msg.Chat.ID, msg.ID, user.ID)
+	if !found || err != nil {
+		if err := h.userRepo.ReleaseRegeneration(user.ID, date); err != nil {
+			logger.Warn("Failed to release regeneration", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+	}
+	switch {
+	case !found:
+		if err != nil {
+			logger.Warn("Failed to look up reminder message", zap.Int("message_id", msg.ID), zap.Error(err))
+		}
+		// Not (or no longer) a reminder of the user's subscriptions: drop the stale button
+		_, _ = c.Bot().EditReplyMarkup(msg, nil)
+		return nil
+	case errors.Is(err, service.ErrReminderExpired):
+		_, _ = c.Bot().EditReplyMarkup(msg, nil)
+		return c.Reply("⏳ 只能换当天的提醒，明天的提醒到达后再试吧")
+	case err != nil:
+		logger.Warn("Failed to regenerate reminder", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Reply("❌ AI 暂时没能写出新的提醒，请稍后再试（不计入今日次数）")
+	}
+
+	if _, err := c.Bot().Edit(msg, text, markup); err != nil {
+		logger.Error("Failed to edit regenerated reminder", zap.Uint("user_id", user.ID), zap.Error(err))
+		if err := h.userRepo.ReleaseRegeneration(user.ID, date); err != nil {
+			logger.Warn("Failed to release regeneration", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+		return c.Reply("❌ 更新提醒失败，请稍后再试")
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// maxSubscriptionLabelRunes caps the name a user gives a subscription
+const maxSubscriptionLabelRunes = 10
+
+// labelClearWords are the words accepted by /rename to remove a subscription's name
+var labelClearWords = map[string]bool{
+	"off": true,
+	"清除":  true,
+	"删除":  true,
+}
+
+// HandleRename handles /rename [城市] <名称|off>, naming a subscription ("家", "公司") so that
+// reminders and todo lists show the name alongside the city and commands accept it instead
+func (h *Handlers) HandleRename(c tele.Context) error {
+	user := userFrom(c)
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市\n请先使用 /subscribe <城市> <时间> 创建订阅")
+	}
+
+	args := c.Args()
+	var targetSub *model.Subscription
+	switch {
+	case len(args) == 0:
+		return c.Send(renameUsage(subs))
+	case len(args) == 1 && len(subs) == 1:
+		targetSub = &subs[0]
+	case len(args) == 2:
+		for i := range subs {
+			if subs[i].MatchesName(args[0]) {
+				targetSub = &subs[i]
+				break
+			}
+		}
+		if targetSub == nil {
+			return c.Send(fmt.Sprintf("❌ 您没有订阅 %s\n您的订阅：%s", args[0], h.formatCityList(subs)))
+		}
+	default:
+		return c.Send(renameUsage(subs))
+	}
+
+	label := args[len(args)-1]
+	if labelClearWords[strings.ToLower(label)] {
+		label = ""
+	} else if msg := validateLabel(label, targetSub, subs); msg != "" {
+		return c.Send(msg)
+	}
+
+	if err := h.subRepo.SetLabel(targetSub.ID, label); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Subscription renamed",
+		zap.Uint("subscription_id", targetSub.ID),
+		zap.String("label", label))
+	if label == "" {
+		return c.Send(fmt.Sprintf("✅ 已清除 %s 订阅的名称", targetSub.City))
+	}
+	return c.Send(fmt.Sprintf("✅ 已将 %s 订阅命名为「%s」\n每日提醒和待办清单将显示该名称，命令中也可以用它代替城市，如 /todo %s", targetSub.City, label, label))
+}
+
+// validateLabel checks a new name of a subscription, returning the reply explaining why it is
+// rejected or "" when it is valid. Names must be short and must not be the city or name of
+// another subscription, so that commands can tell them apart.
+func validateLabel(label string, target *model.Subscription, subs []model.Subscription) string {
+	if utf8.RuneCountInString(label) > maxSubscriptionLabelRunes {
+		return fmt.Sprintf("❌ 名称最多 %d 个字", maxSubscriptionLabelRunes)
+	}
+	for i := range subs {
+		if subs[i].ID != target.ID && subs[i].MatchesName(label) {
+			return fmt.Sprintf("❌ 「%s」已是您另一个订阅的城市或名称，请换一个名称", label)
+		}
+	}
+	return ""
+}
+
+// renameUsage lists the names of a user's subscriptions and how to change them
+func renameUsage(subs []model.Subscription) string {
+	var b strings.Builder
+	b.WriteString("🏷 订阅名称\n\n")
+	for _, sub := range subs {
+		label := sub.Label
+		if label == "" {
+			label = "未命名"
+		}
+		b.WriteString(fmt.Sprintf("📍 %s：%s\n", sub.City, label))
+	}
+	b.WriteString("\n用法:\n/rename <城市> <名称> - 为订阅命名，如 /rename 北京 家\n/rename <城市> off - 清除名称")
+	if len(subs) == 1 {
+		b.WriteString("\n💡 只有一个订阅时可省略城市")
+	}
+	return b.String()
+}
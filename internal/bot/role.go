@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	tele "gopkg.in/telebot.v3"
+)
+
+// grantUsage is the usage of /grant and /revoke
+const grantUsage = `用法:
+/grant - 查看管理人员
+/grant <用户ID> admin - 设为管理员（仅所有者）
+/grant <用户ID> support - 设为客服
+/revoke <用户ID> - 撤销授予的角色`
+
+// roleNames are the display names of staff roles
+var roleNames = map[string]string{
+	model.RoleOwner:   "所有者",
+	model.RoleAdmin:   "管理员",
+	model.RoleSupport: "客服",
+}
+
+// HandleGrant handles /grant (admins, checked by Permissions): lists staff without arguments, or
+// gives a Telegram user the admin or support role
+func (h *Handlers) HandleGrant(c tele.Context) error {
+	args := c.Args()
+	if len(args) == 0 {
+		return h.listStaff(c)
+	}
+	if len(args) != 2 {
+		return c.Send(grantUsage)
+	}
+	target, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return c.Send(grantUsage)
+	}
+	role := strings.ToLower(args[1])
+
+	actor := c.Sender().ID
+	err = h.roleSvc.Grant(actor, target, role)
+	switch {
+	case errors.Is(err, service.ErrRoleInvalid):
+		return c.Send("❌ 只能授予 admin 或 support 角色\n" + grantUsage)
+	case errors.Is(err, service.ErrRoleForbidden):
+		return c.Send("❌ 只能授予低于自己的角色，且不能更改同级或更高级人员的角色")
+	case err != nil:
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	h.auditSvc.Record(service.TelegramActor(actor), model.AuditRoleGrant, fmt.Sprintf("telegram:%d", target), "role="+role)
+	return c.Send(fmt.Sprintf("✅ 已将 %d 设为%s", target, roleNames[role]))
+}
+
+// HandleRevoke handles /revoke (admins, checked by Permissions): removes the role granted to a
+// Telegram user
+func (h *Handlers) HandleRevoke(c tele.Context) error {
+	args := c.Args()
+	if len(args) != 1 {
+		return c.Send(grantUsage)
+	}
+	target, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return c.Send(grantUsage)
+	}
+
+	actor := c.Sender().ID
+	role, err := h.roleSvc.Revoke(actor, target)
+	switch {
+	case errors.Is(err, service.ErrRoleNotGranted):
+		return c.Send("❌ 该用户没有被授予角色")
+	case errors.Is(err, service.ErrRoleConfigured):
+		return c.Send("❌ 该用户的角色在配置文件中设置，请修改配置")
+	case errors.Is(err, service.ErrRoleForbidden):
+		return c.Send("❌ 只能撤销低于自己的角色")
+	case err != nil:
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	h.auditSvc.Record(service.TelegramActor(actor), model.AuditRoleRevoke, fmt.Sprintf("telegram:%d", target), "role="+role)
+	return c.Send(fmt.Sprintf("✅ 已撤销 %d 的%s角色", target, roleNames[role]))
+}
+
+// listStaff sends the staff users and their roles
+func (h *Handlers) listStaff(c tele.Context) error {
+	staff, err := h.roleSvc.Staff()
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	var b strings.Builder
+	b.WriteString("👮 管理人员\n")
+	if len(staff) == 0 {
+		b.WriteString("\n（无）")
+	}
+	for _, m := range staff {
+		line := fmt.Sprintf("\n%d - %s", m.TelegramID, roleNames[m.Role])
+		if m.Configured {
+			line += "（配置文件）"
+		}
+		b.WriteString(line)
+	}
+	b.WriteString("\n\n" + grantUsage)
+	return c.Send(b.String())
+}
@@ -0,0 +1,238 @@
+package bot
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/timeparse"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// maxStartPayload is the maximum length of a /start deep link payload accepted by Telegram
+const maxStartPayload = 64
+
+// startLink is a decoded /start deep link payload.
+//
+// Payload grammar (Telegram allows only A-Z, a-z, 0-9, _ and -):
+//
+//	[r<referral code>-]subscribe_<ASCII city>_<HHMM>   e.g. subscribe_beijing_0800
+//	[r<referral code>-]sub_<base64url city>_<HHMM>     generated by /share for any city
+//	r<referral code>                                   invitation without a city
+//	todo_<token>                                       invitation to co-manage a todo list
+//	xfer_<user>_<expiry>_<signature>                   transfer of subscriptions to the opening chat
+type startLink struct {
+	ReferrerID   uint
+	City         string
+	ReminderTime string
+	TodoInvite   string
+	Transfer     string // Signed transfer token, verified by service.TransferService
+}
+
+// parseStartPayload decodes a /start payload; unknown or malformed parts are ignored
+func parseStartPayload(payload string) startLink {
+	var link startLink
+
+	if token, ok := strings.CutPrefix(payload, todoInvitePrefix); ok {
+		link.TodoInvite = token
+		return link
+	}
+	if strings.HasPrefix(payload, service.TransferPrefix) {
+		link.Transfer = payload
+		return link
+	}
+
+	if strings.HasPrefix(payload, "r") {
+		code, rest, _ := strings.Cut(payload[1:], "-")
+		if id, err := strconv.ParseUint(code, 36, 32); err == nil && id > 0 {
+			link.ReferrerID = uint(id)
+		}
+		payload = rest
+	}
+
+	var token string
+	encoded := false
+	if rest, ok := strings.CutPrefix(payload, "subscribe_"); ok {
+		token = rest
+	} else if rest, ok := strings.CutPrefix(payload, "sub_"); ok {
+		token = rest
+		encoded = true
+	} else {
+		return link
+	}
+
+	// The time is always the last segment; base64url cities may themselves contain '_'
+	i := strings.LastIndex(token, "_")
+	if i <= 0 {
+		return link
+	}
+	rawCity, rawTime := token[:i], token[i+1:]
+
+	reminderTime, err := timeparse.Parse(rawTime)
+	if err != nil {
+		return link
+	}
+
+	city := rawCity
+	if encoded {
+		decoded, err := base64.RawURLEncoding.DecodeString(rawCity)
+		if err != nil || !utf8.Valid(decoded) {
+			return link
+		}
+		city = string(decoded)
+	}
+	city = strings.TrimSpace(city)
+	if city == "" {
+		return link
+	}
+
+	link.City = city
+	link.ReminderTime = reminderTime
+	return link
+}
+
+// referralCode returns the code identifying a user in share links
+func referralCode(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 36)
+}
+
+// shareLink builds a deep link inviting friends on behalf of a user, pre-filling the
+// subscription when given. The city is dropped if the payload would exceed Telegram's limit.
+func shareLink(botUsername string, userID uint, sub *model.Subscription) string {
+	payload := "r" + referralCode(userID)
+	if sub != nil {
+		withCity := fmt.Sprintf("%s-sub_%s_%s", payload,
+			base64.RawURLEncoding.EncodeToString([]byte(sub.City)),
+			strings.ReplaceAll(sub.ReminderTime, ":", ""))
+		if len(withCity) <= maxStartPayload {
+			payload = withCity
+		}
+	}
+	return fmt.Sprintf("https://t.me/%s?start=%s", botUsername, payload)
+}
+
+// applyReferral credits the referrer of a new user and lets them know.
+// Only users who never subscribed before can be referred, and only once.
+func (h *Handlers) applyReferral(c tele.Context, user *model.User, referrerID uint) {
+	if referrerID == user.ID || user.ReferredByID != nil {
+		return
+	}
+
+	count, err := h.subRepo.CountByUserUnscoped(user.ID)
+	if err != nil || count > 0 {
+		return
+	}
+	referrer, err := h.userRepo.FindByID(referrerID)
+	if err != nil || referrer == nil {
+		return
+	}
+
+	applied, err := h.userRepo.SetReferrer(user.ID, referrer.ID)
+	if err != nil || !applied {
+		return
+	}
+	user.ReferredByID = &referrer.ID
+
+	logger.Info("Referral recorded",
+		zap.Uint("user_id", user.ID),
+		zap.Uint("referrer_id", referrer.ID))
+
+	friend := user.FirstName
+	if friend == "" {
+		friend = "一位新朋友"
+	}
+	if err := h.notifySvc.NotifyUser(*referrer, fmt.Sprintf("🎉 %s 通过你的分享链接加入了每日提醒！", friend)); err != nil {
+		logger.Warn("Failed to notify referrer",
+			zap.Uint("referrer_id", referrer.ID),
+			zap.Error(err))
+	}
+}
+
+// promptSharedSubscription asks a user who opened a share link to confirm the pre-filled subscription
+func (h *Handlers) promptSharedSubscription(c tele.Context, link startLink) error {
+	data := map[string]string{"city": link.City, "time": link.ReminderTime}
+	if err := h.conversations.Start(botOf(c), chatIDOf(c), flowSubscribe, "confirm", data); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	return c.Send(fmt.Sprintf("📬 是否订阅 %s 的每日提醒（每天 %s）？\n\n回复「是」确认订阅，或直接发送其他时间修改（发送 /cancel 取消）",
+		link.City, link.ReminderTime))
+}
+
+// subscribeConfirmStep confirms a subscription pre-filled by a share link
+func (h *Handlers) subscribeConfirmStep(c tele.Context, state *ConversationState) error {
+	reply := strings.ToLower(strings.TrimSpace(c.Text()))
+
+	reminderTime := state.Data["time"]
+	switch reply {
+	case "是", "好", "确认", "订阅", "y", "yes", "ok":
+	default:
+		parsed, err := timeparse.Parse(reply)
+		if err != nil {
+			return c.Send("❓ 回复「是」确认订阅，或发送其他时间（如 07:30、早上8点），发送 /cancel 取消")
+		}
+		reminderTime = parsed
+	}
+
+	state.Finish()
+	return h.subscribe(c, userFrom(c), state.Data["city"], reminderTime, "", "")
+}
+
+// HandleShare handles the /share [city] command, generating invitation links
+func (h *Handlers) HandleShare(c tele.Context) error {
+	chatID := c.Sender().ID
+	user := userFrom(c)
+
+	botUsername := c.Bot().Me.Username
+	if botUsername == "" {
+		return c.Send("❌ 暂时无法生成分享链接，请稍后再试。")
+	}
+
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Int64("chat_id", chatID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if args := c.Args(); len(args) > 0 {
+		city := strings.Join(args, " ")
+		var matched []model.Subscription
+		for _, sub := range subs {
+			if sub.MatchesName(city) {
+				matched = append(matched, sub)
+			}
+		}
+		if len(matched) == 0 {
+			if len(subs) == 0 {
+				return c.Send(fmt.Sprintf("❌ 您没有订阅 %s", city))
+			}
+			return c.Send(fmt.Sprintf("❌ 您没有订阅 %s\n当前订阅：%s", city, h.formatCityList(subs)))
+		}
+		subs = matched
+	}
+
+	var msg strings.Builder
+	msg.WriteString("📤 邀请好友\n\n")
+	if len(subs) == 0 {
+		msg.WriteString(shareLink(botUsername, user.ID, nil))
+		msg.WriteString("\n\n好友点击链接即可开始使用每日提醒。")
+	} else {
+		for i := range subs {
+			msg.WriteString(fmt.Sprintf("📍 %s（每天 %s）\n%s\n\n", subs[i].City, subs[i].ReminderTime, shareLink(botUsername, user.ID, &subs[i])))
+		}
+		msg.WriteString("好友点击链接即可一键订阅同一城市的每日提醒。")
+	}
+
+	if count, err := h.userRepo.CountReferrals(user.ID); err == nil && count > 0 {
+		msg.WriteString(fmt.Sprintf("\n\n🤝 已通过你的链接加入：%d 位", count))
+	}
+
+	logger.Info("Share links generated",
+		zap.Int64("chat_id", chatID),
+		zap.Int("link_count", max(len(subs), 1)))
+	return c.Send(msg.String(), tele.NoPreview)
+}
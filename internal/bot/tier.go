@@ -0,0 +1,117 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	tele "gopkg.in/telebot.v3"
+)
+
+// tierUsage is the usage of the admin form of /tier
+const tierUsage = `用法（客服及以上）:
+/tier <chat_id> - 查看用户的套餐
+/tier <chat_id> premium [天数] - 设为高级版（不填天数则长期有效，仅管理员）
+/tier <chat_id> free - 恢复为免费版（仅管理员）`
+
+// tierName returns the display name of a service tier
+func tierName(tier string) string {
+	if tier == model.TierPremium {
+		return "高级版"
+	}
+	return "免费版"
+}
+
+// HandleTier handles /tier: shows the user's own tier and limits, or lets support staff view and
+// admins set the tier of a user by chat ID (roles checked by the Permissions middleware)
+func (h *Handlers) HandleTier(c tele.Context) error {
+	args := c.Args()
+	if len(args) == 0 {
+		return c.Send(h.formatTier(userFrom(c), "📦 我的套餐"))
+	}
+
+	chatID, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || len(args) > 3 {
+		return c.Send(tierUsage)
+	}
+	target, err := h.userRepo.FindByChatID(botOf(c), chatID)
+	if err != nil {
+		return c.Send("❌ 用户不存在")
+	}
+	if target == nil {
+		return c.Send("❌ 用户不存在")
+	}
+	if len(args) == 1 {
+		return c.Send(h.formatTier(target, fmt.Sprintf("📦 用户 %d 的套餐", chatID)))
+	}
+
+	tier := args[1]
+	if tier != model.TierFree && tier != model.TierPremium {
+		return c.Send(tierUsage)
+	}
+	days := 0
+	if len(args) == 3 {
+		if tier != model.TierPremium {
+			return c.Send(tierUsage)
+		}
+		days, err = strconv.Atoi(args[2])
+		if err != nil || days <= 0 {
+			return c.Send("❌ 天数须为正整数\n" + tierUsage)
+		}
+	}
+
+	if err := h.tierSvc.SetTier(target, tier, days, time.Now()); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	h.auditSvc.Record(service.TelegramActor(c.Sender().ID), model.AuditTierSet,
+		fmt.Sprintf("user:%d", target.ID), fmt.Sprintf("tier=%s days=%d", tier, days))
+	return c.Send("✅ 已更新\n\n" + h.formatTier(target, fmt.Sprintf("📦 用户 %d 的套餐", chatID)))
+}
+
+// formatTier describes a user's tier, its limits and the current usage
+func (h *Handlers) formatTier(user *model.User, title string) string {
+	now := time.Now()
+	tier := user.EffectiveTier(now)
+	limits := h.tierSvc.Limits(user, now)
+
+	var b strings.Builder
+	b.WriteString(title + "\n\n")
+	b.WriteString("当前：" + tierName(tier))
+	if tier == model.TierPremium && user.TierUntil != nil {
+		b.WriteString(fmt.Sprintf("（有效期至 %s）", user.TierUntil.In(h.timezone).Format("2006-01-02 15:04")))
+	} else if user.Tier == model.TierPremium {
+		b.WriteString("（高级版已到期）")
+	}
+	b.WriteString("\n")
+
+	subs, err := h.subRepo.CountActiveByUser(user.ID)
+	if err == nil {
+		b.WriteString(fmt.Sprintf("📍 订阅城市：%d / %d\n", subs, limits.Subscriptions))
+	}
+	if watches, err := h.indexWatch.Repository().FindByUser(user.ID); err == nil {
+		b.WriteString(fmt.Sprintf("🔔 生活指数提醒：%d / %d\n", len(watches), limits.IndexWatches))
+	}
+	if h.aiSvc.RegenerateQuota() > 0 {
+		b.WriteString(fmt.Sprintf("🔁 AI 提醒每天换一条：%d 次\n", limits.Regenerations))
+	}
+	calls := 0
+	if user.APIDate == now.In(h.timezone).Format("2006-01-02") {
+		calls = user.APICalls
+	}
+	b.WriteString(fmt.Sprintf("🌐 今日实时查询：%d / %d\n", calls, limits.APICalls))
+
+	if tier == model.TierFree {
+		premium := h.tierSvc.LimitsOf(model.TierPremium)
+		b.WriteString(fmt.Sprintf("\n⭐ 高级版：最多 %d 个城市、%d 个指数提醒、每天 %d 次实时查询", premium.Subscriptions, premium.IndexWatches, premium.APICalls))
+		if h.aiSvc.RegenerateQuota() > 0 {
+			b.WriteString(fmt.Sprintf("、每天换一条 %d 次", premium.Regenerations))
+		}
+		if h.paymentSvc != nil {
+			b.WriteString("\n发送 /premium 升级")
+		}
+	}
+	return b.String()
+}
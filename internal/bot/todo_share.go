@@ -0,0 +1,254 @@
+package bot
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// todoInvitePrefix marks /start payloads inviting a chat to co-manage a todo list
+	todoInvitePrefix = "todo_"
+	// todoInviteTTL is how long a todo list invitation link stays valid
+	todoInviteTTL = 24 * time.Hour
+)
+
+// todoListTitle names a subscription's todo list, marking lists shared by someone else
+func todoListTitle(sub *model.Subscription) string {
+	if sub.SharedListID != nil {
+		return sub.DisplayName() + "（共享）"
+	}
+	return sub.DisplayName()
+}
+
+// shareTodoList creates a one-time link inviting another chat to co-manage a subscription's todos
+func (h *Handlers) shareTodoList(c tele.Context, sub *model.Subscription) error {
+	if sub.SharedListID != nil {
+		return c.Send("❌ 只有清单创建者可以邀请成员")
+	}
+	botUsername := c.Bot().Me.Username
+	if botUsername == "" {
+		return c.Send("❌ 暂时无法生成邀请链接，请稍后再试。")
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	invite := &model.TodoInvite{
+		Token:          hex.EncodeToString(raw),
+		SubscriptionID: sub.ID,
+		ExpiresAt:      time.Now().Add(todoInviteTTL),
+	}
+	if err := h.inviteRepo.Create(invite); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	logger.Info("Todo list invite created",
+		zap.Uint("subscription_id", sub.ID),
+		zap.String("city", sub.City))
+
+	link := fmt.Sprintf("https://t.me/%s?start=%s%s", botUsername, todoInvitePrefix, invite.Token)
+	return c.Send(fmt.Sprintf("👨‍👩‍👧 邀请家人共同管理 %s 的待办\n\n%s\n\n链接 24 小时内有效，仅可使用一次。对方加入后，双方看到同一份待办清单，任何一方完成待办时另一方都会收到通知。",
+		sub.City, link), tele.NoPreview)
+}
+
+// acceptTodoInvite joins the sender to the todo list of an invitation, subscribing them to the
+// city first if needed. Their existing todos for the city are merged into the shared list.
+func (h *Handlers) acceptTodoInvite(c tele.Context, token string) error {
+	user := userFrom(c)
+
+	invite, err := h.inviteRepo.Claim(token, time.Now())
+	if err != nil {
+		logger.Error("Failed to claim todo invite", zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if invite == nil {
+		return c.Send("❌ 邀请链接无效或已过期，请让对方重新发送 /todo <城市> share")
+	}
+
+	owner, err := h.subRepo.FindByIDWithUser(invite.SubscriptionID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if owner == nil {
+		return c.Send("❌ 该待办清单已不存在")
+	}
+	if owner.UserID == user.ID {
+		return c.Send("💡 这是你自己的待办清单，把链接发给家人即可邀请对方加入")
+	}
+
+	sub, err := h.subRepo.FindByUserAndCity(user.ID, owner.City)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if sub == nil {
+		// Subscribe with the owner's reminder time; subscribe reports the outcome itself
+		if err := h.subscribe(c, user, owner.City, owner.ReminderTime, "", ""); err != nil {
+			return err
+		}
+		if sub, err = h.subRepo.FindByUserAndCity(user.ID, owner.City); err != nil || sub == nil {
+			return err
+		}
+	}
+	if sub.SharedListID != nil && *sub.SharedListID == owner.ID {
+		return c.Send(fmt.Sprintf("✅ 你已在共同管理 %s 的待办清单", owner.City))
+	}
+
+	members, err := h.subRepo.FindListMembers(sub.ID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(members) > 0 {
+		return c.Send(fmt.Sprintf("❌ 你的 %s 待办清单已共享给其他成员，无法再加入其他清单", owner.City))
+	}
+
+	if err := h.todoRepo.MoveToSubscription(sub.TodoListID(), owner.ID); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if err := h.subRepo.SetSharedList(sub.ID, &owner.ID); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	logger.Info("Joined shared todo list",
+		zap.Uint("user_id", user.ID),
+		zap.Uint("subscription_id", sub.ID),
+		zap.Uint("list_id", owner.ID))
+
+	if err := h.notifySvc.NotifyUser(owner.User,
+		fmt.Sprintf("👨‍👩‍👧 %s 加入了你的 %s 待办清单", user.DisplayName(), owner.City)); err != nil {
+		logger.Warn("Failed to notify todo list owner",
+			zap.Uint("list_id", owner.ID),
+			zap.Error(err))
+	}
+	return c.Send(fmt.Sprintf("✅ 已加入 %s 的 %s 待办清单\n\n使用 /todo %s 查看共享待办，你原有的待办已合并到共享清单。",
+		owner.User.DisplayName(), owner.City, owner.City))
+}
+
+// listTodoMembers lists the owner and members of a subscription's todo list
+func (h *Handlers) listTodoMembers(c tele.Context, sub *model.Subscription) error {
+	owner, members, err := h.todoListParticipants(sub)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(members) == 0 {
+		return c.Send(fmt.Sprintf("👤 %s 的待办清单尚未共享\n\n使用 /todo %s share 邀请家人共同管理", sub.City, sub.City))
+	}
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("👨‍👩‍👧 %s 共享待办清单\n\n", sub.City))
+	if owner != nil {
+		msg.WriteString(fmt.Sprintf("👑 创建者：%s\n", owner.User.DisplayName()))
+	}
+	msg.WriteString("成员：\n")
+	for i, m := range members {
+		msg.WriteString(fmt.Sprintf("%d. %s\n", i+1, m.User.DisplayName()))
+	}
+	if sub.SharedListID == nil {
+		msg.WriteString(fmt.Sprintf("\n使用 /todo %s remove <编号> 移除成员", sub.City))
+	} else {
+		msg.WriteString(fmt.Sprintf("\n使用 /todo %s leave 退出共享清单", sub.City))
+	}
+	return c.Send(msg.String())
+}
+
+// removeTodoMember lets the owner of a todo list remove a member by number
+func (h *Handlers) removeTodoMember(c tele.Context, sub *model.Subscription, args []string) error {
+	if sub.SharedListID != nil {
+		return c.Send("❌ 只有清单创建者可以移除成员")
+	}
+	members, err := h.subRepo.FindListMembers(sub.ID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(members) == 0 {
+		return c.Send(fmt.Sprintf("👤 %s 的待办清单尚未共享", sub.City))
+	}
+	if len(args) == 0 {
+		return c.Send("❌ 用法: /todo " + sub.City + " remove <编号>\n使用 /todo " + sub.City + " members 查看成员编号")
+	}
+	idx, err := strconv.Atoi(args[0])
+	if err != nil || idx < 1 || idx > len(members) {
+		return c.Send("❌ 编号无效，请输入 1 到 " + strconv.Itoa(len(members)) + " 之间的数字")
+	}
+
+	member := members[idx-1]
+	if err := h.subRepo.SetSharedList(member.ID, nil); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	logger.Info("Removed todo list member",
+		zap.Uint("list_id", sub.ID),
+		zap.Uint("member_subscription_id", member.ID))
+
+	if err := h.notifySvc.NotifyUser(member.User,
+		fmt.Sprintf("👋 你已被移出 %s 的共享待办清单", sub.City)); err != nil {
+		logger.Warn("Failed to notify removed member", zap.Uint("member_subscription_id", member.ID), zap.Error(err))
+	}
+	return c.Send(fmt.Sprintf("✅ 已将 %s 移出 %s 的待办清单", member.User.DisplayName(), sub.City))
+}
+
+// leaveTodoList lets a member stop co-managing a todo list; the shared todos stay with the owner
+func (h *Handlers) leaveTodoList(c tele.Context, sub *model.Subscription) error {
+	if sub.SharedListID == nil {
+		return c.Send("❌ 这是你自己的待办清单，无需退出")
+	}
+	if err := h.subRepo.SetSharedList(sub.ID, nil); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	logger.Info("Left shared todo list",
+		zap.Uint("subscription_id", sub.ID),
+		zap.Uint("list_id", *sub.SharedListID))
+	return c.Send(fmt.Sprintf("✅ 已退出 %s 的共享待办清单，之后的待办将单独管理", sub.City))
+}
+
+// todoListParticipants returns the owner subscription and the member subscriptions of
+// the todo list used by sub, with users preloaded. The owner is nil once it unsubscribed.
+func (h *Handlers) todoListParticipants(sub *model.Subscription) (*model.Subscription, []model.Subscription, error) {
+	listID := sub.TodoListID()
+	owner, err := h.subRepo.FindByIDWithUser(listID)
+	if err != nil {
+		return nil, nil, err
+	}
+	members, err := h.subRepo.FindListMembers(listID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return owner, members, nil
+}
+
+// notifyTodoCompleted tells the other participants of a shared todo list that a todo was completed
+func (h *Handlers) notifyTodoCompleted(sub *model.Subscription, actor *model.User, content string) {
+	owner, members, err := h.todoListParticipants(sub)
+	if err != nil || len(members) == 0 {
+		return
+	}
+
+	var users []model.User
+	if owner != nil && owner.UserID != actor.ID {
+		users = append(users, owner.User)
+	}
+	for _, m := range members {
+		if m.UserID != actor.ID {
+			users = append(users, m.User)
+		}
+	}
+
+	text := fmt.Sprintf("✅ %s 完成了 %s 共享待办：%s", actor.DisplayName(), sub.City, content)
+	for _, user := range users {
+		if err := h.notifySvc.NotifyUser(user, text); err != nil {
+			logger.Warn("Failed to notify todo list participant",
+				zap.Int64("chat_id", user.ChatID),
+				zap.Error(err))
+		}
+	}
+}
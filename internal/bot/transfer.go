@@ -0,0 +1,132 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+var (
+	// btnTransferConfirm performs a transfer in the destination chat; data is the transfer token
+	btnTransferConfirm = &tele.Btn{Unique: "transfer"}
+	// btnTransferCancel dismisses a transfer prompt
+	btnTransferCancel = &tele.Btn{Unique: "transfer_cancel"}
+)
+
+// HandleTransfer handles the /transfer command, creating a link that moves the user's subscriptions
+// and todos to the account opening it
+func (h *Handlers) HandleTransfer(c tele.Context) error {
+	user := userFrom(c)
+	botUsername := c.Bot().Me.Username
+	if botUsername == "" {
+		return c.Send("❌ 暂时无法生成转移链接，请稍后再试。")
+	}
+
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("❌ 您还没有订阅任何城市，无需转移")
+	}
+
+	token := h.transferSvc.Token(user, time.Now().Add(service.TransferTTL))
+	logger.Info("Transfer link created",
+		zap.Uint("user_id", user.ID),
+		zap.Int("subscriptions", len(subs)))
+	return c.Send(fmt.Sprintf(`📦 转移订阅到新账号
+
+请用新的 Telegram 账号打开以下链接，并在新账号中确认：
+https://t.me/%s?start=%s
+
+确认后，%s 的订阅及其待办将转移到新账号，本账号不再收到这些提醒。
+⚠️ 链接 1 小时内有效，任何打开链接的人都能取走您的订阅，请勿转发给他人。`,
+		botUsername, token, h.formatCityList(subs)), tele.NoPreview)
+}
+
+// promptTransfer shows what a transfer link opened in this chat would move and asks for confirmation
+func (h *Handlers) promptTransfer(c tele.Context, token string) error {
+	user := userFrom(c)
+	preview, err := h.transferSvc.Preview(botOf(c), token, time.Now())
+	switch {
+	case errors.Is(err, service.ErrTransferExpired):
+		return c.Send("❌ 转移链接已过期，请在原账号中重新发送 /transfer")
+	case errors.Is(err, service.ErrTransferUsed):
+		return c.Send("❌ 转移链接已经使用过，如需再次转移请在原账号中重新发送 /transfer")
+	case errors.Is(err, service.ErrTransferInvalid):
+		return c.Send("❌ 转移链接无效")
+	case err != nil:
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if preview.From.ID == user.ID {
+		return c.Send("💡 这是本账号的转移链接，请用新的 Telegram 账号打开")
+	}
+	if len(preview.Subscriptions) == 0 {
+		return c.Send("❌ 原账号没有可转移的订阅（可能已经转移过了）")
+	}
+
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("📦 是否将 %s 的订阅转移到本账号？\n\n", preview.From.DisplayName()))
+	for _, sub := range preview.Subscriptions {
+		msg.WriteString(fmt.Sprintf("📍 %s（每天 %s）\n", sub.City, sub.ReminderTime))
+	}
+	if preview.Todos > 0 {
+		msg.WriteString(fmt.Sprintf("📝 未完成待办 %d 条\n", preview.Todos))
+	}
+	msg.WriteString("\n本账号已订阅的同一城市会与之合并（待办合并，保留本账号的提醒时间）。")
+
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(
+		markup.Data("✅ 确认转移", btnTransferConfirm.Unique, token),
+		markup.Data("取消", btnTransferCancel.Unique),
+	))
+	return c.Send(msg.String(), markup)
+}
+
+// HandleTransferConfirm performs the transfer confirmed in the destination chat and lets the
+// source chat know
+func (h *Handlers) HandleTransferConfirm(c tele.Context) error {
+	user := userFrom(c)
+	from, moved, merged, err := h.transferSvc.Transfer(c.Data(), user, time.Now())
+	switch {
+	case errors.Is(err, service.ErrTransferExpired):
+		_ = c.Respond()
+		return c.Edit("❌ 转移链接已过期，请在原账号中重新发送 /transfer")
+	case errors.Is(err, service.ErrTransferUsed):
+		_ = c.Respond()
+		return c.Edit("❌ 转移链接已经使用过，如需再次转移请在原账号中重新发送 /transfer")
+	case errors.Is(err, service.ErrTransferInvalid):
+		_ = c.Respond()
+		return c.Edit("❌ 转移链接无效")
+	case err != nil:
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+	_ = c.Respond()
+
+	if moved+merged == 0 {
+		return c.Edit("❌ 原账号没有可转移的订阅（可能已经转移过了）")
+	}
+	if err := h.notifySvc.NotifyUser(*from, fmt.Sprintf("📦 您的 %d 个订阅已转移到新账号 %s，本账号将不再收到这些提醒。", moved+merged, user.DisplayName())); err != nil {
+		logger.Warn("Failed to notify transfer source",
+			zap.Uint("user_id", from.ID),
+			zap.Error(err))
+	}
+
+	reply := fmt.Sprintf("✅ 已转移 %d 个订阅", moved)
+	if merged > 0 {
+		reply += fmt.Sprintf("，合并 %d 个已有订阅", merged)
+	}
+	return c.Edit(reply + "\n使用 /mystatus 查看当前订阅，/todo 查看待办")
+}
+
+// HandleTransferCancel dismisses a transfer prompt
+func (h *Handlers) HandleTransferCancel(c tele.Context) error {
+	_ = c.Respond()
+	return c.Edit("已取消转移")
+}
@@ -0,0 +1,106 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// tripTimeout bounds a travel briefing, which may wait for the AI narrative
+const tripTimeout = 60 * time.Second
+
+// tripDatePattern matches trip dates like 10-01, 10/1, 10.1 or 10月1日
+var tripDatePattern = regexp.MustCompile(`^(\d{1,2})\s*[-/.月]\s*(\d{1,2})\s*日?$`)
+
+// HandleTrip handles /trip <城市> <开始日期> <结束日期>, the weather briefing of a trip
+func (h *Handlers) HandleTrip(c tele.Context) error {
+	args := c.Args()
+	if len(args) != 3 {
+		return c.Send("❌ 用法: /trip <城市> <开始日期> <结束日期>\n示例: /trip 杭州 10-01 10-05、/trip 成都 明天 后天\n💡 支持 15 天内的行程")
+	}
+	city := args[0]
+	today := dateOf(time.Now().In(h.timezone))
+	from, errFrom := parseTripDate(args[1], today)
+	to, errTo := parseTripDate(args[2], today)
+	if errFrom != nil || errTo != nil {
+		return c.Send("❌ 日期格式不正确\n支持：今天、明天、后天、10-01、10月1日、2025-10-01")
+	}
+	if err := service.ValidateTripRange(from, to, today); err != nil {
+		return c.Send(tripRangeMessage(err))
+	}
+	key := reportKey("trip", city, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
+	}
+	_ = c.Notify(tele.Typing)
+
+	ctx, cancel := context.WithTimeout(context.Background(), tripTimeout)
+	defer cancel()
+	briefing, err := h.tripSvc.Briefing(ctx, city, from, to, today)
+	if err != nil {
+		if errors.Is(err, service.ErrTripNoForecast) {
+			return c.Send(fmt.Sprintf("❌ 暂无 %s 这几天的预报，请临近出发时再查询", city))
+		}
+		logger.Error("Failed to get trip briefing", zap.String("city", city), zap.Error(err))
+		return c.Send(fmt.Sprintf("❌ 无法获取 %s 的行程天气，请检查城市名称是否正确", city))
+	}
+	h.rememberReport(c, key, briefing)
+	return c.Send(briefing)
+}
+
+// tripRangeMessage explains why a trip's dates were rejected
+func tripRangeMessage(err error) string {
+	switch {
+	case errors.Is(err, service.ErrTripRange):
+		return "❌ 结束日期不能早于开始日期"
+	case errors.Is(err, service.ErrTripPast):
+		return "❌ 开始日期不能早于今天"
+	case errors.Is(err, service.ErrTripTooLong):
+		return "❌ 行程最长 15 天"
+	default:
+		return "❌ 只能查询 15 天内出发的行程"
+	}
+}
+
+// parseTripDate parses 今天, 明天, 后天, YYYY-MM-DD or a month and day (the next such date) as
+// midnight of today's timezone
+func parseTripDate(s string, today time.Time) (time.Time, error) {
+	switch s {
+	case "今天", "today":
+		return today, nil
+	case "明天", "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "后天":
+		return today.AddDate(0, 0, 2), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, today.Location()); err == nil {
+		return t, nil
+	}
+	m := tripDatePattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("invalid date: %s", s)
+	}
+	month, _ := strconv.Atoi(m[1])
+	day, _ := strconv.Atoi(m[2])
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, fmt.Errorf("invalid date: %s", s)
+	}
+	date := time.Date(today.Year(), time.Month(month), day, 0, 0, 0, 0, today.Location())
+	if date.Before(today) {
+		date = date.AddDate(1, 0, 0)
+	}
+	return date, nil
+}
+
+// dateOf returns midnight of a time's day
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,37 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// HandleUV handles /uv [城市], the hour-by-hour UV curve with sunscreen advice
+func (h *Handlers) HandleUV(c tele.Context) error {
+	city := strings.Join(c.Args(), " ")
+	if city == "" {
+		subs, err := h.subRepo.FindByUserID(userFrom(c).ID)
+		if err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		if len(subs) == 0 {
+			return c.Send("❌ 请指定城市或先使用 /subscribe 订阅\n用法: /uv <城市>")
+		}
+		city = subs[0].City
+	}
+
+	key := reportKey("uv", city)
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
+	}
+	report, err := h.weatherSvc.GetUVReport(city)
+	if err != nil {
+		logger.Error("Failed to get UV report", zap.String("city", city), zap.Error(err))
+		return c.Send(fmt.Sprintf("❌ 无法获取 %s 的紫外线预报，请稍后再试。", city))
+	}
+	h.rememberReport(c, key, report)
+	return c.Send(report)
+}
@@ -0,0 +1,85 @@
+package bot
+
+import (
+	"errors"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// Callback button endpoints of the votes on AI reminders (see service.ReminderActionVote*)
+var (
+	btnVoteReminder = &tele.Btn{Unique: service.ReminderActionVote}
+	btnVoteReason   = &tele.Btn{Unique: service.ReminderActionVoteReason}
+)
+
+// HandleVoteReminder records a 👍/👎 vote on an AI reminder; a 👎 vote asks for the reason,
+// which is what the tone hints are learned from
+func (h *Handlers) HandleVoteReminder(c tele.Context) error {
+	if h.toneSvc == nil {
+		return c.Respond()
+	}
+	vote := 1
+	if c.Data() == "-1" {
+		vote = -1
+	}
+	user := userFrom(c)
+	msg := c.Message()
+	if err := h.toneSvc.Vote(user.ID, msg.Chat.ID, msg.ID, vote); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+
+	if vote > 0 {
+		h.editReminderMarkup(c, vote)
+		return c.Respond(&tele.CallbackResponse{Text: "🙏 感谢反馈，很高兴提醒对你有帮助！"})
+	}
+	markup := &tele.ReplyMarkup{}
+	markup.Inline(markup.Row(
+		markup.Data("📏 太长了", btnVoteReason.Unique, model.VoteReasonLong),
+		markup.Data("😶 emoji 太多", btnVoteReason.Unique, model.VoteReasonEmoji),
+		markup.Data("💬 其他", btnVoteReason.Unique, model.VoteReasonOther),
+	))
+	if _, err := c.Bot().EditReplyMarkup(msg, markup); err != nil {
+		logger.Warn("Failed to show vote reasons", zap.Int("message_id", msg.ID), zap.Error(err))
+	}
+	return c.Respond(&tele.CallbackResponse{Text: "哪里不满意？选一下原因，之后的提醒会调整"})
+}
+
+// HandleVoteReason records why an AI reminder was voted down and restores its buttons
+func (h *Handlers) HandleVoteReason(c tele.Context) error {
+	if h.toneSvc == nil {
+		return c.Respond()
+	}
+	reason := c.Data()
+	switch reason {
+	case model.VoteReasonLong, model.VoteReasonEmoji, model.VoteReasonOther:
+	default:
+		return c.Respond()
+	}
+	msg := c.Message()
+	if _, err := h.toneSvc.SetReason(msg.Chat.ID, msg.ID, reason); err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+	h.editReminderMarkup(c, -1)
+
+	text := "🙏 感谢反馈，我们会继续改进提醒内容。"
+	switch reason {
+	case model.VoteReasonLong:
+		text = "🙏 已记录，之后的提醒会更简短"
+	case model.VoteReasonEmoji:
+		text = "🙏 已记录，之后的提醒会少用 emoji"
+	}
+	return c.Respond(&tele.CallbackResponse{Text: text})
+}
+
+// editReminderMarkup restores the buttons of an AI reminder, marking the chat's vote
+func (h *Handlers) editReminderMarkup(c tele.Context, vote int) {
+	markup := service.ReminderMarkup(true, vote, h.aiSvc.RegenerateQuota() > 0)
+	_, err := c.Bot().EditReplyMarkup(c.Message(), markup)
+	if err != nil && !errors.Is(err, tele.ErrSameMessageContent) && !errors.Is(err, tele.ErrMessageNotModified) {
+		logger.Warn("Failed to update reminder buttons", zap.Int("message_id", c.Message().ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// Callback button endpoints of warning notifications (see service.WarningAction*)
+var (
+	btnWarningAir     = &tele.Btn{Unique: service.WarningActionAir}
+	btnWarningWeather = &tele.Btn{Unique: service.WarningActionWeather}
+	btnWarningMute    = &tele.Btn{Unique: service.WarningActionMute}
+)
+
+// registerWarningActionHandlers registers the button handlers of warning notifications
+func (h *Handlers) registerWarningActionHandlers(bot *tele.Bot) {
+	bot.Handle(btnWarningAir, h.HandleWarningAir)
+	bot.Handle(btnWarningWeather, h.HandleWarningWeather)
+	bot.Handle(btnWarningMute, h.HandleWarningMute)
+}
+
+// HandleWarningAir replies to a warning notification with the air quality of its city
+func (h *Handlers) HandleWarningAir(c tele.Context) error {
+	city := c.Data()
+	key := reportKey("air", city)
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
+	}
+	report, err := h.airSvc.GetAirQualityReport(city)
+	if err != nil {
+		logger.Error("Failed to get air quality for warning", zap.String("city", city), zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 获取空气质量失败，请稍后再试"})
+	}
+	_ = c.Respond()
+	h.rememberReport(c, key, report)
+	return c.Send(report, refreshMarkup(btnRefreshAir, city))
+}
+
+// HandleWarningWeather replies to a warning notification with today's weather of its city
+func (h *Handlers) HandleWarningWeather(c tele.Context) error {
+	city := c.Data()
+	key := reportKey("weather", city, "")
+	if ok, err := h.spendAPICall(c, key); !ok {
+		return err
+	}
+	report, err := h.weatherSvc.GetFullWeatherReport(city, "", h.airSvc, h.warningSvc)
+	if err != nil {
+		logger.Error("Failed to get weather for warning", zap.String("city", city), zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 获取天气失败，请稍后再试"})
+	}
+	_ = c.Respond()
+	h.rememberReport(c, key, report)
+	return c.Send(report, refreshMarkup(btnRefreshWeather, city))
+}
+
+// HandleWarningMute turns off warning pushes for the subscription a warning notification was sent for
+func (h *Handlers) HandleWarningMute(c tele.Context) error {
+	id, err := strconv.ParseUint(c.Data(), 10, 64)
+	if err != nil {
+		return c.Respond()
+	}
+	sub, err := h.warningSvc.DisableWarnings(userFrom(c).ID, uint(id))
+	if err != nil {
+		logger.Error("Failed to disable warnings", zap.Uint64("subscription_id", id), zap.Error(err))
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+	if sub == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 订阅不存在或已取消"})
+	}
+
+	// Keep the weather buttons but drop the mute button
+	if _, err := c.Bot().EditReplyMarkup(c.Message(), warningQueryMarkup(sub.City)); err != nil {
+		logger.Warn("Failed to update warning buttons", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+	}
+	return c.Respond(&tele.CallbackResponse{
+		Text:      fmt.Sprintf("🔕 已关闭 %s 的预警推送，可使用 /warning_toggle 重新开启", sub.City),
+		ShowAlert: true,
+	})
+}
+
+// warningQueryMarkup builds the air quality and weather buttons of a muted warning notification,
+// or an empty keyboard when the city does not fit in the callback data
+func warningQueryMarkup(city string) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	var rows []tele.Row
+	if len(city) <= maxRefreshCityBytes {
+		rows = append(rows, markup.Row(
+			markup.Data("🌫️ 查看空气质量", btnWarningAir.Unique, city),
+			markup.Data("🌤️ 今日天气", btnWarningWeather.Unique, city),
+		))
+	}
+	markup.Inline(rows...)
+	return markup
+}
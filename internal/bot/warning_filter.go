@@ -0,0 +1,210 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// warningFilterPageSize is the number of warning types per page of the settings list
+	warningFilterPageSize = 15
+	// warningFilterPerRow is the number of warning type buttons per keyboard row
+	warningFilterPerRow = 3
+)
+
+// Callback button endpoints of the warning type settings list
+var (
+	btnWarningFilterPage   = &tele.Btn{Unique: "warn_filter"}     // Data: subscription ID|page
+	btnWarningFilterToggle = &tele.Btn{Unique: "warn_filter_tgl"} // Data: subscription ID|type code|page
+)
+
+// registerWarningFilterHandlers registers the button handlers of the warning type settings list
+func (h *Handlers) registerWarningFilterHandlers(bot *tele.Bot) {
+	bot.Handle(btnWarningFilterPage, h.HandleWarningFilterPage)
+	bot.Handle(btnWarningFilterToggle, h.HandleWarningFilterToggle)
+}
+
+// HandleWarningFilter handles /warning_filter [城市]: shows the warning types of a subscription
+// as buttons that mute or unmute pushes of each type
+func (h *Handlers) HandleWarningFilter(c tele.Context) error {
+	user := userFrom(c)
+	subs, err := h.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		logger.Error("Failed to find subscriptions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(subs) == 0 {
+		return c.Send("您还没有订阅任何城市，请先使用 /subscribe 命令订阅")
+	}
+
+	var target *model.Subscription
+	if args := c.Args(); len(args) > 0 {
+		for i := range subs {
+			if subs[i].MatchesName(args[0]) {
+				target = &subs[i]
+				break
+			}
+		}
+		if target == nil {
+			return c.Send(fmt.Sprintf("❌ 您没有订阅 %s\n您的订阅：%s", args[0], h.formatCityList(subs)))
+		}
+	} else if len(subs) == 1 {
+		target = &subs[0]
+	}
+
+	if target == nil {
+		markup := &tele.ReplyMarkup{}
+		var rows []tele.Row
+		for _, sub := range subs {
+			rows = append(rows, markup.Row(markup.Data("📍 "+sub.City, btnWarningFilterPage.Unique, fmt.Sprintf("%d|0", sub.ID))))
+		}
+		markup.Inline(rows...)
+		return c.Send("⚙️ 请选择要设置预警类型的订阅", markup)
+	}
+
+	text, markup, err := h.warningFilterPage(*target, 0)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	return c.Send(text, markup)
+}
+
+// HandleWarningFilterPage shows a page of a subscription's warning type settings in place
+func (h *Handlers) HandleWarningFilterPage(c tele.Context) error {
+	parts := strings.Split(c.Data(), "|")
+	if len(parts) != 2 {
+		return c.Respond()
+	}
+	sub := h.ownSubscription(c, parts[0])
+	if sub == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 订阅不存在或已取消"})
+	}
+	page, _ := strconv.Atoi(parts[1])
+
+	text, markup, err := h.warningFilterPage(*sub, page)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+	_ = c.Respond()
+	return c.Edit(text, markup)
+}
+
+// HandleWarningFilterToggle mutes or unmutes a warning type and refreshes the settings list
+func (h *Handlers) HandleWarningFilterToggle(c tele.Context) error {
+	parts := strings.Split(c.Data(), "|")
+	if len(parts) != 3 {
+		return c.Respond()
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return c.Respond()
+	}
+	page, _ := strconv.Atoi(parts[2])
+
+	sub, muted, err := h.warningSvc.ToggleMute(userFrom(c).ID, uint(id), parts[1])
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+	if sub == nil {
+		return c.Respond(&tele.CallbackResponse{Text: "❌ 订阅不存在或已取消"})
+	}
+
+	text, markup, err := h.warningFilterPage(*sub, page)
+	if err != nil {
+		return c.Respond(&tele.CallbackResponse{Text: "抱歉,系统出现错误,请稍后再试。"})
+	}
+	if err := c.Edit(text, markup); err != nil {
+		logger.Warn("Failed to update warning filter list", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+	}
+	name := h.warningSvc.WarningTypeName(parts[1])
+	if muted {
+		return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("🔕 已屏蔽 %s 的%s预警", sub.City, name)})
+	}
+	return c.Respond(&tele.CallbackResponse{Text: fmt.Sprintf("🔔 已恢复 %s 的%s预警", sub.City, name)})
+}
+
+// ownSubscription returns the sender's subscription with the given ID, or nil
+func (h *Handlers) ownSubscription(c tele.Context, idText string) *model.Subscription {
+	id, err := strconv.ParseUint(idText, 10, 64)
+	if err != nil {
+		return nil
+	}
+	sub, err := h.subRepo.FindByID(uint(id))
+	if err != nil || sub == nil || sub.UserID != userFrom(c).ID {
+		return nil
+	}
+	return sub
+}
+
+// warningFilterPage renders a page of the warning type settings of a subscription
+func (h *Handlers) warningFilterPage(sub model.Subscription, page int) (string, *tele.ReplyMarkup, error) {
+	muted, err := h.warningSvc.MutedTypes(sub.ID)
+	if err != nil {
+		logger.Error("Failed to load muted warning types", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+		return "", nil, err
+	}
+
+	types := h.warningSvc.WarningTypes()
+	pages := (len(types) + warningFilterPageSize - 1) / warningFilterPageSize
+	if page < 0 || page >= pages {
+		page = 0
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("⚙️ %s 预警类型推送设置\n\n", sub.City))
+	b.WriteString("点击类型切换推送：🔔 推送 / 🔕 已屏蔽\n")
+	var names []string
+	for _, t := range types {
+		if muted[t.Code] {
+			names = append(names, t.Name)
+		}
+	}
+	if len(names) > 0 {
+		b.WriteString(fmt.Sprintf("\n已屏蔽：%s\n", strings.Join(names, "、")))
+	}
+	if !sub.EnableWarning {
+		b.WriteString("\n⚠️ 该订阅的预警推送已关闭，使用 /warning_toggle 开启后设置才会生效\n")
+	}
+	b.WriteString(fmt.Sprintf("\n第 %d/%d 页", page+1, pages))
+
+	markup := &tele.ReplyMarkup{}
+	var rows []tele.Row
+	var row []tele.Btn
+	end := (page + 1) * warningFilterPageSize
+	if end > len(types) {
+		end = len(types)
+	}
+	for _, t := range types[page*warningFilterPageSize : end] {
+		icon := "🔔"
+		if muted[t.Code] {
+			icon = "🔕"
+		}
+		row = append(row, markup.Data(icon+" "+t.Name, btnWarningFilterToggle.Unique, fmt.Sprintf("%d|%s|%d", sub.ID, t.Code, page)))
+		if len(row) == warningFilterPerRow {
+			rows = append(rows, markup.Row(row...))
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, markup.Row(row...))
+	}
+
+	var nav []tele.Btn
+	if page > 0 {
+		nav = append(nav, markup.Data("◀️ 上一页", btnWarningFilterPage.Unique, fmt.Sprintf("%d|%d", sub.ID, page-1)))
+	}
+	if page < pages-1 {
+		nav = append(nav, markup.Data("下一页 ▶️", btnWarningFilterPage.Unique, fmt.Sprintf("%d|%d", sub.ID, page+1)))
+	}
+	if len(nav) > 0 {
+		rows = append(rows, markup.Row(nav...))
+	}
+	markup.Inline(rows...)
+	return b.String(), markup, nil
+}
@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// HandleWebhook handles the /webhook command
+// Usage: /webhook | /webhook add <url> [events] | /webhook delete <id>
+func (h *Handlers) HandleWebhook(c tele.Context) error {
+	args := c.Args()
+
+	if h.webhookSvc == nil {
+		return c.Send("❌ Webhook 功能未开启，请联系管理员")
+	}
+
+	user := userFrom(c)
+
+	if len(args) == 0 {
+		return h.listWebhooks(c, user.ID)
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 2 {
+			return c.Send("❌ 用法: /webhook add <URL> [reminder,warning]")
+		}
+		events := ""
+		if len(args) > 2 {
+			events = args[2]
+		}
+		return h.addWebhook(c, user.ID, args[1], events)
+	case "delete", "del":
+		if len(args) < 2 {
+			return c.Send("❌ 用法: /webhook delete <编号>")
+		}
+		id, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return c.Send("❌ 编号格式错误")
+		}
+		if err := h.webhookRepo.DeleteByUser(uint(id), user.ID); err != nil {
+			return c.Send("❌ 未找到该 Webhook")
+		}
+		return c.Send(fmt.Sprintf("✅ Webhook #%d 已删除", id))
+	default:
+		return c.Send("❌ 未知操作\n用法: /webhook [add <URL> [事件] | delete <编号>]")
+	}
+}
+
+// listWebhooks lists the user's webhooks
+func (h *Handlers) listWebhooks(c tele.Context, userID uint) error {
+	hooks, err := h.webhookRepo.FindByUserID(userID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if len(hooks) == 0 {
+		return c.Send("🔗 暂无 Webhook\n\n💡 使用 /webhook add <URL> [reminder,warning] 注册")
+	}
+
+	var msg strings.Builder
+	msg.WriteString("🔗 我的 Webhook\n\n")
+	for _, hook := range hooks {
+		msg.WriteString(fmt.Sprintf("#%d %s\n   事件：%s\n", hook.ID, hook.URL, hook.Events))
+	}
+	return c.Send(msg.String(), &tele.SendOptions{DisableWebPagePreview: true})
+}
+
+// addWebhook registers a new webhook and returns its signing secret to the user
+func (h *Handlers) addWebhook(c tele.Context, userID uint, rawURL string, rawEvents string) error {
+	if err := service.ValidateWebhookURL(rawURL); err != nil {
+		return c.Send("❌ URL 无效，请使用 http:// 或 https:// 开头的完整地址")
+	}
+	if len(rawURL) > 512 {
+		return c.Send("❌ URL 过长")
+	}
+
+	events, ok := parseWebhookEvents(rawEvents)
+	if !ok {
+		return c.Send("❌ 事件类型无效，可选：reminder、warning（用逗号分隔）")
+	}
+
+	count, err := h.webhookRepo.CountByUser(userID)
+	if err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	if count >= int64(h.maxWebhooks) {
+		return c.Send(fmt.Sprintf("❌ Webhook 数量已达上限（%d个）", h.maxWebhooks))
+	}
+
+	secret, err := service.GenerateWebhookSecret()
+	if err != nil {
+		logger.Error("Failed to generate webhook secret", zap.Error(err))
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	hook := &model.Webhook{
+		UserID: userID,
+		URL:    rawURL,
+		Secret: secret,
+		Events: events,
+		Active: true,
+	}
+	if err := h.webhookRepo.Create(hook); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+
+	return c.Send(fmt.Sprintf(
+		"✅ Webhook #%d 已注册\n事件：%s\n\n🔑 签名密钥（仅显示一次）：\n%s\n\n"+
+			"请求头 %s 为 sha256=HMAC_SHA256(密钥, \"<%s>.<请求体>\")",
+		hook.ID, events, secret, service.WebhookSignatureHeader, service.WebhookTimestampHeader,
+	), &tele.SendOptions{DisableWebPagePreview: true})
+}
+
+// parseWebhookEvents normalizes a comma-separated event list; empty means all events
+func parseWebhookEvents(raw string) (string, bool) {
+	if raw == "" {
+		return model.WebhookEventReminder + "," + model.WebhookEventWarning, true
+	}
+
+	var events []string
+	seen := make(map[string]bool)
+	for _, e := range strings.Split(raw, ",") {
+		e = strings.TrimSpace(strings.ToLower(e))
+		if e != model.WebhookEventReminder && e != model.WebhookEventWarning {
+			return "", false
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+	return strings.Join(events, ","), true
+}
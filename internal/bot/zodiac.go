@@ -0,0 +1,76 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/horoscope"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// HandleZodiac handles the /zodiac [<星座>|<生日>|off] command, choosing the horoscope of the
+// user's daily reminders
+func (h *Handlers) HandleZodiac(c tele.Context) error {
+	if h.horoscopeSvc == nil {
+		return c.Send("❌ 星座运势功能未开启，请联系管理员")
+	}
+	user := userFrom(c)
+
+	arg := strings.TrimSpace(c.Message().Payload)
+	if arg == "" {
+		return c.Send(zodiacStatus(horoscope.Sign(user.Zodiac)))
+	}
+
+	if strings.EqualFold(arg, "off") {
+		if err := h.userRepo.SetZodiac(user.ID, ""); err != nil {
+			return c.Send("抱歉,系统出现错误,请稍后再试。")
+		}
+		logger.Info("Zodiac sign cleared", zap.Uint("user_id", user.ID))
+		return c.Send("🔕 已关闭星座运势\n发送 /zodiac <星座> 可重新开启")
+	}
+
+	sign, err := horoscope.Parse(arg)
+	if err != nil {
+		return c.Send("❌ 无法识别的星座\n示例: /zodiac 白羊座 或 /zodiac 3-25（按生日换算）")
+	}
+	if err := h.userRepo.SetZodiac(user.ID, string(sign)); err != nil {
+		return c.Send("抱歉,系统出现错误,请稍后再试。")
+	}
+	logger.Info("Zodiac sign updated", zap.Uint("user_id", user.ID), zap.String("sign", string(sign)))
+
+	reply := fmt.Sprintf("✅ 已设置为%s%s，每日提醒将在日历后附上星座运势", sign.Emoji(), sign.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	reading, err := h.horoscopeSvc.Reading(ctx, sign, time.Now().In(h.timezone).Format("2006-01-02"))
+	if err != nil {
+		logger.Warn("Failed to preview horoscope", zap.String("sign", string(sign)), zap.Error(err))
+		return c.Send(reply)
+	}
+	return c.Send(reply + "\n\n" + service.FormatReading(reading))
+}
+
+// zodiacStatus describes the user's zodiac setting and the command usage
+func zodiacStatus(sign horoscope.Sign) string {
+	status := "未开启"
+	if sign.Valid() {
+		status = sign.Emoji() + sign.Name()
+	}
+
+	names := make([]string, 0, 12)
+	for _, s := range horoscope.Signs() {
+		names = append(names, s.Name())
+	}
+	return fmt.Sprintf(`🔮 每日提醒星座运势：%s
+
+用法:
+/zodiac <星座> - 设置星座，如 /zodiac 白羊座
+/zodiac <生日> - 按生日换算星座，如 /zodiac 3-25
+/zodiac off - 关闭
+
+可选星座：%s`, status, strings.Join(names, "、"))
+}
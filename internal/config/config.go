@@ -8,31 +8,217 @@ import (
 
 // Config holds all application configuration
 type Config struct {
-	Telegram  TelegramConfig  `mapstructure:"telegram"`
-	QWeather  QWeatherConfig  `mapstructure:"qweather"`
-	OpenAI    OpenAIConfig    `mapstructure:"openai"`
-	Holiday   HolidayConfig   `mapstructure:"holiday"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	Scheduler SchedulerConfig `mapstructure:"scheduler"`
-	Logger    LoggerConfig    `mapstructure:"logger"`
+	Telegram     TelegramConfig     `mapstructure:"telegram"`
+	QWeather     QWeatherConfig     `mapstructure:"qweather"`
+	OpenAI       OpenAIConfig       `mapstructure:"openai"`
+	TTS          TTSConfig          `mapstructure:"tts"`
+	Image        ImageConfig        `mapstructure:"image"`
+	OCR          OCRConfig          `mapstructure:"ocr"`
+	News         NewsConfig         `mapstructure:"news"`
+	Rates        RatesConfig        `mapstructure:"rates"`
+	Horoscope    HoroscopeConfig    `mapstructure:"horoscope"`
+	ICS          ICSConfig          `mapstructure:"ics"`
+	Trivia       TriviaConfig       `mapstructure:"trivia"`
+	JieQi        JieQiConfig        `mapstructure:"jieqi"`
+	Observations ObservationsConfig `mapstructure:"observations"`
+	Tiers        TiersConfig        `mapstructure:"tiers"`
+	Payments     PaymentsConfig     `mapstructure:"payments"`
+	Health       HealthConfig       `mapstructure:"health"`
+	Interval     IntervalConfig     `mapstructure:"interval"`
+	Encryption   EncryptionConfig   `mapstructure:"encryption"`
+	Holiday      HolidayConfig      `mapstructure:"holiday"`
+	Database     DatabaseConfig     `mapstructure:"database"`
+	Scheduler    SchedulerConfig    `mapstructure:"scheduler"`
+	Logger       LoggerConfig       `mapstructure:"logger"`
+	Events       EventsConfig       `mapstructure:"events"`
+	Server       ServerConfig       `mapstructure:"server"`
+	Webhook      WebhookConfig      `mapstructure:"webhook"`
+	Notify       NotifyConfig       `mapstructure:"notify"`
+	MQTT         MQTTConfig         `mapstructure:"mqtt"`
 }
 
 // OpenAIConfig holds OpenAI-compatible API configuration
 type OpenAIConfig struct {
-	Enabled     bool    `mapstructure:"enabled"`     // Whether to enable AI generation
-	APIKey      string  `mapstructure:"api_key"`     // API key
-	BaseURL     string  `mapstructure:"base_url"`    // API base URL (supports OpenAI, DeepSeek, etc.)
-	Model       string  `mapstructure:"model"`       // Model name (e.g., gpt-4o-mini, deepseek-chat)
-	MaxTokens   int     `mapstructure:"max_tokens"`  // Maximum tokens to generate
-	Temperature float64 `mapstructure:"temperature"` // Generation temperature (0-2)
-	Timeout     int     `mapstructure:"timeout"`     // Request timeout in seconds
-	MaxRetries  int     `mapstructure:"max_retries"` // Maximum retry attempts
+	Enabled      bool    `mapstructure:"enabled"`          // Whether to enable AI generation
+	Provider     string  `mapstructure:"provider"`         // API flavour: openai (OpenAI-compatible, default), azure or local (Ollama, llama.cpp)
+	APIKey       string  `mapstructure:"api_key"`          // API key
+	BaseURL      string  `mapstructure:"base_url"`         // API base URL (supports OpenAI, DeepSeek, etc.; azure: the resource endpoint)
+	APIVersion   string  `mapstructure:"api_version"`      // Azure OpenAI API version (default: 2024-10-21)
+	Model        string  `mapstructure:"model"`            // Model name (e.g., gpt-4o-mini, deepseek-chat; azure: the deployment name)
+	MaxTokens    int     `mapstructure:"max_tokens"`       // Maximum tokens to generate
+	Temperature  float64 `mapstructure:"temperature"`      // Generation temperature (0-2)
+	Timeout      int     `mapstructure:"timeout"`          // Request timeout in seconds (local: default 120)
+	MaxRetries   int     `mapstructure:"max_retries"`      // Maximum retry attempts
+	TodoPlanning bool    `mapstructure:"todo_planning"`    // Order and annotate todos by the hourly forecast (one extra request per reminder)
+	OptIn        bool    `mapstructure:"opt_in"`           // Write AI reminders only for subscriptions that turned AI on with /ai
+	LiteModel    string  `mapstructure:"lite_model"`       // Smaller model of the concise AI mode (default: model)
+	CacheMinutes int     `mapstructure:"cache_minutes"`    // How long a reminder is reused for an identical prompt (default: 10, -1 = off)
+	RegenQuota   int     `mapstructure:"regenerate_quota"` // "换一条" regenerations of AI reminders per user and day (default: 3, -1 = no button)
+	ProbeSeconds int     `mapstructure:"probe_interval"`   // local: seconds between health probes of the server (default: 30)
+	ContextSize  int     `mapstructure:"context_window"`   // Context window of the models in tokens (default: by model name, 8192 if unknown)
+	PromptBudget int     `mapstructure:"prompt_budget"`    // Most tokens of a reminder prompt; less important sections are left out beyond it (0 = context window)
+}
+
+// TTSConfig holds text-to-speech configuration for voice reminders
+type TTSConfig struct {
+	Enabled  bool     `mapstructure:"enabled"`  // Whether users can receive reminders as voice messages
+	Provider string   `mapstructure:"provider"` // "openai" (OpenAI-compatible /audio/speech) or "command"
+	APIKey   string   `mapstructure:"api_key"`  // API key (default: openai.api_key)
+	BaseURL  string   `mapstructure:"base_url"` // API base URL (default: openai.base_url)
+	Model    string   `mapstructure:"model"`    // Speech model (default: tts-1)
+	Voice    string   `mapstructure:"voice"`    // Voice name (default: alloy)
+	Command  []string `mapstructure:"command"`  // Program and arguments for the command provider ({text}, {output} placeholders)
+	MIME     string   `mapstructure:"mime"`     // Audio type written by the command (default: audio/mpeg)
+	Timeout  int      `mapstructure:"timeout"`  // Synthesis timeout in seconds (default: 60)
+}
+
+// ImageConfig holds configuration of the weather-matched image attached to daily reminders
+type ImageConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`   // Whether to send an image ahead of each daily reminder
+	Source   string `mapstructure:"source"`    // "builtin" (generated), "dir" or "url"
+	Dir      string `mapstructure:"dir"`       // Directory with <condition>.jpg files or <condition>/ subdirectories (dir source)
+	URL      string `mapstructure:"url"`       // URL template, {condition} is replaced with the condition (url source)
+	CacheTTL int    `mapstructure:"cache_ttl"` // Seconds each condition's image is reused (default: 86400)
+	Timeout  int    `mapstructure:"timeout"`   // Download timeout in seconds (default: 15)
+}
+
+// NewsConfig holds configuration of the news headlines section of daily reminders
+type NewsConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`       // Whether to add news headlines to daily reminders
+	FeedURL      string `mapstructure:"feed_url"`      // RSS/Atom feed of users without their own ("" = none)
+	UserFeeds    bool   `mapstructure:"user_feeds"`    // Allow users to set their own feed with /news
+	MaxItems     int    `mapstructure:"max_items"`     // Headlines per reminder (default: 3)
+	CacheMinutes int    `mapstructure:"cache_minutes"` // How long a feed's headlines are reused (default: 60)
+	Timeout      int    `mapstructure:"timeout"`       // Feed request timeout in seconds (default: 10)
+}
+
+// RatesConfig holds configuration of the exchange rate and metal price section of daily reminders
+type RatesConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`       // Whether to add exchange rates and metal prices to daily reminders
+	Pairs        []string `mapstructure:"pairs"`         // Pairs of users without their own, e.g. USD/CNY, XAU/CNY (default: USD/CNY)
+	MaxPairs     int      `mapstructure:"max_pairs"`     // Maximum pairs a user may choose with /rates (default: 5)
+	FiatURL      string   `mapstructure:"fiat_url"`      // Frankfurter-compatible currency API (default: https://api.frankfurter.app)
+	MetalURL     string   `mapstructure:"metal_url"`     // gold-api.com-compatible metal price API (default: https://api.gold-api.com)
+	CacheMinutes int      `mapstructure:"cache_minutes"` // How long quotes are reused (default: 60)
+	Timeout      int      `mapstructure:"timeout"`       // Request timeout in seconds (default: 10)
+}
+
+// HoroscopeConfig holds configuration of the opt-in horoscope section of daily reminders
+type HoroscopeConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // Whether users may add a horoscope to daily reminders with /zodiac
+	Source  string `mapstructure:"source"`  // "builtin" (generated locally) or "ai" (summary written by the AI with a per-day seed) (default: builtin)
+}
+
+// ICSConfig holds configuration of the users' iCalendar feeds listed in daily reminders
+type ICSConfig struct {
+	Enabled    bool `mapstructure:"enabled"`      // Whether users may add iCalendar feeds with /ics
+	MaxPerUser int  `mapstructure:"max_per_user"` // Feeds per user (default: 3)
+	Timeout    int  `mapstructure:"timeout"`      // Feed request timeout in seconds (default: 15)
+}
+
+// TriviaConfig holds configuration of the "今日冷知识" line of daily reminders
+type TriviaConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // Whether daily reminders end with a weather or solar term trivia
+	Source  string `mapstructure:"source"`  // "builtin" (curated text) or "ai" (curated trivia rephrased by the AI) (default: builtin)
+}
+
+// JieQiConfig holds configuration of the solar term care tips of daily reminders and /jieqi
+type JieQiConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // Whether reminders on solar term days carry a care tip, and /jieqi is available
+	Source  string `mapstructure:"source"`  // "builtin" (curated text) or "ai" (curated tip rephrased by the AI) (default: builtin)
+}
+
+// ObservationsConfig holds configuration of the users' weather observations ("实况打卡") and the crowd
+// observations shown in daily reminders
+type ObservationsConfig struct {
+	Enabled       bool    `mapstructure:"enabled"`        // Whether users may submit observations with /observe
+	MinReporters  int     `mapstructure:"min_reporters"`  // Distinct users reporting a phenomenon before reminders show it (default: 3)
+	WindowMinutes int     `mapstructure:"window_minutes"` // How long an observation counts, in minutes (default: 60)
+	MaxPerDay     int     `mapstructure:"max_per_day"`    // Submissions per user and day (default: 5)
+	Moderators    []int64 `mapstructure:"moderators"`     // Telegram user IDs given the support role, which may use /obsmod
+}
+
+// TiersConfig holds the limits of the free and premium service tiers
+type TiersConfig struct {
+	Free    TierLimitsConfig `mapstructure:"free"`    // Limits of all users by default
+	Premium TierLimitsConfig `mapstructure:"premium"` // Limits of users upgraded by an admin
+}
+
+// TierLimitsConfig holds the limits of a service tier (0 = default, -1 = none allowed)
+type TierLimitsConfig struct {
+	Subscriptions int `mapstructure:"subscriptions"` // Active subscriptions (default: 5 free, 20 premium)
+	Regenerations int `mapstructure:"regenerations"` // "换一条" regenerations of AI reminders a day (default: openai.regenerate_quota free, 10 premium)
+	IndexWatches  int `mapstructure:"index_watches"` // Life index alerts set with /index (default: 10 free, 50 premium)
+	APICalls      int `mapstructure:"api_calls"`     // On-demand weather queries and AI requests a day (default: 100 free, 1000 premium)
+}
+
+// PaymentsConfig holds configuration of buying the premium tier with Telegram payments (/premium)
+type PaymentsConfig struct {
+	Enabled       bool                `mapstructure:"enabled"`
+	ProviderToken string              `mapstructure:"provider_token"` // Payment provider token from @BotFather ("" = Telegram Stars)
+	Currency      string              `mapstructure:"currency"`       // ISO 4217 code of the provider's currency (required with a provider token; Telegram Stars use XTR)
+	Plans         []PaymentPlanConfig `mapstructure:"plans"`          // Premium plans on sale (default: 30 days for 100, 365 days for 900)
+}
+
+// PaymentPlanConfig holds a premium plan on sale
+type PaymentPlanConfig struct {
+	Days  int `mapstructure:"days"`  // How long the premium tier lasts
+	Price int `mapstructure:"price"` // Price in the smallest units of the currency (Stars for XTR, cents for USD)
+}
+
+// HealthConfig holds configuration of private recurring health reminders (menstrual cycle, medication)
+type HealthConfig struct {
+	Enabled    bool `mapstructure:"enabled"`      // Whether users may set private health reminders with /cycle (requires encryption.key)
+	MaxPerUser int  `mapstructure:"max_per_user"` // Maximum health reminders per user (default: 5)
+}
+
+// IntervalConfig holds configuration of water and stretch interval reminders
+type IntervalConfig struct {
+	Enabled bool `mapstructure:"enabled"` // Whether users may set interval reminders with /interval
+}
+
+// EncryptionConfig holds the key of field-level encryption at rest
+type EncryptionConfig struct {
+	Key   string `mapstructure:"key"`   // 32-byte AES-256 key as base64 or hex, e.g. from `openssl rand -base64 32`
+	Todos bool   `mapstructure:"todos"` // Encrypt todo contents at rest (requires key; existing rows are converted at startup)
+}
+
+// OCRConfig holds configuration for turning photos of schedules and notices into todos
+type OCRConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`    // Whether photos sent to the bot are scanned for todos
+	APIKey    string `mapstructure:"api_key"`    // API key (default: openai.api_key)
+	BaseURL   string `mapstructure:"base_url"`   // API base URL (default: openai.base_url)
+	Model     string `mapstructure:"model"`      // Vision-capable model (default: openai.model)
+	MaxTokens int    `mapstructure:"max_tokens"` // Maximum tokens to generate (default: 1000)
+	MaxItems  int    `mapstructure:"max_items"`  // Maximum todos proposed per photo (default: 10)
+	Timeout   int    `mapstructure:"timeout"`    // Request timeout in seconds (default: 60)
 }
 
 // TelegramConfig holds Telegram bot configuration
 type TelegramConfig struct {
-	Token       string `mapstructure:"token"`
-	APIEndpoint string `mapstructure:"api_endpoint"`
+	Token         string           `mapstructure:"token"`
+	APIEndpoint   string           `mapstructure:"api_endpoint"`
+	APIEndpoints  []string         `mapstructure:"api_endpoints"`  // Bot API endpoints in order of preference, with failover (overrides api_endpoint)
+	ProbeInterval int              `mapstructure:"probe_interval"` // Seconds between health probes of api_endpoints (default: 30)
+	Bots          []BotConfig      `mapstructure:"bots"`           // Additional bots served by the same process, each with its own users
+	Owners        []int64          `mapstructure:"owners"`         // Telegram user IDs with the owner role (all staff commands, granting admins)
+	Admins        []int64          `mapstructure:"admins"`         // Telegram user IDs with the admin role (/tier, /audit, /grant support)
+	LocalFiles    LocalFilesConfig `mapstructure:"local_files"`
+}
+
+// LocalFilesConfig holds the directory shared with a local Bot API server (telegram-bot-api --local),
+// through which generated files are sent by path instead of uploaded
+type LocalFilesConfig struct {
+	Dir       string `mapstructure:"dir"`        // Shared directory as seen by the bot ("" = upload files)
+	ServerDir string `mapstructure:"server_dir"` // Shared directory as seen by the server, e.g. in its container (default: dir)
+}
+
+// BotConfig holds an additional Telegram bot
+type BotConfig struct {
+	Name         string   `mapstructure:"name"`          // Unique name stored with the bot's users (lowercase letters, digits, "_" and "-")
+	Token        string   `mapstructure:"token"`         // Bot token from @BotFather
+	APIEndpoint  string   `mapstructure:"api_endpoint"`  // Custom Telegram Bot API endpoint (default: telegram.api_endpoint)
+	APIEndpoints []string `mapstructure:"api_endpoints"` // Bot API endpoints with failover (default: telegram.api_endpoints)
 }
 
 // QWeatherConfig holds QWeather API configuration
@@ -43,6 +229,8 @@ type QWeatherConfig struct {
 	KeyID          string `mapstructure:"key_id"`           // Credential ID from QWeather console (for jwt mode)
 	ProjectID      string `mapstructure:"project_id"`       // Project ID from QWeather console (for jwt mode)
 	BaseURL        string `mapstructure:"base_url"`
+	Timeout        int    `mapstructure:"timeout"`       // Per-request timeout in seconds (default: 10)
+	SnapshotDays   int    `mapstructure:"snapshot_days"` // Days raw responses are kept for comparison and debugging (0 = disabled)
 }
 
 // DatabaseConfig holds database configuration
@@ -59,19 +247,176 @@ type DatabaseConfig struct {
 
 // SchedulerConfig holds scheduler configuration
 type SchedulerConfig struct {
-	Timezone string `mapstructure:"timezone"`
+	Timezone         string `mapstructure:"timezone"`
+	HeartbeatTimeout int    `mapstructure:"heartbeat_timeout"` // Seconds without a scheduler tick after which systemd watchdog keep-alives stop (default 180)
+	Spread           string `mapstructure:"spread"`            // Spreading of reminders due in the same minute: second, half_minute or off (default: second)
 }
 
 // LoggerConfig holds logger configuration
 type LoggerConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
+	Level    string            `mapstructure:"level"`
+	Format   string            `mapstructure:"format"`
+	Levels   map[string]string `mapstructure:"levels"`   // Level per component: logger name, source file name or package directory (e.g. qweather: warn, scheduler: info)
+	Sampling LogSamplingConfig `mapstructure:"sampling"` // Sampling of repeated debug and info entries
+	Privacy  LogPrivacyConfig  `mapstructure:"privacy"`  // Masking of personal data in logs
+}
+
+// LogPrivacyConfig holds the masking of personal data in logs; sensitive URL parameters are
+// masked regardless
+type LogPrivacyConfig struct {
+	Enabled bool     `mapstructure:"enabled"` // Mask chat IDs and text written by users (todo contents, labels, usernames, command arguments)
+	Fields  []string `mapstructure:"fields"`  // More field keys whose string values are masked
+}
+
+// LogSamplingConfig limits how many debug and info entries with the same message are written each
+// second; warnings and errors are never sampled
+type LogSamplingConfig struct {
+	Initial    int `mapstructure:"initial"`    // Entries with the same level and message written each second before sampling (0 = no sampling)
+	Thereafter int `mapstructure:"thereafter"` // After that, every Nth of them is written (default: 100)
+}
+
+// EventsConfig holds the business event log: structured records of reminders sent, warnings
+// pushed and subscriptions created for analytics, separate from the debug logs
+type EventsConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // Whether to record business events
+	Sink    string `mapstructure:"sink"`    // file (JSON lines, default) or db (event_logs table)
+	Path    string `mapstructure:"path"`    // File of the file sink (default: ./data/events.jsonl)
 }
 
 // HolidayConfig holds holiday API configuration
 type HolidayConfig struct {
-	APIURL   string `mapstructure:"api_url"`   // Holiday API base URL
-	CacheTTL int    `mapstructure:"cache_ttl"` // Cache TTL in seconds
+	APIURL     string `mapstructure:"api_url"`     // Holiday API base URL
+	DatasetURL string `mapstructure:"dataset_url"` // Fallback static dataset URL with a {year} placeholder
+	CacheTTL   int    `mapstructure:"cache_ttl"`   // Cache TTL in seconds
+}
+
+// ServerConfig holds the built-in HTTP server configuration
+type ServerConfig struct {
+	Enabled    bool            `mapstructure:"enabled"`     // Whether to start the HTTP server (health endpoint)
+	ListenAddr string          `mapstructure:"listen_addr"` // Listen address (default: 127.0.0.1:8080)
+	Debug      DebugConfig     `mapstructure:"debug"`       // pprof and runtime debug endpoint
+	Admin      AdminConfig     `mapstructure:"admin"`       // REST admin API (served on the main listener)
+	Feed       FeedConfig      `mapstructure:"feed"`        // Per-city RSS feeds (served on the main listener)
+	Dashboard  DashboardConfig `mapstructure:"dashboard"`   // Web dashboard of users' subscriptions and todos (served on the main listener)
+	MiniApp    MiniAppConfig   `mapstructure:"miniapp"`     // Telegram Mini App for todos and settings (served on the main listener)
+	GraphQL    GraphQLConfig   `mapstructure:"graphql"`     // User GraphQL API on dashboard sessions (served on the main listener)
+}
+
+// GraphQLConfig holds user GraphQL API configuration
+type GraphQLConfig struct {
+	Enabled bool `mapstructure:"enabled"` // Whether to serve /graphql (requires server.dashboard.enabled for logins)
+}
+
+// MiniAppConfig holds Telegram Mini App configuration
+type MiniAppConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`    // Whether to serve /miniapp and the /app command opening it
+	PublicURL string `mapstructure:"public_url"` // Public HTTPS address of /miniapp, as required by Telegram (e.g., https://bot.example.com/miniapp)
+}
+
+// DashboardConfig holds web dashboard configuration
+type DashboardConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`       // Whether to serve /dashboard and the /dashboard command issuing login codes
+	PublicURL    string `mapstructure:"public_url"`    // Public address of /dashboard shown by the command (e.g., https://bot.example.com/dashboard)
+	SessionHours int    `mapstructure:"session_hours"` // How long a dashboard login lasts (default: 168)
+}
+
+// FeedConfig holds RSS feed configuration
+type FeedConfig struct {
+	Enabled bool `mapstructure:"enabled"` // Whether to expose /feeds/{city} RSS feeds of daily digests
+}
+
+// AdminConfig holds REST admin API configuration
+type AdminConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // Whether to expose /api/v1/ admin endpoints
+	Token   string `mapstructure:"token"`   // Token expected in the X-Admin-Token header (or as a Bearer token)
+}
+
+// DebugConfig holds pprof/runtime debug endpoint configuration
+type DebugConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`      // Whether to expose /debug/pprof and /debug/runtime
+	ListenAddr  string `mapstructure:"listen_addr"`  // Separate listen address (default: 127.0.0.1:6060)
+	AllowPublic bool   `mapstructure:"allow_public"` // Allow binding to a non-loopback address
+}
+
+// WebhookConfig holds outbound webhook configuration
+type WebhookConfig struct {
+	Enabled             bool                    `mapstructure:"enabled"`               // Whether to publish reminder/warning events to webhooks
+	Timeout             int                     `mapstructure:"timeout"`               // Request timeout in seconds (default: 10)
+	MaxAttempts         int                     `mapstructure:"max_attempts"`          // Delivery attempts before giving up (default: 6)
+	UserWebhooks        bool                    `mapstructure:"user_webhooks"`         // Allow users to register webhooks via /webhook
+	MaxPerUser          int                     `mapstructure:"max_per_user"`          // Maximum webhooks per user (default: 3)
+	AllowPrivateTargets bool                    `mapstructure:"allow_private_targets"` // Allow user webhooks to target loopback/private addresses
+	Endpoints           []WebhookEndpointConfig `mapstructure:"endpoints"`             // Operator-configured endpoints receiving all users' events
+}
+
+// WebhookEndpointConfig holds an operator-configured webhook endpoint
+type WebhookEndpointConfig struct {
+	URL    string   `mapstructure:"url"`    // Target URL
+	Secret string   `mapstructure:"secret"` // HMAC-SHA256 signing secret (optional)
+	Events []string `mapstructure:"events"` // Subscribed events: reminder, warning (empty = all)
+}
+
+// NotifyConfig holds additional notification channel configuration
+type NotifyConfig struct {
+	Timeout     int               `mapstructure:"timeout"`      // Per-channel send timeout in seconds (default: 15)
+	MaxChannels int               `mapstructure:"max_channels"` // Maximum additional channels per subscription (default: 3)
+	Concurrency int               `mapstructure:"concurrency"`  // Deliveries sent at once, warnings first (default: 8)
+	Email       EmailConfig       `mapstructure:"email"`
+	Ntfy        NtfyConfig        `mapstructure:"ntfy"`
+	Bark        BarkConfig        `mapstructure:"bark"`
+	WeCom       RobotConfig       `mapstructure:"wecom"`
+	DingTalk    RobotConfig       `mapstructure:"dingtalk"`
+	Broadcasts  []BroadcastConfig `mapstructure:"broadcasts"`
+}
+
+// EmailConfig holds SMTP configuration for the email channel
+type EmailConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Host     string `mapstructure:"host"`     // SMTP host
+	Port     int    `mapstructure:"port"`     // SMTP port (465 = implicit TLS, otherwise STARTTLS when available)
+	Username string `mapstructure:"username"` // SMTP username
+	Password string `mapstructure:"password"` // SMTP password
+	From     string `mapstructure:"from"`     // Sender address
+}
+
+// NtfyConfig holds ntfy channel configuration
+type NtfyConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Server  string `mapstructure:"server"` // ntfy server URL (default: https://ntfy.sh)
+	Token   string `mapstructure:"token"`  // Access token for protected servers (optional)
+}
+
+// BarkConfig holds Bark channel configuration
+type BarkConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Server  string `mapstructure:"server"` // Bark server URL (default: https://api.day.app)
+}
+
+// RobotConfig holds WeChat Work / DingTalk group robot configuration
+type RobotConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Server  string `mapstructure:"server"` // API server URL (default: official endpoint)
+}
+
+// BroadcastConfig holds an operator-configured target that receives city digests and warnings
+type BroadcastConfig struct {
+	Channel string   `mapstructure:"channel"` // Enabled channel type: wecom, dingtalk, email, ntfy, bark
+	Target  string   `mapstructure:"target"`  // Channel target (robot key, "<access_token>[:<secret>]", address, ...)
+	Events  []string `mapstructure:"events"`  // Subscribed events: reminder, warning (empty = all)
+	Cities  []string `mapstructure:"cities"`  // Cities to forward (empty = all)
+}
+
+// MQTTConfig holds MQTT publishing configuration for smart home automations
+type MQTTConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`      // Whether to publish weather, AQI and warnings to MQTT
+	Broker      string `mapstructure:"broker"`       // Broker URL (e.g., tcp://127.0.0.1:1883, ssl://broker:8883)
+	ClientID    string `mapstructure:"client_id"`    // Client ID (default: daily-reminder-bot)
+	Username    string `mapstructure:"username"`     // Broker username (optional)
+	Password    string `mapstructure:"password"`     // Broker password (optional)
+	TopicPrefix string `mapstructure:"topic_prefix"` // Topic prefix (default: reminderbot)
+	QoS         int    `mapstructure:"qos"`          // Publish QoS 0-2 (default: 0)
+	Interval    int    `mapstructure:"interval"`     // Weather/AQI snapshot interval in minutes (default: 30)
+	Timeout     int    `mapstructure:"timeout"`      // Connect/publish timeout in seconds (default: 10)
 }
 
 // Load reads configuration from file and environment variables
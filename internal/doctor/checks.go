@@ -0,0 +1,115 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/openai"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	tele "gopkg.in/telebot.v3"
+	"gorm.io/gorm"
+)
+
+// probeLocationID is the QWeather location queried by the QWeather check (Beijing)
+const probeLocationID = "101010100"
+
+// errRollback aborts the database probe transaction
+var errRollback = errors.New("rollback")
+
+// Telegram checks the bot token with getMe against the API endpoints ("" = api.telegram.org) in
+// order, passing when any of them answers so that a failover endpoint keeps the bot starting
+func Telegram(token string, apiEndpoints []string) Check {
+	return Check{Name: "Telegram", Required: true, Run: func(ctx context.Context) (string, error) {
+		if token == "" {
+			return "", fmt.Errorf("telegram.token is not set")
+		}
+		if len(apiEndpoints) == 0 {
+			apiEndpoints = []string{""}
+		}
+		var failures []string
+		for _, endpoint := range apiEndpoints {
+			timeout := 10 * time.Second
+			if deadline, ok := ctx.Deadline(); ok {
+				timeout = time.Until(deadline) / time.Duration(len(apiEndpoints)-len(failures))
+			}
+			// NewBot calls getMe unless offline
+			b, err := tele.NewBot(tele.Settings{Token: token, URL: endpoint, Client: &http.Client{Timeout: timeout}})
+			if err != nil {
+				if len(apiEndpoints) == 1 {
+					return "", fmt.Errorf("getMe failed: %w", err)
+				}
+				failures = append(failures, fmt.Sprintf("%s: %v", endpoint, err))
+				continue
+			}
+			detail := fmt.Sprintf("@%s (id %d)", b.Me.Username, b.Me.ID)
+			if len(failures) > 0 {
+				detail += fmt.Sprintf(" via %s; %d endpoint(s) down", endpoint, len(failures))
+			}
+			return detail, nil
+		}
+		return "", fmt.Errorf("getMe failed on all endpoints: %s", strings.Join(failures, "; "))
+	}}
+}
+
+// QWeather checks the QWeather credentials with a current weather request. It is optional: the bot
+// still runs, with weather sections degraded, while QWeather is down.
+func QWeather(client *qweather.Client) Check {
+	return Check{Name: "QWeather", Run: func(ctx context.Context) (string, error) {
+		weather, err := client.GetCurrentWeather(probeLocationID)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Beijing %s°C %s", weather.Temp, weather.Text), nil
+	}}
+}
+
+// OpenAI checks that the OpenAI-compatible API is reachable and accepts the API key
+func OpenAI(client *openai.Client) Check {
+	return Check{Name: "OpenAI", Run: func(ctx context.Context) (string, error) {
+		if err := client.Ping(ctx); err != nil {
+			return "", err
+		}
+		return "model " + client.Model(), nil
+	}}
+}
+
+// Database checks that the database accepts writes by inserting a row in a rolled back transaction
+func Database(db *gorm.DB) Check {
+	return Check{Name: "Database", Required: true, Run: func(ctx context.Context) (string, error) {
+		err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&model.ProcessedUpdate{UpdateID: -1, CreatedAt: time.Now()}).Error; err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			return "", fmt.Errorf("write failed: %w", err)
+		}
+		return db.Dialector.Name() + " writable", nil
+	}}
+}
+
+// Failed returns a check that reports an error found before it could run, e.g. a database that
+// could not be opened
+func Failed(name string, required bool, err error) Check {
+	return Check{Name: name, Required: required, Run: func(context.Context) (string, error) {
+		return "", err
+	}}
+}
+
+// Timezone checks that the timezone data for the scheduler timezone is available
+func Timezone(name string) Check {
+	return Check{Name: "Timezone", Required: true, Run: func(context.Context) (string, error) {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return "", fmt.Errorf("%w (install the tzdata package or build with -tags timetzdata)", err)
+		}
+		zone, offset := time.Now().In(loc).Zone()
+		return fmt.Sprintf("%s (%s, UTC%+d)", loc, zone, offset/3600), nil
+	}}
+}
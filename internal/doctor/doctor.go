@@ -0,0 +1,168 @@
+// Package doctor checks the bot's dependencies (Telegram, QWeather, OpenAI, database, timezone
+// data) and reports a pass/fail result per component. It backs the `doctor` command and the
+// self-check run at startup.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusPass Status = "PASS"
+	StatusFail Status = "FAIL"
+	StatusSkip Status = "SKIP"
+)
+
+// Check is a single component check. Run returns a short detail on success.
+type Check struct {
+	Name     string
+	Required bool // The bot cannot run without the component
+	Run      func(ctx context.Context) (string, error)
+	skip     string
+}
+
+// Skipped returns a check that is reported as skipped with the given reason, e.g. a disabled feature
+func Skipped(name, reason string) Check {
+	return Check{Name: name, skip: reason}
+}
+
+// Result is the outcome of a check
+type Result struct {
+	Name     string
+	Required bool
+	Status   Status
+	Detail   string
+	Duration time.Duration
+}
+
+// Run runs the checks concurrently, each limited to the timeout, and returns their results in order
+func Run(ctx context.Context, checks []Check, timeout time.Duration) []Result {
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		if c.Run == nil {
+			results[i] = Result{Name: c.Name, Required: c.Required, Status: StatusSkip, Detail: c.skip}
+			continue
+		}
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, c, timeout)
+		}(i, c)
+	}
+	wg.Wait()
+	return results
+}
+
+// runCheck runs one check, turning a timeout or panic into a failure
+func runCheck(ctx context.Context, c Check, timeout time.Duration) (result Result) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result = Result{Name: c.Name, Required: c.Required}
+	defer func() {
+		if r := recover(); r != nil {
+			result.Status, result.Detail = StatusFail, fmt.Sprintf("panic: %v", r)
+		}
+		result.Duration = time.Since(start)
+	}()
+
+	type outcome struct {
+		detail string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		detail, err := c.Run(ctx)
+		done <- outcome{detail, err}
+	}()
+
+	select {
+	case o := <-done:
+		if o.err != nil {
+			result.Status, result.Detail = StatusFail, o.err.Error()
+		} else {
+			result.Status, result.Detail = StatusPass, o.detail
+		}
+	case <-ctx.Done():
+		result.Status, result.Detail = StatusFail, fmt.Sprintf("timed out after %s", timeout)
+	}
+	return result
+}
+
+// Failures counts the failed required and optional checks
+func Failures(results []Result) (required, optional int) {
+	for _, r := range results {
+		if r.Status != StatusFail {
+			continue
+		}
+		if r.Required {
+			required++
+		} else {
+			optional++
+		}
+	}
+	return required, optional
+}
+
+// Print writes a human-readable report of the results
+func Print(w io.Writer, results []Result) {
+	width := 0
+	for _, r := range results {
+		width = max(width, len(r.Name))
+	}
+	for _, r := range results {
+		line := fmt.Sprintf("[%s] %-*s", r.Status, width, r.Name)
+		if r.Detail != "" {
+			line += "  " + r.Detail
+		}
+		if r.Status != StatusSkip {
+			line += fmt.Sprintf(" (%s)", r.Duration.Round(time.Millisecond))
+		}
+		if r.Status == StatusFail && !r.Required {
+			line += " [optional]"
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+
+	required, optional := Failures(results)
+	switch {
+	case required > 0:
+		_, _ = fmt.Fprintf(w, "\n%d required check(s) failed, the bot cannot start\n", required)
+	case optional > 0:
+		_, _ = fmt.Fprintf(w, "\n%d optional check(s) failed, the affected features will not work\n", optional)
+	default:
+		_, _ = fmt.Fprintln(w, "\nAll checks passed")
+	}
+}
+
+// Log logs one line per result: failures of required checks as errors, of optional ones as warnings
+func Log(results []Result) {
+	for _, r := range results {
+		fields := []zap.Field{
+			zap.String("component", r.Name),
+			zap.String("status", string(r.Status)),
+			zap.String("detail", r.Detail),
+			zap.Duration("duration", r.Duration),
+		}
+		switch {
+		case r.Status != StatusFail:
+			logger.Info("Self-check", fields...)
+		case r.Required:
+			logger.Error("Self-check failed", fields...)
+		default:
+			logger.Warn("Self-check failed", fields...)
+		}
+	}
+}
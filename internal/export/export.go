@@ -0,0 +1,137 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is an export file format
+type Format string
+
+// Supported export formats
+const (
+	FormatCSV      Format = "csv"
+	FormatMarkdown Format = "md"
+)
+
+// utf8BOM makes spreadsheet applications (e.g., Excel) detect UTF-8 in CSV files
+const utf8BOM = "\ufeff"
+
+// ParseFormat parses a user-supplied format name; empty means CSV
+func ParseFormat(s string) (Format, bool) {
+	switch strings.ToLower(s) {
+	case "", "csv":
+		return FormatCSV, true
+	case "md", "markdown":
+		return FormatMarkdown, true
+	}
+	return "", false
+}
+
+// FileName returns the file name of an export with the extension of the format
+func (f Format) FileName(base string) string {
+	return base + "." + string(f)
+}
+
+// MIME returns the media type of the format
+func (f Format) MIME() string {
+	if f == FormatMarkdown {
+		return "text/markdown"
+	}
+	return "text/csv"
+}
+
+// Table is a titled table of exported rows
+type Table struct {
+	Title   string
+	Headers []string
+	Rows    [][]string
+}
+
+// Write writes the tables in the format. In CSV, tables are separated by an empty line
+// and preceded by their title on its own row.
+func Write(w io.Writer, format Format, tables ...Table) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, tables)
+	case FormatMarkdown:
+		return writeMarkdown(w, tables)
+	}
+	return fmt.Errorf("unsupported export format: %s", format)
+}
+
+// writeCSV writes tables as CSV
+func writeCSV(w io.Writer, tables []Table) error {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	for i, t := range tables {
+		if len(tables) > 1 {
+			if i > 0 {
+				if err := cw.Write(nil); err != nil {
+					return err
+				}
+			}
+			if err := cw.Write([]string{t.Title}); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(t.Headers); err != nil {
+			return err
+		}
+		for _, row := range t.Rows {
+			if err := cw.Write(neutralizeFormulas(row)); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// neutralizeFormulas prefixes cells that spreadsheets would evaluate as formulas (CSV injection)
+func neutralizeFormulas(cells []string) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		if c != "" && strings.ContainsRune("=+-@", rune(c[0])) {
+			c = "'" + c
+		}
+		out[i] = c
+	}
+	return out
+}
+
+// writeMarkdown writes tables as Markdown sections
+func writeMarkdown(w io.Writer, tables []Table) error {
+	var b strings.Builder
+	for i, t := range tables {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "## %s\n\n", t.Title)
+		if len(t.Rows) == 0 {
+			b.WriteString("（无）\n")
+			continue
+		}
+		b.WriteString("| " + strings.Join(escapeCells(t.Headers), " | ") + " |\n")
+		b.WriteString("|" + strings.Repeat(" --- |", len(t.Headers)) + "\n")
+		for _, row := range t.Rows {
+			b.WriteString("| " + strings.Join(escapeCells(row), " | ") + " |\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeCells makes cell values safe inside a Markdown table row
+func escapeCells(cells []string) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		c = strings.ReplaceAll(c, "|", "\\|")
+		out[i] = strings.ReplaceAll(c, "\n", " ")
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package export
+
+import (
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+)
+
+// timeLayout is the layout of timestamps in exports
+const timeLayout = "2006-01-02 15:04"
+
+// SubscriptionTable lists a user's subscriptions
+func SubscriptionTable(subs []model.Subscription, loc *time.Location) Table {
+	t := Table{
+		Title:   "订阅",
+		Headers: []string{"城市", "提醒时间", "状态", "预警推送", "创建时间"},
+	}
+	for _, sub := range subs {
+		t.Rows = append(t.Rows, []string{
+			sub.City,
+			sub.ReminderTime,
+			yesNo(sub.Active, "有效", "已暂停"),
+			yesNo(sub.EnableWarning, "开启", "关闭"),
+			sub.CreatedAt.In(loc).Format(timeLayout),
+		})
+	}
+	return t
+}
+
+// TodoTable lists the todos of a city, completed ones included
+func TodoTable(city string, todos []model.Todo, loc *time.Location) Table {
+	t := Table{
+		Title:   city + " 待办事项",
+		Headers: []string{"城市", "内容", "状态", "创建时间", "完成时间"},
+	}
+	t.Rows = todoRows(city, todos, loc)
+	return t
+}
+
+// AllTodosTable lists the todos of several subscriptions in one table
+func AllTodosTable(subs []model.Subscription, todos map[uint][]model.Todo, loc *time.Location) Table {
+	t := Table{
+		Title:   "待办事项",
+		Headers: []string{"城市", "内容", "状态", "创建时间", "完成时间"},
+	}
+	for _, sub := range subs {
+		t.Rows = append(t.Rows, todoRows(sub.City, todos[sub.ID], loc)...)
+	}
+	return t
+}
+
+// AuditTable lists audit log entries
+func AuditTable(entries []model.AuditLog, loc *time.Location) Table {
+	t := Table{
+		Title:   "审计日志",
+		Headers: []string{"时间", "操作者", "操作", "对象", "详情"},
+	}
+	for _, e := range entries {
+		t.Rows = append(t.Rows, []string{
+			e.CreatedAt.In(loc).Format("2006-01-02 15:04:05"),
+			e.Actor,
+			e.Action,
+			e.Target,
+			e.Detail,
+		})
+	}
+	return t
+}
+
+// todoRows renders todos as table rows
+func todoRows(city string, todos []model.Todo, loc *time.Location) [][]string {
+	var rows [][]string
+	for _, todo := range todos {
+		completedAt := ""
+		if todo.CompletedAt != nil {
+			completedAt = todo.CompletedAt.In(loc).Format(timeLayout)
+		}
+		rows = append(rows, []string{
+			city,
+			todo.Content,
+			yesNo(todo.Completed, "已完成", "未完成"),
+			todo.CreatedAt.In(loc).Format(timeLayout),
+			completedAt,
+		})
+	}
+	return rows
+}
+
+// yesNo returns one of two labels for a flag
+func yesNo(v bool, yes, no string) string {
+	if v {
+		return yes
+	}
+	return no
+}
@@ -0,0 +1,69 @@
+package migration
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DropChatIDUniqueIndexes removes the unique chat ID indexes of users and conversations from
+// before multi-bot support, so that AutoMigrate can replace them with (bot, chat_id) indexes:
+// the same Telegram chat may now have a user record and a conversation in every bot.
+func DropChatIDUniqueIndexes(db *gorm.DB) error {
+	legacy := []struct {
+		model interface{}
+		index string
+	}{
+		{&model.User{}, "idx_users_chat_id"},
+		{&model.Conversation{}, "idx_conversations_chat_id"},
+	}
+
+	for _, l := range legacy {
+		if !db.Migrator().HasTable(l.model) || !db.Migrator().HasIndex(l.model, l.index) {
+			continue
+		}
+		if err := db.Migrator().DropIndex(l.model, l.index); err != nil {
+			return fmt.Errorf("failed to drop index %s: %w", l.index, err)
+		}
+		logger.Info("Dropped legacy unique chat ID index", zap.String("index", l.index))
+	}
+	return nil
+}
+
+// ScopeProcessedUpdatesByBot rebuilds the processed updates table from before multi-bot support,
+// keyed by the update ID alone, with the (bot_id, update_id) primary key: update IDs are per bot.
+// The recorded IDs are kept as the primary bot's.
+func ScopeProcessedUpdatesByBot(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&model.ProcessedUpdate{}) || m.HasColumn(&model.ProcessedUpdate{}, "bot_id") {
+		return nil
+	}
+	const legacy = "processed_updates_legacy"
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Index names are per database in SQLite: the new table's would clash with the legacy one's
+		const createdAtIndex = "idx_processed_updates_created_at"
+		if tx.Migrator().HasIndex(&model.ProcessedUpdate{}, createdAtIndex) {
+			if err := tx.Migrator().DropIndex(&model.ProcessedUpdate{}, createdAtIndex); err != nil {
+				return fmt.Errorf("failed to drop index %s: %w", createdAtIndex, err)
+			}
+		}
+		if err := tx.Migrator().RenameTable("processed_updates", legacy); err != nil {
+			return fmt.Errorf("failed to rename processed updates: %w", err)
+		}
+		if err := tx.Migrator().CreateTable(&model.ProcessedUpdate{}); err != nil {
+			return fmt.Errorf("failed to create processed updates: %w", err)
+		}
+		result := tx.Exec("INSERT INTO processed_updates (bot_id, update_id, created_at) SELECT '', update_id, created_at FROM " + legacy)
+		if result.Error != nil {
+			return fmt.Errorf("failed to copy processed updates: %w", result.Error)
+		}
+		if err := tx.Migrator().DropTable(legacy); err != nil {
+			return fmt.Errorf("failed to drop legacy processed updates: %w", err)
+		}
+		logger.Info("Scoped processed updates by bot", zap.Int64("updates", result.RowsAffected))
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+package migration_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/config"
+	"github.com/cuichanghe/daily-reminder-bot/internal/migration"
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	_ = logger.Init(&config.LoggerConfig{Level: "fatal"})
+	os.Exit(m.Run())
+}
+
+// A processed updates table keyed by the update ID alone is rebuilt keyed by (bot_id, update_id),
+// keeping its records as the primary bot's, so that every bot persists its own update IDs
+func TestScopeProcessedUpdatesByBot(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE processed_updates (update_id integer PRIMARY KEY, created_at datetime NOT NULL)",
+		"CREATE INDEX idx_processed_updates_created_at ON processed_updates (created_at)",
+		"INSERT INTO processed_updates (update_id, created_at) VALUES (100, '2025-01-01 00:00:00'), (101, '2025-01-01 00:00:01')",
+	} {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := migration.Run(db); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	// Idempotent once migrated
+	if err := migration.ScopeProcessedUpdatesByBot(db); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+
+	var updates []model.ProcessedUpdate
+	if err := db.Order("update_id").Find(&updates).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 || updates[0].BotID != "" || updates[0].UpdateID != 100 || updates[1].UpdateID != 101 {
+		t.Fatalf("migrated updates = %+v", updates)
+	}
+	if !db.Migrator().HasIndex(&model.ProcessedUpdate{}, "idx_processed_updates_created_at") {
+		t.Error("created_at index is missing")
+	}
+
+	repo := repository.NewProcessedUpdateRepository(db)
+	for _, tt := range []struct {
+		bot      string
+		updateID int
+		want     bool
+	}{
+		{"", 100, false},      // Kept from before the migration
+		{"backup", 100, true}, // Same update ID from another bot
+		{"backup", 100, false},
+		{"", 102, true},
+	} {
+		isNew, err := repo.MarkProcessed(tt.bot, tt.updateID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isNew != tt.want {
+			t.Errorf("MarkProcessed(%q, %d) = %v, want %v", tt.bot, tt.updateID, isNew, tt.want)
+		}
+	}
+}
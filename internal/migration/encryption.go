@@ -0,0 +1,101 @@
+package migration
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/fieldcrypt"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// encryptionBatchSize is the number of rows loaded per encryption migration query
+const encryptionBatchSize = 500
+
+// encryptedColumns lists the columns tagged `serializer:encrypted`
+var encryptedColumns = []struct {
+	table, column string
+}{
+	{"todos", "content"},
+	{"todo_proposals", "items"},
+	{"conversations", "data"},
+	{"feedback", "text"},
+}
+
+// SyncFieldEncryption brings the encrypted columns in line with the configured cipher (see
+// model.SetFieldCipher): when encrypt is true, plaintext values written before encryption was
+// enabled are sealed; when false, sealed values are decrypted back to plaintext. Soft-deleted rows
+// are included and timestamps are left untouched. It must run after the cipher is set.
+func SyncFieldEncryption(db *gorm.DB, encrypt bool) error {
+	for _, col := range encryptedColumns {
+		if err := syncColumnEncryption(db, col.table, col.column, encrypt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncColumnEncryption seals or opens the values of one column in batches
+func syncColumnEncryption(db *gorm.DB, table, column string, encrypt bool) error {
+	type row struct {
+		ID    uint
+		Value string
+	}
+
+	var lastID uint
+	converted, failed := 0, 0
+	for {
+		var rows []row
+		if err := db.Table(table).
+			Select("id, "+column+" AS value").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(encryptionBatchSize).
+			Scan(&rows).Error; err != nil {
+			return fmt.Errorf("failed to query %s for encryption: %w", table, err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, r := range rows {
+			lastID = r.ID
+			if fieldcrypt.IsSealed(r.Value) == encrypt {
+				continue
+			}
+
+			var value string
+			var err error
+			if encrypt {
+				value, err = model.SealField(table, column, r.Value)
+			} else {
+				value, err = model.OpenField(table, column, r.Value)
+			}
+			if err != nil {
+				failed++
+				logger.Warn("Failed to convert encrypted column",
+					zap.String("table", table),
+					zap.Uint("id", r.ID),
+					zap.Error(err))
+				continue
+			}
+
+			// Written raw: column updates bypass the serializer
+			if err := db.Table(table).Where("id = ?", r.ID).UpdateColumn(column, value).Error; err != nil {
+				return fmt.Errorf("failed to update %s %d: %w", table, r.ID, err)
+			}
+			converted++
+		}
+	}
+
+	if converted > 0 || failed > 0 {
+		logger.Info("Encrypted column migrated",
+			zap.String("table", table),
+			zap.String("column", column),
+			zap.Bool("encrypt", encrypt),
+			zap.Int("converted", converted),
+			zap.Int("failed", failed))
+	}
+	return nil
+}
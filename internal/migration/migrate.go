@@ -9,6 +9,74 @@ import (
 	"gorm.io/gorm"
 )
 
+// Run auto-migrates all models and applies data migrations
+func Run(db *gorm.DB) error {
+	// Must run before AutoMigrate creates the unique (user_id, city) index
+	if err := DeduplicateSubscriptions(db); err != nil {
+		return fmt.Errorf("failed to deduplicate subscriptions: %w", err)
+	}
+
+	// Must run before AutoMigrate creates the unique (bot, chat_id) indexes
+	if err := DropChatIDUniqueIndexes(db); err != nil {
+		return fmt.Errorf("failed to scope chat IDs by bot: %w", err)
+	}
+
+	// Must run before AutoMigrate, which cannot change a primary key
+	if err := ScopeProcessedUpdatesByBot(db); err != nil {
+		return fmt.Errorf("failed to scope processed updates by bot: %w", err)
+	}
+
+	if err := db.AutoMigrate(
+		&model.User{},
+		&model.Subscription{},
+		&model.Todo{},
+		&model.WarningLog{},
+		&model.DeliveryLog{},
+		&model.Webhook{},
+		&model.WebhookDelivery{},
+		&model.NotificationChannel{},
+		&model.ProcessedUpdate{},
+		&model.UsedTransferToken{},
+		&model.Conversation{},
+		&model.Announcement{},
+		&model.TodoInvite{},
+		&model.TodoStats{},
+		&model.TodoProposal{},
+		&model.TodoMessage{},
+		&model.IndexWatch{},
+		&model.APISnapshot{},
+		&model.WarningMute{},
+		&model.WarningType{},
+		&model.PreAlertLog{},
+		&model.SeasonalEvent{},
+		&model.Experiment{},
+		&model.ExperimentEvent{},
+		&model.Feedback{},
+		&model.HealthReminder{},
+		&model.IntervalReminder{},
+		&model.ReminderVote{},
+		&model.Observation{},
+		&model.Payment{},
+		&model.AuditLog{},
+		&model.EventLog{},
+		&model.StaffRole{},
+		&model.DashboardCode{},
+		&model.DashboardSession{},
+		&model.JobRun{},
+		&model.CustomFestival{},
+		&model.CalendarFeed{},
+	); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	// Run data migration to multi-subscription model
+	if err := MigrateToMultiSubscription(db); err != nil {
+		return fmt.Errorf("failed to run data migration: %w", err)
+	}
+
+	return nil
+}
+
 // MigrateToMultiSubscription migrates existing data from single subscription to multi-subscription model
 // This migration:
 // 1. Checks if migration is needed (if UserID column exists in todos table)
@@ -0,0 +1,139 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DeduplicateSubscriptions merges duplicate (user, city) subscription rows left by earlier versions,
+// which inserted a new row on every re-subscribe, so that the unique index can be created.
+// For each pair it keeps the live active row (or the most recently updated one), moves todos,
+// channels and delivery logs of the other rows onto it, and permanently deletes the other rows.
+// Todos and channels of rows that were already cancelled stay hidden: the todos are soft-deleted
+// and the channels removed.
+func DeduplicateSubscriptions(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&model.Subscription{}) {
+		return nil
+	}
+
+	type subRow struct {
+		ID        uint
+		UserID    uint
+		City      string
+		Active    bool
+		DeletedAt *time.Time
+		UpdatedAt time.Time
+	}
+
+	var rows []subRow
+	if err := db.Table("subscriptions").
+		Select("id, user_id, city, active, deleted_at, updated_at").
+		Where("(user_id, city) IN (?)", db.Table("subscriptions").
+			Select("user_id, city").
+			Group("user_id, city").
+			Having("COUNT(*) > 1")).
+		Order("id").
+		Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to query duplicate subscriptions: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	type key struct {
+		userID uint
+		city   string
+	}
+	groups := make(map[key][]subRow)
+	for _, row := range rows {
+		k := key{row.UserID, row.City}
+		groups[k] = append(groups[k], row)
+	}
+
+	// rank orders candidates: live and active, then live, then most recently updated
+	rank := func(r subRow) int {
+		score := 0
+		if r.DeletedAt == nil {
+			score += 2
+			if r.Active {
+				score++
+			}
+		}
+		return score
+	}
+
+	hasTodos := db.Migrator().HasColumn(&model.Todo{}, "subscription_id")
+	hasChannels := db.Migrator().HasTable(&model.NotificationChannel{})
+	hasDeliveries := db.Migrator().HasTable(&model.DeliveryLog{})
+
+	removed := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, group := range groups {
+			keeper := group[0]
+			for _, row := range group[1:] {
+				if rank(row) > rank(keeper) || (rank(row) == rank(keeper) && row.UpdatedAt.After(keeper.UpdatedAt)) {
+					keeper = row
+				}
+			}
+
+			for _, row := range group {
+				if row.ID == keeper.ID {
+					continue
+				}
+				cancelled := row.DeletedAt != nil
+
+				if hasTodos {
+					if cancelled {
+						if err := tx.Table("todos").
+							Where("subscription_id = ? AND deleted_at IS NULL", row.ID).
+							Update("deleted_at", time.Now()).Error; err != nil {
+							return fmt.Errorf("failed to hide todos of subscription %d: %w", row.ID, err)
+						}
+					}
+					if err := tx.Table("todos").
+						Where("subscription_id = ?", row.ID).
+						Update("subscription_id", keeper.ID).Error; err != nil {
+						return fmt.Errorf("failed to move todos of subscription %d: %w", row.ID, err)
+					}
+				}
+				if hasChannels {
+					query := tx.Table("notification_channels").Where("subscription_id = ?", row.ID)
+					var err error
+					if cancelled {
+						err = query.Delete(&model.NotificationChannel{}).Error
+					} else {
+						err = query.Update("subscription_id", keeper.ID).Error
+					}
+					if err != nil {
+						return fmt.Errorf("failed to move channels of subscription %d: %w", row.ID, err)
+					}
+				}
+				if hasDeliveries {
+					if err := tx.Table("delivery_logs").
+						Where("subscription_id = ?", row.ID).
+						Update("subscription_id", keeper.ID).Error; err != nil {
+						return fmt.Errorf("failed to move delivery logs of subscription %d: %w", row.ID, err)
+					}
+				}
+				if err := tx.Exec("DELETE FROM subscriptions WHERE id = ?", row.ID).Error; err != nil {
+					return fmt.Errorf("failed to delete duplicate subscription %d: %w", row.ID, err)
+				}
+				removed++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Merged duplicate subscriptions",
+		zap.Int("groups", len(groups)),
+		zap.Int("removed", removed))
+	return nil
+}
@@ -0,0 +1,75 @@
+package migration
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// backfillBatchSize is the number of users loaded per backfill query
+const backfillBatchSize = 100
+
+// ProfileLookup fetches the Telegram profile of a chat through the named bot (e.g., via getChat)
+type ProfileLookup func(bot string, chatID int64) (model.UserProfile, error)
+
+// BackfillUserProfiles fills the profile of users created before profiles were recorded.
+// Users are looked up one at a time, waiting interval between calls to stay within Telegram's
+// rate limits. Lookups that fail (e.g., the user blocked the bot) are retried on the next run;
+// users who interact with the bot are refreshed by the bot itself in the meantime.
+// getChat does not report the client language, so language_code is left for the bot to fill.
+func BackfillUserProfiles(db *gorm.DB, lookup ProfileLookup, interval time.Duration) error {
+	var lastID uint
+	updated, failed := 0, 0
+
+	for {
+		var users []model.User
+		if err := db.Where("profile_synced_at IS NULL AND id > ?", lastID).
+			Order("id").
+			Limit(backfillBatchSize).
+			Find(&users).Error; err != nil {
+			return fmt.Errorf("failed to query users for profile backfill: %w", err)
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		for _, user := range users {
+			lastID = user.ID
+
+			profile, err := lookup(user.Bot, user.ChatID)
+			time.Sleep(interval)
+			if err != nil {
+				failed++
+				logger.Debug("Failed to look up user profile",
+					zap.Uint("user_id", user.ID),
+					zap.Int64("chat_id", user.ChatID),
+					zap.Error(err))
+				continue
+			}
+
+			// Skip users the bot refreshed while the backfill was running
+			if err := db.Model(&model.User{}).
+				Where("id = ? AND profile_synced_at IS NULL", user.ID).
+				Updates(map[string]interface{}{
+					"username":          profile.Username,
+					"first_name":        profile.FirstName,
+					"last_name":         profile.LastName,
+					"profile_synced_at": time.Now(),
+				}).Error; err != nil {
+				return fmt.Errorf("failed to update user profile: %w", err)
+			}
+			updated++
+		}
+	}
+
+	if updated > 0 || failed > 0 {
+		logger.Info("User profile backfill completed",
+			zap.Int("updated", updated),
+			zap.Int("failed", failed))
+	}
+	return nil
+}
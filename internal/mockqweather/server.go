@@ -0,0 +1,298 @@
+// Package mockqweather provides a fixture-backed QWeather API server for local development and tests
+package mockqweather
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Fixture returns the raw content of an embedded fixture file (e.g., "weather_now.json")
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		panic("mockqweather: missing fixture " + name)
+	}
+	return data
+}
+
+// Options controls latency and error injection
+type Options struct {
+	Latency         time.Duration // Fixed delay added to every response
+	Jitter          time.Duration // Random extra delay in [0, Jitter)
+	ErrorRate       float64       // Fraction of requests (0-1) answered with ErrorCode
+	ErrorCode       string        // QWeather error code returned on injected failures (default "500")
+	FailPaths       []string      // Path prefixes that always fail (e.g., "/v7/warning/now")
+	KnownCitiesOnly bool          // Return 404 for cities not registered via AddCity
+}
+
+// Server is a mock QWeather API implementing http.Handler
+type Server struct {
+	opts Options
+
+	mu        sync.Mutex
+	rng       *rand.Rand
+	cities    map[string]qweather.GeoLocation
+	overrides map[string][]byte
+	requests  []string
+}
+
+// New creates a new mock server; 北京 is always registered from the geo fixture
+func New(opts Options) *Server {
+	if opts.ErrorCode == "" {
+		opts.ErrorCode = "500"
+	}
+	s := &Server{
+		opts:      opts,
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		cities:    make(map[string]qweather.GeoLocation),
+		overrides: make(map[string][]byte),
+	}
+
+	var geo qweather.GeoLocationResponse
+	if err := json.Unmarshal(Fixture("geo_lookup.json"), &geo); err == nil {
+		for _, loc := range geo.Location {
+			s.cities[loc.Name] = loc
+		}
+	}
+	return s
+}
+
+// AddCity registers an additional city for geo lookup
+func (s *Server) AddCity(loc qweather.GeoLocation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cities[loc.Name] = loc
+}
+
+// Override replaces the response body for an endpoint path prefix (e.g., "/v7/warning/now")
+func (s *Server) Override(pathPrefix string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides[pathPrefix] = body
+}
+
+// Requests returns the paths requested so far
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, len(s.requests))
+	copy(out, s.requests)
+	return out
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.Path)
+	delay := s.opts.Latency
+	if s.opts.Jitter > 0 {
+		delay += time.Duration(s.rng.Int63n(int64(s.opts.Jitter)))
+	}
+	injectError := s.opts.ErrorRate > 0 && s.rng.Float64() < s.opts.ErrorRate
+	var override []byte
+	for prefix, body := range s.overrides {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			override = body
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+
+	for _, prefix := range s.opts.FailPaths {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			injectError = true
+			break
+		}
+	}
+	if injectError {
+		writeJSON(w, []byte(fmt.Sprintf(`{"code":%q}`, s.opts.ErrorCode)))
+		return
+	}
+
+	if override != nil {
+		writeJSON(w, override)
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/geo/v2/city/lookup":
+		s.handleLookup(w, r.URL.Query().Get("location"))
+	case r.URL.Path == "/geo/v2/city/top":
+		s.handleTopCities(w)
+	case r.URL.Path == "/geo/v2/poi/lookup":
+		s.handlePOILookup(w, r.URL.Query().Get("location"), r.URL.Query().Get("type"))
+	case r.URL.Path == "/v7/weather/now":
+		writeJSON(w, Fixture("weather_now.json"))
+	case r.URL.Path == "/v7/weather/24h":
+		writeJSON(w, Fixture("weather_24h.json"))
+	case r.URL.Path == "/v7/weather/72h":
+		writeJSON(w, Fixture("weather_72h.json"))
+	case r.URL.Path == "/v7/weather/3d":
+		writeJSON(w, Fixture("weather_3d.json"))
+	case r.URL.Path == "/v7/weather/7d":
+		writeJSON(w, Fixture("weather_7d.json"))
+	case r.URL.Path == "/v7/weather/15d":
+		writeJSON(w, Fixture("weather_15d.json"))
+	case r.URL.Path == "/v7/indices/1d":
+		writeJSON(w, Fixture("indices_1d.json"))
+	case r.URL.Path == "/v7/air/now":
+		writeJSON(w, Fixture("air_now.json"))
+	case r.URL.Path == "/v7/air/5d":
+		writeJSON(w, Fixture("air_5d.json"))
+	case r.URL.Path == "/v7/warning/now":
+		writeJSON(w, Fixture("warning_now.json"))
+	case r.URL.Path == "/v7/warning/types":
+		writeJSON(w, Fixture("warning_types.json"))
+	case r.URL.Path == "/v7/solar-radiation/24h":
+		writeJSON(w, Fixture("solar_radiation.json"))
+	case r.URL.Path == "/v7/ocean/tide":
+		writeJSON(w, Fixture("tide.json"))
+	case r.URL.Path == "/v7/grid-weather/now":
+		writeJSON(w, Fixture("grid_now.json"))
+	case r.URL.Path == "/v7/grid-weather/24h":
+		writeJSON(w, Fixture("grid_24h.json"))
+	case strings.HasPrefix(r.URL.Path, "/airquality/v1/current/"):
+		writeJSON(w, Fixture("air_current.json"))
+	case strings.HasPrefix(r.URL.Path, "/airquality/v1/hourly/"):
+		writeJSON(w, Fixture("air_hourly.json"))
+	case strings.HasPrefix(r.URL.Path, "/airquality/v1/station/"):
+		writeJSON(w, Fixture("air_station.json"))
+	default:
+		writeJSON(w, []byte(`{"code":"404"}`))
+	}
+}
+
+// handleLookup resolves a city name, or "lon,lat" coordinates, to a location.
+// Coordinates resolve to the nearest registered city; unknown cities get a synthetic
+// location unless KnownCitiesOnly is set.
+func (s *Server) handleLookup(w http.ResponseWriter, city string) {
+	s.mu.Lock()
+	loc, ok := s.cities[city]
+	if lon, lat, isCoord := parseCoordinates(city); isCoord {
+		loc, ok = s.nearestCity(lon, lat)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		if s.opts.KnownCitiesOnly || city == "" {
+			writeJSON(w, []byte(`{"code":"404","location":[]}`))
+			return
+		}
+		loc = syntheticLocation(city)
+	}
+
+	body, _ := json.Marshal(qweather.GeoLocationResponse{
+		Code:     "200",
+		Location: []qweather.GeoLocation{loc},
+	})
+	writeJSON(w, body)
+}
+
+// handleTopCities lists the registered cities followed by a few synthetic popular cities
+func (s *Server) handleTopCities(w http.ResponseWriter) {
+	s.mu.Lock()
+	var top []qweather.GeoLocation
+	for _, loc := range s.cities {
+		top = append(top, loc)
+	}
+	sort.Slice(top, func(i, j int) bool { return top[i].ID < top[j].ID })
+	for _, city := range []string{"上海", "广州", "深圳", "杭州"} {
+		if _, ok := s.cities[city]; !ok {
+			top = append(top, syntheticLocation(city))
+		}
+	}
+	s.mu.Unlock()
+
+	body, _ := json.Marshal(qweather.TopCityResponse{Code: "200", TopCityList: top})
+	writeJSON(w, body)
+}
+
+// handlePOILookup resolves a keyword to a synthetic point of interest of the requested type,
+// located next to 北京. Registered cities are not POIs and return 404.
+func (s *Server) handlePOILookup(w http.ResponseWriter, keyword, poiType string) {
+	s.mu.Lock()
+	_, isCity := s.cities[keyword]
+	s.mu.Unlock()
+	if keyword == "" || isCity {
+		writeJSON(w, []byte(`{"code":"404","poi":[]}`))
+		return
+	}
+
+	poi := syntheticLocation(keyword)
+	poi.ID = "10101" + poi.ID[len(poi.ID)-4:]
+	poi.Lat, poi.Lon = "39.92", "116.39"
+	poi.Adm2, poi.Adm1 = "东城", "北京市"
+	poi.Type = poiType
+	body, _ := json.Marshal(qweather.POIResponse{Code: "200", POI: []qweather.GeoLocation{poi}})
+	writeJSON(w, body)
+}
+
+// parseCoordinates parses a "lon,lat" location parameter
+func parseCoordinates(location string) (lon, lat float64, ok bool) {
+	lonStr, latStr, found := strings.Cut(location, ",")
+	if !found {
+		return 0, 0, false
+	}
+	lon, errLon := strconv.ParseFloat(lonStr, 64)
+	lat, errLat := strconv.ParseFloat(latStr, 64)
+	return lon, lat, errLon == nil && errLat == nil
+}
+
+// nearestCity returns the registered city closest to the coordinates; the caller holds s.mu
+func (s *Server) nearestCity(lon, lat float64) (qweather.GeoLocation, bool) {
+	var best qweather.GeoLocation
+	bestDist := math.Inf(1)
+	for _, loc := range s.cities {
+		cityLon, cityLat, ok := parseCoordinates(loc.Lon + "," + loc.Lat)
+		if !ok {
+			continue
+		}
+		if d := math.Hypot(cityLon-lon, cityLat-lat); d < bestDist {
+			best, bestDist = loc, d
+		}
+	}
+	return best, !math.IsInf(bestDist, 1)
+}
+
+// syntheticLocation builds a stable fake location for an arbitrary city name
+func syntheticLocation(city string) qweather.GeoLocation {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(city))
+	sum := h.Sum32()
+	return qweather.GeoLocation{
+		Name:      city,
+		ID:        fmt.Sprintf("1%08d", sum%100000000),
+		Lat:       fmt.Sprintf("%.2f", 20+float64(sum%2000)/100),
+		Lon:       fmt.Sprintf("%.2f", 100+float64((sum/2000)%2500)/100),
+		Adm2:      city,
+		Adm1:      city,
+		Country:   "中国",
+		Timezone:  "Asia/Shanghai",
+		UtcOffset: "+08:00",
+		Type:      "city",
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
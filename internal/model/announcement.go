@@ -0,0 +1,41 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Announcement statuses
+const (
+	AnnouncementPending   = "pending"   // Waiting for its scheduled time
+	AnnouncementSending   = "sending"   // Claimed by the scheduler
+	AnnouncementSent      = "sent"      // Delivered to all recipients
+	AnnouncementCancelled = "cancelled" // Cancelled by an admin before delivery
+)
+
+// Announcement is an admin broadcast (maintenance notice, new feature) delivered by the scheduler
+type Announcement struct {
+	ID          uint       `gorm:"primarykey"`
+	Message     string     `gorm:"type:text;not null"`        // Message body
+	Cities      string     `gorm:"type:varchar(500)"`         // Comma-separated target cities; empty means all users
+	ScheduledAt time.Time  `gorm:"not null;index"`            // Delivery time
+	Status      string     `gorm:"type:varchar(20);not null"` // pending, sending, sent, cancelled
+	SentAt      *time.Time // Delivery completion time
+	Recipients  int        // Users the announcement was sent to
+	Failures    int        // Users the announcement could not be sent to
+	CreatedAt   time.Time  `gorm:"not null"`
+	UpdatedAt   time.Time  `gorm:"not null"`
+}
+
+// TableName specifies the table name for Announcement model
+func (Announcement) TableName() string {
+	return "announcements"
+}
+
+// CityList returns the target cities, empty for all users
+func (a *Announcement) CityList() []string {
+	if a.Cities == "" {
+		return nil
+	}
+	return strings.Split(a.Cities, ",")
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// APISnapshot is the gzip-compressed raw body of a QWeather response, keeping the latest response
+// of each request per day for comparisons, offline re-rendering and debugging
+type APISnapshot struct {
+	ID        uint      `gorm:"primaryKey"`
+	Date      string    `gorm:"not null;size:10;uniqueIndex:idx_api_snapshot"`  // Local date (YYYY-MM-DD)
+	Request   string    `gorm:"not null;size:255;uniqueIndex:idx_api_snapshot"` // Path and query without credentials (qweather.RequestKey)
+	Location  string    `gorm:"not null;size:64;index"`                         // Location ID, city name or "lon,lat" of the request
+	Payload   []byte    `gorm:"not null"`                                       // Gzip-compressed response body
+	Size      int       `gorm:"not null"`                                       // Uncompressed body size in bytes
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for APISnapshot model
+func (APISnapshot) TableName() string {
+	return "api_snapshots"
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// AuditLog is an append-only record of an admin or destructive action; entries are never
+// updated or deleted
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey"`
+	Actor     string    `gorm:"size:64;not null;index"` // Who acted: "telegram:<user ID>" or "admin_api:<remote IP>"
+	Action    string    `gorm:"size:64;not null;index"` // What was done, e.g. "tier.set", "user.delete"
+	Target    string    `gorm:"size:64;index"`          // What was acted on, e.g. "user:12" ("" = nothing existing)
+	Detail    string    `gorm:"size:512"`               // Arguments of the action
+	CreatedAt time.Time `gorm:"not null;index"`
+}
+
+// Audit actions recorded by the bot; the admin API records one action per mutating endpoint
+const (
+	AuditTierSet          = "tier.set"
+	AuditTierPurchase     = "tier.purchase"
+	AuditObservationHide  = "observation.hide"
+	AuditObservationShow  = "observation.show"
+	AuditObservationBan   = "observation.ban"
+	AuditObservationUnban = "observation.unban"
+	AuditExport           = "audit.export"
+	AuditRoleGrant        = "role.grant"
+	AuditRoleRevoke       = "role.revoke"
+	AuditJobRequeue       = "job.requeue"
+)
@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// CalendarFeed is an external iCalendar feed (school timetable, team calendar) a user subscribed
+// to. It is synced daily, and the events of the day are listed in the user's daily reminders.
+type CalendarFeed struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index"`
+	Name      string     `gorm:"size:64;not null"`
+	URL       string     `gorm:"size:512;not null"`
+	Content   string     `gorm:"type:text"` // iCalendar document of the last successful sync
+	SyncedAt  *time.Time // Last successful sync
+	SyncError string     `gorm:"size:255"` // Error of the last sync ("" = it succeeded)
+	CreatedAt time.Time  `gorm:"not null"`
+	UpdatedAt time.Time  `gorm:"not null"`
+}
+
+// TableName specifies the table name for CalendarFeed model
+func (CalendarFeed) TableName() string {
+	return "calendar_feeds"
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Conversation stores the state of a multi-step dialog with a chat
+type Conversation struct {
+	ID        uint      `gorm:"primaryKey"`
+	Bot       string    `gorm:"type:varchar(32);not null;default:'';uniqueIndex:idx_conversations_bot_chat"` // Name of the bot the dialog runs in ("" = the primary bot)
+	ChatID    int64     `gorm:"not null;uniqueIndex:idx_conversations_bot_chat"`                             // One active conversation per chat and bot
+	Flow      string    `gorm:"type:varchar(50);not null"`                                                   // Flow name, e.g. "subscribe"
+	Step      string    `gorm:"type:varchar(50);not null"`                                                   // Current step within the flow
+	Data      string    `gorm:"type:text;serializer:encrypted"`                                              // JSON-encoded values collected so far, such as the text of /todo add (encrypted at rest when encryption.todos is on)
+	ExpiresAt time.Time `gorm:"not null;index"`                                                              // The conversation is abandoned after this time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName specifies the table name for Conversation model
+func (Conversation) TableName() string {
+	return "conversations"
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// CustomFestival is a festival a user added to the calendar of their daily reminders, recurring
+// every year on a solar or lunar date
+type CustomFestival struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	Name      string    `gorm:"size:64;not null"`
+	Lunar     bool      `gorm:"not null;default:false"` // Month and Day are a lunar date
+	Month     int       `gorm:"not null"`
+	Day       int       `gorm:"not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for CustomFestival model
+func (CustomFestival) TableName() string {
+	return "custom_festivals"
+}
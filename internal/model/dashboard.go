@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// DashboardCode is a one-time login code issued by /dashboard; the web dashboard exchanges it for
+// a session. Only the SHA-256 hash of the code is stored.
+type DashboardCode struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	CodeHash  string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// DashboardSession is a web dashboard login scoped to one user's data. Only the SHA-256 hash of
+// the session token is stored.
+type DashboardSession struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"not null"`
+}
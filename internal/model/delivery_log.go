@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Delivery kinds recorded in DeliveryLog
+const (
+	DeliveryKindReminder = "reminder" // Daily reminder (AI or template)
+	DeliveryKindFallback = "fallback" // Simplified reminder sent when weather data is unavailable
+)
+
+// DeliveryLog records the outcome of every reminder delivery attempt
+type DeliveryLog struct {
+	ID             uint      `gorm:"primarykey"`
+	SubscriptionID uint      `gorm:"not null;index"`                     // Subscription the reminder belongs to
+	ChatID         int64     `gorm:"not null;index"`                     // Telegram chat the reminder was sent to
+	MessageID      int       `gorm:"index"`                              // Telegram message ID of the reminder text (0 if not sent as text)
+	Kind           string    `gorm:"not null;size:32"`                   // reminder/fallback
+	Success        bool      `gorm:"not null;index:idx_created_success"` // Whether Telegram accepted the message
+	Error          string    `gorm:"size:512"`                           // Error message for failed deliveries
+	CreatedAt      time.Time `gorm:"not null;index:idx_created_success"`
+}
+
+// TableName specifies the table name for DeliveryLog model
+func (DeliveryLog) TableName() string {
+	return "delivery_logs"
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/fieldcrypt"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm/schema"
+)
+
+// Columns tagged `serializer:encrypted` are sealed with fieldcrypt when GORM writes a model
+// (Create, Save) and opened when it loads one. Values are bound to their column (table.column) as
+// associated data, so a sealed value cannot be copied into another column. Column updates
+// (Update, UpdateColumn, Updates with a map) and Pluck bypass serializers, so encrypted columns
+// must be written through the model.
+func init() {
+	schema.RegisterSerializer("encrypted", encryptedSerializer{})
+}
+
+// fieldEncryption holds the cipher of encrypted columns
+var fieldEncryption struct {
+	sync.RWMutex
+	cipher *fieldcrypt.Cipher
+	seal   bool
+}
+
+// SetFieldCipher sets the cipher of encrypted columns. Sealed values are opened whenever a cipher
+// is set; new values are only sealed when seal is true, so encryption can be turned off again
+// without losing access to sealed rows.
+func SetFieldCipher(cipher *fieldcrypt.Cipher, seal bool) {
+	fieldEncryption.Lock()
+	defer fieldEncryption.Unlock()
+	fieldEncryption.cipher = cipher
+	fieldEncryption.seal = seal && cipher != nil
+}
+
+// fieldCipher returns the cipher of encrypted columns and whether new values are sealed
+func fieldCipher() (*fieldcrypt.Cipher, bool) {
+	fieldEncryption.RLock()
+	defer fieldEncryption.RUnlock()
+	return fieldEncryption.cipher, fieldEncryption.seal
+}
+
+// fieldAssociatedData binds a sealed value to its column
+func fieldAssociatedData(table, column string) []byte {
+	return []byte(table + "." + column)
+}
+
+// SealField encrypts a value of an encrypted column with the configured cipher
+func SealField(table, column, value string) (string, error) {
+	cipher, _ := fieldCipher()
+	if cipher == nil {
+		return "", fmt.Errorf("no field cipher configured")
+	}
+	return cipher.Seal([]byte(value), fieldAssociatedData(table, column))
+}
+
+// OpenField decrypts a sealed value of an encrypted column; plaintext values are returned as is
+func OpenField(table, column, value string) (string, error) {
+	if !fieldcrypt.IsSealed(value) {
+		return value, nil
+	}
+	cipher, _ := fieldCipher()
+	if cipher == nil {
+		return "", fmt.Errorf("%w: no field cipher configured", fieldcrypt.ErrDecrypt)
+	}
+	plaintext, err := cipher.Open(value, fieldAssociatedData(table, column))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// encryptedSerializer is the GORM serializer of encrypted string columns
+type encryptedSerializer struct{}
+
+// Scan implements schema.SerializerInterface. Rows written before encryption was enabled are
+// plaintext and load unchanged; a value that cannot be opened (e.g. the key was changed) is
+// logged and loaded as stored rather than failing the whole query.
+func (encryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		value = v
+	case []byte:
+		value = string(v)
+	default:
+		return fmt.Errorf("unsupported value %T of encrypted column %s", dbValue, field.DBName)
+	}
+
+	plaintext, err := OpenField(field.Schema.Table, field.DBName, value)
+	if err != nil {
+		logger.Warn("Failed to decrypt column",
+			zap.String("table", field.Schema.Table),
+			zap.String("column", field.DBName),
+			zap.Error(err))
+		plaintext = value
+	}
+	return field.Set(ctx, dst, plaintext)
+}
+
+// Value implements schema.SerializerInterface
+func (encryptedSerializer) Value(_ context.Context, field *schema.Field, _ reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported value %T of encrypted column %s", fieldValue, field.DBName)
+	}
+	if _, seal := fieldCipher(); !seal {
+		return value, nil
+	}
+	return SealField(field.Schema.Table, field.DBName, value)
+}
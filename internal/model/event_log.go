@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Business events recorded in the event log for analytics
+const (
+	EventReminderSent        = "reminder_sent"        // A daily reminder was delivered (or failed to be)
+	EventWarningPushed       = "warning_pushed"       // A weather warning was pushed to a subscription
+	EventSubscriptionCreated = "subscription_created" // A subscription was created or restored
+)
+
+// EventLog is a business event kept in the database event sink (events.sink: db); entries are
+// only appended, for analytics pipelines to read
+type EventLog struct {
+	ID             uint      `gorm:"primarykey"`
+	Event          string    `gorm:"size:64;not null;index"` // Event name, e.g. "reminder_sent"
+	UserID         uint      `gorm:"index"`                  // User the event concerns (0 = none)
+	SubscriptionID uint      `gorm:"index"`                  // Subscription the event concerns (0 = none)
+	City           string    `gorm:"size:100"`
+	Data           string    `gorm:"type:text"` // Event-specific attributes as a JSON object
+	CreatedAt      time.Time `gorm:"not null;index"`
+}
+
+// TableName specifies the table name for EventLog model
+func (EventLog) TableName() string {
+	return "event_logs"
+}
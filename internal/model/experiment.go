@@ -0,0 +1,72 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Experiment statuses
+const (
+	ExperimentDraft   = "draft"   // Created, users are not assigned yet
+	ExperimentRunning = "running" // Users are assigned and events recorded
+	ExperimentStopped = "stopped" // Finished; metrics are kept
+)
+
+// Experiment dimensions: the reminder format aspect an experiment varies
+const (
+	ExperimentPersona      = "persona"       // AI writing persona
+	ExperimentSectionOrder = "section_order" // Section order of the fixed template
+	ExperimentEmoji        = "emoji"         // Emoji density of the reminder
+)
+
+// Experiment event kinds
+const (
+	ExperimentEventExposure = "exposure" // A reminder was delivered in the variant
+	ExperimentEventClick    = "click"    // The user clicked an inline button
+	ExperimentEventFeedback = "feedback" // The user sent /feedback; Value is the sentiment
+)
+
+// Experiment is an A/B test of a reminder format. Users are bucketed deterministically by the key,
+// so they keep their variant for the whole experiment.
+type Experiment struct {
+	ID          uint   `gorm:"primarykey"`
+	Key         string `gorm:"type:varchar(32);not null;uniqueIndex"` // Bucketing salt and admin-facing name
+	Dimension   string `gorm:"type:varchar(20);not null;index"`       // persona, section_order, emoji
+	Variants    string `gorm:"type:varchar(255);not null"`            // Comma-separated variant names
+	Description string `gorm:"type:varchar(255)"`
+	Status      string `gorm:"type:varchar(20);not null;index"` // draft, running, stopped
+	StartedAt   *time.Time
+	StoppedAt   *time.Time
+	CreatedAt   time.Time `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for Experiment model
+func (Experiment) TableName() string {
+	return "experiments"
+}
+
+// VariantList returns the variant names
+func (e *Experiment) VariantList() []string {
+	if e.Variants == "" {
+		return nil
+	}
+	return strings.Split(e.Variants, ",")
+}
+
+// ExperimentEvent is an exposure or engagement event of a user in an experiment variant
+type ExperimentEvent struct {
+	ID           uint      `gorm:"primarykey"`
+	ExperimentID uint      `gorm:"not null;index:idx_experiment_event"`
+	Variant      string    `gorm:"type:varchar(32);not null;index:idx_experiment_event"`
+	Kind         string    `gorm:"type:varchar(20);not null"` // exposure, click, feedback
+	UserID       uint      `gorm:"not null;index"`
+	Value        int       // Feedback sentiment: 1 positive, 0 neutral, -1 negative
+	Detail       string    `gorm:"type:varchar(64)"` // Clicked button
+	CreatedAt    time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for ExperimentEvent model
+func (ExperimentEvent) TableName() string {
+	return "experiment_events"
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Feedback is a free-text message sent with /feedback
+type Feedback struct {
+	ID        uint      `gorm:"primarykey"`
+	UserID    uint      `gorm:"not null;index"`
+	Text      string    `gorm:"type:text;not null;serializer:encrypted"` // Encrypted at rest when encryption.todos is on
+	Sentiment int       // 1 positive, 0 neutral, -1 negative
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for Feedback model
+func (Feedback) TableName() string {
+	return "feedback"
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// HealthReminder is a private recurring health reminder (menstrual cycle, medication). Only the
+// owner and the delivery time are stored in the clear; the plan (kind, label, cycle length and
+// start date) is encrypted in Payload with the owner's user ID as associated data, so a row
+// cannot be read without the key or moved to another user.
+type HealthReminder struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserID       uint      `gorm:"not null;index"`
+	ReminderTime string    `gorm:"size:5;not null;index"` // HH:MM in the bot's timezone
+	Payload      string    `gorm:"type:text;not null"`    // Encrypted plan (fieldcrypt)
+	LastSentOn   string    `gorm:"size:10"`               // Date of the last reminder (YYYY-MM-DD), to send at most once a day
+	CreatedAt    time.Time `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// TableName specifies the table name for HealthReminder model
+func (HealthReminder) TableName() string {
+	return "health_reminders"
+}
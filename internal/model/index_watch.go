@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// IndexWatch is a standalone push of a single life index (e.g. car washing), sent on the
+// subscription's reminder run when the day's level is good enough
+type IndexWatch struct {
+	ID             uint      `gorm:"primaryKey"`
+	UserID         uint      `gorm:"not null;index"`
+	SubscriptionID uint      `gorm:"not null;uniqueIndex:idx_index_watch"`        // Subscription whose city and reminder time the watch follows
+	IndexType      string    `gorm:"not null;size:8;uniqueIndex:idx_index_watch"` // QWeather life index type (e.g. "2" = car washing)
+	MaxLevel       int       `gorm:"not null;default:1"`                          // Push when the index level is at most this (1 = best)
+	LastNotifiedOn string    `gorm:"size:10"`                                     // Date of the last push (YYYY-MM-DD), to push at most once a day
+	CreatedAt      time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for IndexWatch model
+func (IndexWatch) TableName() string {
+	return "index_watches"
+}
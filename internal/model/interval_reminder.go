@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// IntervalReminder is a recurring micro-reminder (drinking water, stretching) sent every few
+// minutes within a daily window; each user has at most one per kind
+type IntervalReminder struct {
+	ID              uint      `gorm:"primaryKey"`
+	UserID          uint      `gorm:"not null;uniqueIndex:idx_interval_reminder"`
+	Kind            string    `gorm:"size:16;not null;uniqueIndex:idx_interval_reminder"` // water or stretch
+	IntervalMinutes int       `gorm:"not null"`                                           // Minutes between reminders (a multiple of 15)
+	StartTime       string    `gorm:"size:5;not null"`                                    // First reminder of the day (HH:MM)
+	EndTime         string    `gorm:"size:5;not null"`                                    // Latest reminder of the day (HH:MM)
+	WorkdaysOnly    bool      `gorm:"not null;default:false"`                             // Only on working days (statutory holidays and make-up days included)
+	LastSlot        string    `gorm:"size:16"`                                            // Last reminder sent ("YYYY-MM-DD HH:MM"), to send each slot once
+	CreatedAt       time.Time `gorm:"not null"`
+	UpdatedAt       time.Time `gorm:"not null"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// TableName specifies the table name for IntervalReminder model
+func (IntervalReminder) TableName() string {
+	return "interval_reminders"
+}
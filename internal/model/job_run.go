@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// JobRun records a run of a scheduled job, so admins can see when jobs last ran and requeue failed runs
+type JobRun struct {
+	ID           uint       `gorm:"primaryKey"`
+	Type         string     `gorm:"size:32;not null;index"` // Job type (JobType*)
+	City         string     `gorm:"size:64"`                // City the run was limited to ("" = all cities)
+	Status       string     `gorm:"size:16;not null;index"` // running/succeeded/failed
+	Items        int        `gorm:"not null;default:0"`     // Cities or records processed
+	Failures     int        `gorm:"not null;default:0"`     // Items that failed
+	Error        string     `gorm:"size:512"`               // Why the run failed
+	RequeuedFrom *uint      // Failed run this run retried (nil = scheduled)
+	StartedAt    time.Time  `gorm:"not null;index"`
+	FinishedAt   *time.Time // When the run ended (nil = still running)
+}
+
+// TableName specifies the table name for JobRun model
+func (JobRun) TableName() string {
+	return "job_runs"
+}
+
+// Statuses of a job run (JobRun.Status)
+const (
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+)
+
+// Types of recorded scheduled jobs (JobRun.Type)
+const (
+	JobTypeWarningSweep       = "warning_sweep"       // Weather warning check of all cities, every 15 minutes
+	JobTypeWarningTypes       = "warning_types"       // Warning type catalog sync
+	JobTypePreAlerts          = "pre_alerts"          // Evening forecast pre-alerts
+	JobTypeTodoStats          = "todo_stats"          // Todo completion stats aggregation
+	JobTypeWebhookCleanup     = "webhook_cleanup"     // Pruning of delivered webhooks
+	JobTypeSnapshotCleanup    = "snapshot_cleanup"    // Pruning of stored QWeather responses
+	JobTypeToneHints          = "tone_hints"          // Learning AI reminder tone hints from votes
+	JobTypeObservationCleanup = "observation_cleanup" // Removal of old weather observations
+	JobTypeJobCleanup         = "job_cleanup"         // Pruning of old job runs
+	JobTypeCalendarFeeds      = "calendar_feeds"      // Sync of users' iCalendar feeds
+)
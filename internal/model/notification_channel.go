@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// NotificationChannel is an additional delivery channel attached to a subscription (besides Telegram)
+type NotificationChannel struct {
+	ID             uint      `gorm:"primarykey"`
+	SubscriptionID uint      `gorm:"not null;index"`    // Foreign key to Subscription
+	Type           string    `gorm:"not null;size:16"`  // Channel type: email/ntfy/bark
+	Target         string    `gorm:"not null;size:255"` // Channel target: email address, ntfy topic, Bark device key
+	CreatedAt      time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for NotificationChannel model
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
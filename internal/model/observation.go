@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// Observation is a user's report of the weather where they are ("实况打卡"), optionally with a
+// photo. Reports of several users of a city are shown as crowd observations in its reminders.
+type Observation struct {
+	ID          uint      `gorm:"primaryKey"`
+	UserID      uint      `gorm:"not null;index"`
+	City        string    `gorm:"size:100;not null;index:idx_observation_city_time"`
+	Kind        string    `gorm:"size:16;not null"`       // Observed phenomenon (ObservationKind*)
+	Text        string    `gorm:"size:400"`               // Optional description
+	PhotoFileID string    `gorm:"size:255"`               // Telegram file ID of the photo ("" = none)
+	Hidden      bool      `gorm:"not null;default:false"` // Hidden by a moderator
+	CreatedAt   time.Time `gorm:"not null;index:idx_observation_city_time"`
+
+	User User `gorm:"foreignKey:UserID"`
+}
+
+// Observed phenomena
+const (
+	ObservationKindRain    = "rain"
+	ObservationKindSnow    = "snow"
+	ObservationKindHail    = "hail"
+	ObservationKindThunder = "thunder"
+	ObservationKindFog     = "fog"
+	ObservationKindHaze    = "haze"
+	ObservationKindDust    = "dust"
+	ObservationKindWind    = "wind"
+	ObservationKindSunny   = "sunny"
+)
+
+// TableName specifies the table name for Observation model
+func (Observation) TableName() string {
+	return "observations"
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Payment is a successful Telegram payment for the premium tier, kept as a ledger
+type Payment struct {
+	ID               uint       `gorm:"primaryKey"`
+	UserID           uint       `gorm:"not null;index"`
+	Days             int        `gorm:"not null"`                      // Days of premium bought
+	Currency         string     `gorm:"size:8;not null"`               // ISO 4217 code, XTR for Telegram Stars
+	Amount           int        `gorm:"not null"`                      // Total in the smallest units of the currency
+	Payload          string     `gorm:"size:128;not null"`             // Invoice payload
+	TelegramChargeID string     `gorm:"size:255;not null;uniqueIndex"` // Telegram's payment identifier
+	ProviderChargeID string     `gorm:"size:255"`                      // Payment provider's identifier ("" for Stars)
+	TierUntil        *time.Time // When the premium tier ends after this payment (nil = does not end)
+	CreatedAt        time.Time  `gorm:"not null;index"`
+}
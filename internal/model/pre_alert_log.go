@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// PreAlertLog records a forecast-based pre-alert sent for a city, so each event is pushed only once
+type PreAlertLog struct {
+	ID         uint      `gorm:"primaryKey"`
+	City       string    `gorm:"not null;size:64;uniqueIndex:idx_pre_alert"`
+	Kind       string    `gorm:"not null;size:16;uniqueIndex:idx_pre_alert"` // cold_drop/first_snow/heat
+	TargetDate string    `gorm:"not null;size:10;uniqueIndex:idx_pre_alert"` // Forecast date the alert is about (YYYY-MM-DD)
+	Summary    string    `gorm:"size:255"`                                   // Short description of the forecast change
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for PreAlertLog model
+func (PreAlertLog) TableName() string {
+	return "pre_alert_logs"
+}
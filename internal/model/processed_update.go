@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// ProcessedUpdate records a handled Telegram update ID so that redelivered updates are ignored.
+// Update IDs are per bot, so records are keyed by the bot and the update ID.
+type ProcessedUpdate struct {
+	BotID     string    `gorm:"type:varchar(32);primaryKey;default:''"` // Name of the bot that received the update ("" = the primary bot)
+	UpdateID  int       `gorm:"primaryKey;autoIncrement:false"`         // Telegram update_id
+	CreatedAt time.Time `gorm:"not null;index"`
+}
+
+// TableName specifies the table name for ProcessedUpdate model
+func (ProcessedUpdate) TableName() string {
+	return "processed_updates"
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// ReminderVote is a 👍/👎 vote on an AI-written daily reminder; each reminder message has at most
+// one vote, which the chat can change
+type ReminderVote struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	ChatID    int64     `gorm:"not null;uniqueIndex:idx_reminder_vote_message"`
+	MessageID int       `gorm:"not null;uniqueIndex:idx_reminder_vote_message"`
+	Vote      int       `gorm:"not null"` // 1 for 👍, -1 for 👎
+	Reason    string    `gorm:"size:16"`  // Why the reminder was voted down (VoteReason*, "" = not given)
+	CreatedAt time.Time `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"not null;index"`
+}
+
+// Reasons of 👎 votes
+const (
+	VoteReasonLong  = "long"  // Too long
+	VoteReasonEmoji = "emoji" // Too many emojis
+	VoteReasonOther = "other" // Anything else
+)
+
+// Tone hints learned from a user's votes and injected into the AI system prompt (User.ToneHints)
+const (
+	ToneHintShorter    = "shorter"     // Write shorter reminders
+	ToneHintFewerEmoji = "fewer_emoji" // Use few emojis
+)
+
+// TableName specifies the table name for ReminderVote model
+func (ReminderVote) TableName() string {
+	return "reminder_votes"
+}
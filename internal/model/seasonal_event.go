@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// SeasonalEvent records the first day a seasonal advice rule fired for a city in a season (e.g. the
+// first day below 5°C of a winter), so the tip is shown on that day only
+type SeasonalEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	City      string    `gorm:"not null;size:64;uniqueIndex:idx_seasonal_event"`
+	Rule      string    `gorm:"not null;size:32;uniqueIndex:idx_seasonal_event"` // Advice rule name (e.g. heating_season)
+	Season    string    `gorm:"not null;size:16;uniqueIndex:idx_seasonal_event"` // Season key from the rule (e.g. "2025")
+	Date      string    `gorm:"not null;size:10"`                                // Day the event happened (YYYY-MM-DD)
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for SeasonalEvent model
+func (SeasonalEvent) TableName() string {
+	return "seasonal_events"
+}
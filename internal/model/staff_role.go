@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Roles of staff users, from most to least privileged. Owners are only set in the configuration.
+const (
+	RoleOwner   = "owner"   // Everything, including granting and revoking admins
+	RoleAdmin   = "admin"   // Tiers, audit log and granting support
+	RoleSupport = "support" // Moderation and viewing users' tiers
+)
+
+// StaffRole is a role granted to a Telegram user with /grant
+type StaffRole struct {
+	ID         uint      `gorm:"primaryKey"`
+	TelegramID int64     `gorm:"not null;uniqueIndex"` // Telegram user ID of the staff member
+	Role       string    `gorm:"size:16;not null"`     // RoleAdmin or RoleSupport
+	GrantedBy  int64     `gorm:"not null"`             // Telegram user ID of who granted the role
+	CreatedAt  time.Time `gorm:"not null"`
+	UpdatedAt  time.Time `gorm:"not null"`
+}
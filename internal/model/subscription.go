@@ -6,19 +6,81 @@ import (
 	"gorm.io/gorm"
 )
 
+// Outdoor activities a subscription's daily reminder can include a forecast section for
+const (
+	OutdoorMountain = "mountain" // Hiking: mountain forecast and hazards
+	OutdoorSea      = "sea"      // Sailing: tides and sea wind
+)
+
+// AI modes of a subscription's daily reminder
+const (
+	AIModeDefault = ""     // The operator's default: full, or off when AI is opt-in (openai.opt_in)
+	AIModeOff     = "off"  // Fixed template
+	AIModeFull    = "full" // AI reminder written by the configured model
+	AIModeLite    = "lite" // Concise AI reminder written by the lite model, without todo planning
+)
+
 // Subscription represents a user's daily reminder subscription
 type Subscription struct {
-	ID            uint           `gorm:"primarykey"`
-	UserID        uint           `gorm:"not null;index:idx_user_city_time"` // Foreign key to User
-	User          User           `gorm:"foreignKey:UserID"`
-	City          string         `gorm:"not null;index:idx_user_city_time"` // City for weather lookup (e.g., "北京", "上海")
-	ReminderTime  string         `gorm:"not null;index:idx_user_city_time"` // Daily reminder time in HH:MM format (e.g., "08:00")
-	Active        bool           `gorm:"not null;default:true;index"`       // Whether subscription is active
-	EnableWarning bool           `gorm:"not null;default:true"`             // Whether weather warning notifications are enabled
-	Todos         []Todo         `gorm:"foreignKey:SubscriptionID"`         // Associated todos for this subscription
-	CreatedAt     time.Time      `gorm:"not null"`
-	UpdatedAt     time.Time      `gorm:"not null"`
-	DeletedAt     gorm.DeletedAt `gorm:"index"`
+	ID              uint           `gorm:"primarykey"`
+	UserID          uint           `gorm:"not null;index:idx_user_city_time;uniqueIndex:idx_user_city"` // Foreign key to User
+	User            User           `gorm:"foreignKey:UserID"`
+	City            string         `gorm:"not null;index:idx_user_city_time;uniqueIndex:idx_user_city"` // City for weather lookup (e.g., "北京", "上海"); one row per user and city, restored on re-subscribe
+	ReminderTime    string         `gorm:"not null;index:idx_user_city_time"`                           // Daily reminder time in HH:MM format (e.g., "08:00")
+	Active          bool           `gorm:"not null;default:true;index"`                                 // Whether subscription is active
+	EnableWarning   bool           `gorm:"not null;default:true"`                                       // Whether weather warning notifications are enabled
+	PinReminder     bool           `gorm:"not null;default:false"`                                      // Whether each daily reminder is pinned, unpinning the previous one
+	PinnedMessageID int            `gorm:"not null;default:0"`                                          // Telegram message ID of the currently pinned reminder (0 = none)
+	Lat             string         `gorm:"not null;default:''"`                                         // Latitude of a subscription created from a shared location (empty = city-level weather)
+	Lon             string         `gorm:"not null;default:''"`                                         // Longitude of a subscription created from a shared location (empty = city-level weather)
+	OutdoorKind     string         `gorm:"not null;default:''"`                                         // Outdoor activity of the daily digest section: OutdoorMountain, OutdoorSea or empty (none)
+	OutdoorPlace    string         `gorm:"not null;default:''"`                                         // Mountain, scenic area or tide station of the outdoor section
+	Laundry         bool           `gorm:"not null;default:false"`                                      // Whether the daily reminder includes the laundry drying advice
+	AIMode          string         `gorm:"size:8;not null;default:''"`                                  // How the daily reminder is written: AIMode* ("" = the operator's default)
+	Label           string         `gorm:"size:64;not null;default:''"`                                 // User-chosen name of the subscription, e.g. "家" or "公司" ("" = none)
+	Todos           []Todo         `gorm:"foreignKey:SubscriptionID"`                                   // Associated todos for this subscription
+	SharedListID    *uint          `gorm:"index"`                                                       // Subscription whose todo list this one co-manages (nil = own list)
+	CreatedAt       time.Time      `gorm:"not null"`
+	UpdatedAt       time.Time      `gorm:"not null"`
+	DeletedAt       gorm.DeletedAt `gorm:"index"`
+}
+
+// TodoListID returns the ID of the subscription holding this subscription's todos
+func (s *Subscription) TodoListID() uint {
+	if s.SharedListID != nil {
+		return *s.SharedListID
+	}
+	return s.ID
+}
+
+// DisplayName returns the name shown to the subscriber: "家（北京）" for a labelled subscription,
+// otherwise the city
+func (s *Subscription) DisplayName() string {
+	if s.Label != "" {
+		return s.Label + "（" + s.City + "）"
+	}
+	return s.City
+}
+
+// MatchesName reports whether a name given in a command refers to the subscription, by its city
+// or its label
+func (s *Subscription) MatchesName(name string) bool {
+	return name == s.City || (s.Label != "" && name == s.Label)
+}
+
+// HasCoordinates reports whether the subscription was created from a shared location and
+// uses grid weather for its coordinates
+func (s *Subscription) HasCoordinates() bool {
+	return s.Lat != "" && s.Lon != ""
+}
+
+// LocationQuery returns the GeoAPI location parameter: "lon,lat" for coordinate subscriptions,
+// otherwise the city name
+func (s *Subscription) LocationQuery() string {
+	if s.HasCoordinates() {
+		return s.Lon + "," + s.Lat
+	}
+	return s.City
 }
 
 // TableName specifies the table name for Subscription model
@@ -11,8 +11,11 @@ type Todo struct {
 	ID             uint           `gorm:"primarykey"`
 	SubscriptionID uint           `gorm:"not null;index:idx_subscription_completed"` // Foreign key to Subscription
 	Subscription   Subscription   `gorm:"foreignKey:SubscriptionID"`
-	Content        string         `gorm:"not null"`                                                // Todo item content
+	Content        string         `gorm:"not null;serializer:encrypted"`                           // Todo item content (encrypted at rest when encryption.todos is on)
 	Completed      bool           `gorm:"not null;default:false;index:idx_subscription_completed"` // Whether the todo is completed
+	CompletedAt    *time.Time     `gorm:"index"`                                                   // When the todo was completed
+	CompletedBy    *uint          // User who completed the todo (nil when completed via the admin API)
+	Position       int            `gorm:"not null;default:0"` // Order set by dragging in the Mini App (0 = not ordered; these come first, newest first)
 	CreatedAt      time.Time      `gorm:"not null"`
 	UpdatedAt      time.Time      `gorm:"not null"`
 	DeletedAt      gorm.DeletedAt `gorm:"index"`
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// TodoInvite is a one-time invitation to co-manage the todo list of a subscription
+type TodoInvite struct {
+	ID             uint      `gorm:"primaryKey"`
+	Token          string    `gorm:"type:varchar(64);uniqueIndex;not null"` // Random token carried by the /start deep link
+	SubscriptionID uint      `gorm:"not null;index"`                        // Subscription whose todo list is shared
+	ExpiresAt      time.Time `gorm:"not null;index"`                        // The invitation cannot be accepted after this time
+	CreatedAt      time.Time
+}
+
+// TableName specifies the table name for TodoInvite model
+func (TodoInvite) TableName() string {
+	return "todo_invites"
+}
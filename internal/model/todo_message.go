@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// TodoMessage links a Telegram message showing a single todo (the /todo add command and the
+// bot's confirmation) to that todo, so that reacting to the message completes it
+type TodoMessage struct {
+	ID        uint      `gorm:"primaryKey"`
+	TodoID    uint      `gorm:"not null;index"`
+	ChatID    int64     `gorm:"not null;uniqueIndex:idx_todo_message"`
+	MessageID int       `gorm:"not null;uniqueIndex:idx_todo_message"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// TableName specifies the table name for TodoMessage model
+func (TodoMessage) TableName() string {
+	return "todo_messages"
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// TodoProposal holds todos extracted from a photo until the user confirms or dismisses them
+type TodoProposal struct {
+	ID             uint      `gorm:"primaryKey"`
+	UserID         uint      `gorm:"not null;index"`                 // User who sent the photo
+	SubscriptionID uint      `gorm:"not null"`                       // Subscription whose todo list receives the todos
+	Items          string    `gorm:"type:text;serializer:encrypted"` // JSON-encoded proposed todos and whether each was added (encrypted at rest when encryption.todos is on)
+	ExpiresAt      time.Time `gorm:"not null;index"`                 // The proposal can no longer be confirmed after this time
+	CreatedAt      time.Time
+}
+
+// TableName specifies the table name for TodoProposal model
+func (TodoProposal) TableName() string {
+	return "todo_proposals"
+}
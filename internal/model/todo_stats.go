@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// TodoStats aggregates the todos a user completed on one day, maintained by the nightly stats job
+type TodoStats struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_todo_stats_user_date"`
+	Date      string `gorm:"type:varchar(10);not null;uniqueIndex:idx_todo_stats_user_date;index"` // YYYY-MM-DD in the scheduler timezone
+	Completed int    `gorm:"not null;default:0"`                                                   // Todos completed by the user that day
+	Streak    int    `gorm:"not null;default:0"`                                                   // Consecutive days with completions, ending that day
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name for TodoStats model
+func (TodoStats) TableName() string {
+	return "todo_stats"
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// UsedTransferToken records a redeemed subscription transfer link so that each link moves the
+// subscriptions once. Records are kept until the link has expired.
+type UsedTransferToken struct {
+	Signature string    `gorm:"type:varchar(64);primaryKey"` // HMAC signature identifying the token
+	UserID    uint      `gorm:"not null"`                    // User the subscriptions were transferred from
+	ExpiresAt time.Time `gorm:"not null;index"`
+	CreatedAt time.Time
+}
+
+// TableName specifies the table name for UsedTransferToken model
+func (UsedTransferToken) TableName() string {
+	return "used_transfer_tokens"
+}
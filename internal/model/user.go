@@ -8,14 +8,84 @@ import (
 
 // User represents a Telegram user in the system
 type User struct {
-	ID        uint           `gorm:"primarykey"`
-	ChatID    int64          `gorm:"uniqueIndex;not null"` // Telegram chat ID
-	CreatedAt time.Time      `gorm:"not null"`
-	UpdatedAt time.Time      `gorm:"not null"`
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID               uint           `gorm:"primarykey"`
+	Bot              string         `gorm:"type:varchar(32);not null;default:'';uniqueIndex:idx_users_bot_chat"` // Name of the bot the user talks to ("" = the primary bot)
+	ChatID           int64          `gorm:"not null;uniqueIndex:idx_users_bot_chat"`                             // Telegram chat ID
+	ProfileSyncedAt  *time.Time     // Last time the profile was copied from Telegram
+	ReferredByID     *uint          `gorm:"index"`                         // User who invited this user via a /share link
+	AnnouncementsOff bool           `gorm:"not null;default:false"`        // Opted out of admin announcements
+	VoiceMode        string         `gorm:"size:10;not null;default:off"`  // How daily reminders are read aloud (VoiceMode*)
+	CombinedDigest   bool           `gorm:"not null;default:false"`        // Subscriptions due at the same time share one reminder ("合并推送")
+	NewsFeed         string         `gorm:"type:varchar(512)"`             // Own RSS/Atom feed of the news section ("" = the default feed)
+	NewsOff          bool           `gorm:"not null;default:false"`        // Opted out of the news section
+	RatePairs        string         `gorm:"type:varchar(255)"`             // Own pairs of the rates section, e.g. "USD/CNY,XAU/CNY" ("" = the default pairs)
+	RatesOff         bool           `gorm:"not null;default:false"`        // Opted out of the rates section
+	Zodiac           string         `gorm:"size:16"`                       // Zodiac sign of the horoscope section, e.g. "aries" ("" = no horoscope)
+	HiddenFestivals  string         `gorm:"size:64"`                       // Comma-separated festival categories left out of the calendar, e.g. "western,solar_term"
+	QuietHours       string         `gorm:"size:11"`                       // Daily window without interval reminders, e.g. "12:00-13:30" ("" = none)
+	RegenDate        string         `gorm:"size:10"`                       // Day of the "换一条" regenerations counted in Regenerations (YYYY-MM-DD)
+	Regenerations    int            `gorm:"not null;default:0"`            // AI reminders regenerated on RegenDate
+	APIDate          string         `gorm:"size:10"`                       // Day of the on-demand external API calls counted in APICalls (YYYY-MM-DD)
+	APICalls         int            `gorm:"not null;default:0"`            // Weather queries and AI requests made on APIDate
+	ToneHints        string         `gorm:"size:32"`                       // Comma-separated tone hints of AI reminders learned from votes (ToneHint*)
+	ObservationsBan  bool           `gorm:"not null;default:false"`        // Banned from submitting weather observations by a moderator
+	Tier             string         `gorm:"size:16;not null;default:free"` // Service tier gating limits (Tier*)
+	TierUntil        *time.Time     // When a premium tier ends (nil = does not end)
+	CreatedAt        time.Time      `gorm:"not null"`
+	UpdatedAt        time.Time      `gorm:"not null"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+
+	UserProfile `gorm:"embedded"` // Refreshed from Telegram whenever the user interacts with the bot
+}
+
+// Voice reminder modes
+const (
+	VoiceModeOff  = "off"  // Text only
+	VoiceModeBoth = "both" // Text followed by a voice message
+	VoiceModeOnly = "only" // Voice message instead of text
+)
+
+// Service tiers of a user (User.Tier)
+const (
+	TierFree    = "free"
+	TierPremium = "premium"
+)
+
+// EffectiveTier returns the user's tier at the given time: a premium tier past TierUntil is free
+func (u *User) EffectiveTier(now time.Time) string {
+	if u.Tier == TierPremium && (u.TierUntil == nil || now.Before(*u.TierUntil)) {
+		return TierPremium
+	}
+	return TierFree
+}
+
+// UserProfile holds the public profile fields reported by Telegram
+type UserProfile struct {
+	Username     string `gorm:"type:varchar(64)"`  // @username without the leading @
+	FirstName    string `gorm:"type:varchar(128)"` // First name
+	LastName     string `gorm:"type:varchar(128)"` // Last name
+	LanguageCode string `gorm:"type:varchar(16)"`  // IETF language tag of the user's client (e.g., zh-hans, en)
 }
 
 // TableName specifies the table name for User model
 func (User) TableName() string {
 	return "users"
 }
+
+// DisplayName returns a human-readable name for logs and admin tools
+func (u *User) DisplayName() string {
+	name := u.FirstName
+	if u.LastName != "" {
+		if name != "" {
+			name += " "
+		}
+		name += u.LastName
+	}
+	if u.Username != "" {
+		if name == "" {
+			return "@" + u.Username
+		}
+		return name + " (@" + u.Username + ")"
+	}
+	return name
+}
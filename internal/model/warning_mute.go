@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// WarningMute silences pushes of one warning type (e.g. 大雾) for a subscription
+type WarningMute struct {
+	ID             uint      `gorm:"primaryKey"`
+	UserID         uint      `gorm:"not null;index"`
+	SubscriptionID uint      `gorm:"not null;uniqueIndex:idx_warning_mute"`
+	WarningType    string    `gorm:"not null;size:16;uniqueIndex:idx_warning_mute"` // QWeather warning type code (e.g. "1017" = 大雾)
+	CreatedAt      time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for WarningMute model
+func (WarningMute) TableName() string {
+	return "warning_mutes"
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// WarningType is a cached entry of QWeather's warning type catalog (code → name)
+type WarningType struct {
+	Code      string    `gorm:"primaryKey;size:16"` // Warning type code (e.g. "1017")
+	Name      string    `gorm:"not null;size:64"`   // Chinese name (e.g. "大雾")
+	UpdatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for WarningType model
+func (WarningType) TableName() string {
+	return "warning_types"
+}
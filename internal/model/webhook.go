@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Webhook events
+const (
+	WebhookEventReminder = "reminder" // Daily reminder was dispatched
+	WebhookEventWarning  = "warning"  // Weather warning was issued, updated or lifted
+)
+
+// Webhook delivery statuses
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusFailed    = "failed"
+)
+
+// Webhook represents a user-registered outbound webhook endpoint
+type Webhook struct {
+	ID        uint           `gorm:"primarykey"`
+	UserID    uint           `gorm:"not null;index"`        // Foreign key to User
+	URL       string         `gorm:"not null;size:512"`     // Target URL (http/https)
+	Secret    string         `gorm:"not null;size:128"`     // HMAC-SHA256 signing secret
+	Events    string         `gorm:"not null;size:128"`     // Comma-separated subscribed events (e.g., "reminder,warning")
+	Active    bool           `gorm:"not null;default:true"` // Whether the webhook receives events
+	CreatedAt time.Time      `gorm:"not null"`
+	UpdatedAt time.Time      `gorm:"not null"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// TableName specifies the table name for Webhook model
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// WebhookDelivery is an outbox entry for a single webhook POST, retried until delivered or exhausted
+type WebhookDelivery struct {
+	ID            uint      `gorm:"primarykey"`
+	WebhookID     uint      `gorm:"index"`                                  // Source webhook (0 for operator-configured endpoints)
+	URL           string    `gorm:"not null;size:512"`                      // Target URL
+	Secret        string    `gorm:"size:128"`                               // Signing secret captured at enqueue time
+	Event         string    `gorm:"not null;size:32"`                       // Event name
+	Payload       string    `gorm:"type:text;not null"`                     // JSON body
+	Status        string    `gorm:"not null;size:16;index:idx_status_next"` // pending/delivered/failed
+	Attempts      int       `gorm:"not null;default:0"`                     // Delivery attempts so far
+	NextAttemptAt time.Time `gorm:"not null;index:idx_status_next"`         // Earliest time of the next attempt
+	LastError     string    `gorm:"size:512"`                               // Error of the last failed attempt
+	CreatedAt     time.Time `gorm:"not null;index"`
+	UpdatedAt     time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for WebhookDelivery model
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var barkKeyPattern = regexp.MustCompile(`^[A-Za-z0-9]{8,64}$`)
+
+// BarkNotifier pushes messages to iOS devices via Bark; the target is the device key
+type BarkNotifier struct {
+	server     string
+	httpClient *http.Client
+}
+
+// NewBarkNotifier creates a new BarkNotifier (server defaults to https://api.day.app)
+func NewBarkNotifier(server string, timeout time.Duration) *BarkNotifier {
+	if server == "" {
+		server = "https://api.day.app"
+	}
+	return &BarkNotifier{
+		server:     strings.TrimSuffix(server, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the channel name
+func (n *BarkNotifier) Name() string {
+	return ChannelBark
+}
+
+// ValidateTarget checks that the target looks like a Bark device key
+func (n *BarkNotifier) ValidateTarget(target string) error {
+	if !barkKeyPattern.MatchString(target) {
+		return fmt.Errorf("invalid bark device key")
+	}
+	return nil
+}
+
+// Send pushes the message to the device
+func (n *BarkNotifier) Send(ctx context.Context, target string, msg Message) error {
+	if err := n.ValidateTarget(target); err != nil {
+		return err
+	}
+
+	level := "active"
+	if msg.Priority == PriorityHigh {
+		level = "timeSensitive"
+	}
+	payload, err := json.Marshal(map[string]string{
+		"device_key": target,
+		"title":      msg.Title,
+		"body":       msg.Body,
+		"level":      level,
+		"group":      "daily-reminder",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode bark payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.server+"/push", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("bark request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("bark returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
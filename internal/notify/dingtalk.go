@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var dingtalkTargetPattern = regexp.MustCompile(`^[A-Za-z0-9]{16,128}(:SEC[A-Za-z0-9]{16,128})?$`)
+
+// dingtalkMaxContentBytes is the text message limit of DingTalk group robots
+const dingtalkMaxContentBytes = 20000
+
+// DingTalkNotifier posts messages to DingTalk (钉钉) group robots.
+// The target is "<access_token>" or "<access_token>:<secret>" when 加签 signing is enabled.
+type DingTalkNotifier struct {
+	server     string
+	httpClient *http.Client
+}
+
+// NewDingTalkNotifier creates a new DingTalkNotifier (server defaults to https://oapi.dingtalk.com)
+func NewDingTalkNotifier(server string, timeout time.Duration) *DingTalkNotifier {
+	if server == "" {
+		server = "https://oapi.dingtalk.com"
+	}
+	return &DingTalkNotifier{
+		server:     strings.TrimSuffix(server, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the channel name
+func (n *DingTalkNotifier) Name() string {
+	return ChannelDingTalk
+}
+
+// ValidateTarget checks the "<access_token>[:<secret>]" format
+func (n *DingTalkNotifier) ValidateTarget(target string) error {
+	if !dingtalkTargetPattern.MatchString(target) {
+		return fmt.Errorf("invalid dingtalk robot target")
+	}
+	return nil
+}
+
+// Send posts the message as a text message to the robot, signing the request if a secret is set
+func (n *DingTalkNotifier) Send(ctx context.Context, target string, msg Message) error {
+	if err := n.ValidateTarget(target); err != nil {
+		return err
+	}
+
+	token, secret, _ := strings.Cut(target, ":")
+	query := url.Values{"access_token": {token}}
+	if secret != "" {
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		query.Set("timestamp", timestamp)
+		query.Set("sign", signDingTalk(secret, timestamp))
+	}
+
+	payload := map[string]interface{}{
+		"msgtype": "text",
+		"text": map[string]interface{}{
+			"content": truncateBytes(msg.Body, dingtalkMaxContentBytes),
+		},
+	}
+	return postRobot(ctx, n.httpClient, n.server+"/robot/send?"+query.Encode(), payload)
+}
+
+// signDingTalk computes base64(HMAC-SHA256(secret, "<timestamp>\n<secret>"))
+func signDingTalk(secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + secret))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Lanes bounds how many deliveries are sent at once and hands free slots to high-priority
+// messages (e.g., weather warnings) before normal ones (e.g., daily digests), so that warnings
+// don't wait behind the fan-out of a popular reminder time
+type Lanes struct {
+	mu      sync.Mutex
+	slots   int
+	busy    int
+	waiting [2][]chan struct{} // Waiters per lane, in arrival order; index = Priority
+}
+
+// NewLanes creates Lanes allowing the given number of deliveries at once
+func NewLanes(slots int) *Lanes {
+	if slots < 1 {
+		slots = 1
+	}
+	return &Lanes{slots: slots}
+}
+
+// Acquire waits for a free slot in the lane of the priority and returns the function releasing
+// it. A normal message never takes a slot while a high-priority one is waiting. Returns the
+// context error when the context is done first.
+func (l *Lanes) Acquire(ctx context.Context, priority Priority) (func(), error) {
+	lane := l.lane(priority)
+
+	l.mu.Lock()
+	if l.busy < l.slots && l.queued(lane) == 0 {
+		l.busy++
+		l.mu.Unlock()
+		return l.releaseOnce(), nil
+	}
+	ready := make(chan struct{})
+	l.waiting[lane] = append(l.waiting[lane], ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return l.releaseOnce(), nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, w := range l.waiting[lane] {
+			if w == ready {
+				l.waiting[lane] = append(l.waiting[lane][:i], l.waiting[lane][i+1:]...)
+				return nil, ctx.Err()
+			}
+		}
+		// The slot was handed over just as the context ended; pass it on
+		l.release()
+		return nil, ctx.Err()
+	}
+}
+
+// Waiting returns the number of high-priority and normal messages waiting for a slot
+func (l *Lanes) Waiting() (high, normal int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.waiting[PriorityHigh]), len(l.waiting[PriorityNormal])
+}
+
+// lane returns the lane index of a priority
+func (l *Lanes) lane(priority Priority) Priority {
+	if priority >= PriorityHigh {
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
+
+// queued returns the number of waiters that go before a new waiter in the lane
+func (l *Lanes) queued(lane Priority) int {
+	n := len(l.waiting[PriorityHigh])
+	if lane == PriorityNormal {
+		n += len(l.waiting[PriorityNormal])
+	}
+	return n
+}
+
+// releaseOnce returns a release function that is safe to call more than once
+func (l *Lanes) releaseOnce() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.release()
+		})
+	}
+}
+
+// release hands the slot to the first high-priority waiter, else the first normal one, else
+// frees it. The caller must hold the lock.
+func (l *Lanes) release() {
+	for _, lane := range []Priority{PriorityHigh, PriorityNormal} {
+		if len(l.waiting[lane]) > 0 {
+			next := l.waiting[lane][0]
+			l.waiting[lane] = l.waiting[lane][1:]
+			close(next)
+			return
+		}
+	}
+	l.busy--
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// Upload limits of the Bot API
+const (
+	TelegramPhotoLimit  = 10 << 20   // Photos sent through api.telegram.org
+	TelegramUploadLimit = 50 << 20   // Other files sent through api.telegram.org
+	LocalUploadLimit    = 2000 << 20 // Files sent through a local Bot API server
+)
+
+// ErrFileTooLarge is returned when a file exceeds the upload limit of the Bot API endpoint
+var ErrFileTooLarge = errors.New("file too large")
+
+// LocalFiles hands generated files to a local Bot API server (telegram-bot-api --local) through a
+// directory shared with it: files are written there and sent as file:// URIs, which lifts the
+// upload limit to 2000 MB and avoids streaming uploads over HTTP.
+type LocalFiles struct {
+	dir       string // Shared directory as seen by the bot
+	serverDir string // Shared directory as seen by the Bot API server
+}
+
+// NewLocalFiles creates a LocalFiles writing to dir, which the server sees as serverDir ("" = dir)
+func NewLocalFiles(dir, serverDir string) (*LocalFiles, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local file directory: %w", err)
+	}
+	if serverDir == "" {
+		serverDir = dir
+	}
+	if !path.IsAbs(serverDir) {
+		return nil, fmt.Errorf("local file server directory must be absolute: %s", serverDir)
+	}
+	return &LocalFiles{dir: dir, serverDir: serverDir}, nil
+}
+
+// Check verifies that the shared directory is writable
+func (l *LocalFiles) Check() error {
+	f, err := os.CreateTemp(l.dir, ".probe-*")
+	if err != nil {
+		return fmt.Errorf("local file directory is not writable: %w", err)
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
+
+// stage writes data to the shared directory, returning the file to send and a function removing
+// it once sent
+func (l *LocalFiles) stage(data []byte, name string) (tele.File, func(), error) {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return tele.File{}, nil, err
+	}
+	// Keep the extension, which the server uses to guess the MIME type
+	base := hex.EncodeToString(id[:]) + filepath.Ext(name)
+	local := filepath.Join(l.dir, base)
+	if err := os.WriteFile(local, data, 0o644); err != nil {
+		return tele.File{}, nil, fmt.Errorf("failed to write local file: %w", err)
+	}
+	remove := func() { _ = os.Remove(local) }
+	return tele.FromURL("file://" + path.Join(l.serverDir, base)), remove, nil
+}
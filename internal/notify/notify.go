@@ -0,0 +1,92 @@
+// Package notify abstracts message delivery channels (Telegram, email, ntfy, Bark)
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// Channel names
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+	ChannelNtfy     = "ntfy"
+	ChannelBark     = "bark"
+	ChannelWeCom    = "wecom"    // WeChat Work (企业微信) group robot
+	ChannelDingTalk = "dingtalk" // DingTalk (钉钉) group robot
+)
+
+// Priority indicates how urgently a message should be surfaced
+type Priority int
+
+const (
+	PriorityNormal Priority = iota
+	PriorityHigh            // e.g., weather warnings
+)
+
+// Message is a channel-agnostic notification
+type Message struct {
+	Title    string            // Short title (email subject, push title); Telegram ignores it
+	Body     string            // Plain-text body
+	Priority Priority          // Delivery priority
+	Photo    []byte            // Optional image sent to Telegram ahead of the body; other channels ignore it
+	Markup   *tele.ReplyMarkup // Optional inline keyboard of the Telegram message; other channels ignore it
+	Private  bool              // Telegram: protect the message from forwarding and saving; other channels ignore it
+}
+
+// Notifier delivers a message to a channel-specific target (chat ID, email address, topic, device key)
+type Notifier interface {
+	// Name returns the channel name
+	Name() string
+	// ValidateTarget checks whether a target is acceptable for this channel
+	ValidateTarget(target string) error
+	// Send delivers a message to the target
+	Send(ctx context.Context, target string, msg Message) error
+}
+
+// Router dispatches messages to registered notifiers by channel name
+type Router struct {
+	notifiers map[string]Notifier
+}
+
+// NewRouter creates a Router with the given notifiers
+func NewRouter(notifiers ...Notifier) *Router {
+	r := &Router{notifiers: make(map[string]Notifier)}
+	for _, n := range notifiers {
+		r.Register(n)
+	}
+	return r
+}
+
+// Register adds or replaces a notifier
+func (r *Router) Register(n Notifier) {
+	r.notifiers[n.Name()] = n
+}
+
+// Get returns the notifier of a channel
+func (r *Router) Get(channel string) (Notifier, bool) {
+	n, ok := r.notifiers[channel]
+	return n, ok
+}
+
+// Channels returns the names of registered channels in sorted order
+func (r *Router) Channels() []string {
+	names := make([]string, 0, len(r.notifiers))
+	for name := range r.notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Send delivers a message through the named channel
+func (r *Router) Send(ctx context.Context, channel, target string, msg Message) error {
+	n, ok := r.notifiers[channel]
+	if !ok {
+		return fmt.Errorf("notification channel %q is not enabled", channel)
+	}
+	return n.Send(ctx, target, msg)
+}
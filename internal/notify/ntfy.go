@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ntfyTopicPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// NtfyNotifier publishes messages to an ntfy server; the target is a topic name
+type NtfyNotifier struct {
+	server     string
+	token      string
+	httpClient *http.Client
+}
+
+// NewNtfyNotifier creates a new NtfyNotifier (server defaults to https://ntfy.sh)
+func NewNtfyNotifier(server, token string, timeout time.Duration) *NtfyNotifier {
+	if server == "" {
+		server = "https://ntfy.sh"
+	}
+	return &NtfyNotifier{
+		server:     strings.TrimSuffix(server, "/"),
+		token:      token,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the channel name
+func (n *NtfyNotifier) Name() string {
+	return ChannelNtfy
+}
+
+// ValidateTarget checks that the target is a valid topic name
+func (n *NtfyNotifier) ValidateTarget(target string) error {
+	if !ntfyTopicPattern.MatchString(target) {
+		return fmt.Errorf("invalid ntfy topic: %s", target)
+	}
+	return nil
+}
+
+// Send publishes the message to the topic
+func (n *NtfyNotifier) Send(ctx context.Context, target string, msg Message) error {
+	if err := n.ValidateTarget(target); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.server+"/"+target, strings.NewReader(msg.Body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if msg.Title != "" {
+		req.Header.Set("Title", mime.BEncoding.Encode("utf-8", msg.Title))
+	}
+	if msg.Priority == PriorityHigh {
+		req.Header.Set("Priority", "high")
+		req.Header.Set("Tags", "warning")
+	}
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("ntfy request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("ntfy returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
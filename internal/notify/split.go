@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+const (
+	// TelegramTextLimit is the maximum length of a Telegram message in UTF-16 code units
+	TelegramTextLimit = 4096
+	// maxTextParts is the number of messages a text may be split into before it is sent as a document
+	maxTextParts = 4
+	// overflowNote ends the preview message of a text sent as a document
+	overflowNote = "\n\n📎 内容较长，完整内容见附件"
+)
+
+// splitSeparators are the boundaries a text is split at, in order of preference
+var splitSeparators = []string{"\n\n", "\n", "。", " "}
+
+// SplitText splits text into parts of at most limit UTF-16 code units, preferring
+// paragraph, line and sentence boundaries over cutting in the middle of a line
+func SplitText(text string, limit int) []string {
+	var parts []string
+	for textLen(text) > limit {
+		cut := cutIndex(text, limit)
+		if part := strings.TrimRight(text[:cut], "\n"); part != "" {
+			parts = append(parts, part)
+		}
+		text = strings.TrimLeft(text[cut:], "\n")
+	}
+	if text != "" || len(parts) == 0 {
+		parts = append(parts, text)
+	}
+	return parts
+}
+
+// SendText sends a text message of any length. Texts over the Telegram limit are split
+// into several messages, or, beyond maxTextParts messages, sent as a preview followed by
+// the full text as a .txt document. Reply markup is attached to the last message only,
+// which is also the message returned.
+func SendText(b *tele.Bot, to tele.Recipient, text string, opts ...interface{}) (*tele.Message, error) {
+	if textLen(text) <= TelegramTextLimit {
+		return b.Send(to, text, opts...)
+	}
+
+	parts := SplitText(text, TelegramTextLimit)
+	if len(parts) <= maxTextParts {
+		var last *tele.Message
+		for i, part := range parts {
+			partOpts := opts
+			if i < len(parts)-1 {
+				partOpts = withoutMarkup(opts)
+			}
+			msg, err := b.Send(to, part, partOpts...)
+			if err != nil {
+				return nil, err
+			}
+			last = msg
+		}
+		return last, nil
+	}
+
+	preview := SplitText(text, TelegramTextLimit-textLen(overflowNote))[0] + overflowNote
+	if _, err := b.Send(to, preview, withoutMarkup(opts)...); err != nil {
+		return nil, err
+	}
+	doc := &tele.Document{
+		File:     tele.FromReader(strings.NewReader(text)),
+		FileName: "message.txt",
+		MIME:     "text/plain",
+	}
+	return b.Send(to, doc, opts...)
+}
+
+// cutIndex returns the byte index at which to split text so that the head fits within limit
+func cutIndex(text string, limit int) int {
+	n, end := 0, 0
+	for i, r := range text {
+		l := utf16.RuneLen(r)
+		if l < 0 {
+			l = 1
+		}
+		if n+l > limit {
+			break
+		}
+		n += l
+		end = i + utf8.RuneLen(r)
+	}
+
+	head := text[:end]
+	for _, sep := range splitSeparators {
+		// Only split at a boundary in the second half, to avoid tiny parts
+		if i := strings.LastIndex(head, sep); i >= len(head)/2 {
+			return i + len(sep)
+		}
+	}
+	return end
+}
+
+// textLen returns the length of text as counted by Telegram (UTF-16 code units)
+func textLen(text string) int {
+	n := 0
+	for _, r := range text {
+		if l := utf16.RuneLen(r); l > 0 {
+			n += l
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// withoutMarkup drops reply markup from send options, for all but the last message of a split text
+func withoutMarkup(opts []interface{}) []interface{} {
+	var out []interface{}
+	for _, opt := range opts {
+		if _, ok := opt.(*tele.ReplyMarkup); ok {
+			continue
+		}
+		out = append(out, opt)
+	}
+	return out
+}
@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// TelegramNotifier delivers messages to Telegram chats; the target is the chat ID.
+// It sends through the primary bot; For selects one of the additional named bots.
+type TelegramNotifier struct {
+	bot   *tele.Bot
+	named map[string]*TelegramNotifier
+	files *LocalFiles // Shared directory of a local Bot API server; nil = upload files
+}
+
+// Document is a generated file sent to a chat
+type Document struct {
+	Data     []byte
+	FileName string
+	MIME     string
+	Caption  string
+}
+
+// NewTelegramNotifier creates a new TelegramNotifier sending through the primary bot
+func NewTelegramNotifier(bot *tele.Bot) *TelegramNotifier {
+	return &TelegramNotifier{bot: bot, named: make(map[string]*TelegramNotifier)}
+}
+
+// AddBot registers an additional bot under its name; it must be called before sending
+func (n *TelegramNotifier) AddBot(name string, bot *tele.Bot) {
+	n.named[name] = &TelegramNotifier{bot: bot}
+}
+
+// SetLocalFiles sends files through the shared directory of a local Bot API server
+func (n *TelegramNotifier) SetLocalFiles(files *LocalFiles) {
+	n.files = files
+}
+
+// For returns the notifier of the named bot ("" = the primary bot). Unknown names fall back to
+// the primary bot, e.g. for users of a bot that was removed from the configuration.
+func (n *TelegramNotifier) For(name string) *TelegramNotifier {
+	if named, ok := n.named[name]; ok {
+		return named
+	}
+	return n
+}
+
+// Name returns the channel name
+func (n *TelegramNotifier) Name() string {
+	return ChannelTelegram
+}
+
+// ValidateTarget checks that the target is a numeric chat ID
+func (n *TelegramNotifier) ValidateTarget(target string) error {
+	if _, err := strconv.ParseInt(target, 10, 64); err != nil {
+		return fmt.Errorf("invalid chat ID: %s", target)
+	}
+	return nil
+}
+
+// Send delivers the message body to the chat
+func (n *TelegramNotifier) Send(ctx context.Context, target string, msg Message) error {
+	chatID, err := strconv.ParseInt(target, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid chat ID: %s", target)
+	}
+	return n.SendTo(chatID, msg)
+}
+
+// SendTo delivers the message body to a chat by ID, splitting bodies over the Telegram limit
+func (n *TelegramNotifier) SendTo(chatID int64, msg Message) error {
+	_, err := n.SendMessage(chatID, msg)
+	return err
+}
+
+// SendMessage is like SendTo, but returns the last Telegram message sent
+func (n *TelegramNotifier) SendMessage(chatID int64, msg Message) (*tele.Message, error) {
+	var opts []interface{}
+	if msg.Markup != nil {
+		opts = append(opts, msg.Markup)
+	}
+	if msg.Private {
+		opts = append(opts, tele.Protected)
+	}
+	sent, err := SendText(n.bot, &tele.User{ID: chatID}, msg.Body, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send telegram message: %w", err)
+	}
+	return sent, nil
+}
+
+// CanPin reports whether the bot may pin messages in a chat. Bots can always pin in private
+// chats; in groups they must be the creator or an administrator with the pin messages right.
+func (n *TelegramNotifier) CanPin(chatID int64) (bool, error) {
+	if chatID > 0 {
+		return true, nil
+	}
+	member, err := n.bot.ChatMemberOf(tele.ChatID(chatID), n.bot.Me)
+	if err != nil {
+		return false, fmt.Errorf("failed to get bot permissions: %w", err)
+	}
+	return member.Role == tele.Creator || (member.Role == tele.Administrator && member.CanPinMessages), nil
+}
+
+// Pin pins a message in a chat without notifying the members
+func (n *TelegramNotifier) Pin(chatID int64, messageID int) error {
+	msg := &tele.StoredMessage{MessageID: strconv.Itoa(messageID), ChatID: chatID}
+	if err := n.bot.Pin(msg, tele.Silent); err != nil {
+		return fmt.Errorf("failed to pin telegram message: %w", err)
+	}
+	return nil
+}
+
+// Unpin unpins a message in a chat
+func (n *TelegramNotifier) Unpin(chatID int64, messageID int) error {
+	if err := n.bot.Unpin(tele.ChatID(chatID), messageID); err != nil {
+		return fmt.Errorf("failed to unpin telegram message: %w", err)
+	}
+	return nil
+}
+
+// SendVoice delivers audio to a chat as a voice message
+func (n *TelegramNotifier) SendVoice(chatID int64, audio []byte, mime string) error {
+	file, done, err := n.file(audio, "voice"+mimeExtension(mime), TelegramUploadLimit)
+	if err != nil {
+		return fmt.Errorf("failed to send telegram voice: %w", err)
+	}
+	defer done()
+
+	voice := &tele.Voice{File: file, MIME: mime}
+	if _, err := n.bot.Send(&tele.User{ID: chatID}, voice); err != nil {
+		return fmt.Errorf("failed to send telegram voice: %w", err)
+	}
+	return nil
+}
+
+// SendPhoto delivers an image to a chat. Images over the photo limit are sent as a document.
+func (n *TelegramNotifier) SendPhoto(chatID int64, image []byte) error {
+	mime := http.DetectContentType(image)
+	if len(image) > TelegramPhotoLimit {
+		_, err := n.SendDocument(&tele.User{ID: chatID}, Document{Data: image, FileName: "image" + mimeExtension(mime), MIME: mime})
+		return err
+	}
+
+	file, done, err := n.file(image, "image"+mimeExtension(mime), TelegramPhotoLimit)
+	if err != nil {
+		return fmt.Errorf("failed to send telegram photo: %w", err)
+	}
+	defer done()
+
+	photo := &tele.Photo{File: file}
+	if _, err := n.bot.Send(&tele.User{ID: chatID}, photo); err != nil {
+		return fmt.Errorf("failed to send telegram photo: %w", err)
+	}
+	return nil
+}
+
+// SendDocument delivers a file to a chat
+func (n *TelegramNotifier) SendDocument(to tele.Recipient, doc Document) (*tele.Message, error) {
+	file, done, err := n.file(doc.Data, doc.FileName, TelegramUploadLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send telegram document: %w", err)
+	}
+	defer done()
+
+	sent, err := n.bot.Send(to, &tele.Document{File: file, FileName: doc.FileName, MIME: doc.MIME, Caption: doc.Caption})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send telegram document: %w", err)
+	}
+	return sent, nil
+}
+
+// file returns data as a file to send: staged in the shared directory of a local Bot API server
+// when configured, else uploaded within the limit of api.telegram.org. done must be called after
+// sending.
+func (n *TelegramNotifier) file(data []byte, name string, limit int) (tele.File, func(), error) {
+	if n.files == nil {
+		if len(data) > limit {
+			return tele.File{}, nil, fmt.Errorf("%w: %d bytes exceed %d", ErrFileTooLarge, len(data), limit)
+		}
+		return tele.FromReader(bytes.NewReader(data)), func() {}, nil
+	}
+	if len(data) > LocalUploadLimit {
+		return tele.File{}, nil, fmt.Errorf("%w: %d bytes exceed %d", ErrFileTooLarge, len(data), LocalUploadLimit)
+	}
+	return n.files.stage(data, name)
+}
+
+// mimeExtension returns the file extension of common generated media types
+func mimeExtension(mime string) string {
+	switch strings.TrimSpace(strings.Split(mime, ";")[0]) {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	case "audio/ogg":
+		return ".ogg"
+	case "audio/mpeg":
+		return ".mp3"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,62 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var wecomKeyPattern = regexp.MustCompile(`^[A-Za-z0-9-]{8,64}$`)
+
+// wecomMaxContentBytes is the text message limit of WeChat Work group robots
+const wecomMaxContentBytes = 2048
+
+// WeComNotifier posts messages to WeChat Work (企业微信) group robots; the target is the robot key
+type WeComNotifier struct {
+	server     string
+	httpClient *http.Client
+}
+
+// NewWeComNotifier creates a new WeComNotifier (server defaults to https://qyapi.weixin.qq.com)
+func NewWeComNotifier(server string, timeout time.Duration) *WeComNotifier {
+	if server == "" {
+		server = "https://qyapi.weixin.qq.com"
+	}
+	return &WeComNotifier{
+		server:     strings.TrimSuffix(server, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the channel name
+func (n *WeComNotifier) Name() string {
+	return ChannelWeCom
+}
+
+// ValidateTarget checks that the target looks like a robot key
+func (n *WeComNotifier) ValidateTarget(target string) error {
+	if !wecomKeyPattern.MatchString(target) {
+		return fmt.Errorf("invalid wecom robot key")
+	}
+	return nil
+}
+
+// Send posts the message as a text message to the robot
+func (n *WeComNotifier) Send(ctx context.Context, target string, msg Message) error {
+	if err := n.ValidateTarget(target); err != nil {
+		return err
+	}
+
+	endpoint := n.server + "/cgi-bin/webhook/send?key=" + url.QueryEscape(target)
+	payload := map[string]interface{}{
+		"msgtype": "text",
+		"text": map[string]interface{}{
+			"content": truncateBytes(msg.Body, wecomMaxContentBytes),
+		},
+	}
+	return postRobot(ctx, n.httpClient, endpoint, payload)
+}
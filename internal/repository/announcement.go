@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AnnouncementRepository handles announcement data access
+type AnnouncementRepository struct {
+	db *gorm.DB
+}
+
+// NewAnnouncementRepository creates a new AnnouncementRepository
+func NewAnnouncementRepository(db *gorm.DB) *AnnouncementRepository {
+	return &AnnouncementRepository{db: db}
+}
+
+// Create schedules a new announcement
+func (r *AnnouncementRepository) Create(a *model.Announcement) error {
+	if err := r.db.Create(a).Error; err != nil {
+		logger.Error("Failed to create announcement", zap.Error(err))
+		return fmt.Errorf("failed to create announcement: %w", err)
+	}
+
+	logger.Info("Announcement scheduled",
+		zap.Uint("announcement_id", a.ID),
+		zap.String("cities", a.Cities),
+		zap.Time("scheduled_at", a.ScheduledAt))
+	return nil
+}
+
+// FindByID finds an announcement by ID
+func (r *AnnouncementRepository) FindByID(id uint) (*model.Announcement, error) {
+	var a model.Announcement
+	if err := r.db.First(&a, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error("Failed to find announcement",
+			zap.Uint("announcement_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find announcement: %w", err)
+	}
+	return &a, nil
+}
+
+// List retrieves announcements, newest first, with pagination along with the total count
+func (r *AnnouncementRepository) List(offset, limit int) ([]model.Announcement, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.Announcement{}).Count(&total).Error; err != nil {
+		logger.Error("Failed to count announcements", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count announcements: %w", err)
+	}
+
+	var items []model.Announcement
+	if err := r.db.Order("id DESC").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		logger.Error("Failed to list announcements", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list announcements: %w", err)
+	}
+	return items, total, nil
+}
+
+// ClaimDue marks pending announcements scheduled at or before now as sending and returns them.
+// An announcement is claimed by at most one caller.
+func (r *AnnouncementRepository) ClaimDue(now time.Time) ([]model.Announcement, error) {
+	var due []model.Announcement
+	if err := r.db.Where("status = ? AND scheduled_at <= ?", model.AnnouncementPending, now).
+		Order("scheduled_at").
+		Find(&due).Error; err != nil {
+		logger.Error("Failed to find due announcements", zap.Error(err))
+		return nil, fmt.Errorf("failed to find due announcements: %w", err)
+	}
+
+	claimed := due[:0]
+	for _, a := range due {
+		result := r.db.Model(&model.Announcement{}).
+			Where("id = ? AND status = ?", a.ID, model.AnnouncementPending).
+			Update("status", model.AnnouncementSending)
+		if result.Error != nil {
+			logger.Error("Failed to claim announcement",
+				zap.Uint("announcement_id", a.ID),
+				zap.Error(result.Error))
+			return claimed, fmt.Errorf("failed to claim announcement: %w", result.Error)
+		}
+		if result.RowsAffected == 1 {
+			a.Status = model.AnnouncementSending
+			claimed = append(claimed, a)
+		}
+	}
+	return claimed, nil
+}
+
+// MarkSent records the delivery result of an announcement
+func (r *AnnouncementRepository) MarkSent(id uint, recipients, failures int) error {
+	err := r.db.Model(&model.Announcement{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     model.AnnouncementSent,
+		"sent_at":    time.Now(),
+		"recipients": recipients,
+		"failures":   failures,
+	}).Error
+	if err != nil {
+		logger.Error("Failed to mark announcement sent",
+			zap.Uint("announcement_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to mark announcement sent: %w", err)
+	}
+	return nil
+}
+
+// Cancel cancels a pending announcement, reporting false if it is no longer pending
+func (r *AnnouncementRepository) Cancel(id uint) (bool, error) {
+	result := r.db.Model(&model.Announcement{}).
+		Where("id = ? AND status = ?", id, model.AnnouncementPending).
+		Update("status", model.AnnouncementCancelled)
+	if result.Error != nil {
+		logger.Error("Failed to cancel announcement",
+			zap.Uint("announcement_id", id),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to cancel announcement: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
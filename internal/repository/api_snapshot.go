@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// APISnapshotRepository handles stored QWeather response data access
+type APISnapshotRepository struct {
+	db *gorm.DB
+}
+
+// NewAPISnapshotRepository creates a new APISnapshotRepository
+func NewAPISnapshotRepository(db *gorm.DB) *APISnapshotRepository {
+	return &APISnapshotRepository{db: db}
+}
+
+// Upsert stores a snapshot, replacing the same request's earlier snapshot of the day
+func (r *APISnapshotRepository) Upsert(snapshot *model.APISnapshot) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}, {Name: "request"}},
+		DoUpdates: clause.AssignmentColumns([]string{"location", "payload", "size", "updated_at"}),
+	}).Create(snapshot).Error
+	if err != nil {
+		return fmt.Errorf("failed to save api snapshot: %w", err)
+	}
+	return nil
+}
+
+// Find returns the snapshot of a request on a date, or nil if there is none
+func (r *APISnapshotRepository) Find(date, request string) (*model.APISnapshot, error) {
+	var snapshot model.APISnapshot
+	err := r.db.Where("date = ? AND request = ?", date, request).First(&snapshot).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find api snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// FindByID returns a snapshot by ID, or nil if there is none
+func (r *APISnapshotRepository) FindByID(id uint) (*model.APISnapshot, error) {
+	var snapshot model.APISnapshot
+	err := r.db.First(&snapshot, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find api snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// List returns the snapshots of a date without payloads, optionally only those of a location
+func (r *APISnapshotRepository) List(date, location string) ([]model.APISnapshot, error) {
+	query := r.db.Omit("payload").Where("date = ?", date)
+	if location != "" {
+		query = query.Where("location = ?", location)
+	}
+	var snapshots []model.APISnapshot
+	if err := query.Order("location, request").Find(&snapshots).Error; err != nil {
+		return nil, fmt.Errorf("failed to list api snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// DeleteBefore removes snapshots of dates before the given date (YYYY-MM-DD)
+func (r *APISnapshotRepository) DeleteBefore(date string) error {
+	result := r.db.Where("date < ?", date).Delete(&model.APISnapshot{})
+	if result.Error != nil {
+		logger.Error("Failed to prune api snapshots", zap.Error(result.Error))
+		return fmt.Errorf("failed to prune api snapshots: %w", result.Error)
+	}
+
+	logger.Debug("API snapshots pruned", zap.Int64("count", result.RowsAffected))
+	return nil
+}
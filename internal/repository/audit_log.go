@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AuditLogFilter narrows down audit log queries; empty fields match everything
+type AuditLogFilter struct {
+	Actor  string
+	Action string
+	Target string
+	Since  time.Time
+}
+
+// AuditLogRepository handles the append-only audit log; it offers no way to change or remove entries
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogRepository creates a new AuditLogRepository
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+// Create appends an entry to the audit log
+func (r *AuditLogRepository) Create(entry *model.AuditLog) error {
+	if err := r.db.Create(entry).Error; err != nil {
+		logger.Error("Failed to create audit log",
+			zap.String("actor", entry.Actor),
+			zap.String("action", entry.Action),
+			zap.Error(err))
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// List retrieves entries matching the filter, newest first, with pagination along with the total count
+func (r *AuditLogRepository) List(filter AuditLogFilter, offset, limit int) ([]model.AuditLog, int64, error) {
+	query := r.db.Model(&model.AuditLog{})
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Target != "" {
+		query = query.Where("target = ?", filter.Target)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.Error("Failed to count audit logs", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	var items []model.AuditLog
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		logger.Error("Failed to list audit logs", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	return items, total, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CalendarFeedRepository handles database operations for users' iCalendar feeds
+type CalendarFeedRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarFeedRepository creates a new CalendarFeedRepository
+func NewCalendarFeedRepository(db *gorm.DB) *CalendarFeedRepository {
+	return &CalendarFeedRepository{db: db}
+}
+
+// Create adds a calendar feed
+func (r *CalendarFeedRepository) Create(feed *model.CalendarFeed) error {
+	if err := r.db.Create(feed).Error; err != nil {
+		logger.Error("Failed to create calendar feed",
+			zap.Uint("user_id", feed.UserID),
+			zap.Error(err))
+		return fmt.Errorf("failed to create calendar feed: %w", err)
+	}
+	return nil
+}
+
+// FindByUser returns a user's calendar feeds, ordered by creation
+func (r *CalendarFeedRepository) FindByUser(userID uint) ([]model.CalendarFeed, error) {
+	var feeds []model.CalendarFeed
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&feeds).Error; err != nil {
+		return nil, fmt.Errorf("failed to find calendar feeds: %w", err)
+	}
+	return feeds, nil
+}
+
+// FindAll returns the calendar feeds of all users that are not deleted, without their content
+func (r *CalendarFeedRepository) FindAll() ([]model.CalendarFeed, error) {
+	var feeds []model.CalendarFeed
+	err := r.db.Omit("content").
+		Joins("JOIN users ON users.id = calendar_feeds.user_id AND users.deleted_at IS NULL").
+		Order("calendar_feeds.id").
+		Find(&feeds).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find calendar feeds: %w", err)
+	}
+	return feeds, nil
+}
+
+// SaveSync records a successful sync of a feed with the fetched document
+func (r *CalendarFeedRepository) SaveSync(id uint, content string, syncedAt time.Time) error {
+	err := r.db.Model(&model.CalendarFeed{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"content": content, "synced_at": syncedAt, "sync_error": ""}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save calendar feed: %w", err)
+	}
+	return nil
+}
+
+// SaveSyncError records a failed sync of a feed, keeping the document of the last successful one
+func (r *CalendarFeedRepository) SaveSyncError(id uint, syncErr string) error {
+	if err := r.db.Model(&model.CalendarFeed{}).Where("id = ?", id).Update("sync_error", syncErr).Error; err != nil {
+		return fmt.Errorf("failed to save calendar feed: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a calendar feed of a user, reporting whether it existed
+func (r *CalendarFeedRepository) Delete(userID, id uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.CalendarFeed{})
+	if result.Error != nil {
+		logger.Error("Failed to delete calendar feed",
+			zap.Uint("user_id", userID),
+			zap.Uint("feed_id", id),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to delete calendar feed: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
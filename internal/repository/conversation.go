@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ConversationRepository handles conversation state data access
+type ConversationRepository struct {
+	db *gorm.DB
+}
+
+// NewConversationRepository creates a new ConversationRepository
+func NewConversationRepository(db *gorm.DB) *ConversationRepository {
+	return &ConversationRepository{db: db}
+}
+
+// FindByChatID returns the conversation of a chat with a bot, or nil if there is none
+func (r *ConversationRepository) FindByChatID(bot string, chatID int64) (*model.Conversation, error) {
+	var conv model.Conversation
+	err := r.db.Where("bot = ? AND chat_id = ?", bot, chatID).First(&conv).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error("Failed to find conversation",
+			zap.Int64("chat_id", chatID),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find conversation: %w", err)
+	}
+	return &conv, nil
+}
+
+// Save creates or replaces the conversation of a chat with a bot
+func (r *ConversationRepository) Save(conv *model.Conversation) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "bot"}, {Name: "chat_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"flow", "step", "data", "expires_at", "updated_at"}),
+	}).Create(conv).Error
+	if err != nil {
+		logger.Error("Failed to save conversation",
+			zap.Int64("chat_id", conv.ChatID),
+			zap.String("flow", conv.Flow),
+			zap.Error(err))
+		return fmt.Errorf("failed to save conversation: %w", err)
+	}
+
+	logger.Debug("Conversation saved",
+		zap.Int64("chat_id", conv.ChatID),
+		zap.String("flow", conv.Flow),
+		zap.String("step", conv.Step))
+	return nil
+}
+
+// Delete removes the conversation of a chat with a bot, reporting whether one existed
+func (r *ConversationRepository) Delete(bot string, chatID int64) (bool, error) {
+	result := r.db.Where("bot = ? AND chat_id = ?", bot, chatID).Delete(&model.Conversation{})
+	if result.Error != nil {
+		logger.Error("Failed to delete conversation",
+			zap.Int64("chat_id", chatID),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to delete conversation: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteExpired removes conversations that expired before the given time
+func (r *ConversationRepository) DeleteExpired(now time.Time) error {
+	result := r.db.Where("expires_at < ?", now).Delete(&model.Conversation{})
+	if result.Error != nil {
+		logger.Error("Failed to prune expired conversations", zap.Error(result.Error))
+		return fmt.Errorf("failed to prune expired conversations: %w", result.Error)
+	}
+
+	logger.Debug("Expired conversations pruned", zap.Int64("count", result.RowsAffected))
+	return nil
+}
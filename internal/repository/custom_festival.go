@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CustomFestivalRepository handles database operations for users' custom festivals
+type CustomFestivalRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomFestivalRepository creates a new CustomFestivalRepository
+func NewCustomFestivalRepository(db *gorm.DB) *CustomFestivalRepository {
+	return &CustomFestivalRepository{db: db}
+}
+
+// Create adds a custom festival
+func (r *CustomFestivalRepository) Create(festival *model.CustomFestival) error {
+	if err := r.db.Create(festival).Error; err != nil {
+		logger.Error("Failed to create custom festival",
+			zap.Uint("user_id", festival.UserID),
+			zap.Error(err))
+		return fmt.Errorf("failed to create custom festival: %w", err)
+	}
+	return nil
+}
+
+// FindByUser returns a user's custom festivals, ordered by creation
+func (r *CustomFestivalRepository) FindByUser(userID uint) ([]model.CustomFestival, error) {
+	var festivals []model.CustomFestival
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&festivals).Error; err != nil {
+		return nil, fmt.Errorf("failed to find custom festivals: %w", err)
+	}
+	return festivals, nil
+}
+
+// Delete removes a custom festival of a user, reporting whether it existed
+func (r *CustomFestivalRepository) Delete(userID, id uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.CustomFestival{})
+	if result.Error != nil {
+		logger.Error("Failed to delete custom festival",
+			zap.Uint("user_id", userID),
+			zap.Uint("festival_id", id),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to delete custom festival: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DashboardRepository handles web dashboard login codes and sessions
+type DashboardRepository struct {
+	db *gorm.DB
+}
+
+// NewDashboardRepository creates a new DashboardRepository
+func NewDashboardRepository(db *gorm.DB) *DashboardRepository {
+	return &DashboardRepository{db: db}
+}
+
+// ReplaceCode stores a user's new login code, removing the codes issued to the user before
+func (r *DashboardRepository) ReplaceCode(code *model.DashboardCode) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", code.UserID).Delete(&model.DashboardCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(code).Error
+	})
+	if err != nil {
+		logger.Error("Failed to save dashboard code", zap.Uint("user_id", code.UserID), zap.Error(err))
+		return fmt.Errorf("failed to save dashboard code: %w", err)
+	}
+	return nil
+}
+
+// ClaimCode consumes an unexpired login code and returns its user ID. Returns false when there is
+// no such code or it was consumed concurrently.
+func (r *DashboardRepository) ClaimCode(codeHash string, now time.Time) (uint, bool, error) {
+	var code model.DashboardCode
+	err := r.db.Where("code_hash = ? AND expires_at > ?", codeHash, now).First(&code).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to find dashboard code: %w", err)
+	}
+	res := r.db.Where("id = ?", code.ID).Delete(&model.DashboardCode{})
+	if res.Error != nil {
+		logger.Error("Failed to consume dashboard code", zap.Uint("user_id", code.UserID), zap.Error(res.Error))
+		return 0, false, fmt.Errorf("failed to consume dashboard code: %w", res.Error)
+	}
+	return code.UserID, res.RowsAffected == 1, nil
+}
+
+// CreateSession stores a dashboard session
+func (r *DashboardRepository) CreateSession(session *model.DashboardSession) error {
+	if err := r.db.Create(session).Error; err != nil {
+		logger.Error("Failed to create dashboard session", zap.Uint("user_id", session.UserID), zap.Error(err))
+		return fmt.Errorf("failed to create dashboard session: %w", err)
+	}
+	return nil
+}
+
+// FindSession returns the unexpired session with the token hash, nil when there is none
+func (r *DashboardRepository) FindSession(tokenHash string, now time.Time) (*model.DashboardSession, error) {
+	var session model.DashboardSession
+	err := r.db.Where("token_hash = ? AND expires_at > ?", tokenHash, now).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find dashboard session: %w", err)
+	}
+	return &session, nil
+}
+
+// DeleteSession removes the session with the token hash
+func (r *DashboardRepository) DeleteSession(tokenHash string) error {
+	if err := r.db.Where("token_hash = ?", tokenHash).Delete(&model.DashboardSession{}).Error; err != nil {
+		return fmt.Errorf("failed to delete dashboard session: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes expired login codes and sessions
+func (r *DashboardRepository) DeleteExpired(now time.Time) (int64, error) {
+	codes := r.db.Where("expires_at <= ?", now).Delete(&model.DashboardCode{})
+	if codes.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired dashboard codes: %w", codes.Error)
+	}
+	sessions := r.db.Where("expires_at <= ?", now).Delete(&model.DashboardSession{})
+	if sessions.Error != nil {
+		return codes.RowsAffected, fmt.Errorf("failed to delete expired dashboard sessions: %w", sessions.Error)
+	}
+	return codes.RowsAffected + sessions.RowsAffected, nil
+}
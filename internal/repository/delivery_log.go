@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DeliveryStats summarizes reminder deliveries over a period
+type DeliveryStats struct {
+	Since     time.Time        `json:"since"`
+	Total     int64            `json:"total"`
+	Succeeded int64            `json:"succeeded"`
+	Failed    int64            `json:"failed"`
+	ByKind    map[string]int64 `json:"by_kind"`
+}
+
+// DeliveryLogRepository handles delivery log data access
+type DeliveryLogRepository struct {
+	db *gorm.DB
+}
+
+// NewDeliveryLogRepository creates a new DeliveryLogRepository
+func NewDeliveryLogRepository(db *gorm.DB) *DeliveryLogRepository {
+	return &DeliveryLogRepository{db: db}
+}
+
+// Create records a delivery attempt
+func (r *DeliveryLogRepository) Create(log *model.DeliveryLog) error {
+	logger.Debug("DeliveryLogRepository.Create called",
+		zap.Uint("subscription_id", log.SubscriptionID),
+		zap.String("kind", log.Kind),
+		zap.Bool("success", log.Success))
+
+	if err := r.db.Create(log).Error; err != nil {
+		logger.Error("Failed to create delivery log",
+			zap.Uint("subscription_id", log.SubscriptionID),
+			zap.Error(err))
+		return fmt.Errorf("failed to create delivery log: %w", err)
+	}
+	return nil
+}
+
+// FindBySubscriptionID retrieves the most recent delivery logs for a subscription
+func (r *DeliveryLogRepository) FindBySubscriptionID(subscriptionID uint, limit int) ([]model.DeliveryLog, error) {
+	logger.Debug("DeliveryLogRepository.FindBySubscriptionID called",
+		zap.Uint("subscription_id", subscriptionID),
+		zap.Int("limit", limit))
+
+	var logs []model.DeliveryLog
+	err := r.db.Where("subscription_id = ?", subscriptionID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&logs).Error
+	if err != nil {
+		logger.Error("Failed to find delivery logs",
+			zap.Uint("subscription_id", subscriptionID),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find delivery logs: %w", err)
+	}
+	return logs, nil
+}
+
+// FindByMessage retrieves the delivery that sent the given Telegram message.
+// Returns nil when the message is not a reminder.
+func (r *DeliveryLogRepository) FindByMessage(chatID int64, messageID int) (*model.DeliveryLog, error) {
+	logger.Debug("DeliveryLogRepository.FindByMessage called",
+		zap.Int64("chat_id", chatID),
+		zap.Int("message_id", messageID))
+
+	var log model.DeliveryLog
+	err := r.db.Where("chat_id = ? AND message_id = ? AND success = ?", chatID, messageID, true).First(&log).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error("Failed to find delivery log by message",
+			zap.Int64("chat_id", chatID),
+			zap.Int("message_id", messageID),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find delivery log: %w", err)
+	}
+	return &log, nil
+}
+
+// Stats aggregates delivery counts since the given time
+func (r *DeliveryLogRepository) Stats(since time.Time) (*DeliveryStats, error) {
+	logger.Debug("DeliveryLogRepository.Stats called",
+		zap.Time("since", since))
+
+	var rows []struct {
+		Kind    string
+		Success bool
+		Count   int64
+	}
+	err := r.db.Model(&model.DeliveryLog{}).
+		Select("kind, success, COUNT(*) AS count").
+		Where("created_at >= ?", since).
+		Group("kind, success").
+		Scan(&rows).Error
+	if err != nil {
+		logger.Error("Failed to aggregate delivery stats",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to aggregate delivery stats: %w", err)
+	}
+
+	stats := &DeliveryStats{Since: since, ByKind: make(map[string]int64)}
+	for _, row := range rows {
+		stats.Total += row.Count
+		stats.ByKind[row.Kind] += row.Count
+		if row.Success {
+			stats.Succeeded += row.Count
+		} else {
+			stats.Failed += row.Count
+		}
+	}
+	return stats, nil
+}
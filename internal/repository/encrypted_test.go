@@ -0,0 +1,74 @@
+package repository_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/migration"
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/fieldcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Todo text kept outside the todos table, in photo proposals and dialog state, is sealed at rest
+// including when it is rewritten
+func TestTodoTextIsEncryptedAtRest(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migration.Run(db); err != nil {
+		t.Fatal(err)
+	}
+	cipher, err := fieldcrypt.New([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	model.SetFieldCipher(cipher, true)
+	defer model.SetFieldCipher(nil, false)
+
+	// raw returns a column as stored
+	raw := func(table, column string, id uint) string {
+		var value string
+		if err := db.Table(table).Where("id = ?", id).Select(column).Scan(&value).Error; err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+	now := time.Now()
+
+	proposals := repository.NewTodoProposalRepository(db)
+	proposal := &model.TodoProposal{UserID: 1, SubscriptionID: 1, Items: `[{"content":"交电费"}]`, ExpiresAt: now.Add(time.Hour)}
+	if err := proposals.Create(proposal); err != nil {
+		t.Fatal(err)
+	}
+	if err := proposals.UpdateItems(proposal.ID, `[{"content":"交电费","added":true}]`); err != nil {
+		t.Fatal(err)
+	}
+	if stored := raw("todo_proposals", "items", proposal.ID); !fieldcrypt.IsSealed(stored) || strings.Contains(stored, "交电费") {
+		t.Errorf("stored proposal items = %q, want sealed", stored)
+	}
+	found, err := proposals.FindActive(proposal.ID, 1, now)
+	if err != nil || found == nil || found.Items != `[{"content":"交电费","added":true}]` {
+		t.Errorf("FindActive = %+v, %v", found, err)
+	}
+
+	conversations := repository.NewConversationRepository(db)
+	for _, data := range []string{`{"args":"add 买牛奶"}`, `{"args":"add 交电费"}`} {
+		conv := &model.Conversation{ChatID: 42, Flow: "city_choice", Step: "city", Data: data, ExpiresAt: now.Add(time.Hour)}
+		if err := conversations.Save(conv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	conv, err := conversations.FindByChatID("", 42)
+	if err != nil || conv == nil || conv.Data != `{"args":"add 交电费"}` {
+		t.Fatalf("FindByChatID = %+v, %v", conv, err)
+	}
+	if stored := raw("conversations", "data", conv.ID); !fieldcrypt.IsSealed(stored) || strings.Contains(stored, "交电费") {
+		t.Errorf("stored conversation data = %q, want sealed", stored)
+	}
+}
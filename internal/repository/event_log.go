@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"gorm.io/gorm"
+)
+
+// EventLogRepository appends business events to the event_logs table
+type EventLogRepository struct {
+	db *gorm.DB
+}
+
+// NewEventLogRepository creates a new EventLogRepository
+func NewEventLogRepository(db *gorm.DB) *EventLogRepository {
+	return &EventLogRepository{db: db}
+}
+
+// Create appends an event
+func (r *EventLogRepository) Create(entry *model.EventLog) error {
+	if err := r.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to create event log: %w", err)
+	}
+	return nil
+}
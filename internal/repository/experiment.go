@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// VariantMetrics summarizes the exposure and engagement of an experiment variant
+type VariantMetrics struct {
+	Variant   string  `json:"variant"`
+	Users     int64   `json:"users"`      // Distinct users who received a reminder in the variant
+	Exposures int64   `json:"exposures"`  // Reminders delivered in the variant
+	Clicks    int64   `json:"clicks"`     // Inline button clicks of the variant's users
+	ClickRate float64 `json:"click_rate"` // Clicks per exposure
+	Feedback  int64   `json:"feedback"`   // /feedback messages of the variant's users
+	Positive  int64   `json:"positive"`
+	Negative  int64   `json:"negative"`
+	Sentiment float64 `json:"sentiment"` // Mean feedback sentiment, from -1 to 1
+}
+
+// ExperimentRepository handles experiment and experiment event data access
+type ExperimentRepository struct {
+	db *gorm.DB
+}
+
+// NewExperimentRepository creates a new ExperimentRepository
+func NewExperimentRepository(db *gorm.DB) *ExperimentRepository {
+	return &ExperimentRepository{db: db}
+}
+
+// Create stores a new experiment
+func (r *ExperimentRepository) Create(e *model.Experiment) error {
+	if err := r.db.Create(e).Error; err != nil {
+		logger.Error("Failed to create experiment", zap.String("key", e.Key), zap.Error(err))
+		return fmt.Errorf("failed to create experiment: %w", err)
+	}
+	logger.Info("Experiment created",
+		zap.Uint("experiment_id", e.ID),
+		zap.String("key", e.Key),
+		zap.String("dimension", e.Dimension),
+		zap.String("variants", e.Variants))
+	return nil
+}
+
+// FindByID finds an experiment by ID
+func (r *ExperimentRepository) FindByID(id uint) (*model.Experiment, error) {
+	var e model.Experiment
+	if err := r.db.First(&e, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find experiment: %w", err)
+	}
+	return &e, nil
+}
+
+// FindByKey finds an experiment by key
+func (r *ExperimentRepository) FindByKey(key string) (*model.Experiment, error) {
+	var e model.Experiment
+	if err := r.db.Where("key = ?", key).First(&e).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find experiment: %w", err)
+	}
+	return &e, nil
+}
+
+// List retrieves experiments, newest first, with pagination along with the total count
+func (r *ExperimentRepository) List(offset, limit int) ([]model.Experiment, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.Experiment{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count experiments: %w", err)
+	}
+
+	var items []model.Experiment
+	if err := r.db.Order("id DESC").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list experiments: %w", err)
+	}
+	return items, total, nil
+}
+
+// FindRunning returns the running experiments, oldest first
+func (r *ExperimentRepository) FindRunning() ([]model.Experiment, error) {
+	var items []model.Experiment
+	if err := r.db.Where("status = ?", model.ExperimentRunning).Order("id").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to find running experiments: %w", err)
+	}
+	return items, nil
+}
+
+// Start moves a draft experiment to running, unless another experiment of the same dimension is
+// running. Reports false when the experiment is not a draft or the dimension is taken.
+func (r *ExperimentRepository) Start(e *model.Experiment, now time.Time) (bool, error) {
+	started := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var running int64
+		if err := tx.Model(&model.Experiment{}).
+			Where("dimension = ? AND status = ?", e.Dimension, model.ExperimentRunning).
+			Count(&running).Error; err != nil {
+			return err
+		}
+		if running > 0 {
+			return nil
+		}
+		result := tx.Model(&model.Experiment{}).
+			Where("id = ? AND status = ?", e.ID, model.ExperimentDraft).
+			Updates(map[string]interface{}{"status": model.ExperimentRunning, "started_at": now})
+		started = result.RowsAffected > 0
+		return result.Error
+	})
+	if err != nil {
+		logger.Error("Failed to start experiment", zap.Uint("experiment_id", e.ID), zap.Error(err))
+		return false, fmt.Errorf("failed to start experiment: %w", err)
+	}
+	return started, nil
+}
+
+// Stop moves a running experiment to stopped, reporting false when it was not running
+func (r *ExperimentRepository) Stop(id uint, now time.Time) (bool, error) {
+	result := r.db.Model(&model.Experiment{}).
+		Where("id = ? AND status = ?", id, model.ExperimentRunning).
+		Updates(map[string]interface{}{"status": model.ExperimentStopped, "stopped_at": now})
+	if result.Error != nil {
+		logger.Error("Failed to stop experiment", zap.Uint("experiment_id", id), zap.Error(result.Error))
+		return false, fmt.Errorf("failed to stop experiment: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateDescription changes the description of an experiment
+func (r *ExperimentRepository) UpdateDescription(id uint, description string) error {
+	if err := r.db.Model(&model.Experiment{}).Where("id = ?", id).Update("description", description).Error; err != nil {
+		return fmt.Errorf("failed to update experiment: %w", err)
+	}
+	return nil
+}
+
+// AddEvents records experiment events
+func (r *ExperimentRepository) AddEvents(events []model.ExperimentEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := r.db.Create(&events).Error; err != nil {
+		logger.Error("Failed to record experiment events", zap.Int("count", len(events)), zap.Error(err))
+		return fmt.Errorf("failed to record experiment events: %w", err)
+	}
+	return nil
+}
+
+// VariantMetrics aggregates the events of an experiment per variant, in the given variant order
+func (r *ExperimentRepository) VariantMetrics(experimentID uint, variants []string) ([]VariantMetrics, error) {
+	var rows []VariantMetrics
+	err := r.db.Model(&model.ExperimentEvent{}).
+		Select(`variant,
+			COUNT(DISTINCT CASE WHEN kind = ? THEN user_id END) AS users,
+			SUM(CASE WHEN kind = ? THEN 1 ELSE 0 END) AS exposures,
+			SUM(CASE WHEN kind = ? THEN 1 ELSE 0 END) AS clicks,
+			SUM(CASE WHEN kind = ? THEN 1 ELSE 0 END) AS feedback,
+			SUM(CASE WHEN kind = ? AND value > 0 THEN 1 ELSE 0 END) AS positive,
+			SUM(CASE WHEN kind = ? AND value < 0 THEN 1 ELSE 0 END) AS negative`,
+			model.ExperimentEventExposure, model.ExperimentEventExposure, model.ExperimentEventClick,
+			model.ExperimentEventFeedback, model.ExperimentEventFeedback, model.ExperimentEventFeedback).
+		Where("experiment_id = ?", experimentID).
+		Group("variant").
+		Scan(&rows).Error
+	if err != nil {
+		logger.Error("Failed to aggregate experiment events", zap.Uint("experiment_id", experimentID), zap.Error(err))
+		return nil, fmt.Errorf("failed to aggregate experiment events: %w", err)
+	}
+
+	byVariant := make(map[string]VariantMetrics, len(rows))
+	for _, row := range rows {
+		byVariant[row.Variant] = row
+	}
+	metrics := make([]VariantMetrics, 0, len(variants))
+	for _, variant := range variants {
+		m := byVariant[variant]
+		m.Variant = variant
+		if m.Exposures > 0 {
+			m.ClickRate = float64(m.Clicks) / float64(m.Exposures)
+		}
+		if m.Feedback > 0 {
+			m.Sentiment = float64(m.Positive-m.Negative) / float64(m.Feedback)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// FeedbackRepository handles user feedback data access
+type FeedbackRepository struct {
+	db *gorm.DB
+}
+
+// NewFeedbackRepository creates a new FeedbackRepository
+func NewFeedbackRepository(db *gorm.DB) *FeedbackRepository {
+	return &FeedbackRepository{db: db}
+}
+
+// Create stores a feedback message
+func (r *FeedbackRepository) Create(f *model.Feedback) error {
+	if err := r.db.Create(f).Error; err != nil {
+		logger.Error("Failed to save feedback", zap.Uint("user_id", f.UserID), zap.Error(err))
+		return fmt.Errorf("failed to save feedback: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// HealthReminderRepository handles database operations for private health reminders. Every
+// method acting on a single reminder is scoped by its owner's user ID.
+type HealthReminderRepository struct {
+	db *gorm.DB
+}
+
+// NewHealthReminderRepository creates a new HealthReminderRepository
+func NewHealthReminderRepository(db *gorm.DB) *HealthReminderRepository {
+	return &HealthReminderRepository{db: db}
+}
+
+// Create adds a health reminder
+func (r *HealthReminderRepository) Create(reminder *model.HealthReminder) error {
+	if err := r.db.Create(reminder).Error; err != nil {
+		logger.Error("Failed to create health reminder",
+			zap.Uint("user_id", reminder.UserID),
+			zap.Error(err))
+		return fmt.Errorf("failed to create health reminder: %w", err)
+	}
+	return nil
+}
+
+// FindByUser returns a user's health reminders, ordered by creation
+func (r *HealthReminderRepository) FindByUser(userID uint) ([]model.HealthReminder, error) {
+	var reminders []model.HealthReminder
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&reminders).Error; err != nil {
+		return nil, fmt.Errorf("failed to find health reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// CountByUser returns the number of a user's health reminders
+func (r *HealthReminderRepository) CountByUser(userID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.HealthReminder{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count health reminders: %w", err)
+	}
+	return count, nil
+}
+
+// UpdatePayload replaces the encrypted plan of a user's reminder, reporting whether it exists
+func (r *HealthReminderRepository) UpdatePayload(id, userID uint, payload string) (bool, error) {
+	result := r.db.Model(&model.HealthReminder{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("payload", payload)
+	if result.Error != nil {
+		logger.Error("Failed to update health reminder",
+			zap.Uint("id", id),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to update health reminder: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Delete removes a user's reminder, reporting whether it existed
+func (r *HealthReminderRepository) Delete(id, userID uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.HealthReminder{})
+	if result.Error != nil {
+		logger.Error("Failed to delete health reminder",
+			zap.Uint("id", id),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to delete health reminder: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindDue returns the reminders at a time of day not yet sent on a date, with their users;
+// reminders of deleted users are skipped
+func (r *HealthReminderRepository) FindDue(reminderTime, date string) ([]model.HealthReminder, error) {
+	var reminders []model.HealthReminder
+	err := r.db.Preload("User").
+		Joins("JOIN users ON users.id = health_reminders.user_id AND users.deleted_at IS NULL").
+		Where("health_reminders.reminder_time = ?", reminderTime).
+		Where("health_reminders.last_sent_on IS NULL OR health_reminders.last_sent_on <> ?", date).
+		Order("health_reminders.id").
+		Find(&reminders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find due health reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// ClaimSend records that a reminder is sent on a date, reporting false when it already was, so
+// concurrent instances and catch-up ticks send it once
+func (r *HealthReminderRepository) ClaimSend(id uint, date string) (bool, error) {
+	result := r.db.Model(&model.HealthReminder{}).
+		Where("id = ? AND (last_sent_on IS NULL OR last_sent_on <> ?)", id, date).
+		Update("last_sent_on", date)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim health reminder: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IndexWatchRepository handles database operations for life index watches
+type IndexWatchRepository struct {
+	db *gorm.DB
+}
+
+// NewIndexWatchRepository creates a new IndexWatchRepository
+func NewIndexWatchRepository(db *gorm.DB) *IndexWatchRepository {
+	return &IndexWatchRepository{db: db}
+}
+
+// Upsert creates a watch, or updates the level of the subscription's existing watch of the same index
+func (r *IndexWatchRepository) Upsert(watch *model.IndexWatch) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "index_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_level"}),
+	}).Create(watch).Error
+	if err != nil {
+		logger.Error("Failed to save index watch",
+			zap.Uint("subscription_id", watch.SubscriptionID),
+			zap.String("index_type", watch.IndexType),
+			zap.Error(err))
+		return fmt.Errorf("failed to save index watch: %w", err)
+	}
+	return nil
+}
+
+// FindBySubscription returns the watches of a subscription
+func (r *IndexWatchRepository) FindBySubscription(subscriptionID uint) ([]model.IndexWatch, error) {
+	var watches []model.IndexWatch
+	if err := r.db.Where("subscription_id = ?", subscriptionID).Order("id").Find(&watches).Error; err != nil {
+		return nil, fmt.Errorf("failed to find index watches: %w", err)
+	}
+	return watches, nil
+}
+
+// FindByUser returns all watches of a user, ordered by creation
+func (r *IndexWatchRepository) FindByUser(userID uint) ([]model.IndexWatch, error) {
+	var watches []model.IndexWatch
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&watches).Error; err != nil {
+		return nil, fmt.Errorf("failed to find index watches: %w", err)
+	}
+	return watches, nil
+}
+
+// Delete removes a user's watch, reporting whether it existed
+func (r *IndexWatchRepository) Delete(id, userID uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.IndexWatch{})
+	if result.Error != nil {
+		logger.Error("Failed to delete index watch",
+			zap.Uint("id", id),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to delete index watch: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MarkNotified records the date of a watch's latest push
+func (r *IndexWatchRepository) MarkNotified(id uint, date string) error {
+	if err := r.db.Model(&model.IndexWatch{}).Where("id = ?", id).Update("last_notified_on", date).Error; err != nil {
+		return fmt.Errorf("failed to mark index watch notified: %w", err)
+	}
+	return nil
+}
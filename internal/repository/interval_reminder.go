@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IntervalReminderRepository handles database operations for interval reminders
+type IntervalReminderRepository struct {
+	db *gorm.DB
+}
+
+// NewIntervalReminderRepository creates a new IntervalReminderRepository
+func NewIntervalReminderRepository(db *gorm.DB) *IntervalReminderRepository {
+	return &IntervalReminderRepository{db: db}
+}
+
+// Upsert creates a user's reminder of a kind or replaces its settings, and loads the saved row
+// into reminder
+func (r *IntervalReminderRepository) Upsert(reminder *model.IntervalReminder) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "kind"}},
+		DoUpdates: clause.AssignmentColumns([]string{"interval_minutes", "start_time", "end_time", "workdays_only", "updated_at"}),
+	}).Create(reminder).Error
+	if err != nil {
+		logger.Error("Failed to save interval reminder",
+			zap.Uint("user_id", reminder.UserID),
+			zap.String("kind", reminder.Kind),
+			zap.Error(err))
+		return fmt.Errorf("failed to save interval reminder: %w", err)
+	}
+	// The ID is not reported back on every database when the row was updated
+	if err := r.db.Where("user_id = ? AND kind = ?", reminder.UserID, reminder.Kind).First(reminder).Error; err != nil {
+		return fmt.Errorf("failed to load interval reminder: %w", err)
+	}
+	return nil
+}
+
+// FindByUser returns a user's reminders, ordered by creation
+func (r *IntervalReminderRepository) FindByUser(userID uint) ([]model.IntervalReminder, error) {
+	var reminders []model.IntervalReminder
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&reminders).Error; err != nil {
+		return nil, fmt.Errorf("failed to find interval reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// FindAll returns the reminders of all users that are not deleted
+func (r *IntervalReminderRepository) FindAll() ([]model.IntervalReminder, error) {
+	var reminders []model.IntervalReminder
+	err := r.db.Joins("JOIN users ON users.id = interval_reminders.user_id AND users.deleted_at IS NULL").
+		Order("interval_reminders.id").
+		Find(&reminders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find interval reminders: %w", err)
+	}
+	return reminders, nil
+}
+
+// FindByID returns a reminder with its user, or nil when it or its user no longer exists
+func (r *IntervalReminderRepository) FindByID(id uint) (*model.IntervalReminder, error) {
+	var reminder model.IntervalReminder
+	err := r.db.Preload("User").
+		Joins("JOIN users ON users.id = interval_reminders.user_id AND users.deleted_at IS NULL").
+		Where("interval_reminders.id = ?", id).
+		First(&reminder).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find interval reminder: %w", err)
+	}
+	return &reminder, nil
+}
+
+// Delete removes a user's reminder, reporting whether it existed
+func (r *IntervalReminderRepository) Delete(id, userID uint) (bool, error) {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.IntervalReminder{})
+	if result.Error != nil {
+		logger.Error("Failed to delete interval reminder",
+			zap.Uint("id", id),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to delete interval reminder: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ClaimSlot records that a reminder is sent for a slot ("YYYY-MM-DD HH:MM"), reporting false
+// when it already was, so concurrent instances send each slot once
+func (r *IntervalReminderRepository) ClaimSlot(id uint, slot string) (bool, error) {
+	result := r.db.Model(&model.IntervalReminder{}).
+		Where("id = ? AND (last_slot IS NULL OR last_slot <> ?)", id, slot).
+		Update("last_slot", slot)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim interval reminder: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
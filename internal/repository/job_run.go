@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// JobRunFilter narrows down job run queries; empty fields match everything
+type JobRunFilter struct {
+	Type   string
+	Status string
+}
+
+// JobRunRepository handles the records of scheduled job runs
+type JobRunRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRunRepository creates a new JobRunRepository
+func NewJobRunRepository(db *gorm.DB) *JobRunRepository {
+	return &JobRunRepository{db: db}
+}
+
+// Create records a started run
+func (r *JobRunRepository) Create(run *model.JobRun) error {
+	if err := r.db.Create(run).Error; err != nil {
+		logger.Error("Failed to create job run",
+			zap.String("type", run.Type),
+			zap.Error(err))
+		return fmt.Errorf("failed to create job run: %w", err)
+	}
+	return nil
+}
+
+// Finish saves the outcome of a run
+func (r *JobRunRepository) Finish(run *model.JobRun) error {
+	err := r.db.Model(run).Select("Status", "Items", "Failures", "Error", "FinishedAt").Updates(run).Error
+	if err != nil {
+		logger.Error("Failed to finish job run",
+			zap.Uint("id", run.ID),
+			zap.Error(err))
+		return fmt.Errorf("failed to finish job run: %w", err)
+	}
+	return nil
+}
+
+// FindByID retrieves a run. Returns nil if not found.
+func (r *JobRunRepository) FindByID(id uint) (*model.JobRun, error) {
+	var run model.JobRun
+	err := r.db.First(&run, id).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Failed to find job run", zap.Uint("id", id), zap.Error(err))
+		return nil, fmt.Errorf("failed to find job run: %w", err)
+	}
+	return &run, nil
+}
+
+// List retrieves runs matching the filter, newest first, with pagination along with the total count
+func (r *JobRunRepository) List(filter JobRunFilter, offset, limit int) ([]model.JobRun, int64, error) {
+	query := r.db.Model(&model.JobRun{})
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.Error("Failed to count job runs", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count job runs: %w", err)
+	}
+
+	var runs []model.JobRun
+	if err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&runs).Error; err != nil {
+		logger.Error("Failed to list job runs", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list job runs: %w", err)
+	}
+	return runs, total, nil
+}
+
+// Latest retrieves the most recent run of each job type, ordered by type
+func (r *JobRunRepository) Latest() ([]model.JobRun, error) {
+	var runs []model.JobRun
+	err := r.db.Where("id IN (?)", r.db.Model(&model.JobRun{}).Select("MAX(id)").Group("type")).
+		Order("type ASC").
+		Find(&runs).Error
+	if err != nil {
+		logger.Error("Failed to find latest job runs", zap.Error(err))
+		return nil, fmt.Errorf("failed to find latest job runs: %w", err)
+	}
+	return runs, nil
+}
+
+// FailInterrupted marks runs left running by a previous process as failed
+func (r *JobRunRepository) FailInterrupted(now time.Time) (int64, error) {
+	result := r.db.Model(&model.JobRun{}).
+		Where("status = ?", model.JobStatusRunning).
+		Updates(map[string]interface{}{"status": model.JobStatusFailed, "error": "interrupted by restart", "finished_at": now})
+	if result.Error != nil {
+		logger.Error("Failed to mark interrupted job runs", zap.Error(result.Error))
+		return 0, fmt.Errorf("failed to mark interrupted job runs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteBefore removes runs started before the given time, returning how many were deleted
+func (r *JobRunRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("started_at < ?", before).Delete(&model.JobRun{})
+	if result.Error != nil {
+		logger.Error("Failed to delete old job runs", zap.Error(result.Error))
+		return 0, fmt.Errorf("failed to delete old job runs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
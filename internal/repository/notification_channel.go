@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// NotificationChannelRepository handles notification channel data access
+type NotificationChannelRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationChannelRepository creates a new NotificationChannelRepository
+func NewNotificationChannelRepository(db *gorm.DB) *NotificationChannelRepository {
+	return &NotificationChannelRepository{db: db}
+}
+
+// Create creates a new notification channel
+func (r *NotificationChannelRepository) Create(ch *model.NotificationChannel) error {
+	logger.Debug("NotificationChannelRepository.Create called",
+		zap.Uint("subscription_id", ch.SubscriptionID),
+		zap.String("type", ch.Type))
+
+	if err := r.db.Create(ch).Error; err != nil {
+		logger.Error("Failed to create notification channel",
+			zap.Uint("subscription_id", ch.SubscriptionID),
+			zap.Error(err))
+		return fmt.Errorf("failed to create notification channel: %w", err)
+	}
+
+	logger.Info("Notification channel created successfully",
+		zap.Uint("channel_id", ch.ID),
+		zap.Uint("subscription_id", ch.SubscriptionID),
+		zap.String("type", ch.Type))
+	return nil
+}
+
+// FindBySubscriptionID retrieves the channels of a subscription
+func (r *NotificationChannelRepository) FindBySubscriptionID(subscriptionID uint) ([]model.NotificationChannel, error) {
+	var channels []model.NotificationChannel
+	err := r.db.Where("subscription_id = ?", subscriptionID).Order("id ASC").Find(&channels).Error
+	if err != nil {
+		logger.Error("Failed to find notification channels",
+			zap.Uint("subscription_id", subscriptionID),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find notification channels: %w", err)
+	}
+	return channels, nil
+}
+
+// FindBySubscriptionIDs retrieves the channels of several subscriptions, grouped by subscription ID
+func (r *NotificationChannelRepository) FindBySubscriptionIDs(subscriptionIDs []uint) (map[uint][]model.NotificationChannel, error) {
+	result := make(map[uint][]model.NotificationChannel)
+	if len(subscriptionIDs) == 0 {
+		return result, nil
+	}
+
+	var channels []model.NotificationChannel
+	err := r.db.Where("subscription_id IN ?", subscriptionIDs).Order("id ASC").Find(&channels).Error
+	if err != nil {
+		logger.Error("Failed to find notification channels",
+			zap.Int("subscription_count", len(subscriptionIDs)),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find notification channels: %w", err)
+	}
+	for _, ch := range channels {
+		result[ch.SubscriptionID] = append(result[ch.SubscriptionID], ch)
+	}
+	return result, nil
+}
+
+// DeleteBySubscription deletes a channel belonging to the given subscription
+func (r *NotificationChannelRepository) DeleteBySubscription(id uint, subscriptionID uint) error {
+	logger.Debug("NotificationChannelRepository.DeleteBySubscription called",
+		zap.Uint("channel_id", id),
+		zap.Uint("subscription_id", subscriptionID))
+
+	result := r.db.Where("id = ? AND subscription_id = ?", id, subscriptionID).Delete(&model.NotificationChannel{})
+	if result.Error != nil {
+		logger.Error("Failed to delete notification channel",
+			zap.Uint("channel_id", id),
+			zap.Error(result.Error))
+		return fmt.Errorf("failed to delete notification channel: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("notification channel not found")
+	}
+
+	logger.Info("Notification channel deleted successfully",
+		zap.Uint("channel_id", id))
+	return nil
+}
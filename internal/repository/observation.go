@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ObservationRepository handles the users' weather observations
+type ObservationRepository struct {
+	db *gorm.DB
+}
+
+// NewObservationRepository creates a new ObservationRepository
+func NewObservationRepository(db *gorm.DB) *ObservationRepository {
+	return &ObservationRepository{db: db}
+}
+
+// Create saves a new observation
+func (r *ObservationRepository) Create(obs *model.Observation) error {
+	if err := r.db.Create(obs).Error; err != nil {
+		logger.Error("Failed to create observation",
+			zap.Uint("user_id", obs.UserID),
+			zap.String("city", obs.City),
+			zap.Error(err))
+		return fmt.Errorf("failed to create observation: %w", err)
+	}
+	return nil
+}
+
+// FindByID finds an observation with its user
+func (r *ObservationRepository) FindByID(id uint) (*model.Observation, error) {
+	var obs model.Observation
+	if err := r.db.Preload("User").First(&obs, id).Error; err != nil {
+		return nil, fmt.Errorf("failed to find observation: %w", err)
+	}
+	return &obs, nil
+}
+
+// FindRecent returns the latest observations with their users, newest first, of a city ("" = all
+// cities), including hidden ones
+func (r *ObservationRepository) FindRecent(city string, limit int) ([]model.Observation, error) {
+	query := r.db.Preload("User").Order("created_at DESC, id DESC").Limit(limit)
+	if city != "" {
+		query = query.Where("city = ?", city)
+	}
+	var observations []model.Observation
+	if err := query.Find(&observations).Error; err != nil {
+		return nil, fmt.Errorf("failed to find observations: %w", err)
+	}
+	return observations, nil
+}
+
+// CountByUserSince counts a user's observations since a time
+func (r *ObservationRepository) CountByUserSince(userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Observation{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count observations: %w", err)
+	}
+	return count, nil
+}
+
+// FindLatestByUser returns a user's latest observation, or nil when there is none
+func (r *ObservationRepository) FindLatestByUser(userID uint) (*model.Observation, error) {
+	var observations []model.Observation
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(1).Find(&observations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find observation: %w", err)
+	}
+	if len(observations) == 0 {
+		return nil, nil
+	}
+	return &observations[0], nil
+}
+
+// CountReporters counts, per phenomenon, the distinct users reporting it in a city since a time.
+// Hidden observations and banned users are not counted.
+func (r *ObservationRepository) CountReporters(city string, since time.Time) (map[string]int, error) {
+	var rows []struct {
+		Kind      string
+		Reporters int
+	}
+	err := r.db.Model(&model.Observation{}).
+		Select("observations.kind AS kind, COUNT(DISTINCT observations.user_id) AS reporters").
+		Joins("JOIN users ON users.id = observations.user_id").
+		Where("observations.city = ? AND observations.created_at >= ? AND observations.hidden = ?", city, since, false).
+		Where("users.observations_ban = ? AND users.deleted_at IS NULL", false).
+		Group("observations.kind").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count observation reporters: %w", err)
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.Kind] = row.Reporters
+	}
+	return counts, nil
+}
+
+// SetHidden hides or shows an observation. Returns false when it does not exist.
+func (r *ObservationRepository) SetHidden(id uint, hidden bool) (bool, error) {
+	result := r.db.Model(&model.Observation{}).Where("id = ?", id).Update("hidden", hidden)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update observation: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// HideByUser hides all observations of a user
+func (r *ObservationRepository) HideByUser(userID uint) (int64, error) {
+	result := r.db.Model(&model.Observation{}).Where("user_id = ?", userID).Update("hidden", true)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to hide observations: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// DeleteBefore deletes the observations made before a time
+func (r *ObservationRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&model.Observation{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete observations: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
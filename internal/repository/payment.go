@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentRepository handles the ledger of premium tier payments
+type PaymentRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentRepository creates a new PaymentRepository
+func NewPaymentRepository(db *gorm.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// Record adds a payment to the ledger and upgrades its user to premium until payment.TierUntil,
+// in one transaction. Returns false without changes when the charge is already recorded.
+func (r *PaymentRepository) Record(payment *model.Payment) (bool, error) {
+	recorded := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(payment)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		recorded = true
+		return tx.Model(&model.User{}).Where("id = ?", payment.UserID).
+			Updates(map[string]interface{}{"tier": model.TierPremium, "tier_until": payment.TierUntil}).Error
+	})
+	if err != nil {
+		logger.Error("Failed to record payment",
+			zap.Uint("user_id", payment.UserID),
+			zap.String("charge_id", payment.TelegramChargeID),
+			zap.Error(err))
+		return false, fmt.Errorf("failed to record payment: %w", err)
+	}
+	return recorded, nil
+}
+
+// List retrieves payments, newest first, with pagination along with the total count
+func (r *PaymentRepository) List(offset, limit int) ([]model.Payment, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.Payment{}).Count(&total).Error; err != nil {
+		logger.Error("Failed to count payments", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count payments: %w", err)
+	}
+
+	var items []model.Payment
+	if err := r.db.Order("id DESC").Offset(offset).Limit(limit).Find(&items).Error; err != nil {
+		logger.Error("Failed to list payments", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list payments: %w", err)
+	}
+	return items, total, nil
+}
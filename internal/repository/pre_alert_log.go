@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PreAlertLogRepository handles database operations for forecast pre-alert logs
+type PreAlertLogRepository struct {
+	db *gorm.DB
+}
+
+// NewPreAlertLogRepository creates a new PreAlertLogRepository
+func NewPreAlertLogRepository(db *gorm.DB) *PreAlertLogRepository {
+	return &PreAlertLogRepository{db: db}
+}
+
+// Claim records a pre-alert, reporting false when the same city, kind and date was already recorded
+func (r *PreAlertLogRepository) Claim(log *model.PreAlertLog) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(log)
+	if result.Error != nil {
+		logger.Error("Failed to save pre-alert log",
+			zap.String("city", log.City),
+			zap.String("kind", log.Kind),
+			zap.String("target_date", log.TargetDate),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to save pre-alert log: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Release removes a claimed pre-alert, so that it is retried on the next run
+func (r *PreAlertLogRepository) Release(id uint) error {
+	if err := r.db.Delete(&model.PreAlertLog{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete pre-alert log: %w", err)
+	}
+	return nil
+}
+
+// ExistsSince reports whether a pre-alert of a kind was recorded for a city on or after a date
+func (r *PreAlertLogRepository) ExistsSince(city, kind, since string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.PreAlertLog{}).
+		Where("city = ? AND kind = ? AND target_date >= ?", city, kind, since).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to count pre-alert logs: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProcessedUpdateRepository handles processed Telegram update data access
+type ProcessedUpdateRepository struct {
+	db *gorm.DB
+}
+
+// NewProcessedUpdateRepository creates a new ProcessedUpdateRepository
+func NewProcessedUpdateRepository(db *gorm.DB) *ProcessedUpdateRepository {
+	return &ProcessedUpdateRepository{db: db}
+}
+
+// MarkProcessed records an update ID received by a bot ("" = the primary bot), reporting false if
+// it had already been recorded
+func (r *ProcessedUpdateRepository) MarkProcessed(bot string, updateID int) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.ProcessedUpdate{BotID: bot, UpdateID: updateID})
+	if result.Error != nil {
+		logger.Error("Failed to record processed update",
+			zap.String("bot", bot),
+			zap.Int("update_id", updateID),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to record processed update: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteBefore removes records older than the given time
+func (r *ProcessedUpdateRepository) DeleteBefore(before time.Time) error {
+	result := r.db.Where("created_at < ?", before).Delete(&model.ProcessedUpdate{})
+	if result.Error != nil {
+		logger.Error("Failed to prune processed updates", zap.Error(result.Error))
+		return fmt.Errorf("failed to prune processed updates: %w", result.Error)
+	}
+
+	logger.Debug("Processed updates pruned", zap.Int64("count", result.RowsAffected))
+	return nil
+}
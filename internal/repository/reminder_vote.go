@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReminderVoteRepository handles votes on AI reminders
+type ReminderVoteRepository struct {
+	db *gorm.DB
+}
+
+// NewReminderVoteRepository creates a new ReminderVoteRepository
+func NewReminderVoteRepository(db *gorm.DB) *ReminderVoteRepository {
+	return &ReminderVoteRepository{db: db}
+}
+
+// Upsert records the vote on a reminder message, replacing an earlier vote and its reason
+func (r *ReminderVoteRepository) Upsert(vote *model.ReminderVote) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}, {Name: "message_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "vote", "reason", "updated_at"}),
+	}).Create(vote).Error
+	if err != nil {
+		logger.Error("Failed to save reminder vote",
+			zap.Uint("user_id", vote.UserID),
+			zap.Int("message_id", vote.MessageID),
+			zap.Error(err))
+		return fmt.Errorf("failed to save reminder vote: %w", err)
+	}
+	return nil
+}
+
+// SetReason sets the reason of the 👎 vote on a reminder message. Returns false when the message
+// has no 👎 vote.
+func (r *ReminderVoteRepository) SetReason(chatID int64, messageID int, reason string) (bool, error) {
+	result := r.db.Model(&model.ReminderVote{}).
+		Where("chat_id = ? AND message_id = ? AND vote < 0", chatID, messageID).
+		Update("reason", reason)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update reminder vote: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindSince returns the votes cast or changed since a time, newest first
+func (r *ReminderVoteRepository) FindSince(since time.Time) ([]model.ReminderVote, error) {
+	var votes []model.ReminderVote
+	if err := r.db.Where("updated_at >= ?", since).Order("updated_at DESC").Find(&votes).Error; err != nil {
+		return nil, fmt.Errorf("failed to find reminder votes: %w", err)
+	}
+	return votes, nil
+}
+
+// DeleteBefore removes the votes last changed before a time
+func (r *ReminderVoteRepository) DeleteBefore(before time.Time) (int64, error) {
+	result := r.db.Where("updated_at < ?", before).Delete(&model.ReminderVote{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete reminder votes: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SeasonalEventRepository handles database operations for seasonal advice events
+type SeasonalEventRepository struct {
+	db *gorm.DB
+}
+
+// NewSeasonalEventRepository creates a new SeasonalEventRepository
+func NewSeasonalEventRepository(db *gorm.DB) *SeasonalEventRepository {
+	return &SeasonalEventRepository{db: db}
+}
+
+// Record stores date as the day of a city's seasonal event unless one is already stored, and
+// returns the stored day
+func (r *SeasonalEventRepository) Record(city, rule, season, date string) (string, error) {
+	event := &model.SeasonalEvent{City: city, Rule: rule, Season: season, Date: date}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(event).Error; err != nil {
+		logger.Error("Failed to save seasonal event",
+			zap.String("city", city),
+			zap.String("rule", rule),
+			zap.String("season", season),
+			zap.Error(err))
+		return "", fmt.Errorf("failed to save seasonal event: %w", err)
+	}
+
+	var stored model.SeasonalEvent
+	err := r.db.Where("city = ? AND rule = ? AND season = ?", city, rule, season).First(&stored).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to find seasonal event: %w", err)
+	}
+	return stored.Date, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StaffRoleRepository handles the roles granted to staff users
+type StaffRoleRepository struct {
+	db *gorm.DB
+}
+
+// NewStaffRoleRepository creates a new StaffRoleRepository
+func NewStaffRoleRepository(db *gorm.DB) *StaffRoleRepository {
+	return &StaffRoleRepository{db: db}
+}
+
+// Find returns the role granted to a Telegram user, nil when none is
+func (r *StaffRoleRepository) Find(telegramID int64) (*model.StaffRole, error) {
+	var role model.StaffRole
+	err := r.db.Where("telegram_id = ?", telegramID).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Failed to find staff role", zap.Int64("telegram_id", telegramID), zap.Error(err))
+		return nil, fmt.Errorf("failed to find staff role: %w", err)
+	}
+	return &role, nil
+}
+
+// Upsert grants a role to a Telegram user, replacing the role granted before
+func (r *StaffRoleRepository) Upsert(role *model.StaffRole) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "telegram_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "granted_by", "updated_at"}),
+	}).Create(role).Error
+	if err != nil {
+		logger.Error("Failed to save staff role",
+			zap.Int64("telegram_id", role.TelegramID),
+			zap.String("role", role.Role),
+			zap.Error(err))
+		return fmt.Errorf("failed to save staff role: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the role granted to a Telegram user
+func (r *StaffRoleRepository) Delete(telegramID int64) error {
+	if err := r.db.Where("telegram_id = ?", telegramID).Delete(&model.StaffRole{}).Error; err != nil {
+		logger.Error("Failed to delete staff role", zap.Int64("telegram_id", telegramID), zap.Error(err))
+		return fmt.Errorf("failed to delete staff role: %w", err)
+	}
+	return nil
+}
+
+// FindAll returns all granted roles
+func (r *StaffRoleRepository) FindAll() ([]model.StaffRole, error) {
+	var roles []model.StaffRole
+	if err := r.db.Order("telegram_id ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to find staff roles: %w", err)
+	}
+	return roles, nil
+}
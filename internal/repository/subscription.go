@@ -171,6 +171,58 @@ func (r *SubscriptionRepository) FindByUserAndCity(userID uint, city string) (*m
 	return &sub, nil
 }
 
+// FindByUserAndCityUnscoped finds a subscription by user ID and city regardless of whether it is
+// active or soft-deleted, so that re-subscribing restores the existing row
+func (r *SubscriptionRepository) FindByUserAndCityUnscoped(userID uint, city string) (*model.Subscription, error) {
+	logger.Debug("SubscriptionRepository.FindByUserAndCityUnscoped called",
+		zap.Uint("user_id", userID),
+		zap.String("city", city))
+
+	var sub model.Subscription
+	err := r.db.Unscoped().Where("user_id = ? AND city = ?", userID, city).First(&sub).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		logger.Error("Failed to find subscription",
+			zap.Uint("user_id", userID),
+			zap.String("city", city),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// Restore reactivates a soft-deleted or inactive subscription, saving its reminder time, warning setting and coordinates
+func (r *SubscriptionRepository) Restore(sub *model.Subscription) error {
+	logger.Debug("SubscriptionRepository.Restore called",
+		zap.Uint("id", sub.ID),
+		zap.String("reminder_time", sub.ReminderTime))
+
+	err := r.db.Unscoped().Model(sub).Updates(map[string]interface{}{
+		"deleted_at":     nil,
+		"active":         true,
+		"reminder_time":  sub.ReminderTime,
+		"enable_warning": sub.EnableWarning,
+		"lat":            sub.Lat,
+		"lon":            sub.Lon,
+	}).Error
+	if err != nil {
+		logger.Error("Failed to restore subscription",
+			zap.Uint("id", sub.ID),
+			zap.Error(err))
+		return fmt.Errorf("failed to restore subscription: %w", err)
+	}
+
+	sub.Active = true
+	sub.DeletedAt = gorm.DeletedAt{}
+	logger.Info("Subscription restored successfully",
+		zap.Uint("subscription_id", sub.ID),
+		zap.Uint("user_id", sub.UserID),
+		zap.String("city", sub.City))
+	return nil
+}
+
 // CountActiveByUser counts active subscriptions for a user
 func (r *SubscriptionRepository) CountActiveByUser(userID uint) (int64, error) {
 	logger.Debug("SubscriptionRepository.CountActiveByUser called",
@@ -193,6 +245,24 @@ func (r *SubscriptionRepository) CountActiveByUser(userID uint) (int64, error) {
 	return count, nil
 }
 
+// CountByUserUnscoped counts all subscriptions a user ever had, including cancelled ones
+func (r *SubscriptionRepository) CountByUserUnscoped(userID uint) (int64, error) {
+	logger.Debug("SubscriptionRepository.CountByUserUnscoped called",
+		zap.Uint("user_id", userID))
+
+	var count int64
+	err := r.db.Unscoped().Model(&model.Subscription{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	if err != nil {
+		logger.Error("Failed to count subscriptions",
+			zap.Uint("user_id", userID),
+			zap.Error(err))
+		return 0, fmt.Errorf("failed to count subscriptions: %w", err)
+	}
+	return count, nil
+}
+
 // Delete soft deletes a subscription
 func (r *SubscriptionRepository) Delete(id uint) error {
 	logger.Debug("SubscriptionRepository.Delete called",
@@ -216,3 +286,243 @@ func (r *SubscriptionRepository) Delete(id uint) error {
 		zap.Uint("id", id))
 	return nil
 }
+
+// FindByIDWithUser finds a subscription by ID with its user preloaded
+func (r *SubscriptionRepository) FindByIDWithUser(id uint) (*model.Subscription, error) {
+	logger.Debug("SubscriptionRepository.FindByIDWithUser called",
+		zap.Uint("id", id))
+
+	var sub model.Subscription
+	err := r.db.Preload("User").Where("id = ?", id).First(&sub).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			logger.Debug("Subscription not found",
+				zap.Uint("id", id))
+			return nil, nil
+		}
+		logger.Error("Failed to find subscription",
+			zap.Uint("id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find subscription: %w", err)
+	}
+	return &sub, nil
+}
+
+// FindListMembers returns the subscriptions co-managing the todo list of a subscription, with users preloaded
+func (r *SubscriptionRepository) FindListMembers(listID uint) ([]model.Subscription, error) {
+	var subs []model.Subscription
+	if err := r.db.Preload("User").Where("shared_list_id = ?", listID).Order("id").Find(&subs).Error; err != nil {
+		logger.Error("Failed to find todo list members",
+			zap.Uint("list_id", listID),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find todo list members: %w", err)
+	}
+	return subs, nil
+}
+
+// SetSharedList makes a subscription co-manage the todo list of another subscription (nil = own list)
+func (r *SubscriptionRepository) SetSharedList(id uint, listID *uint) error {
+	if err := r.db.Model(&model.Subscription{}).Where("id = ?", id).Update("shared_list_id", listID).Error; err != nil {
+		logger.Error("Failed to update shared todo list",
+			zap.Uint("subscription_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update shared todo list: %w", err)
+	}
+	return nil
+}
+
+// SetPinReminder enables or disables pinning the daily reminder of a subscription
+func (r *SubscriptionRepository) SetPinReminder(id uint, enabled bool) error {
+	if err := r.db.Model(&model.Subscription{}).Where("id = ?", id).Update("pin_reminder", enabled).Error; err != nil {
+		logger.Error("Failed to update reminder pinning",
+			zap.Uint("subscription_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update reminder pinning: %w", err)
+	}
+	return nil
+}
+
+// SetPinnedMessage records the message ID of the currently pinned reminder (0 = none)
+func (r *SubscriptionRepository) SetPinnedMessage(id uint, messageID int) error {
+	if err := r.db.Model(&model.Subscription{}).Where("id = ?", id).Update("pinned_message_id", messageID).Error; err != nil {
+		logger.Error("Failed to update pinned reminder",
+			zap.Uint("subscription_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update pinned reminder: %w", err)
+	}
+	return nil
+}
+
+// SetOutdoor sets the outdoor activity and place of a subscription's digest section
+// (empty kind turns the section off)
+func (r *SubscriptionRepository) SetOutdoor(id uint, kind, place string) error {
+	err := r.db.Model(&model.Subscription{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"outdoor_kind": kind, "outdoor_place": place}).Error
+	if err != nil {
+		logger.Error("Failed to set outdoor section",
+			zap.Uint("subscription_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to set outdoor section: %w", err)
+	}
+	return nil
+}
+
+// SetLaundry turns the laundry drying advice of a subscription's daily reminder on or off
+func (r *SubscriptionRepository) SetLaundry(id uint, enabled bool) error {
+	err := r.db.Model(&model.Subscription{}).Where("id = ?", id).Update("laundry", enabled).Error
+	if err != nil {
+		logger.Error("Failed to set laundry advice",
+			zap.Uint("subscription_id", id),
+			zap.Bool("enabled", enabled),
+			zap.Error(err))
+		return fmt.Errorf("failed to set laundry advice: %w", err)
+	}
+	return nil
+}
+
+// SetLabel sets the user-chosen name of a subscription ("" clears it)
+func (r *SubscriptionRepository) SetLabel(id uint, label string) error {
+	err := r.db.Model(&model.Subscription{}).Where("id = ?", id).Update("label", label).Error
+	if err != nil {
+		logger.Error("Failed to set subscription label",
+			zap.Uint("subscription_id", id),
+			zap.String("label", label),
+			zap.Error(err))
+		return fmt.Errorf("failed to set subscription label: %w", err)
+	}
+	return nil
+}
+
+// SetAIMode sets how a subscription's daily reminder is written (model.AIMode*)
+func (r *SubscriptionRepository) SetAIMode(id uint, mode string) error {
+	if err := r.db.Model(&model.Subscription{}).Where("id = ?", id).Update("ai_mode", mode).Error; err != nil {
+		logger.Error("Failed to update AI mode",
+			zap.Uint("subscription_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update AI mode: %w", err)
+	}
+	return nil
+}
+
+// List retrieves subscriptions (active and inactive) with pagination, along with the total count.
+// A zero userID lists subscriptions of all users.
+func (r *SubscriptionRepository) List(userID uint, offset, limit int) ([]model.Subscription, int64, error) {
+	logger.Debug("SubscriptionRepository.List called",
+		zap.Uint("user_id", userID),
+		zap.Int("offset", offset),
+		zap.Int("limit", limit))
+
+	query := r.db.Model(&model.Subscription{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		logger.Error("Failed to count subscriptions", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count subscriptions: %w", err)
+	}
+
+	var subs []model.Subscription
+	if err := query.Order("id ASC").Offset(offset).Limit(limit).Find(&subs).Error; err != nil {
+		logger.Error("Failed to list subscriptions", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+
+	logger.Debug("Subscriptions listed",
+		zap.Int("count", len(subs)),
+		zap.Int64("total", total))
+	return subs, total, nil
+}
+
+// TransferAll moves all subscriptions of a user (active and inactive) to another user in one
+// transaction, together with their todos. A subscription to a city the other user already has is
+// merged into theirs: its todos and todo list members move over and it is deleted; an inactive or
+// cancelled subscription of the other user is restored with the moved settings. Returns the number
+// of subscriptions moved and merged.
+func (r *SubscriptionRepository) TransferAll(fromUserID, toUserID uint) (moved, merged int, err error) {
+	logger.Debug("SubscriptionRepository.TransferAll called",
+		zap.Uint("from_user_id", fromUserID),
+		zap.Uint("to_user_id", toUserID))
+
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		var subs []model.Subscription
+		if err := tx.Where("user_id = ?", fromUserID).Order("id").Find(&subs).Error; err != nil {
+			return fmt.Errorf("failed to find subscriptions: %w", err)
+		}
+
+		for _, src := range subs {
+			var dst model.Subscription
+			err := tx.Unscoped().Where("user_id = ? AND city = ?", toUserID, src.City).First(&dst).Error
+			if err == gorm.ErrRecordNotFound {
+				// The pinned reminder belongs to the old chat
+				if err := tx.Model(&model.Subscription{}).Where("id = ?", src.ID).
+					Updates(map[string]interface{}{"user_id": toUserID, "pinned_message_id": 0}).Error; err != nil {
+					return fmt.Errorf("failed to move subscription %d: %w", src.ID, err)
+				}
+				moved++
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to find subscription of %s: %w", src.City, err)
+			}
+
+			if !dst.Active || dst.DeletedAt.Valid {
+				// Nothing to keep from a cancelled subscription but its todos: take the moved settings
+				dst.SharedListID = src.SharedListID
+				if err := tx.Unscoped().Model(&model.Subscription{}).Where("id = ?", dst.ID).Updates(map[string]interface{}{
+					"deleted_at":        nil,
+					"active":            src.Active,
+					"reminder_time":     src.ReminderTime,
+					"enable_warning":    src.EnableWarning,
+					"pin_reminder":      src.PinReminder,
+					"pinned_message_id": 0,
+					"lat":               src.Lat,
+					"lon":               src.Lon,
+					"outdoor_kind":      src.OutdoorKind,
+					"outdoor_place":     src.OutdoorPlace,
+					"laundry":           src.Laundry,
+					"label":             src.Label,
+					"ai_mode":           src.AIMode,
+					"shared_list_id":    src.SharedListID,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to restore subscription %d: %w", dst.ID, err)
+				}
+			} else if dst.SharedListID != nil && *dst.SharedListID == src.ID {
+				// The other user co-manages this very list: they become its owner
+				dst.SharedListID = nil
+				if err := tx.Model(&model.Subscription{}).Where("id = ?", dst.ID).Update("shared_list_id", nil).Error; err != nil {
+					return fmt.Errorf("failed to update shared todo list: %w", err)
+				}
+			}
+
+			listID := dst.TodoListID()
+			if err := tx.Model(&model.Todo{}).Where("subscription_id = ?", src.ID).Update("subscription_id", listID).Error; err != nil {
+				return fmt.Errorf("failed to move todos: %w", err)
+			}
+			if err := tx.Model(&model.Subscription{}).Where("shared_list_id = ? AND id <> ?", src.ID, listID).
+				Update("shared_list_id", listID).Error; err != nil {
+				return fmt.Errorf("failed to move todo list members: %w", err)
+			}
+			if err := tx.Delete(&model.Subscription{}, src.ID).Error; err != nil {
+				return fmt.Errorf("failed to delete merged subscription %d: %w", src.ID, err)
+			}
+			merged++
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to transfer subscriptions",
+			zap.Uint("from_user_id", fromUserID),
+			zap.Uint("to_user_id", toUserID),
+			zap.Error(err))
+		return 0, 0, fmt.Errorf("failed to transfer subscriptions: %w", err)
+	}
+
+	logger.Info("Subscriptions transferred",
+		zap.Uint("from_user_id", fromUserID),
+		zap.Uint("to_user_id", toUserID),
+		zap.Int("moved", moved),
+		zap.Int("merged", merged))
+	return moved, merged, nil
+}
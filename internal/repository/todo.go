@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cuichanghe/daily-reminder-bot/internal/model"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
@@ -23,7 +24,7 @@ func NewTodoRepository(db *gorm.DB) *TodoRepository {
 func (r *TodoRepository) Create(todo *model.Todo) error {
 	logger.Debug("TodoRepository.Create called",
 		zap.Uint("subscription_id", todo.SubscriptionID),
-		zap.String("content", todo.Content))
+		zap.Int("content_len", len(todo.Content)))
 
 	if err := r.db.Create(todo).Error; err != nil {
 		logger.Error("Failed to create todo",
@@ -44,7 +45,7 @@ func (r *TodoRepository) FindBySubscriptionID(subscriptionID uint) ([]model.Todo
 		zap.Uint("subscription_id", subscriptionID))
 
 	var todos []model.Todo
-	err := r.db.Where("subscription_id = ?", subscriptionID).Order("created_at DESC").Find(&todos).Error
+	err := r.db.Where("subscription_id = ?", subscriptionID).Order("position ASC, created_at DESC").Find(&todos).Error
 	if err != nil {
 		logger.Error("Failed to find todos",
 			zap.Uint("subscription_id", subscriptionID),
@@ -64,7 +65,7 @@ func (r *TodoRepository) FindIncompleteBySubscriptionID(subscriptionID uint) ([]
 		zap.Uint("subscription_id", subscriptionID))
 
 	var todos []model.Todo
-	err := r.db.Where("subscription_id = ? AND completed = ?", subscriptionID, false).Order("created_at DESC").Find(&todos).Error
+	err := r.db.Where("subscription_id = ? AND completed = ?", subscriptionID, false).Order("position ASC, created_at DESC").Find(&todos).Error
 	if err != nil {
 		logger.Error("Failed to find incomplete todos",
 			zap.Uint("subscription_id", subscriptionID),
@@ -158,8 +159,21 @@ func (r *TodoRepository) FindByIDAndVerifyOwnership(todoID uint, userID uint) (*
 		return nil, fmt.Errorf("failed to find todo: %w", err)
 	}
 
-	// Verify ownership
+	// Verify ownership: the todo belongs to one of the user's subscriptions, or to a list the user co-manages
 	if todo.Subscription.UserID != userID {
+		var shared int64
+		if err := r.db.Model(&model.Subscription{}).
+			Where("user_id = ? AND shared_list_id = ?", userID, todo.SubscriptionID).
+			Count(&shared).Error; err != nil {
+			return nil, fmt.Errorf("failed to verify todo access: %w", err)
+		}
+		if shared > 0 {
+			logger.Debug("Todo found and shared list membership verified",
+				zap.Uint("todo_id", todoID),
+				zap.Uint("user_id", userID))
+			return &todo, nil
+		}
+
 		logger.Warn("Unauthorized todo access",
 			zap.Uint("todo_id", todoID),
 			zap.Uint("user_id", userID),
@@ -172,3 +186,78 @@ func (r *TodoRepository) FindByIDAndVerifyOwnership(todoID uint, userID uint) (*
 		zap.Uint("user_id", userID))
 	return &todo, nil
 }
+
+// SetPositions orders the todos of a list as given by their IDs; IDs of other lists are ignored
+func (r *TodoRepository) SetPositions(subscriptionID uint, ids []uint) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&model.Todo{}).
+				Where("id = ? AND subscription_id = ?", id, subscriptionID).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to order todos",
+			zap.Uint("subscription_id", subscriptionID),
+			zap.Error(err))
+		return fmt.Errorf("failed to order todos: %w", err)
+	}
+	return nil
+}
+
+// MoveToSubscription moves all todos of a subscription to another subscription's list
+func (r *TodoRepository) MoveToSubscription(fromID, toID uint) error {
+	if err := r.db.Model(&model.Todo{}).Where("subscription_id = ?", fromID).Update("subscription_id", toID).Error; err != nil {
+		logger.Error("Failed to move todos",
+			zap.Uint("from_subscription_id", fromID),
+			zap.Uint("to_subscription_id", toID),
+			zap.Error(err))
+		return fmt.Errorf("failed to move todos: %w", err)
+	}
+	return nil
+}
+
+// CompletionsByUser counts todos completed in [start, end) per user, attributing todos completed
+// without a recorded user to the owner of their list. userIDs optionally restricts the users counted.
+func (r *TodoRepository) CompletionsByUser(start, end time.Time, userIDs ...uint) (map[uint]int, error) {
+	var rows []struct {
+		UserID uint
+		Count  int
+	}
+	userExpr := "COALESCE(todos.completed_by, subscriptions.user_id)"
+	query := r.db.Table("todos").
+		Select(userExpr+" AS user_id, COUNT(*) AS count").
+		Joins("JOIN subscriptions ON subscriptions.id = todos.subscription_id").
+		Where("todos.completed = ? AND todos.completed_at >= ? AND todos.completed_at < ?", true, start, end)
+	if len(userIDs) > 0 {
+		query = query.Where(userExpr+" IN ?", userIDs)
+	}
+	if err := query.Group(userExpr).Scan(&rows).Error; err != nil {
+		logger.Error("Failed to count todo completions", zap.Error(err))
+		return nil, fmt.Errorf("failed to count todo completions: %w", err)
+	}
+
+	counts := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		counts[row.UserID] = row.Count
+	}
+	return counts, nil
+}
+
+// CountIncomplete counts the incomplete todos of the given subscriptions' lists
+func (r *TodoRepository) CountIncomplete(subscriptionIDs []uint) (int64, error) {
+	if len(subscriptionIDs) == 0 {
+		return 0, nil
+	}
+	var count int64
+	err := r.db.Model(&model.Todo{}).
+		Where("subscription_id IN ? AND completed = ?", subscriptionIDs, false).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count incomplete todos: %w", err)
+	}
+	return count, nil
+}
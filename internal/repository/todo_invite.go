@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TodoInviteRepository handles todo list invitation data access
+type TodoInviteRepository struct {
+	db *gorm.DB
+}
+
+// NewTodoInviteRepository creates a new TodoInviteRepository
+func NewTodoInviteRepository(db *gorm.DB) *TodoInviteRepository {
+	return &TodoInviteRepository{db: db}
+}
+
+// Create stores a new invitation, purging expired ones
+func (r *TodoInviteRepository) Create(invite *model.TodoInvite) error {
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&model.TodoInvite{}).Error; err != nil {
+		logger.Warn("Failed to purge expired todo invites", zap.Error(err))
+	}
+	if err := r.db.Create(invite).Error; err != nil {
+		logger.Error("Failed to create todo invite",
+			zap.Uint("subscription_id", invite.SubscriptionID),
+			zap.Error(err))
+		return fmt.Errorf("failed to create todo invite: %w", err)
+	}
+	return nil
+}
+
+// Claim consumes an unexpired invitation, returning nil if it does not exist, expired or was already used
+func (r *TodoInviteRepository) Claim(token string, now time.Time) (*model.TodoInvite, error) {
+	var invite model.TodoInvite
+	err := r.db.Where("token = ? AND expires_at >= ?", token, now).First(&invite).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find todo invite: %w", err)
+	}
+
+	// Deleting guards against two chats accepting the same link concurrently
+	result := r.db.Delete(&model.TodoInvite{}, invite.ID)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to consume todo invite: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &invite, nil
+}
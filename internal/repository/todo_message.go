@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// todoMessageRetention is how long a message can be reacted to in order to complete its todo
+const todoMessageRetention = 90 * 24 * time.Hour
+
+// TodoMessageRepository handles the links between Telegram messages and todos
+type TodoMessageRepository struct {
+	db *gorm.DB
+}
+
+// NewTodoMessageRepository creates a new TodoMessageRepository
+func NewTodoMessageRepository(db *gorm.DB) *TodoMessageRepository {
+	return &TodoMessageRepository{db: db}
+}
+
+// Create links the given messages of a chat to a todo, purging links past retention
+func (r *TodoMessageRepository) Create(todoID uint, chatID int64, messageIDs ...int) error {
+	if err := r.db.Where("created_at < ?", time.Now().Add(-todoMessageRetention)).Delete(&model.TodoMessage{}).Error; err != nil {
+		logger.Warn("Failed to purge old todo messages", zap.Error(err))
+	}
+
+	links := make([]model.TodoMessage, 0, len(messageIDs))
+	for _, id := range messageIDs {
+		links = append(links, model.TodoMessage{TodoID: todoID, ChatID: chatID, MessageID: id})
+	}
+	if len(links) == 0 {
+		return nil
+	}
+	if err := r.db.Create(&links).Error; err != nil {
+		logger.Error("Failed to create todo messages",
+			zap.Uint("todo_id", todoID),
+			zap.Int64("chat_id", chatID),
+			zap.Error(err))
+		return fmt.Errorf("failed to create todo messages: %w", err)
+	}
+	return nil
+}
+
+// FindTodoID returns the todo shown by a message, or 0 if the message is not linked to a todo
+func (r *TodoMessageRepository) FindTodoID(chatID int64, messageID int) (uint, error) {
+	var link model.TodoMessage
+	err := r.db.Where("chat_id = ? AND message_id = ?", chatID, messageID).First(&link).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to find todo message: %w", err)
+	}
+	return link.TodoID, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TodoProposalRepository handles todo proposals extracted from photos
+type TodoProposalRepository struct {
+	db *gorm.DB
+}
+
+// NewTodoProposalRepository creates a new TodoProposalRepository
+func NewTodoProposalRepository(db *gorm.DB) *TodoProposalRepository {
+	return &TodoProposalRepository{db: db}
+}
+
+// Create stores a new proposal, purging expired ones
+func (r *TodoProposalRepository) Create(proposal *model.TodoProposal) error {
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&model.TodoProposal{}).Error; err != nil {
+		logger.Warn("Failed to purge expired todo proposals", zap.Error(err))
+	}
+	if err := r.db.Create(proposal).Error; err != nil {
+		logger.Error("Failed to create todo proposal",
+			zap.Uint("user_id", proposal.UserID),
+			zap.Error(err))
+		return fmt.Errorf("failed to create todo proposal: %w", err)
+	}
+	return nil
+}
+
+// FindActive returns a user's unexpired proposal, or nil if it does not exist or expired
+func (r *TodoProposalRepository) FindActive(id, userID uint, now time.Time) (*model.TodoProposal, error) {
+	var proposal model.TodoProposal
+	err := r.db.Where("id = ? AND user_id = ? AND expires_at > ?", id, userID, now).First(&proposal).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find todo proposal: %w", err)
+	}
+	return &proposal, nil
+}
+
+// UpdateItems stores the items of a proposal after some were added
+func (r *TodoProposalRepository) UpdateItems(id uint, items string) error {
+	// Written through the model so that the encrypted column is sealed
+	if err := r.db.Model(&model.TodoProposal{ID: id}).Select("Items").Updates(&model.TodoProposal{Items: items}).Error; err != nil {
+		return fmt.Errorf("failed to update todo proposal: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a proposal once it was fully handled
+func (r *TodoProposalRepository) Delete(id uint) error {
+	if err := r.db.Delete(&model.TodoProposal{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete todo proposal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TodoStatsRepository handles daily todo completion statistics
+type TodoStatsRepository struct {
+	db *gorm.DB
+}
+
+// NewTodoStatsRepository creates a new TodoStatsRepository
+func NewTodoStatsRepository(db *gorm.DB) *TodoStatsRepository {
+	return &TodoStatsRepository{db: db}
+}
+
+// ReplaceDay replaces all statistics of a day, so re-aggregating a day is idempotent
+func (r *TodoStatsRepository) ReplaceDay(date string, stats []model.TodoStats) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("date = ?", date).Delete(&model.TodoStats{}).Error; err != nil {
+			return err
+		}
+		if len(stats) == 0 {
+			return nil
+		}
+		return tx.Create(&stats).Error
+	})
+	if err != nil {
+		logger.Error("Failed to store todo stats",
+			zap.String("date", date),
+			zap.Error(err))
+		return fmt.Errorf("failed to store todo stats: %w", err)
+	}
+	return nil
+}
+
+// FindByDate returns the statistics of all users for a day
+func (r *TodoStatsRepository) FindByDate(date string) ([]model.TodoStats, error) {
+	var stats []model.TodoStats
+	if err := r.db.Where("date = ?", date).Find(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to find todo stats: %w", err)
+	}
+	return stats, nil
+}
+
+// FindByUserSince returns a user's statistics from a date (inclusive), oldest first
+func (r *TodoStatsRepository) FindByUserSince(userID uint, since string) ([]model.TodoStats, error) {
+	var stats []model.TodoStats
+	err := r.db.Where("user_id = ? AND date >= ?", userID, since).Order("date").Find(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find todo stats: %w", err)
+	}
+	return stats, nil
+}
+
+// Totals returns a user's total completions and longest streak over all recorded days
+func (r *TodoStatsRepository) Totals(userID uint) (total int, bestStreak int, err error) {
+	var row struct {
+		Total      int
+		BestStreak int
+	}
+	err = r.db.Model(&model.TodoStats{}).
+		Select("COALESCE(SUM(completed), 0) AS total, COALESCE(MAX(streak), 0) AS best_streak").
+		Where("user_id = ?", userID).
+		Scan(&row).Error
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to sum todo stats: %w", err)
+	}
+	return row.Total, row.BestStreak, nil
+}
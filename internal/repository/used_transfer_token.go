@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UsedTransferTokenRepository handles redeemed transfer link data access
+type UsedTransferTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUsedTransferTokenRepository creates a new UsedTransferTokenRepository
+func NewUsedTransferTokenRepository(db *gorm.DB) *UsedTransferTokenRepository {
+	return &UsedTransferTokenRepository{db: db}
+}
+
+// Claim records a transfer token as used, reporting false if it already was
+func (r *UsedTransferTokenRepository) Claim(signature string, userID uint, expires time.Time) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.UsedTransferToken{Signature: signature, UserID: userID, ExpiresAt: expires})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to claim transfer token: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// Release gives back a token claimed for a transfer that failed
+func (r *UsedTransferTokenRepository) Release(signature string) error {
+	if err := r.db.Where("signature = ?", signature).Delete(&model.UsedTransferToken{}).Error; err != nil {
+		return fmt.Errorf("failed to release transfer token: %w", err)
+	}
+	return nil
+}
+
+// IsUsed reports whether a transfer token has been used
+func (r *UsedTransferTokenRepository) IsUsed(signature string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.UsedTransferToken{}).Where("signature = ?", signature).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check transfer token: %w", err)
+	}
+	return count > 0, nil
+}
+
+// DeleteExpired removes the records of tokens expired before the given time, which can no
+// longer be redeemed anyway
+func (r *UsedTransferTokenRepository) DeleteExpired(before time.Time) error {
+	if err := r.db.Where("expires_at < ?", before).Delete(&model.UsedTransferToken{}).Error; err != nil {
+		return fmt.Errorf("failed to delete expired transfer tokens: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/cuichanghe/daily-reminder-bot/internal/model"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
@@ -32,25 +33,29 @@ func (r *UserRepository) Create(user *model.User) error {
 	}
 
 	logger.Info("User created successfully",
+		zap.String("bot", user.Bot),
 		zap.Int64("chat_id", user.ChatID),
 		zap.Uint("user_id", user.ID))
 	return nil
 }
 
-// FindByChatID finds a user by Telegram chat ID
-func (r *UserRepository) FindByChatID(chatID int64) (*model.User, error) {
+// FindByChatID finds a user of a bot ("" = the primary bot) by Telegram chat ID
+func (r *UserRepository) FindByChatID(bot string, chatID int64) (*model.User, error) {
 	logger.Debug("UserRepository.FindByChatID called",
+		zap.String("bot", bot),
 		zap.Int64("chat_id", chatID))
 
 	var user model.User
-	err := r.db.Where("chat_id = ?", chatID).First(&user).Error
+	err := r.db.Where("bot = ? AND chat_id = ?", bot, chatID).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			logger.Debug("User not found",
+				zap.String("bot", bot),
 				zap.Int64("chat_id", chatID))
 			return nil, nil
 		}
 		logger.Error("Failed to find user",
+			zap.String("bot", bot),
 			zap.Int64("chat_id", chatID),
 			zap.Error(err))
 		return nil, fmt.Errorf("failed to find user: %w", err)
@@ -62,12 +67,13 @@ func (r *UserRepository) FindByChatID(chatID int64) (*model.User, error) {
 	return &user, nil
 }
 
-// GetOrCreate finds a user by chat ID or creates a new one
-func (r *UserRepository) GetOrCreate(chatID int64) (*model.User, error) {
+// GetOrCreate finds a user of a bot by chat ID or creates a new one
+func (r *UserRepository) GetOrCreate(bot string, chatID int64) (*model.User, error) {
 	logger.Debug("UserRepository.GetOrCreate called",
+		zap.String("bot", bot),
 		zap.Int64("chat_id", chatID))
 
-	user, err := r.FindByChatID(chatID)
+	user, err := r.FindByChatID(bot, chatID)
 	if err != nil {
 		return nil, err
 	}
@@ -80,10 +86,359 @@ func (r *UserRepository) GetOrCreate(chatID int64) (*model.User, error) {
 
 	// Create new user
 	logger.Debug("Creating new user",
+		zap.String("bot", bot),
 		zap.Int64("chat_id", chatID))
-	user = &model.User{ChatID: chatID}
+	user = &model.User{Bot: bot, ChatID: chatID}
 	if err := r.Create(user); err != nil {
 		return nil, err
 	}
 	return user, nil
 }
+
+// UpdateProfile stores the Telegram profile of a user and records when it was synced
+func (r *UserRepository) UpdateProfile(id uint, profile model.UserProfile) error {
+	logger.Debug("UserRepository.UpdateProfile called",
+		zap.Uint("user_id", id))
+
+	err := r.db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"username":          profile.Username,
+		"first_name":        profile.FirstName,
+		"last_name":         profile.LastName,
+		"language_code":     profile.LanguageCode,
+		"profile_synced_at": time.Now(),
+	}).Error
+	if err != nil {
+		logger.Error("Failed to update user profile",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update user profile: %w", err)
+	}
+	return nil
+}
+
+// SetReferrer records who invited a user, reporting false if a referrer was already recorded
+func (r *UserRepository) SetReferrer(id, referrerID uint) (bool, error) {
+	logger.Debug("UserRepository.SetReferrer called",
+		zap.Uint("user_id", id),
+		zap.Uint("referrer_id", referrerID))
+
+	result := r.db.Model(&model.User{}).
+		Where("id = ? AND referred_by_id IS NULL", id).
+		Update("referred_by_id", referrerID)
+	if result.Error != nil {
+		logger.Error("Failed to set referrer",
+			zap.Uint("user_id", id),
+			zap.Uint("referrer_id", referrerID),
+			zap.Error(result.Error))
+		return false, fmt.Errorf("failed to set referrer: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// CountReferrals counts the users invited by a user
+func (r *UserRepository) CountReferrals(id uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.User{}).Where("referred_by_id = ?", id).Count(&count).Error; err != nil {
+		logger.Error("Failed to count referrals",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return 0, fmt.Errorf("failed to count referrals: %w", err)
+	}
+	return count, nil
+}
+
+// SetAnnouncementsOff sets whether a user receives admin announcements
+func (r *UserRepository) SetAnnouncementsOff(id uint, off bool) error {
+	if err := r.db.Model(&model.User{}).Where("id = ?", id).Update("announcements_off", off).Error; err != nil {
+		logger.Error("Failed to update announcement preference",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update announcement preference: %w", err)
+	}
+	return nil
+}
+
+// SetVoiceMode sets how a user's daily reminders are read aloud
+func (r *UserRepository) SetVoiceMode(id uint, mode string) error {
+	if err := r.db.Model(&model.User{}).Where("id = ?", id).Update("voice_mode", mode).Error; err != nil {
+		logger.Error("Failed to update voice mode",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update voice mode: %w", err)
+	}
+	return nil
+}
+
+// SetCombinedDigest sets whether a user's subscriptions due at the same time share one reminder
+func (r *UserRepository) SetCombinedDigest(id uint, combined bool) error {
+	if err := r.db.Model(&model.User{}).Where("id = ?", id).Update("combined_digest", combined).Error; err != nil {
+		logger.Error("Failed to update combined digest preference",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update combined digest preference: %w", err)
+	}
+	return nil
+}
+
+// SetNews sets a user's own news feed ("" = the default feed) and whether the news section is off
+func (r *UserRepository) SetNews(id uint, feed string, off bool) error {
+	err := r.db.Model(&model.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"news_feed": feed, "news_off": off}).Error
+	if err != nil {
+		logger.Error("Failed to update news preference",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update news preference: %w", err)
+	}
+	return nil
+}
+
+// SetRates sets a user's own rate pairs ("" = the default pairs) and whether the rates section is off
+func (r *UserRepository) SetRates(id uint, pairs string, off bool) error {
+	err := r.db.Model(&model.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"rate_pairs": pairs, "rates_off": off}).Error
+	if err != nil {
+		logger.Error("Failed to update rates preference",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update rates preference: %w", err)
+	}
+	return nil
+}
+
+// SetZodiac sets a user's zodiac sign ("" = no horoscope)
+func (r *UserRepository) SetZodiac(id uint, sign string) error {
+	if err := r.db.Model(&model.User{}).Where("id = ?", id).Update("zodiac", sign).Error; err != nil {
+		logger.Error("Failed to update zodiac sign",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update zodiac sign: %w", err)
+	}
+	return nil
+}
+
+// SetHiddenFestivals sets the festival categories left out of a user's calendar, e.g.
+// "western,solar_term" ("" = all shown)
+func (r *UserRepository) SetHiddenFestivals(id uint, categories string) error {
+	if err := r.db.Model(&model.User{}).Where("id = ?", id).Update("hidden_festivals", categories).Error; err != nil {
+		logger.Error("Failed to update hidden festivals",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update hidden festivals: %w", err)
+	}
+	return nil
+}
+
+// SetQuietHours sets a user's quiet hours, e.g. "12:00-13:30" ("" = none)
+func (r *UserRepository) SetQuietHours(id uint, quietHours string) error {
+	if err := r.db.Model(&model.User{}).Where("id = ?", id).Update("quiet_hours", quietHours).Error; err != nil {
+		logger.Error("Failed to update quiet hours",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update quiet hours: %w", err)
+	}
+	return nil
+}
+
+// SetToneHints sets the tone hints of a user's AI reminders (comma-separated, "" = none)
+func (r *UserRepository) SetToneHints(id uint, hints string) error {
+	if err := r.db.Model(&model.User{}).Where("id = ?", id).Update("tone_hints", hints).Error; err != nil {
+		logger.Error("Failed to update tone hints",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update tone hints: %w", err)
+	}
+	return nil
+}
+
+// SetObservationsBan bans a user from submitting weather observations, or lifts the ban
+func (r *UserRepository) SetObservationsBan(id uint, banned bool) error {
+	if err := r.db.Model(&model.User{}).Where("id = ?", id).Update("observations_ban", banned).Error; err != nil {
+		logger.Error("Failed to update observation ban",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to update observation ban: %w", err)
+	}
+	return nil
+}
+
+// SetTier sets a user's service tier and when it ends (nil = does not end)
+func (r *UserRepository) SetTier(id uint, tier string, until *time.Time) error {
+	err := r.db.Model(&model.User{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"tier": tier, "tier_until": until}).Error
+	if err != nil {
+		logger.Error("Failed to update tier",
+			zap.Uint("user_id", id),
+			zap.String("tier", tier),
+			zap.Error(err))
+		return fmt.Errorf("failed to update tier: %w", err)
+	}
+	return nil
+}
+
+// FindWithToneHints returns the users whose AI reminders have tone hints
+func (r *UserRepository) FindWithToneHints() ([]model.User, error) {
+	var users []model.User
+	if err := r.db.Where("tone_hints <> ''").Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to find users with tone hints: %w", err)
+	}
+	return users, nil
+}
+
+// ClaimRegeneration counts a regeneration of an AI reminder on date (YYYY-MM-DD) against the
+// user's daily limit. Returns false when the limit is already reached.
+func (r *UserRepository) ClaimRegeneration(id uint, date string, limit int) (bool, error) {
+	claimed, err := r.claimDailyQuota(id, "regenerations", "regen_date", date, limit)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim regeneration: %w", err)
+	}
+	return claimed, nil
+}
+
+// ReleaseRegeneration gives back a regeneration claimed on date that did not happen
+func (r *UserRepository) ReleaseRegeneration(id uint, date string) error {
+	err := r.db.Model(&model.User{}).
+		Where("id = ? AND regen_date = ? AND regenerations > 0", id, date).
+		Update("regenerations", gorm.Expr("regenerations - 1")).Error
+	if err != nil {
+		return fmt.Errorf("failed to release regeneration: %w", err)
+	}
+	return nil
+}
+
+// ClaimAPICall counts an on-demand external API call (weather query, AI request) on date
+// (YYYY-MM-DD) against the user's daily budget. Returns false when the budget is already used up.
+func (r *UserRepository) ClaimAPICall(id uint, date string, limit int) (bool, error) {
+	claimed, err := r.claimDailyQuota(id, "api_calls", "api_date", date, limit)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim api call: %w", err)
+	}
+	return claimed, nil
+}
+
+// claimDailyQuota increments a user's daily counter column, whose day is kept in dayCol, unless
+// it already reached limit on date. Returns false when it did.
+func (r *UserRepository) claimDailyQuota(id uint, col, dayCol, date string, limit int) (bool, error) {
+	// Start a new count on a new day; conditional so concurrent claims do not reset each other
+	err := r.db.Model(&model.User{}).
+		Where("id = ? AND ("+dayCol+" IS NULL OR "+dayCol+" <> ?)", id, date).
+		Updates(map[string]interface{}{dayCol: date, col: 0}).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to reset %s: %w", col, err)
+	}
+	result := r.db.Model(&model.User{}).
+		Where("id = ? AND "+dayCol+" = ? AND "+col+" < ?", id, date, limit).
+		Update(col, gorm.Expr(col+" + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindAnnouncementRecipients returns the users who receive an announcement: users with an active
+// subscription in one of the cities, or all users when no city is given. Opted-out users are excluded.
+func (r *UserRepository) FindAnnouncementRecipients(cities []string) ([]model.User, error) {
+	query := r.db.Where("announcements_off = ?", false)
+	if len(cities) > 0 {
+		query = query.Where("id IN (?)", r.db.Model(&model.Subscription{}).
+			Select("user_id").
+			Where("active = ? AND city IN ?", true, cities))
+	}
+
+	var users []model.User
+	if err := query.Order("id").Find(&users).Error; err != nil {
+		logger.Error("Failed to find announcement recipients",
+			zap.Strings("cities", cities),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find announcement recipients: %w", err)
+	}
+	return users, nil
+}
+
+// FindByID finds a user by ID
+func (r *UserRepository) FindByID(id uint) (*model.User, error) {
+	logger.Debug("UserRepository.FindByID called",
+		zap.Uint("user_id", id))
+
+	var user model.User
+	err := r.db.Where("id = ?", id).First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			logger.Debug("User not found",
+				zap.Uint("user_id", id))
+			return nil, nil
+		}
+		logger.Error("Failed to find user",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return &user, nil
+}
+
+// List retrieves users ordered by ID with pagination, along with the total count
+func (r *UserRepository) List(offset, limit int) ([]model.User, int64, error) {
+	logger.Debug("UserRepository.List called",
+		zap.Int("offset", offset),
+		zap.Int("limit", limit))
+
+	var total int64
+	if err := r.db.Model(&model.User{}).Count(&total).Error; err != nil {
+		logger.Error("Failed to count users", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	var users []model.User
+	if err := r.db.Order("id ASC").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		logger.Error("Failed to list users", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	logger.Debug("Users listed",
+		zap.Int("count", len(users)),
+		zap.Int64("total", total))
+	return users, total, nil
+}
+
+// Delete soft deletes a user and deactivates all of their subscriptions
+func (r *UserRepository) Delete(id uint) error {
+	logger.Debug("UserRepository.Delete called",
+		zap.Uint("user_id", id))
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Subscription{}).
+			Where("user_id = ?", id).
+			Update("active", false).Error; err != nil {
+			return fmt.Errorf("failed to deactivate subscriptions: %w", err)
+		}
+		// Private health data is removed outright rather than kept with the soft-deleted user
+		if err := tx.Where("user_id = ?", id).Delete(&model.HealthReminder{}).Error; err != nil {
+			return fmt.Errorf("failed to delete health reminders: %w", err)
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.IntervalReminder{}).Error; err != nil {
+			return fmt.Errorf("failed to delete interval reminders: %w", err)
+		}
+		if err := tx.Where("user_id = ?", id).Delete(&model.CalendarFeed{}).Error; err != nil {
+			return fmt.Errorf("failed to delete calendar feeds: %w", err)
+		}
+
+		result := tx.Delete(&model.User{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("user not found")
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to delete user",
+			zap.Uint("user_id", id),
+			zap.Error(err))
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	logger.Info("User deleted successfully",
+		zap.Uint("user_id", id))
+	return nil
+}
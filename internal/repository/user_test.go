@@ -0,0 +1,63 @@
+package repository_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/config"
+	"github.com/cuichanghe/daily-reminder-bot/internal/migration"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	_ = logger.Init(&config.LoggerConfig{Level: "fatal"})
+	os.Exit(m.Run())
+}
+
+// Regenerations and API calls have separate daily quotas that start over on a new day
+func TestClaimDailyQuotas(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migration.Run(db); err != nil {
+		t.Fatal(err)
+	}
+	repo := repository.NewUserRepository(db)
+	user, err := repo.GetOrCreate("", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := map[string]func(date string, limit int) (bool, error){
+		"regeneration": func(date string, limit int) (bool, error) { return repo.ClaimRegeneration(user.ID, date, limit) },
+		"api call":     func(date string, limit int) (bool, error) { return repo.ClaimAPICall(user.ID, date, limit) },
+	}
+	for name, claim := range claims {
+		var got []bool
+		for _, date := range []string{"2025-03-01", "2025-03-01", "2025-03-01", "2025-03-02"} {
+			ok, err := claim(date, 2)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			got = append(got, ok)
+		}
+		if want := []bool{true, true, false, true}; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("%s claims = %v, want %v", name, got, want)
+		}
+	}
+
+	if err := repo.ReleaseRegeneration(user.ID, "2025-03-02"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []bool{true, true, false} {
+		if ok, err := repo.ClaimRegeneration(user.ID, "2025-03-02", 2); err != nil || ok != want {
+			t.Errorf("claim after release = %v, %v, want %v", ok, err, want)
+		}
+	}
+}
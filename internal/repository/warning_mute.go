@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WarningMuteRepository handles database operations for muted warning types
+type WarningMuteRepository struct {
+	db *gorm.DB
+}
+
+// NewWarningMuteRepository creates a new WarningMuteRepository
+func NewWarningMuteRepository(db *gorm.DB) *WarningMuteRepository {
+	return &WarningMuteRepository{db: db}
+}
+
+// Mute silences a warning type for a subscription; muting it again is a no-op
+func (r *WarningMuteRepository) Mute(userID, subscriptionID uint, warningType string) error {
+	mute := &model.WarningMute{UserID: userID, SubscriptionID: subscriptionID, WarningType: warningType}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(mute).Error; err != nil {
+		logger.Error("Failed to mute warning type",
+			zap.Uint("subscription_id", subscriptionID),
+			zap.String("warning_type", warningType),
+			zap.Error(err))
+		return fmt.Errorf("failed to mute warning type: %w", err)
+	}
+	return nil
+}
+
+// Unmute resumes pushes of a warning type for a subscription
+func (r *WarningMuteRepository) Unmute(subscriptionID uint, warningType string) error {
+	err := r.db.Where("subscription_id = ? AND warning_type = ?", subscriptionID, warningType).
+		Delete(&model.WarningMute{}).Error
+	if err != nil {
+		logger.Error("Failed to unmute warning type",
+			zap.Uint("subscription_id", subscriptionID),
+			zap.String("warning_type", warningType),
+			zap.Error(err))
+		return fmt.Errorf("failed to unmute warning type: %w", err)
+	}
+	return nil
+}
+
+// FindTypesBySubscription returns the warning types muted for a subscription
+func (r *WarningMuteRepository) FindTypesBySubscription(subscriptionID uint) ([]string, error) {
+	var types []string
+	err := r.db.Model(&model.WarningMute{}).Where("subscription_id = ?", subscriptionID).
+		Order("warning_type").Pluck("warning_type", &types).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find muted warning types: %w", err)
+	}
+	return types, nil
+}
+
+// FindSubscriptionIDsByType returns the subscriptions that muted a warning type
+func (r *WarningMuteRepository) FindSubscriptionIDsByType(warningType string) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.WarningMute{}).Where("warning_type = ?", warningType).
+		Pluck("subscription_id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find warning mutes: %w", err)
+	}
+	return ids, nil
+}
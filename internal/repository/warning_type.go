@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WarningTypeRepository handles the cached warning type catalog
+type WarningTypeRepository struct {
+	db *gorm.DB
+}
+
+// NewWarningTypeRepository creates a new WarningTypeRepository
+func NewWarningTypeRepository(db *gorm.DB) *WarningTypeRepository {
+	return &WarningTypeRepository{db: db}
+}
+
+// Upsert stores catalog entries, renaming existing codes
+func (r *WarningTypeRepository) Upsert(types []model.WarningType) error {
+	if len(types) == 0 {
+		return nil
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+	}).Create(&types).Error
+	if err != nil {
+		logger.Error("Failed to save warning types", zap.Int("count", len(types)), zap.Error(err))
+		return fmt.Errorf("failed to save warning types: %w", err)
+	}
+	return nil
+}
+
+// FindAll returns the cached catalog ordered by code
+func (r *WarningTypeRepository) FindAll() ([]model.WarningType, error) {
+	var types []model.WarningType
+	if err := r.db.Order("code").Find(&types).Error; err != nil {
+		return nil, fmt.Errorf("failed to find warning types: %w", err)
+	}
+	return types, nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// WebhookRepository handles webhook and webhook outbox data access
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new WebhookRepository
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Create creates a new webhook
+func (r *WebhookRepository) Create(hook *model.Webhook) error {
+	logger.Debug("WebhookRepository.Create called",
+		zap.Uint("user_id", hook.UserID),
+		zap.String("events", hook.Events))
+
+	if err := r.db.Create(hook).Error; err != nil {
+		logger.Error("Failed to create webhook",
+			zap.Uint("user_id", hook.UserID),
+			zap.Error(err))
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	logger.Info("Webhook created successfully",
+		zap.Uint("webhook_id", hook.ID),
+		zap.Uint("user_id", hook.UserID))
+	return nil
+}
+
+// FindByUserID retrieves all webhooks of a user
+func (r *WebhookRepository) FindByUserID(userID uint) ([]model.Webhook, error) {
+	logger.Debug("WebhookRepository.FindByUserID called",
+		zap.Uint("user_id", userID))
+
+	var hooks []model.Webhook
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&hooks).Error
+	if err != nil {
+		logger.Error("Failed to find webhooks",
+			zap.Uint("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// FindActiveByUserIDs retrieves active webhooks belonging to any of the given users
+func (r *WebhookRepository) FindActiveByUserIDs(userIDs []uint) ([]model.Webhook, error) {
+	logger.Debug("WebhookRepository.FindActiveByUserIDs called",
+		zap.Int("user_count", len(userIDs)))
+
+	var hooks []model.Webhook
+	if len(userIDs) == 0 {
+		return hooks, nil
+	}
+	err := r.db.Where("user_id IN ? AND active = ?", userIDs, true).Find(&hooks).Error
+	if err != nil {
+		logger.Error("Failed to find active webhooks",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find active webhooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// CountByUser counts the webhooks of a user
+func (r *WebhookRepository) CountByUser(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Webhook{}).Where("user_id = ?", userID).Count(&count).Error
+	if err != nil {
+		logger.Error("Failed to count webhooks",
+			zap.Uint("user_id", userID),
+			zap.Error(err))
+		return 0, fmt.Errorf("failed to count webhooks: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteByUser soft deletes a webhook owned by the given user
+func (r *WebhookRepository) DeleteByUser(id uint, userID uint) error {
+	logger.Debug("WebhookRepository.DeleteByUser called",
+		zap.Uint("webhook_id", id),
+		zap.Uint("user_id", userID))
+
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Webhook{})
+	if result.Error != nil {
+		logger.Error("Failed to delete webhook",
+			zap.Uint("webhook_id", id),
+			zap.Error(result.Error))
+		return fmt.Errorf("failed to delete webhook: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	logger.Info("Webhook deleted successfully",
+		zap.Uint("webhook_id", id),
+		zap.Uint("user_id", userID))
+	return nil
+}
+
+// Enqueue adds entries to the webhook outbox
+func (r *WebhookRepository) Enqueue(deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	logger.Debug("WebhookRepository.Enqueue called",
+		zap.Int("count", len(deliveries)))
+
+	if err := r.db.Create(&deliveries).Error; err != nil {
+		logger.Error("Failed to enqueue webhook deliveries",
+			zap.Error(err))
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+// FindDue retrieves pending outbox entries whose next attempt is due
+func (r *WebhookRepository) FindDue(now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", model.WebhookStatusPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		logger.Error("Failed to find due webhook deliveries",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to find due webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery saves the state of an outbox entry
+func (r *WebhookRepository) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	if err := r.db.Save(delivery).Error; err != nil {
+		logger.Error("Failed to update webhook delivery",
+			zap.Uint("delivery_id", delivery.ID),
+			zap.Error(err))
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// DeleteFinishedDeliveries removes delivered or failed outbox entries older than the given duration
+func (r *WebhookRepository) DeleteFinishedDeliveries(olderThan time.Duration) error {
+	cutoff := time.Now().Add(-olderThan)
+	result := r.db.Where("status <> ? AND created_at < ?", model.WebhookStatusPending, cutoff).
+		Delete(&model.WebhookDelivery{})
+	if result.Error != nil {
+		logger.Error("Failed to delete finished webhook deliveries",
+			zap.Error(result.Error))
+		return fmt.Errorf("failed to delete finished webhook deliveries: %w", result.Error)
+	}
+
+	logger.Info("Finished webhook deliveries deleted",
+		zap.Int64("deleted_count", result.RowsAffected))
+	return nil
+}
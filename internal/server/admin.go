@@ -0,0 +1,1443 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AdminTokenHeader is the request header carrying the admin API token
+const AdminTokenHeader = "X-Admin-Token"
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// AdminAPI exposes management endpoints for users, subscriptions, todos, announcements,
+// experiments, payments, scheduled job runs and the audit log under /api/v1/; successful changes are audited
+type AdminAPI struct {
+	token            string
+	userRepo         *repository.UserRepository
+	subRepo          *repository.SubscriptionRepository
+	todoRepo         *repository.TodoRepository
+	deliveryRepo     *repository.DeliveryLogRepository
+	announcementRepo *repository.AnnouncementRepository
+	snapshots        *service.SnapshotService // nil when response snapshots are disabled
+	scheduler        *service.SchedulerService
+	experiments      *service.ExperimentService
+	transfers        *service.TransferService
+	tiers            *service.TierService
+	paymentRepo      *repository.PaymentRepository
+	audit            *service.AuditService
+	jobs             *service.JobService
+	events           *service.EventLogService // nil when business events are disabled
+}
+
+// NewAdminAPI creates a new AdminAPI
+func NewAdminAPI(
+	token string,
+	userRepo *repository.UserRepository,
+	subRepo *repository.SubscriptionRepository,
+	todoRepo *repository.TodoRepository,
+	deliveryRepo *repository.DeliveryLogRepository,
+	announcementRepo *repository.AnnouncementRepository,
+	snapshots *service.SnapshotService,
+	scheduler *service.SchedulerService,
+	experiments *service.ExperimentService,
+	transfers *service.TransferService,
+	tiers *service.TierService,
+	paymentRepo *repository.PaymentRepository,
+	audit *service.AuditService,
+	jobs *service.JobService,
+) *AdminAPI {
+	return &AdminAPI{
+		token:            token,
+		userRepo:         userRepo,
+		subRepo:          subRepo,
+		todoRepo:         todoRepo,
+		deliveryRepo:     deliveryRepo,
+		announcementRepo: announcementRepo,
+		snapshots:        snapshots,
+		scheduler:        scheduler,
+		experiments:      experiments,
+		transfers:        transfers,
+		tiers:            tiers,
+		paymentRepo:      paymentRepo,
+		audit:            audit,
+		jobs:             jobs,
+	}
+}
+
+// SetEventLog makes the API emit a subscription_created event for subscriptions it creates
+func (a *AdminAPI) SetEventLog(events *service.EventLogService) {
+	a.events = events
+}
+
+// route describes an admin endpoint; the same table drives routing and the OpenAPI document
+type route struct {
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Query    []queryParam
+	Request  interface{} // Zero value of the JSON request body type, nil if none
+	Response interface{} // Zero value of the JSON response type, nil for 204 responses
+	List     bool        // Response items are wrapped in a paginated list
+	Status   int         // Success status code
+	Audit    string      // Audit log action of successful requests ("" = not audited)
+	handler  http.HandlerFunc
+}
+
+// queryParam describes a query string parameter
+type queryParam struct {
+	Name        string
+	Type        string // OpenAPI scalar type (integer, string)
+	Description string
+}
+
+var paginationParams = []queryParam{
+	{Name: "offset", Type: "integer", Description: "Number of items to skip"},
+	{Name: "limit", Type: "integer", Description: "Maximum number of items to return (default 50, max 500)"},
+}
+
+// routes returns the admin endpoint table
+func (a *AdminAPI) routes() []route {
+	return []route{
+		{Method: "GET", Path: "/api/v1/users", Tag: "users", Summary: "List users",
+			Query: paginationParams, Response: userResponse{}, List: true, Status: http.StatusOK, handler: a.listUsers},
+		{Method: "POST", Path: "/api/v1/users", Tag: "users", Summary: "Create a user (or return the existing one) by chat ID",
+			Request: createUserRequest{}, Response: userResponse{}, Audit: "user.create", Status: http.StatusCreated, handler: a.createUser},
+		{Method: "GET", Path: "/api/v1/users/{id}", Tag: "users", Summary: "Get a user with their subscriptions",
+			Response: userDetailResponse{}, Status: http.StatusOK, handler: a.getUser},
+		{Method: "DELETE", Path: "/api/v1/users/{id}", Tag: "users", Summary: "Delete a user and deactivate their subscriptions",
+			Audit: "user.delete", Status: http.StatusNoContent, handler: a.deleteUser},
+		{Method: "POST", Path: "/api/v1/users/{id}/transfer-link", Tag: "users", Summary: "Create a one-hour link moving the user's subscriptions and todos to the chat that opens and confirms it",
+			Response: transferLinkResponse{}, Audit: "user.transfer_link", Status: http.StatusCreated, handler: a.createTransferLink},
+		{Method: "PUT", Path: "/api/v1/users/{id}/tier", Tag: "users", Summary: "Set the user's service tier (free or premium, optionally for some days)",
+			Request: setTierRequest{}, Response: userResponse{}, Audit: "tier.set", Status: http.StatusOK, handler: a.setTier},
+
+		{Method: "GET", Path: "/api/v1/subscriptions", Tag: "subscriptions", Summary: "List subscriptions",
+			Query:    append([]queryParam{{Name: "user_id", Type: "integer", Description: "Only list subscriptions of this user"}}, paginationParams...),
+			Response: subscriptionResponse{}, List: true, Status: http.StatusOK, handler: a.listSubscriptions},
+		{Method: "POST", Path: "/api/v1/subscriptions", Tag: "subscriptions", Summary: "Create a subscription",
+			Request: createSubscriptionRequest{}, Response: subscriptionResponse{}, Audit: "subscription.create", Status: http.StatusCreated, handler: a.createSubscription},
+		{Method: "GET", Path: "/api/v1/subscriptions/{id}", Tag: "subscriptions", Summary: "Get a subscription with recent deliveries",
+			Response: subscriptionDetailResponse{}, Status: http.StatusOK, handler: a.getSubscription},
+		{Method: "PATCH", Path: "/api/v1/subscriptions/{id}", Tag: "subscriptions", Summary: "Update a subscription",
+			Request: updateSubscriptionRequest{}, Response: subscriptionResponse{}, Audit: "subscription.update", Status: http.StatusOK, handler: a.updateSubscription},
+		{Method: "DELETE", Path: "/api/v1/subscriptions/{id}", Tag: "subscriptions", Summary: "Delete a subscription",
+			Audit: "subscription.delete", Status: http.StatusNoContent, handler: a.deleteSubscription},
+		{Method: "POST", Path: "/api/v1/subscriptions/{id}/test-reminder", Tag: "subscriptions", Summary: "Send the subscription's reminder immediately",
+			Response: testReminderResponse{}, Audit: "subscription.test_reminder", Status: http.StatusOK, handler: a.sendTestReminder},
+		{Method: "GET", Path: "/api/v1/subscriptions/{id}/render", Tag: "subscriptions", Summary: "Dry run: re-render the subscription's reminder weather from a day's stored QWeather responses without sending",
+			Query:    []queryParam{{Name: "date", Type: "string", Description: "Day of the stored responses (YYYY-MM-DD, default today)"}},
+			Response: renderResponse{}, Status: http.StatusOK, handler: a.renderSnapshot},
+
+		{Method: "GET", Path: "/api/v1/subscriptions/{id}/todos", Tag: "todos", Summary: "List todos of a subscription",
+			Response: todoResponse{}, List: true, Status: http.StatusOK, handler: a.listTodos},
+		{Method: "POST", Path: "/api/v1/subscriptions/{id}/todos", Tag: "todos", Summary: "Add a todo to a subscription",
+			Request: createTodoRequest{}, Response: todoResponse{}, Audit: "todo.create", Status: http.StatusCreated, handler: a.createTodo},
+		{Method: "PATCH", Path: "/api/v1/todos/{id}", Tag: "todos", Summary: "Update a todo",
+			Request: updateTodoRequest{}, Response: todoResponse{}, Audit: "todo.update", Status: http.StatusOK, handler: a.updateTodo},
+		{Method: "DELETE", Path: "/api/v1/todos/{id}", Tag: "todos", Summary: "Delete a todo",
+			Audit: "todo.delete", Status: http.StatusNoContent, handler: a.deleteTodo},
+
+		{Method: "GET", Path: "/api/v1/announcements", Tag: "announcements", Summary: "List announcements, newest first",
+			Query: paginationParams, Response: announcementResponse{}, List: true, Status: http.StatusOK, handler: a.listAnnouncements},
+		{Method: "POST", Path: "/api/v1/announcements", Tag: "announcements", Summary: "Schedule an announcement for subscribers of some cities or all users",
+			Request: createAnnouncementRequest{}, Response: announcementResponse{}, Audit: "announcement.create", Status: http.StatusCreated, handler: a.createAnnouncement},
+		{Method: "DELETE", Path: "/api/v1/announcements/{id}", Tag: "announcements", Summary: "Cancel a pending announcement",
+			Audit: "announcement.cancel", Status: http.StatusNoContent, handler: a.cancelAnnouncement},
+
+		{Method: "GET", Path: "/api/v1/snapshots", Tag: "snapshots", Summary: "List the QWeather responses stored on a day",
+			Query: []queryParam{
+				{Name: "date", Type: "string", Description: "Day of the stored responses (YYYY-MM-DD, default today)"},
+				{Name: "location", Type: "string", Description: "Only list responses of this location ID, city name or \"lon,lat\""},
+			},
+			Response: snapshotResponse{}, List: true, Status: http.StatusOK, handler: a.listSnapshots},
+		{Method: "GET", Path: "/api/v1/snapshots/{id}", Tag: "snapshots", Summary: "Get a stored QWeather response with its raw body",
+			Response: snapshotDetailResponse{}, Status: http.StatusOK, handler: a.getSnapshot},
+
+		{Method: "GET", Path: "/api/v1/experiments", Tag: "experiments", Summary: "List reminder format experiments, newest first",
+			Query: paginationParams, Response: experimentResponse{}, List: true, Status: http.StatusOK, handler: a.listExperiments},
+		{Method: "POST", Path: "/api/v1/experiments", Tag: "experiments", Summary: "Create a draft experiment over a reminder format dimension",
+			Request: createExperimentRequest{}, Response: experimentResponse{}, Audit: "experiment.create", Status: http.StatusCreated, handler: a.createExperiment},
+		{Method: "GET", Path: "/api/v1/experiments/{id}", Tag: "experiments", Summary: "Get an experiment with per-variant exposure and engagement metrics",
+			Response: experimentDetailResponse{}, Status: http.StatusOK, handler: a.getExperiment},
+		{Method: "PATCH", Path: "/api/v1/experiments/{id}", Tag: "experiments", Summary: "Start or stop an experiment, or change its description",
+			Request: updateExperimentRequest{}, Response: experimentResponse{}, Audit: "experiment.update", Status: http.StatusOK, handler: a.updateExperiment},
+
+		{Method: "GET", Path: "/api/v1/payments", Tag: "payments", Summary: "List premium payments, newest first",
+			Query: paginationParams, Response: paymentResponse{}, List: true, Status: http.StatusOK, handler: a.listPayments},
+
+		{Method: "GET", Path: "/api/v1/audit", Tag: "audit", Summary: "List audit log entries of admin and destructive actions, newest first",
+			Query: append([]queryParam{
+				{Name: "actor", Type: "string", Description: "Only list entries of this actor, e.g. telegram:123"},
+				{Name: "action", Type: "string", Description: "Only list entries of this action, e.g. user.delete"},
+				{Name: "target", Type: "string", Description: "Only list entries of this target, e.g. user:12"},
+				{Name: "since", Type: "string", Description: "Only list entries from this time on (RFC 3339)"},
+			}, paginationParams...),
+			Response: auditLogResponse{}, List: true, Status: http.StatusOK, handler: a.listAuditLogs},
+
+		{Method: "GET", Path: "/api/v1/jobs", Tag: "jobs", Summary: "List scheduled job runs, newest first",
+			Query: append([]queryParam{
+				{Name: "type", Type: "string", Description: "Only list runs of this job type, e.g. warning_sweep"},
+				{Name: "status", Type: "string", Description: "Only list runs with this status: running, succeeded or failed"},
+			}, paginationParams...),
+			Response: jobRunResponse{}, List: true, Status: http.StatusOK, handler: a.listJobRuns},
+		{Method: "POST", Path: "/api/v1/jobs/{id}/requeue", Tag: "jobs", Summary: "Run a failed job run again in the background with the same scope",
+			Response: jobRunResponse{}, Audit: "job.requeue", Status: http.StatusAccepted, handler: a.requeueJobRun},
+
+		{Method: "GET", Path: "/api/v1/stats/deliveries", Tag: "stats", Summary: "Aggregate reminder deliveries",
+			Query:    []queryParam{{Name: "days", Type: "integer", Description: "Look-back window in days (default 7, max 365)"}},
+			Response: repository.DeliveryStats{}, Status: http.StatusOK, handler: a.deliveryStats},
+		{Method: "GET", Path: "/api/v1/stats/jobs", Tag: "stats", Summary: "The most recent run of each scheduled job",
+			Response: jobStatsResponse{}, Status: http.StatusOK, handler: a.jobStats},
+	}
+}
+
+// Register mounts the admin endpoints and the OpenAPI document on the server
+func (a *AdminAPI) Register(s *Server) {
+	for _, rt := range a.routes() {
+		handler := rt.handler
+		if rt.Audit != "" {
+			handler = a.audited(rt.Audit, handler)
+		}
+		s.Handle(rt.Method+" "+rt.Path, a.authenticate(handler))
+	}
+	s.HandleFunc("GET "+OpenAPIPath, a.serveOpenAPI)
+}
+
+// authenticate rejects requests without a valid admin token.
+// The token is accepted from the X-Admin-Token header or as a Bearer token.
+func (a *AdminAPI) authenticate(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get(AdminTokenHeader)
+		if token == "" {
+			token = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		}
+		if a.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+			logger.Warn("Rejected admin API request",
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("remote_addr", r.RemoteAddr))
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		logger.Debug("Admin API request",
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path))
+		next(w, r)
+	})
+}
+
+// statusRecorder remembers the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records the status code and writes it
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// audited records successful requests to an endpoint in the audit log with the given action.
+// The target is the resource named by the {id} path value, and the detail the request body
+// (see auditDetail).
+func (a *AdminAPI) audited(action string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1<<20))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		if rec.status >= http.StatusBadRequest {
+			return
+		}
+
+		target := ""
+		if id := r.PathValue("id"); id != "" {
+			target = auditResource(r.URL.Path, id) + ":" + id
+		}
+		a.audit.Record(apiActor(r), action, target, auditDetail(body))
+	}
+}
+
+// auditRedactedKeys are the request body keys holding users' private text (todo content); the
+// audit log records only their length, as "<key>_len"
+var auditRedactedKeys = []string{"content"}
+
+// auditDetail returns the audit detail of a request body: the compacted JSON, with the values of
+// auditRedactedKeys replaced by their length
+func auditDetail(body []byte) string {
+	var fields map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if dec.Decode(&fields) == nil {
+		redacted := false
+		for _, key := range auditRedactedKeys {
+			if value, ok := fields[key].(string); ok {
+				delete(fields, key)
+				fields[key+"_len"] = len(value)
+				redacted = true
+			}
+		}
+		if redacted {
+			if data, err := json.Marshal(fields); err == nil {
+				return string(data)
+			}
+		}
+	}
+	detail := string(bytes.TrimSpace(body))
+	var compact bytes.Buffer
+	if json.Compact(&compact, body) == nil {
+		detail = compact.String()
+	}
+	return detail
+}
+
+// auditResource returns the singular name of the resource whose ID is in a path, e.g. "user"
+// for /api/v1/users/12/tier
+func auditResource(path, id string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 1; i < len(segments); i++ {
+		if segments[i] == id {
+			return strings.TrimSuffix(segments[i-1], "s")
+		}
+	}
+	return "resource"
+}
+
+// apiActor returns the audit actor of an admin API request, identified by its remote address
+func apiActor(r *http.Request) string {
+	return "admin_api:" + clientHost(r)
+}
+
+// userResponse is the API representation of a user
+type userResponse struct {
+	ID           uint       `json:"id"`
+	Bot          string     `json:"bot,omitempty"` // Name of the bot the user talks to (omitted for the primary bot)
+	ChatID       int64      `json:"chat_id"`
+	Username     string     `json:"username,omitempty"`
+	FirstName    string     `json:"first_name,omitempty"`
+	LastName     string     `json:"last_name,omitempty"`
+	LanguageCode string     `json:"language_code,omitempty"`
+	ReferredByID *uint      `json:"referred_by_id,omitempty"`
+	Tier         string     `json:"tier"`                 // Service tier in effect (free or premium)
+	TierUntil    *time.Time `json:"tier_until,omitempty"` // When the premium tier ends (omitted = does not end)
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// subscriptionResponse is the API representation of a subscription
+type subscriptionResponse struct {
+	ID            uint      `json:"id"`
+	UserID        uint      `json:"user_id"`
+	City          string    `json:"city"`
+	ReminderTime  string    `json:"reminder_time"`
+	Active        bool      `json:"active"`
+	EnableWarning bool      `json:"enable_warning"`
+	SharedListID  *uint     `json:"shared_list_id,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// todoResponse is the API representation of a todo
+type todoResponse struct {
+	ID             uint       `json:"id"`
+	SubscriptionID uint       `json:"subscription_id"`
+	Content        string     `json:"content"`
+	Completed      bool       `json:"completed"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// deliveryResponse is the API representation of a delivery log entry
+type deliveryResponse struct {
+	Kind      string    `json:"kind"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// userDetailResponse is a user together with their subscriptions
+type userDetailResponse struct {
+	userResponse
+	Subscriptions []subscriptionResponse `json:"subscriptions"`
+}
+
+// subscriptionDetailResponse is a subscription together with its recent deliveries
+type subscriptionDetailResponse struct {
+	subscriptionResponse
+	RecentDeliveries []deliveryResponse `json:"recent_deliveries"`
+}
+
+// announcementResponse is the API representation of an announcement
+type announcementResponse struct {
+	ID          uint       `json:"id"`
+	Message     string     `json:"message"`
+	Cities      []string   `json:"cities"`
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	Status      string     `json:"status"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+	Recipients  int        `json:"recipients"`
+	Failures    int        `json:"failures"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// paymentResponse is the API representation of a premium payment
+type paymentResponse struct {
+	ID               uint       `json:"id"`
+	UserID           uint       `json:"user_id"`
+	Days             int        `json:"days"`
+	Currency         string     `json:"currency"`
+	Amount           int        `json:"amount"`
+	TelegramChargeID string     `json:"telegram_charge_id"`
+	ProviderChargeID string     `json:"provider_charge_id,omitempty"`
+	TierUntil        *time.Time `json:"tier_until,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// auditLogResponse is the API representation of an audit log entry
+type auditLogResponse struct {
+	ID        uint      `json:"id"`
+	Actor     string    `json:"actor"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// jobRunResponse is the API representation of a scheduled job run
+type jobRunResponse struct {
+	ID           uint       `json:"id"`
+	Type         string     `json:"type"`
+	City         string     `json:"city,omitempty"`
+	Status       string     `json:"status"`
+	Items        int        `json:"items"`
+	Failures     int        `json:"failures"`
+	Error        string     `json:"error,omitempty"`
+	RequeuedFrom *uint      `json:"requeued_from,omitempty"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// jobStatsResponse lists the most recent run of each scheduled job
+type jobStatsResponse struct {
+	Jobs []jobRunResponse `json:"jobs"`
+}
+
+// testReminderResponse reports a triggered test reminder
+type testReminderResponse struct {
+	Sent           bool `json:"sent"`
+	SubscriptionID uint `json:"subscription_id"`
+}
+
+// renderResponse is a reminder re-rendered from stored QWeather responses
+type renderResponse struct {
+	SubscriptionID uint   `json:"subscription_id"`
+	Date           string `json:"date"`
+	Text           string `json:"text"`
+}
+
+// snapshotResponse is the API representation of a stored QWeather response
+type snapshotResponse struct {
+	ID        uint      `json:"id"`
+	Date      string    `json:"date"`
+	Request   string    `json:"request"`
+	Location  string    `json:"location"`
+	Size      int       `json:"size"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// snapshotDetailResponse is a stored QWeather response with its decompressed body
+type snapshotDetailResponse struct {
+	snapshotResponse
+	Body string `json:"body"`
+}
+
+// transferLinkResponse is a link transferring a user's subscriptions to another chat
+type transferLinkResponse struct {
+	UserID    uint      `json:"user_id"`
+	Link      string    `json:"link"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// setTierRequest is the body of PUT /api/v1/users/{id}/tier
+type setTierRequest struct {
+	Tier string `json:"tier"` // free or premium
+	Days int    `json:"days"` // Days the premium tier lasts (0 = does not end)
+}
+
+// createUserRequest is the body of POST /api/v1/users
+type createUserRequest struct {
+	ChatID int64  `json:"chat_id"`
+	Bot    string `json:"bot"` // Name of a bot from telegram.bots (empty = the primary bot)
+}
+
+// createSubscriptionRequest is the body of POST /api/v1/subscriptions
+type createSubscriptionRequest struct {
+	UserID        uint   `json:"user_id"`
+	City          string `json:"city"`
+	ReminderTime  string `json:"reminder_time"`
+	EnableWarning *bool  `json:"enable_warning"`
+}
+
+// updateSubscriptionRequest is the body of PATCH /api/v1/subscriptions/{id}
+type updateSubscriptionRequest struct {
+	ReminderTime  *string `json:"reminder_time"`
+	Active        *bool   `json:"active"`
+	EnableWarning *bool   `json:"enable_warning"`
+}
+
+// createTodoRequest is the body of POST /api/v1/subscriptions/{id}/todos
+type createTodoRequest struct {
+	Content string `json:"content"`
+}
+
+// updateTodoRequest is the body of PATCH /api/v1/todos/{id}
+type updateTodoRequest struct {
+	Content   *string `json:"content"`
+	Completed *bool   `json:"completed"`
+}
+
+// createAnnouncementRequest is the body of POST /api/v1/announcements
+type createAnnouncementRequest struct {
+	Message     string     `json:"message"`
+	Cities      []string   `json:"cities"`       // Empty means all users
+	ScheduledAt *time.Time `json:"scheduled_at"` // RFC 3339; omitted means as soon as possible
+}
+
+// listResponse wraps a paginated list
+type listResponse struct {
+	Items  interface{} `json:"items"`
+	Total  int64       `json:"total"`
+	Offset int         `json:"offset"`
+	Limit  int         `json:"limit"`
+}
+
+func toUserResponse(u model.User) userResponse {
+	return userResponse{
+		ID:           u.ID,
+		Bot:          u.Bot,
+		ChatID:       u.ChatID,
+		Username:     u.Username,
+		FirstName:    u.FirstName,
+		LastName:     u.LastName,
+		LanguageCode: u.LanguageCode,
+		ReferredByID: u.ReferredByID,
+		Tier:         u.EffectiveTier(time.Now()),
+		TierUntil:    u.TierUntil,
+		CreatedAt:    u.CreatedAt,
+	}
+}
+
+func toSubscriptionResponse(s model.Subscription) subscriptionResponse {
+	return subscriptionResponse{
+		ID:            s.ID,
+		UserID:        s.UserID,
+		City:          s.City,
+		ReminderTime:  s.ReminderTime,
+		Active:        s.Active,
+		EnableWarning: s.EnableWarning,
+		SharedListID:  s.SharedListID,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
+
+func toTodoResponse(t model.Todo) todoResponse {
+	return todoResponse{
+		ID:             t.ID,
+		SubscriptionID: t.SubscriptionID,
+		Content:        t.Content,
+		Completed:      t.Completed,
+		CompletedAt:    t.CompletedAt,
+		CreatedAt:      t.CreatedAt,
+	}
+}
+
+func toAnnouncementResponse(a model.Announcement) announcementResponse {
+	cities := a.CityList()
+	if cities == nil {
+		cities = []string{}
+	}
+	return announcementResponse{
+		ID:          a.ID,
+		Message:     a.Message,
+		Cities:      cities,
+		ScheduledAt: a.ScheduledAt,
+		Status:      a.Status,
+		SentAt:      a.SentAt,
+		Recipients:  a.Recipients,
+		Failures:    a.Failures,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+func toSnapshotResponse(s model.APISnapshot) snapshotResponse {
+	return snapshotResponse{
+		ID:        s.ID,
+		Date:      s.Date,
+		Request:   s.Request,
+		Location:  s.Location,
+		Size:      s.Size,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// listUsers handles GET /api/v1/users
+func (a *AdminAPI) listUsers(w http.ResponseWriter, r *http.Request) {
+	offset, limit := pagination(r)
+	users, total, err := a.userRepo.List(offset, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]userResponse, 0, len(users))
+	for _, u := range users {
+		items = append(items, toUserResponse(u))
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: items, Total: total, Offset: offset, Limit: limit})
+}
+
+// createUser handles POST /api/v1/users
+func (a *AdminAPI) createUser(w http.ResponseWriter, r *http.Request) {
+	var req createUserRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.ChatID == 0 {
+		writeError(w, http.StatusBadRequest, "chat_id is required")
+		return
+	}
+
+	if len(req.Bot) > 32 {
+		writeError(w, http.StatusBadRequest, "bot must be at most 32 bytes")
+		return
+	}
+
+	user, err := a.userRepo.GetOrCreate(req.Bot, req.ChatID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, toUserResponse(*user))
+}
+
+// getUser handles GET /api/v1/users/{id}, including the user's subscriptions
+func (a *AdminAPI) getUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := a.userRepo.FindByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if user == nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	subs, _, err := a.subRepo.List(user.ID, 0, maxPageLimit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	subItems := make([]subscriptionResponse, 0, len(subs))
+	for _, s := range subs {
+		subItems = append(subItems, toSubscriptionResponse(s))
+	}
+
+	writeJSON(w, http.StatusOK, userDetailResponse{toUserResponse(*user), subItems})
+}
+
+// deleteUser handles DELETE /api/v1/users/{id}
+func (a *AdminAPI) deleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := a.userRepo.FindByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if user == nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	if err := a.userRepo.Delete(id); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// createTransferLink handles POST /api/v1/users/{id}/transfer-link, for users who lost access to
+// their old account; the link must be opened and confirmed in the new chat
+func (a *AdminAPI) createTransferLink(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := a.userRepo.FindByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if user == nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	link, expires, err := a.transfers.Link(user, time.Now())
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	logger.Info("Transfer link created via admin API", zap.Uint("user_id", user.ID))
+	writeJSON(w, http.StatusCreated, transferLinkResponse{UserID: user.ID, Link: link, ExpiresAt: expires})
+}
+
+// setTier handles PUT /api/v1/users/{id}/tier
+func (a *AdminAPI) setTier(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req setTierRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Tier != model.TierFree && req.Tier != model.TierPremium {
+		writeError(w, http.StatusBadRequest, "tier must be free or premium")
+		return
+	}
+	if req.Days < 0 || (req.Days > 0 && req.Tier != model.TierPremium) {
+		writeError(w, http.StatusBadRequest, "days must be positive and only set for the premium tier")
+		return
+	}
+
+	user, err := a.userRepo.FindByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if user == nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	if err := a.tiers.SetTier(user, req.Tier, req.Days, time.Now()); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserResponse(*user))
+}
+
+// listSubscriptions handles GET /api/v1/subscriptions[?user_id=]
+func (a *AdminAPI) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	offset, limit := pagination(r)
+
+	var userID uint
+	if v := r.URL.Query().Get("user_id"); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid user_id")
+			return
+		}
+		userID = uint(parsed)
+	}
+
+	subs, total, err := a.subRepo.List(userID, offset, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]subscriptionResponse, 0, len(subs))
+	for _, s := range subs {
+		items = append(items, toSubscriptionResponse(s))
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: items, Total: total, Offset: offset, Limit: limit})
+}
+
+// createSubscription handles POST /api/v1/subscriptions
+func (a *AdminAPI) createSubscription(w http.ResponseWriter, r *http.Request) {
+	var req createSubscriptionRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	req.City = strings.TrimSpace(req.City)
+	if req.UserID == 0 || req.City == "" {
+		writeError(w, http.StatusBadRequest, "user_id and city are required")
+		return
+	}
+	if !isValidReminderTime(req.ReminderTime) {
+		writeError(w, http.StatusBadRequest, "reminder_time must be in HH:MM format")
+		return
+	}
+
+	user, err := a.userRepo.FindByID(req.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if user == nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	existing, err := a.subRepo.FindByUserAndCity(user.ID, req.City)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if existing != nil {
+		writeError(w, http.StatusConflict, "user already subscribes to this city")
+		return
+	}
+
+	// A cancelled subscription for the same city is restored rather than duplicated
+	sub, err := a.subRepo.FindByUserAndCityUnscoped(user.ID, req.City)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	restored := sub != nil
+	if restored {
+		sub.ReminderTime = req.ReminderTime
+		sub.EnableWarning = req.EnableWarning == nil || *req.EnableWarning
+		err = a.subRepo.Restore(sub)
+	} else {
+		sub = &model.Subscription{
+			UserID:        user.ID,
+			City:          req.City,
+			ReminderTime:  req.ReminderTime,
+			Active:        true,
+			EnableWarning: req.EnableWarning == nil || *req.EnableWarning,
+		}
+		err = a.subRepo.Create(sub)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if a.events != nil {
+		a.events.Emit(service.SubscriptionCreatedEvent(sub, "admin_api", restored))
+	}
+	writeJSON(w, http.StatusCreated, toSubscriptionResponse(*sub))
+}
+
+// getSubscription handles GET /api/v1/subscriptions/{id}, including recent deliveries
+func (a *AdminAPI) getSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, ok := a.loadSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	deliveries := make([]deliveryResponse, 0)
+	if a.deliveryRepo != nil {
+		logs, err := a.deliveryRepo.FindBySubscriptionID(sub.ID, 20)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, l := range logs {
+			deliveries = append(deliveries, deliveryResponse{
+				Kind:      l.Kind,
+				Success:   l.Success,
+				Error:     l.Error,
+				CreatedAt: l.CreatedAt,
+			})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, subscriptionDetailResponse{toSubscriptionResponse(*sub), deliveries})
+}
+
+// updateSubscription handles PATCH /api/v1/subscriptions/{id}
+func (a *AdminAPI) updateSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, ok := a.loadSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateSubscriptionRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	if req.ReminderTime != nil {
+		if !isValidReminderTime(*req.ReminderTime) {
+			writeError(w, http.StatusBadRequest, "reminder_time must be in HH:MM format")
+			return
+		}
+		sub.ReminderTime = *req.ReminderTime
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+	if req.EnableWarning != nil {
+		sub.EnableWarning = *req.EnableWarning
+	}
+
+	if err := a.subRepo.Update(sub); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, toSubscriptionResponse(*sub))
+}
+
+// deleteSubscription handles DELETE /api/v1/subscriptions/{id}
+func (a *AdminAPI) deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	sub, ok := a.loadSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	if err := a.subRepo.Delete(sub.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sendTestReminder handles POST /api/v1/subscriptions/{id}/test-reminder
+func (a *AdminAPI) sendTestReminder(w http.ResponseWriter, r *http.Request) {
+	sub, ok := a.loadSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	if err := a.scheduler.SendTestReminder(sub.ID); err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, testReminderResponse{Sent: true, SubscriptionID: sub.ID})
+}
+
+// renderSnapshot handles GET /api/v1/subscriptions/{id}/render
+func (a *AdminAPI) renderSnapshot(w http.ResponseWriter, r *http.Request) {
+	if a.snapshots == nil {
+		writeError(w, http.StatusNotFound, "api snapshots are disabled")
+		return
+	}
+	sub, ok := a.loadSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	date := a.snapshotDate(r)
+	text, err := a.scheduler.RenderSnapshot(sub.ID, date)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, renderResponse{SubscriptionID: sub.ID, Date: date, Text: text})
+}
+
+// listSnapshots handles GET /api/v1/snapshots
+func (a *AdminAPI) listSnapshots(w http.ResponseWriter, r *http.Request) {
+	if a.snapshots == nil {
+		writeError(w, http.StatusNotFound, "api snapshots are disabled")
+		return
+	}
+
+	snapshots, err := a.snapshots.Repository().List(a.snapshotDate(r), r.URL.Query().Get("location"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]snapshotResponse, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		items = append(items, toSnapshotResponse(snapshot))
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: items, Total: int64(len(items)), Limit: len(items)})
+}
+
+// getSnapshot handles GET /api/v1/snapshots/{id}
+func (a *AdminAPI) getSnapshot(w http.ResponseWriter, r *http.Request) {
+	if a.snapshots == nil {
+		writeError(w, http.StatusNotFound, "api snapshots are disabled")
+		return
+	}
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	snapshot, err := a.snapshots.Repository().FindByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if snapshot == nil {
+		writeError(w, http.StatusNotFound, "snapshot not found")
+		return
+	}
+	body, err := a.snapshots.Payload(snapshot)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, snapshotDetailResponse{snapshotResponse: toSnapshotResponse(*snapshot), Body: string(body)})
+}
+
+// listTodos handles GET /api/v1/subscriptions/{id}/todos
+func (a *AdminAPI) listTodos(w http.ResponseWriter, r *http.Request) {
+	sub, ok := a.loadSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	todos, err := a.todoRepo.FindBySubscriptionID(sub.TodoListID())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]todoResponse, 0, len(todos))
+	for _, t := range todos {
+		items = append(items, toTodoResponse(t))
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: items, Total: int64(len(items)), Limit: len(items)})
+}
+
+// createTodo handles POST /api/v1/subscriptions/{id}/todos
+func (a *AdminAPI) createTodo(w http.ResponseWriter, r *http.Request) {
+	sub, ok := a.loadSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	var req createTodoRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	req.Content = strings.TrimSpace(req.Content)
+	if req.Content == "" {
+		writeError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+
+	todo := &model.Todo{SubscriptionID: sub.TodoListID(), Content: req.Content}
+	if err := a.todoRepo.Create(todo); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, toTodoResponse(*todo))
+}
+
+// updateTodo handles PATCH /api/v1/todos/{id}
+func (a *AdminAPI) updateTodo(w http.ResponseWriter, r *http.Request) {
+	todo, ok := a.loadTodo(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateTodoRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+
+	if req.Content != nil {
+		content := strings.TrimSpace(*req.Content)
+		if content == "" {
+			writeError(w, http.StatusBadRequest, "content must not be empty")
+			return
+		}
+		todo.Content = content
+	}
+	if req.Completed != nil && *req.Completed != todo.Completed {
+		todo.Completed = *req.Completed
+		todo.CompletedAt, todo.CompletedBy = nil, nil
+		if todo.Completed {
+			now := time.Now()
+			todo.CompletedAt = &now
+		}
+	}
+
+	if err := a.todoRepo.Update(todo); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, toTodoResponse(*todo))
+}
+
+// deleteTodo handles DELETE /api/v1/todos/{id}
+func (a *AdminAPI) deleteTodo(w http.ResponseWriter, r *http.Request) {
+	todo, ok := a.loadTodo(w, r)
+	if !ok {
+		return
+	}
+
+	if err := a.todoRepo.Delete(todo.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deliveryStats handles GET /api/v1/stats/deliveries[?days=7]
+func (a *AdminAPI) deliveryStats(w http.ResponseWriter, r *http.Request) {
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > 365 {
+			writeError(w, http.StatusBadRequest, "days must be between 1 and 365")
+			return
+		}
+		days = parsed
+	}
+
+	stats, err := a.deliveryRepo.Stats(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// jobStats handles GET /api/v1/stats/jobs
+func (a *AdminAPI) jobStats(w http.ResponseWriter, r *http.Request) {
+	runs, err := a.jobs.Latest()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := jobStatsResponse{Jobs: make([]jobRunResponse, 0, len(runs))}
+	for _, run := range runs {
+		resp.Jobs = append(resp.Jobs, toJobRunResponse(run))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// listJobRuns handles GET /api/v1/jobs[?type=&status=]
+func (a *AdminAPI) listJobRuns(w http.ResponseWriter, r *http.Request) {
+	offset, limit := pagination(r)
+	q := r.URL.Query()
+	filter := repository.JobRunFilter{Type: q.Get("type"), Status: q.Get("status")}
+	switch filter.Status {
+	case "", model.JobStatusRunning, model.JobStatusSucceeded, model.JobStatusFailed:
+	default:
+		writeError(w, http.StatusBadRequest, "status must be running, succeeded or failed")
+		return
+	}
+
+	runs, total, err := a.jobs.List(filter, offset, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	items := make([]jobRunResponse, 0, len(runs))
+	for _, run := range runs {
+		items = append(items, toJobRunResponse(run))
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: items, Total: total, Offset: offset, Limit: limit})
+}
+
+// requeueJobRun handles POST /api/v1/jobs/{id}/requeue
+func (a *AdminAPI) requeueJobRun(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	run, err := a.jobs.Requeue(id)
+	switch {
+	case errors.Is(err, service.ErrJobRunNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, service.ErrJobRunNotFailed), errors.Is(err, service.ErrJobUnknown), errors.Is(err, service.ErrJobRunning):
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, toJobRunResponse(*run))
+}
+
+// toJobRunResponse converts a job run to its API representation
+func toJobRunResponse(run model.JobRun) jobRunResponse {
+	return jobRunResponse{
+		ID:           run.ID,
+		Type:         run.Type,
+		City:         run.City,
+		Status:       run.Status,
+		Items:        run.Items,
+		Failures:     run.Failures,
+		Error:        run.Error,
+		RequeuedFrom: run.RequeuedFrom,
+		StartedAt:    run.StartedAt,
+		FinishedAt:   run.FinishedAt,
+	}
+}
+
+// listAnnouncements handles GET /api/v1/announcements
+func (a *AdminAPI) listAnnouncements(w http.ResponseWriter, r *http.Request) {
+	offset, limit := pagination(r)
+	announcements, total, err := a.announcementRepo.List(offset, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]announcementResponse, 0, len(announcements))
+	for _, an := range announcements {
+		items = append(items, toAnnouncementResponse(an))
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: items, Total: total, Offset: offset, Limit: limit})
+}
+
+// listPayments handles GET /api/v1/payments
+func (a *AdminAPI) listPayments(w http.ResponseWriter, r *http.Request) {
+	offset, limit := pagination(r)
+	payments, total, err := a.paymentRepo.List(offset, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]paymentResponse, 0, len(payments))
+	for _, p := range payments {
+		items = append(items, paymentResponse{
+			ID:               p.ID,
+			UserID:           p.UserID,
+			Days:             p.Days,
+			Currency:         p.Currency,
+			Amount:           p.Amount,
+			TelegramChargeID: p.TelegramChargeID,
+			ProviderChargeID: p.ProviderChargeID,
+			TierUntil:        p.TierUntil,
+			CreatedAt:        p.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: items, Total: total, Offset: offset, Limit: limit})
+}
+
+// listAuditLogs handles GET /api/v1/audit[?actor=&action=&target=&since=]
+func (a *AdminAPI) listAuditLogs(w http.ResponseWriter, r *http.Request) {
+	offset, limit := pagination(r)
+	q := r.URL.Query()
+	filter := repository.AuditLogFilter{Actor: q.Get("actor"), Action: q.Get("action"), Target: q.Get("target")}
+	if v := q.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since, expected RFC 3339")
+			return
+		}
+		filter.Since = since
+	}
+
+	entries, total, err := a.audit.List(filter, offset, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	items := make([]auditLogResponse, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, auditLogResponse{
+			ID:        e.ID,
+			Actor:     e.Actor,
+			Action:    e.Action,
+			Target:    e.Target,
+			Detail:    e.Detail,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: items, Total: total, Offset: offset, Limit: limit})
+}
+
+// createAnnouncement handles POST /api/v1/announcements
+func (a *AdminAPI) createAnnouncement(w http.ResponseWriter, r *http.Request) {
+	var req createAnnouncementRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	req.Message = strings.TrimSpace(req.Message)
+	if req.Message == "" {
+		writeError(w, http.StatusBadRequest, "message is required")
+		return
+	}
+	if len(req.Message) > 4000 {
+		writeError(w, http.StatusBadRequest, "message must be at most 4000 bytes")
+		return
+	}
+
+	cities := make([]string, 0, len(req.Cities))
+	for _, city := range req.Cities {
+		city = strings.TrimSpace(city)
+		if city == "" || strings.Contains(city, ",") {
+			writeError(w, http.StatusBadRequest, "invalid city: "+city)
+			return
+		}
+		cities = append(cities, city)
+	}
+
+	scheduledAt := time.Now()
+	if req.ScheduledAt != nil {
+		scheduledAt = *req.ScheduledAt
+	}
+
+	announcement := &model.Announcement{
+		Message:     req.Message,
+		Cities:      strings.Join(cities, ","),
+		ScheduledAt: scheduledAt,
+		Status:      model.AnnouncementPending,
+	}
+	if err := a.announcementRepo.Create(announcement); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, toAnnouncementResponse(*announcement))
+}
+
+// cancelAnnouncement handles DELETE /api/v1/announcements/{id}
+func (a *AdminAPI) cancelAnnouncement(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	announcement, err := a.announcementRepo.FindByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if announcement == nil {
+		writeError(w, http.StatusNotFound, "announcement not found")
+		return
+	}
+
+	cancelled, err := a.announcementRepo.Cancel(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !cancelled {
+		writeError(w, http.StatusConflict, "announcement is already "+announcement.Status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadSubscription resolves the {id} path value to a subscription, writing an error response on failure
+func (a *AdminAPI) loadSubscription(w http.ResponseWriter, r *http.Request) (*model.Subscription, bool) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	sub, err := a.subRepo.FindByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if sub == nil {
+		writeError(w, http.StatusNotFound, "subscription not found")
+		return nil, false
+	}
+	return sub, true
+}
+
+// loadTodo resolves the {id} path value to a todo, writing an error response on failure
+func (a *AdminAPI) loadTodo(w http.ResponseWriter, r *http.Request) (*model.Todo, bool) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	todo, err := a.todoRepo.FindByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if todo == nil {
+		writeError(w, http.StatusNotFound, "todo not found")
+		return nil, false
+	}
+	return todo, true
+}
+
+// pathID parses the {id} path value
+func pathID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// snapshotDate returns the date query parameter, defaulting to the snapshots' current day
+func (a *AdminAPI) snapshotDate(r *http.Request) string {
+	if date := r.URL.Query().Get("date"); date != "" {
+		return date
+	}
+	return a.snapshots.Today()
+}
+
+// pagination parses offset/limit query parameters with sane defaults
+func pagination(r *http.Request) (int, int) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return offset, limit
+}
+
+// decodeBody decodes a JSON request body, writing a 400 response on failure
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// isValidReminderTime validates HH:MM time format
+func isValidReminderTime(v string) bool {
+	if len(v) != 5 {
+		return false
+	}
+	_, err := time.Parse("15:04", v)
+	return err == nil
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+)
+
+// experimentKeyPattern restricts experiment keys, which salt the user bucketing
+var experimentKeyPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// experimentResponse is the API representation of an experiment
+type experimentResponse struct {
+	ID          uint       `json:"id"`
+	Key         string     `json:"key"`
+	Dimension   string     `json:"dimension"`
+	Variants    []string   `json:"variants"`
+	Description string     `json:"description,omitempty"`
+	Status      string     `json:"status"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	StoppedAt   *time.Time `json:"stopped_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// experimentDetailResponse is an experiment together with its per-variant metrics
+type experimentDetailResponse struct {
+	experimentResponse
+	Metrics []repository.VariantMetrics `json:"metrics"`
+}
+
+// createExperimentRequest is the body of POST /api/v1/experiments
+type createExperimentRequest struct {
+	Key         string   `json:"key"`       // Lowercase letters, digits, "_" and "-"; salts the bucketing
+	Dimension   string   `json:"dimension"` // persona, section_order or emoji
+	Variants    []string `json:"variants"`  // At least two variants supported by the dimension
+	Description string   `json:"description"`
+}
+
+// updateExperimentRequest is the body of PATCH /api/v1/experiments/{id}
+type updateExperimentRequest struct {
+	Status      *string `json:"status"` // running (from draft) or stopped (from running)
+	Description *string `json:"description"`
+}
+
+func toExperimentResponse(e model.Experiment) experimentResponse {
+	return experimentResponse{
+		ID:          e.ID,
+		Key:         e.Key,
+		Dimension:   e.Dimension,
+		Variants:    e.VariantList(),
+		Description: e.Description,
+		Status:      e.Status,
+		StartedAt:   e.StartedAt,
+		StoppedAt:   e.StoppedAt,
+		CreatedAt:   e.CreatedAt,
+	}
+}
+
+// listExperiments handles GET /api/v1/experiments
+func (a *AdminAPI) listExperiments(w http.ResponseWriter, r *http.Request) {
+	offset, limit := pagination(r)
+	experiments, total, err := a.experiments.Repository().List(offset, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	items := make([]experimentResponse, 0, len(experiments))
+	for _, e := range experiments {
+		items = append(items, toExperimentResponse(e))
+	}
+	writeJSON(w, http.StatusOK, listResponse{Items: items, Total: total, Offset: offset, Limit: limit})
+}
+
+// createExperiment handles POST /api/v1/experiments
+func (a *AdminAPI) createExperiment(w http.ResponseWriter, r *http.Request) {
+	var req createExperimentRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if !experimentKeyPattern.MatchString(req.Key) {
+		writeError(w, http.StatusBadRequest, "key must be 1-32 lowercase letters, digits, '_' or '-'")
+		return
+	}
+	supported, ok := service.ExperimentVariants[req.Dimension]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "dimension must be one of persona, section_order, emoji")
+		return
+	}
+	if len(req.Variants) < 2 {
+		writeError(w, http.StatusBadRequest, "at least two variants are required")
+		return
+	}
+	seen := make(map[string]bool, len(req.Variants))
+	for _, variant := range req.Variants {
+		if !service.IsExperimentVariant(req.Dimension, variant) {
+			writeError(w, http.StatusBadRequest, "unsupported variant "+variant+"; "+req.Dimension+" supports "+strings.Join(supported, ", "))
+			return
+		}
+		if seen[variant] {
+			writeError(w, http.StatusBadRequest, "duplicate variant "+variant)
+			return
+		}
+		seen[variant] = true
+	}
+	if len(req.Description) > 255 {
+		writeError(w, http.StatusBadRequest, "description must be at most 255 bytes")
+		return
+	}
+
+	existing, err := a.experiments.Repository().FindByKey(req.Key)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if existing != nil {
+		writeError(w, http.StatusConflict, "experiment key already exists")
+		return
+	}
+
+	experiment := &model.Experiment{
+		Key:         req.Key,
+		Dimension:   req.Dimension,
+		Variants:    strings.Join(req.Variants, ","),
+		Description: strings.TrimSpace(req.Description),
+		Status:      model.ExperimentDraft,
+	}
+	if err := a.experiments.Repository().Create(experiment); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, toExperimentResponse(*experiment))
+}
+
+// getExperiment handles GET /api/v1/experiments/{id}
+func (a *AdminAPI) getExperiment(w http.ResponseWriter, r *http.Request) {
+	experiment, ok := a.loadExperiment(w, r)
+	if !ok {
+		return
+	}
+
+	metrics, err := a.experiments.Repository().VariantMetrics(experiment.ID, experiment.VariantList())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, experimentDetailResponse{
+		experimentResponse: toExperimentResponse(*experiment),
+		Metrics:            metrics,
+	})
+}
+
+// updateExperiment handles PATCH /api/v1/experiments/{id}
+func (a *AdminAPI) updateExperiment(w http.ResponseWriter, r *http.Request) {
+	experiment, ok := a.loadExperiment(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateExperimentRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	repo := a.experiments.Repository()
+
+	if req.Description != nil {
+		if len(*req.Description) > 255 {
+			writeError(w, http.StatusBadRequest, "description must be at most 255 bytes")
+			return
+		}
+		if err := repo.UpdateDescription(experiment.ID, strings.TrimSpace(*req.Description)); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	if req.Status != nil && *req.Status != experiment.Status {
+		var changed bool
+		var err error
+		switch *req.Status {
+		case model.ExperimentRunning:
+			if experiment.Status != model.ExperimentDraft {
+				writeError(w, http.StatusConflict, "only draft experiments can be started")
+				return
+			}
+			changed, err = repo.Start(experiment, time.Now())
+			if err == nil && !changed {
+				writeError(w, http.StatusConflict, "another "+experiment.Dimension+" experiment is running")
+				return
+			}
+		case model.ExperimentStopped:
+			changed, err = repo.Stop(experiment.ID, time.Now())
+			if err == nil && !changed {
+				writeError(w, http.StatusConflict, "experiment is not running")
+				return
+			}
+		default:
+			writeError(w, http.StatusBadRequest, "status must be running or stopped")
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	updated, err := repo.FindByID(experiment.ID)
+	if err != nil || updated == nil {
+		writeError(w, http.StatusInternalServerError, "failed to reload experiment")
+		return
+	}
+	writeJSON(w, http.StatusOK, toExperimentResponse(*updated))
+}
+
+// loadExperiment resolves the {id} path value to an experiment, writing an error response on failure
+func (a *AdminAPI) loadExperiment(w http.ResponseWriter, r *http.Request) (*model.Experiment, bool) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	experiment, err := a.experiments.Repository().FindByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if experiment == nil {
+		writeError(w, http.StatusNotFound, "experiment not found")
+		return nil, false
+	}
+	return experiment, true
+}
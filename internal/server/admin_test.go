@@ -0,0 +1,21 @@
+package server
+
+import "testing"
+
+func TestAuditDetail(t *testing.T) {
+	tests := []struct {
+		body string
+		want string
+	}{
+		{body: `{"content": "交电费"}`, want: `{"content_len":9}`},
+		{body: `{"content": "买牛奶", "completed": true}`, want: `{"completed":true,"content_len":9}`},
+		{body: `{"completed": true, "id": 12345678901234}`, want: `{"completed":true,"id":12345678901234}`},
+		{body: `{"tier": "premium",  "days": 30}`, want: `{"tier":"premium","days":30}`},
+		{body: "not json", want: "not json"},
+	}
+	for _, tt := range tests {
+		if got := auditDetail([]byte(tt.body)); got != tt.want {
+			t.Errorf("auditDetail(%s) = %s, want %s", tt.body, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// dashboardCookie holds the session token of a dashboard login
+const dashboardCookie = "dashboard_session"
+
+// Login attempts allowed per client address within each window; login codes have 10^8 values,
+// so guessing one within its lifetime is hopeless at this rate
+const (
+	dashboardLoginLimit  = 10
+	dashboardLoginWindow = 10 * time.Minute
+)
+
+//go:embed web/dashboard.html
+var dashboardPage []byte
+
+// userTodo is a todo as shown to its user on the dashboard and in the Mini App
+type userTodo struct {
+	ID          uint       `json:"id"`
+	Content     string     `json:"content"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// userSubscription is a subscription with its todos as shown to its user on the dashboard and in the Mini App
+type userSubscription struct {
+	ID            uint       `json:"id"`
+	City          string     `json:"city"`
+	ReminderTime  string     `json:"reminder_time"`
+	EnableWarning bool       `json:"enable_warning"`
+	Shared        bool       `json:"shared"`
+	Todos         []userTodo `json:"todos"`
+}
+
+// toUserTodo converts a todo to its user-facing representation
+func toUserTodo(t model.Todo) userTodo {
+	return userTodo{ID: t.ID, Content: t.Content, Completed: t.Completed, CompletedAt: t.CompletedAt, CreatedAt: t.CreatedAt}
+}
+
+// toUserSubscriptions converts subscriptions with their todos to their user-facing representation
+func toUserSubscriptions(lists []service.SubscriptionTodos) []userSubscription {
+	subs := make([]userSubscription, 0, len(lists))
+	for _, l := range lists {
+		s := userSubscription{
+			ID:            l.Subscription.ID,
+			City:          l.Subscription.City,
+			ReminderTime:  l.Subscription.ReminderTime,
+			EnableWarning: l.Subscription.EnableWarning,
+			Shared:        l.Subscription.SharedListID != nil,
+			Todos:         make([]userTodo, 0, len(l.Todos)),
+		}
+		for _, t := range l.Todos {
+			s.Todos = append(s.Todos, toUserTodo(t))
+		}
+		subs = append(subs, s)
+	}
+	return subs
+}
+
+// dashboardMe is the dashboard's view of the logged-in user
+type dashboardMe struct {
+	Username      string             `json:"username,omitempty"`
+	FirstName     string             `json:"first_name,omitempty"`
+	Subscriptions []userSubscription `json:"subscriptions"`
+}
+
+// RegisterDashboard mounts the web dashboard. Users log in with a code from the bot's /dashboard
+// command; a session only ever sees its own user's subscriptions and todos.
+func (s *Server) RegisterDashboard(svc *service.DashboardService) {
+	limiter := newLoginLimiter(dashboardLoginLimit, dashboardLoginWindow)
+
+	s.mux.HandleFunc("GET /dashboard", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Frame-Options", "DENY")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(dashboardPage)
+	})
+	s.mux.HandleFunc("POST /dashboard/api/login", func(w http.ResponseWriter, r *http.Request) {
+		dashboardLogin(w, r, svc, limiter)
+	})
+	s.mux.HandleFunc("GET /dashboard/api/me", func(w http.ResponseWriter, r *http.Request) {
+		dashboardOverview(w, r, svc)
+	})
+	s.mux.HandleFunc("POST /dashboard/api/logout", func(w http.ResponseWriter, r *http.Request) {
+		if token := dashboardToken(r); token != "" {
+			if err := svc.Logout(token); err != nil {
+				logger.Warn("Failed to end dashboard session", zap.Error(err))
+			}
+		}
+		http.SetCookie(w, &http.Cookie{Name: dashboardCookie, Value: "", Path: "/dashboard", MaxAge: -1,
+			HttpOnly: true, Secure: isSecure(r), SameSite: http.SameSiteStrictMode})
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// dashboardLogin exchanges a login code for a session cookie
+func dashboardLogin(w http.ResponseWriter, r *http.Request, svc *service.DashboardService, limiter *loginLimiter) {
+	now := time.Now()
+	if !limiter.allow(clientHost(r), now) {
+		logger.Warn("Dashboard login rate limit exceeded", zap.String("remote_addr", r.RemoteAddr))
+		w.Header().Set("Retry-After", "600")
+		writeError(w, http.StatusTooManyRequests, "too many login attempts, try again later")
+		return
+	}
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1024)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	token, expires, err := svc.Login(strings.TrimSpace(req.Code), now)
+	if errors.Is(err, service.ErrDashboardCodeInvalid) {
+		writeError(w, http.StatusUnauthorized, "invalid or expired login code")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to log in to dashboard", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     dashboardCookie,
+		Value:    token,
+		Path:     "/dashboard",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   isSecure(r),
+		SameSite: http.SameSiteStrictMode,
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{"token": token, "expires_at": expires})
+}
+
+// dashboardOverview returns the logged-in user's subscriptions and todos
+func dashboardOverview(w http.ResponseWriter, r *http.Request, svc *service.DashboardService) {
+	user, err := svc.Authenticate(dashboardToken(r), time.Now())
+	if errors.Is(err, service.ErrDashboardUnauthorized) {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to authenticate dashboard session", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	subs, err := svc.Subscriptions(user)
+	if err != nil {
+		logger.Error("Failed to load dashboard", zap.Uint("user_id", user.ID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	me := dashboardMe{Username: user.Username, FirstName: user.FirstName, Subscriptions: toUserSubscriptions(subs)}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, me)
+}
+
+// dashboardToken returns the session token of a request, from the session cookie or a Bearer token
+func dashboardToken(r *http.Request) string {
+	if c, err := r.Cookie(dashboardCookie); err == nil && c.Value != "" {
+		return c.Value
+	}
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// isSecure reports whether a request reached us (or the reverse proxy in front) over HTTPS
+func isSecure(r *http.Request) bool {
+	return r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+// clientHost returns the host part of a request's remote address
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// loginLimiter allows at most limit login attempts per client address within each fixed window
+type loginLimiter struct {
+	mu       sync.Mutex
+	limit    int
+	window   time.Duration
+	counters map[string]*loginCounter
+}
+
+type loginCounter struct {
+	start time.Time
+	count int
+}
+
+func newLoginLimiter(limit int, window time.Duration) *loginLimiter {
+	return &loginLimiter{limit: limit, window: window, counters: make(map[string]*loginCounter)}
+}
+
+// allow counts an attempt from host and reports whether it is within the limit
+func (l *loginLimiter) allow(host string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cnt, ok := l.counters[host]
+	if !ok || now.Sub(cnt.start) >= l.window {
+		// Drop expired windows so idle addresses do not accumulate
+		for h, other := range l.counters {
+			if now.Sub(other.start) >= l.window {
+				delete(l.counters, h)
+			}
+		}
+		l.counters[host] = &loginCounter{start: now, count: 1}
+		return true
+	}
+	cnt.count++
+	return cnt.count <= l.limit
+}
@@ -0,0 +1,41 @@
+package server
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// RegisterDebug mounts net/http/pprof under /debug/pprof/, a runtime summary at /debug/runtime
+// and expvar counters (including bot command metrics) at /debug/vars
+func (s *Server) RegisterDebug() {
+	s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	s.mux.HandleFunc("/debug/runtime", s.handleRuntime)
+	s.mux.Handle("/debug/vars", expvar.Handler())
+}
+
+// handleRuntime reports goroutine count and memory statistics
+func (s *Server) handleRuntime(w http.ResponseWriter, r *http.Request) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"uptime":         time.Since(s.startedAt).Round(time.Second).String(),
+		"go_version":     runtime.Version(),
+		"goroutines":     runtime.NumGoroutine(),
+		"num_cpu":        runtime.NumCPU(),
+		"heap_alloc":     m.HeapAlloc,
+		"heap_inuse":     m.HeapInuse,
+		"heap_objects":   m.HeapObjects,
+		"sys":            m.Sys,
+		"total_alloc":    m.TotalAlloc,
+		"num_gc":         m.NumGC,
+		"pause_total_ns": m.PauseTotalNs,
+	})
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"encoding/xml"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// FeedPathPrefix is where per-city RSS feeds are served (e.g., /feeds/北京)
+const FeedPathPrefix = "/feeds/"
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// RegisterFeed mounts the per-city RSS feeds of cached digests.
+// Digests contain no personal data, so the feeds are served without authentication.
+func (s *Server) RegisterFeed(cache *service.DigestCache) {
+	s.mux.HandleFunc("GET "+FeedPathPrefix+"{city}", func(w http.ResponseWriter, r *http.Request) {
+		serveFeed(w, r, cache)
+	})
+	s.mux.HandleFunc("GET /feeds", func(w http.ResponseWriter, r *http.Request) {
+		var feeds []map[string]string
+		for _, city := range cache.Cities() {
+			feeds = append(feeds, map[string]string{"city": city, "url": feedURL(r, city)})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"feeds": feeds})
+	})
+}
+
+// serveFeed renders the cached digests of a city as RSS 2.0
+func serveFeed(w http.ResponseWriter, r *http.Request, cache *service.DigestCache) {
+	city := strings.TrimSuffix(r.PathValue("city"), ".xml")
+	digests := cache.Recent(city)
+	if len(digests) == 0 {
+		writeError(w, http.StatusNotFound, "no digest for this city yet")
+		return
+	}
+
+	link := feedURL(r, city)
+	doc := rssDocument{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         city + " 每日天气",
+			Link:          link,
+			Description:   city + " 每日天气摘要",
+			Language:      "zh-cn",
+			LastBuildDate: digests[0].GeneratedAt.Format(time.RFC1123Z),
+		},
+	}
+	for _, d := range digests {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       d.Title,
+			Link:        link,
+			Description: strings.ReplaceAll(html.EscapeString(d.Body), "\n", "<br/>"),
+			GUID:        rssGUID{Value: "daily-reminder-bot:" + d.City + ":" + d.Date},
+			PubDate:     d.GeneratedAt.Format(time.RFC1123Z),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	if err := xml.NewEncoder(w).Encode(doc); err != nil {
+		logger.Warn("Failed to encode RSS feed", zap.String("city", city), zap.Error(err))
+	}
+}
+
+// feedURL builds the absolute feed URL of a city from the incoming request
+func feedURL(r *http.Request, city string) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + FeedPathPrefix + url.PathEscape(city)
+}
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/graphql"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// graphQLMaxBody is the largest GraphQL request body accepted
+const graphQLMaxBody = 64 << 10
+
+// graphQLMaxDepth bounds how deep GraphQL queries nest, counting fragments; the schema's own
+// data is 3 levels deep (subscriptions { todos { id } }) and the standard introspection
+// query needs 12
+const graphQLMaxDepth = 15
+
+// graphQLMaxCost bounds the cost of GraphQL queries (see graphql.Schema.SetMaxCost). Fields
+// reading the store cost graphQLStoreCost, so a query selecting every field of the schema costs
+// about 1400, and a query can't alias the top-level deliveries more than 100 times or nested
+// store fields more than about 10 times.
+const graphQLMaxCost = 2000
+
+// graphQLStoreCost is the cost of the fields whose resolvers query the store
+const graphQLStoreCost = 10
+
+// graphQLMaxQueryLength is the longest query string accepted by GET requests, which
+// graphQLMaxBody doesn't cover
+const graphQLMaxQueryLength = 8 << 10
+
+// maxDeliveryLimit caps the limit argument of delivery history fields
+const maxDeliveryLimit = 100
+
+// graphQLUserKey is the context key of the authenticated user of a GraphQL request
+type graphQLUserKey struct{}
+
+// RegisterGraphQL mounts the user GraphQL API. Requests authenticate with a dashboard session
+// token (Authorization: Bearer <token>), and every field is scoped to that session's user.
+func (s *Server) RegisterGraphQL(svc *service.DashboardService) error {
+	schema, err := newUserSchema(svc)
+	if err != nil {
+		return fmt.Errorf("failed to build GraphQL schema: %w", err)
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		serveGraphQL(w, r, svc, schema)
+	}
+	s.mux.HandleFunc("GET /graphql", handler)
+	s.mux.HandleFunc("POST /graphql", handler)
+	return nil
+}
+
+// serveGraphQL executes a GraphQL request for the user of its session token
+func serveGraphQL(w http.ResponseWriter, r *http.Request, svc *service.DashboardService, schema *graphql.Schema) {
+	w.Header().Set("Cache-Control", "no-store")
+	user, err := svc.Authenticate(dashboardToken(r), time.Now())
+	if errors.Is(err, service.ErrDashboardUnauthorized) {
+		writeJSON(w, http.StatusUnauthorized, graphQLError("unauthorized"))
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to authenticate GraphQL request", zap.Error(err))
+		writeJSON(w, http.StatusInternalServerError, graphQLError("internal error"))
+		return
+	}
+
+	var req graphql.Request
+	if r.Method == http.MethodGet {
+		if len(r.URL.RawQuery) > graphQLMaxQueryLength {
+			writeJSON(w, http.StatusRequestURITooLong, graphQLError("query string is too long, use POST"))
+			return
+		}
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			dec := json.NewDecoder(strings.NewReader(v))
+			dec.UseNumber()
+			if err := dec.Decode(&req.Variables); err != nil {
+				writeJSON(w, http.StatusBadRequest, graphQLError("variables must be a JSON object"))
+				return
+			}
+		}
+	} else {
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, graphQLMaxBody))
+		dec.UseNumber()
+		if err := dec.Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, graphQLError("invalid JSON body"))
+			return
+		}
+	}
+	if req.Query == "" {
+		writeJSON(w, http.StatusBadRequest, graphQLError("query is required"))
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), graphQLUserKey{}, user)
+	writeJSON(w, http.StatusOK, graphql.Execute(ctx, schema, req))
+}
+
+// graphQLError is a GraphQL response carrying a single request error
+func graphQLError(message string) *graphql.Result {
+	return &graphql.Result{Errors: []*graphql.Error{{Message: message}}}
+}
+
+// graphQLUser returns the authenticated user of a resolver
+func graphQLUser(p graphql.ResolveParams) *model.User {
+	return p.Context.Value(graphQLUserKey{}).(*model.User)
+}
+
+// newUserSchema builds the schema of the user GraphQL API
+func newUserSchema(svc *service.DashboardService) (*graphql.Schema, error) {
+	nonNull := func(t graphql.Type) graphql.Type { return &graphql.NonNull{OfType: t} }
+	listOf := func(t graphql.Type) graphql.Type { return nonNull(&graphql.List{OfType: nonNull(t)}) }
+	completedArg := &graphql.Argument{Name: "completed", Description: "Only completed (true) or open (false) todos", Type: graphql.Boolean}
+	limitArg := &graphql.Argument{Name: "limit", Description: "Maximum number of deliveries (at most 100)", Type: graphql.Int, DefaultValue: 20}
+
+	userType := &graphql.Object{Name: "User", Description: "The logged-in user", Fields: []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*model.User).ID, nil
+		}},
+		{Name: "username", Description: "Telegram username without the leading @", Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return optionalString(p.Source.(*model.User).Username), nil
+		}},
+		{Name: "firstName", Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return optionalString(p.Source.(*model.User).FirstName), nil
+		}},
+		{Name: "tier", Description: "Service tier in effect: free or premium", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(*model.User).EffectiveTier(time.Now()), nil
+		}},
+		{Name: "tierUntil", Description: "When the premium tier ends (RFC 3339; null = does not end)", Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return optionalTime(p.Source.(*model.User).TierUntil), nil
+		}},
+		{Name: "createdAt", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return formatTime(p.Source.(*model.User).CreatedAt), nil
+		}},
+	}}
+
+	todoType := &graphql.Object{Name: "Todo", Fields: []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(model.Todo).ID, nil
+		}},
+		{Name: "content", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(model.Todo).Content, nil
+		}},
+		{Name: "completed", Type: nonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(model.Todo).Completed, nil
+		}},
+		{Name: "completedAt", Description: "RFC 3339", Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return optionalTime(p.Source.(model.Todo).CompletedAt), nil
+		}},
+		{Name: "createdAt", Description: "RFC 3339", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return formatTime(p.Source.(model.Todo).CreatedAt), nil
+		}},
+	}}
+
+	deliveryType := &graphql.Object{Name: "Delivery", Description: "A reminder delivery attempt", Fields: []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(model.DeliveryLog).ID, nil
+		}},
+		{Name: "subscriptionId", Type: nonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(model.DeliveryLog).SubscriptionID, nil
+		}},
+		{Name: "kind", Description: "reminder or fallback (sent without weather data)", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(model.DeliveryLog).Kind, nil
+		}},
+		{Name: "success", Type: nonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(model.DeliveryLog).Success, nil
+		}},
+		{Name: "error", Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return optionalString(p.Source.(model.DeliveryLog).Error), nil
+		}},
+		{Name: "createdAt", Description: "RFC 3339", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return formatTime(p.Source.(model.DeliveryLog).CreatedAt), nil
+		}},
+	}}
+
+	subscriptionType := &graphql.Object{Name: "ReminderSubscription", Description: "A daily reminder subscription", Fields: []*graphql.Field{
+		{Name: "id", Type: nonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(service.SubscriptionTodos).Subscription.ID, nil
+		}},
+		{Name: "city", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(service.SubscriptionTodos).Subscription.City, nil
+		}},
+		{Name: "reminderTime", Description: "Daily reminder time (HH:MM)", Type: nonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(service.SubscriptionTodos).Subscription.ReminderTime, nil
+		}},
+		{Name: "enableWarning", Description: "Whether weather warnings are pushed", Type: nonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(service.SubscriptionTodos).Subscription.EnableWarning, nil
+		}},
+		{Name: "shared", Description: "Whether the todo list is shared with another user", Type: nonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(service.SubscriptionTodos).Subscription.SharedListID != nil, nil
+		}},
+		{Name: "todos", Type: listOf(todoType), Args: []*graphql.Argument{completedArg}, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return filterTodos(p.Source.(service.SubscriptionTodos).Todos, p.Args["completed"]), nil
+		}},
+		{Name: "deliveries", Description: "Most recent deliveries first", Type: listOf(deliveryType), Args: []*graphql.Argument{limitArg}, Cost: graphQLStoreCost, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			limit, err := deliveryLimit(p.Args["limit"])
+			if err != nil {
+				return nil, err
+			}
+			return svc.Deliveries(graphQLUser(p), p.Source.(service.SubscriptionTodos).Subscription.ID, limit)
+		}},
+	}}
+
+	query := &graphql.Object{Name: "Query", Fields: []*graphql.Field{
+		{Name: "me", Type: nonNull(userType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return graphQLUser(p), nil
+		}},
+		{Name: "subscriptions", Description: "Active subscriptions", Type: listOf(subscriptionType), Cost: graphQLStoreCost, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return svc.Subscriptions(graphQLUser(p))
+		}},
+		{Name: "subscription", Type: subscriptionType, Args: []*graphql.Argument{{Name: "id", Type: nonNull(graphql.ID)}}, Cost: graphQLStoreCost, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			id, err := strconv.ParseUint(p.Args["id"].(string), 10, 32)
+			if err != nil {
+				return nil, nil
+			}
+			sub, err := svc.Subscription(graphQLUser(p), uint(id))
+			if err != nil || sub == nil {
+				return nil, err
+			}
+			return *sub, nil
+		}},
+		{Name: "todos", Description: "Todos of all subscriptions", Type: listOf(todoType), Args: []*graphql.Argument{completedArg}, Cost: graphQLStoreCost, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			lists, err := svc.Subscriptions(graphQLUser(p))
+			if err != nil {
+				return nil, err
+			}
+			seen := make(map[uint]bool)
+			var todos []model.Todo
+			for _, l := range lists {
+				for _, t := range l.Todos {
+					// Subscriptions can share one list
+					if !seen[t.ID] {
+						seen[t.ID] = true
+						todos = append(todos, t)
+					}
+				}
+			}
+			return filterTodos(todos, p.Args["completed"]), nil
+		}},
+		{Name: "deliveries", Description: "Delivery history of all subscriptions, most recent first", Type: listOf(deliveryType), Args: []*graphql.Argument{limitArg}, Cost: graphQLStoreCost, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			limit, err := deliveryLimit(p.Args["limit"])
+			if err != nil {
+				return nil, err
+			}
+			user := graphQLUser(p)
+			lists, err := svc.Subscriptions(user)
+			if err != nil {
+				return nil, err
+			}
+			var logs []model.DeliveryLog
+			for _, l := range lists {
+				subLogs, err := svc.Deliveries(user, l.Subscription.ID, limit)
+				if err != nil {
+					return nil, err
+				}
+				logs = append(logs, subLogs...)
+			}
+			sort.SliceStable(logs, func(i, j int) bool { return logs[i].CreatedAt.After(logs[j].CreatedAt) })
+			if len(logs) > limit {
+				logs = logs[:limit]
+			}
+			return logs, nil
+		}},
+	}}
+	schema, err := graphql.NewSchema(query)
+	if err != nil {
+		return nil, err
+	}
+	schema.SetMaxDepth(graphQLMaxDepth)
+	schema.SetMaxCost(graphQLMaxCost)
+	return schema, nil
+}
+
+// filterTodos returns the todos matching the completed argument (nil = all)
+func filterTodos(todos []model.Todo, completed interface{}) []model.Todo {
+	want, ok := completed.(bool)
+	filtered := make([]model.Todo, 0, len(todos))
+	for _, t := range todos {
+		if !ok || t.Completed == want {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// deliveryLimit validates the limit argument of delivery history fields
+func deliveryLimit(arg interface{}) (int, error) {
+	limit, ok := arg.(int)
+	if !ok || limit < 1 || limit > maxDeliveryLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxDeliveryLimit)
+	}
+	return limit, nil
+}
+
+// optionalString returns nil for an empty string
+func optionalString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// optionalTime formats a time as RFC 3339, nil when unset
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+// formatTime formats a time as RFC 3339
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/graphql"
+)
+
+func TestUserSchemaDepthLimit(t *testing.T) {
+	// Validation and introspection run before any resolver needs the service
+	schema, err := newUserSchema(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := graphql.Execute(context.Background(), schema, graphql.Request{Query: graphql.IntrospectionQuery})
+	if len(result.Errors) > 0 {
+		t.Fatalf("introspection query errors = %v", result.Errors)
+	}
+
+	// __type { ofType { ofType ... } } nested past the limit
+	deep := `{ __type(name: "Query") { ` + strings.Repeat("ofType { ", graphQLMaxDepth) + "name" + strings.Repeat(" }", graphQLMaxDepth) + " } }"
+	result = graphql.Execute(context.Background(), schema, graphql.Request{Query: deep})
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "nested deeper than") || result.Data != nil {
+		t.Fatalf("deep query result = %+v, want the depth error", result)
+	}
+}
+
+func TestUserSchemaCostLimit(t *testing.T) {
+	schema, err := newUserSchema(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A query of every field of the schema is within the limit; the resolvers then fail on the
+	// nil service, after the cost was checked
+	all := `{ me { id username firstName tier tierUntil createdAt }
+		subscriptions { id city reminderTime enableWarning shared
+			todos { id content completed completedAt createdAt }
+			deliveries { id subscriptionId kind success error createdAt } } }`
+	result := graphql.Execute(context.Background(), schema, graphql.Request{Query: all})
+	for _, e := range result.Errors {
+		if strings.HasPrefix(e.Message, "Query costs ") {
+			t.Fatalf("query of every field refused: %s", e.Message)
+		}
+	}
+
+	aliased := "{"
+	for i := 0; i < 200; i++ {
+		aliased += fmt.Sprintf(" d%d: deliveries { id }", i)
+	}
+	result = graphql.Execute(context.Background(), schema, graphql.Request{Query: aliased + " }"})
+	if len(result.Errors) != 1 || !strings.HasPrefix(result.Errors[0].Message, "Query costs ") || result.Data != nil {
+		t.Fatalf("aliased query result = %+v, want the cost error", result)
+	}
+}
@@ -0,0 +1,237 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+//go:embed web/miniapp.html
+var miniAppPage []byte
+
+// miniAppState is everything the Mini App shows
+type miniAppState struct {
+	FirstName     string             `json:"first_name,omitempty"`
+	Announcements bool               `json:"announcements"`
+	Subscriptions []userSubscription `json:"subscriptions"`
+}
+
+// miniAppHandler handles a Mini App API request of an authenticated user
+type miniAppHandler func(w http.ResponseWriter, r *http.Request, svc *service.MiniAppService, user *model.User)
+
+// RegisterMiniApp mounts the Telegram Mini App. API requests carry the initData Telegram passes to
+// the app ("Authorization: tma <initData>"), which only the bot token can sign.
+func (s *Server) RegisterMiniApp(svc *service.MiniAppService) {
+	s.mux.HandleFunc("GET /miniapp", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		_, _ = w.Write(miniAppPage)
+	})
+
+	handle := func(pattern string, handler miniAppHandler) {
+		s.mux.HandleFunc(pattern, miniAppAuth(svc, handler))
+	}
+	handle("GET /miniapp/api/state", miniAppGetState)
+	handle("PATCH /miniapp/api/settings", miniAppUpdateSettings)
+	handle("PATCH /miniapp/api/subscriptions/{id}", miniAppUpdateSubscription)
+	handle("DELETE /miniapp/api/subscriptions/{id}", miniAppUnsubscribe)
+	handle("POST /miniapp/api/subscriptions/{id}/todos", miniAppAddTodo)
+	handle("PUT /miniapp/api/subscriptions/{id}/todos/order", miniAppReorderTodos)
+	handle("PATCH /miniapp/api/todos/{id}", miniAppUpdateTodo)
+	handle("DELETE /miniapp/api/todos/{id}", miniAppDeleteTodo)
+}
+
+// miniAppAuth authenticates a Mini App API request by its initData
+func miniAppAuth(svc *service.MiniAppService, next miniAppHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		initData := strings.TrimPrefix(r.Header.Get("Authorization"), "tma ")
+		user, err := svc.Authenticate(initData, time.Now())
+		if err != nil {
+			writeMiniAppError(w, err)
+			return
+		}
+		next(w, r, svc, user)
+	}
+}
+
+// writeMiniAppError writes the response of a failed Mini App request
+func writeMiniAppError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrMiniAppUnauthorized):
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+	case errors.Is(err, service.ErrMiniAppNotFound):
+		writeError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, service.ErrMiniAppInvalid):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		logger.Error("Mini App request failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+// miniAppID parses the {id} path parameter
+func miniAppID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// decodeMiniAppBody decodes a small JSON request body
+func decodeMiniAppBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return false
+	}
+	return true
+}
+
+// miniAppGetState handles GET /miniapp/api/state
+func miniAppGetState(w http.ResponseWriter, r *http.Request, svc *service.MiniAppService, user *model.User) {
+	lists, err := svc.State(user)
+	if err != nil {
+		writeMiniAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, miniAppState{
+		FirstName:     user.FirstName,
+		Announcements: !user.AnnouncementsOff,
+		Subscriptions: toUserSubscriptions(lists),
+	})
+}
+
+// miniAppUpdateSettings handles PATCH /miniapp/api/settings
+func miniAppUpdateSettings(w http.ResponseWriter, r *http.Request, svc *service.MiniAppService, user *model.User) {
+	var req struct {
+		Announcements *bool `json:"announcements"`
+	}
+	if !decodeMiniAppBody(w, r, &req) {
+		return
+	}
+	if req.Announcements != nil {
+		if err := svc.SetAnnouncements(user, *req.Announcements); err != nil {
+			writeMiniAppError(w, err)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"announcements": !user.AnnouncementsOff})
+}
+
+// miniAppUpdateSubscription handles PATCH /miniapp/api/subscriptions/{id}
+func miniAppUpdateSubscription(w http.ResponseWriter, r *http.Request, svc *service.MiniAppService, user *model.User) {
+	id, ok := miniAppID(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		ReminderTime  *string `json:"reminder_time"`
+		EnableWarning *bool   `json:"enable_warning"`
+	}
+	if !decodeMiniAppBody(w, r, &req) {
+		return
+	}
+	sub, err := svc.UpdateSubscription(user, id, req.ReminderTime, req.EnableWarning)
+	if err != nil {
+		writeMiniAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id": sub.ID, "reminder_time": sub.ReminderTime, "enable_warning": sub.EnableWarning,
+	})
+}
+
+// miniAppUnsubscribe handles DELETE /miniapp/api/subscriptions/{id}
+func miniAppUnsubscribe(w http.ResponseWriter, r *http.Request, svc *service.MiniAppService, user *model.User) {
+	id, ok := miniAppID(w, r)
+	if !ok {
+		return
+	}
+	if err := svc.Unsubscribe(user, id); err != nil {
+		writeMiniAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// miniAppAddTodo handles POST /miniapp/api/subscriptions/{id}/todos
+func miniAppAddTodo(w http.ResponseWriter, r *http.Request, svc *service.MiniAppService, user *model.User) {
+	id, ok := miniAppID(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Content string `json:"content"`
+	}
+	if !decodeMiniAppBody(w, r, &req) {
+		return
+	}
+	todo, err := svc.AddTodo(user, id, req.Content)
+	if err != nil {
+		writeMiniAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toUserTodo(*todo))
+}
+
+// miniAppReorderTodos handles PUT /miniapp/api/subscriptions/{id}/todos/order
+func miniAppReorderTodos(w http.ResponseWriter, r *http.Request, svc *service.MiniAppService, user *model.User) {
+	id, ok := miniAppID(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		IDs []uint `json:"ids"`
+	}
+	if !decodeMiniAppBody(w, r, &req) {
+		return
+	}
+	if err := svc.ReorderTodos(user, id, req.IDs); err != nil {
+		writeMiniAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// miniAppUpdateTodo handles PATCH /miniapp/api/todos/{id}
+func miniAppUpdateTodo(w http.ResponseWriter, r *http.Request, svc *service.MiniAppService, user *model.User) {
+	id, ok := miniAppID(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Completed bool `json:"completed"`
+	}
+	if !decodeMiniAppBody(w, r, &req) {
+		return
+	}
+	todo, err := svc.SetTodoCompleted(user, id, req.Completed)
+	if err != nil {
+		writeMiniAppError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserTodo(*todo))
+}
+
+// miniAppDeleteTodo handles DELETE /miniapp/api/todos/{id}
+func miniAppDeleteTodo(w http.ResponseWriter, r *http.Request, svc *service.MiniAppService, user *model.User) {
+	id, ok := miniAppID(w, r)
+	if !ok {
+		return
+	}
+	if err := svc.DeleteTodo(user, id); err != nil {
+		writeMiniAppError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,258 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// OpenAPIPath is where the admin API's OpenAPI document is served
+const OpenAPIPath = "/api/v1/openapi.json"
+
+var pathParamPattern = regexp.MustCompile(`\{([a-zA-Z_]+)\}`)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// OpenAPI builds the OpenAPI 3 document describing the admin API
+func (a *AdminAPI) OpenAPI() map[string]interface{} {
+	gen := &schemaGenerator{components: make(map[string]interface{})}
+	paths := make(map[string]map[string]interface{})
+
+	for _, rt := range a.routes() {
+		op := map[string]interface{}{
+			"summary":     rt.Summary,
+			"operationId": operationID(rt),
+			"tags":        []string{rt.Tag},
+		}
+
+		var params []interface{}
+		for _, name := range pathParamPattern.FindAllStringSubmatch(rt.Path, -1) {
+			params = append(params, map[string]interface{}{
+				"name":     name[1],
+				"in":       "path",
+				"required": true,
+				"schema":   map[string]interface{}{"type": "integer", "minimum": 1},
+			})
+		}
+		for _, q := range rt.Query {
+			params = append(params, map[string]interface{}{
+				"name":        q.Name,
+				"in":          "query",
+				"description": q.Description,
+				"schema":      map[string]interface{}{"type": q.Type},
+			})
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		if rt.Request != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": gen.schema(reflect.TypeOf(rt.Request))},
+				},
+			}
+		}
+
+		responses := map[string]interface{}{
+			"400": errorResponseRef("Invalid request"),
+			"401": errorResponseRef("Missing or invalid admin token"),
+			"500": errorResponseRef("Internal error"),
+		}
+		if strings.Contains(rt.Path, "{id}") {
+			responses["404"] = errorResponseRef("Resource not found")
+		}
+		success := map[string]interface{}{"description": http.StatusText(rt.Status)}
+		if rt.Response != nil {
+			schema := gen.schema(reflect.TypeOf(rt.Response))
+			if rt.List {
+				schema = listSchema(schema)
+			}
+			success["content"] = map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": schema},
+			}
+		}
+		responses[strconv.Itoa(rt.Status)] = success
+		op["responses"] = responses
+
+		if paths[rt.Path] == nil {
+			paths[rt.Path] = make(map[string]interface{})
+		}
+		paths[rt.Path][strings.ToLower(rt.Method)] = op
+	}
+
+	gen.components["Error"] = map[string]interface{}{
+		"type":       "object",
+		"required":   []string{"error"},
+		"properties": map[string]interface{}{"error": map[string]interface{}{"type": "string"}},
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Daily Reminder Bot Admin API",
+			"version":     "1.0.0",
+			"description": "Management endpoints for users, subscriptions, todos and delivery statistics.",
+		},
+		"servers":  []interface{}{map[string]interface{}{"url": "/"}},
+		"security": []interface{}{map[string]interface{}{"adminToken": []string{}}, map[string]interface{}{"bearerToken": []string{}}},
+		"paths":    paths,
+		"components": map[string]interface{}{
+			"schemas": gen.components,
+			"securitySchemes": map[string]interface{}{
+				"adminToken":  map[string]interface{}{"type": "apiKey", "in": "header", "name": AdminTokenHeader},
+				"bearerToken": map[string]interface{}{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// serveOpenAPI serves the OpenAPI document; it contains no data and is served without authentication
+func (a *AdminAPI) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.OpenAPI())
+}
+
+// schemaGenerator converts Go types into OpenAPI schemas, collecting named structs as components
+type schemaGenerator struct {
+	components map[string]interface{}
+}
+
+// schema returns the schema for t, registering named struct types under components/schemas
+func (g *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	nullable := false
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var s map[string]interface{}
+	switch {
+	case t == timeType:
+		s = map[string]interface{}{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Struct:
+		name := componentName(t)
+		if _, ok := g.components[name]; !ok {
+			g.components[name] = nil // Reserve the name to stop recursion
+			g.components[name] = g.object(t)
+		}
+		return ref(name)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		s = map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		s = map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case t.Kind() == reflect.Bool:
+		s = map[string]interface{}{"type": "boolean"}
+	case t.Kind() == reflect.String:
+		s = map[string]interface{}{"type": "string"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s = map[string]interface{}{"type": "integer", "format": "int64"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = map[string]interface{}{"type": "number"}
+	default:
+		s = map[string]interface{}{}
+	}
+	if nullable {
+		s["nullable"] = true
+	}
+	return s
+}
+
+// object builds an object schema from struct fields, flattening embedded structs like encoding/json.
+// Non-pointer fields without omitempty are required.
+func (g *schemaGenerator) object(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+	g.collectFields(t, properties, &required)
+
+	s := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func (g *schemaGenerator) collectFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && tag == "" && f.Type.Kind() == reflect.Struct {
+			g.collectFields(f.Type, properties, required)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = g.schema(f.Type)
+		if f.Type.Kind() != reflect.Ptr && !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// componentName derives an exported schema name from a Go type name (e.g., userResponse -> UserResponse)
+func componentName(t reflect.Type) string {
+	name := t.Name()
+	if name == "" {
+		return "Object"
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// operationID derives a stable operation ID from a route (e.g., GET /api/v1/users/{id} -> getUsersById)
+func operationID(rt route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(rt.Method))
+	for _, part := range strings.Split(strings.TrimPrefix(rt.Path, "/api/v1/"), "/") {
+		if m := pathParamPattern.FindStringSubmatch(part); m != nil {
+			part = "by-" + m[1]
+		}
+		for _, word := range strings.FieldsFunc(part, func(r rune) bool { return r == '-' || r == '_' }) {
+			runes := []rune(word)
+			runes[0] = unicode.ToUpper(runes[0])
+			b.WriteString(string(runes))
+		}
+	}
+	return b.String()
+}
+
+// listSchema wraps an item schema in the paginated list envelope
+func listSchema(item map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"items", "total", "offset", "limit"},
+		"properties": map[string]interface{}{
+			"items":  map[string]interface{}{"type": "array", "items": item},
+			"total":  map[string]interface{}{"type": "integer", "format": "int64"},
+			"offset": map[string]interface{}{"type": "integer"},
+			"limit":  map[string]interface{}{"type": "integer"},
+		},
+	}
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func errorResponseRef(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": ref("Error")},
+		},
+	}
+}
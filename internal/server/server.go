@@ -0,0 +1,102 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Server is a small HTTP server hosting health, debug and management endpoints
+type Server struct {
+	name       string
+	mux        *http.ServeMux
+	httpServer *http.Server
+	startedAt  time.Time
+}
+
+// New creates a new Server listening on addr
+// The health endpoint (/healthz) is always registered.
+func New(name, addr string) *Server {
+	mux := http.NewServeMux()
+	s := &Server{
+		name:      name,
+		mux:       mux,
+		startedAt: time.Now(),
+		httpServer: &http.Server{
+			Addr:              addr,
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+	}
+	mux.HandleFunc("/healthz", s.handleHealth)
+	return s
+}
+
+// Handle registers a handler for the given pattern
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// HandleFunc registers a handler function for the given pattern
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
+// Addr returns the configured listen address
+func (s *Server) Addr() string {
+	return s.httpServer.Addr
+}
+
+// Start starts listening in the background
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.httpServer.Addr, err)
+	}
+
+	logger.Info("HTTP server started",
+		zap.String("server", s.name),
+		zap.String("addr", ln.Addr().String()))
+
+	go func() {
+		if err := s.httpServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("HTTP server stopped unexpectedly",
+				zap.String("server", s.name),
+				zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Shutdown gracefully stops the server
+func (s *Server) Shutdown(ctx context.Context) error {
+	logger.Info("HTTP server stopping", zap.String("server", s.name))
+	return s.httpServer.Shutdown(ctx)
+}
+
+// handleHealth reports liveness and uptime
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status": "ok",
+		"uptime": time.Since(s.startedAt).Round(time.Second).String(),
+	})
+}
+
+// IsLoopbackAddr reports whether a listen address binds only to the loopback interface
+func IsLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
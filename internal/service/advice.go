@@ -0,0 +1,57 @@
+package service
+
+import (
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/advice"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/calendar"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+// AdviceService runs the advice engine for daily reminders. Seasonal tips are kept to the first
+// day they fire for a city in their season; that day is stored so every subscriber of the city
+// sees them on the same day.
+type AdviceService struct {
+	engine     *advice.Engine
+	eventRepo  *repository.SeasonalEventRepository
+	calculator *calendar.Calculator
+}
+
+// NewAdviceService creates a new AdviceService
+func NewAdviceService(engine *advice.Engine, eventRepo *repository.SeasonalEventRepository, timezone *time.Location) *AdviceService {
+	return &AdviceService{
+		engine:     engine,
+		eventRepo:  eventRepo,
+		calculator: calendar.NewCalculator(timezone),
+	}
+}
+
+// Tips returns the advice for a city on a day, given today's forecast and the current weather
+// (either may be nil)
+func (s *AdviceService) Tips(city string, today *qweather.DailyForecast, weather *qweather.CurrentWeather, now time.Time) []advice.Tip {
+	ctx := &advice.Context{City: city, Date: now, Today: today, Weather: weather}
+	ctx.Fu, ctx.FuDay = s.calculator.GetFu(now)
+
+	date := now.Format("2006-01-02")
+	var tips []advice.Tip
+	for _, tip := range s.engine.Advise(ctx) {
+		if tip.Season != "" {
+			first, err := s.eventRepo.Record(city, tip.Rule, tip.Season, date)
+			if err != nil {
+				logger.Warn("Failed to check seasonal event",
+					zap.String("city", city),
+					zap.String("rule", tip.Rule),
+					zap.Error(err))
+				continue
+			}
+			if first != date {
+				continue
+			}
+		}
+		tips = append(tips, tip)
+	}
+	return tips
+}
@@ -2,7 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/cuichanghe/daily-reminder-bot/internal/model"
@@ -10,21 +17,78 @@ import (
 	"github.com/cuichanghe/daily-reminder-bot/pkg/openai"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// promptClockStep is the precision of the time in reminder prompts: reminders and refreshes
+// within the same quarter hour build the same prompt, so they can share a cached completion
+const promptClockStep = 15 * time.Minute
+
+// sharedCompletionTimeout bounds a completion shared by concurrent identical prompts, which runs
+// detached from the context of the caller that started it
+const sharedCompletionTimeout = 3 * time.Minute
+
+// promptClock returns t rounded down to promptClockStep on the local wall clock, so that the
+// quarter hours of zones with a 30 or 45 minute offset start at :00, :15, :30 and :45 as well
+func promptClock(t time.Time) time.Time {
+	step := int(promptClockStep / time.Minute)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()-t.Minute()%step, 0, 0, t.Location())
+}
+
+// Sampling of regenerated reminders: the temperature is raised by a step, up to a cap, so that
+// the new reminder differs from the cached one
+const (
+	regenerateTemperatureStep = 0.3
+	regenerateTemperatureMax  = 1.3
 )
 
 // AIService handles AI-powered content generation
 type AIService struct {
-	client     *openai.Client
-	maxRetries int
-	enabled    bool
+	client       *openai.Client
+	lite         *openai.Client // Writes reminders in the concise mode (model.AIModeLite)
+	maxRetries   int
+	enabled      bool
+	todoPlanning bool // Whether to order and annotate todos using the hourly forecast
+	optIn        bool // Whether subscriptions get AI reminders only after turning them on
+	cacheTTL     time.Duration
+	regenQuota   int                 // Regenerations of AI reminders per user and day (0 = no regenerate button)
+	probe        *openai.HealthProbe // Health of a local AI server (nil = not probed)
+	promptBudget int                 // Most tokens a reminder prompt may take (0 = the model's context window)
+
+	mu    sync.Mutex
+	cache map[string]aiCacheEntry // Keyed by the hash of the model and prompts
+	group singleflight.Group
 }
 
-// NewAIService creates a new AIService
-func NewAIService(client *openai.Client, maxRetries int, enabled bool) *AIService {
+// maxRetryAfter is the longest wait requested by a rate limited API that AI requests honor;
+// longer waits fail the request, so reminders fall back to the template instead of arriving late
+const maxRetryAfter = 30 * time.Second
+
+// aiCacheEntry is a cached completion
+type aiCacheEntry struct {
+	content   string
+	expiresAt time.Time
+}
+
+// NewAIService creates a new AIService. liteModel is the model of the concise mode ("" = the
+// client's model); with optIn, subscriptions on the default mode get the fixed template.
+// Reminders and todo plans are cached by prompt for cacheTTL (0 = no caching). Users may
+// regenerate regenQuota AI reminders a day (0 = never).
+func NewAIService(client *openai.Client, maxRetries int, enabled bool, todoPlanning bool, liteModel string, optIn bool, cacheTTL time.Duration, regenQuota int) *AIService {
+	lite := client
+	if client != nil && liteModel != "" {
+		lite = client.WithModel(liteModel)
+	}
 	return &AIService{
-		client:     client,
-		maxRetries: maxRetries,
-		enabled:    enabled,
+		client:       client,
+		lite:         lite,
+		maxRetries:   maxRetries,
+		enabled:      enabled,
+		todoPlanning: todoPlanning,
+		optIn:        optIn,
+		cacheTTL:     cacheTTL,
+		regenQuota:   regenQuota,
+		cache:        make(map[string]aiCacheEntry),
 	}
 }
 
@@ -33,58 +97,307 @@ func (s *AIService) IsEnabled() bool {
 	return s.enabled && s.client != nil
 }
 
-// ReminderData holds the data needed to generate a reminder
-type ReminderData struct {
-	City         string
-	Date         string
-	Weather      *qweather.CurrentWeather
-	LifeIndices  []qweather.LifeIndex
-	Todos        []model.Todo
-	CalendarInfo string                       // Formatted calendar info including lunar date, festivals, solar terms
-	AirQuality   *qweather.AirQualityResponse // Air quality data (optional)
-	Warnings     []qweather.Warning           // Weather warnings (optional)
+// DefaultMode returns the AI mode of subscriptions that did not choose one
+func (s *AIService) DefaultMode() string {
+	if s.optIn {
+		return model.AIModeOff
+	}
+	return model.AIModeFull
 }
 
-// GenerateReminder generates a daily reminder using AI with retry logic
-// Returns the generated content and a boolean indicating success
-func (s *AIService) GenerateReminder(ctx context.Context, data ReminderData) (string, bool) {
+// ReminderMode returns how a subscription's daily reminder is written: model.AIModeOff,
+// model.AIModeFull or model.AIModeLite
+func (s *AIService) ReminderMode(sub model.Subscription) string {
+	if !s.IsEnabled() {
+		return model.AIModeOff
+	}
+	switch sub.AIMode {
+	case model.AIModeOff, model.AIModeFull, model.AIModeLite:
+		return sub.AIMode
+	default:
+		return s.DefaultMode()
+	}
+}
+
+// RegenerateQuota returns how many AI reminders a user may regenerate a day (0 = none)
+func (s *AIService) RegenerateQuota() int {
 	if !s.IsEnabled() {
+		return 0
+	}
+	return s.regenQuota
+}
+
+// RegenerateReminder is GenerateReminder for a user asking for another version of a reminder:
+// it bypasses the cache and samples at a higher temperature with a random seed
+func (s *AIService) RegenerateReminder(ctx context.Context, report *DailyReport, mode string) (string, bool) {
+	if !s.IsEnabled() || mode == model.AIModeOff {
 		return "", false
 	}
 
-	systemPrompt := buildSystemPrompt()
-	userPrompt := buildUserPrompt(data)
+	client, style := s.client, report.Style
+	if mode == model.AIModeLite {
+		client, style.Persona = s.lite, "concise"
+	}
+	client = client.WithTemperature(min(client.Temperature()+regenerateTemperatureStep, regenerateTemperatureMax))
+	seed := rand.Int64()
+	systemPrompt := buildSystemPrompt(style)
+	content, err := s.completeSeededWith(ctx, client, systemPrompt, s.fitUserPrompt(client, systemPrompt, report), seed)
+	if err != nil {
+		logger.Error("AI service unavailable after retries",
+			zap.Int("attempts", s.maxRetries),
+			zap.Error(err))
+		return "", false
+	}
+	if err := validateReminderReply(content); err != nil {
+		logger.Warn("Discarding AI reminder", zap.Error(err))
+		return "", false
+	}
+
+	logger.Debug("AI regenerated reminder successfully", zap.String("mode", mode))
+	return content, true
+}
+
+// GenerateReminder generates a daily reminder using AI with retry logic in the given mode
+// (model.AIModeLite writes a concise reminder with the lite model).
+// Returns the generated content and a boolean indicating success
+func (s *AIService) GenerateReminder(ctx context.Context, report *DailyReport, mode string) (string, bool) {
+	if !s.IsEnabled() || mode == model.AIModeOff {
+		return "", false
+	}
+
+	client, style := s.client, report.Style
+	if mode == model.AIModeLite {
+		client, style.Persona = s.lite, "concise"
+	}
+	systemPrompt := buildSystemPrompt(style)
+	content, err := s.completeCached(ctx, client, systemPrompt, s.fitUserPrompt(client, systemPrompt, report))
+	if err != nil {
+		logger.Error("AI service unavailable after retries",
+			zap.Int("attempts", s.maxRetries),
+			zap.Error(err))
+		return "", false
+	}
+	if err := validateReminderReply(content); err != nil {
+		logger.Warn("Discarding AI reminder", zap.Error(err))
+		return "", false
+	}
+
+	logger.Debug("AI generated reminder successfully", zap.String("mode", mode))
+	return content, true
+}
+
+// complete runs a chat completion with retries and exponential backoff, returning the last error on failure
+func (s *AIService) complete(ctx context.Context, systemPrompt, userPrompt string) (string, error) {
+	return s.retry(ctx, func() (string, error) {
+		return s.client.GetContent(ctx, systemPrompt, userPrompt)
+	})
+}
+
+// completeCached is complete with the given client, reusing the completion of an identical prompt
+// for cacheTTL. Concurrent identical prompts (e.g. subscribers of one city without todos) wait
+// for a single request, which is not canceled with the context of the caller that started it;
+// each caller stops waiting when its own context ends.
+func (s *AIService) completeCached(ctx context.Context, client *openai.Client, systemPrompt, userPrompt string) (string, error) {
+	if s.cacheTTL <= 0 {
+		return s.retry(ctx, func() (string, error) {
+			return client.GetContent(ctx, systemPrompt, userPrompt)
+		})
+	}
+
+	key := promptKey(client.Model(), systemPrompt, userPrompt)
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		logger.Debug("AI completion served from cache", zap.String("model", client.Model()))
+		return entry.content, nil
+	}
+
+	ch := s.group.DoChan(key, func() (interface{}, error) {
+		shared, cancel := context.WithTimeout(context.WithoutCancel(ctx), sharedCompletionTimeout)
+		defer cancel()
+		content, err := s.retry(shared, func() (string, error) {
+			return client.GetContent(shared, systemPrompt, userPrompt)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		s.mu.Lock()
+		for k, e := range s.cache {
+			if now.After(e.expiresAt) {
+				delete(s.cache, k)
+			}
+		}
+		s.cache[key] = aiCacheEntry{content: content, expiresAt: now.Add(s.cacheTTL)}
+		s.mu.Unlock()
+		return content, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// promptKey returns the cache key of a completion: a hash of the model and prompts
+func promptKey(model, systemPrompt, userPrompt string) string {
+	sum := sha256.Sum256([]byte(model + "\x00" + systemPrompt + "\x00" + userPrompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// completeSeeded is complete with a sampling seed, for content that should repeat for the same seed
+func (s *AIService) completeSeeded(ctx context.Context, systemPrompt, userPrompt string, seed int64) (string, error) {
+	return s.completeSeededWith(ctx, s.client, systemPrompt, userPrompt, seed)
+}
+
+// completeSeededWith is completeSeeded with the given client
+func (s *AIService) completeSeededWith(ctx context.Context, client *openai.Client, systemPrompt, userPrompt string, seed int64) (string, error) {
+	return s.retry(ctx, func() (string, error) {
+		return client.GetSeededContent(ctx, systemPrompt, userPrompt, seed)
+	})
+}
+
+// SetPromptBudget caps the tokens of reminder prompts below the model's context window (0 = no cap)
+func (s *AIService) SetPromptBudget(tokens int) {
+	s.promptBudget = tokens
+}
+
+// fitUserPrompt builds the user prompt of a reminder, leaving out its least important sections
+// while the prompts would exceed the client's prompt limit or the configured budget
+func (s *AIService) fitUserPrompt(client *openai.Client, systemPrompt string, report *DailyReport) string {
+	limit := client.PromptLimit()
+	if s.promptBudget > 0 {
+		limit = min(limit, s.promptBudget)
+	}
+	estimate := func(userPrompt string) int {
+		return openai.EstimateMessageTokens([]openai.Message{
+			{Role: "system", Content: systemPrompt},
+			{Role: "user", Content: userPrompt},
+		})
+	}
+
+	trim := promptTrim{hourlyHours: promptHourlyHours}
+	userPrompt := buildUserPrompt(report, trim)
+	tokens := estimate(userPrompt)
+	full, steps := tokens, 0
+	for ; tokens > limit && steps < len(promptTrimSteps); steps++ {
+		promptTrimSteps[steps](&trim)
+		userPrompt = buildUserPrompt(report, trim)
+		tokens = estimate(userPrompt)
+	}
+	switch {
+	case tokens > limit:
+		logger.Warn("AI reminder prompt exceeds the token limit after trimming",
+			zap.String("model", client.Model()),
+			zap.Int("tokens", tokens),
+			zap.Int("limit", limit))
+	case steps > 0:
+		logger.Info("AI reminder prompt trimmed to fit the token limit",
+			zap.String("model", client.Model()),
+			zap.Int("steps", steps),
+			zap.Int("tokens_before", full),
+			zap.Int("tokens", tokens),
+			zap.Int("limit", limit))
+	}
+	return userPrompt
+}
 
+// SetHealthProbe makes AI requests fail fast, without retries, while the probe reports the AI
+// server as down
+func (s *AIService) SetHealthProbe(probe *openai.HealthProbe) {
+	s.probe = probe
+}
+
+// retry runs a completion up to maxRetries times with exponential backoff, returning the last error on failure.
+// Errors that cannot succeed on a retry (rejected key, bad request, exhausted quota) end it at
+// once, and a longer wait requested by the server (Retry-After) replaces the backoff unless it
+// exceeds maxRetryAfter.
+func (s *AIService) retry(ctx context.Context, attempt func() (string, error)) (string, error) {
+	if s.probe != nil {
+		if err := s.probe.Err(); err != nil {
+			return "", fmt.Errorf("AI server unavailable: %w", err)
+		}
+	}
 	var lastErr error
 	for i := 0; i < s.maxRetries; i++ {
-		content, err := s.client.GetContent(ctx, systemPrompt, userPrompt)
+		content, err := attempt()
 		if err == nil {
-			logger.Debug("AI generated reminder successfully", zap.Int("attempt", i+1))
-			return content, true
+			return content, nil
 		}
 
 		lastErr = err
+		if !openai.IsRetryable(err) {
+			logger.Warn("AI generation failed, not retrying",
+				zap.Int("attempt", i+1),
+				zap.Error(err))
+			return "", err
+		}
+		if i == s.maxRetries-1 {
+			break
+		}
+
+		// Exponential backoff, or the wait the server asked for
+		wait := time.Duration(1<<i) * time.Second
+		if retryAfter := openai.RetryAfter(err); retryAfter > maxRetryAfter {
+			logger.Warn("AI generation rate limited, giving up",
+				zap.Duration("retry_after", retryAfter),
+				zap.Error(err))
+			return "", err
+		} else if retryAfter > wait {
+			wait = retryAfter
+		}
 		logger.Warn("AI generation failed, retrying...",
 			zap.Int("attempt", i+1),
 			zap.Int("max_retries", s.maxRetries),
+			zap.Duration("wait", wait),
 			zap.Error(err))
 
-		// Exponential backoff
-		if i < s.maxRetries-1 {
-			time.Sleep(time.Duration(1<<i) * time.Second)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return "", ctx.Err()
 		}
 	}
+	return "", lastErr
+}
 
-	logger.Error("AI service unavailable after retries",
-		zap.Int("attempts", s.maxRetries),
-		zap.Error(lastErr))
+// decodeJSONReply decodes the JSON object in a model reply, ignoring any surrounding text or code fences
+func decodeJSONReply(content string, v interface{}) error {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return fmt.Errorf("no JSON object in reply")
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
 
-	return "", false
+// aiPersonas are the writing personas selectable by experiments (ReportStyle.Persona)
+var aiPersonas = map[string]string{
+	"warm":     "语气像贴心的家人，温柔体贴，多说一些关心的话",
+	"concise":  "语气简洁干练，像专业的天气播报员，只说重点，总长度控制在 200 字以内",
+	"humorous": "语气轻松幽默，可以适当开个小玩笑，但预警等重要信息必须严肃准确",
 }
 
-// buildSystemPrompt builds the system prompt for AI generation
-func buildSystemPrompt() string {
-	return `你是一个友善的每日提醒助手。你的任务是根据提供的日期、天气数据和待办事项，生成一条温馨、自然的提醒消息。
+// buildSystemPrompt builds the system prompt for AI generation in the given style
+func buildSystemPrompt(style ReportStyle) string {
+	hints := strings.Split(style.ToneHints, ",")
+	emojiRule := "使用适当的 emoji 增加亲和力和可读性"
+	if style.Emoji == "minimal" || slices.Contains(hints, model.ToneHintFewerEmoji) {
+		emojiRule = "尽量不使用 emoji，以纯文字为主"
+	}
+	lengthLimit := "400"
+	if slices.Contains(hints, model.ToneHintShorter) {
+		lengthLimit = "200"
+	}
+	prompt := `你是一个友善的每日提醒助手。你的任务是根据提供的日期、天气数据和待办事项，生成一条温馨、自然的提醒消息。
 
 要求：
 1. 开头根据现在的时间给予问候（比如早上好、中午好等），展示今日日期（公历和农历），如有节日或节气要特别提及
@@ -104,20 +417,56 @@ func buildSystemPrompt() string {
 7. 自然地提及今日待办事项，如有多项可按重要程度排序提醒
 8. 根据天气、节日、待办事项的综合情况给出贴心的生活建议
 9. 保持积极正面、温暖友善的语气
-10. 使用适当的 emoji 增加亲和力和可读性
-11. 总长度控制在 400 字以内
+10. ` + emojiRule + `
+11. 总长度控制在 ` + lengthLimit + ` 字以内
 12. 使用中文回复`
+	if persona, ok := aiPersonas[style.Persona]; ok {
+		prompt += "\n13. 写作风格（与以上要求冲突时以此为准）：" + persona
+	}
+	return prompt + "\n\n" + userContentRule
+}
+
+// promptHourlyHours is how many hours of the hourly forecast a reminder prompt includes
+const promptHourlyHours = 12
+
+// promptOmitted stands in for a section left out of a prompt to fit the token limit
+const promptOmitted = "（篇幅所限，已省略）"
+
+// promptTrim lists what a reminder prompt leaves out to fit the token limit
+type promptTrim struct {
+	warningDetails bool // Full warning texts (types, levels and titles stay)
+	otherIndices   bool // Life indices other than dressing, UV and sports
+	indexDetails   bool // Detailed advice of the key life indices
+	advice         bool // Dressing and seasonal care tips
+	hourlyHours    int  // Hours of the hourly forecast (0 = none)
 }
 
-// buildUserPrompt builds the user prompt with weather and todo data
-func buildUserPrompt(data ReminderData) string {
-	loc, _ := time.LoadLocation("Asia/Shanghai")
-	now := time.Now().In(loc)
+// promptTrimSteps leave out the least important sections of a reminder prompt, in order; the
+// weather, warnings, air quality, calendar and todos are always kept
+var promptTrimSteps = []func(*promptTrim){
+	func(t *promptTrim) { t.warningDetails = true },
+	func(t *promptTrim) { t.otherIndices = true },
+	func(t *promptTrim) { t.hourlyHours = 6 },
+	func(t *promptTrim) { t.indexDetails = true },
+	func(t *promptTrim) { t.advice = true },
+	func(t *promptTrim) { t.hourlyHours = 0 },
+}
+
+// buildUserPrompt builds the user prompt from a daily report, which must include the current weather,
+// leaving out the sections named by trim
+func buildUserPrompt(report *DailyReport, trim promptTrim) string {
+	weather := report.Weather
 	// Calculate temperature difference for AI analysis
 	tempDiff := ""
-	if data.Weather.Temp != "" && data.Weather.FeelsLike != "" {
+	if weather.Temp != "" && weather.FeelsLike != "" {
 		// Note: This is for display purposes; actual calculation would need parsing
-		tempDiff = fmt.Sprintf("（温差：实际温度与体感温度相差 %s°C - %s°C）", data.Weather.Temp, data.Weather.FeelsLike)
+		tempDiff = fmt.Sprintf("（温差：实际温度与体感温度相差 %s°C - %s°C）", weather.Temp, weather.FeelsLike)
+	}
+
+	// Name the place as the user does when the subscription is labelled ("家", "公司")
+	place := sanitizeUserText(report.City, maxPromptCityRunes)
+	if report.Label != "" {
+		place += fmt.Sprintf("（用户称这里为「%s」，提到该地时请自然地使用这个称呼）", sanitizeUserText(report.Label, maxPromptCityRunes))
 	}
 
 	// Format weather information with more details
@@ -129,96 +478,103 @@ func buildUserPrompt(data ReminderData) string {
 天气状况: %s
 相对湿度: %s%%
 风向风力: %s %s级 (风速 %s km/h)`,
-		data.City,
-		data.Date,
-		now.Format("15:04"),
-		data.Weather.Temp,
-		data.Weather.FeelsLike,
+		place,
+		report.Date.Format("2006-01-02"),
+		promptClock(report.Date).Format("15:04"),
+		weather.Temp,
+		weather.FeelsLike,
 		tempDiff,
-		data.Weather.Text,
-		data.Weather.Humidity,
-		data.Weather.WindDir,
-		data.Weather.WindScale,
-		data.Weather.WindSpeed,
+		weather.Text,
+		weather.Humidity,
+		weather.WindDir,
+		weather.WindScale,
+		weather.WindSpeed,
 	)
 
-	// Format life indices with more details
+	// Format life indices, key indices (dressing, UV, sports) first with more details
 	var indicesInfo string
-	indicesMap := make(map[string]qweather.LifeIndex)
-	for _, idx := range data.LifeIndices {
-		indicesMap[idx.Type] = idx
-	}
-
-	// Prioritize important indices: dressing (3), UV (5), sports (1)
-	importantTypes := []string{"3", "5", "1"}
-	for _, typ := range importantTypes {
-		if idx, exists := indicesMap[typ]; exists {
-			indicesInfo += fmt.Sprintf("• %s：等级 %s，%s\n  详细建议：%s\n",
-				idx.Name, idx.Level, idx.Category, idx.Text)
+	for _, idx := range report.KeyIndices {
+		if trim.indexDetails {
+			indicesInfo += fmt.Sprintf("• %s：等级 %s，%s\n", idx.Name, idx.Level, idx.Category)
+			continue
 		}
+		indicesInfo += fmt.Sprintf("• %s：等级 %s，%s\n  详细建议：%s\n",
+			idx.Name, idx.Level, idx.Category, idx.Text)
 	}
-
-	// Add other available indices
-	for _, idx := range data.LifeIndices {
-		// Skip already processed indices
-		if idx.Type == "1" || idx.Type == "3" || idx.Type == "5" {
-			continue
+	if !trim.otherIndices {
+		for _, idx := range report.OtherIndices {
+			indicesInfo += fmt.Sprintf("• %s：%s\n  %s\n", idx.Name, idx.Category, idx.Text)
 		}
-		indicesInfo += fmt.Sprintf("• %s：%s\n  %s\n", idx.Name, idx.Category, idx.Text)
 	}
-
 	if indicesInfo == "" {
 		indicesInfo = "暂无生活指数数据"
 	}
 
+	// Format dressing and seasonal care tips
+	var adviceInfo string
+	for _, tip := range report.Advice {
+		adviceInfo += fmt.Sprintf("• %s\n", tip.Text)
+	}
+	if adviceInfo == "" {
+		adviceInfo = "暂无"
+	} else if trim.advice {
+		adviceInfo = promptOmitted
+	}
+
 	// Format todos
 	var todosInfo string
-	if len(data.Todos) == 0 {
+	if len(report.Todos) == 0 {
 		todosInfo = "今日暂无待办事项"
 	} else {
-		for i, todo := range data.Todos {
-			todosInfo += fmt.Sprintf("%d. %s\n", i+1, todo.Content)
+		lines := make([]string, 0, len(report.Todos))
+		for i, todo := range report.Todos {
+			lines = append(lines, fmt.Sprintf("%d. %s", i+1, sanitizeUserText(todo.Content, maxPromptTodoRunes)))
+		}
+		todosInfo = fenceUserText(lines...) + "\n"
+		if report.TodoPlan != nil {
+			todosInfo += "（待办清单及安排建议会附在消息末尾，正文中简要提及即可，无需逐条列出）\n"
 		}
 	}
 
 	// Format air quality
 	var airQualityInfo string
-	if data.AirQuality != nil && len(data.AirQuality.Indexes) > 0 {
-		// Find primary index (prefer "qaqi" for China, or "us-epa", or first available)
-		var mainIndex qweather.AirQualityIndex
-		foundIndex := false
-		for _, idx := range data.AirQuality.Indexes {
-			if idx.Code == "qaqi" {
-				mainIndex = idx
-				foundIndex = true
-				break
-			}
-		}
-		if !foundIndex {
-			mainIndex = data.AirQuality.Indexes[0]
-		}
-
+	if air := report.Air; air != nil {
 		airQualityInfo = fmt.Sprintf(`• AQI：%.0f
 • 等级：%s
 • 类别：%s`,
-			mainIndex.Aqi,
-			mainIndex.Level,
-			mainIndex.Category)
-		if mainIndex.PrimaryPollutant.Name != "" {
-			airQualityInfo += fmt.Sprintf("\n• 主要污染物：%s", mainIndex.PrimaryPollutant.Name)
+			air.Aqi,
+			air.Level,
+			air.Category)
+		if air.PrimaryPollutant.Name != "" {
+			airQualityInfo += fmt.Sprintf("\n• 主要污染物：%s", air.PrimaryPollutant.Name)
+		}
+		if report.AirTrend != "" {
+			airQualityInfo += fmt.Sprintf("\n• 趋势：%s", report.AirTrend)
 		}
 	} else {
 		airQualityInfo = "暂无空气质量数据"
 	}
 
 	// Format calendar info
-	calendarInfo := data.CalendarInfo
+	calendarInfo := report.Calendar.AIInfo
 	if calendarInfo == "" {
-		calendarInfo = fmt.Sprintf("日期: %s", data.Date)
+		calendarInfo = fmt.Sprintf("日期: %s", report.Date.Format("2006-01-02"))
 	}
 
 	// Format warnings
-	warningsInfo := formatWarningsForAI(data.Warnings)
+	warningsInfo := formatWarningsForAI(report.Warnings, !trim.warningDetails)
+
+	// Format hourly forecast
+	hourlyInfo := promptOmitted
+	if trim.hourlyHours > 0 || len(report.Hourly) == 0 {
+		hourlyInfo = formatHourlyForAI(report.Hourly, trim.hourlyHours)
+	}
+
+	// Format UV peak
+	uvInfo := report.UVPeak
+	if uvInfo == "" {
+		uvInfo = "暂无紫外线预报"
+	}
 
 	return fmt.Sprintf(`请根据以下信息生成今日提醒：
 
@@ -231,12 +587,21 @@ func buildUserPrompt(data ReminderData) string {
 【天气信息】
 %s
 
+【逐小时预报】
+%s
+
+【紫外线】
+%s
+
 【空气质量】
 %s
 
 【生活指数】
 %s
 
+【贴心建议】
+%s
+
 【待办事项】
 %s
 
@@ -247,11 +612,14 @@ func buildUserPrompt(data ReminderData) string {
 4. 根据湿度水平说明体感舒适度（<30%%干燥，>70%%潮湿闷热）
 5. 根据AQI等级给出健康建议（优：无需特殊措施，良：敏感人群减少户外，轻度污染以上：减少户外活动，佩戴口罩）
 6. 充分利用生活指数的详细建议，给出具体可行的行动指导
-7. 如果有待办事项，要自然地融入提醒中，不要生硬列举`, calendarInfo, warningsInfo, weatherInfo, airQualityInfo, indicesInfo, todosInfo)
+7. 如果有待办事项，要自然地融入提醒中，不要生硬列举
+8. 如有逐小时预报，提示降雨、降温等天气变化的大致时段
+9. 如有紫外线峰值时段且需防晒，提醒在该时段出门前做好防晒
+10. 如有入伏、首次降温至需取暖等季节性建议，务必提及`, calendarInfo, warningsInfo, weatherInfo, hourlyInfo, uvInfo, airQualityInfo, indicesInfo, adviceInfo, todosInfo)
 }
 
-// formatWarningsForAI formats weather warnings for AI prompt
-func formatWarningsForAI(warnings []qweather.Warning) string {
+// formatWarningsForAI formats weather warnings for AI prompt, with their full texts if details is set
+func formatWarningsForAI(warnings []qweather.Warning, details bool) string {
 	if len(warnings) == 0 {
 		return "当前无天气预警"
 	}
@@ -263,7 +631,7 @@ func formatWarningsForAI(warnings []qweather.Warning) string {
 		}
 		result += fmt.Sprintf("• 预警类型：%s\n  级别：%s\n  颜色：%s\n  内容：%s",
 			w.TypeName, w.Level, w.SeverityColor, w.Title)
-		if w.Text != "" {
+		if details && w.Text != "" {
 			result += fmt.Sprintf("\n  详情：%s", w.Text)
 		}
 	}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPromptClock(t *testing.T) {
+	zones := []*time.Location{
+		time.FixedZone("CST", 8*3600),
+		time.FixedZone("IST", 5*3600+30*60),
+		time.FixedZone("NPT", 5*3600+45*60),
+		time.FixedZone("LMT", 8*3600+5*60+43),
+	}
+	tests := map[string]string{
+		"07:00:00": "07:00",
+		"07:14:59": "07:00",
+		"07:15:00": "07:15",
+		"07:44:30": "07:30",
+		"07:59:59": "07:45",
+	}
+	for _, loc := range zones {
+		for clock, want := range tests {
+			at, err := time.ParseInLocation("2006-01-02 15:04:05", "2024-03-01 "+clock, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := promptClock(at)
+			if got.Format("15:04:05") != want+":00" || got.Location() != loc {
+				t.Errorf("promptClock(%s %s) = %s, want %s", clock, loc, got.Format("15:04:05 MST"), want)
+			}
+		}
+	}
+}
@@ -2,6 +2,9 @@ package service
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -10,14 +13,35 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxAirStations bounds the monitoring stations looked up for one location
+const maxAirStations = 5
+
+// airTrendHours is how many hours of the hourly air quality forecast /air shows
+const airTrendHours = 12
+
 // AirQualityService handles air quality-related business logic
 type AirQualityService struct {
-	client *qweather.Client
+	client   *qweather.Client
+	stations locationCache  // Monitoring station locations by station ID
+	timezone *time.Location // Timezone forecast hours are shown in
+}
+
+// AirStation is the latest reading of a monitoring station near a location
+type AirStation struct {
+	ID         string
+	Name       string
+	DistanceKm float64 // Distance from the location; -1 when the station could not be located
+	Pollutants []qweather.Pollutant
 }
 
 // NewAirQualityService creates a new AirQualityService
 func NewAirQualityService(client *qweather.Client) *AirQualityService {
-	return &AirQualityService{client: client}
+	return &AirQualityService{client: client, timezone: time.Local}
+}
+
+// SetTimezone sets the timezone forecast hours are shown in
+func (s *AirQualityService) SetTimezone(loc *time.Location) {
+	s.timezone = loc
 }
 
 // GetAirQualityReport generates a formatted air quality report for a city
@@ -100,6 +124,15 @@ func (s *AirQualityService) GetAirQualityReport(city string) (string, error) {
 			zap.Int("days", len(airForecast)))
 	}
 
+	// Get the hourly forecast for the trend of the coming hours (optional, non-critical)
+	hours, err := s.client.GetAirQualityHourly(location.Lat, location.Lon)
+	if err != nil {
+		logger.Warn("Failed to get air quality hourly forecast",
+			zap.String("city", city),
+			zap.Error(err))
+		hours = nil
+	}
+
 	// Build report
 	var report strings.Builder
 	report.WriteString(fmt.Sprintf("📊 %s 空气质量\n\n", city))
@@ -123,6 +156,9 @@ func (s *AirQualityService) GetAirQualityReport(city string) (string, error) {
 		}
 	}
 
+	// Trend of the coming hours
+	report.WriteString(s.formatAirTrend(hours, mainIndex.Code))
+
 	// Forecast (if available, show only next 2 days)
 	if len(airForecast) > 0 {
 		report.WriteString("\n📅 未来预报：\n")
@@ -146,3 +182,299 @@ func (s *AirQualityService) GetAirQualityReport(city string) (string, error) {
 		zap.Duration("duration", time.Since(start)))
 	return report.String(), nil
 }
+
+// NearbyStations returns the latest readings of the monitoring stations listed for the coordinates,
+// nearest first; stations that could not be located come last. Stations whose readings are
+// unavailable are left out.
+func (s *AirQualityService) NearbyStations(lat, lon string) ([]AirStation, error) {
+	airResp, err := s.client.GetAirQualityCurrent(lat, lon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current air quality: %w", err)
+	}
+	stations := s.locateStations(lat, lon, airResp.Stations)
+
+	readings := make([]AirStation, 0, len(stations))
+	for _, station := range stations {
+		data, err := s.client.GetAirStation(station.ID)
+		if err != nil {
+			logger.Warn("Failed to get air station",
+				zap.String("station_id", station.ID),
+				zap.Error(err))
+			continue
+		}
+		station.Pollutants = data.Pollutants
+		readings = append(readings, station)
+	}
+	if len(readings) == 0 {
+		return nil, fmt.Errorf("no monitoring station data available")
+	}
+	return readings, nil
+}
+
+// NearestStation returns the latest reading of the nearest located monitoring station of those
+// listed in a current air quality response
+func (s *AirQualityService) NearestStation(lat, lon string, listed []qweather.Station) (*AirStation, error) {
+	stations := s.locateStations(lat, lon, listed)
+	if len(stations) == 0 || stations[0].DistanceKm < 0 {
+		return nil, fmt.Errorf("no located monitoring station")
+	}
+	nearest := stations[0]
+	data, err := s.client.GetAirStation(nearest.ID)
+	if err != nil {
+		return nil, err
+	}
+	nearest.Pollutants = data.Pollutants
+	return &nearest, nil
+}
+
+// locateStations looks up the listed stations' locations (cached) and orders them by distance
+// from the coordinates
+func (s *AirQualityService) locateStations(lat, lon string, listed []qweather.Station) []AirStation {
+	if len(listed) > maxAirStations {
+		listed = listed[:maxAirStations]
+	}
+	stations := make([]AirStation, 0, len(listed))
+	for _, st := range listed {
+		station := AirStation{ID: st.ID, Name: st.Name, DistanceKm: -1}
+		loc, ok := s.stations.get(st.ID)
+		if !ok {
+			var err error
+			if loc, err = s.client.GetPOI(st.ID, qweather.POITypeAir); err != nil {
+				logger.Debug("Failed to locate air station",
+					zap.String("station_id", st.ID),
+					zap.Error(err))
+			} else {
+				s.stations.put(st.ID, loc)
+			}
+		}
+		if loc != nil {
+			if d, ok := distanceKm(lat, lon, loc.Lat, loc.Lon); ok {
+				station.DistanceKm = d
+			}
+		}
+		stations = append(stations, station)
+	}
+	sort.SliceStable(stations, func(i, j int) bool {
+		a, b := stations[i].DistanceKm, stations[j].DistanceKm
+		if a < 0 || b < 0 {
+			return b < 0 && a >= 0
+		}
+		return a < b
+	})
+	return stations
+}
+
+// GetStationsReport generates a report of the monitoring stations near the coordinates of a place
+func (s *AirQualityService) GetStationsReport(place, lat, lon string) (string, error) {
+	stations, err := s.NearbyStations(lat, lon)
+	if err != nil {
+		return "", err
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("📡 %s 附近空气质量监测站\n", place))
+	for i, station := range stations {
+		report.WriteString(fmt.Sprintf("\n%d. %s", i+1, station.Name))
+		if station.DistanceKm >= 0 {
+			report.WriteString(fmt.Sprintf("（%.1f km）", station.DistanceKm))
+		}
+		report.WriteString("\n")
+		if aqi, ok := station.AQI(); ok {
+			report.WriteString(fmt.Sprintf("   AQI：%.0f（%s）\n", aqi, aqiCategory(aqi)))
+		}
+		var readings []string
+		for _, p := range station.Pollutants {
+			readings = append(readings, fmt.Sprintf("%s %s %s", p.Name, strconv.FormatFloat(p.Concentration.Value, 'f', -1, 64), p.Concentration.Unit))
+		}
+		if len(readings) > 0 {
+			report.WriteString("   " + strings.Join(readings, " · ") + "\n")
+		}
+	}
+	return report.String(), nil
+}
+
+// AQI returns the station's AQI under the Chinese standard: the highest pollutant sub-index
+func (st AirStation) AQI() (float64, bool) {
+	aqi, found := 0.0, false
+	for _, p := range st.Pollutants {
+		for _, sub := range p.SubIndexes {
+			if sub.Code == "cn-mee" && sub.Aqi >= aqi {
+				aqi, found = sub.Aqi, true
+			}
+		}
+	}
+	return aqi, found
+}
+
+// PrimaryPollutant returns the pollutant with the highest sub-index when the AQI exceeds 50,
+// following the Chinese standard
+func (st AirStation) PrimaryPollutant() qweather.PrimaryPollutant {
+	var primary qweather.PrimaryPollutant
+	highest := 50.0
+	for _, p := range st.Pollutants {
+		for _, sub := range p.SubIndexes {
+			if sub.Code == "cn-mee" && sub.Aqi > highest {
+				highest = sub.Aqi
+				primary = qweather.PrimaryPollutant{Code: p.Code, Name: p.Name, FullName: p.FullName}
+			}
+		}
+	}
+	return primary
+}
+
+// aqiCategory returns the category of an AQI under the Chinese standard
+func aqiCategory(aqi float64) string {
+	switch {
+	case aqi <= 50:
+		return "优"
+	case aqi <= 100:
+		return "良"
+	case aqi <= 150:
+		return "轻度污染"
+	case aqi <= 200:
+		return "中度污染"
+	case aqi <= 300:
+		return "重度污染"
+	default:
+		return "严重污染"
+	}
+}
+
+// distanceKm returns the great-circle distance between two coordinates given as strings
+func distanceKm(lat1, lon1, lat2, lon2 string) (float64, bool) {
+	var coords [4]float64
+	for i, v := range []string{lat1, lon1, lat2, lon2} {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, false
+		}
+		coords[i] = f * math.Pi / 180
+	}
+	dLat, dLon := coords[2]-coords[0], coords[3]-coords[1]
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(coords[0])*math.Cos(coords[2])*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * 6371 * math.Asin(math.Sqrt(h)), true
+}
+
+// formatAirTrend writes the AQI of the coming hours, every 3 hours, and their peak. The index
+// with the given code is used, falling back to the first index of each hour.
+func (s *AirQualityService) formatAirTrend(hours []qweather.AirHourly, code string) string {
+	if len(hours) > airTrendHours {
+		hours = hours[:airTrendHours]
+	}
+	var points []string
+	var peak *qweather.AirQualityIndex
+	var peakAt string
+	for i, hour := range hours {
+		index := hourlyIndex(hour, code)
+		if index == nil {
+			continue
+		}
+		at := hour.ForecastTime
+		if t, ok := parseForecastTime(hour.ForecastTime); ok {
+			at = t.In(s.timezone).Format("15时")
+		}
+		if i%3 == 0 {
+			points = append(points, fmt.Sprintf("%s %s", at, index.AqiDisplay))
+		}
+		if peak == nil || index.Aqi > peak.Aqi {
+			peak, peakAt = index, at
+		}
+	}
+	if len(points) == 0 {
+		return ""
+	}
+	var trend strings.Builder
+	trend.WriteString(fmt.Sprintf("\n⏱️ 未来 %d 小时 AQI：\n", len(hours)))
+	trend.WriteString("   " + strings.Join(points, " → ") + "\n")
+	trend.WriteString(fmt.Sprintf("   最高：%s AQI %s（%s）\n", peakAt, peak.AqiDisplay, peak.Category))
+	return trend.String()
+}
+
+// airTrendHint returns a hint that the air will turn polluted later today, compared with the
+// current Chinese-standard AQI: the first coming hour of the day reaching a worse category of at
+// least light pollution. Returns "" when the air stays the same or better.
+func airTrendHint(current *qweather.AirQualityResponse, hours []qweather.AirHourly, now time.Time) string {
+	var currentAQI float64
+	found := false
+	if current != nil {
+		for _, index := range current.Indexes {
+			if index.Code == "cn-mee" {
+				currentAQI, found = index.Aqi, true
+				break
+			}
+		}
+	}
+	if !found {
+		return ""
+	}
+	for _, hour := range hours {
+		t, ok := parseForecastTime(hour.ForecastTime)
+		if !ok {
+			continue
+		}
+		t = t.In(now.Location())
+		if t.Before(now.Truncate(time.Hour)) {
+			continue
+		}
+		if t.YearDay() != now.YearDay() || t.Year() != now.Year() {
+			break
+		}
+		index := hourlyIndex(hour, "cn-mee")
+		if index == nil || index.Code != "cn-mee" {
+			continue
+		}
+		if index.Aqi > 100 && aqiLevel(index.Aqi) > aqiLevel(currentAQI) {
+			return fmt.Sprintf("空气将在%s转差：%d 时起 AQI 约 %.0f（%s）", dayPeriod(t.Hour()), t.Hour(), index.Aqi, aqiCategory(index.Aqi))
+		}
+	}
+	return ""
+}
+
+// hourlyIndex returns the index of an hour with the given code, else its first index
+func hourlyIndex(hour qweather.AirHourly, code string) *qweather.AirQualityIndex {
+	for i := range hour.Indexes {
+		if hour.Indexes[i].Code == code {
+			return &hour.Indexes[i]
+		}
+	}
+	if len(hour.Indexes) > 0 {
+		return &hour.Indexes[0]
+	}
+	return nil
+}
+
+// parseForecastTime parses a forecast time of the v1 APIs, e.g. "2025-06-01T08:00Z"
+func parseForecastTime(value string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02T15:04Z07:00", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// dayPeriod names the part of the day of an hour
+func dayPeriod(hour int) string {
+	switch {
+	case hour < 6:
+		return "凌晨"
+	case hour < 11:
+		return "上午"
+	case hour < 13:
+		return "中午"
+	case hour < 18:
+		return "下午"
+	default:
+		return "晚上"
+	}
+}
+
+// aqiLevel returns the level (1-6) of an AQI under the Chinese standard
+func aqiLevel(aqi float64) int {
+	for level, upper := range []float64{50, 100, 150, 200, 300} {
+		if aqi <= upper {
+			return level + 1
+		}
+	}
+	return 6
+}
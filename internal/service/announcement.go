@@ -0,0 +1,74 @@
+package service
+
+import (
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// announcementSendInterval spaces out messages to stay below Telegram's broadcast limit (~30 msg/s)
+const announcementSendInterval = 50 * time.Millisecond
+
+// AnnouncementService delivers admin announcements to their target users
+type AnnouncementService struct {
+	repo     *repository.AnnouncementRepository
+	userRepo *repository.UserRepository
+	telegram *notify.TelegramNotifier
+}
+
+// NewAnnouncementService creates a new AnnouncementService
+func NewAnnouncementService(
+	repo *repository.AnnouncementRepository,
+	userRepo *repository.UserRepository,
+	telegram *notify.TelegramNotifier,
+) *AnnouncementService {
+	return &AnnouncementService{
+		repo:     repo,
+		userRepo: userRepo,
+		telegram: telegram,
+	}
+}
+
+// DeliverDue sends every pending announcement scheduled at or before now
+func (s *AnnouncementService) DeliverDue(now time.Time) {
+	due, err := s.repo.ClaimDue(now)
+	if err != nil {
+		logger.Error("Failed to claim due announcements", zap.Error(err))
+	}
+
+	for _, a := range due {
+		users, err := s.userRepo.FindAnnouncementRecipients(a.CityList())
+		if err != nil {
+			// Leave it in the sending state for an admin to inspect rather than resend
+			continue
+		}
+
+		msg := notify.Message{
+			Title: "公告",
+			Body:  "📢 公告\n\n" + a.Message + "\n\n——\n发送 /announcement_toggle 可关闭公告推送",
+		}
+		failures := 0
+		for _, user := range users {
+			if err := s.telegram.For(user.Bot).SendTo(user.ChatID, msg); err != nil {
+				failures++
+				logger.Debug("Failed to send announcement",
+					zap.Uint("announcement_id", a.ID),
+					zap.Int64("chat_id", user.ChatID),
+					zap.Error(err))
+			}
+			time.Sleep(announcementSendInterval)
+		}
+
+		if err := s.repo.MarkSent(a.ID, len(users), failures); err != nil {
+			continue
+		}
+		logger.Info("Announcement delivered",
+			zap.Uint("announcement_id", a.ID),
+			zap.Strings("cities", a.CityList()),
+			zap.Int("recipients", len(users)),
+			zap.Int("failures", failures))
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxAuditDetailRunes caps the detail of an audit entry to its column size
+const maxAuditDetailRunes = 500
+
+// AuditService records admin and destructive actions (admin commands, announcements, tier
+// changes, user deletions) in the append-only audit log
+type AuditService struct {
+	repo *repository.AuditLogRepository
+}
+
+// NewAuditService creates a new AuditService
+func NewAuditService(repo *repository.AuditLogRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// TelegramActor returns the audit actor of a Telegram user
+func TelegramActor(userID int64) string {
+	return fmt.Sprintf("telegram:%d", userID)
+}
+
+// Record appends an action to the audit log. Failures are logged but do not fail the action.
+func (s *AuditService) Record(actor, action, target, detail string) {
+	entry := &model.AuditLog{
+		Actor:  truncateRunes(actor, 64),
+		Action: action,
+		Target: truncateRunes(target, 64),
+		Detail: truncateRunes(detail, maxAuditDetailRunes),
+	}
+	if err := s.repo.Create(entry); err != nil {
+		return
+	}
+	logger.Info("Audit",
+		zap.String("actor", entry.Actor),
+		zap.String("action", entry.Action),
+		zap.String("target", entry.Target))
+}
+
+// List returns audit entries matching the filter, newest first, along with the total count
+func (s *AuditService) List(filter repository.AuditLogFilter, offset, limit int) ([]model.AuditLog, int64, error) {
+	return s.repo.List(filter, offset, limit)
+}
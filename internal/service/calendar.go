@@ -5,17 +5,23 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/calendar"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/holiday"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
 	"go.uber.org/zap"
 )
 
+// customFestivalMaxRunes limits custom festival names written into AI prompts
+const customFestivalMaxRunes = 20
+
 // CalendarService provides calendar-related functionality
 type CalendarService struct {
 	calculator    *calendar.Calculator
 	holidayClient *holiday.Client
 	timezone      *time.Location
+	festivalRepo  *repository.CustomFestivalRepository // Users' custom festivals (nil = none)
 }
 
 // NewCalendarService creates a new CalendarService
@@ -27,6 +33,44 @@ func NewCalendarService(timezone *time.Location, holidayClient *holiday.Client)
 	}
 }
 
+// SetCustomFestivals enables users' custom festivals, added to their calendars by ForUser
+func (s *CalendarService) SetCustomFestivals(repo *repository.CustomFestivalRepository) {
+	s.festivalRepo = repo
+}
+
+// ForUser returns the calendar of a user, including their custom festivals; without any, it
+// returns s itself
+func (s *CalendarService) ForUser(userID uint) *CalendarService {
+	if s.festivalRepo == nil || userID == 0 {
+		return s
+	}
+	rows, err := s.festivalRepo.FindByUser(userID)
+	if err != nil {
+		logger.Warn("Failed to load custom festivals", zap.Uint("user_id", userID), zap.Error(err))
+		return s
+	}
+	if len(rows) == 0 {
+		return s
+	}
+	custom := make([]calendar.CustomFestival, 0, len(rows))
+	for _, row := range rows {
+		custom = append(custom, CustomFestivalOf(row))
+	}
+	clone := *s
+	clone.calculator = s.calculator.WithCustom(custom)
+	return &clone
+}
+
+// CustomFestivalOf converts a stored custom festival for the calendar
+func CustomFestivalOf(row model.CustomFestival) calendar.CustomFestival {
+	return calendar.CustomFestival{Name: row.Name, Month: row.Month, Day: row.Day, Lunar: row.Lunar}
+}
+
+// NextCustomFestival returns the next date of a custom festival from date and the days until it
+func (s *CalendarService) NextCustomFestival(f calendar.CustomFestival, date time.Time) (time.Time, int) {
+	return s.calculator.NextDate(f, date)
+}
+
 // FormatDateHeader formats the date header with both solar and lunar dates
 // Example: 今天是 2025年1月28日 农历甲辰年腊月廿九
 func (s *CalendarService) FormatDateHeader(date time.Time) string {
@@ -53,22 +97,22 @@ func (s *CalendarService) FormatDateHeader(date time.Time) string {
 	return result
 }
 
-// FormatTodaySpecial formats today's special dates (festivals/solar terms)
-// Returns empty string if no special dates
-func (s *CalendarService) FormatTodaySpecial(date time.Time) string {
+// FormatTodaySpecial formats today's special dates (festivals/solar terms), leaving out the hidden
+// categories. Returns empty string if no special dates
+func (s *CalendarService) FormatTodaySpecial(date time.Time, hidden ...calendar.FestivalCategory) string {
 	logger.Debug("FormatTodaySpecial called", zap.Time("date", date))
 
 	var specials []string
 
 	// Check today's solar term
-	jieQi := s.calculator.GetTodayJieQi(date)
+	jieQi := s.todayJieQi(date, hidden)
 	if jieQi != "" {
 		specials = append(specials, jieQi)
 		logger.Debug("Today's solar term found", zap.String("jie_qi", jieQi))
 	}
 
 	// Check today's festivals
-	festivals := s.calculator.GetTodayFestivals(date)
+	festivals := s.calculator.GetTodayFestivals(date, hidden...)
 	specials = append(specials, festivals...)
 	if len(festivals) > 0 {
 		logger.Debug("Today's festivals found", zap.Strings("festivals", festivals))
@@ -85,13 +129,14 @@ func (s *CalendarService) FormatTodaySpecial(date time.Time) string {
 	return fmt.Sprintf("【%s】", strings.Join(specials, " | "))
 }
 
-// FormatUpcomingFestivals formats the upcoming festivals countdown
-func (s *CalendarService) FormatUpcomingFestivals(date time.Time, limit int) string {
+// FormatUpcomingFestivals formats the upcoming festivals countdown, leaving out the hidden
+// categories
+func (s *CalendarService) FormatUpcomingFestivals(date time.Time, limit int, hidden ...calendar.FestivalCategory) string {
 	logger.Debug("FormatUpcomingFestivals called",
 		zap.Time("date", date),
 		zap.Int("limit", limit))
 
-	festivals := s.calculator.GetUpcomingFestivals(date, limit+5) // Get extra for filtering
+	festivals := s.calculator.GetUpcomingFestivals(date, limit+5, hidden...) // Get extra for filtering
 
 	if len(festivals) == 0 {
 		logger.Debug("No upcoming festivals found")
@@ -159,14 +204,56 @@ func (s *CalendarService) FormatUpcomingFestivals(date time.Time, limit int) str
 	return builder.String()
 }
 
-// GetCalendarInfo returns comprehensive calendar information for AI prompts
-func (s *CalendarService) GetCalendarInfo(date time.Time) *calendar.CalendarInfo {
+// IsWorkday reports whether a date is a working day, using the holiday API for make-up working
+// days and days off in lieu and falling back to Monday to Friday
+func (s *CalendarService) IsWorkday(date time.Time) bool {
+	if s.holidayClient != nil {
+		workday, err := s.holidayClient.IsWorkday(date)
+		if err == nil {
+			return workday
+		}
+		logger.Warn("Failed to get workday from holiday API, using weekdays",
+			zap.Time("date", date),
+			zap.Error(err))
+	}
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+}
+
+// DayNotes lists the solar term and festivals of a date, and returns the statutory holiday the
+// date is a day off of according to the holiday API ("" = none, or the API is unavailable)
+func (s *CalendarService) DayNotes(date time.Time) ([]string, string) {
+	var notes []string
+	if jieQi := s.calculator.GetTodayJieQi(date); jieQi != "" {
+		notes = append(notes, jieQi)
+	}
+	notes = append(notes, s.calculator.GetTodayFestivals(date)...)
+
+	if s.holidayClient == nil {
+		return notes, ""
+	}
+	holidays, err := s.holidayClient.GetYearHolidays(date.Year())
+	if err != nil {
+		logger.Warn("Failed to get year holidays", zap.Int("year", date.Year()), zap.Error(err))
+		return notes, ""
+	}
+	day := date.Format("2006-01-02")
+	for _, h := range holidays {
+		if h.Date.Format("2006-01-02") == day {
+			return notes, h.Name
+		}
+	}
+	return notes, ""
+}
+
+// GetCalendarInfo returns comprehensive calendar information for AI prompts, leaving out the
+// hidden festival categories
+func (s *CalendarService) GetCalendarInfo(date time.Time, hidden ...calendar.FestivalCategory) *calendar.CalendarInfo {
 	logger.Debug("GetCalendarInfo called", zap.Time("date", date))
 
 	info := s.calculator.GetDateInfo(date)
-	festivals := s.calculator.GetUpcomingFestivals(date, 5)
-	todayFestivals := s.calculator.GetTodayFestivals(date)
-	todayJieQi := s.calculator.GetTodayJieQi(date)
+	festivals := s.calculator.GetUpcomingFestivals(date, 5, hidden...)
+	todayFestivals := s.calculator.GetTodayFestivals(date, hidden...)
+	todayJieQi := s.todayJieQi(date, hidden)
 
 	logger.Debug("Calendar info retrieved",
 		zap.Int("upcoming_festivals", len(festivals)),
@@ -181,11 +268,12 @@ func (s *CalendarService) GetCalendarInfo(date time.Time) *calendar.CalendarInfo
 	}
 }
 
-// FormatCalendarInfoForAI formats calendar information for AI prompts
-func (s *CalendarService) FormatCalendarInfoForAI(date time.Time) string {
+// FormatCalendarInfoForAI formats calendar information for AI prompts, leaving out the hidden
+// festival categories
+func (s *CalendarService) FormatCalendarInfoForAI(date time.Time, hidden ...calendar.FestivalCategory) string {
 	logger.Debug("FormatCalendarInfoForAI called", zap.Time("date", date))
 
-	info := s.GetCalendarInfo(date)
+	info := s.GetCalendarInfo(date, hidden...)
 	if info == nil || info.DateInfo == nil {
 		logger.Debug("No calendar info available")
 		return ""
@@ -200,23 +288,64 @@ func (s *CalendarService) FormatCalendarInfoForAI(date time.Time) string {
 		info.DateInfo.LunarYearCN, info.DateInfo.LunarMonthCN, info.DateInfo.LunarDayCN))
 	builder.WriteString(fmt.Sprintf("生肖: %s\n", info.DateInfo.Zodiac))
 
+	// Custom festivals are written by users, so they are listed apart in a fence
+	var custom []string
+	customNames := make(map[string]bool)
+	upcoming := make([]calendar.Festival, 0, len(info.UpcomingFestivals))
+	for _, f := range info.UpcomingFestivals {
+		if f.Type != calendar.FestivalTypeCustom {
+			upcoming = append(upcoming, f)
+			continue
+		}
+		name := sanitizeUserText(f.Name, customFestivalMaxRunes)
+		if f.DaysUntil == 0 {
+			custom = append(custom, fmt.Sprintf("- %s（今天）", name))
+			customNames[f.Name] = true
+		} else {
+			custom = append(custom, fmt.Sprintf("- %s（%d天后）", name, f.DaysUntil))
+		}
+	}
+	var today []string
+	for _, name := range info.TodayFestivals {
+		if !customNames[name] {
+			today = append(today, name)
+		}
+	}
+
 	// Today's special
 	if info.TodayJieQi != "" {
 		builder.WriteString(fmt.Sprintf("今日节气: %s\n", info.TodayJieQi))
 	}
-	if len(info.TodayFestivals) > 0 {
-		builder.WriteString(fmt.Sprintf("今日节日: %s\n", strings.Join(info.TodayFestivals, ", ")))
+	if len(today) > 0 {
+		builder.WriteString(fmt.Sprintf("今日节日: %s\n", strings.Join(today, ", ")))
 	}
 
 	// Upcoming festivals
-	if len(info.UpcomingFestivals) > 0 {
+	if len(upcoming) > 0 {
 		builder.WriteString("近期节日:\n")
-		for _, f := range info.UpcomingFestivals {
+		for _, f := range upcoming {
 			if f.DaysUntil > 0 {
 				builder.WriteString(fmt.Sprintf("- %s（%d天后）\n", f.Name, f.DaysUntil))
 			}
 		}
 	}
+	if len(custom) > 0 {
+		builder.WriteString("用户自己的节日:\n")
+		builder.WriteString(fenceUserText(custom...))
+		builder.WriteString("\n")
+	}
 
 	return builder.String()
 }
+
+// todayJieQi returns today's solar term, or "" when there is none or its category is hidden
+func (s *CalendarService) todayJieQi(date time.Time, hidden []calendar.FestivalCategory) string {
+	jieQi := s.calculator.GetTodayJieQi(date)
+	category := calendar.SolarTermCategory(jieQi)
+	for _, c := range hidden {
+		if c == category {
+			return ""
+		}
+	}
+	return jieQi
+}
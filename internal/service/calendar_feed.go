@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/ics"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// sectionAgenda is the name of the digest section listing the events of users' calendar feeds
+const sectionAgenda = "agenda"
+
+// Limits of the agenda section
+const (
+	agendaMaxEvents        = 10 // Events listed per reminder
+	agendaSummaryMaxRunes  = 40 // Length of an event title
+	agendaLocationMaxRunes = 20 // Length of an event location
+	calendarFeedNameMax    = 20 // Length of a feed name
+)
+
+// ErrCalendarFeedLimit is returned when a user already has the maximum number of calendar feeds
+var ErrCalendarFeedLimit = errors.New("calendar feed limit reached")
+
+// CalendarFeedService imports users' iCalendar feeds (school timetables, team calendars) and is
+// the agenda digest section, shown after the calendar, listing the events of the day. Feeds are
+// synced daily; a failed sync keeps the events of the last successful one.
+type CalendarFeedService struct {
+	repo       *repository.CalendarFeedRepository
+	client     *ics.Client
+	maxPerUser int
+	timezone   *time.Location // Location of floating times and all-day dates
+
+	mu     sync.Mutex
+	parsed map[uint]parsedCalendar // Keyed by feed ID
+}
+
+// parsedCalendar is the parsed document of a feed's last sync
+type parsedCalendar struct {
+	syncedAt time.Time
+	calendar *ics.Calendar
+}
+
+// agendaEvent is an event of the day with the name of its feed
+type agendaEvent struct {
+	feed string
+	ics.Occurrence
+}
+
+// agendaContent is the fetched agenda section of a reminder
+type agendaContent struct {
+	day    time.Time
+	events []agendaEvent
+	feeds  int // Feeds with events today, to tell whether to show feed names
+}
+
+// NewCalendarFeedService creates a new CalendarFeedService
+func NewCalendarFeedService(repo *repository.CalendarFeedRepository, client *ics.Client, maxPerUser int, timezone *time.Location) *CalendarFeedService {
+	return &CalendarFeedService{
+		repo:       repo,
+		client:     client,
+		maxPerUser: maxPerUser,
+		timezone:   timezone,
+		parsed:     make(map[uint]parsedCalendar),
+	}
+}
+
+// MaxPerUser returns the maximum number of calendar feeds per user
+func (s *CalendarFeedService) MaxPerUser() int {
+	return s.maxPerUser
+}
+
+// Name implements DigestSection
+func (s *CalendarFeedService) Name() string {
+	return sectionAgenda
+}
+
+// After implements SectionAnchor: the agenda follows the calendar
+func (s *CalendarFeedService) After() string {
+	return sectionCalendar
+}
+
+// Personal implements PersonalSection: the feeds are the subscriber's own
+func (s *CalendarFeedService) Personal() bool {
+	return true
+}
+
+// Fetch implements DigestSection: it returns the events of the day of the subscriber's feeds, or
+// nil when there are none
+func (s *CalendarFeedService) Fetch(ctx context.Context, target *SectionTarget) (SectionContent, error) {
+	feeds, err := s.repo.FindByUser(target.Sub.UserID)
+	if err != nil {
+		return nil, err
+	}
+	content := &agendaContent{day: target.Now}
+	for _, feed := range feeds {
+		cal := s.calendar(feed)
+		if cal == nil {
+			continue
+		}
+		occurrences := cal.EventsOn(target.Now)
+		if len(occurrences) > 0 {
+			content.feeds++
+		}
+		for _, occurrence := range occurrences {
+			content.events = append(content.events, agendaEvent{feed: feed.Name, Occurrence: occurrence})
+		}
+	}
+	if len(content.events) == 0 {
+		return nil, nil
+	}
+	return content, nil
+}
+
+// Render lists the events of the day, all-day events first
+func (c *agendaContent) Render(string) string {
+	var section strings.Builder
+	section.WriteString("🗓 今日日程：\n")
+	for i, event := range c.events {
+		if i == agendaMaxEvents {
+			section.WriteString(fmt.Sprintf("…… 另有 %d 项\n", len(c.events)-i))
+			break
+		}
+		line := truncateRunes(event.Summary, agendaSummaryMaxRunes)
+		if line == "" {
+			line = "（无标题）"
+		}
+		if event.Location != "" {
+			line += "（" + truncateRunes(event.Location, agendaLocationMaxRunes) + "）"
+		}
+		if c.feeds > 1 {
+			line = "[" + event.feed + "] " + line
+		}
+		section.WriteString("• " + agendaTime(event.Occurrence, c.day) + " " + line + "\n")
+	}
+	section.WriteString("\n")
+	return section.String()
+}
+
+// agendaTime formats when an event happens on day: "全天", "08:00-09:40", or the part of an
+// event spanning midnight ("08:00 起", "至 10:00")
+func agendaTime(o ics.Occurrence, day time.Time) string {
+	if o.AllDay {
+		return "全天"
+	}
+	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+	start, end := o.Start.In(day.Location()), o.End.In(day.Location())
+	switch {
+	case start.Before(dayStart) && end.After(dayEnd):
+		return "全天"
+	case start.Before(dayStart):
+		return "至 " + end.Format("15:04")
+	case end.After(dayEnd):
+		return start.Format("15:04") + " 起"
+	case !end.After(start):
+		return start.Format("15:04")
+	}
+	return start.Format("15:04") + "-" + end.Format("15:04")
+}
+
+// calendar returns the parsed document of a feed's last sync, or nil when it has none
+func (s *CalendarFeedService) calendar(feed model.CalendarFeed) *ics.Calendar {
+	if feed.SyncedAt == nil || feed.Content == "" {
+		return nil
+	}
+	s.mu.Lock()
+	cached, ok := s.parsed[feed.ID]
+	s.mu.Unlock()
+	if ok && cached.syncedAt.Equal(*feed.SyncedAt) {
+		return cached.calendar
+	}
+
+	cal, err := ics.Parse([]byte(feed.Content), s.timezone)
+	if err != nil {
+		logger.Warn("Failed to parse calendar feed",
+			zap.Uint("feed_id", feed.ID),
+			zap.Error(err))
+		return nil
+	}
+	if len(cal.Skipped) > 0 {
+		logger.Warn("Skipped calendar feed events with unsupported recurrence rules",
+			zap.Uint("feed_id", feed.ID),
+			zap.Errors("reasons", cal.Skipped))
+	}
+	s.mu.Lock()
+	s.parsed[feed.ID] = parsedCalendar{syncedAt: *feed.SyncedAt, calendar: cal}
+	s.mu.Unlock()
+	return cal
+}
+
+// fetch downloads and parses the calendar at a feed URL
+func (s *CalendarFeedService) fetch(ctx context.Context, rawURL string) ([]byte, *ics.Calendar, error) {
+	data, err := s.client.Fetch(ctx, rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	cal, err := ics.Parse(data, s.timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, cal, nil
+}
+
+// Add subscribes a user to the calendar at rawURL (http, https or webcal), fetching it at once.
+// name defaults to the calendar's own name.
+func (s *CalendarFeedService) Add(ctx context.Context, userID uint, rawURL, name string) (*model.CalendarFeed, error) {
+	feeds, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(feeds) >= s.maxPerUser {
+		return nil, ErrCalendarFeedLimit
+	}
+	if err := validateFeedURL(strings.Replace(rawURL, "webcal://", "https://", 1)); err != nil {
+		return nil, err
+	}
+	data, cal, err := s.fetch(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = cal.Name
+	}
+	if name == "" {
+		name = fmt.Sprintf("日历%d", len(feeds)+1)
+	}
+	now := time.Now()
+	feed := &model.CalendarFeed{
+		UserID:   userID,
+		Name:     truncateRunes(name, calendarFeedNameMax),
+		URL:      rawURL,
+		Content:  string(data),
+		SyncedAt: &now,
+	}
+	if err := s.repo.Create(feed); err != nil {
+		return nil, err
+	}
+	logger.Info("Calendar feed added",
+		zap.Uint("user_id", userID),
+		zap.Uint("feed_id", feed.ID),
+		zap.Int("events", len(cal.Events)))
+	return feed, nil
+}
+
+// List returns a user's calendar feeds
+func (s *CalendarFeedService) List(userID uint) ([]model.CalendarFeed, error) {
+	return s.repo.FindByUser(userID)
+}
+
+// Delete unsubscribes a user from a calendar feed, reporting whether it existed
+func (s *CalendarFeedService) Delete(userID, id uint) (bool, error) {
+	deleted, err := s.repo.Delete(userID, id)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	delete(s.parsed, id)
+	s.mu.Unlock()
+	if deleted {
+		logger.Info("Calendar feed deleted",
+			zap.Uint("user_id", userID),
+			zap.Uint("feed_id", id))
+	}
+	return deleted, nil
+}
+
+// SyncAll fetches every feed again. A feed that fails keeps the events of its last successful
+// sync and records the error, shown to its owner by /ics.
+func (s *CalendarFeedService) SyncAll(ctx context.Context) (JobResult, error) {
+	feeds, err := s.repo.FindAll()
+	if err != nil {
+		return JobResult{}, err
+	}
+	result := JobResult{Items: len(feeds)}
+	for _, feed := range feeds {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		data, _, err := s.fetch(ctx, feed.URL)
+		if err != nil {
+			result.Failures++
+			logger.Warn("Failed to sync calendar feed",
+				zap.Uint("feed_id", feed.ID),
+				zap.Error(err))
+			if err := s.repo.SaveSyncError(feed.ID, truncateError(err.Error(), 255)); err != nil {
+				logger.Warn("Failed to record calendar feed sync error", zap.Uint("feed_id", feed.ID), zap.Error(err))
+			}
+			continue
+		}
+		if err := s.repo.SaveSync(feed.ID, string(data), time.Now()); err != nil {
+			result.Failures++
+			logger.Warn("Failed to save calendar feed", zap.Uint("feed_id", feed.ID), zap.Error(err))
+		}
+	}
+	logger.Info("Calendar feeds synced",
+		zap.Int("feeds", result.Items),
+		zap.Int("failures", result.Failures))
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// combinedBatches groups the subscriptions due at the same time into reminders: the subscriptions
+// of a user who chose combined reminders form one batch, every other subscription its own.
+// Batches keep the order of their first subscription.
+func combinedBatches(subs []model.Subscription) [][]model.Subscription {
+	var batches [][]model.Subscription
+	byUser := make(map[uint]int)
+	for _, sub := range subs {
+		if sub.User.CombinedDigest {
+			if i, ok := byUser[sub.UserID]; ok {
+				batches[i] = append(batches[i], sub)
+				continue
+			}
+			byUser[sub.UserID] = len(batches)
+		}
+		batches = append(batches, []model.Subscription{sub})
+	}
+	return batches
+}
+
+// sendCombinedReminder sends one reminder covering several subscriptions of a user due at the same
+// time: a weather overview of every city followed by their todos. It always uses the fixed
+// template. The delivery is recorded for each subscription, and each city's digest is published
+// as for a separate reminder. The reminder is composed for now (local time).
+func (s *SchedulerService) sendCombinedReminder(subs []model.Subscription, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	reports := make([]*DailyReport, len(subs))
+	var g errgroup.Group
+	for i, sub := range subs {
+		g.Go(func() error {
+			reports[i] = s.reports.Build(s.gatherReminderData(ctx, sub, now, false))
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	message := s.reports.RenderCombined(reports)
+
+	kind := model.DeliveryKindReminder
+	for _, report := range reports {
+		if report.Weather == nil {
+			kind = model.DeliveryKindFallback
+		}
+	}
+	messageID, sendErr := s.deliver(subs[0], kind, message, nil, nil)
+	for _, sub := range subs[1:] {
+		s.recordDelivery(sub, kind, message, messageID, sendErr)
+	}
+	logger.Info("Combined reminder sent",
+		zap.Uint("user_id", subs[0].UserID),
+		zap.Int("subscriptions", len(subs)),
+		zap.Bool("delivered", sendErr == nil))
+
+	for i, sub := range subs {
+		s.followUpReminder(ctx, sub, reports[i], now)
+	}
+	return sendErr
+}
+
+// RenderCombined renders the combined reminder of several cities: the calendar once, a line of
+// current weather and air quality per city, the warnings of every city, the todos grouped by city
+// and the additional registered sections, each distinct text once.
+func (b *ReportBuilder) RenderCombined(reports []*DailyReport) string {
+	first := reports[0]
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("🌅 早安！今日提醒（%d 个城市）\n", len(reports)))
+	if content := first.section(sectionCalendar); content != nil {
+		report.WriteString(content.Render(first.Locale))
+	}
+
+	report.WriteString("🌤 各城市天气：\n")
+	for _, r := range reports {
+		report.WriteString(formatCombinedWeather(r) + "\n")
+	}
+	report.WriteString("\n")
+
+	var warnings []string
+	for _, r := range reports {
+		for _, w := range r.Warnings {
+			warnings = append(warnings, fmt.Sprintf("%s %s：%s", getWarningEmojiFromColor(w.SeverityColor), r.displayName(), w.Title))
+		}
+		if r.Unavailable.Warnings {
+			warnings = append(warnings, fmt.Sprintf("⚠️ %s：预警暂时无法获取", r.displayName()))
+		}
+	}
+	if len(warnings) > 0 {
+		report.WriteString("⚠️ 天气预警\n" + strings.Join(warnings, "\n") + "\n\n")
+	}
+
+	seen := make(map[string]bool)
+	for _, r := range reports {
+		for _, text := range r.extraSections(nil) {
+			if !seen[text] {
+				seen[text] = true
+				report.WriteString(text + "\n\n")
+			}
+		}
+	}
+
+	report.WriteString(formatCombinedTodos(reports))
+	report.WriteString("\n💡 各城市的生活指数与详细天气可通过 /weather <城市> 查看")
+	return report.String()
+}
+
+// formatCombinedWeather formats the overview line of a city: weather, temperature, humidity and
+// air quality, or why they are missing
+func formatCombinedWeather(r *DailyReport) string {
+	switch {
+	case r.Unavailable.Location:
+		return fmt.Sprintf("📍 %s｜暂时无法获取该城市的天气", r.displayName())
+	case r.Weather == nil:
+		return fmt.Sprintf("📍 %s｜天气数据暂时无法获取", r.displayName())
+	}
+	line := fmt.Sprintf("📍 %s｜%s %s°C｜湿度 %s%%", r.displayName(), r.Weather.Text, r.Weather.Temp, r.Weather.Humidity)
+	if r.Air != nil {
+		line += fmt.Sprintf("｜空气 %s（%s）", r.Air.Category, r.Air.AqiDisplay)
+	}
+	if r.AirTrend != "" {
+		line += "\n   📈 " + r.AirTrend
+	}
+	return line
+}
+
+// formatCombinedTodos formats the todos of every city under the city's name, numbered as in /todo
+func formatCombinedTodos(reports []*DailyReport) string {
+	var section strings.Builder
+	for _, r := range reports {
+		if r.section(sectionTodos) == nil || len(r.Todos) == 0 {
+			continue
+		}
+		section.WriteString(fmt.Sprintf("【%s】\n", r.displayName()))
+		for i, todo := range r.Todos {
+			status := "⬜"
+			if todo.Completed {
+				status = "✅"
+			}
+			section.WriteString(fmt.Sprintf("%d. %s %s\n", i+1, status, todo.Content))
+		}
+	}
+	if section.Len() == 0 {
+		return "📝 暂无待办事项\n"
+	}
+	return "📝 待办事项：\n" + section.String()
+}
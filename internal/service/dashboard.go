@@ -0,0 +1,196 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DashboardCodeTTL is how long a /dashboard login code can be used
+const DashboardCodeTTL = 5 * time.Minute
+
+// dashboardCodeDigits is the length of login codes, typed by users into the dashboard
+const dashboardCodeDigits = 8
+
+// Errors of dashboard logins
+var (
+	ErrDashboardCodeInvalid  = errors.New("invalid or expired login code")
+	ErrDashboardUnauthorized = errors.New("invalid or expired session")
+)
+
+// DashboardService pairs the web dashboard with users: /dashboard issues a short-lived one-time
+// code, which the dashboard exchanges for a session scoped to that user's subscriptions and todos
+type DashboardService struct {
+	repo         *repository.DashboardRepository
+	userRepo     *repository.UserRepository
+	subRepo      *repository.SubscriptionRepository
+	todoSvc      *TodoService
+	deliveryRepo *repository.DeliveryLogRepository
+	sessionTTL   time.Duration
+	url          string
+}
+
+// NewDashboardService creates a new DashboardService; url is where users open the dashboard ("" = unknown)
+func NewDashboardService(repo *repository.DashboardRepository, userRepo *repository.UserRepository, subRepo *repository.SubscriptionRepository, todoSvc *TodoService, deliveryRepo *repository.DeliveryLogRepository, sessionTTL time.Duration, url string) *DashboardService {
+	return &DashboardService{
+		repo:         repo,
+		userRepo:     userRepo,
+		subRepo:      subRepo,
+		todoSvc:      todoSvc,
+		deliveryRepo: deliveryRepo,
+		sessionTTL:   sessionTTL,
+		url:          url,
+	}
+}
+
+// URL returns where users open the dashboard ("" = not configured)
+func (s *DashboardService) URL() string {
+	return s.url
+}
+
+// IssueCode creates a one-time login code for a user, replacing the user's earlier codes, and
+// removes expired codes and sessions
+func (s *DashboardService) IssueCode(user *model.User, now time.Time) (string, time.Time, error) {
+	if n, err := s.repo.DeleteExpired(now); err != nil {
+		logger.Warn("Failed to clean up dashboard logins", zap.Error(err))
+	} else if n > 0 {
+		logger.Debug("Dashboard logins cleaned up", zap.Int64("deleted", n))
+	}
+
+	expires := now.Add(DashboardCodeTTL)
+	var lastErr error
+	// Retry on the rare collision with another user's unexpired code
+	for attempt := 0; attempt < 3; attempt++ {
+		code, err := randomDigits(dashboardCodeDigits)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		lastErr = s.repo.ReplaceCode(&model.DashboardCode{UserID: user.ID, CodeHash: hashSecret(code), ExpiresAt: expires})
+		if lastErr == nil {
+			logger.Info("Dashboard login code issued", zap.Uint("user_id", user.ID))
+			return code, expires, nil
+		}
+	}
+	return "", time.Time{}, lastErr
+}
+
+// Login exchanges a login code for a session token, returning the token and when it expires
+func (s *DashboardService) Login(code string, now time.Time) (string, time.Time, error) {
+	userID, ok, err := s.repo.ClaimCode(hashSecret(code), now)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if !ok {
+		return "", time.Time{}, ErrDashboardCodeInvalid
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	expires := now.Add(s.sessionTTL)
+	if err := s.repo.CreateSession(&model.DashboardSession{UserID: userID, TokenHash: hashSecret(token), ExpiresAt: expires}); err != nil {
+		return "", time.Time{}, err
+	}
+	logger.Info("Dashboard login", zap.Uint("user_id", userID))
+	return token, expires, nil
+}
+
+// Authenticate returns the user of a session token
+func (s *DashboardService) Authenticate(token string, now time.Time) (*model.User, error) {
+	if token == "" {
+		return nil, ErrDashboardUnauthorized
+	}
+	session, err := s.repo.FindSession(hashSecret(token), now)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrDashboardUnauthorized
+	}
+	user, err := s.userRepo.FindByID(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		// The user was deleted; the session is of no use anymore
+		_ = s.repo.DeleteSession(session.TokenHash)
+		return nil, ErrDashboardUnauthorized
+	}
+	return user, nil
+}
+
+// Logout ends the session of a token
+func (s *DashboardService) Logout(token string) error {
+	return s.repo.DeleteSession(hashSecret(token))
+}
+
+// Subscriptions returns a user's active subscriptions with their todos
+func (s *DashboardService) Subscriptions(user *model.User) ([]SubscriptionTodos, error) {
+	subs, err := s.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.todoSvc.SubscriptionLists(subs)
+}
+
+// Subscription returns one of a user's active subscriptions with its todos (nil if not the user's)
+func (s *DashboardService) Subscription(user *model.User, id uint) (*SubscriptionTodos, error) {
+	sub, err := s.ownSubscription(user, id)
+	if err != nil || sub == nil {
+		return nil, err
+	}
+	lists, err := s.todoSvc.SubscriptionLists([]model.Subscription{*sub})
+	if err != nil {
+		return nil, err
+	}
+	return &lists[0], nil
+}
+
+// Deliveries returns the most recent reminder deliveries of one of a user's subscriptions
+func (s *DashboardService) Deliveries(user *model.User, subscriptionID uint, limit int) ([]model.DeliveryLog, error) {
+	sub, err := s.ownSubscription(user, subscriptionID)
+	if err != nil || sub == nil {
+		return nil, err
+	}
+	return s.deliveryRepo.FindBySubscriptionID(subscriptionID, limit)
+}
+
+// ownSubscription returns an active subscription of the user (nil if it isn't one)
+func (s *DashboardService) ownSubscription(user *model.User, id uint) (*model.Subscription, error) {
+	sub, err := s.subRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || sub.UserID != user.ID || !sub.Active {
+		return nil, nil
+	}
+	return sub, nil
+}
+
+// randomDigits returns a random string of decimal digits
+func randomDigits(n int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	v, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate login code: %w", err)
+	}
+	return fmt.Sprintf("%0*d", n, v), nil
+}
+
+// hashSecret returns the hex SHA-256 of a login code or session token, which is what is stored
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
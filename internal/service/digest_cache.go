@@ -0,0 +1,64 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// digestHistory is the number of daily digests kept per city
+const digestHistory = 7
+
+// Digest is a rendered, non-personal daily city digest (weather, air quality, warnings; no todos)
+type Digest struct {
+	City        string
+	Date        string // YYYY-MM-DD in the scheduler timezone
+	Title       string
+	Body        string
+	GeneratedAt time.Time
+}
+
+// DigestCache keeps the most recent rendered digests per city in memory
+type DigestCache struct {
+	mu      sync.RWMutex
+	digests map[string][]Digest // city -> digests, newest first
+}
+
+// NewDigestCache creates a new DigestCache
+func NewDigestCache() *DigestCache {
+	return &DigestCache{digests: make(map[string][]Digest)}
+}
+
+// Put stores a digest, replacing an earlier digest of the same city and date
+func (c *DigestCache) Put(d Digest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	history := []Digest{d}
+	for _, existing := range c.digests[d.City] {
+		if existing.Date != d.Date && len(history) < digestHistory {
+			history = append(history, existing)
+		}
+	}
+	c.digests[d.City] = history
+}
+
+// Recent returns the cached digests of a city, newest first
+func (c *DigestCache) Recent(city string) []Digest {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Digest(nil), c.digests[city]...)
+}
+
+// Cities returns the cities with cached digests in sorted order
+func (c *DigestCache) Cities() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	cities := make([]string, 0, len(c.digests))
+	for city := range c.digests {
+		cities = append(cities, city)
+	}
+	sort.Strings(cities)
+	return cities
+}
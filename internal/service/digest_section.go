@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// sectionFetchTimeout bounds each section's Fetch, so a slow section cannot hold up the reminder
+const sectionFetchTimeout = 20 * time.Second
+
+// DigestSection is a pluggable section of the daily reminder. The scheduler fetches every
+// registered section concurrently for each reminder and renders the contents in order, so new
+// sections (stocks, news headlines) only need to be registered, not wired into the scheduler.
+type DigestSection interface {
+	// Name identifies the section in logs; it must be unique among the registered sections
+	Name() string
+	// Fetch gathers the section's data for a subscription, honouring ctx. A failing section may
+	// still return content, e.g. a placeholder; nil content leaves the section out.
+	Fetch(ctx context.Context, target *SectionTarget) (SectionContent, error)
+}
+
+// SectionContent is the data a digest section fetched for one reminder
+type SectionContent interface {
+	// Render returns the section text for the user's Telegram language code ("" = default),
+	// ending with a blank line; "" leaves the section out
+	Render(locale string) string
+}
+
+// SectionAnchor is optionally implemented by digest sections that belong right after a built-in
+// section of the fixed template instead of after all of them
+type SectionAnchor interface {
+	// After returns the built-in section to follow: warnings, calendar, weather, indices, advice
+	// or air. Unknown names place the section after the built-in ones.
+	After() string
+}
+
+// PersonalSection is optionally implemented by digest sections whose content depends on the
+// subscriber's own settings. Personal sections are left out of the city digest.
+type PersonalSection interface {
+	Personal() bool
+}
+
+// reportSection is implemented by the contents of the built-in sections, which also feed the AI
+// prompt and the other report consumers
+type reportSection interface {
+	fill(r *DailyReport)
+}
+
+// SectionTarget is the subscription a digest section is fetched for. The location is resolved
+// once per reminder and shared by all sections.
+type SectionTarget struct {
+	Sub         model.Subscription
+	Now         time.Time             // Local time the reminder is generated for
+	Location    *qweather.GeoLocation // nil when the lookup failed
+	LocationErr error
+	Lat, Lon    string // Coordinates of the subscription, or of its city ("" when unknown)
+
+	client *qweather.Client // Used by the built-in sections; a replay client when re-rendering snapshots
+}
+
+// LocationID returns the QWeather location ID, or "" when the location lookup failed
+func (t *SectionTarget) LocationID() string {
+	if t.Location == nil {
+		return ""
+	}
+	return t.Location.ID
+}
+
+// SectionRegistry holds the digest sections in registration order
+type SectionRegistry struct {
+	mu       sync.RWMutex
+	sections []DigestSection
+}
+
+// NewSectionRegistry creates a new SectionRegistry
+func NewSectionRegistry() *SectionRegistry {
+	return &SectionRegistry{}
+}
+
+// Register adds a section after the registered ones
+func (r *SectionRegistry) Register(section DigestSection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.sections {
+		if s.Name() == section.Name() {
+			return fmt.Errorf("digest section %q is already registered", section.Name())
+		}
+	}
+	r.sections = append(r.sections, section)
+	logger.Info("Digest section registered", zap.String("section", section.Name()))
+	return nil
+}
+
+// Sections returns the registered sections in registration order
+func (r *SectionRegistry) Sections() []DigestSection {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]DigestSection(nil), r.sections...)
+}
+
+// fetchedSection is the content a section fetched for a reminder
+type fetchedSection struct {
+	name     string
+	after    string // Built-in section the content follows ("" = after all of them)
+	personal bool   // Left out of the city digest
+	content  SectionContent
+}
+
+// fetchSections fetches the sections concurrently and returns the non-nil contents in section
+// order. Errors and panics are logged; they only affect their own section.
+func fetchSections(ctx context.Context, sections []DigestSection, target *SectionTarget) []fetchedSection {
+	contents := make([]SectionContent, len(sections))
+	var g errgroup.Group
+	for i, section := range sections {
+		g.Go(func() error {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error("Digest section panicked",
+						zap.String("section", section.Name()),
+						zap.Uint("subscription_id", target.Sub.ID),
+						zap.Any("panic", r))
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(ctx, sectionFetchTimeout)
+			defer cancel()
+			content, err := section.Fetch(ctx, target)
+			if err != nil {
+				logger.Warn("Failed to fetch digest section",
+					zap.String("section", section.Name()),
+					zap.Uint("subscription_id", target.Sub.ID),
+					zap.Error(err))
+			}
+			contents[i] = content
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	fetched := make([]fetchedSection, 0, len(sections))
+	for i, content := range contents {
+		if content == nil {
+			continue
+		}
+		s := fetchedSection{name: sections[i].Name(), content: content}
+		if anchor, ok := sections[i].(SectionAnchor); ok {
+			s.after = anchor.After()
+		}
+		if p, ok := sections[i].(PersonalSection); ok {
+			s.personal = p.Personal()
+		}
+		fetched = append(fetched, s)
+	}
+	return fetched
+}
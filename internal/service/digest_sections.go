@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/calendar"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+// Names of the built-in digest sections; sectionAdvice is derived from the weather sections
+// rather than fetched
+const (
+	sectionWarnings = "warnings"
+	sectionCalendar = "calendar"
+	sectionWeather  = "weather"
+	sectionIndices  = "indices"
+	sectionAdvice   = "advice"
+	sectionAir      = "air"
+	sectionTodos    = "todos"
+)
+
+// builtinSections returns the built-in digest sections in their default order. The warnings
+// section is only included when weather warnings are enabled.
+func builtinSections(calendarSvc *CalendarService, warningSvc *WarningService, todoSvc *TodoService, todoStats *TodoStatsService, airSvc *AirQualityService) []DigestSection {
+	sections := []DigestSection{}
+	if warningSvc != nil {
+		sections = append(sections, warningsSection{})
+	}
+	return append(sections,
+		calendarSection{calendarSvc: calendarSvc},
+		weatherSection{},
+		indicesSection{},
+		airSection{airSvc: airSvc},
+		todosSection{todoSvc: todoSvc, todoStats: todoStats},
+	)
+}
+
+// warningsSection shows the active weather warnings of the subscription's city
+type warningsSection struct{}
+
+type warningsContent struct {
+	warnings    []qweather.Warning
+	unavailable bool
+}
+
+func (warningsSection) Name() string { return sectionWarnings }
+
+func (warningsSection) Fetch(_ context.Context, t *SectionTarget) (SectionContent, error) {
+	if t.LocationErr != nil {
+		return &warningsContent{}, nil
+	}
+	warnings, err := t.client.GetWarningNow(t.LocationID())
+	if err != nil {
+		return &warningsContent{unavailable: true}, err
+	}
+	return &warningsContent{warnings: warnings}, nil
+}
+
+// Render writes the weather warnings (if any), meant to stay at the top
+func (c *warningsContent) Render(string) string {
+	var report strings.Builder
+	if len(c.warnings) > 0 {
+		report.WriteString("\n⚠️ 天气预警\n")
+		for _, w := range c.warnings {
+			emoji := getWarningEmojiFromColor(w.SeverityColor)
+			report.WriteString(fmt.Sprintf("%s %s\n", emoji, w.Title))
+		}
+		report.WriteString("\n")
+	} else if c.unavailable {
+		report.WriteString("\n⚠️ 天气预警暂时无法获取，请留意当地气象部门发布的预警\n\n")
+	}
+	return report.String()
+}
+
+func (c *warningsContent) fill(r *DailyReport) {
+	r.Warnings = c.warnings
+	r.Unavailable.Warnings = c.unavailable
+}
+
+// calendarSection shows the date, today's festival and the upcoming festivals
+type calendarSection struct {
+	calendarSvc *CalendarService // Calendar details (nil = date only)
+}
+
+type calendarContent struct {
+	calendar ReportCalendar
+	date     string
+	shared   *calendarContent // All festival categories, for the city digest (nil = same)
+}
+
+func (calendarSection) Name() string { return sectionCalendar }
+
+// Fetch shows the subscriber's custom festivals and the festival categories they did not hide
+// (/festivals)
+func (s calendarSection) Fetch(_ context.Context, t *SectionTarget) (SectionContent, error) {
+	content := &calendarContent{date: t.Now.Format("2006-01-02")}
+	if s.calendarSvc != nil {
+		hidden := calendar.ParseCategories(t.Sub.User.HiddenFestivals)
+		userCalendar := s.calendarSvc.ForUser(t.Sub.UserID)
+		content.calendar = reportCalendar(userCalendar, t.Now, hidden)
+		if len(hidden) > 0 || userCalendar != s.calendarSvc {
+			content.shared = &calendarContent{date: content.date, calendar: reportCalendar(s.calendarSvc, t.Now, nil)}
+		}
+	}
+	return content, nil
+}
+
+// reportCalendar formats the calendar of a day without the hidden festival categories
+func reportCalendar(svc *CalendarService, now time.Time, hidden []calendar.FestivalCategory) ReportCalendar {
+	return ReportCalendar{
+		Header:    svc.FormatDateHeader(now),
+		Special:   svc.FormatTodaySpecial(now, hidden...),
+		Festivals: svc.FormatUpcomingFestivals(now, 3, hidden...),
+		AIInfo:    svc.FormatCalendarInfoForAI(now, hidden...),
+	}
+}
+
+// impersonal implements impersonalContent: the city digest shows all festival categories
+func (c *calendarContent) impersonal() SectionContent {
+	if c.shared != nil {
+		return c.shared
+	}
+	return c
+}
+
+func (c *calendarContent) Render(string) string {
+	var report strings.Builder
+	if c.calendar.Header != "" {
+		report.WriteString(fmt.Sprintf("📆 %s\n", c.calendar.Header))
+		if c.calendar.Special != "" {
+			report.WriteString(fmt.Sprintf("🎊 %s\n", c.calendar.Special))
+		}
+		report.WriteString("\n")
+
+		// Upcoming festivals
+		if c.calendar.Festivals != "" {
+			report.WriteString(c.calendar.Festivals)
+			report.WriteString("\n")
+		}
+	} else {
+		report.WriteString(fmt.Sprintf("📆 %s\n\n", c.date))
+	}
+	return report.String()
+}
+
+func (c *calendarContent) fill(r *DailyReport) {
+	r.Calendar = c.calendar
+}
+
+// weatherSection shows the current weather, using grid weather for coordinate subscriptions
+type weatherSection struct{}
+
+type weatherContent struct {
+	city            string
+	weather         *qweather.CurrentWeather // nil when unavailable
+	locationUnknown bool
+}
+
+func (weatherSection) Name() string { return sectionWeather }
+
+func (weatherSection) Fetch(_ context.Context, t *SectionTarget) (SectionContent, error) {
+	content := &weatherContent{city: t.Sub.City, locationUnknown: t.LocationErr != nil}
+	if t.LocationErr != nil {
+		return content, nil
+	}
+	weather, err := t.client.GetCurrentWeather(t.LocationID())
+	if err != nil {
+		return content, err
+	}
+	if t.Sub.HasCoordinates() {
+		weather = withGridWeather(t.client, t.Sub, weather)
+	}
+	content.weather = weather
+	return content, nil
+}
+
+// Render writes the current weather, or a placeholder
+func (c *weatherContent) Render(string) string {
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("📍 %s 天气播报\n\n", c.city))
+	switch weather := c.weather; {
+	case weather != nil:
+		report.WriteString(fmt.Sprintf("🌡️ 温度：%s°C（体感 %s°C）\n", weather.Temp, weather.FeelsLike))
+		report.WriteString(fmt.Sprintf("☁️ 天气：%s\n", weather.Text))
+		report.WriteString(fmt.Sprintf("💧 湿度：%s%%\n", weather.Humidity))
+		report.WriteString(fmt.Sprintf("🌬️ 风向：%s %s级（%s km/h）\n\n", weather.WindDir, weather.WindScale, weather.WindSpeed))
+	case c.locationUnknown:
+		report.WriteString(fmt.Sprintf("⚠️ 无法获取 %s 的位置信息，今日天气数据暂缺\n\n", c.city))
+	default:
+		report.WriteString(fmt.Sprintf("⚠️ 实时天气暂时无法获取，可稍后使用 /weather %s 查询\n\n", c.city))
+	}
+	return report.String()
+}
+
+func (c *weatherContent) fill(r *DailyReport) {
+	r.Weather = c.weather
+	r.Unavailable.Weather = c.weather == nil
+}
+
+// withGridWeather overlays the grid weather for a coordinate subscription onto the city weather,
+// keeping the city's feels-like temperature which grid weather lacks. The city weather is
+// returned unchanged when grid weather is unavailable.
+func withGridWeather(client *qweather.Client, sub model.Subscription, weather *qweather.CurrentWeather) *qweather.CurrentWeather {
+	grid, err := client.GetGridWeatherNow(sub.Lat, sub.Lon)
+	if err != nil {
+		logger.Warn("Failed to get grid weather, using city weather",
+			zap.Uint("subscription_id", sub.ID),
+			zap.Error(err))
+		return weather
+	}
+	grid.FeelsLike = weather.FeelsLike
+	return grid
+}
+
+// indicesSection shows the key life indices (dressing, UV, sports)
+type indicesSection struct{}
+
+type indicesContent struct {
+	indices     []qweather.LifeIndex
+	unavailable bool
+}
+
+func (indicesSection) Name() string { return sectionIndices }
+
+func (indicesSection) Fetch(_ context.Context, t *SectionTarget) (SectionContent, error) {
+	if t.LocationErr != nil {
+		return &indicesContent{}, nil
+	}
+	indices, err := t.client.GetLifeIndices(t.LocationID())
+	if err != nil {
+		return &indicesContent{unavailable: true}, err
+	}
+	return &indicesContent{indices: indices}, nil
+}
+
+// Render writes the key life indices, or a placeholder
+func (c *indicesContent) Render(string) string {
+	var report strings.Builder
+	key, _ := splitLifeIndices(c.indices)
+	if len(key) > 0 {
+		report.WriteString("📋 生活指数：\n")
+		for _, index := range key {
+			report.WriteString(fmt.Sprintf("%s %s：%s\n", getIndexEmoji(index.Type), index.Name, index.Category))
+			if index.Text != "" {
+				report.WriteString(fmt.Sprintf("   %s\n", index.Text))
+			}
+		}
+		report.WriteString("\n")
+	} else if c.unavailable {
+		report.WriteString("📋 生活指数：暂时无法获取\n\n")
+	}
+	return report.String()
+}
+
+func (c *indicesContent) fill(r *DailyReport) {
+	r.Indices = c.indices
+	r.KeyIndices, r.OtherIndices = splitLifeIndices(c.indices)
+	r.Unavailable.Indices = c.unavailable
+}
+
+// airSection shows the main air quality index at the subscription's coordinates. Subscriptions
+// with their own coordinates get the reading of the nearest monitoring station when available.
+type airSection struct {
+	airSvc *AirQualityService
+}
+
+type airContent struct {
+	air         *qweather.AirQualityIndex
+	station     *AirStation // Nearest monitoring station the index was taken from
+	trend       string      // Hint that the air turns polluted later today
+	unavailable bool
+}
+
+func (airSection) Name() string { return sectionAir }
+
+func (s airSection) Fetch(_ context.Context, t *SectionTarget) (SectionContent, error) {
+	if t.Lat == "" || t.Lon == "" {
+		return &airContent{}, nil
+	}
+	airQuality, err := t.client.GetAirQualityCurrent(t.Lat, t.Lon)
+	if err != nil {
+		return &airContent{unavailable: true}, err
+	}
+	content := &airContent{air: mainAirQualityIndex(airQuality)}
+	if t.Sub.HasCoordinates() && s.airSvc != nil && len(airQuality.Stations) > 0 {
+		station, err := s.airSvc.NearestStation(t.Lat, t.Lon, airQuality.Stations)
+		if err != nil {
+			logger.Debug("Nearest air station unavailable", zap.Uint("subscription_id", t.Sub.ID), zap.Error(err))
+		} else if aqi, ok := station.AQI(); ok {
+			content.air = &qweather.AirQualityIndex{
+				Code:             "cn-mee",
+				Aqi:              aqi,
+				Level:            strconv.Itoa(aqiLevel(aqi)),
+				Category:         aqiCategory(aqi),
+				PrimaryPollutant: station.PrimaryPollutant(),
+			}
+			content.station = station
+		}
+	}
+	if hours, err := t.client.GetAirQualityHourly(t.Lat, t.Lon); err != nil {
+		logger.Debug("Air quality hourly forecast unavailable", zap.Uint("subscription_id", t.Sub.ID), zap.Error(err))
+	} else {
+		content.trend = airTrendHint(airQuality, hours, t.Now)
+	}
+	return content, nil
+}
+
+// Render writes the main air quality index, or a placeholder
+func (c *airContent) Render(string) string {
+	var report strings.Builder
+	if c.air != nil {
+		report.WriteString("🌫️ 空气质量：\n")
+		report.WriteString(fmt.Sprintf("   AQI：%.0f（%s）\n", c.air.Aqi, c.air.Category))
+		if c.station != nil {
+			report.WriteString(fmt.Sprintf("   监测站：%s（%.1f km）\n", c.station.Name, c.station.DistanceKm))
+		}
+		if c.air.PrimaryPollutant.Name != "" {
+			report.WriteString(fmt.Sprintf("   主要污染物：%s\n", c.air.PrimaryPollutant.Name))
+		}
+		if c.trend != "" {
+			report.WriteString(fmt.Sprintf("   📈 %s\n", c.trend))
+		}
+		report.WriteString("\n")
+	} else if c.unavailable {
+		report.WriteString("🌫️ 空气质量：暂时无法获取\n\n")
+	}
+	return report.String()
+}
+
+func (c *airContent) fill(r *DailyReport) {
+	r.Air = c.air
+	r.AirTrend = c.trend
+	r.Unavailable.Air = c.unavailable
+}
+
+// todosSection shows the subscription's incomplete todos and the user's completion streaks. It is
+// personal, so it is left out of the city digest.
+type todosSection struct {
+	todoSvc   *TodoService
+	todoStats *TodoStatsService // Todo streaks and badges (nil = disabled)
+}
+
+type todosContent struct {
+	todoSvc      *TodoService
+	todos        []model.Todo
+	achievements string
+}
+
+func (todosSection) Name() string { return sectionTodos }
+
+// Fetch loads the incomplete todos. Todos are scoped to subscriptions, so lookups must always use
+// the subscription's todo list (sub.TodoListID(), which differs from sub.ID for shared lists)
+// rather than sub.UserID.
+func (s todosSection) Fetch(_ context.Context, t *SectionTarget) (SectionContent, error) {
+	content := &todosContent{todoSvc: s.todoSvc}
+	if s.todoStats != nil {
+		summary, err := s.todoStats.Summary(t.Sub.UserID, t.Now)
+		if err != nil {
+			logger.Warn("Failed to get todo stats", zap.Uint("user_id", t.Sub.UserID), zap.Error(err))
+		} else {
+			content.achievements = summary.Format()
+		}
+	}
+	todos, err := s.todoSvc.GetIncompleteTodos(t.Sub.TodoListID())
+	if err != nil {
+		return content, fmt.Errorf("failed to get todos: %w", err)
+	}
+	content.todos = todos
+	return content, nil
+}
+
+// Render writes the todo list and completion streaks
+func (c *todosContent) Render(string) string {
+	return formatTodoSection(c.todoSvc, c.todos, nil, c.achievements)
+}
+
+func (c *todosContent) fill(r *DailyReport) {
+	r.Todos = c.todos
+	r.Achievements = c.achievements
+}
+
+// formatTodoSection formats the todos, ordered by the weather when planned, followed by the
+// completion streaks
+func formatTodoSection(todoSvc *TodoService, todos []model.Todo, plan *TodoPlan, achievements string) string {
+	var section string
+	if plan != nil {
+		section = todoSvc.FormatTodoPlan(plan)
+	} else {
+		section = todoSvc.FormatTodoList(todos)
+	}
+	if achievements != "" {
+		section += "\n" + achievements
+	}
+	return section
+}
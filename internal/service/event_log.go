@@ -0,0 +1,167 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Event sinks selected by events.sink
+const (
+	EventSinkFile = "file" // JSON lines appended to a file
+	EventSinkDB   = "db"   // Rows of the event_logs table
+)
+
+// BusinessEvent is a business event (see model.Event*) emitted for analytics, independent of the
+// debug logs
+type BusinessEvent struct {
+	Name           string
+	UserID         uint
+	SubscriptionID uint
+	City           string
+	Data           map[string]any // Event-specific attributes
+}
+
+// SubscriptionCreatedEvent is the subscription_created event of a new or restored subscription;
+// source tells where it was created ("bot", "admin_api")
+func SubscriptionCreatedEvent(sub *model.Subscription, source string, restored bool) BusinessEvent {
+	return BusinessEvent{
+		Name:           model.EventSubscriptionCreated,
+		UserID:         sub.UserID,
+		SubscriptionID: sub.ID,
+		City:           sub.City,
+		Data: map[string]any{
+			"source":        source,
+			"restored":      restored,
+			"reminder_time": sub.ReminderTime,
+		},
+	}
+}
+
+// EventSink stores emitted business events
+type EventSink interface {
+	Write(entry *model.EventLog) error
+	Close() error
+}
+
+// EventLogService emits business events (reminders sent, warnings pushed, subscriptions created)
+// as structured records to a dedicated sink, separate from the zap logs
+type EventLogService struct {
+	sink EventSink
+}
+
+// NewEventLogService creates a new EventLogService writing to sink
+func NewEventLogService(sink EventSink) *EventLogService {
+	return &EventLogService{sink: sink}
+}
+
+// Emit records a business event. Failures are logged but do not fail the action.
+func (s *EventLogService) Emit(event BusinessEvent) {
+	entry := &model.EventLog{
+		Event:          event.Name,
+		UserID:         event.UserID,
+		SubscriptionID: event.SubscriptionID,
+		City:           event.City,
+		CreatedAt:      time.Now(),
+	}
+	if len(event.Data) > 0 {
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			logger.Warn("Failed to encode event data", zap.String("event", event.Name), zap.Error(err))
+			return
+		}
+		entry.Data = string(data)
+	}
+	if err := s.sink.Write(entry); err != nil {
+		logger.Warn("Failed to write event", zap.String("event", event.Name), zap.Error(err))
+	}
+}
+
+// Close flushes and closes the sink
+func (s *EventLogService) Close() error {
+	return s.sink.Close()
+}
+
+// DBEventSink writes events to the event_logs table
+type DBEventSink struct {
+	repo *repository.EventLogRepository
+}
+
+// NewDBEventSink creates a new DBEventSink
+func NewDBEventSink(repo *repository.EventLogRepository) *DBEventSink {
+	return &DBEventSink{repo: repo}
+}
+
+// Write appends an event to the table
+func (s *DBEventSink) Write(entry *model.EventLog) error {
+	return s.repo.Create(entry)
+}
+
+// Close does nothing; the database is closed by its owner
+func (s *DBEventSink) Close() error {
+	return nil
+}
+
+// fileEvent is the JSON line of an event in the file sink
+type fileEvent struct {
+	Time           time.Time       `json:"time"`
+	Event          string          `json:"event"`
+	UserID         uint            `json:"user_id,omitempty"`
+	SubscriptionID uint            `json:"subscription_id,omitempty"`
+	City           string          `json:"city,omitempty"`
+	Data           json.RawMessage `json:"data,omitempty"`
+}
+
+// FileEventSink appends events to a file as JSON lines
+type FileEventSink struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewFileEventSink opens (or creates) the file events are appended to, creating its directory
+func NewFileEventSink(path string) (*FileEventSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create event log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event log: %w", err)
+	}
+	return &FileEventSink{file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Write appends an event as one JSON line
+func (s *FileEventSink) Write(entry *model.EventLog) error {
+	line := fileEvent{
+		Time:           entry.CreatedAt,
+		Event:          entry.Event,
+		UserID:         entry.UserID,
+		SubscriptionID: entry.SubscriptionID,
+		City:           entry.City,
+	}
+	if entry.Data != "" {
+		line.Data = json.RawMessage(entry.Data)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enc.Encode(line); err != nil {
+		return fmt.Errorf("failed to append event: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file
+func (s *FileEventSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
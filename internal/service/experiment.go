@@ -0,0 +1,190 @@
+package service
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// VariantDefault is the variant that keeps the regular reminder format
+const VariantDefault = "default"
+
+// ExperimentVariants lists the variants each experiment dimension supports
+var ExperimentVariants = map[string][]string{
+	model.ExperimentPersona:      {VariantDefault, "warm", "concise", "humorous"},
+	model.ExperimentSectionOrder: {VariantDefault, "weather_first"},
+	model.ExperimentEmoji:        {VariantDefault, "minimal"},
+}
+
+// Feedback sentiment keywords, matched as substrings
+var (
+	positiveWords = []string{"👍", "❤", "喜欢", "好用", "不错", "很好", "太好", "挺好", "赞", "棒", "满意", "感谢", "谢谢", "实用", "贴心"}
+	negativeWords = []string{"👎", "不喜欢", "不好", "不行", "难用", "太长", "啰嗦", "烦", "差", "没用", "失望", "看不懂", "不准"}
+)
+
+// ReportStyle is the reminder format a user gets; zero values keep the regular format
+type ReportStyle struct {
+	Persona      string // AI writing persona (AI reminders only)
+	SectionOrder string // Section order of the fixed template
+	Emoji        string // Emoji density
+	ToneHints    string // Tone hints learned from the user's votes (comma-separated model.ToneHint*; AI reminders only)
+}
+
+// ExperimentAssignment is the variant a user is assigned in a running experiment
+type ExperimentAssignment struct {
+	ExperimentID uint
+	Dimension    string
+	Variant      string
+}
+
+// ExperimentService assigns users to the variants of running reminder format experiments and
+// records their exposures and engagement
+type ExperimentService struct {
+	repo         *repository.ExperimentRepository
+	feedbackRepo *repository.FeedbackRepository
+}
+
+// NewExperimentService creates a new ExperimentService
+func NewExperimentService(repo *repository.ExperimentRepository, feedbackRepo *repository.FeedbackRepository) *ExperimentService {
+	return &ExperimentService{repo: repo, feedbackRepo: feedbackRepo}
+}
+
+// Repository returns the underlying experiment repository
+func (s *ExperimentService) Repository() *repository.ExperimentRepository {
+	return s.repo
+}
+
+// Bucket deterministically picks a user's variant index out of n for an experiment key
+func Bucket(key string, userID uint, n int) int {
+	if n <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key + ":" + strconv.FormatUint(uint64(userID), 10)))
+	return int(h.Sum32() % uint32(n))
+}
+
+// Assign returns the user's variants in the running experiments and the resulting reminder style
+func (s *ExperimentService) Assign(userID uint) ([]ExperimentAssignment, ReportStyle) {
+	var style ReportStyle
+	experiments, err := s.repo.FindRunning()
+	if err != nil {
+		logger.Warn("Failed to load running experiments", zap.Error(err))
+		return nil, style
+	}
+
+	assignments := make([]ExperimentAssignment, 0, len(experiments))
+	for _, e := range experiments {
+		variants := e.VariantList()
+		if len(variants) == 0 {
+			continue
+		}
+		variant := variants[Bucket(e.Key, userID, len(variants))]
+		assignments = append(assignments, ExperimentAssignment{ExperimentID: e.ID, Dimension: e.Dimension, Variant: variant})
+
+		if variant == VariantDefault {
+			continue
+		}
+		switch e.Dimension {
+		case model.ExperimentPersona:
+			style.Persona = variant
+		case model.ExperimentSectionOrder:
+			style.SectionOrder = variant
+		case model.ExperimentEmoji:
+			style.Emoji = variant
+		}
+	}
+	return assignments, style
+}
+
+// RecordExposure records that a reminder was delivered to the user in the assigned variants
+func (s *ExperimentService) RecordExposure(userID uint, assignments []ExperimentAssignment) {
+	_ = s.record(userID, assignments, model.ExperimentEventExposure, 0, "")
+}
+
+// TrackClick records an inline button click of a user in every running experiment
+func (s *ExperimentService) TrackClick(userID uint, button string) {
+	assignments, _ := s.Assign(userID)
+	if len(button) > 64 {
+		button = button[:64]
+	}
+	_ = s.record(userID, assignments, model.ExperimentEventClick, 0, button)
+}
+
+// SubmitFeedback stores a /feedback message and records its sentiment in every running
+// experiment, returning the sentiment
+func (s *ExperimentService) SubmitFeedback(userID uint, text string) (int, error) {
+	sentiment := Sentiment(text)
+	if err := s.feedbackRepo.Create(&model.Feedback{UserID: userID, Text: text, Sentiment: sentiment}); err != nil {
+		return 0, err
+	}
+	assignments, _ := s.Assign(userID)
+	_ = s.record(userID, assignments, model.ExperimentEventFeedback, sentiment, "")
+	logger.Info("Feedback received",
+		zap.Uint("user_id", userID),
+		zap.Int("sentiment", sentiment),
+		zap.Int("experiments", len(assignments)))
+	return sentiment, nil
+}
+
+// record stores one event per assignment
+func (s *ExperimentService) record(userID uint, assignments []ExperimentAssignment, kind string, value int, detail string) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+	now := time.Now()
+	events := make([]model.ExperimentEvent, 0, len(assignments))
+	for _, a := range assignments {
+		events = append(events, model.ExperimentEvent{
+			ExperimentID: a.ExperimentID,
+			Variant:      a.Variant,
+			Kind:         kind,
+			UserID:       userID,
+			Value:        value,
+			Detail:       detail,
+			CreatedAt:    now,
+		})
+	}
+	return s.repo.AddEvents(events)
+}
+
+// Sentiment classifies feedback text by keywords: 1 positive, -1 negative, 0 neutral or mixed
+func Sentiment(text string) int {
+	score := 0
+	rest := text
+	// Negative phrases first, so that "不喜欢" does not count as "喜欢"
+	for _, word := range negativeWords {
+		if strings.Contains(rest, word) {
+			score--
+			rest = strings.ReplaceAll(rest, word, " ")
+		}
+	}
+	for _, word := range positiveWords {
+		if strings.Contains(rest, word) {
+			score++
+		}
+	}
+	switch {
+	case score > 0:
+		return 1
+	case score < 0:
+		return -1
+	}
+	return 0
+}
+
+// IsExperimentVariant reports whether a dimension supports a variant
+func IsExperimentVariant(dimension, variant string) bool {
+	for _, v := range ExperimentVariants[dimension] {
+		if v == variant {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/fieldcrypt"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Kinds of health reminders
+const (
+	HealthKindCycle      = "cycle"      // Menstrual cycle: reminded ahead of and on the expected start
+	HealthKindMedication = "medication" // Medication: reminded every CycleDays days
+)
+
+// healthCycleLeadDays is how many days ahead of the expected start a cycle reminder is sent
+const healthCycleLeadDays = 2
+
+// healthDateLayout is the format of plan dates
+const healthDateLayout = "2006-01-02"
+
+// ErrHealthReminderLimit is returned when a user already has the maximum number of health reminders
+var ErrHealthReminderLimit = errors.New("health reminder limit reached")
+
+// HealthPlan is the decrypted content of a health reminder
+type HealthPlan struct {
+	Kind      string `json:"kind"`       // HealthKind*
+	Label     string `json:"label"`      // User-chosen name shown in the reminder ("" = a neutral default)
+	CycleDays int    `json:"cycle_days"` // Cycle length, or days between doses
+	StartDate string `json:"start_date"` // First day of a cycle or first dose (YYYY-MM-DD)
+}
+
+// HealthEntry is a user's health reminder with its decrypted plan
+type HealthEntry struct {
+	ID           uint
+	ReminderTime string
+	Plan         HealthPlan
+}
+
+// HealthReminderService manages private recurring health reminders. Plans are encrypted at rest
+// and bound to their owner, reminders go out as protected messages on their own, apart from the
+// weather digest, and their text never names the kind of reminder.
+type HealthReminderService struct {
+	repo       *repository.HealthReminderRepository
+	cipher     *fieldcrypt.Cipher
+	telegram   *notify.TelegramNotifier
+	maxPerUser int
+}
+
+// NewHealthReminderService creates a new HealthReminderService
+func NewHealthReminderService(repo *repository.HealthReminderRepository, cipher *fieldcrypt.Cipher, telegram *notify.TelegramNotifier, maxPerUser int) *HealthReminderService {
+	return &HealthReminderService{
+		repo:       repo,
+		cipher:     cipher,
+		telegram:   telegram,
+		maxPerUser: maxPerUser,
+	}
+}
+
+// MaxPerUser returns the maximum number of health reminders per user
+func (s *HealthReminderService) MaxPerUser() int {
+	return s.maxPerUser
+}
+
+// healthAssociatedData binds a payload to its owner
+func healthAssociatedData(userID uint) []byte {
+	return []byte("health_reminder:" + strconv.FormatUint(uint64(userID), 10))
+}
+
+// seal encrypts a plan for its owner
+func (s *HealthReminderService) seal(userID uint, plan HealthPlan) (string, error) {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode health plan: %w", err)
+	}
+	return s.cipher.Seal(data, healthAssociatedData(userID))
+}
+
+// open decrypts the plan of a reminder
+func (s *HealthReminderService) open(reminder model.HealthReminder) (HealthPlan, error) {
+	data, err := s.cipher.Open(reminder.Payload, healthAssociatedData(reminder.UserID))
+	if err != nil {
+		return HealthPlan{}, err
+	}
+	var plan HealthPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return HealthPlan{}, fmt.Errorf("failed to decode health plan: %w", err)
+	}
+	return plan, nil
+}
+
+// List returns a user's health reminders. Reminders that cannot be decrypted (e.g. after the key
+// changed) are logged and left out.
+func (s *HealthReminderService) List(userID uint) ([]HealthEntry, error) {
+	reminders, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]HealthEntry, 0, len(reminders))
+	for _, reminder := range reminders {
+		plan, err := s.open(reminder)
+		if err != nil {
+			logger.Warn("Failed to decrypt health reminder",
+				zap.Uint("id", reminder.ID),
+				zap.Error(err))
+			continue
+		}
+		entries = append(entries, HealthEntry{ID: reminder.ID, ReminderTime: reminder.ReminderTime, Plan: plan})
+	}
+	return entries, nil
+}
+
+// Add creates a health reminder for a user
+func (s *HealthReminderService) Add(userID uint, reminderTime string, plan HealthPlan) error {
+	count, err := s.repo.CountByUser(userID)
+	if err != nil {
+		return err
+	}
+	if int(count) >= s.maxPerUser {
+		return ErrHealthReminderLimit
+	}
+	payload, err := s.seal(userID, plan)
+	if err != nil {
+		return err
+	}
+	return s.repo.Create(&model.HealthReminder{UserID: userID, ReminderTime: reminderTime, Payload: payload})
+}
+
+// Restart moves the start date of a user's reminder, e.g. when a cycle started earlier or later
+// than expected; it reports whether the reminder exists
+func (s *HealthReminderService) Restart(userID, id uint, startDate string) (bool, error) {
+	entries, err := s.List(userID)
+	if err != nil {
+		return false, err
+	}
+	for _, entry := range entries {
+		if entry.ID != id {
+			continue
+		}
+		entry.Plan.StartDate = startDate
+		payload, err := s.seal(userID, entry.Plan)
+		if err != nil {
+			return false, err
+		}
+		return s.repo.UpdatePayload(id, userID, payload)
+	}
+	return false, nil
+}
+
+// Delete removes a user's reminder, reporting whether it existed
+func (s *HealthReminderService) Delete(userID, id uint) (bool, error) {
+	return s.repo.Delete(id, userID)
+}
+
+// SendDue sends the reminders set for a time of day (HH:MM) that fall on the date of now
+func (s *HealthReminderService) SendDue(reminderTime string, now time.Time) {
+	date := now.Format(healthDateLayout)
+	reminders, err := s.repo.FindDue(reminderTime, date)
+	if err != nil {
+		logger.Error("Failed to find due health reminders", zap.Error(err))
+		return
+	}
+
+	for _, reminder := range reminders {
+		plan, err := s.open(reminder)
+		if err != nil {
+			logger.Warn("Failed to decrypt health reminder",
+				zap.Uint("id", reminder.ID),
+				zap.Error(err))
+			continue
+		}
+		text := plan.Message(now)
+		if text == "" {
+			continue
+		}
+		claimed, err := s.repo.ClaimSend(reminder.ID, date)
+		if err != nil || !claimed {
+			continue
+		}
+
+		msg := notify.Message{Body: text + "\n\n发送 /cycle 管理私人提醒", Private: true}
+		if err := s.telegram.For(reminder.User.Bot).SendTo(reminder.User.ChatID, msg); err != nil {
+			logger.Warn("Failed to send health reminder",
+				zap.Uint("id", reminder.ID),
+				zap.Error(err))
+			continue
+		}
+		logger.Info("Health reminder sent", zap.Uint("id", reminder.ID))
+	}
+}
+
+// daysSinceStart returns the days from the plan's start date to the date of now
+func (p HealthPlan) daysSinceStart(now time.Time) (int, error) {
+	start, err := time.ParseInLocation(healthDateLayout, p.StartDate, now.Location())
+	if err != nil {
+		return 0, fmt.Errorf("invalid start date: %w", err)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// Round to absorb 23 and 25 hour days around DST changes
+	return int(today.Sub(start).Round(24*time.Hour) / (24 * time.Hour)), nil
+}
+
+// Next returns the date of the next occurrence on or after the date of now
+func (p HealthPlan) Next(now time.Time) string {
+	days, err := p.daysSinceStart(now)
+	if err != nil || p.CycleDays <= 0 {
+		return ""
+	}
+	ahead := 0
+	if days < 0 {
+		ahead = -days
+	} else if rem := days % p.CycleDays; rem > 0 {
+		ahead = p.CycleDays - rem
+	}
+	return now.AddDate(0, 0, ahead).Format(healthDateLayout)
+}
+
+// Message returns the reminder text for the date of now, or "" when nothing is due. The text only
+// shows the user's own label, so a glance at the notification does not reveal what it is about.
+func (p HealthPlan) Message(now time.Time) string {
+	days, err := p.daysSinceStart(now)
+	if err != nil || p.CycleDays <= 0 {
+		return ""
+	}
+	label := p.DisplayLabel()
+
+	switch {
+	case days >= 0 && days%p.CycleDays == 0:
+		if p.Kind == HealthKindCycle {
+			return fmt.Sprintf("🔔 私人提醒：「%s」预计今天开始", label)
+		}
+		return fmt.Sprintf("🔔 私人提醒：「%s」", label)
+	case p.Kind == HealthKindCycle && p.CycleDays > healthCycleLeadDays &&
+		days+healthCycleLeadDays >= 0 && (days+healthCycleLeadDays)%p.CycleDays == 0:
+		return fmt.Sprintf("🔔 私人提醒：「%s」预计 %d 天后开始", label, healthCycleLeadDays)
+	}
+	return ""
+}
+
+// DisplayLabel returns the label of the plan, or a neutral default
+func (p HealthPlan) DisplayLabel() string {
+	if p.Label != "" {
+		return p.Label
+	}
+	if p.Kind == HealthKindCycle {
+		return "周期"
+	}
+	return "按时服用"
+}
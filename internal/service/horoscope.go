@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/horoscope"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// sectionHoroscope is the name of the horoscope digest section
+const sectionHoroscope = "horoscope"
+
+// horoscopeSummaryMaxRunes limits the AI-written summary of a reading
+const horoscopeSummaryMaxRunes = 80
+
+// HoroscopeService is the opt-in horoscope digest section, shown after the calendar to users who
+// set their zodiac sign. Readings come from the provider; when the AI is enabled it rewrites the
+// summary with a seed derived from the sign and date. Readings are cached per sign and date, so
+// each sign costs at most one AI request a day.
+type HoroscopeService struct {
+	provider horoscope.Provider
+	aiSvc    *AIService // Writes the summaries (nil or disabled = the provider's summary)
+
+	mu    sync.Mutex
+	cache map[string]*horoscope.Reading // Keyed by sign and date
+	group singleflight.Group
+}
+
+// horoscopeContent is the fetched horoscope section of a reminder
+type horoscopeContent struct {
+	reading *horoscope.Reading
+}
+
+// NewHoroscopeService creates a new HoroscopeService
+func NewHoroscopeService(provider horoscope.Provider, aiSvc *AIService) *HoroscopeService {
+	return &HoroscopeService{
+		provider: provider,
+		aiSvc:    aiSvc,
+		cache:    make(map[string]*horoscope.Reading),
+	}
+}
+
+// Name implements DigestSection
+func (s *HoroscopeService) Name() string {
+	return sectionHoroscope
+}
+
+// After implements SectionAnchor: the horoscope follows the calendar
+func (s *HoroscopeService) After() string {
+	return sectionCalendar
+}
+
+// Personal implements PersonalSection: the sign is chosen per user
+func (s *HoroscopeService) Personal() bool {
+	return true
+}
+
+// Fetch implements DigestSection: it returns today's reading of the subscriber's sign, or nil when
+// the subscriber has not set one
+func (s *HoroscopeService) Fetch(ctx context.Context, target *SectionTarget) (SectionContent, error) {
+	sign := horoscope.Sign(target.Sub.User.Zodiac)
+	if !sign.Valid() {
+		return nil, nil
+	}
+	reading, err := s.Reading(ctx, sign, target.Now.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	return &horoscopeContent{reading: reading}, nil
+}
+
+// Render writes the ratings, lucky color and number and the summary
+func (c *horoscopeContent) Render(string) string {
+	return FormatReading(c.reading) + "\n"
+}
+
+// Reading returns the reading of a sign on a date (YYYY-MM-DD), from the cache when available
+func (s *HoroscopeService) Reading(ctx context.Context, sign horoscope.Sign, date string) (*horoscope.Reading, error) {
+	key := string(sign) + "|" + date
+	s.mu.Lock()
+	reading, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok {
+		return reading, nil
+	}
+
+	v, err, _ := s.group.Do(key, func() (interface{}, error) {
+		reading, err := s.provider.Daily(ctx, sign, date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get horoscope: %w", err)
+		}
+		if s.aiSvc != nil && s.aiSvc.IsEnabled() {
+			summary, err := s.aiSvc.WriteHoroscope(ctx, reading)
+			if err == nil {
+				reading.Summary = summary
+			} else {
+				logger.Warn("Failed to write horoscope, using the provider's summary",
+					zap.String("sign", string(sign)),
+					zap.Error(err))
+			}
+		}
+
+		s.mu.Lock()
+		// Drop readings older than the previous day, which users in other timezones may still need
+		if day, err := time.Parse("2006-01-02", date); err == nil {
+			oldest := day.AddDate(0, 0, -1).Format("2006-01-02")
+			for k, r := range s.cache {
+				if r.Date < oldest {
+					delete(s.cache, k)
+				}
+			}
+		}
+		s.cache[key] = reading
+		s.mu.Unlock()
+		return reading, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*horoscope.Reading), nil
+}
+
+// FormatReading formats a reading for Telegram
+func FormatReading(r *horoscope.Reading) string {
+	var section strings.Builder
+	section.WriteString(fmt.Sprintf("🔮 %s%s今日运势：\n", r.Sign.Emoji(), r.Sign.Name()))
+	section.WriteString(fmt.Sprintf("   综合 %s  爱情 %s\n", horoscope.Stars(r.Overall), horoscope.Stars(r.Love)))
+	section.WriteString(fmt.Sprintf("   事业 %s  财运 %s\n", horoscope.Stars(r.Career), horoscope.Stars(r.Wealth)))
+	section.WriteString(fmt.Sprintf("   健康 %s\n", horoscope.Stars(r.Health)))
+	section.WriteString(fmt.Sprintf("   幸运色：%s  幸运数字：%d\n", r.LuckyColor, r.LuckyNumber))
+	if r.Summary != "" {
+		section.WriteString("   " + r.Summary + "\n")
+	}
+	return section.String()
+}
+
+// horoscopeSystemPrompt instructs the AI to write the summary of a reading
+const horoscopeSystemPrompt = `你是轻松有趣的星座运势作者，为用户的早间提醒写今日运势寄语。
+根据给出的星座和各项评分（1-5 星），写一到两句中文（不超过 60 字），语气积极温和，点出评分最高和最低的方面，给出一条具体可行的小建议。
+不要重复列出评分，不要使用 Markdown，只输出寄语本身。`
+
+// WriteHoroscope writes the summary of a reading. The seed is derived from the sign and date, so
+// providers that honour seeds write the same summary when the reading is generated again.
+func (s *AIService) WriteHoroscope(ctx context.Context, r *horoscope.Reading) (string, error) {
+	prompt := fmt.Sprintf("星座：%s\n日期：%s\n综合：%d\n爱情：%d\n事业：%d\n财运：%d\n健康：%d\n幸运色：%s\n幸运数字：%d",
+		r.Sign.Name(), r.Date, r.Overall, r.Love, r.Career, r.Wealth, r.Health, r.LuckyColor, r.LuckyNumber)
+
+	content, err := s.completeSeeded(ctx, horoscopeSystemPrompt, prompt, horoscope.Seed(r.Sign, r.Date))
+	if err != nil {
+		return "", err
+	}
+	summary := strings.TrimSpace(strings.ReplaceAll(content, "\n", ""))
+	if summary == "" {
+		return "", fmt.Errorf("empty horoscope summary")
+	}
+	return truncateRunes(summary, horoscopeSummaryMaxRunes), nil
+}
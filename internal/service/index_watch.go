@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+// LifeIndexNames maps QWeather life index types to their names, in type order
+var LifeIndexNames = []struct {
+	Type string
+	Name string
+}{
+	{"1", "运动"}, {"2", "洗车"}, {"3", "穿衣"}, {"4", "钓鱼"}, {"5", "紫外线"}, {"6", "旅游"},
+	{"7", "过敏"}, {"8", "舒适度"}, {"9", "感冒"}, {"10", "空气污染扩散条件"}, {"11", "空调开启"},
+	{"12", "太阳镜"}, {"13", "化妆"}, {"14", "晾晒"}, {"15", "交通"}, {"16", "防晒"},
+}
+
+// IndexWatchService evaluates life index watches and pushes the ones whose level is met
+type IndexWatchService struct {
+	repo      *repository.IndexWatchRepository
+	notifySvc *NotificationService
+}
+
+// NewIndexWatchService creates a new IndexWatchService
+func NewIndexWatchService(repo *repository.IndexWatchRepository, notifySvc *NotificationService) *IndexWatchService {
+	return &IndexWatchService{repo: repo, notifySvc: notifySvc}
+}
+
+// Repository returns the underlying watch repository
+func (s *IndexWatchService) Repository() *repository.IndexWatchRepository {
+	return s.repo
+}
+
+// Evaluate pushes a standalone message for each of the subscription's watches whose index level
+// is met today, at most once per watch and day
+func (s *IndexWatchService) Evaluate(ctx context.Context, sub model.Subscription, indices []qweather.LifeIndex, now time.Time) {
+	watches, err := s.repo.FindBySubscription(sub.ID)
+	if err != nil {
+		logger.Warn("Failed to load index watches", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+		return
+	}
+	today := now.Format("2006-01-02")
+
+	for _, watch := range watches {
+		if watch.LastNotifiedOn == today {
+			continue
+		}
+		index := findLifeIndex(indices, watch.IndexType)
+		if index == nil {
+			continue
+		}
+		level, err := strconv.Atoi(index.Level)
+		if err != nil || level > watch.MaxLevel {
+			continue
+		}
+
+		title := fmt.Sprintf("%s %s指数提醒", sub.DisplayName(), LifeIndexName(watch.IndexType))
+		msg := notify.Message{
+			Title: title,
+			Body: fmt.Sprintf("🔔 %s\n\n今日%s：%s\n%s\n\n💡 使用 /index 管理指数提醒",
+				title, index.Name, index.Category, index.Text),
+		}
+		if err := s.notifySvc.Deliver(ctx, sub, msg); err != nil {
+			logger.Warn("Failed to push index watch",
+				zap.Uint("watch_id", watch.ID),
+				zap.Uint("subscription_id", sub.ID),
+				zap.Error(err))
+			continue
+		}
+		if err := s.repo.MarkNotified(watch.ID, today); err != nil {
+			logger.Warn("Failed to mark index watch notified", zap.Uint("watch_id", watch.ID), zap.Error(err))
+		}
+		logger.Info("Index watch pushed",
+			zap.Uint("watch_id", watch.ID),
+			zap.Uint("subscription_id", sub.ID),
+			zap.String("index_type", watch.IndexType),
+			zap.String("level", index.Level))
+	}
+}
+
+// ParseLifeIndex returns the index type for a name such as "洗车" or "洗车指数"
+func ParseLifeIndex(name string) (string, bool) {
+	name = strings.TrimSuffix(strings.TrimSpace(name), "指数")
+	for _, idx := range LifeIndexNames {
+		if idx.Name == name || idx.Type == name {
+			return idx.Type, true
+		}
+	}
+	return "", false
+}
+
+// LifeIndexName returns the name of an index type
+func LifeIndexName(indexType string) string {
+	for _, idx := range LifeIndexNames {
+		if idx.Type == indexType {
+			return idx.Name
+		}
+	}
+	return indexType
+}
+
+// ParseIndexCondition converts a watch condition into the highest accepted level:
+// "好"/"适宜" (or nothing) = 1, "较好"/"较适宜" = 2, or a level number
+func ParseIndexCondition(cond string) (int, bool) {
+	switch strings.TrimSpace(cond) {
+	case "", "好", "适宜", "最好":
+		return 1, true
+	case "较好", "较适宜", "还行":
+		return 2, true
+	}
+	level, err := strconv.Atoi(cond)
+	if err != nil || level < 1 || level > 7 {
+		return 0, false
+	}
+	return level, true
+}
+
+// findLifeIndex returns the index of the given type
+func findLifeIndex(indices []qweather.LifeIndex, indexType string) *qweather.LifeIndex {
+	for i := range indices {
+		if indices[i].Type == indexType {
+			return &indices[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/robfig/cron/v3"
+	"go.uber.org/zap"
+)
+
+// Kinds of interval reminders
+const (
+	IntervalKindWater   = "water"   // Drinking water
+	IntervalKindStretch = "stretch" // Standing up and stretching
+)
+
+// Bounds of the interval between reminders; intervals are multiples of intervalStepMinutes so
+// that a reminder needs only a few cron entries
+const (
+	intervalMinMinutes  = 15
+	intervalMaxMinutes  = 240
+	intervalStepMinutes = 15
+)
+
+// intervalMessages are the rotating texts of each kind of interval reminder
+var intervalMessages = map[string][]string{
+	IntervalKindWater: {
+		"💧 喝水时间到，起身倒杯水吧",
+		"💧 记得补充水分，小口慢饮更舒服",
+		"💧 喝口水，顺便让眼睛休息一下",
+	},
+	IntervalKindStretch: {
+		"🧘 久坐提醒：站起来活动一下，转转脖子、伸伸腰",
+		"🧘 起来走动两分钟，放松一下肩颈",
+		"🧘 该活动一下了：远眺片刻，做几次深呼吸",
+	},
+}
+
+// IntervalKindName returns the Chinese name of a kind of interval reminder
+func IntervalKindName(kind string) string {
+	if kind == IntervalKindStretch {
+		return "久坐活动"
+	}
+	return "喝水"
+}
+
+// IntervalReminderService sends opt-in micro-reminders such as drinking water or stretching
+// every few minutes within a daily window. Each reminder is turned into cron entries on the
+// service's own cron, rescheduled whenever the user changes it; slots on non-working days (when
+// limited to working days) and in the user's quiet hours are skipped.
+type IntervalReminderService struct {
+	repo     *repository.IntervalReminderRepository
+	calendar *CalendarService
+	telegram *notify.TelegramNotifier
+	timezone *time.Location
+	cron     *cron.Cron
+
+	mu      sync.Mutex
+	entries map[uint][]cron.EntryID // Cron entries of each reminder
+}
+
+// NewIntervalReminderService creates a new IntervalReminderService
+func NewIntervalReminderService(repo *repository.IntervalReminderRepository, calendar *CalendarService, telegram *notify.TelegramNotifier, timezone *time.Location) *IntervalReminderService {
+	return &IntervalReminderService{
+		repo:     repo,
+		calendar: calendar,
+		telegram: telegram,
+		timezone: timezone,
+		cron:     cron.New(cron.WithLocation(timezone)),
+		entries:  make(map[uint][]cron.EntryID),
+	}
+}
+
+// Start schedules the stored reminders and starts the cron
+func (s *IntervalReminderService) Start() error {
+	reminders, err := s.repo.FindAll()
+	if err != nil {
+		return err
+	}
+	for _, reminder := range reminders {
+		if err := s.schedule(reminder); err != nil {
+			logger.Warn("Failed to schedule interval reminder",
+				zap.Uint("id", reminder.ID),
+				zap.Error(err))
+		}
+	}
+	s.cron.Start()
+	logger.Info("Interval reminders started", zap.Int("reminders", len(reminders)))
+	return nil
+}
+
+// Stop stops the cron
+func (s *IntervalReminderService) Stop() {
+	s.cron.Stop()
+}
+
+// List returns a user's interval reminders
+func (s *IntervalReminderService) List(userID uint) ([]model.IntervalReminder, error) {
+	return s.repo.FindByUser(userID)
+}
+
+// Set creates or replaces a user's reminder of a kind and reschedules it
+func (s *IntervalReminderService) Set(userID uint, kind string, intervalMinutes int, startTime, endTime string, workdaysOnly bool) (*model.IntervalReminder, error) {
+	reminder := &model.IntervalReminder{
+		UserID:          userID,
+		Kind:            kind,
+		IntervalMinutes: intervalMinutes,
+		StartTime:       startTime,
+		EndTime:         endTime,
+		WorkdaysOnly:    workdaysOnly,
+	}
+	if _, err := IntervalCronSpecs(*reminder); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Upsert(reminder); err != nil {
+		return nil, err
+	}
+	if err := s.schedule(*reminder); err != nil {
+		return nil, err
+	}
+	return reminder, nil
+}
+
+// Remove deletes a user's reminder of a kind, or all of them when kind is "", reporting whether
+// any existed
+func (s *IntervalReminderService) Remove(userID uint, kind string) (bool, error) {
+	reminders, err := s.repo.FindByUser(userID)
+	if err != nil {
+		return false, err
+	}
+	removed := false
+	for _, reminder := range reminders {
+		if kind != "" && reminder.Kind != kind {
+			continue
+		}
+		deleted, err := s.repo.Delete(reminder.ID, userID)
+		if err != nil {
+			return removed, err
+		}
+		s.unschedule(reminder.ID)
+		removed = removed || deleted
+	}
+	return removed, nil
+}
+
+// schedule replaces the cron entries of a reminder
+func (s *IntervalReminderService) schedule(reminder model.IntervalReminder) error {
+	specs, err := IntervalCronSpecs(reminder)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.entries[reminder.ID] {
+		s.cron.Remove(entry)
+	}
+	delete(s.entries, reminder.ID)
+
+	id := reminder.ID
+	entries := make([]cron.EntryID, 0, len(specs))
+	for _, spec := range specs {
+		entry, err := s.cron.AddFunc(spec, func() { s.Remind(id, time.Now()) })
+		if err != nil {
+			for _, added := range entries {
+				s.cron.Remove(added)
+			}
+			return fmt.Errorf("failed to add interval reminder cron job: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	s.entries[id] = entries
+	return nil
+}
+
+// unschedule removes the cron entries of a reminder
+func (s *IntervalReminderService) unschedule(id uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.entries[id] {
+		s.cron.Remove(entry)
+	}
+	delete(s.entries, id)
+}
+
+// Remind sends a reminder for the slot at the given time, unless the day or the user's quiet
+// hours exclude it. It is exposed so tests and tools can fire a slot deterministically.
+func (s *IntervalReminderService) Remind(id uint, at time.Time) {
+	reminder, err := s.repo.FindByID(id)
+	if err != nil {
+		logger.Error("Failed to load interval reminder", zap.Uint("id", id), zap.Error(err))
+		return
+	}
+	if reminder == nil {
+		// Removed with its user
+		s.unschedule(id)
+		return
+	}
+
+	local := at.In(s.timezone)
+	if reminder.WorkdaysOnly && !s.calendar.IsWorkday(local) {
+		return
+	}
+	clock := local.Format("15:04")
+	if InQuietHours(reminder.User.QuietHours, clock) {
+		logger.Debug("Interval reminder in quiet hours", zap.Uint("id", id), zap.String("time", clock))
+		return
+	}
+
+	claimed, err := s.repo.ClaimSlot(id, local.Format("2006-01-02 15:04"))
+	if err != nil || !claimed {
+		return
+	}
+
+	msg := notify.Message{Body: intervalMessage(*reminder, local) + "\n\n发送 /interval 调整或关闭"}
+	if err := s.telegram.For(reminder.User.Bot).SendTo(reminder.User.ChatID, msg); err != nil {
+		logger.Warn("Failed to send interval reminder",
+			zap.Uint("id", id),
+			zap.Error(err))
+		return
+	}
+	logger.Debug("Interval reminder sent", zap.Uint("id", id), zap.String("kind", reminder.Kind))
+}
+
+// intervalMessage picks the text of a slot, rotating through the texts of the kind
+func intervalMessage(reminder model.IntervalReminder, at time.Time) string {
+	messages := intervalMessages[reminder.Kind]
+	if len(messages) == 0 {
+		messages = intervalMessages[IntervalKindWater]
+	}
+	slot := (at.Hour()*60 + at.Minute()) / reminder.IntervalMinutes
+	return messages[slot%len(messages)]
+}
+
+// IntervalSlots returns the times of day (HH:MM) of a reminder's slots
+func IntervalSlots(reminder model.IntervalReminder) ([]string, error) {
+	minutes, err := intervalSlotMinutes(reminder)
+	if err != nil {
+		return nil, err
+	}
+	slots := make([]string, 0, len(minutes))
+	for _, m := range minutes {
+		slots = append(slots, fmt.Sprintf("%02d:%02d", m/60, m%60))
+	}
+	return slots, nil
+}
+
+// IntervalCronSpecs returns cron specs firing at a reminder's slots: one entry per distinct
+// minute past the hour, e.g. "0 9,11,13,15,17 * * *" for every two hours from 09:00 to 18:00
+func IntervalCronSpecs(reminder model.IntervalReminder) ([]string, error) {
+	minutes, err := intervalSlotMinutes(reminder)
+	if err != nil {
+		return nil, err
+	}
+
+	hoursByMinute := make(map[int][]string)
+	for _, m := range minutes {
+		hoursByMinute[m%60] = append(hoursByMinute[m%60], strconv.Itoa(m/60))
+	}
+	pastHour := make([]int, 0, len(hoursByMinute))
+	for minute := range hoursByMinute {
+		pastHour = append(pastHour, minute)
+	}
+	sort.Ints(pastHour)
+
+	specs := make([]string, 0, len(pastHour))
+	for _, minute := range pastHour {
+		specs = append(specs, fmt.Sprintf("%d %s * * *", minute, strings.Join(hoursByMinute[minute], ",")))
+	}
+	return specs, nil
+}
+
+// intervalSlotMinutes validates a reminder and returns its slots as minutes since midnight
+func intervalSlotMinutes(reminder model.IntervalReminder) ([]int, error) {
+	if _, ok := intervalMessages[reminder.Kind]; !ok {
+		return nil, fmt.Errorf("unknown interval reminder kind %q", reminder.Kind)
+	}
+	interval := reminder.IntervalMinutes
+	if interval < intervalMinMinutes || interval > intervalMaxMinutes || interval%intervalStepMinutes != 0 {
+		return nil, fmt.Errorf("interval must be a multiple of %d minutes between %d and %d", intervalStepMinutes, intervalMinMinutes, intervalMaxMinutes)
+	}
+	start, err := clockMinutes(reminder.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	end, err := clockMinutes(reminder.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if end <= start {
+		return nil, fmt.Errorf("end time %s must be after start time %s", reminder.EndTime, reminder.StartTime)
+	}
+
+	var minutes []int
+	for m := start; m <= end; m += interval {
+		minutes = append(minutes, m)
+	}
+	return minutes, nil
+}
+
+// clockMinutes parses HH:MM into minutes since midnight
+func clockMinutes(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: %w", clock, err)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// InQuietHours reports whether a time of day (HH:MM) falls in quiet hours such as "12:00-13:30"
+// or "22:00-07:00" (crossing midnight); the end is exclusive and "" means no quiet hours
+func InQuietHours(quietHours, clock string) bool {
+	from, to, ok := strings.Cut(quietHours, "-")
+	if !ok {
+		return false
+	}
+	start, err1 := clockMinutes(from)
+	end, err2 := clockMinutes(to)
+	now, err3 := clockMinutes(clock)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return false
+	}
+	if start <= end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// JobRunRetention is how long job runs are kept
+const JobRunRetention = 30 * 24 * time.Hour
+
+// jobTimeout bounds a single job run
+const jobTimeout = 10 * time.Minute
+
+// Errors of requeuing job runs
+var (
+	ErrJobRunNotFound  = errors.New("job run not found")
+	ErrJobRunNotFailed = errors.New("only failed runs can be requeued")
+	ErrJobUnknown      = errors.New("job is not scheduled")
+	ErrJobRunning      = errors.New("job is already running")
+)
+
+// JobResult is what a job run processed
+type JobResult struct {
+	Items    int // Cities or records processed
+	Failures int // Items that failed
+}
+
+// JobFunc runs a job, limited to one city when city is not ""; an error fails the run
+type JobFunc func(ctx context.Context, city string) (JobResult, error)
+
+// JobService runs scheduled jobs and records each run in the job_runs table, so admins can see
+// when jobs last ran and requeue failed runs
+type JobService struct {
+	repo *repository.JobRunRepository
+
+	mu      sync.Mutex
+	jobs    map[string]JobFunc
+	running map[string]bool // Job types with a run in progress
+}
+
+// NewJobService creates a new JobService
+func NewJobService(repo *repository.JobRunRepository) *JobService {
+	return &JobService{repo: repo, jobs: make(map[string]JobFunc), running: make(map[string]bool)}
+}
+
+// Register makes a job type runnable, replacing an earlier registration of the type
+func (s *JobService) Register(jobType string, fn JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[jobType] = fn
+}
+
+// Registered reports whether a job type can be run
+func (s *JobService) Registered(jobType string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[jobType] != nil
+}
+
+// FailInterrupted marks runs left running by a previous process as failed, so they can be requeued
+func (s *JobService) FailInterrupted(now time.Time) {
+	n, err := s.repo.FailInterrupted(now)
+	if err != nil {
+		return
+	}
+	if n > 0 {
+		logger.Warn("Job runs interrupted by restart marked as failed", zap.Int64("count", n))
+	}
+}
+
+// Run runs a registered job and records the run. A run is skipped (returning ErrJobRunning) while
+// another run of the same type is in progress.
+func (s *JobService) Run(jobType, city string) (*model.JobRun, error) {
+	fn, run, err := s.start(jobType, city, nil)
+	if err != nil {
+		if errors.Is(err, ErrJobRunning) {
+			logger.Warn("Job still running, skipping this run", zap.String("type", jobType))
+		}
+		return nil, err
+	}
+	s.execute(fn, run)
+	return run, nil
+}
+
+// Requeue runs a failed run again in the background with the same scope, returning the new run
+func (s *JobService) Requeue(id uint) (*model.JobRun, error) {
+	failed, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if failed == nil {
+		return nil, ErrJobRunNotFound
+	}
+	if failed.Status != model.JobStatusFailed {
+		return nil, ErrJobRunNotFailed
+	}
+	fn, run, err := s.start(failed.Type, failed.City, &failed.ID)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Job run requeued",
+		zap.Uint("failed_run_id", failed.ID),
+		zap.Uint("run_id", run.ID),
+		zap.String("type", run.Type))
+	started := *run
+	go s.execute(fn, run)
+	return &started, nil
+}
+
+// start claims a job type and records a started run
+func (s *JobService) start(jobType, city string, requeuedFrom *uint) (JobFunc, *model.JobRun, error) {
+	s.mu.Lock()
+	fn := s.jobs[jobType]
+	if fn == nil {
+		s.mu.Unlock()
+		return nil, nil, ErrJobUnknown
+	}
+	if s.running[jobType] {
+		s.mu.Unlock()
+		return nil, nil, ErrJobRunning
+	}
+	s.running[jobType] = true
+	s.mu.Unlock()
+
+	run := &model.JobRun{Type: jobType, City: city, Status: model.JobStatusRunning, RequeuedFrom: requeuedFrom, StartedAt: time.Now()}
+	if err := s.repo.Create(run); err != nil {
+		s.release(jobType)
+		return nil, nil, err
+	}
+	return fn, run, nil
+}
+
+// execute runs a started run's job and records its outcome; a panicking job fails the run
+func (s *JobService) execute(fn JobFunc, run *model.JobRun) {
+	defer s.release(run.Type)
+
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	result, err := func() (result JobResult, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("Job panicked", zap.String("type", run.Type), zap.Any("panic", r), zap.Stack("stack"))
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return fn(ctx, run.City)
+	}()
+
+	finished := time.Now()
+	run.FinishedAt = &finished
+	run.Items = result.Items
+	run.Failures = result.Failures
+	run.Status = model.JobStatusSucceeded
+	if err != nil {
+		run.Status = model.JobStatusFailed
+		run.Error = truncateRunes(err.Error(), 500)
+		logger.Error("Job failed",
+			zap.Uint("run_id", run.ID),
+			zap.String("type", run.Type),
+			zap.Error(err))
+	} else {
+		logger.Debug("Job completed",
+			zap.Uint("run_id", run.ID),
+			zap.String("type", run.Type),
+			zap.Int("items", run.Items),
+			zap.Duration("duration", finished.Sub(run.StartedAt)))
+	}
+	_ = s.repo.Finish(run)
+}
+
+// release marks a job type as no longer running
+func (s *JobService) release(jobType string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, jobType)
+}
+
+// Latest returns the most recent run of each job type
+func (s *JobService) Latest() ([]model.JobRun, error) {
+	return s.repo.Latest()
+}
+
+// List returns runs matching the filter, newest first, along with the total count
+func (s *JobService) List(filter repository.JobRunFilter, offset, limit int) ([]model.JobRun, int64, error) {
+	return s.repo.List(filter, offset, limit)
+}
+
+// Cleanup removes runs older than JobRunRetention, returning how many were removed
+func (s *JobService) Cleanup(now time.Time) (int, error) {
+	n, err := s.repo.DeleteBefore(now.Add(-JobRunRetention))
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		logger.Info("Old job runs removed", zap.Int64("count", n))
+	}
+	return int(n), nil
+}
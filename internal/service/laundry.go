@@ -0,0 +1,285 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+)
+
+// Drying window and horizon of the laundry advisor
+const (
+	laundryHorizon    = 48 // Forecast hours considered
+	laundryDayStart   = 9  // First daytime hour clothes can be hung out
+	laundryDayEnd     = 17 // Last daytime hour of the drying window
+	laundryMinHours   = 3  // Daytime hours a day needs left to be rated
+	laundryDryPop     = 30 // Probability of precipitation below which an hour counts as dry
+	laundryGoodScore  = 75 // Score from which a day is rated 适合晾晒
+	laundryFairScore  = 50 // Score from which a day is rated 较适合
+	laundryStillWind  = 1  // Wind scale up to which clothes dry slowly
+	laundryBreezyWind = 5  // Wind scale from which light clothes may be blown away
+	laundryStrongWind = 6  // Wind scale from which hanging clothes outside is discouraged
+)
+
+// LaundryDay is the drying forecast of one day
+type LaundryDay struct {
+	Date      string // YYYY-MM-DD
+	Score     int    // 0-100, higher dries faster
+	Humidity  int    // Average daytime relative humidity
+	MaxPop    int    // Highest daytime probability of precipitation
+	RainHours int    // Daytime hours with precipitation
+	WindScale int    // Highest daytime wind scale
+	DryFrom   string // Start of the longest dry daytime window (HH:MM, "" = none)
+	DryTo     string // End of the longest dry daytime window
+}
+
+// LaundryForecast rates the days of the next 48 hours for drying laundry outdoors
+type LaundryForecast struct {
+	Days []LaundryDay
+	Best int // Index of the best day, -1 when no day suits drying
+}
+
+// BestDay returns the best day for drying, or nil when no day suits it
+func (f *LaundryForecast) BestDay() *LaundryDay {
+	if f.Best < 0 {
+		return nil
+	}
+	return &f.Days[f.Best]
+}
+
+// GetLaundryForecast rates the daytime hours of the next 48 hours of a location for drying
+// laundry, combining humidity, probability of precipitation and wind
+func (s *WeatherService) GetLaundryForecast(locationID string) (*LaundryForecast, error) {
+	hours, err := s.client.GetHourlyForecast72h(locationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hourly forecast: %w", err)
+	}
+	if len(hours) > laundryHorizon {
+		hours = hours[:laundryHorizon]
+	}
+	return rateLaundryDays(hours), nil
+}
+
+// GetLaundryReport generates the /laundry report: the drying rating of each day of the next
+// 48 hours with the best day highlighted
+func (s *WeatherService) GetLaundryReport(city string, now time.Time) (string, error) {
+	location, err := s.ResolveLocation(city, "")
+	if err != nil {
+		return "", err
+	}
+	forecast, err := s.GetLaundryForecast(location.ID)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("👕 %s 晾晒指数\n\n", LocationLabel(city, location)))
+	if len(forecast.Days) == 0 {
+		b.WriteString("未来 48 小时没有可晾晒的白天时段\n")
+		return b.String(), nil
+	}
+	for _, day := range forecast.Days {
+		emoji, rating := laundryRating(day.Score)
+		b.WriteString(fmt.Sprintf("%s %s · %s（%d 分）\n", emoji, laundryDayLabel(day.Date, now), rating, day.Score))
+		b.WriteString(fmt.Sprintf("   湿度 %d%%  降水概率 %d%%  风力 %d级\n", day.Humidity, day.MaxPop, day.WindScale))
+		if day.DryFrom != "" {
+			b.WriteString(fmt.Sprintf("   晾晒时段：%s-%s\n", day.DryFrom, day.DryTo))
+		}
+		if day.WindScale >= laundryBreezyWind {
+			b.WriteString("   ⚠️ 风力较大，注意用夹子固定衣物\n")
+		}
+	}
+	if best := forecast.BestDay(); best != nil {
+		b.WriteString(fmt.Sprintf("\n👉 最佳晾晒日：%s，%s-%s 晾出为宜\n", laundryDayLabel(best.Date, now), best.DryFrom, best.DryTo))
+	} else {
+		b.WriteString("\n👉 未来两天都不宜户外晾晒，建议室内晾干或使用烘干机\n")
+	}
+	b.WriteString("\n💡 根据未来 48 小时逐小时预报的湿度、降水概率和风力估算")
+	return b.String(), nil
+}
+
+// LaundryDigest returns the one-line drying advice of a daily reminder for a subscription that
+// enabled it, or "" when the subscription has not
+func (s *WeatherService) LaundryDigest(sub model.Subscription, now time.Time) (string, error) {
+	if !sub.Laundry {
+		return "", nil
+	}
+	location, err := s.client.GetLocation(sub.LocationQuery())
+	if err != nil {
+		return "", fmt.Errorf("failed to get location: %w", err)
+	}
+	forecast, err := s.GetLaundryForecast(location.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(forecast.Days) == 0 {
+		return "", nil
+	}
+
+	var ratings []string
+	for _, day := range forecast.Days {
+		_, rating := laundryRating(day.Score)
+		ratings = append(ratings, laundryDayLabel(day.Date, now)+rating)
+	}
+	line := "👕 晾晒：" + strings.Join(ratings, "，")
+	if best := forecast.BestDay(); best != nil {
+		line += fmt.Sprintf("\n👉 最佳：%s %s-%s", laundryDayLabel(best.Date, now), best.DryFrom, best.DryTo)
+	} else {
+		line += "\n👉 建议室内晾干或使用烘干机"
+	}
+	return line, nil
+}
+
+// rateLaundryDays groups the daytime hours by day and scores each day; days with fewer than
+// laundryMinHours daytime hours left are skipped
+func rateLaundryDays(hours []qweather.HourlyForecast) *LaundryForecast {
+	forecast := &LaundryForecast{Best: -1}
+	var daytime []qweather.HourlyForecast
+	flush := func() {
+		if len(daytime) >= laundryMinHours {
+			forecast.Days = append(forecast.Days, rateLaundryDay(daytime))
+		}
+		daytime = nil
+	}
+	for _, h := range hours {
+		t, err := time.Parse("2006-01-02T15:04Z07:00", h.FxTime)
+		if err != nil {
+			continue
+		}
+		if t.Hour() < laundryDayStart || t.Hour() > laundryDayEnd {
+			continue
+		}
+		if len(daytime) > 0 && daytime[0].FxTime[:10] != h.FxTime[:10] {
+			flush()
+		}
+		daytime = append(daytime, h)
+	}
+	flush()
+
+	for i, day := range forecast.Days {
+		if day.Score < laundryFairScore || day.DryFrom == "" {
+			continue
+		}
+		if forecast.Best < 0 || day.Score > forecast.Days[forecast.Best].Score {
+			forecast.Best = i
+		}
+	}
+	return forecast
+}
+
+// rateLaundryDay scores the daytime hours of one day for drying laundry
+func rateLaundryDay(hours []qweather.HourlyForecast) LaundryDay {
+	day := LaundryDay{Date: hours[0].FxTime[:10]}
+	humidity := 0
+	runStart, bestStart, bestLen := -1, -1, 0
+	for i, h := range hours {
+		humidity += atoiOrZero(h.Humidity)
+		pop := atoiOrZero(h.Pop)
+		if pop > day.MaxPop {
+			day.MaxPop = pop
+		}
+		wet := false
+		if precip, err := strconv.ParseFloat(h.Precip, 64); err == nil && precip > 0 {
+			day.RainHours++
+			wet = true
+		}
+		if wind := maxWindScale(h.WindScale); wind > day.WindScale {
+			day.WindScale = wind
+		}
+
+		// Track the longest run of dry hours
+		if wet || pop >= laundryDryPop {
+			runStart = -1
+			continue
+		}
+		if runStart < 0 {
+			runStart = i
+		}
+		if n := i - runStart + 1; n > bestLen {
+			bestStart, bestLen = runStart, n
+		}
+	}
+	day.Humidity = humidity / len(hours)
+	if bestStart >= 0 {
+		day.DryFrom = hourOf(hours[bestStart].FxTime)
+		day.DryTo = fmt.Sprintf("%02d:00", forecastHour(hours[bestStart+bestLen-1].FxTime)+1)
+	}
+
+	score := 100
+	// Rain costs drying time, and a short dry window leaves too little of it
+	score -= min(40, 10*day.RainHours)
+	switch {
+	case bestLen < laundryMinHours:
+		score -= 40
+	case bestLen < 6:
+		score -= 15
+	}
+	if day.RainHours == 0 && day.MaxPop >= laundryDryPop {
+		score -= 15 // Showers are possible though none is forecast
+	}
+	switch {
+	case day.Humidity >= 85:
+		score -= 35
+	case day.Humidity >= 70:
+		score -= 20
+	case day.Humidity >= 60:
+		score -= 10
+	}
+	switch {
+	case day.WindScale >= laundryStrongWind:
+		score -= 30
+	case day.WindScale >= laundryBreezyWind:
+		score -= 10
+	case day.WindScale <= laundryStillWind:
+		score -= 10
+	}
+	day.Score = max(0, min(100, score))
+	return day
+}
+
+// laundryRating returns the emoji and rating of a drying score
+func laundryRating(score int) (string, string) {
+	switch {
+	case score >= laundryGoodScore:
+		return "☀️", "适合晾晒"
+	case score >= laundryFairScore:
+		return "🌤", "较适合晾晒"
+	default:
+		return "🌧", "不宜晾晒"
+	}
+}
+
+// laundryDayLabel names a forecast date relative to now, e.g. "今天" or "6月3日"
+func laundryDayLabel(date string, now time.Time) string {
+	d, err := time.ParseInLocation("2006-01-02", date, now.Location())
+	if err != nil {
+		return date
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch int(d.Sub(today).Hours() / 24) {
+	case 0:
+		return "今天"
+	case 1:
+		return "明天"
+	case 2:
+		return "后天"
+	}
+	return fmt.Sprintf("%d月%d日", d.Month(), d.Day())
+}
+
+// forecastHour returns the hour of a QWeather forecast time, or 0 when unparseable
+func forecastHour(fxTime string) int {
+	if t, err := time.Parse("2006-01-02T15:04Z07:00", fxTime); err == nil {
+		return t.Hour()
+	}
+	return 0
+}
+
+// atoiOrZero parses an integer forecast value, treating empty or invalid values as 0
+func atoiOrZero(value string) int {
+	n, _ := strconv.Atoi(value)
+	return n
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+// locationCacheTTL is how long a resolved place name is reused before looking it up again
+const locationCacheTTL = 24 * time.Hour
+
+// LocationHintCity restricts a place lookup to cities and districts
+const LocationHintCity = "city"
+
+// locationHints maps the type hint written after a place name (e.g. "/weather 故宫 景点")
+// to the lookup it selects
+var locationHints = map[string]string{
+	"城市":     LocationHintCity,
+	"city":   LocationHintCity,
+	"景点":     qweather.POITypeScenic,
+	"景区":     qweather.POITypeScenic,
+	"scenic": qweather.POITypeScenic,
+	"潮汐":     qweather.POITypeTide,
+	"tide":   qweather.POITypeTide,
+	"海流":     qweather.POITypeCurrent,
+}
+
+// ParseLocationHint returns the lookup selected by a type hint, or false for an unknown hint
+func ParseLocationHint(hint string) (string, bool) {
+	kind, ok := locationHints[strings.ToLower(strings.TrimSpace(hint))]
+	return kind, ok
+}
+
+// locationEntry is a cached place lookup
+type locationEntry struct {
+	location  qweather.GeoLocation
+	expiresAt time.Time
+}
+
+// locationCache caches resolved place names by hint and name
+type locationCache struct {
+	mu      sync.Mutex
+	entries map[string]locationEntry
+}
+
+// get returns an unexpired cached location
+func (c *locationCache) get(key string) (*qweather.GeoLocation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	loc := entry.location
+	return &loc, true
+}
+
+// put caches a location
+func (c *locationCache) put(key string, loc *qweather.GeoLocation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]locationEntry)
+	}
+	c.entries[key] = locationEntry{location: *loc, expiresAt: time.Now().Add(locationCacheTTL)}
+}
+
+// ResolveLocation resolves a city name or a point of interest such as a scenic spot.
+// Without a hint, the city lookup is tried first and scenic spots second; LocationHintCity
+// restricts the lookup to cities and a POI type restricts it to that type.
+// Results are cached for locationCacheTTL.
+func (s *WeatherService) ResolveLocation(query, hint string) (*qweather.GeoLocation, error) {
+	key := hint + "|" + query
+	if loc, ok := s.locations.get(key); ok {
+		return loc, nil
+	}
+
+	var loc *qweather.GeoLocation
+	var err error
+	switch hint {
+	case "", LocationHintCity:
+		loc, err = s.client.GetLocation(query)
+		if err != nil && hint == "" {
+			var poiErr error
+			if loc, poiErr = s.client.GetPOI(query, qweather.POITypeScenic); poiErr == nil {
+				err = nil
+			}
+		}
+	default:
+		loc, err = s.client.GetPOI(query, hint)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve location %s: %w", query, err)
+	}
+
+	logger.Debug("Location resolved",
+		zap.String("query", query),
+		zap.String("hint", hint),
+		zap.String("name", loc.Name),
+		zap.String("type", loc.Type),
+		zap.String("location_id", loc.ID))
+	s.locations.put(key, loc)
+	return loc, nil
+}
+
+// LocationLabel returns the display name of a resolved location: the query for cities, and the
+// POI name with its district for points of interest (e.g. "故宫博物院（东城）")
+func LocationLabel(query string, loc *qweather.GeoLocation) string {
+	if !IsPOI(loc) {
+		return query
+	}
+	if loc.Adm2 != "" && loc.Adm2 != loc.Name {
+		return fmt.Sprintf("%s（%s）", loc.Name, loc.Adm2)
+	}
+	return loc.Name
+}
+
+// IsPOI reports whether a resolved location is a point of interest rather than a city or district
+func IsPOI(loc *qweather.GeoLocation) bool {
+	return loc.Type != "" && loc.Type != "city"
+}
+
+// maxCitySuggestions is the number of cities returned by SearchCities
+const maxCitySuggestions = 8
+
+const (
+	// maxFuzzyTrim is the most characters dropped from a keyword without matches
+	maxFuzzyTrim = 3
+	// minFuzzyKeywordRunes is the shortest prefix tried when a misspelled keyword has no match
+	minFuzzyKeywordRunes = 2
+)
+
+// SearchCities suggests cities for a keyword, which may be a partial Chinese name or pinyin
+// (e.g. "beij", "Bei Jing"). A keyword without matches is shortened by up to maxFuzzyTrim characters
+// so that a misspelled ending still yields suggestions. An empty keyword lists popular cities.
+func (s *WeatherService) SearchCities(keyword string) ([]qweather.GeoLocation, error) {
+	keyword = normalizeCityKeyword(keyword)
+	if keyword == "" {
+		return s.client.GetTopCities(maxCitySuggestions)
+	}
+
+	runes := []rune(keyword)
+	for trim := 0; trim <= maxFuzzyTrim; trim++ {
+		n := len(runes) - trim
+		if trim > 0 && n < minFuzzyKeywordRunes {
+			break
+		}
+		cities, err := s.client.SearchCities(string(runes[:n]), maxCitySuggestions)
+		if err != nil {
+			return nil, err
+		}
+		if len(cities) > 0 {
+			return dedupeCities(cities), nil
+		}
+	}
+	return nil, nil
+}
+
+// normalizeCityKeyword trims a keyword and folds pinyin input to lowercase without spaces or
+// apostrophes ("Xi'an" -> "xian"), the form the GeoAPI matches
+func normalizeCityKeyword(keyword string) string {
+	keyword = strings.TrimSpace(keyword)
+	for _, r := range keyword {
+		if r > unicode.MaxASCII {
+			return keyword
+		}
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "", "'", "", "-", "").Replace(keyword))
+}
+
+// dedupeCities drops repeated entries for the same city and province, keeping the ranking order
+func dedupeCities(cities []qweather.GeoLocation) []qweather.GeoLocation {
+	seen := make(map[string]bool, len(cities))
+	out := cities[:0]
+	for _, city := range cities {
+		key := city.Name + "|" + city.Adm1
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, city)
+	}
+	return out
+}
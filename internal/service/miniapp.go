@@ -0,0 +1,278 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// MiniAppInitDataTTL is how long the initData Telegram passes to the Mini App is accepted
+const MiniAppInitDataTTL = 24 * time.Hour
+
+// maxMiniAppTodoRunes caps todos added in the Mini App
+const maxMiniAppTodoRunes = 500
+
+// Errors of Mini App requests
+var (
+	ErrMiniAppUnauthorized = errors.New("invalid or expired init data")
+	ErrMiniAppNotFound     = errors.New("not found")
+	ErrMiniAppInvalid      = errors.New("invalid request")
+)
+
+// MiniAppService backs the Telegram Mini App: it authenticates users by the initData Telegram
+// signs with the bot token, and manages their todos (including their order), subscriptions and
+// settings
+type MiniAppService struct {
+	tokens   map[string]string // Bot name ("" = primary bot) → bot token
+	userRepo *repository.UserRepository
+	subRepo  *repository.SubscriptionRepository
+	todoRepo *repository.TodoRepository
+	todoSvc  *TodoService
+	url      string
+}
+
+// NewMiniAppService creates a new MiniAppService; tokens maps bot names to their tokens and url is
+// the HTTPS address of the Mini App
+func NewMiniAppService(tokens map[string]string, userRepo *repository.UserRepository, subRepo *repository.SubscriptionRepository, todoRepo *repository.TodoRepository, todoSvc *TodoService, url string) *MiniAppService {
+	return &MiniAppService{
+		tokens:   tokens,
+		userRepo: userRepo,
+		subRepo:  subRepo,
+		todoRepo: todoRepo,
+		todoSvc:  todoSvc,
+		url:      url,
+	}
+}
+
+// URL returns the HTTPS address of the Mini App
+func (s *MiniAppService) URL() string {
+	return s.url
+}
+
+// Authenticate validates Mini App initData and returns the user who opened the app. The user must
+// have started the bot whose token signed the data.
+func (s *MiniAppService) Authenticate(initData string, now time.Time) (*model.User, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil || values.Get("hash") == "" {
+		return nil, ErrMiniAppUnauthorized
+	}
+	bot, ok := s.verify(values)
+	if !ok {
+		return nil, ErrMiniAppUnauthorized
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil || now.Sub(time.Unix(authDate, 0)) > MiniAppInitDataTTL {
+		return nil, ErrMiniAppUnauthorized
+	}
+	var tgUser struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(values.Get("user")), &tgUser); err != nil || tgUser.ID == 0 {
+		return nil, ErrMiniAppUnauthorized
+	}
+
+	// Private chats have the user's ID
+	user, err := s.userRepo.FindByChatID(bot, tgUser.ID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrMiniAppUnauthorized
+	}
+	return user, nil
+}
+
+// verify checks the hash of initData against each bot token, returning the name of the bot that signed it
+func (s *MiniAppService) verify(values url.Values) (string, bool) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if k != "hash" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + values.Get(k)
+	}
+	dataCheck := []byte(strings.Join(pairs, "\n"))
+
+	hash, err := hex.DecodeString(values.Get("hash"))
+	if err != nil {
+		return "", false
+	}
+	for bot, token := range s.tokens {
+		secret := hmac.New(sha256.New, []byte("WebAppData"))
+		secret.Write([]byte(token))
+		mac := hmac.New(sha256.New, secret.Sum(nil))
+		mac.Write(dataCheck)
+		if hmac.Equal(mac.Sum(nil), hash) {
+			return bot, true
+		}
+	}
+	return "", false
+}
+
+// State returns a user's active subscriptions with their todos
+func (s *MiniAppService) State(user *model.User) ([]SubscriptionTodos, error) {
+	subs, err := s.subRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.todoSvc.SubscriptionLists(subs)
+}
+
+// subscription returns one of the user's active subscriptions
+func (s *MiniAppService) subscription(user *model.User, subID uint) (*model.Subscription, error) {
+	sub, err := s.subRepo.FindByID(subID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || sub.UserID != user.ID || !sub.Active {
+		return nil, ErrMiniAppNotFound
+	}
+	return sub, nil
+}
+
+// UpdateSubscription changes the reminder time and/or warning notifications of a subscription (nil = unchanged)
+func (s *MiniAppService) UpdateSubscription(user *model.User, subID uint, reminderTime *string, enableWarning *bool) (*model.Subscription, error) {
+	sub, err := s.subscription(user, subID)
+	if err != nil {
+		return nil, err
+	}
+	if reminderTime != nil {
+		if _, err := time.Parse("15:04", *reminderTime); err != nil || len(*reminderTime) != 5 {
+			return nil, fmt.Errorf("%w: reminder_time must be in HH:MM format", ErrMiniAppInvalid)
+		}
+		sub.ReminderTime = *reminderTime
+	}
+	if enableWarning != nil {
+		sub.EnableWarning = *enableWarning
+	}
+	if err := s.subRepo.Update(sub); err != nil {
+		return nil, err
+	}
+	logger.Info("Subscription updated in Mini App", zap.Uint("user_id", user.ID), zap.Uint("subscription_id", sub.ID))
+	return sub, nil
+}
+
+// Unsubscribe deletes one of the user's subscriptions
+func (s *MiniAppService) Unsubscribe(user *model.User, subID uint) error {
+	sub, err := s.subscription(user, subID)
+	if err != nil {
+		return err
+	}
+	if err := s.subRepo.Delete(sub.ID); err != nil {
+		return err
+	}
+	logger.Info("Unsubscribed in Mini App", zap.Uint("user_id", user.ID), zap.String("city", sub.City))
+	return nil
+}
+
+// AddTodo adds a todo to the list of one of the user's subscriptions
+func (s *MiniAppService) AddTodo(user *model.User, subID uint, content string) (*model.Todo, error) {
+	sub, err := s.subscription(user, subID)
+	if err != nil {
+		return nil, err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" || utf8.RuneCountInString(content) > maxMiniAppTodoRunes {
+		return nil, fmt.Errorf("%w: content must be 1 to %d characters", ErrMiniAppInvalid, maxMiniAppTodoRunes)
+	}
+	return s.todoSvc.AddTodo(sub.TodoListID(), content)
+}
+
+// todo returns a todo of a list the user owns or co-manages
+func (s *MiniAppService) todo(user *model.User, todoID uint) (*model.Todo, error) {
+	todo, err := s.todoRepo.FindByIDAndVerifyOwnership(todoID, user.ID)
+	if err != nil {
+		if err.Error() == "unauthorized" {
+			return nil, ErrMiniAppNotFound
+		}
+		return nil, err
+	}
+	if todo == nil {
+		return nil, ErrMiniAppNotFound
+	}
+	return todo, nil
+}
+
+// SetTodoCompleted marks a todo as completed or not completed
+func (s *MiniAppService) SetTodoCompleted(user *model.User, todoID uint, completed bool) (*model.Todo, error) {
+	todo, err := s.todo(user, todoID)
+	if err != nil {
+		return nil, err
+	}
+	if todo.Completed != completed {
+		todo.Completed = completed
+		todo.CompletedAt, todo.CompletedBy = nil, nil
+		if completed {
+			now := time.Now()
+			todo.CompletedAt = &now
+			todo.CompletedBy = &user.ID
+		}
+		if err := s.todoRepo.Update(todo); err != nil {
+			return nil, err
+		}
+	}
+	return todo, nil
+}
+
+// DeleteTodo deletes a todo
+func (s *MiniAppService) DeleteTodo(user *model.User, todoID uint) error {
+	if _, err := s.todo(user, todoID); err != nil {
+		return err
+	}
+	return s.todoRepo.Delete(todoID)
+}
+
+// ReorderTodos orders the todos of a subscription's list; ids must list each of its todos once
+func (s *MiniAppService) ReorderTodos(user *model.User, subID uint, ids []uint) error {
+	sub, err := s.subscription(user, subID)
+	if err != nil {
+		return err
+	}
+	listID := sub.TodoListID()
+	todos, err := s.todoSvc.GetSubscriptionTodos(listID)
+	if err != nil {
+		return err
+	}
+	if len(ids) != len(todos) {
+		return fmt.Errorf("%w: ids must list each todo once", ErrMiniAppInvalid)
+	}
+	pending := make(map[uint]bool, len(todos))
+	for _, t := range todos {
+		pending[t.ID] = true
+	}
+	for _, id := range ids {
+		if !pending[id] {
+			return fmt.Errorf("%w: ids must list each todo once", ErrMiniAppInvalid)
+		}
+		delete(pending, id)
+	}
+	return s.todoRepo.SetPositions(listID, ids)
+}
+
+// SetAnnouncements turns admin announcements on or off for the user
+func (s *MiniAppService) SetAnnouncements(user *model.User, on bool) error {
+	if err := s.userRepo.SetAnnouncementsOff(user.ID, !on); err != nil {
+		return err
+	}
+	user.AnnouncementsOff = !on
+	return nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"go.uber.org/zap"
+)
+
+// MQTT status payloads published to <prefix>/status (the offline payload is the broker-side last will)
+const (
+	mqttStatusOnline  = "online"
+	mqttStatusOffline = "offline"
+)
+
+// MQTTOptions holds MQTT broker and publishing settings
+type MQTTOptions struct {
+	Broker      string // e.g., tcp://127.0.0.1:1883, ssl://broker:8883
+	ClientID    string
+	Username    string
+	Password    string
+	TopicPrefix string
+	QoS         byte
+	Interval    time.Duration // Weather/AQI snapshot interval
+	Timeout     time.Duration // Connect/publish timeout
+}
+
+// WeatherSnapshot is the payload published to <prefix>/city/<city>/weather
+type WeatherSnapshot struct {
+	City      string    `json:"city"`
+	Temp      string    `json:"temp"`
+	FeelsLike string    `json:"feels_like"`
+	Text      string    `json:"text"`
+	Humidity  string    `json:"humidity"`
+	WindDir   string    `json:"wind_dir"`
+	WindScale string    `json:"wind_scale"`
+	WindSpeed string    `json:"wind_speed"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AirQualitySnapshot is the payload published to <prefix>/city/<city>/air
+type AirQualitySnapshot struct {
+	City             string    `json:"city"`
+	AQI              float64   `json:"aqi"`
+	Category         string    `json:"category"`
+	PrimaryPollutant string    `json:"primary_pollutant,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// MQTTService publishes weather snapshots, air quality and warning events to an MQTT broker
+// for smart home automations. Snapshots are retained so new subscribers see the latest state.
+type MQTTService struct {
+	client     mqtt.Client
+	opts       MQTTOptions
+	subRepo    *repository.SubscriptionRepository
+	weatherSvc *WeatherService
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewMQTTService creates a new MQTTService; call Start to connect and begin publishing
+func NewMQTTService(opts MQTTOptions, subRepo *repository.SubscriptionRepository, weatherSvc *WeatherService) *MQTTService {
+	s := &MQTTService{
+		opts:       opts,
+		subRepo:    subRepo,
+		weatherSvc: weatherSvc,
+		stop:       make(chan struct{}),
+	}
+
+	clientOpts := mqtt.NewClientOptions().
+		AddBroker(opts.Broker).
+		SetClientID(opts.ClientID).
+		SetUsername(opts.Username).
+		SetPassword(opts.Password).
+		SetConnectTimeout(opts.Timeout).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetWill(s.topic("status"), mqttStatusOffline, opts.QoS, true).
+		SetOnConnectHandler(func(c mqtt.Client) {
+			logger.Info("MQTT connected", zap.String("broker", opts.Broker))
+			c.Publish(s.topic("status"), opts.QoS, true, mqttStatusOnline)
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			logger.Warn("MQTT connection lost", zap.Error(err))
+		})
+	s.client = mqtt.NewClient(clientOpts)
+	return s
+}
+
+// Start connects to the broker in the background and begins periodic snapshot publishing
+func (s *MQTTService) Start() {
+	// With connect retry enabled the token only completes once connected, so don't block startup on it
+	token := s.client.Connect()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		if token.WaitTimeout(s.opts.Timeout) {
+			s.PublishSnapshots()
+		}
+
+		ticker := time.NewTicker(s.opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.PublishSnapshots()
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+
+	logger.Info("MQTT publisher started",
+		zap.String("broker", s.opts.Broker),
+		zap.String("topic_prefix", s.opts.TopicPrefix),
+		zap.Duration("interval", s.opts.Interval))
+}
+
+// Stop stops snapshot publishing and disconnects, marking the bot offline
+func (s *MQTTService) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+	if s.client.IsConnected() {
+		s.publish(s.topic("status"), true, []byte(mqttStatusOffline))
+	}
+	s.client.Disconnect(250)
+	logger.Info("MQTT publisher stopped")
+}
+
+// PublishSnapshots publishes weather and air quality for every city with an active subscription
+func (s *MQTTService) PublishSnapshots() {
+	if !s.client.IsConnected() {
+		logger.Debug("MQTT not connected, skipping snapshots")
+		return
+	}
+
+	subs, err := s.subRepo.GetAllActive()
+	if err != nil {
+		logger.Warn("Failed to get subscriptions for MQTT snapshots", zap.Error(err))
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, sub := range subs {
+		if seen[sub.City] {
+			continue
+		}
+		seen[sub.City] = true
+		s.publishCitySnapshot(sub.City)
+	}
+}
+
+// PublishWarning publishes a warning event to <prefix>/city/<city>/warning (not retained)
+func (s *MQTTService) PublishWarning(event WarningEvent) {
+	s.publishJSON(s.cityTopic(event.City, "warning"), false, event)
+}
+
+// publishCitySnapshot fetches and publishes the current weather and air quality of a city
+func (s *MQTTService) publishCitySnapshot(city string) {
+	client := s.weatherSvc.Client()
+	location, err := client.GetLocation(city)
+	if err != nil {
+		logger.Warn("Failed to get location for MQTT snapshot", zap.String("city", city), zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	if weather, err := client.GetCurrentWeather(location.ID); err != nil {
+		logger.Warn("Failed to get weather for MQTT snapshot", zap.String("city", city), zap.Error(err))
+	} else {
+		s.publishJSON(s.cityTopic(city, "weather"), true, WeatherSnapshot{
+			City:      city,
+			Temp:      weather.Temp,
+			FeelsLike: weather.FeelsLike,
+			Text:      weather.Text,
+			Humidity:  weather.Humidity,
+			WindDir:   weather.WindDir,
+			WindScale: weather.WindScale,
+			WindSpeed: weather.WindSpeed,
+			UpdatedAt: now,
+		})
+	}
+
+	if air, err := client.GetAirQualityCurrent(location.Lat, location.Lon); err != nil {
+		logger.Warn("Failed to get air quality for MQTT snapshot", zap.String("city", city), zap.Error(err))
+	} else if index, ok := primaryAQIIndex(air); ok {
+		s.publishJSON(s.cityTopic(city, "air"), true, AirQualitySnapshot{
+			City:             city,
+			AQI:              index.Aqi,
+			Category:         index.Category,
+			PrimaryPollutant: index.PrimaryPollutant.Name,
+			UpdatedAt:        now,
+		})
+	}
+}
+
+// publishJSON encodes and publishes a payload
+func (s *MQTTService) publishJSON(topic string, retained bool, v interface{}) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		logger.Warn("Failed to encode MQTT payload", zap.String("topic", topic), zap.Error(err))
+		return
+	}
+	s.publish(topic, retained, payload)
+}
+
+// publish sends a message and waits up to the configured timeout for the broker acknowledgement
+func (s *MQTTService) publish(topic string, retained bool, payload []byte) {
+	token := s.client.Publish(topic, s.opts.QoS, retained, payload)
+	if !token.WaitTimeout(s.opts.Timeout) {
+		logger.Warn("MQTT publish timed out", zap.String("topic", topic))
+		return
+	}
+	if err := token.Error(); err != nil {
+		logger.Warn("MQTT publish failed", zap.String("topic", topic), zap.Error(err))
+		return
+	}
+	logger.Debug("MQTT message published", zap.String("topic", topic), zap.Int("bytes", len(payload)))
+}
+
+// topic joins the prefix and a suffix
+func (s *MQTTService) topic(suffix string) string {
+	return s.opts.TopicPrefix + "/" + suffix
+}
+
+// cityTopic returns <prefix>/city/<city>/<kind>
+func (s *MQTTService) cityTopic(city, kind string) string {
+	return s.topic(fmt.Sprintf("city/%s/%s", sanitizeTopicLevel(city), kind))
+}
+
+// sanitizeTopicLevel replaces characters that would split or wildcard a topic level
+func sanitizeTopicLevel(level string) string {
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(level)
+}
+
+// primaryAQIIndex picks the China AQI index when available, otherwise the first index
+func primaryAQIIndex(air *qweather.AirQualityResponse) (qweather.AirQualityIndex, bool) {
+	if air == nil || len(air.Indexes) == 0 {
+		return qweather.AirQualityIndex{}, false
+	}
+	for _, idx := range air.Indexes {
+		if idx.Code == "qaqi" {
+			return idx, true
+		}
+	}
+	return air.Indexes[0], true
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	tele "gopkg.in/telebot.v3"
+)
+
+// BroadcastTarget is an operator-configured channel (e.g., a team's WeChat Work robot)
+// that receives city digests and warnings independently of any subscription
+type BroadcastTarget struct {
+	Channel string
+	Target  string
+	Events  []string // reminder/warning (empty = all)
+	Cities  []string // Empty means all cities
+}
+
+// ErrPinNotAllowed is returned when the bot lacks the right to pin messages in a chat
+var ErrPinNotAllowed = errors.New("bot is not allowed to pin messages in the chat")
+
+// NotificationService delivers messages to a subscription's Telegram chat and its additional channels
+type NotificationService struct {
+	telegram    *notify.TelegramNotifier
+	router      *notify.Router
+	channelRepo *repository.NotificationChannelRepository
+	broadcasts  []BroadcastTarget
+	voice       *VoiceService
+	lanes       *notify.Lanes // Bounds concurrent deliveries, warnings first; nil = unbounded
+
+	mu            sync.Mutex
+	digestsSentOn map[string]string // city -> date of the last broadcast digest
+}
+
+// NewNotificationService creates a new NotificationService.
+// The router holds the additional channels (email/ntfy/Bark/WeCom/DingTalk) enabled by the operator.
+// voice is nil when voice reminders are disabled.
+func NewNotificationService(
+	telegram *notify.TelegramNotifier,
+	router *notify.Router,
+	channelRepo *repository.NotificationChannelRepository,
+	broadcasts []BroadcastTarget,
+	voice *VoiceService,
+) *NotificationService {
+	if router == nil {
+		router = notify.NewRouter()
+	}
+	return &NotificationService{
+		telegram:      telegram,
+		router:        router,
+		channelRepo:   channelRepo,
+		broadcasts:    broadcasts,
+		voice:         voice,
+		digestsSentOn: make(map[string]string),
+	}
+}
+
+// Router returns the router of additional channels
+func (s *NotificationService) Router() *notify.Router {
+	return s.router
+}
+
+// SetConcurrency bounds how many deliveries are sent at once. Waiting high-priority messages
+// (warnings) take free slots before normal ones (daily reminders).
+func (s *NotificationService) SetConcurrency(slots int) {
+	s.lanes = notify.NewLanes(slots)
+}
+
+// VoiceEnabled reports whether users can receive reminders as voice messages
+func (s *NotificationService) VoiceEnabled() bool {
+	return s.voice != nil
+}
+
+// Deliver sends a message to the subscription's Telegram chat, then to each additional channel.
+// Additional channels are attempted even when Telegram fails; only the Telegram error is returned.
+func (s *NotificationService) Deliver(ctx context.Context, sub model.Subscription, msg notify.Message) error {
+	release, err := s.acquire(ctx, msg.Priority)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.deliver(ctx, sub, msg, s.telegram.For(sub.User.Bot).SendTo(sub.User.ChatID, msg))
+}
+
+// DeliverReminder is like Deliver, but sends the message's photo first and reads the message
+// aloud in Telegram according to the user's voice mode. A voice-only reminder falls back to
+// text when synthesis fails. Returns the ID of the Telegram text message (0 when only a voice
+// message was sent), so that reactions to the reminder can be traced back to it.
+func (s *NotificationService) DeliverReminder(ctx context.Context, sub model.Subscription, msg notify.Message) (int, error) {
+	release, err := s.acquire(ctx, msg.Priority)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+
+	if len(msg.Photo) > 0 {
+		if err := s.telegram.For(sub.User.Bot).SendPhoto(sub.User.ChatID, msg.Photo); err != nil {
+			logger.Warn("Failed to send reminder photo",
+				zap.Uint("subscription_id", sub.ID),
+				zap.Error(err))
+		}
+	}
+
+	var sent *tele.Message
+	var telegramErr error
+	mode := sub.User.VoiceMode
+	switch {
+	case s.voice == nil || mode == "" || mode == model.VoiceModeOff:
+		sent, telegramErr = s.telegram.For(sub.User.Bot).SendMessage(sub.User.ChatID, msg)
+	case mode == model.VoiceModeOnly:
+		if err := s.voice.Send(ctx, sub.User.Bot, sub.User.ChatID, msg); err != nil {
+			logger.Warn("Failed to send voice reminder, falling back to text",
+				zap.Uint("subscription_id", sub.ID),
+				zap.Error(err))
+			sent, telegramErr = s.telegram.For(sub.User.Bot).SendMessage(sub.User.ChatID, msg)
+		}
+	default:
+		sent, telegramErr = s.telegram.For(sub.User.Bot).SendMessage(sub.User.ChatID, msg)
+		if telegramErr == nil {
+			if err := s.voice.Send(ctx, sub.User.Bot, sub.User.ChatID, msg); err != nil {
+				logger.Warn("Failed to send voice reminder",
+					zap.Uint("subscription_id", sub.ID),
+					zap.Error(err))
+			}
+		}
+	}
+
+	messageID := 0
+	if sent != nil {
+		messageID = sent.ID
+	}
+	return messageID, s.deliver(ctx, sub, msg, telegramErr)
+}
+
+// Notice sends a service notice to the subscription's Telegram chat only
+func (s *NotificationService) Notice(sub model.Subscription, text string) error {
+	return s.telegram.For(sub.User.Bot).SendTo(sub.User.ChatID, notify.Message{Body: text})
+}
+
+// NotifyUser sends a service notice to a user's Telegram chat through the bot the user talks to
+func (s *NotificationService) NotifyUser(user model.User, text string) error {
+	return s.telegram.For(user.Bot).SendTo(user.ChatID, notify.Message{Body: text})
+}
+
+// SendDocument sends a generated file to a chat through the named bot, using the shared directory
+// of a local Bot API server when configured
+func (s *NotificationService) SendDocument(bot string, to tele.Recipient, doc notify.Document) error {
+	_, err := s.telegram.For(bot).SendDocument(to, doc)
+	return err
+}
+
+// CanPinReminder reports whether the bot may pin messages in the subscription's Telegram chat
+func (s *NotificationService) CanPinReminder(sub model.Subscription) (bool, error) {
+	return s.telegram.For(sub.User.Bot).CanPin(sub.User.ChatID)
+}
+
+// PinReminder pins a reminder message in the subscription's Telegram chat and unpins the
+// previously pinned reminder. Returns ErrPinNotAllowed when the bot lacks the right to pin.
+func (s *NotificationService) PinReminder(sub model.Subscription, messageID int) error {
+	chatID := sub.User.ChatID
+	allowed, err := s.CanPinReminder(sub)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrPinNotAllowed
+	}
+	if err := s.telegram.For(sub.User.Bot).Pin(chatID, messageID); err != nil {
+		return err
+	}
+	if sub.PinnedMessageID != 0 && sub.PinnedMessageID != messageID {
+		// The previous reminder may have been unpinned or deleted by the user already
+		if err := s.telegram.For(sub.User.Bot).Unpin(chatID, sub.PinnedMessageID); err != nil {
+			logger.Debug("Failed to unpin previous reminder",
+				zap.Uint("subscription_id", sub.ID),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// UnpinReminder unpins the currently pinned reminder of a subscription, if any
+func (s *NotificationService) UnpinReminder(sub model.Subscription) error {
+	if sub.PinnedMessageID == 0 {
+		return nil
+	}
+	return s.telegram.For(sub.User.Bot).Unpin(sub.User.ChatID, sub.PinnedMessageID)
+}
+
+// acquire waits for a delivery slot in the lane of the priority, returning the function releasing it
+func (s *NotificationService) acquire(ctx context.Context, priority notify.Priority) (func(), error) {
+	if s.lanes == nil {
+		return func() {}, nil
+	}
+	release, err := s.lanes.Acquire(ctx, priority)
+	if err != nil {
+		high, normal := s.lanes.Waiting()
+		logger.Warn("Gave up waiting for a delivery slot",
+			zap.Int("priority", int(priority)),
+			zap.Int("waiting_high", high),
+			zap.Int("waiting_normal", normal),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to wait for a delivery slot: %w", err)
+	}
+	return release, nil
+}
+
+// deliver sends a message to the subscription's additional channels after Telegram was attempted
+func (s *NotificationService) deliver(ctx context.Context, sub model.Subscription, msg notify.Message, telegramErr error) error {
+	if s.channelRepo == nil {
+		return telegramErr
+	}
+	channels, err := s.channelRepo.FindBySubscriptionID(sub.ID)
+	if err != nil {
+		logger.Warn("Failed to load notification channels",
+			zap.Uint("subscription_id", sub.ID),
+			zap.Error(err))
+		return telegramErr
+	}
+	// Buttons act on the subscriber's own chat, so extra channels get the plain message
+	msg.Markup = nil
+	s.sendToChannels(ctx, sub.ID, channels, msg)
+	return telegramErr
+}
+
+// sendToChannels sends a message to the given channels of a subscription
+func (s *NotificationService) sendToChannels(ctx context.Context, subscriptionID uint, channels []model.NotificationChannel, msg notify.Message) {
+	for _, ch := range channels {
+		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err := s.router.Send(sendCtx, ch.Type, ch.Target, msg)
+		cancel()
+		if err != nil {
+			logger.Warn("Failed to deliver to notification channel",
+				zap.Uint("subscription_id", subscriptionID),
+				zap.Uint("channel_id", ch.ID),
+				zap.String("type", ch.Type),
+				zap.Error(err))
+			continue
+		}
+		logger.Debug("Delivered to notification channel",
+			zap.Uint("subscription_id", subscriptionID),
+			zap.Uint("channel_id", ch.ID),
+			zap.String("type", ch.Type))
+	}
+}
+
+// WantsBroadcast reports whether any broadcast target subscribes to the event for the city
+func (s *NotificationService) WantsBroadcast(event, city string) bool {
+	for _, b := range s.broadcasts {
+		if b.matches(event, city) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClaimDigest reports whether the city's digest for the date has not been broadcast yet,
+// marking it as broadcast. Subscriptions share a city, so only the first reminder of the day claims it.
+func (s *NotificationService) ClaimDigest(city, date string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.digestsSentOn[city] == date {
+		return false
+	}
+	s.digestsSentOn[city] = date
+	return true
+}
+
+// Broadcast sends a message to every broadcast target subscribed to the event for the city
+func (s *NotificationService) Broadcast(ctx context.Context, event, city string, msg notify.Message) {
+	for _, b := range s.broadcasts {
+		if !b.matches(event, city) {
+			continue
+		}
+		release, err := s.acquire(ctx, msg.Priority)
+		if err != nil {
+			logger.Warn("Gave up waiting to broadcast notification",
+				zap.String("event", event),
+				zap.String("city", city),
+				zap.Error(err))
+			return
+		}
+		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		err = s.router.Send(sendCtx, b.Channel, b.Target, msg)
+		cancel()
+		release()
+		if err != nil {
+			logger.Warn("Failed to broadcast notification",
+				zap.String("event", event),
+				zap.String("city", city),
+				zap.String("channel", b.Channel),
+				zap.Error(err))
+			continue
+		}
+		logger.Debug("Broadcast notification sent",
+			zap.String("event", event),
+			zap.String("city", city),
+			zap.String("channel", b.Channel))
+	}
+}
+
+// matches reports whether the target subscribes to the event for the city
+func (b BroadcastTarget) matches(event, city string) bool {
+	if !subscribesTo(b.Events, event) {
+		return false
+	}
+	if len(b.Cities) == 0 {
+		return true
+	}
+	for _, c := range b.Cities {
+		if c == city {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// sectionObservations is the name of the crowd observations digest section
+const sectionObservations = "observations"
+
+// Limits of observation submissions
+const (
+	observationCooldown  = 10 * time.Minute // Between two submissions of a user
+	maxObservationRunes  = 100              // Description length
+	observationRetention = 7 * 24 * time.Hour
+)
+
+// Errors of observation submissions
+var (
+	ErrObservationBanned   = errors.New("user is banned from submitting observations")
+	ErrObservationCooldown = errors.New("observation submitted too recently")
+	ErrObservationLimit    = errors.New("daily observation limit reached")
+	ErrObservationText     = errors.New("observation text contains a link")
+)
+
+// ObservationKind describes an observed phenomenon
+type ObservationKind struct {
+	Kind    string
+	Emoji   string
+	Label   string   // Button label, e.g. "下雪"
+	Phrase  string   // Completes "N 位北京用户报告…", e.g. "正在下雪"
+	Aliases []string // Words accepted for the phenomenon
+}
+
+// ObservationKinds lists the phenomena users can report, in button order
+var ObservationKinds = []ObservationKind{
+	{model.ObservationKindRain, "🌧️", "下雨", "正在下雨", []string{"雨", "下雨", "下雨了"}},
+	{model.ObservationKindSnow, "❄️", "下雪", "正在下雪", []string{"雪", "下雪", "下雪了"}},
+	{model.ObservationKindHail, "🧊", "冰雹", "遇到冰雹", []string{"冰雹", "下冰雹"}},
+	{model.ObservationKindThunder, "⛈️", "打雷", "听到雷声", []string{"雷", "打雷", "雷暴", "雷雨"}},
+	{model.ObservationKindFog, "🌫️", "大雾", "遇到大雾", []string{"雾", "大雾", "起雾"}},
+	{model.ObservationKindHaze, "😷", "雾霾", "感到雾霾较重", []string{"霾", "雾霾"}},
+	{model.ObservationKindDust, "🏜️", "沙尘", "遇到沙尘", []string{"沙尘", "扬沙", "沙尘暴", "浮尘"}},
+	{model.ObservationKindWind, "💨", "大风", "正在刮大风", []string{"风", "大风", "刮风"}},
+	{model.ObservationKindSunny, "☀️", "晴好", "天气晴好", []string{"晴", "晴天", "晴好", "出太阳"}},
+}
+
+// FindObservationKind returns the phenomenon with a kind or one of its aliases, or nil
+func FindObservationKind(word string) *ObservationKind {
+	for i := range ObservationKinds {
+		k := &ObservationKinds[i]
+		if word == k.Kind || word == k.Label {
+			return k
+		}
+		for _, alias := range k.Aliases {
+			if word == alias {
+				return k
+			}
+		}
+	}
+	return nil
+}
+
+// ObservationService stores the users' weather observations ("实况打卡") and shows them as crowd
+// observations, after the weather, in the reminders of the same city once enough distinct users
+// report the same phenomenon within the window. Submissions are rate limited per user; support
+// staff can hide observations and ban users.
+type ObservationService struct {
+	repo         *repository.ObservationRepository
+	userRepo     *repository.UserRepository
+	minReporters int           // Distinct users needed before a phenomenon is shown
+	window       time.Duration // How long an observation counts
+	maxPerDay    int           // Submissions per user and day
+}
+
+// observationsContent is the fetched crowd observations section of a reminder
+type observationsContent struct {
+	city   string
+	counts map[string]int
+}
+
+// NewObservationService creates a new ObservationService
+func NewObservationService(repo *repository.ObservationRepository, userRepo *repository.UserRepository, minReporters int, window time.Duration, maxPerDay int) *ObservationService {
+	return &ObservationService{
+		repo:         repo,
+		userRepo:     userRepo,
+		minReporters: minReporters,
+		window:       window,
+		maxPerDay:    maxPerDay,
+	}
+}
+
+// Submit records a user's observation of a city. The description is sanitized; descriptions with
+// links, banned users and users over the rate limits are rejected.
+func (s *ObservationService) Submit(user *model.User, city, kind, text, photoFileID string, now time.Time) (*model.Observation, error) {
+	if user.ObservationsBan {
+		return nil, ErrObservationBanned
+	}
+	text = sanitizeUserText(text, maxObservationRunes)
+	if containsLink(text) {
+		return nil, ErrObservationText
+	}
+
+	latest, err := s.repo.FindLatestByUser(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && now.Sub(latest.CreatedAt) < observationCooldown {
+		return nil, ErrObservationCooldown
+	}
+	count, err := s.repo.CountByUserSince(user.ID, now.Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if count >= int64(s.maxPerDay) {
+		return nil, ErrObservationLimit
+	}
+
+	obs := &model.Observation{
+		UserID:      user.ID,
+		City:        city,
+		Kind:        kind,
+		Text:        text,
+		PhotoFileID: photoFileID,
+		CreatedAt:   now,
+	}
+	if err := s.repo.Create(obs); err != nil {
+		return nil, err
+	}
+	logger.Info("Observation submitted",
+		zap.Uint("user_id", user.ID),
+		zap.String("city", city),
+		zap.String("kind", kind),
+		zap.Bool("photo", photoFileID != ""))
+	return obs, nil
+}
+
+// MaxPerDay returns how many observations a user may submit a day
+func (s *ObservationService) MaxPerDay() int {
+	return s.maxPerDay
+}
+
+// Reporters returns how many distinct users reported each phenomenon in a city within the window
+func (s *ObservationService) Reporters(city string, now time.Time) (map[string]int, error) {
+	return s.repo.CountReporters(city, now.Add(-s.window))
+}
+
+// Recent returns the latest observations of a city ("" = all cities) for moderation
+func (s *ObservationService) Recent(city string, limit int) ([]model.Observation, error) {
+	return s.repo.FindRecent(city, limit)
+}
+
+// Find returns an observation for moderation
+func (s *ObservationService) Find(id uint) (*model.Observation, error) {
+	return s.repo.FindByID(id)
+}
+
+// SetHidden hides or shows an observation. Returns false when it does not exist.
+func (s *ObservationService) SetHidden(id uint, hidden bool) (bool, error) {
+	return s.repo.SetHidden(id, hidden)
+}
+
+// BanAuthor bans the author of an observation and hides all their observations
+func (s *ObservationService) BanAuthor(id uint) (*model.User, error) {
+	obs, err := s.repo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetObservationsBan(obs.UserID, true); err != nil {
+		return nil, err
+	}
+	hidden, err := s.repo.HideByUser(obs.UserID)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("User banned from observations",
+		zap.Uint("user_id", obs.UserID),
+		zap.Int64("observations_hidden", hidden))
+	return &obs.User, nil
+}
+
+// Unban lifts a user's ban; their hidden observations stay hidden
+func (s *ObservationService) Unban(userID uint) error {
+	return s.userRepo.SetObservationsBan(userID, false)
+}
+
+// Cleanup deletes the observations past the retention period
+func (s *ObservationService) Cleanup(now time.Time) {
+	deleted, err := s.repo.DeleteBefore(now.Add(-observationRetention))
+	if err != nil {
+		logger.Warn("Failed to clean up observations", zap.Error(err))
+		return
+	}
+	logger.Info("Observations cleaned up", zap.Int64("deleted", deleted))
+}
+
+// Name implements DigestSection
+func (s *ObservationService) Name() string {
+	return sectionObservations
+}
+
+// After implements SectionAnchor: crowd observations follow the weather
+func (s *ObservationService) After() string {
+	return sectionWeather
+}
+
+// Fetch implements DigestSection: it returns the phenomena reported by enough users of the
+// subscription's city, or nil when there are none
+func (s *ObservationService) Fetch(_ context.Context, target *SectionTarget) (SectionContent, error) {
+	counts, err := s.Reporters(target.Sub.City, target.Now)
+	if err != nil {
+		return nil, err
+	}
+	for kind, n := range counts {
+		if n < s.minReporters {
+			delete(counts, kind)
+		}
+	}
+	if len(counts) == 0 {
+		return nil, nil
+	}
+	return &observationsContent{city: target.Sub.City, counts: counts}, nil
+}
+
+// Render writes a line per reported phenomenon, in ObservationKinds order
+func (c *observationsContent) Render(string) string {
+	var section strings.Builder
+	section.WriteString("👥 网友实况：\n")
+	for _, k := range ObservationKinds {
+		if n := c.counts[k.Kind]; n > 0 {
+			section.WriteString(fmt.Sprintf("%s %d 位%s用户报告%s\n", k.Emoji, n, c.city, k.Phrase))
+		}
+	}
+	return section.String() + "\n"
+}
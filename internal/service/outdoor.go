@@ -0,0 +1,273 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+const (
+	// outdoorHourlyStep is the interval between hours listed in the mountain report
+	outdoorHourlyStep = 3
+	// outdoorHourlyHours is how far ahead the mountain report lists hours
+	outdoorHourlyHours = 12
+	// roughWindScale is the wind scale from which hiking and sailing are discouraged
+	roughWindScale = 6
+	// breezyWindScale is the wind scale from which small boats should take care
+	breezyWindScale = 4
+)
+
+// mountainForecast is the forecast of a mountain or scenic area
+type mountainForecast struct {
+	location *qweather.GeoLocation
+	today    *qweather.DailyForecast
+	hourly   []qweather.HourlyForecast
+}
+
+// seaForecast is the tide table and sea-level weather of a tide station
+type seaForecast struct {
+	location *qweather.GeoLocation
+	tide     *qweather.TideResponse
+	today    *qweather.DailyForecast // nil when the station's weather is unavailable
+}
+
+// GetMountainReport generates a hiking forecast for a mountain or scenic area
+func (s *WeatherService) GetMountainReport(place string) (string, error) {
+	forecast, err := s.fetchMountain(place)
+	if err != nil {
+		return "", err
+	}
+	today := forecast.today
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("⛰️ %s 登山天气\n\n", LocationLabel(place, forecast.location)))
+	b.WriteString(fmt.Sprintf("☁️ 白天：%s　夜间：%s\n", today.TextDay, today.TextNight))
+	b.WriteString(fmt.Sprintf("🌡️ 温度：%s°C ~ %s°C\n", today.TempMin, today.TempMax))
+	b.WriteString(fmt.Sprintf("🌬️ 风力：白天 %s %s级　夜间 %s %s级\n", today.WindDirDay, today.WindScaleDay, today.WindDirNight, today.WindScaleNight))
+	b.WriteString(fmt.Sprintf("💧 湿度：%s%%　👁 能见度：%s km\n", today.Humidity, today.Vis))
+	if today.UvIndex != "" {
+		b.WriteString(fmt.Sprintf("☀️ 紫外线指数：%s\n", today.UvIndex))
+	}
+	b.WriteString(fmt.Sprintf("🌅 日出 %s　日落 %s\n", today.Sunrise, today.Sunset))
+
+	if len(forecast.hourly) > 0 {
+		b.WriteString(fmt.Sprintf("\n⏱ 未来 %d 小时：\n", outdoorHourlyHours))
+		for i := 0; i < len(forecast.hourly) && i < outdoorHourlyHours; i += outdoorHourlyStep {
+			h := forecast.hourly[i]
+			b.WriteString(fmt.Sprintf("%s %s %s°C %s%s级", hourOf(h.FxTime), h.Text, h.Temp, h.WindDir, h.WindScale))
+			if h.Pop != "" {
+				b.WriteString(fmt.Sprintf(" 降水%s%%", h.Pop))
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n" + formatOutdoorAdvice(hikingAdvice(today), "✅ 天气条件适宜登山"))
+	return b.String(), nil
+}
+
+// GetSeaReport generates the tide table and sailing forecast of a tide station
+func (s *WeatherService) GetSeaReport(place string, now time.Time) (string, error) {
+	forecast, err := s.fetchSea(place, now)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🌊 %s 海况与潮汐\n\n", LocationLabel(place, forecast.location)))
+	if len(forecast.tide.TideTable) > 0 {
+		b.WriteString("📈 今日潮汐：\n")
+		for _, t := range forecast.tide.TideTable {
+			b.WriteString(fmt.Sprintf("%s %s %sm\n", tideLabel(t.Type), hourOf(t.FxTime), t.Height))
+		}
+	} else {
+		b.WriteString("📈 今日暂无潮汐数据\n")
+	}
+
+	if today := forecast.today; today != nil {
+		b.WriteString(fmt.Sprintf("\n☁️ 天气：白天 %s　夜间 %s\n", today.TextDay, today.TextNight))
+		b.WriteString(fmt.Sprintf("🌬️ 海面风力：白天 %s %s级　夜间 %s %s级\n", today.WindDirDay, today.WindScaleDay, today.WindDirNight, today.WindScaleNight))
+		b.WriteString(fmt.Sprintf("👁 能见度：%s km\n", today.Vis))
+		b.WriteString("\n" + formatOutdoorAdvice(sailingAdvice(today), "✅ 风浪较小，适宜出海"))
+	}
+	return b.String(), nil
+}
+
+// OutdoorDigest returns the short outdoor section of a daily reminder for a subscription flagged
+// for hiking or sailing, or "" when the subscription has no outdoor place
+func (s *WeatherService) OutdoorDigest(sub model.Subscription, now time.Time) (string, error) {
+	switch sub.OutdoorKind {
+	case model.OutdoorMountain:
+		forecast, err := s.fetchMountain(sub.OutdoorPlace)
+		if err != nil {
+			return "", err
+		}
+		today := forecast.today
+		advice := hikingAdvice(today)
+		return fmt.Sprintf("⛰️ 登山 · %s：%s，%s°C ~ %s°C，风力%s级\n%s",
+			LocationLabel(sub.OutdoorPlace, forecast.location), dayText(today), today.TempMin, today.TempMax,
+			today.WindScaleDay, formatOutdoorAdvice(advice, "✅ 适宜登山")), nil
+	case model.OutdoorSea:
+		forecast, err := s.fetchSea(sub.OutdoorPlace, now)
+		if err != nil {
+			return "", err
+		}
+		var high, low []string
+		for _, t := range forecast.tide.TideTable {
+			if t.Type == "H" {
+				high = append(high, hourOf(t.FxTime))
+			} else {
+				low = append(low, hourOf(t.FxTime))
+			}
+		}
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("🌊 出海 · %s：", LocationLabel(sub.OutdoorPlace, forecast.location)))
+		b.WriteString(fmt.Sprintf("高潮 %s，低潮 %s", joinOrDash(high), joinOrDash(low)))
+		if today := forecast.today; today != nil {
+			b.WriteString(fmt.Sprintf("，%s %s级\n", today.WindDirDay, today.WindScaleDay))
+			b.WriteString(formatOutdoorAdvice(sailingAdvice(today), "✅ 适宜出海"))
+		}
+		return b.String(), nil
+	}
+	return "", nil
+}
+
+// fetchMountain resolves a mountain as a scenic spot (falling back to a city or district) and
+// fetches its daily and hourly forecast; the hourly forecast is optional
+func (s *WeatherService) fetchMountain(place string) (*mountainForecast, error) {
+	location, err := s.ResolveLocation(place, qweather.POITypeScenic)
+	if err != nil {
+		if location, err = s.ResolveLocation(place, LocationHintCity); err != nil {
+			return nil, err
+		}
+	}
+	today, err := s.client.GetDailyForecast(location.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get mountain forecast: %w", err)
+	}
+	hourly, err := s.client.GetHourlyForecast(location.ID)
+	if err != nil {
+		logger.Warn("Failed to get mountain hourly forecast", zap.String("place", place), zap.Error(err))
+		hourly = nil
+	}
+	return &mountainForecast{location: location, today: today, hourly: hourly}, nil
+}
+
+// fetchSea resolves a tide station and fetches today's tide table and the weather at the
+// station's coordinates; the weather is optional
+func (s *WeatherService) fetchSea(place string, now time.Time) (*seaForecast, error) {
+	location, err := s.ResolveLocation(place, qweather.POITypeTide)
+	if err != nil {
+		return nil, err
+	}
+	tide, err := s.client.GetTide(location.ID, now.Format("20060102"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tide: %w", err)
+	}
+	today, err := s.client.GetDailyForecast(location.Lon + "," + location.Lat)
+	if err != nil {
+		logger.Warn("Failed to get sea forecast", zap.String("place", place), zap.Error(err))
+		today = nil
+	}
+	return &seaForecast{location: location, tide: tide, today: today}, nil
+}
+
+// hikingAdvice lists the day's hazards for hiking
+func hikingAdvice(today *qweather.DailyForecast) []string {
+	var advice []string
+	text := today.TextDay + today.TextNight
+	switch {
+	case strings.Contains(text, "雷"):
+		advice = append(advice, "有雷电，避免登顶和停留在空旷山脊")
+	case strings.Contains(text, "雨") || strings.Contains(text, "雪"):
+		advice = append(advice, "有降水，山路湿滑，注意防滑")
+	}
+	if maxWindScale(today.WindScaleDay, today.WindScaleNight) >= roughWindScale {
+		advice = append(advice, "风力较大，山顶和索道可能关闭")
+	}
+	if minTemp, err := strconv.Atoi(today.TempMin); err == nil && minTemp <= 0 {
+		advice = append(advice, "最低气温 0°C 以下，注意保暖防冻")
+	}
+	if vis, err := strconv.ParseFloat(today.Vis, 64); err == nil && vis > 0 && vis < 1 {
+		advice = append(advice, "能见度低，注意迷路风险")
+	}
+	return advice
+}
+
+// sailingAdvice lists the day's hazards for going to sea
+func sailingAdvice(today *qweather.DailyForecast) []string {
+	var advice []string
+	wind := maxWindScale(today.WindScaleDay, today.WindScaleNight)
+	switch {
+	case wind >= roughWindScale:
+		advice = append(advice, fmt.Sprintf("风力达 %d 级，不宜出海", wind))
+	case wind >= breezyWindScale:
+		advice = append(advice, "风浪较大，小型船只谨慎出海")
+	}
+	if strings.Contains(today.TextDay+today.TextNight, "雷") {
+		advice = append(advice, "有雷电，避免海上活动")
+	}
+	if strings.Contains(today.TextDay+today.TextNight, "雾") {
+		advice = append(advice, "有雾，海上能见度低")
+	}
+	return advice
+}
+
+// formatOutdoorAdvice formats hazards as warnings, or the all-clear line when there are none
+func formatOutdoorAdvice(advice []string, clear string) string {
+	if len(advice) == 0 {
+		return clear
+	}
+	return "⚠️ " + strings.Join(advice, "；")
+}
+
+// maxWindScale returns the highest wind scale among values such as "3" or "3-4"
+func maxWindScale(scales ...string) int {
+	highest := 0
+	for _, scale := range scales {
+		for _, part := range strings.Split(scale, "-") {
+			if n, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && n > highest {
+				highest = n
+			}
+		}
+	}
+	return highest
+}
+
+// dayText describes the day's weather, e.g. "晴转多云"
+func dayText(today *qweather.DailyForecast) string {
+	if today.TextDay == today.TextNight {
+		return today.TextDay
+	}
+	return today.TextDay + "转" + today.TextNight
+}
+
+// tideLabel names a tide type
+func tideLabel(tideType string) string {
+	if tideType == "H" {
+		return "⬆️ 高潮"
+	}
+	return "⬇️ 低潮"
+}
+
+// hourOf formats a QWeather forecast time as HH:MM, returning it unchanged when unparseable
+func hourOf(fxTime string) string {
+	if t, err := time.Parse("2006-01-02T15:04Z07:00", fxTime); err == nil {
+		return t.Format("15:04")
+	}
+	return fxTime
+}
+
+// joinOrDash joins times with "/", or returns "-" when there are none
+func joinOrDash(times []string) string {
+	if len(times) == 0 {
+		return "-"
+	}
+	return strings.Join(times, "/")
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// CurrencyStars is the currency of payments in Telegram Stars
+const CurrencyStars = "XTR"
+
+// paymentPayloadPrefix starts the payload of premium invoices: premium_<days>_<user ID>
+const paymentPayloadPrefix = "premium_"
+
+// ErrPaymentInvalid is returned for a payment that does not match a plan on sale for the user
+var ErrPaymentInvalid = errors.New("invalid payment")
+
+// PaymentPlan is a premium plan on sale
+type PaymentPlan struct {
+	Days  int // How long the premium tier lasts
+	Price int // Price in the smallest units of the currency
+}
+
+// PaymentService sells the premium tier through Telegram payments: it builds invoice payloads,
+// checks payments before they are made and records them in the ledger
+type PaymentService struct {
+	paymentRepo   *repository.PaymentRepository
+	providerToken string
+	currency      string
+	plans         []PaymentPlan
+}
+
+// NewPaymentService creates a new PaymentService; an empty provider token sells in Telegram Stars
+func NewPaymentService(paymentRepo *repository.PaymentRepository, providerToken, currency string, plans []PaymentPlan) *PaymentService {
+	return &PaymentService{
+		paymentRepo:   paymentRepo,
+		providerToken: providerToken,
+		currency:      currency,
+		plans:         plans,
+	}
+}
+
+// ProviderToken returns the payment provider token of invoices ("" for Telegram Stars)
+func (s *PaymentService) ProviderToken() string {
+	return s.providerToken
+}
+
+// Currency returns the currency of invoices
+func (s *PaymentService) Currency() string {
+	return s.currency
+}
+
+// Plans returns the premium plans on sale
+func (s *PaymentService) Plans() []PaymentPlan {
+	return s.plans
+}
+
+// Plan returns the plan on sale lasting the given number of days
+func (s *PaymentService) Plan(days int) (PaymentPlan, bool) {
+	for _, p := range s.plans {
+		if p.Days == days {
+			return p, true
+		}
+	}
+	return PaymentPlan{}, false
+}
+
+// Payload returns the invoice payload of a plan bought by a user
+func (s *PaymentService) Payload(user *model.User, plan PaymentPlan) string {
+	return fmt.Sprintf("%s%d_%d", paymentPayloadPrefix, plan.Days, user.ID)
+}
+
+// Validate checks a payment about to be made by a user (pre-checkout) against the plans on sale,
+// so that stale invoices of changed plans and invoices of other users are refused
+func (s *PaymentService) Validate(user *model.User, payload, currency string, total int) (PaymentPlan, error) {
+	parts := strings.Split(strings.TrimPrefix(payload, paymentPayloadPrefix), "_")
+	if !strings.HasPrefix(payload, paymentPayloadPrefix) || len(parts) != 2 {
+		return PaymentPlan{}, ErrPaymentInvalid
+	}
+	days, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return PaymentPlan{}, ErrPaymentInvalid
+	}
+	userID, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || uint(userID) != user.ID {
+		return PaymentPlan{}, ErrPaymentInvalid
+	}
+	plan, ok := s.Plan(days)
+	if !ok || currency != s.currency || total != plan.Price {
+		return PaymentPlan{}, ErrPaymentInvalid
+	}
+	return plan, nil
+}
+
+// Complete records a successful payment and extends the user's premium tier by the plan's days,
+// from the end of an active premium tier. Returns false when the charge is already recorded.
+func (s *PaymentService) Complete(user *model.User, payment *model.Payment, now time.Time) (bool, error) {
+	start := now
+	if user.EffectiveTier(now) == model.TierPremium {
+		if user.TierUntil == nil {
+			// Already premium without an end; the payment is still recorded
+			start = time.Time{}
+		} else {
+			start = *user.TierUntil
+		}
+	}
+	if !start.IsZero() {
+		until := start.AddDate(0, 0, payment.Days)
+		payment.TierUntil = &until
+	}
+	payment.UserID = user.ID
+
+	recorded, err := s.paymentRepo.Record(payment)
+	if err != nil || !recorded {
+		return recorded, err
+	}
+	user.Tier, user.TierUntil = model.TierPremium, payment.TierUntil
+	logger.Info("Premium tier bought",
+		zap.Uint("user_id", user.ID),
+		zap.Int("days", payment.Days),
+		zap.String("currency", payment.Currency),
+		zap.Int("amount", payment.Amount))
+	return true, nil
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+// Kinds of forecast pre-alerts
+const (
+	PreAlertColdDrop  = "cold_drop"
+	PreAlertFirstSnow = "first_snow"
+	PreAlertHeat      = "heat"
+)
+
+const (
+	// preAlertDropThreshold is the day-over-day drop of the high or low temperature (°C) that
+	// counts as a sharp cooling
+	preAlertDropThreshold = 8
+	// preAlertHeatTemp is the high temperature (°C) of a hot day
+	preAlertHeatTemp = 35
+	// preAlertHeatDays is the number of consecutive hot days that counts as sustained heat; a
+	// heat spell is also alerted at most once per this many days
+	preAlertHeatDays = 3
+	// preAlertSnowSeasonMonth is the month a new snow season starts in, for the first snow alert
+	preAlertSnowSeasonMonth = time.August
+)
+
+// preAlertWarningTypes maps pre-alert kinds to the official warning types whose mute also
+// silences them (cold wave, blizzard, high temperature)
+var preAlertWarningTypes = map[string]string{
+	PreAlertColdDrop:  "1005",
+	PreAlertFirstSnow: "1004",
+	PreAlertHeat:      "1009",
+}
+
+// PreAlert is a notable change found in a city's forecast
+type PreAlert struct {
+	Kind       string
+	TargetDate string // Forecast date the alert is about (YYYY-MM-DD)
+	Title      string
+	Detail     string
+}
+
+// PreAlertService analyzes the 3-day forecast of subscribed cities the evening before and pushes
+// notices about sharp cooling, the first snow of the season and sustained heat, ahead of any
+// official warning
+type PreAlertService struct {
+	client     *qweather.Client
+	repo       *repository.PreAlertLogRepository
+	subRepo    *repository.SubscriptionRepository
+	warningSvc *WarningService // Warning type mutes (nil = not applied)
+	notifySvc  *NotificationService
+}
+
+// NewPreAlertService creates a new PreAlertService
+func NewPreAlertService(
+	client *qweather.Client,
+	repo *repository.PreAlertLogRepository,
+	subRepo *repository.SubscriptionRepository,
+	warningSvc *WarningService,
+	notifySvc *NotificationService,
+) *PreAlertService {
+	return &PreAlertService{
+		client:     client,
+		repo:       repo,
+		subRepo:    subRepo,
+		warningSvc: warningSvc,
+		notifySvc:  notifySvc,
+	}
+}
+
+// CheckAndNotify analyzes the forecast of every city with warning pushes enabled (or only the
+// given city when not "") and notifies its subscribers of new pre-alerts
+func (s *PreAlertService) CheckAndNotify(ctx context.Context, now time.Time, city string) (JobResult, error) {
+	start := time.Now()
+	subs, err := s.subRepo.GetAllActive()
+	if err != nil {
+		logger.Error("Failed to get subscriptions for pre-alerts", zap.Error(err))
+		return JobResult{}, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+
+	cityMap := make(map[string][]model.Subscription)
+	for _, sub := range subs {
+		if sub.Active && sub.EnableWarning && (city == "" || sub.City == city) {
+			cityMap[sub.City] = append(cityMap[sub.City], sub)
+		}
+	}
+
+	result := JobResult{Items: len(cityMap)}
+	var firstErr error
+	for city, citySubs := range cityMap {
+		if err := s.checkCity(ctx, city, citySubs, now); err != nil {
+			logger.Warn("Failed to check pre-alerts for city",
+				zap.String("city", city),
+				zap.Error(err))
+			result.Failures++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", city, err)
+			}
+		}
+	}
+
+	logger.Info("Pre-alert check completed",
+		zap.Int("city_count", result.Items),
+		zap.Int("failed_count", result.Failures),
+		zap.Duration("duration", time.Since(start)))
+	if firstErr != nil {
+		return result, fmt.Errorf("%d of %d cities failed, first %w", result.Failures, result.Items, firstErr)
+	}
+	return result, nil
+}
+
+// checkCity analyzes a city's forecast and pushes each pre-alert not sent before
+func (s *PreAlertService) checkCity(ctx context.Context, city string, subs []model.Subscription, now time.Time) error {
+	locationID, err := s.client.GetLocationID(city)
+	if err != nil {
+		return fmt.Errorf("failed to get location ID for %s: %w", city, err)
+	}
+	days, err := s.client.GetDailyForecasts(locationID)
+	if err != nil {
+		return fmt.Errorf("failed to get forecast for %s: %w", city, err)
+	}
+
+	for _, alert := range AnalyzeForecast(days, now) {
+		if s.suppressed(city, alert) {
+			continue
+		}
+		log := &model.PreAlertLog{City: city, Kind: alert.Kind, TargetDate: alert.TargetDate, Summary: alert.Detail}
+		claimed, err := s.repo.Claim(log)
+		if err != nil || !claimed {
+			continue
+		}
+
+		recipients := subs
+		if s.warningSvc != nil {
+			recipients = s.warningSvc.unmutedSubscriptions(preAlertWarningTypes[alert.Kind], subs)
+		}
+		sent := s.deliver(ctx, city, alert, recipients)
+		if sent == 0 && len(recipients) > 0 {
+			// Nobody got it: drop the record so the next run retries
+			if err := s.repo.Release(log.ID); err != nil {
+				logger.Warn("Failed to release pre-alert log", zap.Uint("id", log.ID), zap.Error(err))
+			}
+			continue
+		}
+		logger.Info("Pre-alert sent",
+			zap.String("city", city),
+			zap.String("kind", alert.Kind),
+			zap.String("target_date", alert.TargetDate),
+			zap.Int("sent_count", sent),
+			zap.Int("muted_count", len(subs)-len(recipients)))
+	}
+	return nil
+}
+
+// suppressed reports whether an alert repeats one of the same event: a snow season already
+// alerted, or a heat spell alerted within the last days
+func (s *PreAlertService) suppressed(city string, alert PreAlert) bool {
+	target, err := time.Parse("2006-01-02", alert.TargetDate)
+	if err != nil {
+		return false
+	}
+	var since string
+	switch alert.Kind {
+	case PreAlertFirstSnow:
+		year := target.Year()
+		if target.Month() < preAlertSnowSeasonMonth {
+			year--
+		}
+		since = time.Date(year, preAlertSnowSeasonMonth, 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	case PreAlertHeat:
+		since = target.AddDate(0, 0, -preAlertHeatDays).Format("2006-01-02")
+	default:
+		return false
+	}
+	exists, err := s.repo.ExistsSince(city, alert.Kind, since)
+	if err != nil {
+		logger.Warn("Failed to check pre-alert history", zap.String("city", city), zap.Error(err))
+		return true
+	}
+	return exists
+}
+
+// deliver pushes a pre-alert to each subscription, returning the number of successful sends
+func (s *PreAlertService) deliver(ctx context.Context, city string, alert PreAlert, subs []model.Subscription) int {
+	body := fmt.Sprintf("📢 %s%s\n\n%s\n\n💡 此为根据天气预报的提前提醒，请以气象部门发布的预警为准", city, alert.Title, alert.Detail)
+	sent := 0
+	for _, sub := range subs {
+		msg := notify.Message{
+			Title:    city + alert.Title,
+			Body:     body + "\n\n" + subscriptionContext(sub),
+			Priority: notify.PriorityHigh,
+			Markup:   warningMarkup(sub),
+		}
+		if err := s.notifySvc.Deliver(ctx, sub, msg); err != nil {
+			logger.Warn("Failed to push pre-alert",
+				zap.Uint("subscription_id", sub.ID),
+				zap.String("kind", alert.Kind),
+				zap.Error(err))
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// AnalyzeForecast finds the pre-alerts about tomorrow in a forecast starting today: a drop of the
+// high or low temperature by preAlertDropThreshold, snow, and the start or continuation of
+// preAlertHeatDays hot days. Snow and heat still need the history check of the caller.
+func AnalyzeForecast(days []qweather.DailyForecast, now time.Time) []PreAlert {
+	today := now.Format("2006-01-02")
+	idx := -1
+	for i, day := range days {
+		if day.FxDate == today {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 || idx+1 >= len(days) {
+		return nil
+	}
+	cur, next := days[idx], days[idx+1]
+
+	var alerts []PreAlert
+	if dropMax, dropMin, ok := temperatureDrop(cur, next); ok && (dropMax >= preAlertDropThreshold || dropMin >= preAlertDropThreshold) {
+		alerts = append(alerts, PreAlert{
+			Kind:       PreAlertColdDrop,
+			TargetDate: next.FxDate,
+			Title:      "明天大降温",
+			Detail: fmt.Sprintf("🌡️ 明天 %s~%s°C，今天 %s~%s°C\n最高气温降 %d°C，最低气温降 %d°C，请及时添衣保暖",
+				next.TempMin, next.TempMax, cur.TempMin, cur.TempMax, dropMax, dropMin),
+		})
+	}
+
+	if isSnow(next) {
+		alerts = append(alerts, PreAlert{
+			Kind:       PreAlertFirstSnow,
+			TargetDate: next.FxDate,
+			Title:      "明天或迎初雪",
+			Detail: fmt.Sprintf("❄️ 明天白天%s，夜间%s，%s~%s°C\n这是今冬首次降雪预报，出行注意防滑保暖",
+				next.TextDay, next.TextNight, next.TempMin, next.TempMax),
+		})
+	}
+
+	hot := 0
+	var highs []string
+	for _, day := range days[idx:] {
+		if high, err := strconv.Atoi(day.TempMax); err != nil || high < preAlertHeatTemp {
+			break
+		}
+		hot++
+		highs = append(highs, day.TempMax+"°C")
+	}
+	if hot >= preAlertHeatDays {
+		alerts = append(alerts, PreAlert{
+			Kind:       PreAlertHeat,
+			TargetDate: next.FxDate,
+			Title:      "持续高温",
+			Detail: fmt.Sprintf("🔥 预计连续 %d 天最高气温 %d°C 以上（%s）\n注意防暑降温，避免午后长时间户外活动",
+				hot, preAlertHeatTemp, strings.Join(highs, "、")),
+		})
+	}
+	return alerts
+}
+
+// temperatureDrop returns how much the high and low temperatures fall from one day to the next
+func temperatureDrop(cur, next qweather.DailyForecast) (dropMax, dropMin int, ok bool) {
+	curMax, err1 := strconv.Atoi(cur.TempMax)
+	curMin, err2 := strconv.Atoi(cur.TempMin)
+	nextMax, err3 := strconv.Atoi(next.TempMax)
+	nextMin, err4 := strconv.Atoi(next.TempMin)
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
+		return 0, 0, false
+	}
+	return curMax - nextMax, curMin - nextMin, true
+}
+
+// isSnow reports whether a forecast day has snow (including sleet) during the day or night
+func isSnow(day qweather.DailyForecast) bool {
+	return strings.Contains(day.TextDay, "雪") || strings.Contains(day.TextNight, "雪")
+}
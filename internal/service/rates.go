@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/rates"
+)
+
+// sectionRates is the name of the exchange rate and metal price digest section
+const sectionRates = "rates"
+
+// currencyNames are the Chinese names of common currencies and precious metals
+var currencyNames = map[string]string{
+	"CNY": "人民币", "USD": "美元", "EUR": "欧元", "JPY": "日元", "GBP": "英镑",
+	"HKD": "港币", "KRW": "韩元", "AUD": "澳元", "CAD": "加元", "SGD": "新加坡元",
+	"CHF": "瑞士法郎", "NZD": "新西兰元", "THB": "泰铢", "MYR": "林吉特",
+	"XAU": "黄金", "XAG": "白银", "XPT": "铂金", "XPD": "钯金",
+}
+
+// RatesService is the exchange rate and metal price digest section: it shows the configured pairs,
+// or the subscriber's own, with the change from the previous day. Quotes are cached per pair, so
+// all reminders within the cache period share one provider request.
+type RatesService struct {
+	provider     rates.Provider
+	defaultPairs []rates.Pair // Pairs of users without their own (empty = none)
+	maxPairs     int          // Maximum pairs a user may choose
+	cacheTTL     time.Duration
+
+	mu      sync.Mutex
+	cache   map[rates.Pair]ratesEntry
+	fetchMu sync.Mutex // Serializes provider requests so concurrent reminders wait for one fetch
+}
+
+// ratesEntry is a cached quote
+type ratesEntry struct {
+	quote     rates.Quote
+	expiresAt time.Time
+}
+
+// ratesContent is the fetched rates section of a reminder
+type ratesContent struct {
+	quotes []rates.Quote
+}
+
+// NewRatesService creates a new RatesService
+func NewRatesService(provider rates.Provider, defaultPairs []rates.Pair, maxPairs int, cacheTTL time.Duration) *RatesService {
+	return &RatesService{
+		provider:     provider,
+		defaultPairs: defaultPairs,
+		maxPairs:     maxPairs,
+		cacheTTL:     cacheTTL,
+		cache:        make(map[rates.Pair]ratesEntry),
+	}
+}
+
+// DefaultPairs returns the pairs of users without their own
+func (s *RatesService) DefaultPairs() []rates.Pair {
+	return s.defaultPairs
+}
+
+// MaxPairs returns the maximum number of pairs a user may choose
+func (s *RatesService) MaxPairs() int {
+	return s.maxPairs
+}
+
+// Name implements DigestSection
+func (s *RatesService) Name() string {
+	return sectionRates
+}
+
+// Personal implements PersonalSection: the pairs are chosen per user
+func (s *RatesService) Personal() bool {
+	return true
+}
+
+// Fetch implements DigestSection: it returns the quotes of the subscriber's pairs, or nil when the
+// subscriber turned the section off or there are no pairs
+func (s *RatesService) Fetch(ctx context.Context, target *SectionTarget) (SectionContent, error) {
+	user := target.Sub.User
+	if user.RatesOff {
+		return nil, nil
+	}
+	pairs := s.defaultPairs
+	if user.RatePairs != "" {
+		own, err := ParseRatePairs(user.RatePairs)
+		if err != nil {
+			return nil, err
+		}
+		pairs = own
+	}
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	quotes, err := s.Quotes(ctx, pairs)
+	if err != nil {
+		return nil, err
+	}
+	return &ratesContent{quotes: quotes}, nil
+}
+
+// Quotes returns the quotes of pairs in order, requesting the ones not cached from the provider
+func (s *RatesService) Quotes(ctx context.Context, pairs []rates.Pair) ([]rates.Quote, error) {
+	if quotes, missing := s.cached(pairs); len(missing) == 0 {
+		return quotes, nil
+	}
+
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+
+	// Another reminder may have fetched them while waiting
+	quotes, missing := s.cached(pairs)
+	if len(missing) == 0 {
+		return quotes, nil
+	}
+	fetched, err := s.provider.Quotes(ctx, missing)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rates: %w", err)
+	}
+
+	s.mu.Lock()
+	expiresAt := time.Now().Add(s.cacheTTL)
+	for _, q := range fetched {
+		s.cache[q.Pair] = ratesEntry{quote: q, expiresAt: expiresAt}
+	}
+	s.mu.Unlock()
+
+	quotes, missing = s.cached(pairs)
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no rate for %s", missing[0])
+	}
+	return quotes, nil
+}
+
+// cached returns the cached quotes of pairs and the pairs that are missing or expired
+func (s *RatesService) cached(pairs []rates.Pair) ([]rates.Quote, []rates.Pair) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	quotes := make([]rates.Quote, 0, len(pairs))
+	var missing []rates.Pair
+	for _, p := range pairs {
+		entry, ok := s.cache[p]
+		if !ok || now.After(entry.expiresAt) {
+			missing = append(missing, p)
+			continue
+		}
+		quotes = append(quotes, entry.quote)
+	}
+	return quotes, missing
+}
+
+// ParseRatePairs parses a comma or space separated list of pairs such as "USD/CNY,XAU/CNY",
+// dropping duplicates
+func ParseRatePairs(s string) ([]rates.Pair, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '，' || r == ' '
+	})
+	pairs := make([]rates.Pair, 0, len(fields))
+	seen := make(map[rates.Pair]bool, len(fields))
+	for _, field := range fields {
+		p, err := rates.ParsePair(field)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[p] {
+			seen[p] = true
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs, nil
+}
+
+// FormatRatePairs joins pairs into the form stored on users
+func FormatRatePairs(pairs []rates.Pair) string {
+	names := make([]string, 0, len(pairs))
+	for _, p := range pairs {
+		names = append(names, p.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// Render writes one line per quote with its change from the previous day
+func (c *ratesContent) Render(string) string {
+	var section strings.Builder
+	section.WriteString("💱 汇率金价：\n")
+	for _, q := range c.quotes {
+		section.WriteString("   " + FormatQuote(q) + "\n")
+	}
+	return section.String()
+}
+
+// FormatQuote formats a quote, e.g. "美元/人民币 7.1234 ↑0.0120" or "黄金 612.35 人民币/克"
+func FormatQuote(q rates.Quote) string {
+	var line string
+	if q.Pair.IsMetal() {
+		unit := "盎司"
+		if q.Unit == "g" {
+			unit = "克"
+		}
+		line = fmt.Sprintf("%s %s %s/%s", currencyName(q.Pair.Base), formatRate(q.Rate, q.Rate), currencyName(q.Pair.Quote), unit)
+	} else {
+		line = fmt.Sprintf("%s/%s %s", currencyName(q.Pair.Base), currencyName(q.Pair.Quote), formatRate(q.Rate, q.Rate))
+	}
+
+	if change, ok := q.Change(); ok {
+		switch {
+		case formatRate(math.Abs(change), q.Rate) == formatRate(0, q.Rate):
+			line += " 持平"
+		case change > 0:
+			line += " ↑" + formatRate(change, q.Rate)
+		default:
+			line += " ↓" + formatRate(-change, q.Rate)
+		}
+	}
+	return line
+}
+
+// formatRate formats a value with the precision suited to the magnitude of rate
+func formatRate(value, rate float64) string {
+	if rate >= 100 {
+		return fmt.Sprintf("%.2f", value)
+	}
+	return fmt.Sprintf("%.4f", value)
+}
+
+// currencyName returns the Chinese name of a currency or metal, or its code when unknown
+func currencyName(code string) string {
+	if name, ok := currencyNames[code]; ok {
+		return name
+	}
+	return code
+}
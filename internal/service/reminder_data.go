@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// reminderData is the data of a daily reminder: the digest sections and the forecasts that are
+// not shown as sections but feed the AI prompt, the advice and the yesterday comparison. Each
+// source is fetched independently, so a failing source only leaves its own part empty.
+type reminderData struct {
+	target   *SectionTarget
+	sections []fetchedSection
+	hourly   []qweather.HourlyForecast
+	uv       *UVForecast             // Only fetched for morning AI reminders
+	forecast *qweather.DailyForecast // Today's forecast, for the yesterday comparison and advice
+}
+
+// resolveTarget looks up the subscription's location, shared by all digest sections. The
+// subscription's own coordinates take precedence over the city's.
+func resolveTarget(client *qweather.Client, sub model.Subscription, now time.Time) *SectionTarget {
+	target := &SectionTarget{Sub: sub, Now: now, Lat: sub.Lat, Lon: sub.Lon, client: client}
+	target.Location, target.LocationErr = client.GetLocation(sub.LocationQuery())
+	if target.LocationErr != nil {
+		logger.Error("Failed to get location", zap.Uint("user_id", sub.UserID), zap.Error(target.LocationErr))
+		target.Location = nil
+	} else if !sub.HasCoordinates() {
+		target.Lat, target.Lon = target.Location.Lat, target.Location.Lon
+	}
+	return target
+}
+
+// gatherReminderData resolves the subscription's location and then fetches the registered digest
+// sections and, when the AI writes the reminder, the hourly and UV forecasts concurrently. Each
+// QWeather call is bounded by its client's timeout. Failures are logged and only affect their
+// own section.
+func (s *SchedulerService) gatherReminderData(ctx context.Context, sub model.Subscription, now time.Time, withAI bool) *reminderData {
+	client := s.weatherSvc.Client()
+	data := &reminderData{target: resolveTarget(client, sub, now)}
+	locationID := data.target.LocationID()
+	lat, lon := data.target.Lat, data.target.Lon
+
+	// Sources fail independently, so the goroutines never return an error to cancel the others
+	var g errgroup.Group
+	fetch := func(f func()) {
+		g.Go(func() error {
+			f()
+			return nil
+		})
+	}
+
+	fetch(func() {
+		data.sections = fetchSections(ctx, s.sections.Sections(), data.target)
+	})
+
+	if withAI && (sub.HasCoordinates() || locationID != "") {
+		fetch(func() {
+			var err error
+			if sub.HasCoordinates() {
+				data.hourly, err = client.GetGridHourlyForecast(lat, lon)
+			} else {
+				data.hourly, err = client.GetHourlyForecast(locationID)
+			}
+			if err != nil {
+				logger.Warn("Failed to get hourly forecast", zap.Uint("user_id", sub.UserID), zap.Error(err))
+				data.hourly = nil
+			}
+		})
+	}
+
+	if withAI && locationID != "" && now.Hour() < uvPeakBeforeHour {
+		fetch(func() {
+			var err error
+			if data.uv, err = s.weatherSvc.GetUVForecast(locationID, lat, lon); err != nil {
+				logger.Warn("Failed to get UV forecast", zap.Uint("user_id", sub.UserID), zap.Error(err))
+				data.uv = nil
+			}
+		})
+	}
+
+	if (s.snapshots != nil || s.advice != nil) && locationID != "" {
+		fetch(func() {
+			var err error
+			if data.forecast, err = client.GetDailyForecast(locationID); err != nil {
+				logger.Warn("Failed to get daily forecast", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+				data.forecast = nil
+			}
+		})
+	}
+
+	_ = g.Wait()
+	return data
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/advice"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+)
+
+// keyIndexTypes are the life indices highlighted in reminders, in order: dressing, UV, sports
+var keyIndexTypes = []string{"3", "5", "1"}
+
+// DailyReport is the assembled content of a daily reminder. Both the AI prompt and the fixed
+// template are rendered from it, so they always agree on what is shown.
+type DailyReport struct {
+	City   string
+	Label  string    // User-chosen name of the subscription ("" = none)
+	Date   time.Time // Local time the report is generated for
+	Locale string    // Telegram language code of the user, passed to the sections' Render
+
+	Calendar ReportCalendar
+	Warnings []qweather.Warning
+	Weather  *qweather.CurrentWeather
+	Hourly   []qweather.HourlyForecast // Hourly forecast for the coming hours (AI only)
+	UVPeak   string                    // Peak UV time and protection window, for morning AI reminders
+	Air      *qweather.AirQualityIndex // Main air quality index (QAQI preferred)
+	AirTrend string                    // Hint that the air turns polluted later today ("" = none)
+
+	Indices      []qweather.LifeIndex // All life indices, in API order
+	KeyIndices   []qweather.LifeIndex // Dressing, UV and sports indices, in that order
+	OtherIndices []qweather.LifeIndex // Remaining life indices, in API order
+	Advice       []advice.Tip         // Dressing and seasonal care tips
+
+	Style ReportStyle // Format variant from running experiments (zero = regular format)
+
+	Todos        []model.Todo
+	TodoPlan     *TodoPlan // Todos ordered and annotated by the weather (nil = not planned)
+	Achievements string    // Todo streaks and badges
+
+	Unavailable ReportGaps
+
+	sections []fetchedSection // Fetched section contents, in registration order
+}
+
+// ReportCalendar holds the calendar sections of a daily report
+type ReportCalendar struct {
+	Header    string // Gregorian and lunar date (empty without a calendar service)
+	Special   string // Today's festival or solar term
+	Festivals string // Upcoming festivals and holidays
+	AIInfo    string // Calendar details for the AI prompt
+}
+
+// ReportGaps marks the sections whose data source failed, shown as placeholders. Location is set
+// instead of the others when the city could not be resolved.
+type ReportGaps struct {
+	Location bool
+	Warnings bool
+	Weather  bool
+	Indices  bool
+	Air      bool
+}
+
+// ReportBuilder assembles daily reports from the fetched digest sections and renders the fixed template
+type ReportBuilder struct {
+	todoSvc *TodoService
+}
+
+// NewReportBuilder creates a new ReportBuilder
+func NewReportBuilder(todoSvc *TodoService) *ReportBuilder {
+	return &ReportBuilder{todoSvc: todoSvc}
+}
+
+// Build assembles a daily report from the fetched reminder data. The built-in sections fill the
+// report fields used by the AI prompt; all sections are kept for rendering.
+func (b *ReportBuilder) Build(data *reminderData) *DailyReport {
+	target := data.target
+	report := &DailyReport{
+		City:     target.Sub.City,
+		Label:    target.Sub.Label,
+		Date:     target.Now,
+		Locale:   target.Sub.User.LanguageCode,
+		Hourly:   data.hourly,
+		sections: data.sections,
+	}
+	if data.uv != nil {
+		report.UVPeak = FormatUVPeakForAI(data.uv)
+	}
+	for _, section := range data.sections {
+		if s, ok := section.content.(reportSection); ok {
+			s.fill(report)
+		}
+	}
+	if target.LocationErr != nil {
+		report.Unavailable = ReportGaps{Location: true}
+	}
+	return report
+}
+
+// section returns the fetched content of a section, or nil
+func (r *DailyReport) section(name string) SectionContent {
+	for _, s := range r.sections {
+		if s.name == name {
+			return s.content
+		}
+	}
+	return nil
+}
+
+// displayName returns the name of the report's place shown to the subscriber, as
+// model.Subscription.DisplayName
+func (r *DailyReport) displayName() string {
+	if r.Label != "" {
+		return r.Label + "（" + r.City + "）"
+	}
+	return r.City
+}
+
+// impersonalContent is implemented by the contents of shared sections that still follow some of
+// the subscriber's settings (e.g. hidden festivals); the city digest uses their impersonal form
+type impersonalContent interface {
+	impersonal() SectionContent
+}
+
+// dropPersonalSections removes the contents of personal sections, before rendering the city digest
+func (r *DailyReport) dropPersonalSections() {
+	sections := make([]fetchedSection, 0, len(r.sections))
+	for _, s := range r.sections {
+		if s.personal {
+			continue
+		}
+		if c, ok := s.content.(impersonalContent); ok {
+			s.content = c.impersonal()
+		}
+		sections = append(sections, s)
+	}
+	r.sections = sections
+}
+
+// extraSections renders the sections registered in addition to the built-in ones that match keep
+// (nil = all of them)
+func (r *DailyReport) extraSections(keep func(s fetchedSection) bool) []string {
+	var texts []string
+	for _, s := range r.sections {
+		if _, builtin := s.content.(reportSection); builtin {
+			continue
+		}
+		if keep != nil && !keep(s) {
+			continue
+		}
+		if text := strings.TrimRight(s.content.Render(r.Locale), "\n"); text != "" {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+// digestSectionOrders maps ReportStyle.SectionOrder to the order of the built-in digest sections
+var digestSectionOrders = map[string][]string{
+	"":              {sectionWarnings, sectionCalendar, sectionWeather, sectionIndices, sectionAdvice, sectionAir},
+	"weather_first": {sectionWarnings, sectionWeather, sectionAir, sectionIndices, sectionAdvice, sectionCalendar},
+}
+
+// RenderDigest renders the non-personal part of the fixed template: the built-in sections
+// (calendar, warnings, weather, life indices, advice and air quality) followed by the additional
+// registered sections, except those anchored after a built-in section. Sections whose source failed are shown as placeholders; when the location
+// is unknown only a single notice replaces them. The report style selects the order of the
+// built-in sections and the emoji density.
+func (b *ReportBuilder) RenderDigest(r *DailyReport) string {
+	var report strings.Builder
+
+	// Date header with calendar info
+	if r.Label != "" {
+		report.WriteString(fmt.Sprintf("🌅 早安！今日提醒 · 🏷 %s\n", r.Label))
+	} else {
+		report.WriteString("🌅 早安！今日提醒\n")
+	}
+
+	order, ok := digestSectionOrders[r.Style.SectionOrder]
+	if !ok {
+		order = digestSectionOrders[""]
+	}
+	anchored := make(map[string]bool, len(order))
+	for _, name := range order {
+		anchored[name] = true
+	}
+	for _, name := range order {
+		if name == sectionAdvice {
+			writeAdviceSection(&report, r)
+		} else if content := r.section(name); content != nil {
+			report.WriteString(content.Render(r.Locale))
+		}
+		for _, text := range r.extraSections(func(s fetchedSection) bool { return s.after == name }) {
+			report.WriteString(text + "\n\n")
+		}
+	}
+	for _, text := range r.extraSections(func(s fetchedSection) bool { return !anchored[s.after] }) {
+		report.WriteString(text + "\n\n")
+	}
+
+	return applyEmojiStyle(report.String(), r.Style)
+}
+
+// writeAdviceSection writes the dressing and seasonal care tips
+func writeAdviceSection(report *strings.Builder, r *DailyReport) {
+	if len(r.Advice) > 0 {
+		report.WriteString("💡 贴心建议：\n")
+		for _, tip := range r.Advice {
+			report.WriteString(tip.String() + "\n")
+		}
+		report.WriteString("\n")
+	}
+}
+
+// RenderTemplate renders the full fixed-template reminder: the digest followed by the todos and
+// achievements, with a notice when the AI was supposed to write it
+func (b *ReportBuilder) RenderTemplate(r *DailyReport, aiWasEnabled bool) string {
+	var report strings.Builder
+	report.WriteString(b.RenderDigest(r))
+
+	// Add todo list (ordered by the weather when planned) and completion streaks
+	if r.section(sectionTodos) != nil {
+		report.WriteString(formatTodoSection(b.todoSvc, r.Todos, r.TodoPlan, r.Achievements))
+	}
+
+	// Add AI service unavailable notice
+	if aiWasEnabled {
+		report.WriteString("\n---\n(AI 服务暂不可用，使用默认模板)")
+	}
+
+	return applyEmojiStyle(report.String(), r.Style)
+}
+
+// RenderAIAppendix renders the sections appended to an AI-written reminder: the additional
+// registered sections, which the AI does not see, and the planned todos and achievements, which
+// the AI only mentions briefly
+func (b *ReportBuilder) RenderAIAppendix(r *DailyReport) string {
+	sections := r.extraSections(nil)
+	if r.TodoPlan != nil {
+		sections = append(sections, b.todoSvc.FormatTodoPlan(r.TodoPlan))
+	}
+	if r.Achievements != "" {
+		sections = append(sections, r.Achievements)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// mainAirQualityIndex returns the QAQI index of an air quality response, or its first index
+// when QAQI is missing; nil when there is no data
+func mainAirQualityIndex(airQuality *qweather.AirQualityResponse) *qweather.AirQualityIndex {
+	if airQuality == nil || len(airQuality.Indexes) == 0 {
+		return nil
+	}
+	for i := range airQuality.Indexes {
+		if airQuality.Indexes[i].Code == "qaqi" {
+			return &airQuality.Indexes[i]
+		}
+	}
+	return &airQuality.Indexes[0]
+}
+
+// splitLifeIndices separates the key life indices (in keyIndexTypes order) from the rest
+func splitLifeIndices(indices []qweather.LifeIndex) (key, other []qweather.LifeIndex) {
+	byType := make(map[string]qweather.LifeIndex, len(indices))
+	for _, index := range indices {
+		byType[index.Type] = index
+	}
+	for _, typ := range keyIndexTypes {
+		if index, ok := byType[typ]; ok {
+			key = append(key, index)
+		}
+	}
+	for _, index := range indices {
+		if !isKeyIndexType(index.Type) {
+			other = append(other, index)
+		}
+	}
+	return key, other
+}
+
+// isKeyIndexType reports whether a life index type is one of keyIndexTypes
+func isKeyIndexType(typ string) bool {
+	for _, key := range keyIndexTypes {
+		if typ == key {
+			return true
+		}
+	}
+	return false
+}
+
+// applyEmojiStyle removes the emoji of a rendered text when the style asks for minimal emoji
+func applyEmojiStyle(text string, style ReportStyle) string {
+	if style.Emoji != "minimal" {
+		return text
+	}
+	return stripEmoji(text)
+}
+
+// stripEmoji removes emoji (pictographs, symbols, flags and their modifiers) and the spaces they
+// leave at the start of lines
+func stripEmoji(text string) string {
+	stripped := strings.Map(func(r rune) rune {
+		switch {
+		case r == 0xFE0F || r == 0x200D || r == 0x20E3:
+			return -1
+		case r >= 0x1F000 && r <= 0x1FAFF:
+			return -1
+		case r >= 0x2190 && r <= 0x2BFF && unicode.Is(unicode.So, r):
+			return -1
+		}
+		return r
+	}, text)
+
+	lines := strings.Split(stripped, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		// Keep the indentation of detail lines, which never started with an emoji
+		if strings.HasPrefix(line, "   ") && len(line)-len(trimmed) == 3 {
+			continue
+		}
+		lines[i] = trimmed
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Errors of granting and revoking roles
+var (
+	ErrRoleInvalid    = errors.New("role cannot be granted")
+	ErrRoleForbidden  = errors.New("not allowed to change this role")
+	ErrRoleNotGranted = errors.New("no role granted")
+	ErrRoleConfigured = errors.New("role is set in the configuration")
+)
+
+// roleRanks orders the roles; a role includes the permissions of the roles ranked below it
+var roleRanks = map[string]int{
+	model.RoleSupport: 1,
+	model.RoleAdmin:   2,
+	model.RoleOwner:   3,
+}
+
+// StaffMember is a staff user with their effective role
+type StaffMember struct {
+	TelegramID int64
+	Role       string
+	Configured bool // The role comes from the configuration and cannot be revoked
+}
+
+// RoleService resolves the roles (owner, admin, support) of staff users, combining roles set in
+// the configuration with roles granted by /grant, and checks who may grant and revoke them
+type RoleService struct {
+	repo       *repository.StaffRoleRepository
+	configured map[int64]string
+}
+
+// NewRoleService creates a new RoleService; owners, admins and support are the Telegram user IDs
+// given each role in the configuration
+func NewRoleService(repo *repository.StaffRoleRepository, owners, admins, support []int64) *RoleService {
+	configured := make(map[int64]string)
+	for _, group := range []struct {
+		role string
+		ids  []int64
+	}{{model.RoleSupport, support}, {model.RoleAdmin, admins}, {model.RoleOwner, owners}} {
+		for _, id := range group.ids {
+			configured[id] = group.role
+		}
+	}
+	return &RoleService{repo: repo, configured: configured}
+}
+
+// Role returns the effective role of a Telegram user ("" = none): the higher of the configured
+// and the granted role
+func (s *RoleService) Role(telegramID int64) string {
+	role := s.configured[telegramID]
+	if role == model.RoleOwner {
+		return role
+	}
+	granted, err := s.repo.Find(telegramID)
+	if err != nil {
+		logger.Warn("Failed to resolve staff role, using the configured role",
+			zap.Int64("telegram_id", telegramID),
+			zap.Error(err))
+		return role
+	}
+	if granted != nil && roleRanks[granted.Role] > roleRanks[role] {
+		role = granted.Role
+	}
+	return role
+}
+
+// Has reports whether a Telegram user has a role or a higher one
+func (s *RoleService) Has(telegramID int64, role string) bool {
+	return roleRanks[s.Role(telegramID)] >= roleRanks[role]
+}
+
+// Grant gives a role (admin or support) to a Telegram user. The actor's role must rank above
+// both the granted role and the user's current role.
+func (s *RoleService) Grant(actor, target int64, role string) error {
+	if role != model.RoleAdmin && role != model.RoleSupport {
+		return ErrRoleInvalid
+	}
+	actorRank := roleRanks[s.Role(actor)]
+	if actorRank <= roleRanks[role] || actorRank <= roleRanks[s.Role(target)] {
+		return ErrRoleForbidden
+	}
+	if err := s.repo.Upsert(&model.StaffRole{TelegramID: target, Role: role, GrantedBy: actor}); err != nil {
+		return err
+	}
+	logger.Info("Staff role granted",
+		zap.Int64("actor", actor),
+		zap.Int64("telegram_id", target),
+		zap.String("role", role))
+	return nil
+}
+
+// Revoke removes the role granted to a Telegram user and returns it. The actor's role must rank
+// above it; roles set in the configuration cannot be revoked.
+func (s *RoleService) Revoke(actor, target int64) (string, error) {
+	granted, err := s.repo.Find(target)
+	if err != nil {
+		return "", err
+	}
+	if granted == nil {
+		if s.configured[target] != "" {
+			return "", ErrRoleConfigured
+		}
+		return "", ErrRoleNotGranted
+	}
+	if roleRanks[s.Role(actor)] <= roleRanks[granted.Role] {
+		return "", ErrRoleForbidden
+	}
+	if err := s.repo.Delete(target); err != nil {
+		return "", err
+	}
+	logger.Info("Staff role revoked",
+		zap.Int64("actor", actor),
+		zap.Int64("telegram_id", target),
+		zap.String("role", granted.Role))
+	return granted.Role, nil
+}
+
+// Staff returns all staff users with their effective roles, most privileged first
+func (s *RoleService) Staff() ([]StaffMember, error) {
+	granted, err := s.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[int64]StaffMember, len(s.configured)+len(granted))
+	for id, role := range s.configured {
+		members[id] = StaffMember{TelegramID: id, Role: role, Configured: true}
+	}
+	for _, g := range granted {
+		if m, ok := members[g.TelegramID]; ok && roleRanks[m.Role] >= roleRanks[g.Role] {
+			continue
+		}
+		members[g.TelegramID] = StaffMember{TelegramID: g.TelegramID, Role: g.Role}
+	}
+
+	list := make([]StaffMember, 0, len(members))
+	for _, m := range members {
+		list = append(list, m)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if roleRanks[list[i].Role] != roleRanks[list[j].Role] {
+			return roleRanks[list[i].Role] > roleRanks[list[j].Role]
+		}
+		return list[i].TelegramID < list[j].TelegramID
+	})
+	return list, nil
+}
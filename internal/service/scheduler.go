@@ -2,12 +2,18 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
 	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/imagery"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
 	"github.com/robfig/cron/v3"
@@ -15,28 +21,100 @@ import (
 	tele "gopkg.in/telebot.v3"
 )
 
+// maxReminderCatchUp bounds how many missed minutes a tick replays (e.g., after the process was suspended)
+const maxReminderCatchUp = time.Hour
+
+// uvPeakBeforeHour is the hour before which daily reminders mention the UV peak
+const uvPeakBeforeHour = 12
+
+// Spread modes of the reminders due in the same minute (scheduler.spread)
+const (
+	ReminderSpreadOff        = "off"         // All at the start of the minute
+	ReminderSpreadSecond     = "second"      // At a fixed second of the minute per subscription
+	ReminderSpreadHalfMinute = "half_minute" // At :00 or :30 per subscription
+)
+
+// Callback button endpoints of AI reminders, handled by the bot
+const (
+	ReminderActionRegenerate = "regen_reminder" // "换一条"
+	ReminderActionVote       = "vote_reminder"  // Data: 1 (👍) or -1 (👎)
+	ReminderActionVoteReason = "vote_reason"    // Data: reason of a 👎 vote (model.VoteReason*)
+)
+
+// Errors of RegenerateReminder
+var (
+	ErrReminderExpired  = errors.New("only today's reminder can be regenerated")
+	ErrRegenerateFailed = errors.New("AI could not write a new reminder")
+)
+
 // SchedulerService handles scheduled tasks
 type SchedulerService struct {
-	cron        *cron.Cron
-	subRepo     *repository.SubscriptionRepository
-	weatherSvc  *WeatherService
-	todoSvc     *TodoService
-	aiSvc       *AIService
-	calendarSvc *CalendarService
-	warningSvc  *WarningService
-	bot         *tele.Bot
-	timezone    *time.Location
+	cron         *cron.Cron
+	subRepo      *repository.SubscriptionRepository
+	deliveryRepo *repository.DeliveryLogRepository
+	weatherSvc   *WeatherService
+	todoSvc      *TodoService
+	todoStats    *TodoStatsService // Todo streaks and badges shown in reminders (nil = disabled)
+	aiSvc        *AIService
+	calendarSvc  *CalendarService
+	warningSvc   *WarningService
+	webhookSvc   *WebhookService
+	notifySvc    *NotificationService
+	digestCache  *DigestCache
+	announceSvc  *AnnouncementService
+	indexWatch   *IndexWatchService     // Standalone life index pushes (nil = disabled)
+	snapshots    *SnapshotService       // Stored QWeather responses for the yesterday comparison (nil = disabled)
+	images       imagery.Provider       // Weather-matched images attached to reminders (nil = disabled)
+	preAlerts    *PreAlertService       // Evening forecast pre-alerts (nil = disabled)
+	advice       *AdviceService         // Dressing and seasonal care tips in reminders (nil = disabled)
+	experiments  *ExperimentService     // Reminder format experiments (nil = disabled)
+	health       *HealthReminderService // Private health reminders, sent at their own times (nil = disabled)
+	tone         *ToneService           // 👍/👎 votes on AI reminders and the tone hints learned from them (nil = disabled)
+	trivia       *TriviaService         // "今日冷知识" line closing daily reminders (nil = disabled)
+	observations *ObservationService    // Users' weather observations, cleaned up daily (nil = disabled)
+	jobs         *JobService            // Runs and records the scheduled jobs
+	sections     *SectionRegistry       // Digest sections of the reminders
+	reports      *ReportBuilder
+	timezone     *time.Location
+	spread       string               // How reminders due in the same minute are spread over it (ReminderSpread*)
+	events       *EventLogService     // nil when business events are disabled
+	feeds        *CalendarFeedService // Users' iCalendar feeds, synced daily (nil = disabled)
+
+	stopped  chan struct{} // Closed by Stop; reminders still waiting for their second are sent at once
+	stopOnce sync.Once
+
+	heartbeat atomic.Int64 // Unix nanoseconds of the last completed reminder tick
+
+	tickMu   sync.Mutex
+	lastTick time.Time // Last processed minute (absolute time)
+	lastWall time.Time // Latest processed local wall-clock minute, to skip repeats when DST ends
 }
 
 // NewSchedulerService creates a new SchedulerService
 func NewSchedulerService(
 	subRepo *repository.SubscriptionRepository,
+	deliveryRepo *repository.DeliveryLogRepository,
 	weatherSvc *WeatherService,
 	todoSvc *TodoService,
+	todoStats *TodoStatsService,
 	aiSvc *AIService,
 	calendarSvc *CalendarService,
 	warningSvc *WarningService,
-	bot *tele.Bot,
+	webhookSvc *WebhookService,
+	notifySvc *NotificationService,
+	digestCache *DigestCache,
+	announceSvc *AnnouncementService,
+	indexWatch *IndexWatchService,
+	snapshots *SnapshotService,
+	images imagery.Provider,
+	preAlerts *PreAlertService,
+	adviceSvc *AdviceService,
+	experiments *ExperimentService,
+	health *HealthReminderService,
+	tone *ToneService,
+	trivia *TriviaService,
+	observations *ObservationService,
+	jobs *JobService,
 	timezoneStr string,
 ) (*SchedulerService, error) {
 	loc, err := time.LoadLocation(timezoneStr)
@@ -46,21 +124,73 @@ func NewSchedulerService(
 
 	c := cron.New(cron.WithLocation(loc))
 
+	sections := NewSectionRegistry()
+	for _, section := range builtinSections(calendarSvc, warningSvc, todoSvc, todoStats, NewAirQualityService(weatherSvc.Client())) {
+		if err := sections.Register(section); err != nil {
+			return nil, err
+		}
+	}
+
 	return &SchedulerService{
-		cron:        c,
-		subRepo:     subRepo,
-		weatherSvc:  weatherSvc,
-		todoSvc:     todoSvc,
-		aiSvc:       aiSvc,
-		calendarSvc: calendarSvc,
-		warningSvc:  warningSvc,
-		bot:         bot,
-		timezone:    loc,
+		cron:         c,
+		subRepo:      subRepo,
+		deliveryRepo: deliveryRepo,
+		weatherSvc:   weatherSvc,
+		todoSvc:      todoSvc,
+		todoStats:    todoStats,
+		aiSvc:        aiSvc,
+		calendarSvc:  calendarSvc,
+		warningSvc:   warningSvc,
+		webhookSvc:   webhookSvc,
+		notifySvc:    notifySvc,
+		digestCache:  digestCache,
+		announceSvc:  announceSvc,
+		indexWatch:   indexWatch,
+		snapshots:    snapshots,
+		images:       images,
+		preAlerts:    preAlerts,
+		advice:       adviceSvc,
+		experiments:  experiments,
+		health:       health,
+		tone:         tone,
+		trivia:       trivia,
+		observations: observations,
+		jobs:         jobs,
+		sections:     sections,
+		reports:      NewReportBuilder(todoSvc),
+		timezone:     loc,
+		spread:       ReminderSpreadOff,
+		stopped:      make(chan struct{}),
 	}, nil
 }
 
+// Sections returns the registry of the reminders' digest sections, to which additional sections
+// can be registered
+func (s *SchedulerService) Sections() *SectionRegistry {
+	return s.sections
+}
+
+// SetReminderSpread sets how the reminders due in the same minute are spread over it
+// (ReminderSpread*), smoothing the bursts of API and Telegram requests at popular times
+func (s *SchedulerService) SetReminderSpread(mode string) {
+	s.spread = mode
+}
+
+// SetEventLog makes the scheduler emit a reminder_sent event for every reminder delivery
+func (s *SchedulerService) SetEventLog(events *EventLogService) {
+	s.events = events
+}
+
+// SetCalendarFeeds makes the scheduler sync the users' iCalendar feeds daily
+func (s *SchedulerService) SetCalendarFeeds(feeds *CalendarFeedService) {
+	s.feeds = feeds
+}
+
 // Start starts the scheduler
 func (s *SchedulerService) Start() error {
+	// Runs left running by a previous process will never finish
+	s.jobs.FailInterrupted(time.Now())
+
 	// Schedule a job every minute to check for reminders
 	_, err := s.cron.AddFunc("* * * * *", s.checkReminders)
 	if err != nil {
@@ -69,283 +199,726 @@ func (s *SchedulerService) Start() error {
 
 	// Schedule weather warning check every 15 minutes
 	if s.warningSvc != nil {
-		_, err = s.cron.AddFunc("*/15 * * * *", s.checkWarnings)
+		err = s.scheduleJob("*/15 * * * *", model.JobTypeWarningSweep, func(ctx context.Context, city string) (JobResult, error) {
+			logger.Debug("Checking weather warnings")
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+			defer cancel()
+			return s.warningSvc.CheckAndNotify(ctx, city)
+		})
 		if err != nil {
 			return fmt.Errorf("failed to add warning cron job: %w", err)
 		}
 		logger.Info("Warning check scheduled (every 15 minutes)")
+
+		// Refresh the warning type catalog daily and at startup
+		err = s.scheduleJob("50 4 * * *", model.JobTypeWarningTypes, func(ctx context.Context, city string) (JobResult, error) {
+			return JobResult{}, s.warningSvc.SyncWarningTypes()
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add warning type sync cron job: %w", err)
+		}
+		go s.jobs.Run(model.JobTypeWarningTypes, "")
+	}
+
+	// Analyze the forecast for sharp cooling, first snow and sustained heat the evening before
+	if s.preAlerts != nil {
+		err = s.scheduleJob("0 20 * * *", model.JobTypePreAlerts, func(ctx context.Context, city string) (JobResult, error) {
+			return s.preAlerts.CheckAndNotify(ctx, time.Now().In(s.timezone), city)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add pre-alert cron job: %w", err)
+		}
+		logger.Info("Forecast pre-alerts scheduled (daily at 20:00)")
 	}
 
+	// Deliver pending webhooks every 30 seconds and prune the outbox daily
+	if s.webhookSvc != nil {
+		_, err = s.cron.AddFunc("@every 30s", func() {
+			s.webhookSvc.ProcessOutbox(context.Background())
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add webhook cron job: %w", err)
+		}
+		err = s.scheduleJob("30 4 * * *", model.JobTypeWebhookCleanup, func(ctx context.Context, city string) (JobResult, error) {
+			s.webhookSvc.Cleanup(7 * 24 * time.Hour)
+			return JobResult{}, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add webhook cleanup cron job: %w", err)
+		}
+		logger.Info("Webhook outbox scheduled (every 30 seconds)")
+	}
+
+	// Deliver due admin announcements every minute
+	if s.announceSvc != nil {
+		_, err = s.cron.AddFunc("* * * * *", func() {
+			s.announceSvc.DeliverDue(time.Now())
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add announcement cron job: %w", err)
+		}
+	}
+
+	// Aggregate todo completion stats shortly after midnight; catch up on missed days at startup
+	if s.todoStats != nil {
+		err = s.scheduleJob("5 0 * * *", model.JobTypeTodoStats, func(ctx context.Context, city string) (JobResult, error) {
+			s.todoStats.AggregateRecent(time.Now())
+			return JobResult{}, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add todo stats cron job: %w", err)
+		}
+		go s.jobs.Run(model.JobTypeTodoStats, "")
+	}
+
+	// Prune stored QWeather responses past their retention daily
+	if s.snapshots != nil {
+		err = s.scheduleJob("40 4 * * *", model.JobTypeSnapshotCleanup, func(ctx context.Context, city string) (JobResult, error) {
+			s.snapshots.Cleanup(time.Now())
+			return JobResult{}, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add snapshot cleanup cron job: %w", err)
+		}
+	}
+
+	// Learn the tone hints of AI reminders from the users' votes daily
+	if s.tone != nil {
+		err = s.scheduleJob("10 4 * * *", model.JobTypeToneHints, func(ctx context.Context, city string) (JobResult, error) {
+			s.tone.AdjustHints(time.Now())
+			return JobResult{}, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add tone hint cron job: %w", err)
+		}
+	}
+
+	// Remove old weather observations daily
+	if s.observations != nil {
+		err = s.scheduleJob("20 4 * * *", model.JobTypeObservationCleanup, func(ctx context.Context, city string) (JobResult, error) {
+			s.observations.Cleanup(time.Now())
+			return JobResult{}, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add observation cleanup cron job: %w", err)
+		}
+	}
+
+	// Sync the users' calendar feeds before the morning reminders
+	if s.feeds != nil {
+		err = s.scheduleJob("30 5 * * *", model.JobTypeCalendarFeeds, func(ctx context.Context, city string) (JobResult, error) {
+			return s.feeds.SyncAll(ctx)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add calendar feed sync cron job: %w", err)
+		}
+	}
+
+	// Prune old job runs daily
+	err = s.scheduleJob("0 4 * * *", model.JobTypeJobCleanup, func(ctx context.Context, city string) (JobResult, error) {
+		n, err := s.jobs.Cleanup(time.Now())
+		return JobResult{Items: n}, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add job run cleanup cron job: %w", err)
+	}
+
+	s.heartbeat.Store(time.Now().UnixNano())
 	s.cron.Start()
 	logger.Info("Scheduler started")
 	return nil
 }
 
+// scheduleJob registers a job with the job service and runs it on the cron schedule, recording each run
+func (s *SchedulerService) scheduleJob(spec, jobType string, fn JobFunc) error {
+	s.jobs.Register(jobType, fn)
+	_, err := s.cron.AddFunc(spec, func() {
+		_, _ = s.jobs.Run(jobType, "")
+	})
+	return err
+}
+
 // Stop stops the scheduler
 func (s *SchedulerService) Stop() {
+	s.stopOnce.Do(func() { close(s.stopped) })
 	s.cron.Stop()
 	logger.Info("Scheduler stopped")
 }
 
 // checkReminders checks for subscriptions that need reminders at the current time
 func (s *SchedulerService) checkReminders() {
-	now := time.Now().In(s.timezone)
-	currentTime := now.Format("15:04")
+	s.CheckRemindersAt(time.Now())
+	s.heartbeat.Store(time.Now().UnixNano())
+}
 
-	subs, err := s.subRepo.GetByReminderTime(currentTime)
-	if err != nil {
-		logger.Error("Error getting subscriptions", zap.Error(err))
-		return
+// LastHeartbeat returns when the scheduler last completed its every-minute reminder check (or
+// started); a stale heartbeat means the scheduler is stuck
+func (s *SchedulerService) LastHeartbeat() time.Time {
+	return time.Unix(0, s.heartbeat.Load())
+}
+
+// CheckRemindersAt dispatches reminders for subscriptions due at the given time.
+// Every minute since the previous call is processed, so a delayed tick doesn't skip reminders;
+// wall-clock minutes skipped by a DST jump are processed too, and minutes repeated when DST ends
+// are processed only once. Calls for minutes that were already processed are ignored.
+// Reminders are composed for the time they were due rather than the time they are sent, so a
+// tick is deterministic for a given time; it is exposed so tests and tools can drive it.
+func (s *SchedulerService) CheckRemindersAt(at time.Time) {
+	// Reminders of the current minute wait for their offset within it; missed minutes are late already
+	current := wallClock(at.In(s.timezone))
+	elapsed := at.Sub(at.Truncate(time.Minute))
+
+	for _, minute := range s.dueReminderTimes(at) {
+		reminderTime := minute.Format("15:04")
+		logger.Debug("Checking reminders", zap.String("reminder_time", reminderTime))
+		due := at.In(s.timezone)
+		if !minute.Equal(current) {
+			due = time.Date(minute.Year(), minute.Month(), minute.Day(), minute.Hour(), minute.Minute(), 0, 0, s.timezone)
+		}
+
+		subs, err := s.subRepo.GetByReminderTime(reminderTime)
+		if err != nil {
+			logger.Error("Error getting subscriptions", zap.Error(err))
+			continue
+		}
+
+		for _, batch := range combinedBatches(subs) {
+			var delay time.Duration
+			if minute.Equal(current) {
+				delay = s.reminderOffset(batch[0].ID) - elapsed
+			}
+			if len(batch) > 1 {
+				s.dispatchReminder(func() { _ = s.sendCombinedReminder(batch, due) }, delay)
+			} else {
+				sub := batch[0]
+				s.dispatchReminder(func() { _ = s.sendReminder(sub, due) }, delay)
+			}
+		}
+
+		// Health reminders are sent on their own, never as part of the weather reminder
+		if s.health != nil {
+			go s.health.SendDue(reminderTime, due)
+		}
 	}
+}
 
-	for _, sub := range subs {
-		go s.sendReminder(sub)
+// reminderOffset returns the fixed offset of a subscription's reminder within its minute, the same
+// every day, so that subscriptions due at the same time don't all send in the same second
+func (s *SchedulerService) reminderOffset(subscriptionID uint) time.Duration {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strconv.FormatUint(uint64(subscriptionID), 10)))
+	switch s.spread {
+	case ReminderSpreadSecond:
+		return time.Duration(h.Sum32()%60) * time.Second
+	case ReminderSpreadHalfMinute:
+		return time.Duration(h.Sum32()%2) * 30 * time.Second
 	}
+	return 0
 }
 
-// checkWarnings checks for weather warnings and notifies subscribed users
-func (s *SchedulerService) checkWarnings() {
-	logger.Debug("Checking weather warnings")
+// dispatchReminder sends a reminder in the background after a delay
+func (s *SchedulerService) dispatchReminder(send func(), delay time.Duration) {
+	if delay <= 0 {
+		go send()
+		return
+	}
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-s.stopped:
+			// Send now rather than lose the reminder: the minute won't be replayed after a restart
+		}
+		send()
+	}()
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+// dueReminderTimes returns the wall-clock minutes (see wallClock) not yet processed up to the given
+// time, in order
+func (s *SchedulerService) dueReminderTimes(at time.Time) []time.Time {
+	s.tickMu.Lock()
+	defer s.tickMu.Unlock()
+
+	minute := at.Truncate(time.Minute)
+	start := minute
+	if !s.lastTick.IsZero() {
+		if !minute.After(s.lastTick) {
+			return nil
+		}
+		start = s.lastTick.Add(time.Minute)
+		if minute.Sub(start) >= maxReminderCatchUp {
+			logger.Warn("Scheduler fell behind, skipping missed minutes",
+				zap.Time("last_tick", s.lastTick),
+				zap.Time("now", minute))
+			start = minute.Add(-maxReminderCatchUp + time.Minute)
+		}
+	}
+	s.lastTick = minute
 
-	if err := s.warningSvc.CheckAndNotify(ctx); err != nil {
-		logger.Error("Failed to check warnings", zap.Error(err))
+	var times []time.Time
+	prev := wallClock(start.Add(-time.Minute).In(s.timezone))
+	if s.lastWall.After(prev) {
+		// Minutes repeated when DST ends were processed on their first occurrence
+		prev = s.lastWall
 	}
+	for m := start; !m.After(minute); m = m.Add(time.Minute) {
+		current := wallClock(m.In(s.timezone))
+		// Include wall-clock minutes skipped by a forward DST jump
+		for w := prev.Add(time.Minute); !w.After(current); w = w.Add(time.Minute) {
+			times = append(times, w)
+		}
+		if current.After(prev) {
+			prev = current
+		}
+	}
+	s.lastWall = prev
+	return times
 }
 
-// sendReminder sends a daily reminder to a user
-func (s *SchedulerService) sendReminder(sub model.Subscription) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
-	defer cancel()
-
-	now := time.Now().In(s.timezone)
+// wallClock returns the local wall-clock reading of t as a UTC time, so that minutes can be
+// compared and stepped without DST offsets
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC)
+}
 
-	// Get location ID and weather data
-	location, err := s.weatherSvc.Client().GetLocation(sub.City)
+// SendTestReminder immediately sends the reminder of a subscription, regardless of its schedule
+func (s *SchedulerService) SendTestReminder(subscriptionID uint) error {
+	sub, err := s.subRepo.FindByIDWithUser(subscriptionID)
 	if err != nil {
-		logger.Error("Failed to get location", zap.Uint("user_id", sub.UserID), zap.Error(err))
-		s.sendFallbackReminder(sub, now, fmt.Sprintf("⚠️ 无法获取 %s 的位置信息", sub.City))
-		return
+		return err
 	}
-	locationID := location.ID
+	if sub == nil {
+		return fmt.Errorf("subscription not found")
+	}
+
+	logger.Info("Sending test reminder",
+		zap.Uint("subscription_id", sub.ID),
+		zap.Int64("chat_id", sub.User.ChatID))
+	return s.sendReminder(*sub, time.Now().In(s.timezone))
+}
 
-	weather, err := s.weatherSvc.Client().GetCurrentWeather(locationID)
+// ResendReminder resends, with fresh data, the reminder delivered as the given Telegram message.
+// Returns false when the message is not a reminder of one of the user's subscriptions.
+func (s *SchedulerService) ResendReminder(chatID int64, messageID int, userID uint) (bool, error) {
+	if s.deliveryRepo == nil {
+		return false, nil
+	}
+	delivery, err := s.deliveryRepo.FindByMessage(chatID, messageID)
 	if err != nil {
-		logger.Error("Failed to get weather", zap.Uint("user_id", sub.UserID), zap.Error(err))
-		s.sendFallbackReminder(sub, now, fmt.Sprintf("⚠️ 无法获取 %s 的天气信息", sub.City))
-		return
+		return false, err
 	}
-
-	indices, err := s.weatherSvc.Client().GetLifeIndices(locationID)
+	if delivery == nil {
+		return false, nil
+	}
+	sub, err := s.subRepo.FindByIDWithUser(delivery.SubscriptionID)
 	if err != nil {
-		logger.Warn("Failed to get life indices", zap.Uint("user_id", sub.UserID), zap.Error(err))
-		indices = nil
+		return false, err
 	}
+	if sub == nil || !sub.Active || sub.UserID != userID {
+		return false, nil
+	}
+
+	logger.Info("Resending reminder",
+		zap.Uint("subscription_id", sub.ID),
+		zap.Int64("chat_id", chatID),
+		zap.Int("message_id", messageID))
+	return true, s.sendReminder(*sub, time.Now().In(s.timezone))
+}
 
-	// Get air quality (non-critical, failure won't interrupt)
-	airQuality, err := s.weatherSvc.Client().GetAirQualityCurrent(location.Lat, location.Lon)
+// RegenerateReminder asks the AI for a new version of today's reminder delivered as the given
+// Telegram message, returning the text and buttons to edit the message with. Returns false when
+// the message is not a reminder of one of the user's subscriptions.
+func (s *SchedulerService) RegenerateReminder(chatID int64, messageID int, userID uint) (string, *tele.ReplyMarkup, bool, error) {
+	if s.deliveryRepo == nil {
+		return "", nil, false, nil
+	}
+	delivery, err := s.deliveryRepo.FindByMessage(chatID, messageID)
+	if err != nil {
+		return "", nil, false, err
+	}
+	if delivery == nil {
+		return "", nil, false, nil
+	}
+	sub, err := s.subRepo.FindByIDWithUser(delivery.SubscriptionID)
 	if err != nil {
-		logger.Warn("Failed to get air quality", zap.Uint("user_id", sub.UserID), zap.Error(err))
-		airQuality = nil
+		return "", nil, false, err
+	}
+	if sub == nil || !sub.Active || sub.UserID != userID {
+		return "", nil, false, nil
 	}
 
-	// Get weather warnings (non-critical, failure won't interrupt)
-	var warnings []qweather.Warning
-	if s.warningSvc != nil {
-		warnings, err = s.weatherSvc.Client().GetWarningNow(locationID)
-		if err != nil {
-			logger.Warn("Failed to get warnings", zap.Uint("user_id", sub.UserID), zap.Error(err))
-			warnings = nil
-		}
+	now := time.Now().In(s.timezone)
+	if delivery.CreatedAt.In(s.timezone).Format("2006-01-02") != now.Format("2006-01-02") {
+		return "", nil, true, ErrReminderExpired
 	}
 
-	// Get incomplete todos
-	todos, err := s.todoSvc.GetIncompleteTodos(sub.ID)
-	if err != nil {
-		logger.Warn("Failed to get todos", zap.Uint("subscription_id", sub.ID), zap.Error(err))
-		todos = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	draft := s.composeReminder(ctx, *sub, now, true)
+	if !draft.aiWritten || len(notify.SplitText(draft.message, notify.TelegramTextLimit)) > 1 {
+		// The AI failed or is off for the subscription, or the new text no longer fits one message
+		return "", nil, true, ErrRegenerateFailed
 	}
+	markup := s.reminderMarkup(draft)
 
-	// Get calendar info
-	var calendarInfo string
-	if s.calendarSvc != nil {
-		calendarInfo = s.calendarSvc.FormatCalendarInfoForAI(now)
+	logger.Info("Regenerated reminder",
+		zap.Uint("subscription_id", sub.ID),
+		zap.Int64("chat_id", chatID),
+		zap.Int("message_id", messageID))
+	return draft.message, markup, true, nil
+}
+
+// reminderMarkup returns the buttons of a reminder written by the AI, or nil when it has none.
+// Long reminders are sent in several messages and cannot be edited in place, so they get none.
+func (s *SchedulerService) reminderMarkup(draft *reminderDraft) *tele.ReplyMarkup {
+	if !draft.aiWritten || len(notify.SplitText(draft.message, notify.TelegramTextLimit)) > 1 {
+		return nil
 	}
+	return ReminderMarkup(s.tone != nil, 0, s.aiSvc.RegenerateQuota() > 0)
+}
 
-	// Try to generate AI reminder
-	var message string
-	if s.aiSvc != nil && s.aiSvc.IsEnabled() {
-		data := ReminderData{
-			City:         sub.City,
-			Date:         now.Format("2006-01-02"),
-			Weather:      weather,
-			LifeIndices:  indices,
-			Todos:        todos,
-			CalendarInfo: calendarInfo,
-			AirQuality:   airQuality,
-			Warnings:     warnings,
+// ReminderMarkup builds the buttons of an AI reminder: 👍/👎 when votes are enabled, marking the
+// chat's vote (1, -1 or 0 = none), and "换一条" when regenerate is true. Returns nil without buttons.
+func ReminderMarkup(votes bool, vote int, regenerate bool) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	var buttons []tele.Btn
+	if votes {
+		up, down := "👍", "👎"
+		switch {
+		case vote > 0:
+			up += " ✓"
+		case vote < 0:
+			down += " ✓"
 		}
+		buttons = append(buttons,
+			markup.Data(up, ReminderActionVote, "1"),
+			markup.Data(down, ReminderActionVote, "-1"))
+	}
+	if regenerate {
+		buttons = append(buttons, markup.Data("换一条 🔁", ReminderActionRegenerate))
+	}
+	if len(buttons) == 0 {
+		return nil
+	}
+	markup.Inline(markup.Row(buttons...))
+	return markup
+}
 
-		aiContent, ok := s.aiSvc.GenerateReminder(ctx, data)
-		if ok {
-			message = aiContent
-		}
+// reminderDraft is a composed daily reminder, ready to be sent
+type reminderDraft struct {
+	message     string
+	report      *DailyReport
+	assignments []ExperimentAssignment
+	aiWritten   bool // Whether the AI wrote the reminder text
+}
+
+// sendReminder sends a daily reminder to a user, composed for now (local time). Data sources are
+// fetched independently, so the reminder degrades section by section (with placeholders) rather
+// than as a whole.
+func (s *SchedulerService) sendReminder(sub model.Subscription, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	draft := s.composeReminder(ctx, sub, now, false)
+	report := draft.report
+
+	// Send message to user; reminders without the current weather are logged as fallbacks
+	kind := model.DeliveryKindReminder
+	var photo []byte
+	if report.Weather != nil {
+		photo = s.weatherImage(ctx, report.Weather)
+	} else {
+		kind = model.DeliveryKindFallback
+	}
+	_, sendErr := s.deliver(sub, kind, draft.message, photo, s.reminderMarkup(draft))
+	if sendErr == nil && len(draft.assignments) > 0 {
+		s.experiments.RecordExposure(sub.UserID, draft.assignments)
 	}
 
-	// Fallback to fixed template if AI generation failed or disabled
-	if message == "" {
-		message = s.buildFallbackMessage(sub.City, weather, indices, airQuality, warnings, todos, now, s.aiSvc != nil && s.aiSvc.IsEnabled())
+	s.followUpReminder(ctx, sub, report, now)
+	return sendErr
+}
+
+// followUpReminder runs what follows a subscription's daily reminder: the life index reminders
+// whose level is met today and the city digest
+func (s *SchedulerService) followUpReminder(ctx context.Context, sub model.Subscription, report *DailyReport, now time.Time) {
+	// Push the life indices the user watches whose level is met today
+	if s.indexWatch != nil && len(report.Indices) > 0 {
+		s.indexWatch.Evaluate(ctx, sub, report.Indices, now)
 	}
 
-	// Send message to user
-	recipient := &tele.User{ID: sub.User.ChatID}
-	_, err = s.bot.Send(recipient, message)
-	if err != nil {
-		logger.Error("Error sending reminder", zap.Uint("user_id", sub.UserID), zap.Error(err))
+	// Publish the city digest (without personal todos) to the feed cache and broadcast targets,
+	// unless the current weather is missing; it always uses the regular format and default locale
+	// and leaves out the sections personal to this subscriber
+	if report.Weather == nil {
+		return
+	}
+	report.Style = ReportStyle{}
+	report.Locale = ""
+	report.Label = ""
+	report.dropPersonalSections()
+	digest := Digest{
+		City:        sub.City,
+		Date:        now.Format("2006-01-02"),
+		Title:       fmt.Sprintf("%s 每日天气 %s", sub.City, now.Format("2006-01-02")),
+		Body:        s.reports.RenderDigest(report),
+		GeneratedAt: now,
+	}
+	if s.digestCache != nil {
+		s.digestCache.Put(digest)
+	}
+	if s.notifySvc.WantsBroadcast(model.WebhookEventReminder, sub.City) &&
+		s.notifySvc.ClaimDigest(sub.City, digest.Date) {
+		s.notifySvc.Broadcast(ctx, model.WebhookEventReminder, sub.City, notify.Message{
+			Title: digest.Title,
+			Body:  digest.Body,
+		})
 	}
 }
 
-// buildFallbackMessage builds a fallback message using the fixed template
-func (s *SchedulerService) buildFallbackMessage(
-	city string,
-	weather *qweather.CurrentWeather,
-	indices []qweather.LifeIndex,
-	airQuality *qweather.AirQualityResponse,
-	warnings []qweather.Warning,
-	todos []model.Todo,
-	now time.Time,
-	aiWasEnabled bool,
-) string {
-	var report strings.Builder
+// composeReminder gathers the data of a subscription's daily reminder and writes it, with the AI
+// when enabled for the subscription. regenerate asks the AI for a new version rather than a cached one.
+func (s *SchedulerService) composeReminder(ctx context.Context, sub model.Subscription, now time.Time, regenerate bool) *reminderDraft {
+	aiMode := model.AIModeOff
+	if s.aiSvc != nil {
+		aiMode = s.aiSvc.ReminderMode(sub)
+	}
+	aiEnabled := aiMode != model.AIModeOff
 
-	// Date header with calendar info
-	report.WriteString("🌅 早安！今日提醒\n")
+	data := s.gatherReminderData(ctx, sub, now, aiEnabled)
 
-	// Weather warnings at the top (if any)
-	if len(warnings) > 0 {
-		report.WriteString("\n⚠️ 天气预警\n")
-		for _, w := range warnings {
-			emoji := getWarningEmojiFromColor(w.SeverityColor)
-			report.WriteString(fmt.Sprintf("%s %s\n", emoji, w.Title))
-		}
-		report.WriteString("\n")
+	// Assemble the report shared by the AI prompt and the fixed template
+	report := s.reports.Build(data)
+	if s.advice != nil {
+		report.Advice = s.advice.Tips(sub.City, data.forecast, report.Weather, now)
 	}
-	if s.calendarSvc != nil {
-		dateHeader := s.calendarSvc.FormatDateHeader(now)
-		report.WriteString(fmt.Sprintf("📆 %s\n", dateHeader))
 
-		todaySpecial := s.calendarSvc.FormatTodaySpecial(now)
-		if todaySpecial != "" {
-			report.WriteString(fmt.Sprintf("🎊 %s\n", todaySpecial))
-		}
-		report.WriteString("\n")
+	// Apply the user's variants of running format experiments
+	var assignments []ExperimentAssignment
+	if s.experiments != nil {
+		assignments, report.Style = s.experiments.Assign(sub.UserID)
+	}
+	report.Style.ToneHints = sub.User.ToneHints
 
-		// Upcoming festivals
-		upcomingFestivals := s.calendarSvc.FormatUpcomingFestivals(now, 3)
-		if upcomingFestivals != "" {
-			report.WriteString(upcomingFestivals)
-			report.WriteString("\n")
+	// Order the todos by the hourly forecast for the AI prompt (non-critical)
+	if aiMode == model.AIModeFull {
+		report.TodoPlan, _ = s.aiSvc.PlanTodos(ctx, sub.City, report.Todos, data.hourly, now)
+	}
+
+	// Try to generate AI reminder; the AI needs the current weather, so without it the
+	// template shows whatever sections are available
+	var message string
+	aiWritten := false
+	if aiEnabled && report.Weather != nil {
+		generate := s.aiSvc.GenerateReminder
+		if regenerate {
+			generate = s.aiSvc.RegenerateReminder
 		}
-	} else {
-		report.WriteString(fmt.Sprintf("📆 %s\n\n", now.Format("2006-01-02")))
-	}
-
-	report.WriteString(fmt.Sprintf("📍 %s 天气播报\n\n", city))
-	report.WriteString(fmt.Sprintf("🌡️ 温度：%s°C（体感 %s°C）\n", weather.Temp, weather.FeelsLike))
-	report.WriteString(fmt.Sprintf("☁️ 天气：%s\n", weather.Text))
-	report.WriteString(fmt.Sprintf("💧 湿度：%s%%\n", weather.Humidity))
-	report.WriteString(fmt.Sprintf("🌬️ 风向：%s %s级（%s km/h）\n\n", weather.WindDir, weather.WindScale, weather.WindSpeed))
-
-	// Add life indices
-	if len(indices) > 0 {
-		report.WriteString("📋 生活指数：\n")
-		for _, index := range indices {
-			if index.Type == "3" || index.Type == "5" || index.Type == "1" {
-				emoji := getIndexEmoji(index.Type)
-				report.WriteString(fmt.Sprintf("%s %s：%s\n", emoji, index.Name, index.Category))
-				if index.Text != "" {
-					report.WriteString(fmt.Sprintf("   %s\n", index.Text))
-				}
+		if aiContent, ok := generate(ctx, report, aiMode); ok {
+			message, aiWritten = aiContent, true
+			if appendix := s.reports.RenderAIAppendix(report); appendix != "" {
+				message += "\n\n" + appendix
 			}
 		}
-		report.WriteString("\n")
 	}
 
-	// Add air quality
-	if airQuality != nil && len(airQuality.Indexes) > 0 {
-		// Find primary index (prefer "qaqi" for China, or "us-epa", or first available)
-		var mainIndex qweather.AirQualityIndex
-		foundIndex := false
-		for _, idx := range airQuality.Indexes {
-			if idx.Code == "qaqi" {
-				mainIndex = idx
-				foundIndex = true
-				break
-			}
+	// Fallback to fixed template if AI generation failed or disabled
+	if message == "" {
+		message = s.reports.RenderTemplate(report, aiEnabled && report.Weather != nil)
+	}
+
+	// Append the hiking/sailing forecast for outdoor-oriented subscriptions (non-critical)
+	if sub.OutdoorKind != "" {
+		if section, err := s.weatherSvc.OutdoorDigest(sub, now); err != nil {
+			logger.Warn("Failed to get outdoor forecast", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+		} else if section != "" {
+			message += "\n\n" + section
 		}
-		if !foundIndex {
-			mainIndex = airQuality.Indexes[0]
+	}
+
+	// Append the drying advice for subscriptions that enabled it (non-critical)
+	if sub.Laundry {
+		if line, err := s.weatherSvc.LaundryDigest(sub, now); err != nil {
+			logger.Warn("Failed to get laundry forecast", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+		} else if line != "" {
+			message += "\n\n" + line
 		}
+	}
 
-		report.WriteString("🌫️ 空气质量：\n")
-		report.WriteString(fmt.Sprintf("   AQI：%.0f（%s）\n", mainIndex.Aqi, mainIndex.Category))
-		if mainIndex.PrimaryPollutant.Name != "" {
-			report.WriteString(fmt.Sprintf("   主要污染物：%s\n", mainIndex.PrimaryPollutant.Name))
+	// Compare today's forecast temperatures with yesterday's stored forecast (non-critical)
+	if s.snapshots != nil && data.forecast != nil {
+		if comparison := s.snapshots.CompareWithYesterday(data.target.LocationID(), data.forecast, now); comparison != "" {
+			message += "\n\n" + comparison
 		}
-		report.WriteString("\n")
 	}
 
-	// Add todo list
-	report.WriteString(s.todoSvc.FormatTodoList(todos))
+	// Close with today's trivia
+	if s.trivia != nil {
+		message += "\n\n" + applyEmojiStyle(s.trivia.Line(ctx, report.Weather, data.forecast, now), report.Style)
+	}
 
-	// Add AI service unavailable notice
-	if aiWasEnabled {
-		report.WriteString("\n---\n(AI 服务暂不可用，使用默认模板)")
+	return &reminderDraft{message: message, report: report, assignments: assignments, aiWritten: aiWritten}
+}
+
+// RenderSnapshot re-renders the weather part of a subscription's reminder from the QWeather
+// responses stored on date (YYYY-MM-DD), without calling QWeather or sending anything
+func (s *SchedulerService) RenderSnapshot(subscriptionID uint, date string) (string, error) {
+	if s.snapshots == nil {
+		return "", fmt.Errorf("api snapshots are disabled")
+	}
+	day, err := time.ParseInLocation("2006-01-02", date, s.timezone)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", date)
+	}
+	sub, err := s.subRepo.FindByIDWithUser(subscriptionID)
+	if err != nil {
+		return "", err
+	}
+	if sub == nil {
+		return "", fmt.Errorf("subscription not found")
 	}
 
-	return report.String()
+	// Only the built-in weather sections are re-rendered: the others would call live APIs
+	client := s.snapshots.ReplayClient(date)
+	target := resolveTarget(client, *sub, day)
+	if target.LocationErr != nil {
+		return "", fmt.Errorf("no stored location of %s on %s: %w", sub.City, date, target.LocationErr)
+	}
+	var sections []DigestSection
+	for _, section := range s.sections.Sections() {
+		switch section.Name() {
+		case sectionWarnings, sectionCalendar, sectionWeather, sectionIndices, sectionAir:
+			sections = append(sections, section)
+		}
+	}
+	data := &reminderData{target: target, sections: fetchSections(context.Background(), sections, target)}
+	report := s.reports.Build(data)
+	if report.Weather == nil {
+		return "", fmt.Errorf("no stored weather of %s on %s", sub.City, date)
+	}
+	report.Locale = ""
+	return s.reports.RenderDigest(report), nil
 }
 
-// sendFallbackReminder sends a simplified fallback reminder when weather data is unavailable
-func (s *SchedulerService) sendFallbackReminder(sub model.Subscription, now time.Time, errorMsg string) {
-	// Get todos even if weather failed
-	todos, _ := s.todoSvc.GetIncompleteTodos(sub.UserID)
-	todoReport := s.todoSvc.FormatTodoList(todos)
+// weatherImage returns the image matching the current weather, or nil when images are disabled or unavailable
+func (s *SchedulerService) weatherImage(ctx context.Context, weather *qweather.CurrentWeather) []byte {
+	if s.images == nil {
+		return nil
+	}
+	condition := imagery.Condition(weather.Icon, weather.Text)
+	img, err := s.images.Image(ctx, condition)
+	if err != nil {
+		logger.Warn("Failed to get weather image",
+			zap.String("condition", condition),
+			zap.Error(err))
+		return nil
+	}
+	return img.Data
+}
+
+// deliver sends a reminder message, preceded by the photo if any, and records the outcome in the
+// delivery log; it returns the Telegram message ID (0 when unknown)
+func (s *SchedulerService) deliver(sub model.Subscription, kind string, message string, photo []byte, markup *tele.ReplyMarkup) (int, error) {
+	messageID, sendErr := s.notifySvc.DeliverReminder(context.Background(), sub, notify.Message{
+		Title:  fmt.Sprintf("%s 每日提醒", sub.City),
+		Body:   message,
+		Photo:  photo,
+		Markup: markup,
+	})
+	if sendErr != nil {
+		logger.Error("Error sending reminder",
+			zap.Uint("user_id", sub.UserID),
+			zap.String("kind", kind),
+			zap.Error(sendErr))
+	}
 
-	var message strings.Builder
-	message.WriteString("🌅 早安！今日提醒\n")
+	s.recordDelivery(sub, kind, message, messageID, sendErr)
 
-	// Add calendar info
-	if s.calendarSvc != nil {
-		dateHeader := s.calendarSvc.FormatDateHeader(now)
-		message.WriteString(fmt.Sprintf("📆 %s\n", dateHeader))
+	if sendErr == nil && messageID != 0 && sub.PinReminder {
+		s.pinReminder(sub, messageID)
+	}
 
-		todaySpecial := s.calendarSvc.FormatTodaySpecial(now)
-		if todaySpecial != "" {
-			message.WriteString(fmt.Sprintf("🎊 %s\n", todaySpecial))
-		}
-		message.WriteString("\n")
+	if sendErr != nil {
+		return 0, fmt.Errorf("failed to send reminder: %w", sendErr)
+	}
+	return messageID, nil
+}
 
-		upcomingFestivals := s.calendarSvc.FormatUpcomingFestivals(now, 3)
-		if upcomingFestivals != "" {
-			message.WriteString(upcomingFestivals)
-			message.WriteString("\n")
+// recordDelivery records the outcome of a subscription's reminder in the delivery log and the
+// event log and publishes it to the user's webhooks
+func (s *SchedulerService) recordDelivery(sub model.Subscription, kind, message string, messageID int, sendErr error) {
+	if s.deliveryRepo != nil {
+		entry := &model.DeliveryLog{
+			SubscriptionID: sub.ID,
+			ChatID:         sub.User.ChatID,
+			MessageID:      messageID,
+			Kind:           kind,
+			Success:        sendErr == nil,
 		}
-	} else {
-		message.WriteString(fmt.Sprintf("📆 %s\n\n", now.Format("2006-01-02")))
+		if sendErr != nil {
+			entry.Error = truncateError(sendErr.Error(), 512)
+		}
+		if err := s.deliveryRepo.Create(entry); err != nil {
+			logger.Warn("Failed to record delivery", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+		}
+	}
+
+	if s.events != nil {
+		s.events.Emit(BusinessEvent{
+			Name:           model.EventReminderSent,
+			UserID:         sub.UserID,
+			SubscriptionID: sub.ID,
+			City:           sub.City,
+			Data:           map[string]any{"kind": kind, "delivered": sendErr == nil},
+		})
 	}
 
-	message.WriteString(errorMsg)
-	message.WriteString("\n\n")
-	message.WriteString(todoReport)
+	if s.webhookSvc != nil {
+		s.webhookSvc.Publish(model.WebhookEventReminder, []uint{sub.UserID}, ReminderEvent{
+			SubscriptionID: sub.ID,
+			ChatID:         sub.User.ChatID,
+			City:           sub.City,
+			Kind:           kind,
+			Message:        message,
+			Delivered:      sendErr == nil,
+		})
+	}
+}
 
-	recipient := &tele.User{ID: sub.User.ChatID}
-	_, err := s.bot.Send(recipient, message.String())
+// pinReminder pins a delivered reminder in place of the previous one. Pinning is turned off
+// for the subscription, with a notice to the chat, when the bot lacks the right to pin.
+func (s *SchedulerService) pinReminder(sub model.Subscription, messageID int) {
+	err := s.notifySvc.PinReminder(sub, messageID)
+	if errors.Is(err, ErrPinNotAllowed) {
+		logger.Warn("Not allowed to pin reminder, disabling pinning",
+			zap.Uint("subscription_id", sub.ID),
+			zap.Int64("chat_id", sub.User.ChatID))
+		if err := s.subRepo.SetPinReminder(sub.ID, false); err != nil {
+			return
+		}
+		notice := fmt.Sprintf("⚠️ 机器人没有置顶消息的权限，已关闭 %s 每日提醒的置顶\n授予「置顶消息」管理员权限后，可使用 /pin %s on 重新开启", sub.City, sub.City)
+		if err := s.notifySvc.Notice(sub, notice); err != nil {
+			logger.Warn("Failed to send pin notice", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+		}
+		return
+	}
 	if err != nil {
-		logger.Error("Error sending fallback reminder", zap.Uint("user_id", sub.UserID), zap.Error(err))
+		logger.Warn("Failed to pin reminder", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+		return
+	}
+	if err := s.subRepo.SetPinnedMessage(sub.ID, messageID); err != nil {
+		logger.Warn("Failed to record pinned reminder", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+	}
+}
+
+// truncateError shortens an error message to fit the delivery log column
+func truncateError(msg string, max int) string {
+	runes := []rune(msg)
+	if len(runes) <= max {
+		return msg
 	}
+	return string(runes[:max])
 }
 
 // getWarningEmojiFromColor returns an emoji based on warning severity color
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// wallMinutes returns the local minutes from..to (inclusive) of a day, formatted as "01-02 15:04"
+func wallMinutes(day, from, to string) []string {
+	start, _ := time.Parse("2006-01-02 15:04", day+" "+from)
+	end, _ := time.Parse("2006-01-02 15:04", day+" "+to)
+	var minutes []string
+	for m := start; !m.After(end); m = m.Add(time.Minute) {
+		minutes = append(minutes, m.Format("01-02 15:04"))
+	}
+	return minutes
+}
+
+// everyMinute returns the instants from..to (inclusive, UTC "2006-01-02 15:04") a minute apart
+func everyMinute(from, to string) []time.Time {
+	start, _ := time.Parse("2006-01-02 15:04", from)
+	end, _ := time.Parse("2006-01-02 15:04", to)
+	var ticks []time.Time
+	for t := start; !t.After(end); t = t.Add(time.Minute) {
+		ticks = append(ticks, t)
+	}
+	return ticks
+}
+
+func utc(value string) time.Time {
+	t, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func concat(lists ...[]string) []string {
+	var all []string
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+	return all
+}
+
+func TestDueReminderTimesAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		ticks []time.Time // Absolute times of the scheduler ticks
+		want  []string    // Local minutes processed, in order
+	}{
+		{
+			// 2025-03-09 02:00 EST jumps to 03:00 EDT (07:00 UTC): the 02:xx minutes never occur
+			// on the clock but their reminders must still fire, once
+			name:  "spring forward",
+			ticks: everyMinute("2025-03-09 06:58", "2025-03-09 07:01"),
+			want:  wallMinutes("2025-03-09", "01:58", "03:01"),
+		},
+		{
+			// 2025-11-02 02:00 EDT falls back to 01:00 EST (06:00 UTC): the 01:xx minutes occur
+			// twice but their reminders fire once
+			name:  "fall back",
+			ticks: everyMinute("2025-11-02 04:58", "2025-11-02 07:01"),
+			want:  wallMinutes("2025-11-02", "00:58", "02:01"),
+		},
+		{
+			// A tick delayed under load replays every minute since the previous one
+			name:  "delayed tick",
+			ticks: []time.Time{utc("2025-06-01 14:00:00"), utc("2025-06-01 14:07:30"), utc("2025-06-01 14:08:05")},
+			want:  wallMinutes("2025-06-01", "10:00", "10:08"),
+		},
+		{
+			name:  "delayed tick across spring forward",
+			ticks: []time.Time{utc("2025-03-09 06:55:00"), utc("2025-03-09 07:05:10")},
+			want:  wallMinutes("2025-03-09", "01:55", "03:05"),
+		},
+		{
+			// The tick at 06:10 UTC (01:10 EST) falls in the repeated hour; the next one must not
+			// replay the repeated minutes either
+			name:  "delayed tick across fall back",
+			ticks: []time.Time{utc("2025-11-02 05:50:00"), utc("2025-11-02 06:10:00"), utc("2025-11-02 07:00:00")},
+			want:  concat(wallMinutes("2025-11-02", "01:50", "01:59"), wallMinutes("2025-11-02", "02:00", "02:00")),
+		},
+		{
+			// Repeated and earlier ticks are ignored
+			name:  "repeated tick",
+			ticks: []time.Time{utc("2025-06-01 14:00:10"), utc("2025-06-01 14:00:50"), utc("2025-06-01 13:59:00")},
+			want:  wallMinutes("2025-06-01", "10:00", "10:00"),
+		},
+		{
+			// Gaps longer than maxReminderCatchUp only replay the last hour
+			name:  "long gap",
+			ticks: []time.Time{utc("2025-06-01 10:00:00"), utc("2025-06-01 14:00:00")},
+			want:  concat(wallMinutes("2025-06-01", "06:00", "06:00"), wallMinutes("2025-06-01", "09:01", "10:00")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SchedulerService{timezone: loc}
+			var got []string
+			for _, tick := range tt.ticks {
+				for _, minute := range s.dueReminderTimes(tick) {
+					got = append(got, minute.Format("01-02 15:04"))
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("processed %d minutes %v\nwant %d minutes %v", len(got), got, len(tt.want), tt.want)
+			}
+			seen := make(map[string]bool)
+			for _, minute := range got {
+				if seen[minute] {
+					t.Errorf("minute %s processed twice", minute)
+				}
+				seen[minute] = true
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/config"
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/testutil"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	_ = logger.Init(&config.LoggerConfig{Level: "fatal"})
+	os.Exit(m.Run())
+}
+
+// sendReminder sends a subscription's reminder and returns its text
+func sendReminder(t *testing.T, h *testutil.Harness, subID uint) string {
+	t.Helper()
+	h.Telegram.Reset()
+	if err := h.Scheduler.SendTestReminder(subID); err != nil {
+		t.Fatalf("SendTestReminder: %v", err)
+	}
+	sent := h.Telegram.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	return sent[0].Text
+}
+
+// Todos belong to subscriptions, so both the normal reminder and the fallback without the current
+// weather must list the subscription's todos, never those found by the user's ID
+func TestReminderTodosAreSubscriptionScoped(t *testing.T) {
+	h, err := testutil.NewHarness()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+
+	other, err := h.UserRepo.GetOrCreate("", 41)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := h.UserRepo.GetOrCreate("", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := &model.Subscription{UserID: user.ID, City: "北京", ReminderTime: "08:00", Active: true}
+	if err := h.SubRepo.Create(sub); err != nil {
+		t.Fatal(err)
+	}
+	// The other user's subscription gets the ID equal to the user's ID
+	otherSub := &model.Subscription{UserID: other.ID, City: "北京", ReminderTime: "09:00", Active: true}
+	if err := h.SubRepo.Create(otherSub); err != nil {
+		t.Fatal(err)
+	}
+	if sub.ID == user.ID || otherSub.ID != user.ID {
+		t.Fatalf("subscription IDs %d/%d must differ from/equal user ID %d", sub.ID, otherSub.ID, user.ID)
+	}
+	for _, todo := range []*model.Todo{
+		{SubscriptionID: sub.ID, Content: "买牛奶"},
+		{SubscriptionID: sub.ID, Content: "交电费"},
+		{SubscriptionID: otherSub.ID, Content: "别人的待办"},
+	} {
+		if err := h.TodoRepo.Create(todo); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	normal := sendReminder(t, h, sub.ID)
+	h.QWeather.Override("/v7/weather/now", []byte("{bad"))
+	fallback := sendReminder(t, h, sub.ID)
+	if !strings.Contains(fallback, "实时天气暂时无法获取") {
+		t.Fatalf("weather failure did not produce the fallback reminder:\n%s", fallback)
+	}
+
+	if got, want := todoLines(fallback), todoLines(normal); got != want {
+		t.Errorf("fallback todos = %q, normal todos = %q", got, want)
+	}
+	for name, text := range map[string]string{"normal": normal, "fallback": fallback} {
+		for _, want := range []string{"⬜ 买牛奶", "⬜ 交电费"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("%s reminder lacks %q:\n%s", name, want, text)
+			}
+		}
+		if strings.Contains(text, "别人的待办") {
+			t.Errorf("%s reminder lists another subscription's todo:\n%s", name, text)
+		}
+	}
+}
+
+// todoLines returns the todo lines of a reminder
+func todoLines(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.Contains(line, "⬜") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+const (
+	// replayBaseURL is the base URL of replay clients; requests never leave the process
+	replayBaseURL = "http://snapshot.replay"
+	// dailyForecastPath is the API path of the daily forecast compared with yesterday's
+	dailyForecastPath = "/v7/weather/3d"
+)
+
+// SnapshotService stores the raw QWeather responses of each day and replays them, for the
+// yesterday comparison, offline re-rendering of reminders and debugging
+type SnapshotService struct {
+	repo      *repository.APISnapshotRepository
+	retention int // Days snapshots are kept
+	timezone  *time.Location
+}
+
+// NewSnapshotService creates a new SnapshotService
+func NewSnapshotService(repo *repository.APISnapshotRepository, retention int, timezone *time.Location) *SnapshotService {
+	return &SnapshotService{repo: repo, retention: retention, timezone: timezone}
+}
+
+// Repository returns the underlying snapshot repository
+func (s *SnapshotService) Repository() *repository.APISnapshotRepository {
+	return s.repo
+}
+
+// RecordResponse stores a response body as today's snapshot of the request
+func (s *SnapshotService) RecordResponse(request, location string, body []byte) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		logger.Warn("Failed to compress api snapshot", zap.String("request", request), zap.Error(err))
+		return
+	}
+	if err := zw.Close(); err != nil {
+		logger.Warn("Failed to compress api snapshot", zap.String("request", request), zap.Error(err))
+		return
+	}
+
+	snapshot := &model.APISnapshot{
+		Date:     s.Today(),
+		Request:  request,
+		Location: location,
+		Payload:  buf.Bytes(),
+		Size:     len(body),
+	}
+	if err := s.repo.Upsert(snapshot); err != nil {
+		logger.Warn("Failed to save api snapshot", zap.String("request", request), zap.Error(err))
+	}
+}
+
+// Today returns the current date (YYYY-MM-DD) that new snapshots are stored under
+func (s *SnapshotService) Today() string {
+	return time.Now().In(s.timezone).Format("2006-01-02")
+}
+
+// Payload returns the decompressed body of a snapshot
+func (s *SnapshotService) Payload(snapshot *model.APISnapshot) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(snapshot.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress api snapshot: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	body, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress api snapshot: %w", err)
+	}
+	return body, nil
+}
+
+// ReplayClient returns a QWeather client answering every request with the snapshot recorded on
+// date (YYYY-MM-DD); requests without a snapshot fail as not found
+func (s *SnapshotService) ReplayClient(date string) *qweather.Client {
+	return qweather.NewClientWithTransport(replayBaseURL, &replayTransport{snapshots: s, date: date})
+}
+
+// Cleanup removes snapshots older than the retention
+func (s *SnapshotService) Cleanup(now time.Time) {
+	cutoff := now.In(s.timezone).AddDate(0, 0, -s.retention).Format("2006-01-02")
+	if err := s.repo.DeleteBefore(cutoff); err != nil {
+		logger.Warn("Failed to clean up api snapshots", zap.Error(err))
+	}
+}
+
+// CompareWithYesterday describes how today's forecast temperatures differ from yesterday's, using
+// yesterday's snapshot of the daily forecast; returns "" when there is no snapshot
+func (s *SnapshotService) CompareWithYesterday(locationID string, today *qweather.DailyForecast, now time.Time) string {
+	yesterday := now.In(s.timezone).AddDate(0, 0, -1).Format("2006-01-02")
+	if !s.hasSnapshot(yesterday, locationID, dailyForecastPath) {
+		return ""
+	}
+	previous, err := s.ReplayClient(yesterday).GetDailyForecast(locationID)
+	if err != nil || previous.FxDate == today.FxDate {
+		logger.Debug("Unusable forecast snapshot of yesterday", zap.String("location_id", locationID), zap.Error(err))
+		return ""
+	}
+	return fmt.Sprintf("📊 较昨日：最高 %s°C（%s），最低 %s°C（%s）",
+		today.TempMax, temperatureDelta(today.TempMax, previous.TempMax),
+		today.TempMin, temperatureDelta(today.TempMin, previous.TempMin))
+}
+
+// hasSnapshot reports whether a response of the API path for the location was stored on date
+func (s *SnapshotService) hasSnapshot(date, location, path string) bool {
+	snapshots, err := s.repo.List(date, location)
+	if err != nil {
+		logger.Warn("Failed to list api snapshots", zap.String("date", date), zap.Error(err))
+		return false
+	}
+	for _, snapshot := range snapshots {
+		if strings.HasPrefix(snapshot.Request, path+"?") {
+			return true
+		}
+	}
+	return false
+}
+
+// temperatureDelta formats the difference between two temperatures, e.g. "+3°C" or "持平"
+func temperatureDelta(current, previous string) string {
+	cur, err := strconv.Atoi(current)
+	if err != nil {
+		return "-"
+	}
+	prev, err := strconv.Atoi(previous)
+	if err != nil {
+		return "-"
+	}
+	switch diff := cur - prev; {
+	case diff > 0:
+		return fmt.Sprintf("+%d°C", diff)
+	case diff < 0:
+		return fmt.Sprintf("%d°C", diff)
+	default:
+		return "持平"
+	}
+}
+
+// replayTransport answers QWeather requests from the snapshots of one day
+type replayTransport struct {
+	snapshots *SnapshotService
+	date      string
+}
+
+// RoundTrip returns the recorded body of the request, or a QWeather-style 404 body when none exists
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	request, _ := qweather.RequestKey(req.URL)
+	status, body := http.StatusOK, []byte(nil)
+	snapshot, err := t.snapshots.repo.Find(t.date, request)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil {
+		if body, err = t.snapshots.Payload(snapshot); err != nil {
+			return nil, err
+		}
+	} else {
+		logger.Debug("No api snapshot for replayed request", zap.String("date", t.date), zap.String("request", request))
+		status, body = http.StatusNotFound, []byte(`{"code":"404"}`)
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
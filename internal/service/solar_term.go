@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/calendar"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/jieqi"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// sectionSolarTerm is the name of the digest section with the care tip of a solar term day
+const sectionSolarTerm = "solar_term"
+
+// solarTermTipMaxRunes limits an AI-rephrased tip; longer replies keep the curated text
+const solarTermTipMaxRunes = 80
+
+// SolarTermService is the digest section shown after the calendar on solar term days, with a
+// seasonal care tip (谷雨养生) picked from the curated tips. When the AI is enabled it rephrases
+// the tip once per term and day, so the tip reads the same for everyone that day.
+type SolarTermService struct {
+	calculator *calendar.Calculator
+	aiSvc      *AIService // Rephrases the tips (nil or disabled = the curated text)
+
+	mu    sync.Mutex
+	cache map[string]string // Rephrased tips keyed by term and date
+	group singleflight.Group
+}
+
+// solarTermContent is the fetched solar term section of a reminder
+type solarTermContent struct {
+	term string
+	tip  string
+}
+
+// NewSolarTermService creates a new SolarTermService
+func NewSolarTermService(aiSvc *AIService, timezone *time.Location) *SolarTermService {
+	return &SolarTermService{
+		calculator: calendar.NewCalculator(timezone),
+		aiSvc:      aiSvc,
+		cache:      make(map[string]string),
+	}
+}
+
+// Name implements DigestSection
+func (s *SolarTermService) Name() string {
+	return sectionSolarTerm
+}
+
+// After implements SectionAnchor: the tip follows the calendar, which names the solar term
+func (s *SolarTermService) After() string {
+	return sectionCalendar
+}
+
+// Fetch implements DigestSection: it returns the tip of today's solar term, or nil on other days
+func (s *SolarTermService) Fetch(ctx context.Context, target *SectionTarget) (SectionContent, error) {
+	term := s.calculator.GetTodayJieQi(target.Now)
+	if _, ok := jieqi.Lookup(term); !ok {
+		return nil, nil
+	}
+	return &solarTermContent{term: term, tip: s.Tip(ctx, term, target.Now.Format("2006-01-02"))}, nil
+}
+
+// Render writes the tip
+func (c *solarTermContent) Render(string) string {
+	return fmt.Sprintf("🌿 %s养生：%s\n\n", c.term, c.tip)
+}
+
+// Tip returns the care tip of a solar term on a date (YYYY-MM-DD), rephrased by the AI when
+// enabled, or "" for an unknown term
+func (s *SolarTermService) Tip(ctx context.Context, term, date string) string {
+	tip := jieqi.Pick(term, date)
+	if tip == "" || s.aiSvc == nil || !s.aiSvc.IsEnabled() {
+		return tip
+	}
+
+	key := term + "|" + date
+	s.mu.Lock()
+	text, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok {
+		return text
+	}
+
+	v, _, _ := s.group.Do(key, func() (interface{}, error) {
+		text, err := s.aiSvc.RephraseSolarTermTip(ctx, term, tip)
+		if err != nil {
+			logger.Warn("Failed to rephrase solar term tip, using the curated text",
+				zap.String("term", term),
+				zap.Error(err))
+			// Not cached, so the next reminder tries again
+			return tip, nil
+		}
+
+		s.mu.Lock()
+		// Drop tips older than the previous day, which users in other timezones may still need
+		if day, err := time.Parse("2006-01-02", date); err == nil {
+			oldest := day.AddDate(0, 0, -1).Format("2006-01-02")
+			for k := range s.cache {
+				if k[strings.LastIndex(k, "|")+1:] < oldest {
+					delete(s.cache, k)
+				}
+			}
+		}
+		s.cache[key] = text
+		s.mu.Unlock()
+		return text, nil
+	})
+	return v.(string)
+}
+
+// Current returns the solar term the date falls in and the day it started
+func (s *SolarTermService) Current(now time.Time) (string, time.Time) {
+	return s.calculator.GetPrevJieQi(now)
+}
+
+// Next returns the first solar term after the date and the day it starts
+func (s *SolarTermService) Next(now time.Time) (string, time.Time) {
+	return s.calculator.GetNextJieQi(now)
+}
+
+// NextDate returns the day a solar term next starts on or after the date
+func (s *SolarTermService) NextDate(term string, now time.Time) time.Time {
+	return s.calculator.NextJieQiDate(term, now)
+}
+
+// solarTermSystemPrompt instructs the AI to rephrase a solar term tip
+const solarTermSystemPrompt = `你是节气养生小编，为用户的每日提醒改写一条节气养生提示。
+用一句亲切自然的中文重新表述给出的提示（不超过 50 字），保留原意，不要添加原文没有的建议、食疗功效或医疗说法，不要使用 Markdown、不要加引号或前缀，只输出这句话。`
+
+// RephraseSolarTermTip rephrases a curated solar term tip in a warmer tone, keeping its advice
+func (s *AIService) RephraseSolarTermTip(ctx context.Context, term, tip string) (string, error) {
+	content, err := s.complete(ctx, solarTermSystemPrompt, fmt.Sprintf("节气：%s\n提示：%s", term, tip))
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(strings.ReplaceAll(content, "\n", ""))
+	switch {
+	case text == "":
+		return "", fmt.Errorf("empty tip")
+	case utf8.RuneCountInString(text) > solarTermTipMaxRunes:
+		return "", fmt.Errorf("tip too long")
+	case containsLink(text):
+		return "", fmt.Errorf("tip contains a link")
+	}
+	return text, nil
+}
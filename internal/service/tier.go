@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// TierLimits holds the limits of a service tier
+type TierLimits struct {
+	Subscriptions int // Active subscriptions
+	Regenerations int // "换一条" regenerations of AI reminders a day
+	IndexWatches  int // Life index alerts
+	APICalls      int // On-demand weather queries and AI requests a day
+}
+
+// TierService resolves the limits of users' service tiers (free/premium), letting operators who
+// offer the bot publicly control costs, and changes tiers
+type TierService struct {
+	userRepo *repository.UserRepository
+	limits   map[string]TierLimits
+}
+
+// NewTierService creates a new TierService
+func NewTierService(userRepo *repository.UserRepository, free, premium TierLimits) *TierService {
+	return &TierService{
+		userRepo: userRepo,
+		limits:   map[string]TierLimits{model.TierFree: free, model.TierPremium: premium},
+	}
+}
+
+// Limits returns the limits of a user's tier at the given time
+func (s *TierService) Limits(user *model.User, now time.Time) TierLimits {
+	return s.limits[user.EffectiveTier(now)]
+}
+
+// LimitsOf returns the limits of a tier
+func (s *TierService) LimitsOf(tier string) TierLimits {
+	return s.limits[tier]
+}
+
+// ClaimAPICall counts an on-demand external API call of a user against the daily budget of the
+// user's tier, returning false and the budget when it is already used up. The count is kept for
+// the date of now, which should be in the bot's timezone.
+func (s *TierService) ClaimAPICall(user *model.User, now time.Time) (bool, int, error) {
+	limit := s.Limits(user, now).APICalls
+	ok, err := s.userRepo.ClaimAPICall(user.ID, now.Format("2006-01-02"), limit)
+	if err != nil {
+		return false, limit, err
+	}
+	if !ok {
+		logger.Debug("API budget used up", zap.Uint("user_id", user.ID), zap.Int("limit", limit))
+	}
+	return ok, limit, nil
+}
+
+// SetTier changes a user's tier; a premium tier lasts the given number of days (0 = does not end)
+func (s *TierService) SetTier(user *model.User, tier string, days int, now time.Time) error {
+	if _, ok := s.limits[tier]; !ok {
+		return fmt.Errorf("unknown tier %q", tier)
+	}
+	var until *time.Time
+	if tier == model.TierPremium && days > 0 {
+		end := now.AddDate(0, 0, days)
+		until = &end
+	}
+	if err := s.userRepo.SetTier(user.ID, tier, until); err != nil {
+		return err
+	}
+	user.Tier, user.TierUntil = tier, until
+	logger.Info("User tier changed",
+		zap.Uint("user_id", user.ID),
+		zap.String("tier", tier),
+		zap.Int("days", days))
+	return nil
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/cuichanghe/daily-reminder-bot/internal/model"
 	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
@@ -21,10 +22,10 @@ func NewTodoService(todoRepo *repository.TodoRepository) *TodoService {
 }
 
 // AddTodo adds a new todo item for a subscription
-func (s *TodoService) AddTodo(subscriptionID uint, content string) error {
+func (s *TodoService) AddTodo(subscriptionID uint, content string) (*model.Todo, error) {
 	logger.Debug("AddTodo called",
 		zap.Uint("subscription_id", subscriptionID),
-		zap.String("content", content))
+		zap.Int("content_len", len(content)))
 
 	todo := &model.Todo{
 		SubscriptionID: subscriptionID,
@@ -33,15 +34,15 @@ func (s *TodoService) AddTodo(subscriptionID uint, content string) error {
 	if err := s.todoRepo.Create(todo); err != nil {
 		logger.Error("Failed to add todo",
 			zap.Uint("subscription_id", subscriptionID),
-			zap.String("content", content),
+			zap.Int("content_len", len(content)),
 			zap.Error(err))
-		return err
+		return nil, err
 	}
 
 	logger.Info("Todo added successfully",
 		zap.Uint("subscription_id", subscriptionID),
 		zap.Uint("todo_id", todo.ID))
-	return nil
+	return todo, nil
 }
 
 // GetSubscriptionTodos retrieves all todos for a subscription
@@ -80,6 +81,25 @@ func (s *TodoService) GetIncompleteTodos(subscriptionID uint) ([]model.Todo, err
 	return todos, nil
 }
 
+// SubscriptionTodos is a subscription with the todos of its list
+type SubscriptionTodos struct {
+	Subscription model.Subscription
+	Todos        []model.Todo
+}
+
+// SubscriptionLists returns the todos of each subscription's list (the shared list for members)
+func (s *TodoService) SubscriptionLists(subs []model.Subscription) ([]SubscriptionTodos, error) {
+	lists := make([]SubscriptionTodos, 0, len(subs))
+	for _, sub := range subs {
+		todos, err := s.GetSubscriptionTodos(sub.TodoListID())
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, SubscriptionTodos{Subscription: sub, Todos: todos})
+	}
+	return lists, nil
+}
+
 // CompleteTodo marks a todo as completed
 func (s *TodoService) CompleteTodo(todoID uint, userID uint) error {
 	logger.Debug("CompleteTodo called",
@@ -106,7 +126,10 @@ func (s *TodoService) CompleteTodo(todoID uint, userID uint) error {
 		return fmt.Errorf("todo not found")
 	}
 
+	now := time.Now()
 	todo.Completed = true
+	todo.CompletedAt = &now
+	todo.CompletedBy = &userID
 	if err := s.todoRepo.Update(todo); err != nil {
 		logger.Error("Failed to complete todo",
 			zap.Uint("todo_id", todoID),
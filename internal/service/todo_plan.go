@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+const (
+	// planForecastHours is how many hours of forecast the todo planner sees
+	planForecastHours = 16
+	// maxPlanNoteRunes caps the length of a todo annotation
+	maxPlanNoteRunes = 40
+)
+
+// TodoPlanItem is a todo with an optional weather-based suggestion
+type TodoPlanItem struct {
+	Todo model.Todo
+	Note string
+}
+
+// TodoPlan is the day's todos in the order suggested by the AI
+type TodoPlan struct {
+	Items []TodoPlanItem
+}
+
+// todoPlanResponse is the JSON the model is asked to reply with
+type todoPlanResponse struct {
+	Items []struct {
+		Index int    `json:"index"`
+		Note  string `json:"note"`
+	} `json:"items"`
+}
+
+// TodoPlanningEnabled returns whether todos should be ordered and annotated by the AI
+func (s *AIService) TodoPlanningEnabled() bool {
+	return s.IsEnabled() && s.todoPlanning
+}
+
+// PlanTodos asks the AI to order the day's todos and annotate those affected by the weather.
+// Returns the plan and a boolean indicating success.
+func (s *AIService) PlanTodos(ctx context.Context, city string, todos []model.Todo, hourly []qweather.HourlyForecast, now time.Time) (*TodoPlan, bool) {
+	if !s.TodoPlanningEnabled() || len(todos) == 0 || len(hourly) == 0 {
+		return nil, false
+	}
+
+	content, err := s.completeCached(ctx, s.client, buildTodoPlanSystemPrompt(), buildTodoPlanUserPrompt(city, todos, hourly, now))
+	if err != nil {
+		logger.Warn("AI todo planning unavailable", zap.Error(err))
+		return nil, false
+	}
+
+	plan, err := parseTodoPlan(content, todos)
+	if err != nil {
+		logger.Warn("Failed to parse AI todo plan",
+			zap.Int("content_len", len(content)),
+			zap.Error(err))
+		return nil, false
+	}
+
+	logger.Debug("AI todo plan generated",
+		zap.String("city", city),
+		zap.Int("todos", len(todos)))
+	return plan, true
+}
+
+// buildTodoPlanSystemPrompt builds the system prompt for todo planning
+func buildTodoPlanSystemPrompt() string {
+	return `你是一个日程安排助手。根据逐小时天气预报，为用户今天的待办事项排出合理的先后顺序，并为受天气影响的事项给出简短建议。
+
+要求：
+1. 受天气影响的户外事项（如遛狗、跑步、晾衣服、洗车）应安排在天气合适的时段，例如"上午有雨，建议改到下午"
+2. 不受天气影响的事项保持原有相对顺序，无需建议
+3. 每条建议不超过 30 个字，使用中文，不要使用 emoji
+4. 只输出 JSON，不要输出其他内容，格式：{"items":[{"index":待办编号,"note":"建议，可为空字符串"}]}
+5. items 必须包含全部待办编号，每个编号只出现一次
+6. ` + userContentRule
+}
+
+// buildTodoPlanUserPrompt builds the user prompt with the hourly forecast and numbered todos
+func buildTodoPlanUserPrompt(city string, todos []model.Todo, hourly []qweather.HourlyForecast, now time.Time) string {
+	lines := make([]string, 0, len(todos))
+	for i, todo := range todos {
+		lines = append(lines, fmt.Sprintf("%d. %s", i+1, sanitizeUserText(todo.Content, maxPromptTodoRunes)))
+	}
+
+	return fmt.Sprintf(`城市：%s
+当前时间：%s
+
+【逐小时预报】
+%s
+
+【待办事项】
+%s`, sanitizeUserText(city, maxPromptCityRunes), now.Format("2006-01-02 15:04"), formatHourlyForAI(hourly, planForecastHours), fenceUserText(lines...))
+}
+
+// formatHourlyForAI formats up to limit hours of forecast for an AI prompt
+func formatHourlyForAI(hourly []qweather.HourlyForecast, limit int) string {
+	if len(hourly) == 0 {
+		return "暂无逐小时预报"
+	}
+
+	var b strings.Builder
+	for i, h := range hourly {
+		if i >= limit {
+			break
+		}
+		hour := h.FxTime
+		if t, err := time.Parse("2006-01-02T15:04Z07:00", h.FxTime); err == nil {
+			hour = t.Format("15:04")
+		}
+		b.WriteString(fmt.Sprintf("• %s %s %s°C 风力%s级", hour, h.Text, h.Temp, h.WindScale))
+		if h.Pop != "" {
+			b.WriteString(fmt.Sprintf(" 降水概率%s%%", h.Pop))
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// parseTodoPlan parses the model's JSON reply. Unknown or repeated indices are ignored and
+// todos the model left out are appended in their original order.
+func parseTodoPlan(content string, todos []model.Todo) (*TodoPlan, error) {
+	var resp todoPlanResponse
+	if err := decodeJSONReply(content, &resp); err != nil {
+		return nil, err
+	}
+
+	plan := &TodoPlan{}
+	used := make([]bool, len(todos))
+	for _, item := range resp.Items {
+		idx := item.Index - 1
+		if idx < 0 || idx >= len(todos) || used[idx] {
+			continue
+		}
+		used[idx] = true
+		note := []rune(strings.TrimSpace(item.Note))
+		if containsLink(string(note)) {
+			note = nil
+		}
+		if len(note) > maxPlanNoteRunes {
+			note = append(note[:maxPlanNoteRunes], '…')
+		}
+		plan.Items = append(plan.Items, TodoPlanItem{Todo: todos[idx], Note: string(note)})
+	}
+	if len(plan.Items) == 0 {
+		return nil, fmt.Errorf("reply contains no valid todo index")
+	}
+	for i, todo := range todos {
+		if !used[i] {
+			plan.Items = append(plan.Items, TodoPlanItem{Todo: todo})
+		}
+	}
+	return plan, nil
+}
+
+// FormatTodoPlan formats a todo plan as the annotated todo section of a reminder
+func (s *TodoService) FormatTodoPlan(plan *TodoPlan) string {
+	var builder strings.Builder
+	builder.WriteString("📝 今日待办（已结合天气排序）：\n\n")
+
+	for i, item := range plan.Items {
+		builder.WriteString(fmt.Sprintf("%d. ⬜ %s\n", i+1, item.Todo.Content))
+		if item.Note != "" {
+			builder.WriteString(fmt.Sprintf("   💡 %s\n", item.Note))
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// todoStatsBackfillDays is how many past days the nightly job re-aggregates, covering downtime
+const todoStatsBackfillDays = 7
+
+// TodoBadge is an emoji badge awarded for todo completion milestones
+type TodoBadge struct {
+	Emoji string
+	Name  string
+}
+
+// todoBadges lists the badges in the order they are displayed, with the milestone earning each
+var todoBadges = []struct {
+	TodoBadge
+	earned func(s *TodoSummary) bool
+}{
+	{TodoBadge{"🌱", "初次完成"}, func(s *TodoSummary) bool { return s.Total >= 1 }},
+	{TodoBadge{"🔥", "三日连胜"}, func(s *TodoSummary) bool { return s.BestStreak >= 3 }},
+	{TodoBadge{"⭐", "一周坚持"}, func(s *TodoSummary) bool { return s.BestStreak >= 7 }},
+	{TodoBadge{"🏆", "月度达人"}, func(s *TodoSummary) bool { return s.BestStreak >= 30 }},
+	{TodoBadge{"💯", "百项达成"}, func(s *TodoSummary) bool { return s.Total >= 100 }},
+}
+
+// TodoSummary describes a user's todo completion progress
+type TodoSummary struct {
+	Streak        int // Consecutive days with completions, ending today (or yesterday if none yet today)
+	BestStreak    int // Longest streak ever
+	Total         int // Todos completed ever
+	WeekCompleted int // Todos completed in the last 7 days, including today
+	Open          int // Incomplete todos in the user's lists
+	Badges        []TodoBadge
+}
+
+// WeeklyRate returns the share of the week's todos that were completed, in percent
+func (s *TodoSummary) WeeklyRate() int {
+	if s.WeekCompleted+s.Open == 0 {
+		return 0
+	}
+	return s.WeekCompleted * 100 / (s.WeekCompleted + s.Open)
+}
+
+// Format renders the summary as a digest section, or "" when the user never completed a todo
+func (s *TodoSummary) Format() string {
+	if s.Total == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("🏅 待办成就\n")
+	if s.Streak > 0 {
+		b.WriteString(fmt.Sprintf("🔥 已连续 %d 天完成待办（最长 %d 天）\n", s.Streak, s.BestStreak))
+	} else {
+		b.WriteString(fmt.Sprintf("💤 连续记录已中断，今天完成一项待办重新开始（最长 %d 天）\n", s.BestStreak))
+	}
+	b.WriteString(fmt.Sprintf("📈 本周完成率 %d%%（完成 %d 项，未完成 %d 项）\n", s.WeeklyRate(), s.WeekCompleted, s.Open))
+	if len(s.Badges) > 0 {
+		names := make([]string, len(s.Badges))
+		for i, badge := range s.Badges {
+			names[i] = badge.Emoji + badge.Name
+		}
+		b.WriteString("🎖️ 徽章：" + strings.Join(names, " ") + "\n")
+	}
+	return b.String()
+}
+
+// TodoStatsService maintains daily todo completion statistics and derives streaks and badges
+type TodoStatsService struct {
+	todoRepo  *repository.TodoRepository
+	statsRepo *repository.TodoStatsRepository
+	subRepo   *repository.SubscriptionRepository
+	timezone  *time.Location
+}
+
+// NewTodoStatsService creates a new TodoStatsService
+func NewTodoStatsService(
+	todoRepo *repository.TodoRepository,
+	statsRepo *repository.TodoStatsRepository,
+	subRepo *repository.SubscriptionRepository,
+	timezone *time.Location,
+) *TodoStatsService {
+	return &TodoStatsService{
+		todoRepo:  todoRepo,
+		statsRepo: statsRepo,
+		subRepo:   subRepo,
+		timezone:  timezone,
+	}
+}
+
+// AggregateRecent re-aggregates the days before now, oldest first so that streaks chain
+func (s *TodoStatsService) AggregateRecent(now time.Time) {
+	today := s.dayStart(now)
+	for i := todoStatsBackfillDays; i >= 1; i-- {
+		if err := s.Aggregate(today.AddDate(0, 0, -i)); err != nil {
+			logger.Error("Failed to aggregate todo stats", zap.Error(err))
+			return
+		}
+	}
+}
+
+// Aggregate computes the completions and streaks of every user for the day containing day.
+// The previous day must already be aggregated for streaks to carry over.
+func (s *TodoStatsService) Aggregate(day time.Time) error {
+	start := s.dayStart(day)
+	date := start.Format("2006-01-02")
+
+	counts, err := s.todoRepo.CompletionsByUser(s.dbTime(start), s.dbTime(start.AddDate(0, 0, 1)))
+	if err != nil {
+		return err
+	}
+	previous, err := s.statsRepo.FindByDate(start.AddDate(0, 0, -1).Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	prevStreak := make(map[uint]int, len(previous))
+	for _, p := range previous {
+		prevStreak[p.UserID] = p.Streak
+	}
+
+	stats := make([]model.TodoStats, 0, len(counts))
+	for userID, count := range counts {
+		stats = append(stats, model.TodoStats{
+			UserID:    userID,
+			Date:      date,
+			Completed: count,
+			Streak:    prevStreak[userID] + 1,
+		})
+	}
+	if err := s.statsRepo.ReplaceDay(date, stats); err != nil {
+		return err
+	}
+
+	logger.Debug("Todo stats aggregated",
+		zap.String("date", date),
+		zap.Int("users", len(stats)))
+	return nil
+}
+
+// Summary returns a user's progress as of now, combining aggregated days with today's live completions
+func (s *TodoStatsService) Summary(userID uint, now time.Time) (*TodoSummary, error) {
+	today := s.dayStart(now)
+	yesterday := today.AddDate(0, 0, -1).Format("2006-01-02")
+
+	counts, err := s.todoRepo.CompletionsByUser(s.dbTime(today), s.dbTime(today.AddDate(0, 0, 1)), userID)
+	if err != nil {
+		return nil, err
+	}
+	todayCount := counts[userID]
+
+	recent, err := s.statsRepo.FindByUserSince(userID, today.AddDate(0, 0, -6).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	total, best, err := s.statsRepo.Totals(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &TodoSummary{
+		Total:         total + todayCount,
+		WeekCompleted: todayCount,
+	}
+	for _, day := range recent {
+		summary.WeekCompleted += day.Completed
+		if day.Date == yesterday {
+			summary.Streak = day.Streak
+		}
+	}
+	if todayCount > 0 {
+		summary.Streak++
+	}
+	summary.BestStreak = max(best, summary.Streak)
+
+	subs, err := s.subRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	listIDs := make([]uint, len(subs))
+	for i, sub := range subs {
+		listIDs[i] = sub.TodoListID()
+	}
+	open, err := s.todoRepo.CountIncomplete(listIDs)
+	if err != nil {
+		return nil, err
+	}
+	summary.Open = int(open)
+
+	for _, badge := range todoBadges {
+		if badge.earned(summary) {
+			summary.Badges = append(summary.Badges, badge.TodoBadge)
+		}
+	}
+	return summary, nil
+}
+
+// dayStart returns midnight of the day containing t in the scheduler timezone
+func (s *TodoStatsService) dayStart(t time.Time) time.Time {
+	t = t.In(s.timezone)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.timezone)
+}
+
+// dbTime converts a day boundary to the process timezone that completion times are stored in,
+// so range comparisons also hold on databases storing timestamps as text (SQLite)
+func (s *TodoStatsService) dbTime(t time.Time) time.Time {
+	return t.In(time.Local)
+}
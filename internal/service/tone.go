@@ -0,0 +1,129 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Tone hints are learned from each user's most recent votes within toneVoteWindow: a hint is on
+// when at least toneHintMinVotes of them give its reason and they make up a third of the votes,
+// so later 👍 votes turn it off again
+const (
+	toneVoteWindow    = 30 * 24 * time.Hour
+	toneVoteLimit     = 20
+	toneHintMinVotes  = 2
+	toneVoteRetention = 90 * 24 * time.Hour
+)
+
+// toneHintReasons maps the reasons of 👎 votes to the tone hints they count towards
+var toneHintReasons = map[string]string{
+	model.VoteReasonLong:  model.ToneHintShorter,
+	model.VoteReasonEmoji: model.ToneHintFewerEmoji,
+}
+
+// ToneService records the 👍/👎 votes on AI reminders and periodically turns them into per-user
+// tone hints (shorter, fewer emojis) for the AI system prompt
+type ToneService struct {
+	voteRepo *repository.ReminderVoteRepository
+	userRepo *repository.UserRepository
+}
+
+// NewToneService creates a new ToneService
+func NewToneService(voteRepo *repository.ReminderVoteRepository, userRepo *repository.UserRepository) *ToneService {
+	return &ToneService{voteRepo: voteRepo, userRepo: userRepo}
+}
+
+// Vote records a user's 👍 (1) or 👎 (-1) on the reminder delivered as the given message,
+// replacing an earlier vote on it
+func (s *ToneService) Vote(userID uint, chatID int64, messageID int, vote int) error {
+	return s.voteRepo.Upsert(&model.ReminderVote{
+		UserID:    userID,
+		ChatID:    chatID,
+		MessageID: messageID,
+		Vote:      vote,
+	})
+}
+
+// SetReason records why the reminder delivered as the given message was voted down. Returns
+// false when it has no 👎 vote.
+func (s *ToneService) SetReason(chatID int64, messageID int, reason string) (bool, error) {
+	return s.voteRepo.SetReason(chatID, messageID, reason)
+}
+
+// AdjustHints recomputes the tone hints of the users who voted recently or have hints, and
+// removes old votes
+func (s *ToneService) AdjustHints(now time.Time) {
+	votes, err := s.voteRepo.FindSince(now.Add(-toneVoteWindow))
+	if err != nil {
+		logger.Error("Failed to load reminder votes", zap.Error(err))
+		return
+	}
+	byUser := make(map[uint][]model.ReminderVote)
+	for _, v := range votes {
+		if len(byUser[v.UserID]) < toneVoteLimit {
+			byUser[v.UserID] = append(byUser[v.UserID], v)
+		}
+	}
+
+	current := make(map[uint]string)
+	users, err := s.userRepo.FindWithToneHints()
+	if err != nil {
+		logger.Error("Failed to load tone hints", zap.Error(err))
+		return
+	}
+	for _, u := range users {
+		current[u.ID] = u.ToneHints
+		if _, ok := byUser[u.ID]; !ok {
+			byUser[u.ID] = nil
+		}
+	}
+
+	changed := 0
+	for userID, userVotes := range byUser {
+		hints := ToneHints(userVotes)
+		if hints == current[userID] {
+			continue
+		}
+		if err := s.userRepo.SetToneHints(userID, hints); err != nil {
+			continue
+		}
+		changed++
+		logger.Debug("Tone hints updated",
+			zap.Uint("user_id", userID),
+			zap.String("from", current[userID]),
+			zap.String("to", hints))
+	}
+
+	deleted, err := s.voteRepo.DeleteBefore(now.Add(-toneVoteRetention))
+	if err != nil {
+		logger.Warn("Failed to clean up reminder votes", zap.Error(err))
+	}
+	logger.Info("Tone hints adjusted",
+		zap.Int("users", len(byUser)),
+		zap.Int("changed", changed),
+		zap.Int64("votes_deleted", deleted))
+}
+
+// ToneHints returns the comma-separated tone hints supported by votes
+func ToneHints(votes []model.ReminderVote) string {
+	counts := make(map[string]int)
+	for _, v := range votes {
+		if hint, ok := toneHintReasons[v.Reason]; ok && v.Vote < 0 {
+			counts[hint]++
+		}
+	}
+	var hints []string
+	for hint, n := range counts {
+		if n >= toneHintMinVotes && n*3 >= len(votes) {
+			hints = append(hints, hint)
+		}
+	}
+	sort.Strings(hints)
+	return strings.Join(hints, ",")
+}
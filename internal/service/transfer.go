@@ -0,0 +1,205 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// TransferPrefix marks /start payloads transferring subscriptions to the opening chat
+const TransferPrefix = "xfer_"
+
+// TransferTTL is how long a transfer link stays valid
+const TransferTTL = time.Hour
+
+// Errors returned when checking transfer tokens
+var (
+	ErrTransferInvalid = errors.New("invalid transfer link")
+	ErrTransferExpired = errors.New("transfer link expired")
+	ErrTransferUsed    = errors.New("transfer link already used")
+)
+
+// TransferPreview describes what a transfer token would move
+type TransferPreview struct {
+	From          *model.User
+	Subscriptions []model.Subscription // Active subscriptions
+	Todos         int64                // Incomplete todos of the active subscriptions' lists
+}
+
+// TransferService moves a user's subscriptions and todos to another chat of the same bot (e.g.
+// after changing phone number or account). The old chat, or an operator, creates a signed link;
+// opening it in the new chat and confirming performs the transfer. Each link can be used once.
+type TransferService struct {
+	subRepo   *repository.SubscriptionRepository
+	userRepo  *repository.UserRepository
+	todoRepo  *repository.TodoRepository
+	usedRepo  *repository.UsedTransferTokenRepository
+	key       []byte
+	usernames map[string]string // Bot name ("" = primary) -> Telegram username, for links
+}
+
+// NewTransferService creates a new TransferService. Links are signed with a key derived from
+// secret (the bot token), so they cannot be forged and stop working when the token is revoked.
+func NewTransferService(subRepo *repository.SubscriptionRepository, userRepo *repository.UserRepository, todoRepo *repository.TodoRepository, usedRepo *repository.UsedTransferTokenRepository, secret string, usernames map[string]string) *TransferService {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("daily-reminder-bot subscription transfer"))
+	return &TransferService{
+		subRepo:   subRepo,
+		userRepo:  userRepo,
+		todoRepo:  todoRepo,
+		usedRepo:  usedRepo,
+		key:       mac.Sum(nil),
+		usernames: usernames,
+	}
+}
+
+// Token returns a signed /start payload transferring a user's subscriptions, valid until expires.
+// With the full 43-character signature it stays within Telegram's 64-character payload limit.
+func (s *TransferService) Token(user *model.User, expires time.Time) string {
+	id := strconv.FormatUint(uint64(user.ID), 36)
+	exp := strconv.FormatInt(expires.Unix(), 36)
+	return TransferPrefix + id + "_" + exp + "_" + s.sign(user.Bot, id, exp)
+}
+
+// Link returns a deep link transferring a user's subscriptions and its expiry time.
+// Returns an error when the username of the user's bot is unknown.
+func (s *TransferService) Link(user *model.User, now time.Time) (string, time.Time, error) {
+	username := s.usernames[user.Bot]
+	if username == "" {
+		return "", time.Time{}, fmt.Errorf("unknown username of bot %q", user.Bot)
+	}
+	expires := now.Add(TransferTTL)
+	return fmt.Sprintf("https://t.me/%s?start=%s", username, s.Token(user, expires)), expires, nil
+}
+
+// sign returns the base64url HMAC of a token's fields
+func (s *TransferService) sign(bot, id, exp string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(bot + "|" + id + "|" + exp))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// transferToken is a verified transfer token
+type transferToken struct {
+	userID    uint // User the subscriptions are transferred from
+	signature string
+	expires   time.Time
+}
+
+// verify checks a token opened with the given bot, returning ErrTransferUsed once it has been
+// redeemed
+func (s *TransferService) verify(bot, token string, now time.Time) (*transferToken, error) {
+	// The signature is base64url and may itself contain '_'
+	parts := strings.SplitN(strings.TrimPrefix(token, TransferPrefix), "_", 3)
+	if len(parts) != 3 {
+		return nil, ErrTransferInvalid
+	}
+	id, exp, sig := parts[0], parts[1], parts[2]
+	if !hmac.Equal([]byte(sig), []byte(s.sign(bot, id, exp))) {
+		return nil, ErrTransferInvalid
+	}
+	expires, err := strconv.ParseInt(exp, 36, 64)
+	if err != nil {
+		return nil, ErrTransferInvalid
+	}
+	if now.Unix() > expires {
+		return nil, ErrTransferExpired
+	}
+	userID, err := strconv.ParseUint(id, 36, 32)
+	if err != nil || userID == 0 {
+		return nil, ErrTransferInvalid
+	}
+	used, err := s.usedRepo.IsUsed(sig)
+	if err != nil {
+		return nil, err
+	}
+	if used {
+		return nil, ErrTransferUsed
+	}
+	return &transferToken{userID: uint(userID), signature: sig, expires: time.Unix(expires, 0)}, nil
+}
+
+// Preview checks a token opened with the given bot and returns what it would transfer
+func (s *TransferService) Preview(bot, token string, now time.Time) (*TransferPreview, error) {
+	t, err := s.verify(bot, token, now)
+	if err != nil {
+		return nil, err
+	}
+	from, err := s.userRepo.FindByID(t.userID)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, ErrTransferInvalid
+	}
+	subs, err := s.subRepo.FindByUserID(from.ID)
+	if err != nil {
+		return nil, err
+	}
+	listIDs := make([]uint, 0, len(subs))
+	for i := range subs {
+		listIDs = append(listIDs, subs[i].TodoListID())
+	}
+	todos, err := s.todoRepo.CountIncomplete(listIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &TransferPreview{From: from, Subscriptions: subs, Todos: todos}, nil
+}
+
+// Transfer checks a token and moves the subscriptions and todos of the user it was issued for to
+// the given user, using up the token. Returns the source user and the number of subscriptions
+// moved and merged.
+func (s *TransferService) Transfer(token string, to *model.User, now time.Time) (from *model.User, moved, merged int, err error) {
+	t, err := s.verify(to.Bot, token, now)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if t.userID == to.ID {
+		return nil, 0, 0, ErrTransferInvalid
+	}
+	from, err = s.userRepo.FindByID(t.userID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if from == nil {
+		return nil, 0, 0, ErrTransferInvalid
+	}
+
+	// Records of expired tokens are no longer needed to reject them
+	if err := s.usedRepo.DeleteExpired(now); err != nil {
+		logger.Warn("Failed to delete expired transfer tokens", zap.Error(err))
+	}
+	// Claimed before transferring, so that concurrent confirmations transfer once
+	claimed, err := s.usedRepo.Claim(t.signature, from.ID, t.expires)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !claimed {
+		return nil, 0, 0, ErrTransferUsed
+	}
+	moved, merged, err = s.subRepo.TransferAll(from.ID, to.ID)
+	if err != nil {
+		if releaseErr := s.usedRepo.Release(t.signature); releaseErr != nil {
+			logger.Warn("Failed to release transfer token", zap.Error(releaseErr))
+		}
+		return nil, 0, 0, err
+	}
+	logger.Info("Subscriptions transferred between chats",
+		zap.String("bot", to.Bot),
+		zap.Int64("from_chat_id", from.ChatID),
+		zap.Int64("to_chat_id", to.ChatID),
+		zap.Int("moved", moved),
+		zap.Int("merged", merged))
+	return from, moved, merged, nil
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/internal/testutil"
+)
+
+// A transfer link moves the subscriptions once: opening or confirming it again is refused
+func TestTransferTokenIsSingleUse(t *testing.T) {
+	h, err := testutil.NewHarness()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	transfers := service.NewTransferService(h.SubRepo, h.UserRepo, h.TodoRepo, repository.NewUsedTransferTokenRepository(h.DB), "secret", nil)
+
+	from, err := h.UserRepo.GetOrCreate("", 41)
+	if err != nil {
+		t.Fatal(err)
+	}
+	to, err := h.UserRepo.GetOrCreate("", 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thief, err := h.UserRepo.GetOrCreate("", 43)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.SubRepo.Create(&model.Subscription{UserID: from.ID, City: "北京", ReminderTime: "08:00", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	token := transfers.Token(from, now.Add(service.TransferTTL))
+	// Telegram limits /start payloads to 64 characters
+	if longest := transfers.Token(&model.User{ID: 1<<32 - 1}, time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)); len(longest) > 64 {
+		t.Errorf("token %q is %d characters long", longest, len(longest))
+	}
+	// xfer_<user>_<expiry>_<signature>; the signature may contain '_'
+	if sig := strings.SplitN(strings.TrimPrefix(token, service.TransferPrefix), "_", 3)[2]; len(sig) != 43 {
+		t.Errorf("signature %q is not a full SHA-256 HMAC", sig)
+	}
+
+	if _, err := transfers.Preview("", token, now); err != nil {
+		t.Fatalf("Preview: %v", err)
+	}
+	if _, moved, _, err := transfers.Transfer(token, to, now); err != nil || moved != 1 {
+		t.Fatalf("Transfer = %d, %v, want 1 moved", moved, err)
+	}
+	if _, err := transfers.Preview("", token, now); !errors.Is(err, service.ErrTransferUsed) {
+		t.Errorf("Preview after use = %v, want ErrTransferUsed", err)
+	}
+	if _, _, _, err := transfers.Transfer(token, thief, now); !errors.Is(err, service.ErrTransferUsed) {
+		t.Errorf("second Transfer = %v, want ErrTransferUsed", err)
+	}
+
+	// A truncated or altered signature is invalid
+	for _, bad := range []string{token[:len(token)-1], token[:len(token)-27], token + "A"} {
+		if _, err := transfers.Preview("", bad, now); !errors.Is(err, service.ErrTransferInvalid) {
+			t.Errorf("Preview(%q) = %v, want ErrTransferInvalid", bad, err)
+		}
+	}
+	// A new link works after the previous one expired and its record was pruned
+	next := transfers.Token(to, now.Add(2*service.TransferTTL))
+	if _, _, _, err := transfers.Transfer(next, from, now.Add(90*time.Minute)); err != nil {
+		t.Errorf("Transfer with a new link = %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+// Limits of travel briefings
+const (
+	tripMaxDays           = 15  // Longest trip, and how far ahead the forecast reaches
+	tripWeekDays          = 7   // Trips ending within this many days use the free 7-day forecast
+	tripNarrativeMaxRunes = 150 // Length of the AI-written narrative
+)
+
+// Errors of travel briefings
+var (
+	ErrTripRange      = errors.New("trip ends before it starts")
+	ErrTripPast       = errors.New("trip starts in the past")
+	ErrTripTooLong    = errors.New("trip is too long")
+	ErrTripTooFar     = errors.New("trip starts beyond the forecast range")
+	ErrTripNoForecast = errors.New("no forecast covers the trip")
+)
+
+// tripWeekdays names the days of the week
+var tripWeekdays = []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// TripService compiles travel weather briefings: the daily forecast of a destination over a
+// date range, packing suggestions and the festivals and holidays the trip overlaps, with an AI
+// narrative when the AI is enabled
+type TripService struct {
+	weatherSvc  *WeatherService
+	calendarSvc *CalendarService
+	aiSvc       *AIService // Writes the narrative (nil or disabled = none)
+}
+
+// tripDay is the forecast and calendar notes of one day of a trip
+type tripDay struct {
+	date     time.Time
+	forecast *qweather.DailyForecast
+	notes    []string // Solar term and festivals
+	holiday  string   // Statutory holiday the day is off for ("" = none)
+}
+
+// NewTripService creates a new TripService
+func NewTripService(weatherSvc *WeatherService, calendarSvc *CalendarService, aiSvc *AIService) *TripService {
+	return &TripService{
+		weatherSvc:  weatherSvc,
+		calendarSvc: calendarSvc,
+		aiSvc:       aiSvc,
+	}
+}
+
+// ValidateTripRange checks that a trip from one date to another (inclusive) lies within the
+// forecast range of today
+func ValidateTripRange(from, to, today time.Time) error {
+	switch {
+	case to.Before(from):
+		return ErrTripRange
+	case from.Before(today):
+		return ErrTripPast
+	case int(to.Sub(from).Hours()/24) >= tripMaxDays:
+		return ErrTripTooLong
+	case int(from.Sub(today).Hours()/24) >= tripMaxDays:
+		return ErrTripTooFar
+	}
+	return nil
+}
+
+// Briefing generates the /trip briefing of a destination for the dates from and to (inclusive,
+// midnight in the bot's timezone); today is the current date. Days beyond the forecast range
+// are listed as unavailable.
+func (s *TripService) Briefing(ctx context.Context, city string, from, to, today time.Time) (string, error) {
+	if err := ValidateTripRange(from, to, today); err != nil {
+		return "", err
+	}
+	location, err := s.weatherSvc.ResolveLocation(city, "")
+	if err != nil {
+		return "", err
+	}
+	forecasts, err := s.fetchForecasts(location.ID, int(to.Sub(today).Hours()/24)+1)
+	if err != nil {
+		return "", err
+	}
+	byDate := make(map[string]*qweather.DailyForecast, len(forecasts))
+	for i := range forecasts {
+		byDate[forecasts[i].FxDate] = &forecasts[i]
+	}
+
+	var days []tripDay
+	covered := 0
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		day := tripDay{date: date, forecast: byDate[date.Format("2006-01-02")]}
+		day.notes, day.holiday = s.calendarSvc.DayNotes(date)
+		if day.forecast != nil {
+			covered++
+		}
+		days = append(days, day)
+	}
+	if covered == 0 {
+		return "", ErrTripNoForecast
+	}
+
+	label := LocationLabel(city, location)
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("🧳 %s 行程天气\n", label))
+	b.WriteString(fmt.Sprintf("📅 %s - %s（%d 天）\n\n", formatTripDate(from), formatTripDate(to), len(days)))
+	for _, day := range days {
+		b.WriteString(formatTripDay(day) + "\n")
+	}
+	if covered < len(days) {
+		b.WriteString("   （超出预报范围的日期请临近出发时再查询）\n")
+	}
+
+	if summary := tripSummary(days); summary != "" {
+		b.WriteString("\n📊 " + summary + "\n")
+	}
+	if packing := packingSuggestions(days); len(packing) > 0 {
+		b.WriteString("\n🎒 行李建议：\n")
+		for _, item := range packing {
+			b.WriteString("   • " + item + "\n")
+		}
+	}
+	if overlap := holidayOverlap(days); overlap != "" {
+		b.WriteString("\n" + overlap + "\n")
+	}
+
+	if s.aiSvc != nil && s.aiSvc.IsEnabled() {
+		narrative, err := s.aiSvc.writeTripBriefing(ctx, label, days)
+		if err == nil {
+			b.WriteString("\n🤖 " + narrative + "\n")
+		} else {
+			logger.Warn("Failed to write trip narrative", zap.String("city", city), zap.Error(err))
+		}
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+// fetchForecasts returns the daily forecasts covering the next days days, using the 15-day
+// forecast only when the 7-day one is too short and falling back to it when the 15-day forecast
+// is unavailable on the QWeather plan
+func (s *TripService) fetchForecasts(locationID string, days int) ([]qweather.DailyForecast, error) {
+	client := s.weatherSvc.Client()
+	if days > tripWeekDays {
+		forecasts, err := client.GetDailyForecasts15d(locationID)
+		if err == nil {
+			return forecasts, nil
+		}
+		logger.Warn("Failed to get 15-day forecast, using the 7-day forecast",
+			zap.String("location_id", locationID),
+			zap.Error(err))
+	}
+	forecasts, err := client.GetDailyForecasts7d(locationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily forecast: %w", err)
+	}
+	return forecasts, nil
+}
+
+// formatTripDate formats a trip date, e.g. "10月1日 周三"
+func formatTripDate(date time.Time) string {
+	return fmt.Sprintf("%d月%d日 %s", date.Month(), date.Day(), tripWeekdays[date.Weekday()])
+}
+
+// formatTripDay formats the forecast line of one day of a trip
+func formatTripDay(day tripDay) string {
+	line := formatTripDate(day.date)
+	if day.holiday != "" {
+		line += " 🏮休"
+	}
+	if len(day.notes) > 0 {
+		line += "（" + strings.Join(day.notes, "、") + "）"
+	}
+	if day.forecast == nil {
+		return line + "：暂无预报"
+	}
+	f := day.forecast
+	line += fmt.Sprintf("：%s %s~%s°C", dayText(f), f.TempMin, f.TempMax)
+	if precip, err := strconv.ParseFloat(f.Precip, 64); err == nil && precip > 0 {
+		line += fmt.Sprintf(" 💧%smm", f.Precip)
+	}
+	if wind := maxWindScale(f.WindScaleDay); wind >= laundryBreezyWind {
+		line += fmt.Sprintf(" 💨%d级", wind)
+	}
+	return line
+}
+
+// tripSummary summarizes the temperature range and rainy days of the forecast days of a trip
+func tripSummary(days []tripDay) string {
+	lowest, highest, rainy, counted := 0, 0, 0, 0
+	for _, day := range days {
+		if day.forecast == nil {
+			continue
+		}
+		low, errLow := strconv.Atoi(day.forecast.TempMin)
+		high, errHigh := strconv.Atoi(day.forecast.TempMax)
+		if errLow != nil || errHigh != nil {
+			continue
+		}
+		if counted == 0 || low < lowest {
+			lowest = low
+		}
+		if counted == 0 || high > highest {
+			highest = high
+		}
+		counted++
+		if isWetDay(day.forecast) {
+			rainy++
+		}
+	}
+	if counted == 0 {
+		return ""
+	}
+	summary := fmt.Sprintf("气温 %d~%d°C", lowest, highest)
+	if rainy > 0 {
+		summary += fmt.Sprintf("，%d 天有降水", rainy)
+	} else {
+		summary += "，预报期内无降水"
+	}
+	return summary
+}
+
+// packingSuggestions suggests what to pack for the forecast days of a trip
+func packingSuggestions(days []tripDay) []string {
+	lowest, highest, maxSpread, maxUV, maxWind := 100, -100, 0, 0, 0
+	wet, snow, counted := false, false, 0
+	for _, day := range days {
+		f := day.forecast
+		if f == nil {
+			continue
+		}
+		low, errLow := strconv.Atoi(f.TempMin)
+		high, errHigh := strconv.Atoi(f.TempMax)
+		if errLow == nil && errHigh == nil {
+			lowest, highest = min(lowest, low), max(highest, high)
+			maxSpread = max(maxSpread, high-low)
+			counted++
+		}
+		if uv, err := strconv.Atoi(f.UvIndex); err == nil {
+			maxUV = max(maxUV, uv)
+		}
+		maxWind = max(maxWind, maxWindScale(f.WindScaleDay, f.WindScaleNight))
+		wet = wet || isWetDay(f)
+		snow = snow || strings.Contains(f.TextDay+f.TextNight, "雪")
+	}
+
+	var items []string
+	if counted > 0 {
+		switch {
+		case lowest < 0:
+			items = append(items, "羽绒服、保暖内衣、帽子和手套")
+		case lowest < 10:
+			items = append(items, "厚外套或薄羽绒服、毛衣")
+		case lowest < 18:
+			items = append(items, "长袖和一件外套")
+		default:
+			items = append(items, "短袖等轻薄衣物")
+		}
+		if highest >= 28 && lowest < 18 {
+			items = append(items, "短袖，白天较热")
+		}
+		if maxSpread >= 10 {
+			items = append(items, fmt.Sprintf("早晚温差达 %d°C，备一件可随时穿脱的外套", maxSpread))
+		}
+	}
+	if snow {
+		items = append(items, "防滑鞋，雪天路滑")
+	} else if wet {
+		items = append(items, "雨伞或雨衣，最好带一双防水鞋")
+	}
+	if maxUV >= 6 {
+		items = append(items, "防晒霜、太阳镜和遮阳帽")
+	}
+	if maxWind >= laundryBreezyWind {
+		items = append(items, "防风外套")
+	}
+	return items
+}
+
+// holidayOverlap describes the statutory holidays a trip overlaps, or "" when there are none
+func holidayOverlap(days []tripDay) string {
+	var names []string
+	count := 0
+	for _, day := range days {
+		if day.holiday == "" {
+			continue
+		}
+		count++
+		if len(names) == 0 || names[len(names)-1] != day.holiday {
+			names = append(names, day.holiday)
+		}
+	}
+	if count == 0 {
+		return ""
+	}
+	return fmt.Sprintf("🎉 行程中有 %d 天是%s假期，景区和交通可能拥挤，建议提前预订车票和住宿", count, strings.Join(names, "、"))
+}
+
+// isWetDay reports whether a day's forecast has rain or snow
+func isWetDay(f *qweather.DailyForecast) bool {
+	if strings.Contains(f.TextDay+f.TextNight, "雨") || strings.Contains(f.TextDay+f.TextNight, "雪") {
+		return true
+	}
+	precip, err := strconv.ParseFloat(f.Precip, 64)
+	return err == nil && precip > 0
+}
+
+// tripSystemPrompt instructs the AI to write the narrative of a travel briefing
+const tripSystemPrompt = `你是贴心的旅行天气顾问，根据目的地的逐日天气预报和节假日信息，为用户写一段出行建议。
+用两到三句中文（不超过 120 字）概括行程期间的天气走势，指出最适合户外游览的日子和需要注意的天气，必要时提醒节假日人流。
+不要逐日复述预报，不要使用 Markdown，只输出建议本身。`
+
+// writeTripBriefing writes the narrative of a travel briefing for a destination
+func (s *AIService) writeTripBriefing(ctx context.Context, destination string, days []tripDay) (string, error) {
+	var prompt strings.Builder
+	prompt.WriteString(fmt.Sprintf("目的地：%s\n逐日预报：\n", destination))
+	for _, day := range days {
+		prompt.WriteString(formatTripDay(day))
+		if day.holiday != "" {
+			prompt.WriteString(fmt.Sprintf("（%s假期）", day.holiday))
+		}
+		prompt.WriteString("\n")
+	}
+
+	content, err := s.complete(ctx, tripSystemPrompt, prompt.String())
+	if err != nil {
+		return "", err
+	}
+	narrative := strings.TrimSpace(strings.ReplaceAll(content, "\n", ""))
+	if narrative == "" {
+		return "", fmt.Errorf("empty trip narrative")
+	}
+	return truncateRunes(narrative, tripNarrativeMaxRunes), nil
+}
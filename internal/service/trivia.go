@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/calendar"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/trivia"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// triviaMaxRunes limits an AI-rephrased trivia; longer replies keep the curated text
+const triviaMaxRunes = 90
+
+// TriviaService adds a "今日冷知识" line to daily reminders, picked from the curated trivia by the
+// day's solar term or weather. When the AI is enabled it rephrases the trivia once per topic and
+// day, so the same topic reads the same for everyone that day.
+type TriviaService struct {
+	calculator *calendar.Calculator
+	aiSvc      *AIService // Rephrases the trivia (nil or disabled = the curated text)
+
+	mu    sync.Mutex
+	cache map[string]string // Rephrased trivia keyed by topic and date
+	group singleflight.Group
+}
+
+// NewTriviaService creates a new TriviaService
+func NewTriviaService(aiSvc *AIService, timezone *time.Location) *TriviaService {
+	return &TriviaService{
+		calculator: calendar.NewCalculator(timezone),
+		aiSvc:      aiSvc,
+		cache:      make(map[string]string),
+	}
+}
+
+// Line returns the trivia line of a reminder, given the current weather and today's forecast
+// (either may be nil)
+func (s *TriviaService) Line(ctx context.Context, weather *qweather.CurrentWeather, today *qweather.DailyForecast, now time.Time) string {
+	topic := trivia.Topic(s.calculator.GetTodayJieQi(now), weather, today)
+	return "🧠 今日冷知识：" + s.Trivia(ctx, topic, now.Format("2006-01-02"))
+}
+
+// Trivia returns the trivia of a topic on a date (YYYY-MM-DD), rephrased by the AI when enabled
+func (s *TriviaService) Trivia(ctx context.Context, topic, date string) string {
+	fact := trivia.Pick(topic, date)
+	if s.aiSvc == nil || !s.aiSvc.IsEnabled() {
+		return fact
+	}
+
+	key := topic + "|" + date
+	s.mu.Lock()
+	text, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok {
+		return text
+	}
+
+	v, _, _ := s.group.Do(key, func() (interface{}, error) {
+		text, err := s.aiSvc.RephraseTrivia(ctx, fact)
+		if err != nil {
+			logger.Warn("Failed to rephrase trivia, using the curated text",
+				zap.String("topic", topic),
+				zap.Error(err))
+			// Not cached, so the next reminder tries again
+			return fact, nil
+		}
+
+		s.mu.Lock()
+		// Drop trivia older than the previous day, which users in other timezones may still need
+		if day, err := time.Parse("2006-01-02", date); err == nil {
+			oldest := day.AddDate(0, 0, -1).Format("2006-01-02")
+			for k := range s.cache {
+				if k[strings.LastIndex(k, "|")+1:] < oldest {
+					delete(s.cache, k)
+				}
+			}
+		}
+		s.cache[key] = text
+		s.mu.Unlock()
+		return text, nil
+	})
+	return v.(string)
+}
+
+// triviaSystemPrompt instructs the AI to rephrase a trivia
+const triviaSystemPrompt = `你是天气科普小编，为用户的每日提醒改写一条「今日冷知识」。
+用一句轻松有趣的中文重新表述给出的知识（不超过 60 字），保留其中的事实和数字，不要添加原文没有的事实，不要使用 Markdown、不要加引号或前缀，只输出这句话。`
+
+// RephraseTrivia rephrases a curated trivia in a lighter tone, keeping its facts
+func (s *AIService) RephraseTrivia(ctx context.Context, fact string) (string, error) {
+	content, err := s.complete(ctx, triviaSystemPrompt, fact)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(strings.ReplaceAll(content, "\n", ""))
+	switch {
+	case text == "":
+		return "", fmt.Errorf("empty trivia")
+	case utf8.RuneCountInString(text) > triviaMaxRunes:
+		return "", fmt.Errorf("trivia too long")
+	case containsLink(text):
+		return "", fmt.Errorf("trivia contains a link")
+	}
+	return text, nil
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// uvProtectionIndex is the UV index from which sun protection is advised
+	uvProtectionIndex = 3
+	// maxUVCurveHours is the number of daylight hours shown in the /uv curve
+	maxUVCurveHours = 16
+)
+
+// UVHour is the estimated UV index of one daylight hour
+type UVHour struct {
+	Time  string  // HH:MM
+	Index float64 // Estimated UV index
+}
+
+// UVForecast is the hour-by-hour UV curve of a location. QWeather only forecasts the daily
+// maximum UV index, so each hour's index is estimated by scaling the maximum with the hour's
+// share of the peak solar irradiance.
+type UVForecast struct {
+	MaxIndex int      // Daily maximum UV index
+	Hours    []UVHour // Daylight hours, in order
+	Peak     UVHour   // Hour with the highest UV index
+}
+
+// GetUVForecast builds the UV curve from the daily UV index and the solar radiation forecast
+func (s *WeatherService) GetUVForecast(locationID, lat, lon string) (*UVForecast, error) {
+	daily, err := s.client.GetDailyForecast(locationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get UV index: %w", err)
+	}
+	maxIndex, err := strconv.Atoi(daily.UvIndex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid UV index %q", daily.UvIndex)
+	}
+	radiation, err := s.client.GetSolarRadiation(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	type hourIrradiance struct {
+		time  string
+		value float64
+	}
+	var hours []hourIrradiance
+	peakValue := 0.0
+	for _, r := range radiation {
+		elevation, _ := strconv.ParseFloat(r.Elevation, 64)
+		if elevation <= 0 {
+			if len(hours) > 0 {
+				break // only the first stretch of daylight
+			}
+			continue
+		}
+		direct, _ := strconv.ParseFloat(r.Direct, 64)
+		diffuse, _ := strconv.ParseFloat(r.Diffuse, 64)
+		// Global horizontal irradiance: the direct beam projected on the ground plus diffuse light
+		value := direct*math.Sin(elevation*math.Pi/180) + diffuse
+		hours = append(hours, hourIrradiance{time: hourOf(r.FxTime), value: value})
+		peakValue = math.Max(peakValue, value)
+		if len(hours) == maxUVCurveHours {
+			break
+		}
+	}
+	if len(hours) == 0 || peakValue == 0 {
+		return nil, fmt.Errorf("no daylight in solar radiation forecast")
+	}
+
+	forecast := &UVForecast{MaxIndex: maxIndex}
+	for _, h := range hours {
+		uv := UVHour{Time: h.time, Index: math.Round(float64(maxIndex)*h.value/peakValue*10) / 10}
+		forecast.Hours = append(forecast.Hours, uv)
+		if uv.Index > forecast.Peak.Index {
+			forecast.Peak = uv
+		}
+	}
+	return forecast, nil
+}
+
+// ProtectionWindow returns the first and last hour whose UV index calls for sun protection
+func (f *UVForecast) ProtectionWindow() (from, to string, ok bool) {
+	for _, h := range f.Hours {
+		if h.Index >= uvProtectionIndex {
+			if from == "" {
+				from = h.Time
+			}
+			to = h.Time
+		}
+	}
+	return from, to, from != ""
+}
+
+// GetUVReport generates the /uv report: the hour-by-hour UV curve and sunscreen advice
+func (s *WeatherService) GetUVReport(city string) (string, error) {
+	location, err := s.ResolveLocation(city, "")
+	if err != nil {
+		return "", err
+	}
+	forecast, err := s.GetUVForecast(location.ID, location.Lat, location.Lon)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("☀️ %s 紫外线预报\n\n", LocationLabel(city, location)))
+	b.WriteString(fmt.Sprintf("今日最强：%d（%s），约 %s 达到峰值\n\n", forecast.MaxIndex, uvLevel(float64(forecast.MaxIndex)), forecast.Peak.Time))
+	for _, h := range forecast.Hours {
+		bar := strings.Repeat("█", int(math.Round(h.Index)))
+		if bar == "" {
+			bar = "▏"
+		}
+		b.WriteString(fmt.Sprintf("%s %s %.1f\n", h.Time, bar, h.Index))
+	}
+	if from, to, ok := forecast.ProtectionWindow(); ok {
+		b.WriteString(fmt.Sprintf("\n🕐 需防晒时段：%s - %s\n", from, to))
+	}
+	b.WriteString("\n🧴 " + sunscreenAdvice(forecast.MaxIndex))
+	b.WriteString("\n\n💡 逐小时数值由当日紫外线指数结合太阳辐射预报估算")
+	return b.String(), nil
+}
+
+// FormatUVPeakForAI describes the UV peak and protection window for the AI prompt
+func FormatUVPeakForAI(forecast *UVForecast) string {
+	if forecast == nil {
+		return "暂无紫外线预报"
+	}
+	info := fmt.Sprintf("约 %s 达到峰值，紫外线指数约 %d（%s）", forecast.Peak.Time, forecast.MaxIndex, uvLevel(float64(forecast.MaxIndex)))
+	if from, to, ok := forecast.ProtectionWindow(); ok {
+		info += fmt.Sprintf("，%s-%s 需防晒", from, to)
+	}
+	return info
+}
+
+// uvLevel names a UV index level
+func uvLevel(index float64) string {
+	switch {
+	case index >= 11:
+		return "极强"
+	case index >= 8:
+		return "很强"
+	case index >= 6:
+		return "强"
+	case index >= 3:
+		return "中等"
+	default:
+		return "弱"
+	}
+}
+
+// sunscreenAdvice gives sun protection advice for the daily maximum UV index
+func sunscreenAdvice(maxIndex int) string {
+	switch {
+	case maxIndex >= 11:
+		return "紫外线极强：尽量避免外出，必须外出时穿长袖、戴宽檐帽和太阳镜，涂抹 SPF50+ PA++++ 防晒霜"
+	case maxIndex >= 8:
+		return "紫外线很强：涂抹 SPF50+ PA+++ 防晒霜，每 2 小时补涂，10-16 点减少户外活动"
+	case maxIndex >= 6:
+		return "紫外线强：涂抹 SPF30+ PA++ 防晒霜，戴帽子和太阳镜，避免正午长时间暴晒"
+	case maxIndex >= 3:
+		return "紫外线中等：外出涂抹 SPF15+ 防晒霜，可戴遮阳帽"
+	default:
+		return "紫外线弱：无需特别防护"
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/tts"
+	"go.uber.org/zap"
+)
+
+// maxSpeechRunes caps the text read aloud so voice messages stay a few minutes long
+const maxSpeechRunes = 1500
+
+// speechReplacer spells out symbols that text-to-speech engines read poorly
+var speechReplacer = strings.NewReplacer(
+	"°C", "度",
+	"℃", "度",
+	"~", "到",
+	"～", "到",
+)
+
+// VoiceService reads messages aloud and sends them as Telegram voice messages
+type VoiceService struct {
+	synth    tts.Synthesizer
+	telegram *notify.TelegramNotifier
+	timeout  time.Duration
+}
+
+// NewVoiceService creates a new VoiceService
+func NewVoiceService(synth tts.Synthesizer, telegram *notify.TelegramNotifier, timeout time.Duration) *VoiceService {
+	return &VoiceService{
+		synth:    synth,
+		telegram: telegram,
+		timeout:  timeout,
+	}
+}
+
+// Send synthesizes the message body and sends it to the chat as a voice message through the
+// named bot
+func (s *VoiceService) Send(ctx context.Context, bot string, chatID int64, msg notify.Message) error {
+	text := SpeechText(msg.Body)
+	if text == "" {
+		return fmt.Errorf("message has no speakable text")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	start := time.Now()
+	audio, err := s.synth.Synthesize(ctx, text)
+	if err != nil {
+		return fmt.Errorf("failed to synthesize speech: %w", err)
+	}
+	if err := s.telegram.For(bot).SendVoice(chatID, audio.Data, audio.MIME); err != nil {
+		return err
+	}
+
+	logger.Debug("Voice message sent",
+		zap.Int64("chat_id", chatID),
+		zap.Int("audio_bytes", len(audio.Data)),
+		zap.Duration("duration", time.Since(start)))
+	return nil
+}
+
+// SpeechText prepares a message for reading aloud: emoji, bullets and separator lines are removed,
+// units are spelled out and the result is capped at maxSpeechRunes.
+func SpeechText(body string) string {
+	body = speechReplacer.Replace(body)
+
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(strings.Map(func(r rune) rune {
+			switch {
+			case unicode.Is(unicode.So, r), unicode.Is(unicode.Sk, r), unicode.In(r, unicode.Variation_Selector):
+				return -1
+			case r == '\u200d', r == '•', r == '·':
+				return -1
+			}
+			return r
+		}, line))
+		if !hasSpeakable(line) {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	text := []rune(strings.Join(lines, "\n"))
+	if len(text) > maxSpeechRunes {
+		text = text[:maxSpeechRunes]
+	}
+	return string(text)
+}
+
+// hasSpeakable reports whether a line contains letters or digits
+func hasSpeakable(line string) bool {
+	return strings.IndexFunc(line, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) >= 0
+}
@@ -3,10 +3,13 @@ package service
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
 	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
 	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
@@ -14,12 +17,33 @@ import (
 	tele "gopkg.in/telebot.v3"
 )
 
+// Callback button endpoints of warning notifications, handled by the bot
+const (
+	WarningActionAir     = "warning_air"     // Data: city
+	WarningActionWeather = "warning_weather" // Data: city
+	WarningActionMute    = "warning_mute"    // Data: subscription ID
+)
+
+// maxWarningActionCityBytes keeps the city within Telegram's 64-byte callback data limit
+const maxWarningActionCityBytes = 40
+
 // WarningService handles weather warning notifications
 type WarningService struct {
 	client      *qweather.Client
 	warningRepo *repository.WarningLogRepository
 	subRepo     *repository.SubscriptionRepository
-	bot         *tele.Bot
+	muteRepo    *repository.WarningMuteRepository
+	catalog     *WarningCatalog
+	webhookSvc  *WebhookService
+	notifySvc   *NotificationService
+	mqttSvc     *MQTTService
+	events      *EventLogService // nil when business events are disabled
+
+	scopeMu  sync.Mutex
+	heldBack map[string]map[uint]bool // Warning ID -> subscriptions held back outside its area
+
+	districts        locationCache         // Districts of subscription coordinates
+	districtWarnings districtWarningsCache // Warning IDs of districts, by location ID
 }
 
 // NewWarningService creates a new WarningService
@@ -27,16 +51,30 @@ func NewWarningService(
 	client *qweather.Client,
 	warningRepo *repository.WarningLogRepository,
 	subRepo *repository.SubscriptionRepository,
-	bot *tele.Bot,
+	muteRepo *repository.WarningMuteRepository,
+	catalog *WarningCatalog,
+	webhookSvc *WebhookService,
+	notifySvc *NotificationService,
+	mqttSvc *MQTTService,
 ) *WarningService {
 	return &WarningService{
 		client:      client,
 		warningRepo: warningRepo,
 		subRepo:     subRepo,
-		bot:         bot,
+		muteRepo:    muteRepo,
+		catalog:     catalog,
+		webhookSvc:  webhookSvc,
+		notifySvc:   notifySvc,
+		mqttSvc:     mqttSvc,
+		heldBack:    make(map[string]map[uint]bool),
 	}
 }
 
+// SetEventLog makes the service emit a warning_pushed event for every warning pushed to a subscription
+func (s *WarningService) SetEventLog(events *EventLogService) {
+	s.events = events
+}
+
 // GetWarnings retrieves weather warnings for a city
 func (s *WarningService) GetWarnings(city string) ([]qweather.Warning, error) {
 	logger.Debug("GetWarnings called", zap.String("city", city))
@@ -115,8 +153,9 @@ func (s *WarningService) GetWarningReport(city string) (string, error) {
 	return report.String(), nil
 }
 
-// CheckAndNotify checks for new warnings and notifies subscribed users
-func (s *WarningService) CheckAndNotify(ctx context.Context) error {
+// CheckAndNotify checks for new warnings and notifies subscribed users, limited to one city when
+// city is not "". Cities that fail don't stop the others; they fail the sweep when it ends.
+func (s *WarningService) CheckAndNotify(ctx context.Context, city string) (JobResult, error) {
 	logger.Debug("CheckAndNotify called")
 	start := time.Now()
 
@@ -124,13 +163,13 @@ func (s *WarningService) CheckAndNotify(ctx context.Context) error {
 	subs, err := s.subRepo.GetAllActive()
 	if err != nil {
 		logger.Error("Failed to get subscriptions", zap.Error(err))
-		return fmt.Errorf("failed to get subscriptions: %w", err)
+		return JobResult{}, fmt.Errorf("failed to get subscriptions: %w", err)
 	}
 
 	// Group subscriptions by city to avoid duplicate API calls
 	cityMap := make(map[string][]model.Subscription)
 	for _, sub := range subs {
-		if sub.Active && sub.EnableWarning {
+		if sub.Active && sub.EnableWarning && (city == "" || sub.City == city) {
 			cityMap[sub.City] = append(cityMap[sub.City], sub)
 		}
 	}
@@ -139,18 +178,29 @@ func (s *WarningService) CheckAndNotify(ctx context.Context) error {
 		zap.Int("city_count", len(cityMap)))
 
 	// Check warnings for each city
+	result := JobResult{Items: len(cityMap)}
+	var firstErr error
 	for city, citySubs := range cityMap {
 		if err := s.checkCityWarnings(ctx, city, citySubs); err != nil {
 			logger.Warn("Failed to check warnings for city",
 				zap.String("city", city),
 				zap.Error(err))
 			// Continue with other cities even if one fails
+			result.Failures++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", city, err)
+			}
 		}
 	}
 
 	logger.Debug("CheckAndNotify completed",
+		zap.Int("city_count", result.Items),
+		zap.Int("failed_count", result.Failures),
 		zap.Duration("duration", time.Since(start)))
-	return nil
+	if firstErr != nil {
+		return result, fmt.Errorf("%d of %d cities failed, first %w", result.Failures, result.Items, firstErr)
+	}
+	return result, nil
 }
 
 // checkCityWarnings checks warnings for a specific city and notifies users
@@ -177,9 +227,15 @@ func (s *WarningService) checkCityWarnings(ctx context.Context, city string, sub
 		currentWarningIDs[w.ID] = true
 	}
 
+	// Resolve which warnings cover the subscriptions with coordinates
+	var scope warningScope
+	if len(currentWarnings) > 0 {
+		scope = s.scopeWarnings(locationID, subs)
+	}
+
 	// Process each current warning (handles NEW and MODIFIED scenarios)
 	for _, warning := range currentWarnings {
-		if err := s.processWarning(ctx, city, locationID, warning, subs); err != nil {
+		if err := s.processWarning(ctx, city, locationID, warning, scope, subs); err != nil {
 			logger.Warn("Failed to process warning",
 				zap.String("warning_id", warning.ID),
 				zap.Error(err))
@@ -225,8 +281,11 @@ func (s *WarningService) processWarning(
 	city string,
 	locationID string,
 	warning qweather.Warning,
+	scope warningScope,
 	subs []model.Subscription,
 ) error {
+	s.catalog.Learn(warning.Type, warning.TypeName)
+
 	// Check if we've already notified about this warning
 	existingLog, err := s.warningRepo.GetByWarningID(warning.ID)
 	if err != nil {
@@ -283,12 +342,22 @@ func (s *WarningService) processWarning(
 
 	// Format notification message
 	message := s.formatWarningMessage(city, warning)
+	recipients := s.unmutedSubscriptions(warning.Type, s.scopedRecipients(warning.ID, scope, subs))
 
 	// Send to all subscribers
 	successCount := 0
-	for _, sub := range subs {
-		recipient := &tele.User{ID: sub.User.ChatID}
-		if _, err := s.bot.Send(recipient, message); err != nil {
+	notification := notify.Message{
+		Title:    fmt.Sprintf("%s %s", city, warning.Title),
+		Body:     message,
+		Priority: notify.PriorityHigh,
+	}
+	for _, sub := range recipients {
+		personal := notification
+		personal.Body = strings.TrimRight(message, "\n") + "\n\n" + subscriptionContext(sub)
+		if warning.Status != "cancel" {
+			personal.Markup = warningMarkup(sub)
+		}
+		if err := s.notifySvc.Deliver(ctx, sub, personal); err != nil {
 			logger.Warn("Failed to send warning notification",
 				zap.Uint("user_id", sub.UserID),
 				zap.Int64("chat_id", sub.User.ChatID),
@@ -297,6 +366,7 @@ func (s *WarningService) processWarning(
 			successCount++
 			logger.Debug("Warning notification sent",
 				zap.Uint("user_id", sub.UserID))
+			s.emitWarningPushed(sub, warning.ID, warning.Type, warning.Level, changeReason)
 		}
 	}
 
@@ -304,7 +374,23 @@ func (s *WarningService) processWarning(
 		zap.String("warning_id", warning.ID),
 		zap.String("change_reason", changeReason),
 		zap.Int("success_count", successCount),
-		zap.Int("total_count", len(subs)))
+		zap.Int("total_count", len(recipients)),
+		zap.Int("skipped_count", len(subs)-len(recipients)))
+
+	s.notifySvc.Broadcast(ctx, model.WebhookEventWarning, city, notification)
+	s.publishWarning(subs, WarningEvent{
+		City:          city,
+		WarningID:     warning.ID,
+		Title:         warning.Title,
+		Type:          warning.Type,
+		TypeName:      s.catalog.Name(warning.Type),
+		Level:         warning.Level,
+		SeverityColor: warning.SeverityColor,
+		Status:        warning.Status,
+		Text:          warning.Text,
+		StartTime:     warning.StartTime,
+		EndTime:       warning.EndTime,
+	})
 
 	// Update or create warning log
 	now := time.Now()
@@ -379,17 +465,26 @@ func (s *WarningService) sendResolvedNotification(city string, log model.Warning
 	var msg strings.Builder
 	msg.WriteString(fmt.Sprintf("✅ %s 预警解除\n\n", city))
 	msg.WriteString(fmt.Sprintf("📢 %s\n", log.Title))
+	if log.Type != "" {
+		msg.WriteString(fmt.Sprintf("预警类型：%s\n", s.catalog.Name(log.Type)))
+	}
 	msg.WriteString("该预警已解除，不再有效。\n")
 	msg.WriteString(fmt.Sprintf("\n原预警时间：%s - %s",
 		log.StartTime.Format("2006-01-02 15:04"),
 		log.EndTime.Format("2006-01-02 15:04")))
 
-	message := msg.String()
+	notification := notify.Message{
+		Title:    fmt.Sprintf("%s 预警解除：%s", city, log.Title),
+		Body:     msg.String(),
+		Priority: notify.PriorityHigh,
+	}
 
+	recipients := s.unmutedSubscriptions(log.Type, s.notifiedRecipients(log.WarningID, subs))
 	successCount := 0
-	for _, sub := range subs {
-		recipient := &tele.User{ID: sub.User.ChatID}
-		if _, err := s.bot.Send(recipient, message); err != nil {
+	for _, sub := range recipients {
+		personal := notification
+		personal.Body = notification.Body + "\n\n" + subscriptionContext(sub)
+		if err := s.notifySvc.Deliver(context.Background(), sub, personal); err != nil {
 			logger.Warn("Failed to send resolved notification",
 				zap.Uint("user_id", sub.UserID),
 				zap.Int64("chat_id", sub.User.ChatID),
@@ -402,7 +497,295 @@ func (s *WarningService) sendResolvedNotification(city string, log model.Warning
 	logger.Info("Resolved notifications sent",
 		zap.String("warning_id", log.WarningID),
 		zap.Int("success_count", successCount),
-		zap.Int("total_count", len(subs)))
+		zap.Int("total_count", len(recipients)))
+
+	s.notifySvc.Broadcast(context.Background(), model.WebhookEventWarning, city, notification)
+	s.publishWarning(subs, WarningEvent{
+		City:      city,
+		WarningID: log.WarningID,
+		Title:     log.Title,
+		Type:      log.Type,
+		TypeName:  s.catalog.Name(log.Type),
+		Level:     log.Level,
+		Status:    "resolved",
+		StartTime: log.StartTime.Format(time.RFC3339),
+		EndTime:   log.EndTime.Format(time.RFC3339),
+	})
+}
+
+// NotifyActive sends the warnings in effect in a new subscription's city to it once, since the
+// sweep only notifies subscribers when a warning is issued or changes. The details come from
+// the warnings API; when it fails, a summary from the warning log is sent instead. Warnings no
+// longer returned by the API are skipped, the next sweep resolves them, and so are warnings that
+// don't cover the subscription's coordinates. Returns the number sent.
+func (s *WarningService) NotifyActive(ctx context.Context, subscriptionID uint) (int, error) {
+	sub, err := s.subRepo.FindByIDWithUser(subscriptionID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find subscription: %w", err)
+	}
+	if sub == nil || !sub.Active || !sub.EnableWarning {
+		return 0, nil
+	}
+
+	logs, err := s.warningRepo.GetUnresolvedWarningsByCity(sub.City)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unresolved warnings: %w", err)
+	}
+	var active []model.WarningLog
+	for _, log := range logs {
+		if log.Status != "cancel" {
+			active = append(active, log)
+		}
+	}
+	if len(active) == 0 {
+		return 0, nil
+	}
+
+	current := make(map[string]qweather.Warning)
+	warnings, apiErr := s.GetWarnings(sub.City)
+	for _, w := range warnings {
+		current[w.ID] = w
+	}
+	var scope warningScope
+	if apiErr == nil {
+		scope = s.scopeWarnings("", []model.Subscription{*sub})
+	}
+
+	sent := 0
+	for _, log := range active {
+		var message string
+		if apiErr != nil {
+			message = formatWarningSummary(sub.City, log)
+		} else if warning, ok := current[log.WarningID]; ok {
+			message = s.formatWarningMessage(sub.City, warning)
+		} else {
+			continue
+		}
+		if !scope.covers(*sub, log.WarningID) {
+			s.markNotified(log.WarningID, sub.ID, false)
+			continue
+		}
+		if len(s.unmutedSubscriptions(log.Type, []model.Subscription{*sub})) == 0 {
+			continue
+		}
+
+		err := s.notifySvc.Deliver(ctx, *sub, notify.Message{
+			Title:    fmt.Sprintf("%s %s", sub.City, log.Title),
+			Body:     "📣 订阅城市正在生效的预警\n\n" + strings.TrimRight(message, "\n") + "\n\n" + subscriptionContext(*sub),
+			Priority: notify.PriorityHigh,
+			Markup:   warningMarkup(*sub),
+		})
+		if err != nil {
+			logger.Warn("Failed to send active warning to new subscription",
+				zap.Uint("subscription_id", sub.ID),
+				zap.String("warning_id", log.WarningID),
+				zap.Error(err))
+			continue
+		}
+		s.markNotified(log.WarningID, sub.ID, true)
+		s.emitWarningPushed(*sub, log.WarningID, log.Type, log.Level, "active")
+		sent++
+	}
+
+	logger.Info("Active warnings sent to new subscription",
+		zap.Uint("subscription_id", sub.ID),
+		zap.String("city", sub.City),
+		zap.Int("sent_count", sent),
+		zap.Int("active_count", len(active)))
+	return sent, nil
+}
+
+// emitWarningPushed records a warning pushed to a subscription in the event log; reason is why it
+// was pushed ("new", "status_changed", "level_changed", "content_changed", or "active" for a
+// new subscription)
+func (s *WarningService) emitWarningPushed(sub model.Subscription, warningID, warningType, level, reason string) {
+	if s.events == nil {
+		return
+	}
+	s.events.Emit(BusinessEvent{
+		Name:           model.EventWarningPushed,
+		UserID:         sub.UserID,
+		SubscriptionID: sub.ID,
+		City:           sub.City,
+		Data: map[string]any{
+			"warning_id": warningID,
+			"type":       warningType,
+			"level":      level,
+			"reason":     reason,
+		},
+	})
+}
+
+// formatWarningSummary formats a warning from its log entry, for when the warnings API is unavailable
+func formatWarningSummary(city string, log model.WarningLog) string {
+	var msg strings.Builder
+	msg.WriteString(fmt.Sprintf("⚠️ %s 天气预警\n\n", city))
+	msg.WriteString(fmt.Sprintf("📢 %s\n", log.Title))
+	if !log.StartTime.IsZero() && !log.EndTime.IsZero() {
+		msg.WriteString(fmt.Sprintf("生效时间：%s - %s\n",
+			log.StartTime.Format("2006-01-02 15:04"),
+			log.EndTime.Format("2006-01-02 15:04")))
+	}
+	return msg.String()
+}
+
+// DisableWarnings turns off warning pushes for one of a user's subscriptions and returns it;
+// returns nil when the subscription does not exist or belongs to another user
+func (s *WarningService) DisableWarnings(userID, subscriptionID uint) (*model.Subscription, error) {
+	sub, err := s.subRepo.FindByID(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || sub.UserID != userID {
+		return nil, nil
+	}
+	if !sub.EnableWarning {
+		return sub, nil
+	}
+	sub.EnableWarning = false
+	if err := s.subRepo.Update(sub); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Warning notifications disabled from warning push",
+		zap.Uint("user_id", userID),
+		zap.Uint("subscription_id", subscriptionID),
+		zap.String("city", sub.City))
+	return sub, nil
+}
+
+// MutedTypes returns the warning types muted for a subscription, as a set of type codes
+func (s *WarningService) MutedTypes(subscriptionID uint) (map[string]bool, error) {
+	types, err := s.muteRepo.FindTypesBySubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	muted := make(map[string]bool, len(types))
+	for _, t := range types {
+		muted[t] = true
+	}
+	return muted, nil
+}
+
+// ToggleMute mutes or unmutes a warning type for one of a user's subscriptions and returns the
+// subscription and whether the type is now muted; the subscription is nil when it does not
+// exist or belongs to another user
+func (s *WarningService) ToggleMute(userID, subscriptionID uint, warningType string) (*model.Subscription, bool, error) {
+	sub, err := s.subRepo.FindByID(subscriptionID)
+	if err != nil {
+		return nil, false, err
+	}
+	if sub == nil || sub.UserID != userID {
+		return nil, false, nil
+	}
+	muted, err := s.MutedTypes(subscriptionID)
+	if err != nil {
+		return nil, false, err
+	}
+	if muted[warningType] {
+		err = s.muteRepo.Unmute(subscriptionID, warningType)
+	} else {
+		err = s.muteRepo.Mute(userID, subscriptionID, warningType)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	logger.Info("Warning type mute toggled",
+		zap.Uint("user_id", userID),
+		zap.Uint("subscription_id", subscriptionID),
+		zap.String("warning_type", warningType),
+		zap.Bool("muted", !muted[warningType]))
+	return sub, !muted[warningType], nil
+}
+
+// unmutedSubscriptions filters out the subscriptions that muted a warning type. On lookup
+// failure all subscriptions are kept, so warnings are never lost.
+func (s *WarningService) unmutedSubscriptions(warningType string, subs []model.Subscription) []model.Subscription {
+	if s.muteRepo == nil || warningType == "" {
+		return subs
+	}
+	ids, err := s.muteRepo.FindSubscriptionIDsByType(warningType)
+	if err != nil {
+		logger.Warn("Failed to load warning mutes", zap.String("warning_type", warningType), zap.Error(err))
+		return subs
+	}
+	if len(ids) == 0 {
+		return subs
+	}
+	muted := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		muted[id] = true
+	}
+	var recipients []model.Subscription
+	for _, sub := range subs {
+		if !muted[sub.ID] {
+			recipients = append(recipients, sub)
+		}
+	}
+	return recipients
+}
+
+// WarningTypes returns the warning type catalog ordered by code
+func (s *WarningService) WarningTypes() []qweather.WarningType {
+	return s.catalog.Types()
+}
+
+// WarningTypeName returns the name of a warning type code, or the code when unknown
+func (s *WarningService) WarningTypeName(code string) string {
+	return s.catalog.Name(code)
+}
+
+// SyncWarningTypes refreshes the warning type catalog from the QWeather API
+func (s *WarningService) SyncWarningTypes() error {
+	return s.catalog.Sync()
+}
+
+// subscriptionContext names the subscription a warning notification was sent for
+func subscriptionContext(sub model.Subscription) string {
+	place := sub.City
+	if sub.HasCoordinates() {
+		place = fmt.Sprintf("%s（定位 %s,%s）", sub.City, sub.Lat, sub.Lon)
+	}
+	return fmt.Sprintf("📌 触发订阅：%s · 每日 %s 提醒", place, sub.ReminderTime)
+}
+
+// warningMarkup builds the action buttons of a warning notification: air quality and weather of
+// the city (when it fits the callback data) and muting the subscription's warning pushes
+func warningMarkup(sub model.Subscription) *tele.ReplyMarkup {
+	markup := &tele.ReplyMarkup{}
+	var rows []tele.Row
+	if len(sub.City) <= maxWarningActionCityBytes {
+		rows = append(rows, markup.Row(
+			markup.Data("🌫️ 查看空气质量", WarningActionAir, sub.City),
+			markup.Data("🌤️ 今日天气", WarningActionWeather, sub.City),
+		))
+	}
+	rows = append(rows, markup.Row(
+		markup.Data("🔕 关闭此城市预警推送", WarningActionMute, strconv.FormatUint(uint64(sub.ID), 10)),
+	))
+	markup.Inline(rows...)
+	return markup
+}
+
+// publishWarning emits a warning event to MQTT and, once, to the webhooks of every distinct subscriber
+func (s *WarningService) publishWarning(subs []model.Subscription, event WarningEvent) {
+	if s.mqttSvc != nil {
+		s.mqttSvc.PublishWarning(event)
+	}
+	if s.webhookSvc == nil {
+		return
+	}
+
+	seen := make(map[uint]bool)
+	var userIDs []uint
+	for _, sub := range subs {
+		if !seen[sub.UserID] {
+			seen[sub.UserID] = true
+			userIDs = append(userIDs, sub.UserID)
+		}
+	}
+	s.webhookSvc.Publish(model.WebhookEventWarning, userIDs, event)
 }
 
 // getWarningEmoji returns an emoji based on warning severity color
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+// WarningCatalog maps warning type codes to names. It is cached in the database, refreshed from
+// the QWeather catalog API and extended with the types of received warnings; the built-in
+// catalog is used until anything is stored.
+type WarningCatalog struct {
+	client *qweather.Client
+	repo   *repository.WarningTypeRepository
+
+	mu    sync.RWMutex
+	types []qweather.WarningType // Ordered by code
+	names map[string]string
+}
+
+// NewWarningCatalog creates a WarningCatalog loaded from the database
+func NewWarningCatalog(client *qweather.Client, repo *repository.WarningTypeRepository) *WarningCatalog {
+	c := &WarningCatalog{client: client, repo: repo}
+	c.reload()
+	return c
+}
+
+// Sync refreshes the cached catalog from the QWeather API, keeping the current one on failure
+func (c *WarningCatalog) Sync() error {
+	types, err := c.client.GetWarningTypes()
+	if err != nil {
+		logger.Warn("Failed to sync warning types, keeping cached catalog", zap.Error(err))
+		return fmt.Errorf("failed to get warning types: %w", err)
+	}
+	if err := c.repo.Upsert(toWarningTypeModels(types)); err != nil {
+		return err
+	}
+	c.reload()
+	logger.Info("Warning types synced", zap.Int("count", len(types)))
+	return nil
+}
+
+// Learn records the type of a received warning when its code is new or its name changed
+func (c *WarningCatalog) Learn(code, name string) {
+	if code == "" || name == "" {
+		return
+	}
+	c.mu.RLock()
+	known := c.names[code] == name
+	c.mu.RUnlock()
+	if known {
+		return
+	}
+	if err := c.repo.Upsert([]model.WarningType{{Code: code, Name: name}}); err != nil {
+		return
+	}
+	c.reload()
+	logger.Info("Warning type learned", zap.String("code", code), zap.String("name", name))
+}
+
+// Name returns the name of a warning type code, or the code when unknown
+func (c *WarningCatalog) Name(code string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if name, ok := c.names[code]; ok {
+		return name
+	}
+	return code
+}
+
+// Types returns the catalog ordered by code
+func (c *WarningCatalog) Types() []qweather.WarningType {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.types
+}
+
+// reload replaces the in-memory catalog with the stored one, seeding the database with the
+// built-in catalog when it is empty
+func (c *WarningCatalog) reload() {
+	stored, err := c.repo.FindAll()
+	if err != nil {
+		logger.Warn("Failed to load warning types", zap.Error(err))
+	}
+	if err == nil && len(stored) == 0 {
+		if err := c.repo.Upsert(toWarningTypeModels(qweather.BuiltinWarningTypes)); err == nil {
+			stored = toWarningTypeModels(qweather.BuiltinWarningTypes)
+		}
+	}
+
+	types := make([]qweather.WarningType, 0, len(stored))
+	names := make(map[string]string, len(stored))
+	for _, t := range stored {
+		types = append(types, qweather.WarningType{Code: t.Code, Name: t.Name})
+		names[t.Code] = t.Name
+	}
+	if len(types) == 0 {
+		types = append(types, qweather.BuiltinWarningTypes...)
+		for _, t := range types {
+			names[t.Code] = t.Name
+		}
+	}
+	sort.SliceStable(types, func(i, j int) bool { return types[i].Code < types[j].Code })
+
+	c.mu.Lock()
+	c.types, c.names = types, names
+	c.mu.Unlock()
+}
+
+// toWarningTypeModels converts catalog entries to their database models
+func toWarningTypeModels(types []qweather.WarningType) []model.WarningType {
+	models := make([]model.WarningType, 0, len(types))
+	for _, t := range types {
+		if t.Code != "" && t.Name != "" {
+			models = append(models, model.WarningType{Code: t.Code, Name: t.Name})
+		}
+	}
+	return models
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+	"go.uber.org/zap"
+)
+
+// districtWarningsTTL is how long the warnings of a district are reused. It is shorter than the
+// 15-minute warning sweep, so every sweep sees fresh warnings while the subscriptions of a sweep,
+// and the warnings pushed outside it, share one request per district.
+const districtWarningsTTL = 10 * time.Minute
+
+// districtWarningsEntry holds the IDs of the warnings covering a district
+type districtWarningsEntry struct {
+	ids       map[string]bool
+	expiresAt time.Time
+}
+
+// districtWarningsCache caches the warnings of districts by location ID
+type districtWarningsCache struct {
+	mu      sync.Mutex
+	entries map[string]districtWarningsEntry
+}
+
+// get returns the unexpired warning IDs of a district
+func (c *districtWarningsCache) get(locationID string) (map[string]bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[locationID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, locationID)
+		return nil, false
+	}
+	return entry.ids, true
+}
+
+// put caches the warning IDs of a district
+func (c *districtWarningsCache) put(locationID string, ids map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]districtWarningsEntry)
+	}
+	c.entries[locationID] = districtWarningsEntry{ids: ids, expiresAt: time.Now().Add(districtWarningsTTL)}
+}
+
+// warningScope holds, for subscriptions with coordinates, the IDs of the warnings covering their
+// location. Subscriptions without coordinates, or whose location could not be resolved, are
+// absent and receive every warning of the city.
+type warningScope map[uint]map[string]bool
+
+// covers reports whether a warning covers the subscription's location
+func (sc warningScope) covers(sub model.Subscription, warningID string) bool {
+	ids, ok := sc[sub.ID]
+	return !ok || ids[warningID]
+}
+
+// scopeWarnings resolves the warnings covering the location of each subscription with coordinates.
+// Provincial and city warnings often apply to a few districts only, so the coordinates are
+// resolved to their district with the GeoAPI and the district's warnings are fetched. Districts
+// are cached by coordinates for locationCacheTTL and their warnings by location ID for
+// districtWarningsTTL. Lookups that fail are left out and not cached, so their subscribers
+// aren't missed.
+func (s *WarningService) scopeWarnings(cityLocationID string, subs []model.Subscription) warningScope {
+	scope := make(warningScope)
+	for _, sub := range subs {
+		if !sub.HasCoordinates() {
+			continue
+		}
+		location, err := s.subscriptionDistrict(sub)
+		if err != nil {
+			logger.Warn("Failed to resolve subscription district for warnings",
+				zap.Uint("subscription_id", sub.ID),
+				zap.Error(err))
+			continue
+		}
+		if location.ID == cityLocationID {
+			continue
+		}
+		if ids, ok := s.districtWarningIDs(location.ID); ok {
+			scope[sub.ID] = ids
+		}
+	}
+	return scope
+}
+
+// subscriptionDistrict resolves the district of a subscription's coordinates
+func (s *WarningService) subscriptionDistrict(sub model.Subscription) (*qweather.GeoLocation, error) {
+	query := sub.LocationQuery()
+	if location, ok := s.districts.get(query); ok {
+		return location, nil
+	}
+	location, err := s.client.GetLocation(query)
+	if err != nil {
+		return nil, err
+	}
+	s.districts.put(query, location)
+	return location, nil
+}
+
+// districtWarningIDs returns the IDs of the warnings covering a district, or false when they
+// could not be fetched
+func (s *WarningService) districtWarningIDs(locationID string) (map[string]bool, bool) {
+	if ids, ok := s.districtWarnings.get(locationID); ok {
+		return ids, true
+	}
+	warnings, err := s.client.GetWarningNow(locationID)
+	if err != nil {
+		logger.Warn("Failed to get district warnings",
+			zap.String("location_id", locationID),
+			zap.Error(err))
+		return nil, false
+	}
+	ids := make(map[string]bool, len(warnings))
+	for _, w := range warnings {
+		ids[w.ID] = true
+	}
+	s.districtWarnings.put(locationID, ids)
+	return ids, true
+}
+
+// scopedRecipients filters out the subscriptions outside a warning's area. Subscriptions held back
+// when the warning was issued stay held back for its updates and cancellation; subscriptions that
+// were notified earlier keep receiving its updates. Warnings not seen since startup are treated as
+// new, so a restart doesn't notify subscribers outside their area.
+func (s *WarningService) scopedRecipients(warningID string, scope warningScope, subs []model.Subscription) []model.Subscription {
+	s.scopeMu.Lock()
+	defer s.scopeMu.Unlock()
+
+	held, known := s.heldBack[warningID]
+	next := make(map[uint]bool)
+	var recipients []model.Subscription
+	for _, sub := range subs {
+		if !scope.covers(sub, warningID) && (!known || held[sub.ID]) {
+			next[sub.ID] = true
+			continue
+		}
+		recipients = append(recipients, sub)
+	}
+	s.heldBack[warningID] = next
+	if len(next) > 0 {
+		logger.Debug("Warning held back outside its area",
+			zap.String("warning_id", warningID),
+			zap.Int("held_count", len(next)))
+	}
+	return recipients
+}
+
+// notifiedRecipients filters out the subscriptions held back from a warning, for its resolved
+// notice, and forgets the warning
+func (s *WarningService) notifiedRecipients(warningID string, subs []model.Subscription) []model.Subscription {
+	s.scopeMu.Lock()
+	defer s.scopeMu.Unlock()
+
+	held := s.heldBack[warningID]
+	delete(s.heldBack, warningID)
+	if len(held) == 0 {
+		return subs
+	}
+	var recipients []model.Subscription
+	for _, sub := range subs {
+		if !held[sub.ID] {
+			recipients = append(recipients, sub)
+		}
+	}
+	return recipients
+}
+
+// markNotified records whether a subscription received a warning outside the regular sweep.
+// Warnings not seen since startup are left unknown.
+func (s *WarningService) markNotified(warningID string, subscriptionID uint, notified bool) {
+	s.scopeMu.Lock()
+	defer s.scopeMu.Unlock()
+
+	held, ok := s.heldBack[warningID]
+	if !ok {
+		return
+	}
+	if notified {
+		delete(held, subscriptionID)
+	} else {
+		held[subscriptionID] = true
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/mockqweather"
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+)
+
+// Subscriptions in one district share its lookups, and later sweeps reuse them
+func TestScopeWarningsCachesDistricts(t *testing.T) {
+	mock := mockqweather.New(mockqweather.Options{KnownCitiesOnly: true})
+	mock.AddCity(qweather.GeoLocation{Name: "海淀", ID: "101010200", Lat: "39.96", Lon: "116.30"})
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	s := &WarningService{client: qweather.NewClient("test-key", server.URL)}
+
+	subs := []model.Subscription{
+		{ID: 1, City: "北京", Lat: "39.95", Lon: "116.31"},
+		{ID: 2, City: "北京", Lat: "39.97", Lon: "116.29"},
+		{ID: 3, City: "北京", Lat: "39.90", Lon: "116.40"}, // The city itself
+		{ID: 4, City: "北京"},                              // No coordinates
+	}
+	for sweep := 0; sweep < 2; sweep++ {
+		scope := s.scopeWarnings("101010100", subs)
+		if _, ok := scope[1]; !ok {
+			t.Fatalf("sweep %d: subscription 1 not scoped", sweep)
+		}
+		if _, ok := scope[2]; !ok {
+			t.Fatalf("sweep %d: subscription 2 not scoped", sweep)
+		}
+		if len(scope) != 2 {
+			t.Fatalf("sweep %d: scoped %d subscriptions, want 2", sweep, len(scope))
+		}
+	}
+
+	lookups, warnings := 0, 0
+	for _, path := range mock.Requests() {
+		switch {
+		case strings.HasPrefix(path, "/geo/"):
+			lookups++
+		case strings.HasPrefix(path, "/v7/warning/now"):
+			warnings++
+		}
+	}
+	if lookups != 3 || warnings != 1 {
+		t.Errorf("made %d lookups and %d warning requests, want 3 and 1", lookups, warnings)
+	}
+}
@@ -12,7 +12,8 @@ import (
 
 // WeatherService handles weather-related business logic
 type WeatherService struct {
-	client *qweather.Client // exported via getter for scheduler access
+	client    *qweather.Client // exported via getter for scheduler access
+	locations locationCache    // Place names resolved by ResolveLocation
 }
 
 // Client returns the underlying QWeather client
@@ -176,14 +177,15 @@ func getIndexEmoji(indexType string) string {
 	}
 }
 
-// GetFullWeatherReport generates a comprehensive weather report including air quality and warnings
-func (s *WeatherService) GetFullWeatherReport(city string, airSvc *AirQualityService, warningSvc *WarningService) (string, error) {
-	logger.Debug("GetFullWeatherReport called", zap.String("city", city))
+// GetFullWeatherReport generates a comprehensive weather report including air quality and warnings.
+// city may also name a point of interest such as a scenic spot; hint selects the lookup (see ResolveLocation).
+func (s *WeatherService) GetFullWeatherReport(city, hint string, airSvc *AirQualityService, warningSvc *WarningService) (string, error) {
+	logger.Debug("GetFullWeatherReport called", zap.String("city", city), zap.String("hint", hint))
 	start := time.Now()
 
 	// Get location
 	logger.Debug("Fetching location", zap.String("city", city))
-	location, err := s.client.GetLocation(city)
+	location, err := s.ResolveLocation(city, hint)
 	if err != nil {
 		logger.Error("Failed to get location",
 			zap.String("city", city),
@@ -251,11 +253,15 @@ func (s *WeatherService) GetFullWeatherReport(city string, airSvc *AirQualitySer
 
 	// Format the report
 	var report strings.Builder
-	report.WriteString(fmt.Sprintf("📍 %s 天气播报\n\n", city))
+	report.WriteString(fmt.Sprintf("📍 %s 天气播报\n\n", LocationLabel(city, location)))
 
-	// Weather warnings at the top (if any)
+	// Weather warnings at the top (if any); points of interest use the warnings of their city
 	if warningSvc != nil {
-		warnings, err := warningSvc.GetWarnings(city)
+		warningQuery := city
+		if IsPOI(location) {
+			warningQuery = location.Lon + "," + location.Lat
+		}
+		warnings, err := warningSvc.GetWarnings(warningQuery)
 		if err != nil {
 			logger.Warn("Failed to get warnings for full report",
 				zap.String("city", city),
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/model"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/safehttp"
+	"go.uber.org/zap"
+)
+
+// Webhook request headers
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+const (
+	webhookBatchSize   = 50
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = time.Hour
+)
+
+// WebhookEndpoint is an operator-configured webhook target
+type WebhookEndpoint struct {
+	URL    string
+	Secret string
+	Events []string // Empty means all events
+}
+
+// WebhookPayload is the JSON body POSTed to webhook targets
+type WebhookPayload struct {
+	Event     string      `json:"event"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// ReminderEvent is the payload data of a reminder event
+type ReminderEvent struct {
+	SubscriptionID uint   `json:"subscription_id"`
+	ChatID         int64  `json:"chat_id"`
+	City           string `json:"city"`
+	Kind           string `json:"kind"` // reminder/fallback
+	Message        string `json:"message"`
+	Delivered      bool   `json:"delivered"` // Whether Telegram accepted the message
+}
+
+// WarningEvent is the payload data of a warning event
+type WarningEvent struct {
+	City          string `json:"city"`
+	WarningID     string `json:"warning_id"`
+	Title         string `json:"title"`
+	Type          string `json:"type"`
+	TypeName      string `json:"type_name,omitempty"` // Name of the type code from the warning type catalog
+	Level         string `json:"level"`
+	SeverityColor string `json:"severity_color,omitempty"`
+	Status        string `json:"status"` // active/update/cancel/resolved
+	Text          string `json:"text,omitempty"`
+	StartTime     string `json:"start_time,omitempty"`
+	EndTime       string `json:"end_time,omitempty"`
+}
+
+// WebhookService publishes events to webhooks through a persistent outbox with HMAC signing and retries
+type WebhookService struct {
+	repo        *repository.WebhookRepository
+	endpoints   []WebhookEndpoint
+	maxAttempts int
+
+	operatorClient *http.Client // Trusted operator endpoints
+	userClient     *http.Client // User-registered endpoints (private targets blocked unless allowed)
+
+	processing sync.Mutex
+}
+
+// NewWebhookService creates a new WebhookService
+func NewWebhookService(
+	repo *repository.WebhookRepository,
+	endpoints []WebhookEndpoint,
+	timeout time.Duration,
+	maxAttempts int,
+	allowPrivateTargets bool,
+) *WebhookService {
+	// Redirects are not followed: a webhook delivery is a single signed request
+	userClient := safehttp.NewClient(timeout, allowPrivateTargets)
+	userClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &WebhookService{
+		repo:           repo,
+		endpoints:      endpoints,
+		maxAttempts:    maxAttempts,
+		operatorClient: &http.Client{Timeout: timeout},
+		userClient:     userClient,
+	}
+}
+
+// Publish enqueues an event for all operator endpoints and the webhooks of the given users,
+// then triggers an immediate delivery attempt in the background
+func (s *WebhookService) Publish(event string, userIDs []uint, data interface{}) {
+	body, err := json.Marshal(WebhookPayload{Event: event, Timestamp: time.Now(), Data: data})
+	if err != nil {
+		logger.Error("Failed to encode webhook payload", zap.String("event", event), zap.Error(err))
+		return
+	}
+
+	now := time.Now()
+	var deliveries []model.WebhookDelivery
+	newDelivery := func(webhookID uint, target, secret string) model.WebhookDelivery {
+		return model.WebhookDelivery{
+			WebhookID:     webhookID,
+			URL:           target,
+			Secret:        secret,
+			Event:         event,
+			Payload:       string(body),
+			Status:        model.WebhookStatusPending,
+			NextAttemptAt: now,
+		}
+	}
+
+	for _, ep := range s.endpoints {
+		if subscribesTo(ep.Events, event) {
+			deliveries = append(deliveries, newDelivery(0, ep.URL, ep.Secret))
+		}
+	}
+
+	hooks, err := s.repo.FindActiveByUserIDs(userIDs)
+	if err != nil {
+		logger.Warn("Failed to load user webhooks", zap.String("event", event), zap.Error(err))
+	}
+	for _, hook := range hooks {
+		if subscribesTo(strings.Split(hook.Events, ","), event) {
+			deliveries = append(deliveries, newDelivery(hook.ID, hook.URL, hook.Secret))
+		}
+	}
+
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := s.repo.Enqueue(deliveries); err != nil {
+		return
+	}
+
+	logger.Debug("Webhook event enqueued",
+		zap.String("event", event),
+		zap.Int("deliveries", len(deliveries)))
+
+	go s.ProcessOutbox(context.Background())
+}
+
+// ProcessOutbox delivers all due outbox entries.
+// Concurrent calls are coalesced: if a run is already in progress this returns immediately.
+func (s *WebhookService) ProcessOutbox(ctx context.Context) {
+	if !s.processing.TryLock() {
+		return
+	}
+	defer s.processing.Unlock()
+
+	for {
+		deliveries, err := s.repo.FindDue(time.Now(), webhookBatchSize)
+		if err != nil || len(deliveries) == 0 {
+			return
+		}
+
+		for i := range deliveries {
+			if ctx.Err() != nil {
+				return
+			}
+			s.attempt(ctx, &deliveries[i])
+		}
+
+		if len(deliveries) < webhookBatchSize {
+			return
+		}
+	}
+}
+
+// Cleanup removes finished outbox entries older than the retention period
+func (s *WebhookService) Cleanup(retention time.Duration) {
+	if err := s.repo.DeleteFinishedDeliveries(retention); err != nil {
+		logger.Warn("Failed to clean up webhook outbox", zap.Error(err))
+	}
+}
+
+// attempt performs one delivery attempt and records the outcome
+func (s *WebhookService) attempt(ctx context.Context, d *model.WebhookDelivery) {
+	d.Attempts++
+	err := s.post(ctx, d)
+	if err == nil {
+		d.Status = model.WebhookStatusDelivered
+		d.LastError = ""
+		logger.Debug("Webhook delivered",
+			zap.Uint("delivery_id", d.ID),
+			zap.String("event", d.Event),
+			zap.Int("attempts", d.Attempts))
+	} else {
+		d.LastError = truncateError(err.Error(), 512)
+		if d.Attempts >= s.maxAttempts {
+			d.Status = model.WebhookStatusFailed
+			logger.Warn("Webhook delivery failed permanently",
+				zap.Uint("delivery_id", d.ID),
+				zap.String("event", d.Event),
+				zap.Int("attempts", d.Attempts),
+				zap.Error(err))
+		} else {
+			d.NextAttemptAt = time.Now().Add(webhookBackoff(d.Attempts))
+			logger.Info("Webhook delivery failed, will retry",
+				zap.Uint("delivery_id", d.ID),
+				zap.String("event", d.Event),
+				zap.Int("attempts", d.Attempts),
+				zap.Time("next_attempt_at", d.NextAttemptAt),
+				zap.Error(err))
+		}
+	}
+
+	if err := s.repo.UpdateDelivery(d); err != nil {
+		logger.Warn("Failed to record webhook attempt", zap.Uint("delivery_id", d.ID), zap.Error(err))
+	}
+}
+
+// post sends a signed webhook request; any non-2xx response is an error
+func (s *WebhookService) post(ctx context.Context, d *model.WebhookDelivery) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader([]byte(d.Payload)))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "daily-reminder-bot-webhook/1.0")
+	req.Header.Set(WebhookEventHeader, d.Event)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	if d.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(d.Secret, timestamp, []byte(d.Payload)))
+	}
+
+	client := s.operatorClient
+	if d.WebhookID != 0 {
+		client = s.userClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhook computes the hex HMAC-SHA256 of "<timestamp>.<body>" with the given secret.
+// Receivers verify the X-Webhook-Signature header ("sha256=<hex>") by recomputing it.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateWebhookSecret returns a random signing secret
+func GenerateWebhookSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ValidateWebhookURL checks that a URL is an absolute http(s) URL
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("URL scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("URL must include a host")
+	}
+	return nil
+}
+
+// webhookBackoff returns the exponential retry delay after the given number of attempts
+func webhookBackoff(attempts int) time.Duration {
+	delay := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return delay
+}
+
+// subscribesTo reports whether an event list (empty = all) contains the event
+func subscribesTo(events []string, event string) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package testutil_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/config"
+	"github.com/cuichanghe/daily-reminder-bot/internal/testutil"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+)
+
+const waitTimeout = 10 * time.Second
+
+func TestMain(m *testing.M) {
+	_ = logger.Init(&config.LoggerConfig{Level: "fatal"})
+	os.Exit(m.Run())
+}
+
+// newHarness starts a harness closed at the end of the test
+func newHarness(t *testing.T) *testutil.Harness {
+	t.Helper()
+	h, err := testutil.NewHarness()
+	if err != nil {
+		t.Fatalf("NewHarness: %v", err)
+	}
+	t.Cleanup(h.Close)
+	return h
+}
+
+func TestSubscribeThenReminderTick(t *testing.T) {
+	h := newHarness(t)
+	const chatID = 42
+
+	reply, err := h.Send(chatID, "/subscribe 北京 08:00", waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(reply.Text, "北京") || !strings.Contains(reply.Text, "08:00") {
+		t.Fatalf("unexpected /subscribe reply: %q", reply.Text)
+	}
+
+	loc, err := time.LoadLocation(testutil.Timezone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Telegram.Reset()
+	// A minute before is not the subscription's time
+	h.Scheduler.CheckRemindersAt(time.Date(2025, 3, 1, 7, 59, 0, 0, loc))
+	h.Scheduler.CheckRemindersAt(time.Date(2025, 3, 1, 8, 0, 0, 0, loc))
+
+	m, err := h.Telegram.WaitForText(chatID, "早安", waitTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Method != "sendMessage" {
+		t.Errorf("method = %q, want sendMessage", m.Method)
+	}
+	for _, want := range []string{"2025年3月1日", "📍 北京 天气播报", "🌡️ 温度：24°C"} {
+		if !strings.Contains(m.Text, want) {
+			t.Errorf("reminder lacks %q:\n%s", want, m.Text)
+		}
+	}
+
+	// The same minute is processed once
+	h.Scheduler.CheckRemindersAt(time.Date(2025, 3, 1, 8, 0, 30, 0, loc))
+	time.Sleep(200 * time.Millisecond)
+	count := 0
+	for _, sent := range h.Telegram.Sent() {
+		if sent.ChatID == chatID && strings.Contains(sent.Text, "早安") {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("sent %d reminders, want 1", count)
+	}
+}
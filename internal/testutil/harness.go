@@ -0,0 +1,235 @@
+package testutil
+
+import (
+	"crypto/rand"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/bot"
+	"github.com/cuichanghe/daily-reminder-bot/internal/migration"
+	"github.com/cuichanghe/daily-reminder-bot/internal/notify"
+	"github.com/cuichanghe/daily-reminder-bot/internal/repository"
+	"github.com/cuichanghe/daily-reminder-bot/internal/service"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/advice"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/fieldcrypt"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/ics"
+	tele "gopkg.in/telebot.v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// Timezone is the scheduler timezone used by the harness
+const Timezone = "Asia/Shanghai"
+
+var dbCounter int64
+
+// Harness wires the full bot (database, services, handlers, scheduler) against fake upstream servers.
+// Drive it with Telegram.PushCommand and Scheduler.CheckRemindersAt, then assert on Telegram.Sent.
+type Harness struct {
+	Telegram *FakeTelegram
+	QWeather *FakeQWeather
+	Holiday  *FakeHoliday
+	Speech   *FakeSpeech
+
+	DB            *gorm.DB
+	Bot           *tele.Bot
+	UserRepo      *repository.UserRepository
+	SubRepo       *repository.SubscriptionRepository
+	TodoRepo      *repository.TodoRepository
+	DeliveryRepo  *repository.DeliveryLogRepository
+	Scheduler     *service.SchedulerService
+	Notify        *service.NotificationService
+	Digests       *service.DigestCache
+	Announcements *service.AnnouncementService
+	TodoStats     *service.TodoStatsService
+	Snapshots     *service.SnapshotService
+	PreAlerts     *service.PreAlertService
+	Experiments   *service.ExperimentService
+	Health        *service.HealthReminderService
+	Intervals     *service.IntervalReminderService
+	Jobs          *service.JobService
+	CalendarFeeds *service.CalendarFeedService
+	SolarTerms    *service.SolarTermService
+
+	started bool
+}
+
+// NewHarness creates a fully wired harness backed by an in-memory SQLite database
+func NewHarness() (*Harness, error) {
+	h := &Harness{
+		Telegram: NewFakeTelegram(),
+		QWeather: NewFakeQWeather(),
+		Holiday:  NewFakeHoliday(),
+		Speech:   NewFakeSpeech(),
+	}
+
+	db, err := NewTestDB()
+	if err != nil {
+		h.Close()
+		return nil, err
+	}
+	h.DB = db
+
+	teleBot, err := h.Telegram.NewBot()
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("failed to create bot: %w", err)
+	}
+	h.Bot = teleBot
+
+	loc, err := time.LoadLocation(Timezone)
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("failed to load timezone: %w", err)
+	}
+
+	h.UserRepo = repository.NewUserRepository(db)
+	h.SubRepo = repository.NewSubscriptionRepository(db)
+	h.TodoRepo = repository.NewTodoRepository(db)
+	warningRepo := repository.NewWarningLogRepository(db)
+	h.DeliveryRepo = repository.NewDeliveryLogRepository(db)
+
+	qwClient := h.QWeather.Client()
+	h.Snapshots = service.NewSnapshotService(repository.NewAPISnapshotRepository(db), 7, loc)
+	qwClient.SetRecorder(h.Snapshots)
+	weatherSvc := service.NewWeatherService(qwClient)
+	todoSvc := service.NewTodoService(h.TodoRepo)
+	airSvc := service.NewAirQualityService(qwClient)
+	airSvc.SetTimezone(loc)
+	aiSvc := service.NewAIService(nil, 0, false, false, "", false, 0, 0)
+	calendarSvc := service.NewCalendarService(loc, h.Holiday.Client())
+	customFestivalRepo := repository.NewCustomFestivalRepository(db)
+	calendarSvc.SetCustomFestivals(customFestivalRepo)
+	channelRepo := repository.NewNotificationChannelRepository(db)
+	telegramNotifier := notify.NewTelegramNotifier(teleBot)
+	notifySvc := service.NewNotificationService(telegramNotifier, nil, channelRepo, nil,
+		service.NewVoiceService(h.Speech, telegramNotifier, 5*time.Second))
+	h.Notify = notifySvc
+	warningSvc := service.NewWarningService(qwClient, warningRepo, h.SubRepo, repository.NewWarningMuteRepository(db), service.NewWarningCatalog(qwClient, repository.NewWarningTypeRepository(db)), nil, notifySvc, nil)
+
+	h.Digests = service.NewDigestCache()
+	h.Announcements = service.NewAnnouncementService(repository.NewAnnouncementRepository(db), h.UserRepo, telegramNotifier)
+	h.TodoStats = service.NewTodoStatsService(h.TodoRepo, repository.NewTodoStatsRepository(db), h.SubRepo, loc)
+	indexWatchSvc := service.NewIndexWatchService(repository.NewIndexWatchRepository(db), notifySvc)
+	h.Experiments = service.NewExperimentService(repository.NewExperimentRepository(db), repository.NewFeedbackRepository(db))
+	healthCipher, err := newTestCipher()
+	if err != nil {
+		h.Close()
+		return nil, err
+	}
+	h.Health = service.NewHealthReminderService(repository.NewHealthReminderRepository(db), healthCipher, telegramNotifier, 5)
+	h.Intervals = service.NewIntervalReminderService(repository.NewIntervalReminderRepository(db), calendarSvc, telegramNotifier, loc)
+	h.PreAlerts = service.NewPreAlertService(qwClient, repository.NewPreAlertLogRepository(db), h.SubRepo, warningSvc, notifySvc)
+	h.Jobs = service.NewJobService(repository.NewJobRunRepository(db))
+	h.Scheduler, err = service.NewSchedulerService(
+		h.SubRepo,
+		h.DeliveryRepo,
+		weatherSvc,
+		todoSvc,
+		h.TodoStats,
+		aiSvc,
+		calendarSvc,
+		warningSvc,
+		nil,
+		notifySvc,
+		h.Digests,
+		h.Announcements,
+		indexWatchSvc,
+		h.Snapshots,
+		nil,
+		h.PreAlerts,
+		service.NewAdviceService(advice.NewEngine(advice.DefaultRules()...), repository.NewSeasonalEventRepository(db), loc),
+		h.Experiments,
+		h.Health,
+		nil,
+		nil,
+		nil,
+		h.Jobs,
+		Timezone,
+	)
+	if err != nil {
+		h.Close()
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+	h.CalendarFeeds = service.NewCalendarFeedService(repository.NewCalendarFeedRepository(db), ics.NewClient(5*time.Second, false), 3, loc)
+	if err := h.Scheduler.Sections().Register(h.CalendarFeeds); err != nil {
+		h.Close()
+		return nil, err
+	}
+	h.Scheduler.SetCalendarFeeds(h.CalendarFeeds)
+	h.SolarTerms = service.NewSolarTermService(nil, loc)
+	if err := h.Scheduler.Sections().Register(h.SolarTerms); err != nil {
+		h.Close()
+		return nil, err
+	}
+
+	handlers := bot.NewHandlers(h.UserRepo, h.SubRepo, h.TodoRepo, repository.NewWebhookRepository(db), channelRepo, repository.NewConversationRepository(db), repository.NewTodoInviteRepository(db), repository.NewTodoProposalRepository(db), repository.NewTodoMessageRepository(db), weatherSvc, todoSvc, airSvc, warningSvc, nil, notifySvc, nil, indexWatchSvc, h.Scheduler, h.Experiments, nil, nil, nil, h.Health, h.Intervals, aiSvc, nil, nil, service.NewTransferService(h.SubRepo, h.UserRepo, h.TodoRepo, repository.NewUsedTransferTokenRepository(db), "test", nil), service.NewTierService(h.UserRepo, service.TierLimits{Subscriptions: 5, Regenerations: 3, IndexWatches: 10, APICalls: 100}, service.TierLimits{Subscriptions: 20, Regenerations: 10, IndexWatches: 50, APICalls: 1000}), nil, service.NewAuditService(repository.NewAuditLogRepository(db)), service.NewRoleService(repository.NewStaffRoleRepository(db), nil, nil, nil), service.NewDashboardService(repository.NewDashboardRepository(db), h.UserRepo, h.SubRepo, todoSvc, h.DeliveryRepo, time.Hour, ""), service.NewMiniAppService(map[string]string{"": FakeToken}, h.UserRepo, h.SubRepo, h.TodoRepo, todoSvc, "https://example.com/miniapp"), h.Jobs, service.NewTripService(weatherSvc, calendarSvc, aiSvc), 0, 3, loc)
+	handlers.SetCustomFestivals(customFestivalRepo, calendarSvc)
+	handlers.SetCalendarFeeds(h.CalendarFeeds)
+	handlers.SetSolarTerms(h.SolarTerms)
+	teleBot.Use(bot.NewUpdateDeduplicator(repository.NewProcessedUpdateRepository(db), "", 1024).Middleware())
+	handlers.RegisterHandlers("", teleBot)
+
+	go teleBot.Start()
+	h.started = true
+	return h, nil
+}
+
+// NewTestDB opens an isolated in-memory SQLite database with all migrations applied
+func NewTestDB() (*gorm.DB, error) {
+	name := fmt.Sprintf("file:testdb%d?mode=memory&cache=shared", atomic.AddInt64(&dbCounter, 1))
+	db, err := gorm.Open(sqlite.Open(name), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open test database: %w", err)
+	}
+	if err := migration.Run(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// newTestCipher creates a field cipher with a random key
+func newTestCipher() (*fieldcrypt.Cipher, error) {
+	key := make([]byte, fieldcrypt.KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return fieldcrypt.New(key)
+}
+
+// Send pushes a user message and waits for the first reply to that chat
+func (h *Harness) Send(chatID int64, text string, timeout time.Duration) (SentMessage, error) {
+	before := len(h.Telegram.Sent())
+	h.Telegram.PushCommand(chatID, text)
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		sent := h.Telegram.Sent()
+		for _, m := range sent[before:] {
+			if m.ChatID == chatID {
+				return m, nil
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return SentMessage{}, fmt.Errorf("no reply to %q within %s", text, timeout)
+}
+
+// Close stops the bot and all fake servers
+func (h *Harness) Close() {
+	if h.Scheduler != nil {
+		h.Scheduler.Stop()
+	}
+	if h.started {
+		h.Bot.Stop()
+	}
+	if h.DB != nil {
+		if sqlDB, err := h.DB.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+	h.Telegram.Close()
+	h.QWeather.Close()
+	h.Holiday.Close()
+}
@@ -0,0 +1,81 @@
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/holiday"
+)
+
+// FakeHoliday is a fixture-backed holiday API server, also serving a holiday dataset under
+// /dataset/{year}.json
+type FakeHoliday struct {
+	server *httptest.Server
+	down   atomic.Bool
+}
+
+// NewFakeHoliday starts a fake holiday API server
+func NewFakeHoliday() *FakeHoliday {
+	f := &FakeHoliday{}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+// URL returns the base URL of the fake holiday API
+func (f *FakeHoliday) URL() string {
+	return f.server.URL
+}
+
+// Close shuts down the fake server
+func (f *FakeHoliday) Close() {
+	f.server.Close()
+}
+
+// DatasetURL returns the URL template of the fake holiday dataset
+func (f *FakeHoliday) DatasetURL() string {
+	return f.server.URL + "/dataset/{year}.json"
+}
+
+// SetDown makes the fake holiday API answer 429 Too Many Requests (the dataset stays up)
+func (f *FakeHoliday) SetDown(down bool) {
+	f.down.Store(down)
+}
+
+// Client returns a holiday client pointing at the fake server
+func (f *FakeHoliday) Client() *holiday.Client {
+	return holiday.NewClient(f.URL(), time.Hour)
+}
+
+// ChainClient returns a holiday client failing over from the fake API to the fake dataset
+func (f *FakeHoliday) ChainClient() *holiday.Client {
+	return holiday.NewChain(time.Hour, holiday.NewTimorProvider(f.URL()), holiday.NewDatasetProvider(f.DatasetURL()))
+}
+
+func (f *FakeHoliday) handle(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/dataset/") {
+		writeJSON(w, Fixture("holiday/dataset.json"))
+		return
+	}
+	if f.down.Load() {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+		return
+	}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/holiday/next/"):
+		writeJSON(w, Fixture("holiday/next.json"))
+	case strings.HasPrefix(r.URL.Path, "/api/holiday/year/"):
+		writeJSON(w, Fixture("holiday/year.json"))
+	case strings.HasPrefix(r.URL.Path, "/api/holiday/info/"):
+		writeJSON(w, Fixture("holiday/info.json"))
+	default:
+		writeJSON(w, []byte(`{"code":-1}`))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,51 @@
+package testutil
+
+import (
+	"embed"
+	"net/http/httptest"
+
+	"github.com/cuichanghe/daily-reminder-bot/internal/mockqweather"
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Fixture returns the raw content of an embedded fixture file (e.g., "holiday/next.json")
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		panic("testutil: missing fixture " + name)
+	}
+	return data
+}
+
+// FakeQWeather is a fixture-backed QWeather API server that only knows registered cities
+type FakeQWeather struct {
+	*mockqweather.Server
+	server *httptest.Server
+}
+
+// NewFakeQWeather starts a fake QWeather server that knows about 北京 by default
+func NewFakeQWeather() *FakeQWeather {
+	mock := mockqweather.New(mockqweather.Options{KnownCitiesOnly: true})
+	return &FakeQWeather{
+		Server: mock,
+		server: httptest.NewServer(mock),
+	}
+}
+
+// URL returns the base URL to use as the QWeather API host
+func (f *FakeQWeather) URL() string {
+	return f.server.URL
+}
+
+// Close shuts down the fake server
+func (f *FakeQWeather) Close() {
+	f.server.Close()
+}
+
+// Client returns a QWeather client pointing at the fake server
+func (f *FakeQWeather) Client() *qweather.Client {
+	return qweather.NewClient("test-key", f.URL())
+}
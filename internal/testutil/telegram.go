@@ -0,0 +1,417 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// FakeToken is the bot token accepted by FakeTelegram
+const FakeToken = "123456:TEST-TOKEN"
+
+// SentMessage represents an outbound Bot API call recorded by FakeTelegram
+type SentMessage struct {
+	Method    string                 // Bot API method (e.g., sendMessage, editMessageText)
+	ChatID    int64                  // Target chat ID
+	MessageID int                    // ID assigned to the sent message
+	Text      string                 // Message text (empty for non-text methods)
+	Params    map[string]interface{} // Raw request parameters
+}
+
+// FakeTelegram is an in-memory Telegram Bot API server.
+// Point telebot at URL() and use PushCommand/WaitForMessage to drive conversations.
+type FakeTelegram struct {
+	server *httptest.Server
+
+	mu            sync.Mutex
+	updates       []tele.Update
+	lastUpdate    *tele.Update
+	redelivered   []tele.Update // Returned by the next poll regardless of offset
+	nextUpdateID  int
+	nextMessageID int
+	sent          []SentMessage
+	files         map[string][]byte // Downloadable files by file ID
+	notify        chan struct{}
+	sentNotify    chan struct{}
+}
+
+// NewFakeTelegram starts a new fake Bot API server
+func NewFakeTelegram() *FakeTelegram {
+	f := &FakeTelegram{
+		nextUpdateID:  1,
+		nextMessageID: 1,
+		notify:        make(chan struct{}, 1),
+		sentNotify:    make(chan struct{}, 1),
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	return f
+}
+
+// URL returns the base URL to use as the bot API endpoint
+func (f *FakeTelegram) URL() string {
+	return f.server.URL
+}
+
+// Close shuts down the fake server
+func (f *FakeTelegram) Close() {
+	f.server.Close()
+}
+
+// NewBot creates a telebot instance wired to the fake server
+func (f *FakeTelegram) NewBot() (*tele.Bot, error) {
+	return tele.NewBot(tele.Settings{
+		Token:  FakeToken,
+		URL:    f.URL(),
+		Poller: &tele.LongPoller{Timeout: 100 * time.Millisecond},
+	})
+}
+
+// PushCommand queues a text message from the given chat as if a user had sent it
+func (f *FakeTelegram) PushCommand(chatID int64, text string) {
+	f.mu.Lock()
+	msgID := f.nextMessageID
+	f.nextMessageID++
+	update := tele.Update{
+		ID: f.nextUpdateID,
+		Message: &tele.Message{
+			ID:       msgID,
+			Unixtime: time.Now().Unix(),
+			Sender:   &tele.User{ID: chatID, FirstName: "Test"},
+			Chat:     &tele.Chat{ID: chatID, Type: tele.ChatPrivate},
+			Text:     text,
+		},
+	}
+	f.nextUpdateID++
+	f.updates = append(f.updates, update)
+	f.lastUpdate = &update
+	f.mu.Unlock()
+
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+// PushPhoto queues a photo message with an optional caption; the photo can be downloaded via getFile
+func (f *FakeTelegram) PushPhoto(chatID int64, data []byte, caption string) {
+	f.mu.Lock()
+	msgID := f.nextMessageID
+	f.nextMessageID++
+	fileID := fmt.Sprintf("photo-%d", msgID)
+	if f.files == nil {
+		f.files = make(map[string][]byte)
+	}
+	f.files[fileID] = data
+	f.mu.Unlock()
+
+	f.push(tele.Update{Message: &tele.Message{
+		ID:       msgID,
+		Unixtime: time.Now().Unix(),
+		Sender:   &tele.User{ID: chatID, FirstName: "Test"},
+		Chat:     &tele.Chat{ID: chatID, Type: tele.ChatPrivate},
+		Caption:  caption,
+		Photo: &tele.Photo{
+			File:   tele.File{FileID: fileID, UniqueID: fileID, FileSize: int64(len(data))},
+			Width:  800,
+			Height: 600,
+		},
+	}})
+}
+
+// PushLocation queues a shared (non-live) location message
+func (f *FakeTelegram) PushLocation(chatID int64, lat, lng float32) {
+	f.mu.Lock()
+	msgID := f.nextMessageID
+	f.nextMessageID++
+	f.mu.Unlock()
+
+	f.push(tele.Update{Message: &tele.Message{
+		ID:       msgID,
+		Unixtime: time.Now().Unix(),
+		Sender:   &tele.User{ID: chatID, FirstName: "Test"},
+		Chat:     &tele.Chat{ID: chatID, Type: tele.ChatPrivate},
+		Location: &tele.Location{Lat: lat, Lng: lng},
+	}})
+}
+
+// PushCallback queues a press of an inline button with the given callback data
+// (as sent by telebot, e.g. "\funique|payload") on the given bot message
+func (f *FakeTelegram) PushCallback(chatID int64, messageID int, data string) {
+	f.mu.Lock()
+	callbackID := fmt.Sprintf("callback-%d", f.nextUpdateID)
+	f.mu.Unlock()
+
+	f.push(tele.Update{Callback: &tele.Callback{
+		ID:     callbackID,
+		Sender: &tele.User{ID: chatID, FirstName: "Test"},
+		Message: &tele.Message{
+			ID:   messageID,
+			Chat: &tele.Chat{ID: chatID, Type: tele.ChatPrivate},
+		},
+		Data: data,
+	}})
+}
+
+// PushReaction queues a reaction update: the user set the given emoji on a message
+func (f *FakeTelegram) PushReaction(chatID int64, messageID int, emoji string) {
+	f.push(tele.Update{MessageReaction: &tele.MessageReaction{
+		Chat:         &tele.Chat{ID: chatID, Type: tele.ChatPrivate},
+		MessageID:    messageID,
+		User:         &tele.User{ID: chatID, FirstName: "Test"},
+		DateUnixtime: time.Now().Unix(),
+		NewReaction:  []tele.Reaction{{Type: "emoji", Emoji: emoji}},
+	}})
+}
+
+// push assigns the next update ID and queues the update
+func (f *FakeTelegram) push(update tele.Update) {
+	f.mu.Lock()
+	update.ID = f.nextUpdateID
+	f.nextUpdateID++
+	f.updates = append(f.updates, update)
+	f.lastUpdate = &update
+	f.mu.Unlock()
+
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+// RepeatLastUpdate queues the most recently pushed update again with the same update ID,
+// emulating Telegram redelivering an update after a long-poll timeout
+func (f *FakeTelegram) RepeatLastUpdate() {
+	f.mu.Lock()
+	if f.lastUpdate == nil {
+		f.mu.Unlock()
+		return
+	}
+	f.redelivered = append(f.redelivered, *f.lastUpdate)
+	f.mu.Unlock()
+
+	select {
+	case f.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Sent returns a copy of all recorded outbound calls
+func (f *FakeTelegram) Sent() []SentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]SentMessage, len(f.sent))
+	copy(out, f.sent)
+	return out
+}
+
+// Reset clears all recorded outbound calls
+func (f *FakeTelegram) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = nil
+}
+
+// WaitForMessage waits until a message matching the predicate is sent to chatID
+func (f *FakeTelegram) WaitForMessage(chatID int64, timeout time.Duration, match func(SentMessage) bool) (SentMessage, error) {
+	deadline := time.After(timeout)
+	for {
+		for _, m := range f.Sent() {
+			if m.ChatID == chatID && (match == nil || match(m)) {
+				return m, nil
+			}
+		}
+		select {
+		case <-f.sentNotify:
+		case <-deadline:
+			return SentMessage{}, fmt.Errorf("timed out waiting for message to chat %d", chatID)
+		}
+	}
+}
+
+// WaitForText waits until a message containing substr is sent to chatID
+func (f *FakeTelegram) WaitForText(chatID int64, substr string, timeout time.Duration) (SentMessage, error) {
+	return f.WaitForMessage(chatID, timeout, func(m SentMessage) bool {
+		return strings.Contains(m.Text, substr)
+	})
+}
+
+// handle dispatches Bot API calls of the form /bot<token>/<method>
+func (f *FakeTelegram) handle(w http.ResponseWriter, r *http.Request) {
+	if filePath, ok := strings.CutPrefix(r.URL.Path, "/file/bot"+FakeToken+"/"); ok {
+		f.mu.Lock()
+		data, found := f.files[filePath]
+		f.mu.Unlock()
+		if !found {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+		return
+	}
+
+	prefix := "/bot" + FakeToken + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		writeTelegramError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	method := strings.TrimPrefix(r.URL.Path, prefix)
+
+	params := make(map[string]interface{})
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		// File uploads: keep the form fields; uploaded files are recorded by field name and size
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			writeTelegramError(w, http.StatusBadRequest, "Bad Request: "+err.Error())
+			return
+		}
+		for key, values := range r.MultipartForm.Value {
+			if len(values) > 0 {
+				params[key] = values[0]
+			}
+		}
+		for key, files := range r.MultipartForm.File {
+			if len(files) > 0 {
+				params[key] = files[0].Size
+			}
+		}
+	} else if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil && err.Error() != "EOF" {
+			writeTelegramError(w, http.StatusBadRequest, "Bad Request: "+err.Error())
+			return
+		}
+	}
+
+	switch method {
+	case "getMe":
+		writeTelegramResult(w, tele.User{ID: 123456, IsBot: true, FirstName: "TestBot", Username: "test_bot"})
+	case "getUpdates":
+		writeTelegramResult(w, f.pollUpdates(params))
+	case "getFile":
+		fileID := paramString(params, "file_id")
+		f.mu.Lock()
+		data, found := f.files[fileID]
+		f.mu.Unlock()
+		if !found {
+			writeTelegramError(w, http.StatusBadRequest, "Bad Request: invalid file_id")
+			return
+		}
+		writeTelegramResult(w, tele.File{FileID: fileID, UniqueID: fileID, FileSize: int64(len(data)), FilePath: fileID})
+	case "sendMessage", "editMessageText", "sendPhoto", "sendDocument", "sendSticker",
+		"sendVoice", "sendAudio", "forwardMessage", "copyMessage":
+		writeTelegramResult(w, f.record(method, params))
+	default:
+		f.record(method, params)
+		writeTelegramResult(w, true)
+	}
+}
+
+// pollUpdates returns pending updates starting at the requested offset,
+// blocking briefly when none are available to emulate long polling
+func (f *FakeTelegram) pollUpdates(params map[string]interface{}) []tele.Update {
+	offset, _ := strconv.Atoi(paramString(params, "offset"))
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f.mu.Lock()
+		pending := f.redelivered
+		f.redelivered = nil
+		var kept []tele.Update
+		for _, u := range f.updates {
+			if u.ID >= offset {
+				pending = append(pending, u)
+				kept = append(kept, u)
+			}
+		}
+		f.updates = kept
+		f.mu.Unlock()
+
+		if len(pending) > 0 {
+			return pending
+		}
+		select {
+		case <-f.notify:
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return []tele.Update{}
+}
+
+// record stores an outbound call and returns a message echoing it
+func (f *FakeTelegram) record(method string, params map[string]interface{}) tele.Message {
+	chatID, _ := strconv.ParseInt(paramString(params, "chat_id"), 10, 64)
+	text := paramString(params, "text")
+	if text == "" {
+		text = paramString(params, "caption")
+	}
+
+	f.mu.Lock()
+	msgID := f.nextMessageID
+	f.nextMessageID++
+	f.sent = append(f.sent, SentMessage{Method: method, ChatID: chatID, MessageID: msgID, Text: text, Params: params})
+	f.mu.Unlock()
+
+	select {
+	case f.sentNotify <- struct{}{}:
+	default:
+	}
+
+	msg := tele.Message{
+		ID:       msgID,
+		Unixtime: time.Now().Unix(),
+		Chat:     &tele.Chat{ID: chatID, Type: tele.ChatPrivate},
+		Text:     text,
+	}
+
+	// telebot copies the file ID of uploaded media from the returned message
+	file := tele.File{FileID: fmt.Sprintf("file-%d", msgID), UniqueID: fmt.Sprintf("unique-%d", msgID)}
+	switch method {
+	case "sendVoice":
+		msg.Voice = &tele.Voice{File: file}
+	case "sendAudio":
+		msg.Audio = &tele.Audio{File: file}
+	case "sendPhoto":
+		msg.Photo = &tele.Photo{File: file}
+	case "sendDocument":
+		msg.Document = &tele.Document{File: file}
+	}
+	return msg
+}
+
+// paramString returns a request parameter as a string regardless of its JSON type
+func paramString(params map[string]interface{}, key string) string {
+	v, ok := params[key]
+	if !ok || v == nil {
+		return ""
+	}
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatInt(int64(val), 10)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func writeTelegramResult(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":     true,
+		"result": result,
+	})
+}
+
+func writeTelegramError(w http.ResponseWriter, status int, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"ok":          false,
+		"error_code":  status,
+		"description": description,
+	})
+}
@@ -0,0 +1,46 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/tts"
+)
+
+// FakeSpeech is a tts.Synthesizer that records the texts it was asked to read
+type FakeSpeech struct {
+	mu    sync.Mutex
+	texts []string
+	fail  bool
+}
+
+// NewFakeSpeech creates a new FakeSpeech
+func NewFakeSpeech() *FakeSpeech {
+	return &FakeSpeech{}
+}
+
+// Synthesize records the text and returns a small fake Ogg payload
+func (f *FakeSpeech) Synthesize(ctx context.Context, text string) (*tts.Audio, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return nil, fmt.Errorf("fake speech failure")
+	}
+	f.texts = append(f.texts, text)
+	return &tts.Audio{Data: []byte("OggS fake voice"), MIME: "audio/ogg"}, nil
+}
+
+// SetFail makes subsequent syntheses fail
+func (f *FakeSpeech) SetFail(fail bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fail = fail
+}
+
+// Texts returns the texts synthesized so far
+func (f *FakeSpeech) Texts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.texts...)
+}
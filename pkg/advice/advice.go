@@ -0,0 +1,86 @@
+// Package advice derives care tips (what to wear, seasonal heating and cooling) from a day's
+// weather and calendar. Each kind of tip is a Rule; an Engine runs its rules in order.
+package advice
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+)
+
+// Context is the data rules advise on
+type Context struct {
+	City    string
+	Date    time.Time
+	Today   *qweather.DailyForecast  // Today's forecast (nil = unavailable)
+	Weather *qweather.CurrentWeather // Current weather (nil = unavailable)
+	Fu      string                   // Dog days period (初伏/中伏/末伏), "" outside the dog days
+	FuDay   int                      // Day number within the dog days period
+}
+
+// Tip is a piece of advice produced by a rule
+type Tip struct {
+	Rule   string // Name of the rule that produced the tip
+	Icon   string
+	Text   string
+	Season string // Non-empty for seasonal events: the tip is only shown on the first day it fires in the season
+}
+
+// String formats the tip as a single line
+func (t Tip) String() string {
+	return t.Icon + " " + t.Text
+}
+
+// Rule produces a tip from the context, or nil when it has nothing to say
+type Rule interface {
+	Name() string
+	Advise(c *Context) *Tip
+}
+
+// Engine runs a list of rules
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine creates an engine running the given rules in order
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// DefaultRules returns the built-in rules: dressing, heating season, air conditioning season and dog days
+func DefaultRules() []Rule {
+	return []Rule{DressingRule{}, HeatingSeasonRule{}, CoolingSeasonRule{}, DogDaysRule{}}
+}
+
+// Register adds a rule after the existing ones
+func (e *Engine) Register(rule Rule) {
+	e.rules = append(e.rules, rule)
+}
+
+// Advise runs every rule and returns their tips in rule order
+func (e *Engine) Advise(c *Context) []Tip {
+	var tips []Tip
+	for _, rule := range e.rules {
+		if tip := rule.Advise(c); tip != nil {
+			if tip.Rule == "" {
+				tip.Rule = rule.Name()
+			}
+			tips = append(tips, *tip)
+		}
+	}
+	return tips
+}
+
+// temperatures parses the high and low temperature of a forecast day
+func temperatures(day *qweather.DailyForecast) (high, low int, ok bool) {
+	if day == nil {
+		return 0, 0, false
+	}
+	high, err1 := strconv.Atoi(day.TempMax)
+	low, err2 := strconv.Atoi(day.TempMin)
+	if err1 != nil || err2 != nil {
+		return 0, 0, false
+	}
+	return high, low, true
+}
@@ -0,0 +1,54 @@
+package advice
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// dressingWideRange is the day-night temperature difference (°C) above which a jacket is suggested
+const dressingWideRange = 10
+
+// dressingBands maps the day's high temperature to clothing, from cold to hot
+var dressingBands = []struct {
+	below   int // Applies when the high is below this
+	clothes string
+}{
+	{1, "羽绒服、厚毛衣，注意帽子手套"},
+	{10, "棉衣或厚外套，内搭毛衣"},
+	{18, "毛衣、夹克或风衣"},
+	{25, "长袖衬衫、卫衣或薄外套"},
+	{30, "短袖或薄长袖"},
+}
+
+// DressingRule suggests clothing from today's temperature range, with a jacket when the day and
+// night temperatures differ a lot
+type DressingRule struct{}
+
+// Name returns the rule name
+func (DressingRule) Name() string { return "dressing" }
+
+// Advise suggests clothing for today
+func (DressingRule) Advise(c *Context) *Tip {
+	high, low, ok := temperatures(c.Today)
+	if !ok {
+		return nil
+	}
+	clothes := "透气短袖短裤，注意防晒"
+	for _, band := range dressingBands {
+		if high < band.below {
+			clothes = band.clothes
+			break
+		}
+	}
+
+	text := fmt.Sprintf("穿衣：今天 %d~%d°C，建议%s", low, high, clothes)
+	if c.Weather != nil {
+		if feelsLike, err := strconv.Atoi(c.Weather.FeelsLike); err == nil && feelsLike <= low-3 {
+			text += fmt.Sprintf("；当前体感仅 %d°C，出门多穿一层", feelsLike)
+		}
+	}
+	if high-low >= dressingWideRange {
+		text += fmt.Sprintf("；早晚温差 %d°C，记得带件外套", high-low)
+	}
+	return &Tip{Icon: "👔", Text: text}
+}
@@ -0,0 +1,88 @@
+package advice
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// heatingMeanTemp is the daily mean temperature (°C) below which rooms need heating
+	heatingMeanTemp = 5
+	// coolingHighTemp is the high temperature (°C) from which air conditioning is usually needed
+	coolingHighTemp = 30
+)
+
+// HeatingSeasonRule marks the first day of the season whose mean temperature falls below 5°C,
+// with heating care tips
+type HeatingSeasonRule struct{}
+
+// Name returns the rule name
+func (HeatingSeasonRule) Name() string { return "heating_season" }
+
+// Advise fires when today's mean temperature is below 5°C; the caller keeps only the first such
+// day of the winter season
+func (HeatingSeasonRule) Advise(c *Context) *Tip {
+	high, low, ok := temperatures(c.Today)
+	if !ok {
+		return nil
+	}
+	mean := float64(high+low) / 2
+	if mean >= heatingMeanTemp {
+		return nil
+	}
+	return &Tip{
+		Icon: "🔥",
+		Text: fmt.Sprintf("今日平均气温 %.1f°C，入秋以来首次低于 %d°C：注意室内取暖，"+
+			"电暖器远离易燃物，燃气、煤炉取暖务必通风，谨防一氧化碳中毒", mean, heatingMeanTemp),
+		Season: winterSeason(c.Date),
+	}
+}
+
+// CoolingSeasonRule marks the first day of the year reaching 30°C, with air conditioning care tips
+type CoolingSeasonRule struct{}
+
+// Name returns the rule name
+func (CoolingSeasonRule) Name() string { return "cooling_season" }
+
+// Advise fires when today's high reaches 30°C; the caller keeps only the first such day of the year
+func (CoolingSeasonRule) Advise(c *Context) *Tip {
+	high, _, ok := temperatures(c.Today)
+	if !ok || high < coolingHighTemp {
+		return nil
+	}
+	return &Tip{
+		Icon: "❄️",
+		Text: fmt.Sprintf("今日最高气温 %d°C，今年首次达到 %d°C：空调开启前记得清洗滤网，"+
+			"温度建议设在 26°C 左右，避免对着人直吹", high, coolingHighTemp),
+		Season: strconv.Itoa(c.Date.Year()),
+	}
+}
+
+// DogDaysRule greets the start of the dog days (入伏) with summer care tips
+type DogDaysRule struct{}
+
+// Name returns the rule name
+func (DogDaysRule) Name() string { return "dog_days" }
+
+// Advise fires on the first day of 初伏
+func (DogDaysRule) Advise(c *Context) *Tip {
+	if c.Fu != "初伏" || c.FuDay != 1 {
+		return nil
+	}
+	return &Tip{
+		Icon: "☀️",
+		Text: "今日入伏，一年中最热的三伏天开始：空调温度不宜过低，室内外温差控制在 7°C 以内，" +
+			"多补水、少吃生冷，午后尽量避免户外暴晒",
+	}
+}
+
+// winterSeason returns the winter season a date belongs to, named by the year it starts in
+// (seasons run from August to July)
+func winterSeason(date time.Time) string {
+	year := date.Year()
+	if date.Month() < time.August {
+		year--
+	}
+	return strconv.Itoa(year)
+}
@@ -1,6 +1,7 @@
 package calendar
 
 import (
+	"slices"
 	"sort"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 // Calculator handles date calculations for calendar information
 type Calculator struct {
 	timezone *time.Location
+	custom   []CustomFestival // User-defined festivals (see WithCustom)
 }
 
 // NewCalculator creates a new Calculator with the specified timezone
@@ -48,30 +50,90 @@ func (c *Calculator) GetTodayJieQi(date time.Time) string {
 	return lunar.GetJieQi()
 }
 
-// GetTodayFestivals returns a list of festivals for the given date
-func (c *Calculator) GetTodayFestivals(date time.Time) []string {
+// GetPrevJieQi returns the latest solar term on or before the date and the day it starts
+func (c *Calculator) GetPrevJieQi(date time.Time) (string, time.Time) {
+	date = date.In(c.timezone)
+	lunar := calendar.NewSolarFromYmd(date.Year(), int(date.Month()), date.Day()).GetLunar()
+	return c.jieQiOf(lunar.GetPrevJieQiByWholeDay(true))
+}
+
+// GetNextJieQi returns the first solar term after the date and the day it starts
+func (c *Calculator) GetNextJieQi(date time.Time) (string, time.Time) {
+	date = date.In(c.timezone)
+	lunar := calendar.NewSolarFromYmd(date.Year(), int(date.Month()), date.Day()).GetLunar()
+	return c.jieQiOf(lunar.GetNextJieQiByWholeDay(true))
+}
+
+// NextJieQiDate returns the day a solar term next starts on or after the date, or the zero time
+// for an unknown name
+func (c *Calculator) NextJieQiDate(name string, date time.Time) time.Time {
+	day := date.AddDate(0, 0, -1)
+	// Each term comes round once in 24 terms
+	for i := 0; i < 25; i++ {
+		next, start := c.GetNextJieQi(day)
+		if next == "" {
+			break
+		}
+		if next == name {
+			return start
+		}
+		day = start
+	}
+	return time.Time{}
+}
+
+// jieQiOf returns the name and starting day of a solar term ("" when nil)
+func (c *Calculator) jieQiOf(jq *calendar.JieQi) (string, time.Time) {
+	if jq == nil {
+		return "", time.Time{}
+	}
+	solar := jq.GetSolar()
+	return jq.GetName(), time.Date(solar.GetYear(), time.Month(solar.GetMonth()), solar.GetDay(), 0, 0, 0, 0, c.timezone)
+}
+
+// GetFu returns the dog days period of a date (初伏/中伏/末伏) and its day number (1-based),
+// or "" and 0 outside the dog days
+func (c *Calculator) GetFu(date time.Time) (string, int) {
+	date = date.In(c.timezone)
+	solar := calendar.NewSolarFromYmd(date.Year(), int(date.Month()), date.Day())
+	fu := solar.GetLunar().GetFu()
+	if fu == nil {
+		return "", 0
+	}
+	return fu.GetName(), fu.GetIndex()
+}
+
+// GetTodayFestivals returns a list of festivals for the given date, leaving out the hidden
+// categories
+func (c *Calculator) GetTodayFestivals(date time.Time, hidden ...FestivalCategory) []string {
 	date = date.In(c.timezone)
 	solar := calendar.NewSolarFromYmd(date.Year(), int(date.Month()), date.Day())
 	lunar := solar.GetLunar()
 
 	var festivals []string
+	add := func(name string, category FestivalCategory) {
+		if !hasCategory(hidden, category) {
+			festivals = append(festivals, name)
+		}
+	}
 
 	// Get lunar festivals from the library
 	lunarFestivals := lunar.GetFestivals()
 	for i := lunarFestivals.Front(); i != nil; i = i.Next() {
-		festivals = append(festivals, i.Value.(string))
+		name := i.Value.(string)
+		add(name, lunarCategory(name))
 	}
 
 	// Get other traditional festivals
 	otherFestivals := lunar.GetOtherFestivals()
 	for i := otherFestivals.Front(); i != nil; i = i.Next() {
-		festivals = append(festivals, i.Value.(string))
+		add(i.Value.(string), CategoryLunar)
 	}
 
 	// Check fixed solar festivals
 	for _, sf := range SolarFestivals {
 		if sf.Month == int(date.Month()) && sf.Day == date.Day() {
-			festivals = append(festivals, sf.Name)
+			add(sf.Name, sf.Type.Category())
 		}
 	}
 
@@ -81,15 +143,34 @@ func (c *Calculator) GetTodayFestivals(date time.Time) []string {
 		if festivalDate.Year() == date.Year() &&
 			festivalDate.Month() == date.Month() &&
 			festivalDate.Day() == date.Day() {
-			festivals = append(festivals, ff.Name)
+			add(ff.Name, ff.Type.Category())
+		}
+	}
+
+	// Check custom festivals, skipping those repeating a festival above
+	for _, name := range c.todayCustomFestivals(date) {
+		if !slices.Contains(festivals, name) {
+			add(name, CategoryCustom)
 		}
 	}
 
 	return festivals
 }
 
-// GetUpcomingFestivals returns the upcoming festivals sorted by date
-func (c *Calculator) GetUpcomingFestivals(date time.Time, limit int) []Festival {
+// lunarCategory returns the category of a lunar festival named by the lunar library: statutory
+// for 春节, 端午节 and 中秋节, else traditional
+func lunarCategory(name string) FestivalCategory {
+	for _, lf := range LunarFestivals {
+		if lf.Name == name {
+			return lf.Type.Category()
+		}
+	}
+	return CategoryLunar
+}
+
+// GetUpcomingFestivals returns the upcoming festivals sorted by date, leaving out the hidden
+// categories
+func (c *Calculator) GetUpcomingFestivals(date time.Time, limit int, hidden ...FestivalCategory) []Festival {
 	date = date.In(c.timezone)
 	today := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, c.timezone)
 
@@ -107,9 +188,15 @@ func (c *Calculator) GetUpcomingFestivals(date time.Time, limit int) []Festival
 	// Add solar terms
 	festivals = append(festivals, c.getSolarTerms(date)...)
 
+	// Add custom festivals
+	festivals = append(festivals, c.getCustomFestivals(date)...)
+
 	// Filter to only include today and future dates, calculate DaysUntil
 	var upcoming []Festival
 	for _, f := range festivals {
+		if hasCategory(hidden, f.Type.Category()) {
+			continue
+		}
 		fDate := time.Date(f.Date.Year(), f.Date.Month(), f.Date.Day(), 0, 0, 0, 0, c.timezone)
 		if !fDate.Before(today) {
 			f.DaysUntil = int(fDate.Sub(today).Hours() / 24)
@@ -117,12 +204,13 @@ func (c *Calculator) GetUpcomingFestivals(date time.Time, limit int) []Festival
 		}
 	}
 
-	// Sort by date
-	sort.Slice(upcoming, func(i, j int) bool {
+	// Sort by date, keeping built-in festivals before custom ones of the same date
+	sort.SliceStable(upcoming, func(i, j int) bool {
 		return upcoming[i].Date.Before(upcoming[j].Date)
 	})
 
-	// Remove duplicates (same date and name)
+	// Remove duplicates (same date and name), so that a custom festival repeating a built-in one
+	// keeps the built-in type
 	upcoming = removeDuplicates(upcoming)
 
 	// Limit results
@@ -0,0 +1,172 @@
+package calendar
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/6tail/lunar-go/calendar"
+)
+
+// CustomFestival is a festival a user defined, recurring every year on a solar or lunar date
+// (e.g. a company anniversary on 3-15, a temple fair on 农历二月初二)
+type CustomFestival struct {
+	Name  string
+	Month int
+	Day   int
+	Lunar bool
+}
+
+// lunarMonthNames and lunarDayNames are the Chinese names of lunar months and days
+var (
+	lunarMonthNames = []string{"", "正", "二", "三", "四", "五", "六", "七", "八", "九", "十", "冬", "腊"}
+	lunarDayNames   = []string{"",
+		"初一", "初二", "初三", "初四", "初五", "初六", "初七", "初八", "初九", "初十",
+		"十一", "十二", "十三", "十四", "十五", "十六", "十七", "十八", "十九", "二十",
+		"廿一", "廿二", "廿三", "廿四", "廿五", "廿六", "廿七", "廿八", "廿九", "三十"}
+	// lunarMonthAliases are other names of lunar months
+	lunarMonthAliases = map[string]int{"一": 1, "十一": 11, "十二": 12}
+)
+
+// numericDatePattern matches "3-15", "03/15", "3.15" and "3月15日"
+var numericDatePattern = regexp.MustCompile(`^(\d{1,2})\s*[-/.月]\s*(\d{1,2})\s*[日号]?$`)
+
+// ParseCustomDate parses the date of a custom festival: a solar date such as "3-15" or "3月15日",
+// or a lunar date prefixed with "农历" such as "农历2-2" or "农历二月初二"
+func ParseCustomDate(s string) (month, day int, lunar bool, err error) {
+	s = strings.TrimSpace(s)
+	if rest, ok := strings.CutPrefix(s, "农历"); ok {
+		s, lunar = strings.TrimSpace(rest), true
+	}
+
+	if m := numericDatePattern.FindStringSubmatch(s); m != nil {
+		month, _ = strconv.Atoi(m[1])
+		day, _ = strconv.Atoi(m[2])
+	} else if lunar {
+		month, day = parseLunarName(s)
+	}
+	if month < 1 || month > 12 || day < 1 {
+		return 0, 0, false, fmt.Errorf("invalid date %q", s)
+	}
+	if lunar {
+		if day > 30 {
+			return 0, 0, false, fmt.Errorf("invalid lunar date %q", s)
+		}
+	} else if day > daysIn(time.Month(month)) {
+		return 0, 0, false, fmt.Errorf("invalid date %q", s)
+	}
+	return month, day, lunar, nil
+}
+
+// parseLunarName parses a lunar date written in Chinese, e.g. "二月初二" or "腊月廿三"
+func parseLunarName(s string) (month, day int) {
+	monthName, dayName, ok := strings.Cut(s, "月")
+	if !ok {
+		return 0, 0
+	}
+	month = lunarMonthAliases[monthName]
+	for i, name := range lunarMonthNames {
+		if i > 0 && name == monthName {
+			month = i
+		}
+	}
+	for i, name := range lunarDayNames {
+		if i > 0 && name == dayName {
+			day = i
+		}
+	}
+	return month, day
+}
+
+// daysIn returns the most days a solar month has (29 for February)
+func daysIn(month time.Month) int {
+	return time.Date(2024, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// DateString formats the date of the festival, e.g. "3月15日" or "农历二月初二"
+func (f CustomFestival) DateString() string {
+	if f.Lunar && f.Month >= 1 && f.Month <= 12 && f.Day >= 1 && f.Day <= 30 {
+		return "农历" + lunarMonthNames[f.Month] + "月" + lunarDayNames[f.Day]
+	}
+	return fmt.Sprintf("%d月%d日", f.Month, f.Day)
+}
+
+// WithCustom returns a calculator that also includes the custom festivals in the festivals of a
+// day and the upcoming festivals
+func (c *Calculator) WithCustom(custom []CustomFestival) *Calculator {
+	clone := *c
+	clone.custom = custom
+	return &clone
+}
+
+// dateOf returns the date of a custom festival in a (solar or lunar) year. Days missing from the
+// month fall on its last day: February 29 on February 28, lunar 30th in short months on the 29th.
+func (c *Calculator) dateOf(f CustomFestival, year int) time.Time {
+	if !f.Lunar {
+		day := f.Day
+		if last := time.Date(year, time.Month(f.Month)+1, 0, 0, 0, 0, 0, c.timezone).Day(); day > last {
+			day = last
+		}
+		return time.Date(year, time.Month(f.Month), day, 0, 0, 0, 0, c.timezone)
+	}
+
+	day := f.Day
+	if day == 30 {
+		day = 29
+		if next := calendar.NewLunarFromYmd(year, f.Month, 29).Next(1); next.GetMonth() == f.Month {
+			day = 30
+		}
+	}
+	solar := calendar.NewLunarFromYmd(year, f.Month, day).GetSolar()
+	return time.Date(solar.GetYear(), time.Month(solar.GetMonth()), solar.GetDay(), 0, 0, 0, 0, c.timezone)
+}
+
+// getCustomFestivals returns the custom festivals of the current and next year
+func (c *Calculator) getCustomFestivals(date time.Time) []Festival {
+	if len(c.custom) == 0 {
+		return nil
+	}
+	lunarYear := calendar.NewSolarFromYmd(date.Year(), int(date.Month()), date.Day()).GetLunar().GetYear()
+
+	var festivals []Festival
+	for _, cf := range c.custom {
+		year := date.Year()
+		if cf.Lunar {
+			year = lunarYear
+		}
+		for _, y := range []int{year, year + 1} {
+			festivals = append(festivals, Festival{
+				Name: cf.Name,
+				Date: c.dateOf(cf, y),
+				Type: FestivalTypeCustom,
+			})
+		}
+	}
+	return festivals
+}
+
+// NextDate returns the next date of a custom festival on or after date and the days until it
+func (c *Calculator) NextDate(f CustomFestival, date time.Time) (time.Time, int) {
+	date = date.In(c.timezone)
+	today := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, c.timezone)
+	for _, festival := range c.WithCustom([]CustomFestival{f}).getCustomFestivals(date) {
+		if !festival.Date.Before(today) {
+			return festival.Date, int(festival.Date.Sub(today).Hours() / 24)
+		}
+	}
+	return time.Time{}, -1
+}
+
+// todayCustomFestivals returns the names of the custom festivals falling on date
+func (c *Calculator) todayCustomFestivals(date time.Time) []string {
+	var names []string
+	day := date.Format("2006-01-02")
+	for _, f := range c.getCustomFestivals(date) {
+		if f.Date.Format("2006-01-02") == day {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
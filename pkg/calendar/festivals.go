@@ -81,7 +81,7 @@ var FloatingFestivals = []FloatingFestival{
 	},
 	{
 		Name: "感恩节",
-		Type: FestivalTypeFloating,
+		Type: FestivalTypeWestern,
 		Calculator: func(year int) time.Time {
 			// 11月第4个周四（美国）
 			return getNthWeekday(year, time.November, time.Thursday, 4)
@@ -89,7 +89,7 @@ var FloatingFestivals = []FloatingFestival{
 	},
 	{
 		Name: "复活节",
-		Type: FestivalTypeFloating,
+		Type: FestivalTypeWestern,
 		Calculator: func(year int) time.Time {
 			return calculateEaster(year)
 		},
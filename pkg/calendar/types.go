@@ -1,6 +1,9 @@
 package calendar
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // DateInfo contains date information including solar and lunar calendars
 type DateInfo struct {
@@ -26,6 +29,7 @@ const (
 	FestivalTypeStatutory                         // 法定节假日
 	FestivalTypeWestern                           // 西方节日
 	FestivalTypeFloating                          // 浮动节日（如母亲节）
+	FestivalTypeCustom                            // 用户自定义节日
 )
 
 // String returns the Chinese name of the festival type
@@ -43,6 +47,8 @@ func (t FestivalType) String() string {
 		return "西方"
 	case FestivalTypeFloating:
 		return "浮动"
+	case FestivalTypeCustom:
+		return "自定义"
 	default:
 		return "未知"
 	}
@@ -63,11 +69,134 @@ func (t FestivalType) Emoji() string {
 		return "🌍"
 	case FestivalTypeFloating:
 		return "💐"
+	case FestivalTypeCustom:
+		return "⭐"
 	default:
 		return "📌"
 	}
 }
 
+// FestivalCategory groups festival types for users choosing which festivals they see
+type FestivalCategory string
+
+// Festival categories
+const (
+	CategoryStatutory FestivalCategory = "statutory"  // 法定节假日
+	CategoryLunar     FestivalCategory = "lunar"      // 传统节日
+	CategoryWestern   FestivalCategory = "western"    // 西方节日
+	CategorySolarTerm FestivalCategory = "solar_term" // 节气
+	CategorySolar     FestivalCategory = "solar"      // 纪念日（妇女节、母亲节等）
+	CategoryCustom    FestivalCategory = "custom"     // 用户自定义节日
+)
+
+// Categories lists all festival categories in display order
+var Categories = []FestivalCategory{
+	CategoryStatutory, CategoryLunar, CategoryWestern, CategorySolarTerm, CategorySolar, CategoryCustom,
+}
+
+// Category returns the category of the festival type
+func (t FestivalType) Category() FestivalCategory {
+	switch t {
+	case FestivalTypeSolarTerm:
+		return CategorySolarTerm
+	case FestivalTypeLunar:
+		return CategoryLunar
+	case FestivalTypeStatutory:
+		return CategoryStatutory
+	case FestivalTypeWestern:
+		return CategoryWestern
+	case FestivalTypeCustom:
+		return CategoryCustom
+	default:
+		return CategorySolar
+	}
+}
+
+// Name returns the Chinese name of the category
+func (c FestivalCategory) Name() string {
+	switch c {
+	case CategoryStatutory:
+		return "法定节假日"
+	case CategoryLunar:
+		return "传统节日"
+	case CategoryWestern:
+		return "西方节日"
+	case CategorySolarTerm:
+		return "节气"
+	case CategorySolar:
+		return "纪念日"
+	case CategoryCustom:
+		return "我的节日"
+	default:
+		return string(c)
+	}
+}
+
+// categoryAliases maps the Chinese names users may type to categories
+var categoryAliases = map[string]FestivalCategory{
+	"法定": CategoryStatutory, "法定节假日": CategoryStatutory, "假日": CategoryStatutory,
+	"传统": CategoryLunar, "传统节日": CategoryLunar, "农历": CategoryLunar,
+	"西方": CategoryWestern, "西方节日": CategoryWestern, "洋节": CategoryWestern,
+	"节气": CategorySolarTerm, "二十四节气": CategorySolarTerm,
+	"纪念日": CategorySolar, "公历": CategorySolar,
+	"我的": CategoryCustom, "我的节日": CategoryCustom, "自定义": CategoryCustom,
+}
+
+// ParseCategory parses a category by its identifier (e.g. "western") or Chinese name (e.g. "西方")
+func ParseCategory(s string) (FestivalCategory, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "solarterm" || s == "solar-term" {
+		s = string(CategorySolarTerm)
+	}
+	for _, c := range Categories {
+		if s == string(c) {
+			return c, true
+		}
+	}
+	c, ok := categoryAliases[s]
+	return c, ok
+}
+
+// ParseCategories parses a comma-separated list of categories, skipping unknown ones
+func ParseCategories(s string) []FestivalCategory {
+	var list []FestivalCategory
+	for _, part := range strings.Split(s, ",") {
+		if c, ok := ParseCategory(part); ok {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// JoinCategories formats categories as a comma-separated list in display order
+func JoinCategories(list []FestivalCategory) string {
+	var parts []string
+	for _, c := range Categories {
+		if hasCategory(list, c) {
+			parts = append(parts, string(c))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// hasCategory reports whether list contains c
+func hasCategory(list []FestivalCategory, c FestivalCategory) bool {
+	for _, item := range list {
+		if item == c {
+			return true
+		}
+	}
+	return false
+}
+
+// SolarTermCategory returns the category of a solar term: 清明 is a statutory holiday
+func SolarTermCategory(name string) FestivalCategory {
+	if name == "清明" {
+		return CategoryStatutory
+	}
+	return CategorySolarTerm
+}
+
 // Festival represents a festival or solar term
 type Festival struct {
 	Name        string
@@ -0,0 +1,184 @@
+// Package feed fetches and parses RSS 2.0 and Atom feeds
+package feed
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/safehttp"
+)
+
+// maxFeedSize limits the feed documents read
+const maxFeedSize = 2 << 20
+
+// Feed is a parsed RSS or Atom feed
+type Feed struct {
+	Title string
+	Items []Item // In document order, usually newest first
+}
+
+// Item is a feed entry
+type Item struct {
+	Title     string
+	Link      string
+	Summary   string    // Description or summary as plain text
+	Published time.Time // Zero when missing or unparseable
+}
+
+// Client fetches feeds over HTTP
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a new feed client with the given request timeout. Loopback and private
+// addresses are refused unless allowPrivate is set, for feeds whose URL comes from users.
+func NewClient(timeout time.Duration, allowPrivate bool) *Client {
+	return &Client{httpClient: safehttp.NewClient(timeout, allowPrivate)}
+}
+
+// Fetch downloads and parses the feed at url
+func (c *Client) Fetch(ctx context.Context, url string) (*Feed, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "daily-reminder-bot")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	return Parse(data)
+}
+
+// rssDocument is the subset of RSS 2.0 that is parsed
+type rssDocument struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			PubDate     string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// atomDocument is the subset of Atom that is parsed
+type atomDocument struct {
+	Title   string `xml:"title"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// Parse parses an RSS 2.0 or Atom document
+func Parse(data []byte) (*Feed, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid feed XML: %w", err)
+	}
+
+	switch root.XMLName.Local {
+	case "rss":
+		var doc rssDocument
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid RSS feed: %w", err)
+		}
+		feed := &Feed{Title: strings.TrimSpace(doc.Channel.Title)}
+		for _, item := range doc.Channel.Items {
+			feed.Items = append(feed.Items, Item{
+				Title:     strings.TrimSpace(item.Title),
+				Link:      strings.TrimSpace(item.Link),
+				Summary:   plainText(item.Description),
+				Published: parseTime(item.PubDate),
+			})
+		}
+		return feed, nil
+	case "feed":
+		var doc atomDocument
+		if err := xml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid Atom feed: %w", err)
+		}
+		feed := &Feed{Title: strings.TrimSpace(doc.Title)}
+		for _, entry := range doc.Entries {
+			item := Item{Title: strings.TrimSpace(entry.Title), Summary: plainText(entry.Summary)}
+			if item.Summary == "" {
+				item.Summary = plainText(entry.Content)
+			}
+			for _, link := range entry.Links {
+				if link.Rel == "" || link.Rel == "alternate" {
+					item.Link = strings.TrimSpace(link.Href)
+					break
+				}
+			}
+			if item.Published = parseTime(entry.Published); item.Published.IsZero() {
+				item.Published = parseTime(entry.Updated)
+			}
+			feed.Items = append(feed.Items, item)
+		}
+		return feed, nil
+	}
+	return nil, fmt.Errorf("unsupported feed format <%s>", root.XMLName.Local)
+}
+
+// timeLayouts are the date formats seen in feeds (RFC 822 variants for RSS, RFC 3339 for Atom)
+var timeLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+}
+
+// parseTime parses a feed date, returning the zero time when no layout matches
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+var (
+	tagPattern   = regexp.MustCompile(`<[^>]*>`)
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// plainText strips HTML tags and entities and collapses whitespace
+func plainText(s string) string {
+	s = tagPattern.ReplaceAllString(s, " ")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
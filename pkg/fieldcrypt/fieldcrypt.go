@@ -0,0 +1,91 @@
+// Package fieldcrypt encrypts individual database fields with AES-256-GCM. Sealed values are
+// text ("v1:" followed by base64 of nonce and ciphertext), so they fit ordinary string columns.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the key length in bytes (AES-256)
+const KeySize = 32
+
+// prefix marks sealed values and their format version
+const prefix = "v1:"
+
+// ErrDecrypt is returned when a value cannot be decrypted: wrong key, wrong associated data or
+// tampered ciphertext
+var ErrDecrypt = errors.New("failed to decrypt value")
+
+// Cipher seals and opens field values with one key
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// ParseKey decodes a 32-byte key given as standard base64 (e.g. from `openssl rand -base64 32`)
+// or as 64 hex digits
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if key, err := base64.StdEncoding.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	if key, err := hex.DecodeString(s); err == nil && len(key) == KeySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("key must be %d bytes encoded as base64 or hex", KeySize)
+}
+
+// New creates a Cipher from a 32-byte key
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Seal encrypts plaintext. The associated data (e.g. the owner's ID) is authenticated but not
+// stored, so the value only opens with the same associated data.
+func (c *Cipher) Seal(plaintext, associatedData []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, plaintext, associatedData)
+	return prefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value produced by Seal with the same associated data
+func (c *Cipher) Open(value string, associatedData []byte) ([]byte, error) {
+	if !IsSealed(value) {
+		return nil, fmt.Errorf("%w: not a sealed value", ErrDecrypt)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return nil, fmt.Errorf("%w: malformed value", ErrDecrypt)
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, associatedData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// IsSealed reports whether a value looks like the output of Seal
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
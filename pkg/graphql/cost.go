@@ -0,0 +1,90 @@
+package graphql
+
+import (
+	"math"
+	"strings"
+)
+
+// listCostFactor is how many items the fields selected under a list field are counted for
+const listCostFactor = 10
+
+// costCap saturates cost arithmetic, so that nested lists can't overflow it
+const costCap = math.MaxInt32
+
+// cost returns the cost of resolving a selection set of an object, expanding fragments: every
+// field costs its Cost (1 when unset), and the fields selected under a list field count
+// listCostFactor times. Introspection fields are free. Fragment costs are memoized; spreads of
+// fragments within themselves were rejected by depth.
+func (s *Schema) cost(doc *document, obj *Object, sel []selection, memo map[string]int) int {
+	total := 0
+	for _, sl := range sel {
+		n := 0
+		switch sl := sl.(type) {
+		case *field:
+			if strings.HasPrefix(sl.name, "__") {
+				continue
+			}
+			def := obj.field(sl.name)
+			if def == nil {
+				// Reported when the field is executed
+				continue
+			}
+			n = def.Cost
+			if n <= 0 {
+				n = 1
+			}
+			if sl.sel == nil {
+				break
+			}
+			t := def.Type
+			if nn, ok := t.(*NonNull); ok {
+				t = nn.OfType
+			}
+			_, list := t.(*List)
+			if child, ok := unwrap(t).(*Object); ok {
+				sub := s.cost(doc, child, sl.sel, memo)
+				if list {
+					sub = saturatedMul(sub, listCostFactor)
+				}
+				n = saturatedAdd(n, sub)
+			}
+		case *inlineFragment:
+			n = s.cost(doc, s.conditionType(sl.typeCond, obj), sl.sel, memo)
+		case *fragmentSpread:
+			frag, ok := doc.fragments[sl.name]
+			if !ok {
+				continue
+			}
+			var cached bool
+			if n, cached = memo[sl.name]; !cached {
+				n = s.cost(doc, s.conditionType(frag.typeCond, obj), frag.sel, memo)
+				memo[sl.name] = n
+			}
+		}
+		total = saturatedAdd(total, n)
+	}
+	return total
+}
+
+// conditionType returns the object type named by a fragment's type condition, or obj when there
+// is none or it doesn't name an object
+func (s *Schema) conditionType(name string, obj *Object) *Object {
+	if t, ok := s.types[name].(*Object); ok {
+		return t
+	}
+	return obj
+}
+
+func saturatedAdd(a, b int) int {
+	if a > costCap-b {
+		return costCap
+	}
+	return a + b
+}
+
+func saturatedMul(a, b int) int {
+	if a > costCap/b {
+		return costCap
+	}
+	return a * b
+}
@@ -0,0 +1,455 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// executor executes one operation, collecting field errors
+type executor struct {
+	ctx    context.Context
+	schema *Schema
+	doc    *document
+	vars   map[string]interface{}
+	errors []*Error
+}
+
+func (e *executor) addError(message string, loc Location, path []interface{}) {
+	e.errors = append(e.errors, &Error{Message: message, Locations: []Location{loc}, Path: path})
+}
+
+// orderedMap is a JSON object keeping the order of the selected fields
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// MarshalJSON encodes the fields in selection order
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// inputType returns the schema type of a variable definition
+func (e *executor) inputType(t *typeRef) (Type, error) {
+	var typ Type
+	if t.list != nil {
+		inner, err := e.inputType(t.list)
+		if err != nil {
+			return nil, err
+		}
+		typ = &List{OfType: inner}
+	} else {
+		named, ok := e.schema.types[t.name]
+		if !ok {
+			return nil, fmt.Errorf("Unknown type %q.", t.name)
+		}
+		typ = named
+	}
+	if t.nonNull {
+		typ = &NonNull{OfType: typ}
+	}
+	return typ, nil
+}
+
+// coerceVariables checks the provided variables against the operation's definitions, applying defaults
+func (e *executor) coerceVariables(op *operation, provided map[string]interface{}) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+	for _, def := range op.vars {
+		typ, err := e.inputType(def.typ)
+		if err != nil {
+			return nil, &Error{Message: err.Error(), Locations: []Location{def.loc}}
+		}
+		if !isInputType(typ) {
+			return nil, &Error{Message: fmt.Sprintf("Variable \"$%s\" cannot be non-input type %q.", def.name, def.typ), Locations: []Location{def.loc}}
+		}
+		value, ok := provided[def.name]
+		if !ok {
+			if def.hasDefault {
+				if vars[def.name], err = coerceInput(typ, def.def); err != nil {
+					return nil, &Error{Message: fmt.Sprintf("Variable \"$%s\" has invalid default value: %s", def.name, err), Locations: []Location{def.loc}}
+				}
+			} else if def.typ.nonNull {
+				return nil, &Error{Message: fmt.Sprintf("Variable \"$%s\" of required type %q was not provided.", def.name, def.typ), Locations: []Location{def.loc}}
+			}
+			continue
+		}
+		if vars[def.name], err = coerceInput(typ, value); err != nil {
+			return nil, &Error{Message: fmt.Sprintf("Variable \"$%s\" got invalid value: %s", def.name, err), Locations: []Location{def.loc}}
+		}
+	}
+	return vars, nil
+}
+
+// coerceInput converts an argument or variable value to the given input type
+func coerceInput(t Type, v interface{}) (interface{}, error) {
+	if nn, ok := t.(*NonNull); ok {
+		if v == nil {
+			return nil, fmt.Errorf("Expected non-nullable type %q not to be null.", t)
+		}
+		return coerceInput(nn.OfType, v)
+	}
+	if v == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		items, ok := v.([]interface{})
+		if !ok {
+			item, err := coerceInput(t.OfType, v)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{item}, nil
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			c, err := coerceInput(t.OfType, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = c
+		}
+		return list, nil
+	case *Scalar:
+		return t.ParseValue(v)
+	case *Enum:
+		var name string
+		switch x := v.(type) {
+		case enumValue:
+			name = string(x)
+		case string:
+			name = x
+		}
+		if !t.has(name) {
+			return nil, fmt.Errorf("Value %v does not exist in %q enum.", v, t.Name)
+		}
+		return name, nil
+	}
+	return nil, fmt.Errorf("Type %q is not an input type.", t)
+}
+
+// substitute replaces variables in a literal; ok is false for an undefined variable
+func (e *executor) substitute(v interface{}) (interface{}, bool) {
+	switch x := v.(type) {
+	case variable:
+		value, ok := e.vars[string(x)]
+		return value, ok
+	case []interface{}:
+		list := make([]interface{}, len(x))
+		for i, item := range x {
+			list[i], _ = e.substitute(item)
+		}
+		return list, true
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(x))
+		for k, item := range x {
+			obj[k], _ = e.substitute(item)
+		}
+		return obj, true
+	}
+	return v, true
+}
+
+// coerceArgs returns the arguments of a field or directive, with defaults applied
+func (e *executor) coerceArgs(defs []*Argument, args []*argument, owner string) (map[string]interface{}, error) {
+	given := make(map[string]*argument, len(args))
+	for _, a := range args {
+		found := false
+		for _, d := range defs {
+			if d.Name == a.name {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown argument %q on %s.", a.name, owner)
+		}
+		given[a.name] = a
+	}
+
+	coerced := make(map[string]interface{}, len(defs))
+	for _, d := range defs {
+		if a, ok := given[d.Name]; ok {
+			if value, ok := e.substitute(a.val); ok {
+				c, err := coerceInput(d.Type, value)
+				if err != nil {
+					return nil, fmt.Errorf("Argument %q has invalid value: %s", d.Name, err)
+				}
+				coerced[d.Name] = c
+				continue
+			}
+		}
+		if d.DefaultValue != nil {
+			coerced[d.Name] = d.DefaultValue
+		} else if _, ok := d.Type.(*NonNull); ok {
+			return nil, fmt.Errorf("Argument %q of required type %q was not provided.", d.Name, d.Type)
+		}
+	}
+	return coerced, nil
+}
+
+// include evaluates @skip and @include
+func (e *executor) include(dirs []*directive) (bool, error) {
+	for _, d := range dirs {
+		def := findDirective(d.name)
+		if def == nil {
+			return false, &Error{Message: fmt.Sprintf("Unknown directive \"@%s\".", d.name), Locations: []Location{d.loc}}
+		}
+		args, err := e.coerceArgs(def.Args, d.args, "directive \"@"+d.name+"\"")
+		if err != nil {
+			return false, &Error{Message: err.Error(), Locations: []Location{d.loc}}
+		}
+		cond := args["if"].(bool)
+		if d.name == "skip" && cond || d.name == "include" && !cond {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// fieldGroup is the fields sharing a response key
+type fieldGroup struct {
+	key    string
+	fields []*field
+}
+
+// collectFields flattens fragments in a selection set into fields grouped by response key
+func (e *executor) collectFields(obj *Object, sel []selection, groups *[]*fieldGroup, index map[string]int, visited map[string]bool) error {
+	for _, s := range sel {
+		switch s := s.(type) {
+		case *field:
+			ok, err := e.include(s.directives)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			key := s.responseKey()
+			if i, ok := index[key]; ok {
+				(*groups)[i].fields = append((*groups)[i].fields, s)
+			} else {
+				index[key] = len(*groups)
+				*groups = append(*groups, &fieldGroup{key: key, fields: []*field{s}})
+			}
+		case *fragmentSpread:
+			ok, err := e.include(s.directives)
+			if err != nil {
+				return err
+			}
+			if !ok || visited[s.name] {
+				continue
+			}
+			visited[s.name] = true
+			frag, found := e.doc.fragments[s.name]
+			if !found {
+				return &Error{Message: fmt.Sprintf("Unknown fragment %q.", s.name), Locations: []Location{s.loc}}
+			}
+			if frag.typeCond != obj.Name {
+				continue
+			}
+			if err := e.collectFields(obj, frag.sel, groups, index, visited); err != nil {
+				return err
+			}
+		case *inlineFragment:
+			ok, err := e.include(s.directives)
+			if err != nil {
+				return err
+			}
+			if !ok || s.typeCond != "" && s.typeCond != obj.Name {
+				continue
+			}
+			if err := e.collectFields(obj, s.sel, groups, index, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// selectionSet executes a selection set on an object, returning nil when a non-null field is null
+func (e *executor) selectionSet(obj *Object, source interface{}, sel []selection, path []interface{}) interface{} {
+	var groups []*fieldGroup
+	if err := e.collectFields(obj, sel, &groups, make(map[string]int), make(map[string]bool)); err != nil {
+		e.errors = append(e.errors, toError(err))
+		return nil
+	}
+	result := &orderedMap{values: make(map[string]interface{}, len(groups))}
+	for _, g := range groups {
+		fieldPath := append(path[:len(path):len(path)], g.key)
+		value, def := e.field(obj, source, g.fields, fieldPath)
+		if value == nil && def != nil {
+			if _, ok := def.Type.(*NonNull); ok {
+				return nil
+			}
+		}
+		result.set(g.key, value)
+	}
+	return result
+}
+
+// field executes a field, returning its value and definition (nil for unknown fields)
+func (e *executor) field(obj *Object, source interface{}, fields []*field, path []interface{}) (value interface{}, def *Field) {
+	f := fields[0]
+	switch {
+	case f.name == "__typename":
+		return obj.Name, typenameMetaField
+	case obj == e.schema.query && f.name == "__schema":
+		def, source = schemaMetaField, e.schema
+	case obj == e.schema.query && f.name == "__type":
+		def, source = typeMetaField, e.schema
+	default:
+		def = obj.field(f.name)
+	}
+	if def == nil {
+		e.addError(fmt.Sprintf("Cannot query field %q on type %q.", f.name, obj.Name), f.loc, path)
+		return nil, nil
+	}
+
+	named := unwrap(def.Type)
+	if _, isObject := named.(*Object); isObject && f.sel == nil {
+		e.addError(fmt.Sprintf("Field %q of type %q must have a selection of subfields.", f.name, def.Type), f.loc, path)
+		return nil, nil
+	} else if !isObject && f.sel != nil {
+		e.addError(fmt.Sprintf("Field %q must not have a selection since type %q has no subfields.", f.name, def.Type), f.loc, path)
+		return nil, nil
+	}
+
+	args, err := e.coerceArgs(def.Args, f.args, fmt.Sprintf("field \"%s.%s\"", obj.Name, def.Name))
+	if err != nil {
+		e.addError(err.Error(), f.loc, path)
+		return nil, def
+	}
+
+	resolved, err := e.resolve(def, source, args)
+	if err != nil {
+		e.addError(err.Error(), f.loc, path)
+		return nil, def
+	}
+	value, _ = e.complete(def.Type, fields, resolved, path)
+	return value, def
+}
+
+// resolve calls a field's resolver, turning panics into errors
+func (e *executor) resolve(def *Field, source interface{}, args map[string]interface{}) (value interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("internal error")
+		}
+	}()
+	if def.Resolve == nil {
+		if m, ok := source.(map[string]interface{}); ok {
+			return m[def.Name], nil
+		}
+		return nil, nil
+	}
+	return def.Resolve(ResolveParams{Context: e.ctx, Source: source, Args: args})
+}
+
+// complete converts a resolved value to its result for the given type. errored reports a null
+// caused by an error that has already been recorded.
+func (e *executor) complete(t Type, fields []*field, v interface{}, path []interface{}) (result interface{}, errored bool) {
+	if nn, ok := t.(*NonNull); ok {
+		result, errored = e.complete(nn.OfType, fields, v, path)
+		if result == nil && !errored {
+			e.addError(fmt.Sprintf("Cannot return null for non-nullable field %q.", fields[0].name), fields[0].loc, path)
+		}
+		return result, result == nil
+	}
+	if isNull(v) {
+		return nil, false
+	}
+
+	switch t := t.(type) {
+	case *List:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			e.addError(fmt.Sprintf("Expected a list for field %q.", fields[0].name), fields[0].loc, path)
+			return nil, true
+		}
+		_, nonNullItems := t.OfType.(*NonNull)
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			item, _ := e.complete(t.OfType, fields, rv.Index(i).Interface(), append(path[:len(path):len(path)], i))
+			if item == nil && nonNullItems {
+				return nil, true
+			}
+			items[i] = item
+		}
+		return items, false
+	case *Scalar:
+		s, err := t.Serialize(v)
+		if err != nil {
+			e.addError(err.Error(), fields[0].loc, path)
+			return nil, true
+		}
+		return s, false
+	case *Enum:
+		name := fmt.Sprint(v)
+		if !t.has(name) {
+			e.addError(fmt.Sprintf("Enum %q cannot represent value: %q", t.Name, name), fields[0].loc, path)
+			return nil, true
+		}
+		return name, false
+	case *Object:
+		var sel []selection
+		for _, f := range fields {
+			sel = append(sel, f.sel...)
+		}
+		result := e.selectionSet(t, v, sel, path)
+		return result, result == nil
+	}
+	return nil, false
+}
+
+// isNull reports whether a resolved value is null; nil slices are empty lists, not null
+func isNull(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Interface, reflect.Func:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// unwrap returns the named type inside List and NonNull
+func unwrap(t Type) Type {
+	for {
+		switch x := t.(type) {
+		case *List:
+			t = x.OfType
+		case *NonNull:
+			t = x.OfType
+		default:
+			return t
+		}
+	}
+}
@@ -0,0 +1,409 @@
+// Package graphql is a small GraphQL server implementation for read-only APIs: it executes query
+// operations (variables, fragments, aliases, @skip/@include) against a schema of objects, scalars,
+// enums and lists defined in Go, and answers introspection queries. Mutations, subscriptions,
+// interfaces, unions and input objects are not supported. Operations can be limited in depth and
+// cost before they execute.
+//
+// It serves the bot's one small read-only schema, so it implements only what that schema needs
+// instead of adding a general GraphQL library, and its resolvers stay plain functions over the
+// service layer.
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Type is a GraphQL type: *Scalar, *Enum, *Object, *List or *NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type
+type Scalar struct {
+	Name        string
+	Description string
+	Serialize   func(v interface{}) (interface{}, error) // Converts a resolved value to JSON
+	ParseValue  func(v interface{}) (interface{}, error) // Converts an argument or variable value to Go
+}
+
+func (t *Scalar) String() string { return t.Name }
+
+// Enum is a leaf type with a fixed set of string values
+type Enum struct {
+	Name        string
+	Description string
+	Values      []EnumValue
+}
+
+// EnumValue is a value of an Enum
+type EnumValue struct {
+	Name        string
+	Description string
+}
+
+func (t *Enum) String() string { return t.Name }
+
+func (t *Enum) has(name string) bool {
+	for _, v := range t.Values {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Object is a type with fields
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+func (t *Object) String() string { return t.Name }
+
+func (t *Object) field(name string) *Field {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Field is a field of an Object. Without Resolve, the field is read from a map[string]interface{} source.
+type Field struct {
+	Name              string
+	Description       string
+	Type              Type
+	Args              []*Argument
+	Resolve           ResolveFunc
+	DeprecationReason string
+	Cost              int // Cost of resolving the field, for SetMaxCost (0 = 1)
+}
+
+// Argument is an argument of a field or directive
+type Argument struct {
+	Name         string
+	Description  string
+	Type         Type        // A scalar or enum type, possibly wrapped in List or NonNull
+	DefaultValue interface{} // Used when the argument is not given (nil = no default)
+}
+
+// List wraps a type in a list
+type List struct{ OfType Type }
+
+func (t *List) String() string { return "[" + t.OfType.String() + "]" }
+
+// NonNull wraps a type to forbid null
+type NonNull struct{ OfType Type }
+
+func (t *NonNull) String() string { return t.OfType.String() + "!" }
+
+// ResolveFunc returns the value of a field: a scalar, a string for enums, a slice for lists, or
+// any source value passed on to the fields of objects
+type ResolveFunc func(p ResolveParams) (interface{}, error)
+
+// ResolveParams are the inputs of a ResolveFunc
+type ResolveParams struct {
+	Context context.Context
+	Source  interface{}            // Value of the parent object (nil for the query type)
+	Args    map[string]interface{} // Coerced arguments, with defaults applied
+}
+
+// Error is a GraphQL error in a response
+type Error struct {
+	Message   string        `json:"message"`
+	Locations []Location    `json:"locations,omitempty"`
+	Path      []interface{} `json:"path,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Built-in scalars
+var (
+	Int = &Scalar{
+		Name:        "Int",
+		Description: "The `Int` scalar type represents non-fractional signed whole numeric values between -(2^31) and 2^31 - 1.",
+		Serialize:   coerceInt,
+		ParseValue:  coerceInt,
+	}
+	Float = &Scalar{
+		Name:        "Float",
+		Description: "The `Float` scalar type represents signed double-precision fractional values.",
+		Serialize:   coerceFloat,
+		ParseValue:  coerceFloat,
+	}
+	String = &Scalar{
+		Name:        "String",
+		Description: "The `String` scalar type represents textual data as UTF-8 character sequences.",
+		Serialize:   coerceString,
+		ParseValue:  coerceString,
+	}
+	Boolean = &Scalar{
+		Name:        "Boolean",
+		Description: "The `Boolean` scalar type represents `true` or `false`.",
+		Serialize:   coerceBoolean,
+		ParseValue:  coerceBoolean,
+	}
+	ID = &Scalar{
+		Name:        "ID",
+		Description: "The `ID` scalar type represents a unique identifier, serialized as a string.",
+		Serialize:   coerceID,
+		ParseValue:  coerceID,
+	}
+)
+
+func coerceInt(v interface{}) (interface{}, error) {
+	var n int64
+	switch x := v.(type) {
+	case int:
+		n = int64(x)
+	case int32:
+		n = int64(x)
+	case int64:
+		n = x
+	case uint:
+		n = int64(x)
+	case uint32:
+		n = int64(x)
+	case uint64:
+		n = int64(x)
+	case float64:
+		if x != math.Trunc(x) {
+			return nil, fmt.Errorf("Int cannot represent non-integer value: %v", x)
+		}
+		n = int64(x)
+	case json.Number:
+		i, err := x.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("Int cannot represent non-integer value: %s", x)
+		}
+		n = i
+	default:
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %v", v)
+	}
+	if n > math.MaxInt32 || n < math.MinInt32 {
+		return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %d", n)
+	}
+	return int(n), nil
+}
+
+func coerceFloat(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case float32:
+		return float64(x), nil
+	case json.Number:
+		return x.Float64()
+	}
+	if n, err := coerceInt(v); err == nil {
+		return float64(n.(int)), nil
+	}
+	return nil, fmt.Errorf("Float cannot represent non numeric value: %v", v)
+}
+
+func coerceString(v interface{}) (interface{}, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("String cannot represent a non string value: %v", v)
+}
+
+func coerceBoolean(v interface{}) (interface{}, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %v", v)
+}
+
+func coerceID(v interface{}) (interface{}, error) {
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case int:
+		return strconv.Itoa(x), nil
+	case int64:
+		return strconv.FormatInt(x, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(x), 10), nil
+	case uint64:
+		return strconv.FormatUint(x, 10), nil
+	case json.Number:
+		if _, err := x.Int64(); err == nil {
+			return x.String(), nil
+		}
+	case float64:
+		if x == math.Trunc(x) {
+			return strconv.FormatInt(int64(x), 10), nil
+		}
+	}
+	return nil, fmt.Errorf("ID cannot represent value: %v", v)
+}
+
+// Schema is a GraphQL schema with a query type
+type Schema struct {
+	query    *Object
+	types    map[string]Type // Named types by name, including introspection types
+	names    []string        // Names of types in types, in discovery order
+	maxDepth int             // 0 = unlimited
+	maxCost  int             // 0 = unlimited
+}
+
+// NewSchema creates a schema from its query type, collecting the types reachable from it
+func NewSchema(query *Object) (*Schema, error) {
+	s := &Schema{query: query, types: make(map[string]Type)}
+	if err := s.collect(query); err != nil {
+		return nil, err
+	}
+	if err := s.collect(schemaType); err != nil {
+		return nil, err
+	}
+	for _, d := range directives {
+		for _, a := range d.Args {
+			if err := s.collect(a.Type); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// SetMaxDepth rejects operations whose fields nest deeper than depth levels, counted with their
+// fragments expanded ({ me { id } } is 2 levels), before any resolver runs. 0 disables the limit.
+func (s *Schema) SetMaxDepth(depth int) {
+	s.maxDepth = depth
+}
+
+// SetMaxCost rejects operations costing more than cost before any resolver runs. Every field
+// costs its Cost (1 when unset), the fields selected under a list field count 10 times (once per
+// expected item) and introspection fields are free, so aliases and repeated fragments add up.
+// 0 disables the limit.
+func (s *Schema) SetMaxCost(cost int) {
+	s.maxCost = cost
+}
+
+// collect adds a type and the types it references
+func (s *Schema) collect(t Type) error {
+	switch t := t.(type) {
+	case *List:
+		return s.collect(t.OfType)
+	case *NonNull:
+		return s.collect(t.OfType)
+	}
+	name := t.String()
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("schema has two types named %q", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+	s.names = append(s.names, name)
+	if obj, ok := t.(*Object); ok {
+		for _, f := range obj.Fields {
+			if err := s.collect(f.Type); err != nil {
+				return err
+			}
+			for _, a := range f.Args {
+				if !isInputType(a.Type) {
+					return fmt.Errorf("argument %s.%s(%s) is not a scalar or enum", obj.Name, f.Name, a.Name)
+				}
+				if err := s.collect(a.Type); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isInputType reports whether a type can be used for arguments and variables
+func isInputType(t Type) bool {
+	switch t := t.(type) {
+	case *List:
+		return isInputType(t.OfType)
+	case *NonNull:
+		return isInputType(t.OfType)
+	case *Scalar, *Enum:
+		return true
+	}
+	return false
+}
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Result is the response to a Request. Data is absent when the request failed before execution.
+type Result struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []*Error    `json:"errors,omitempty"`
+}
+
+// Execute parses and executes a query operation
+func Execute(ctx context.Context, schema *Schema, req Request) *Result {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+	if op.kind != "query" {
+		return &Result{Errors: []*Error{{Message: fmt.Sprintf("Schema is not configured to execute %s operation.", op.kind), Locations: []Location{op.loc}}}}
+	}
+	depth, err := doc.depth(op.sel, make(map[string]int), make(map[string]bool))
+	if err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+	if schema.maxDepth > 0 && depth > schema.maxDepth {
+		return &Result{Errors: []*Error{{Message: fmt.Sprintf("Query is nested deeper than %d levels.", schema.maxDepth), Locations: []Location{op.loc}}}}
+	}
+	if schema.maxCost > 0 {
+		if cost := schema.cost(doc, schema.query, op.sel, make(map[string]int)); cost > schema.maxCost {
+			return &Result{Errors: []*Error{{Message: fmt.Sprintf("Query costs %d, more than the limit of %d.", cost, schema.maxCost), Locations: []Location{op.loc}}}}
+		}
+	}
+
+	e := &executor{ctx: ctx, schema: schema, doc: doc}
+	if e.vars, err = e.coerceVariables(op, req.Variables); err != nil {
+		return &Result{Errors: []*Error{toError(err)}}
+	}
+	data := e.selectionSet(schema.query, nil, op.sel, nil)
+	if data == nil {
+		return &Result{Data: json.RawMessage("null"), Errors: e.errors}
+	}
+	return &Result{Data: data, Errors: e.errors}
+}
+
+func toError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return &Error{Message: err.Error()}
+}
+
+// selectOperation picks the operation to execute by name
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q.", name)}
+}
@@ -0,0 +1,348 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testSchema is a small library schema: a query with a book list, books with an author and
+// authors with their books, so that queries can nest arbitrarily deep
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+	genre := &Enum{Name: "Genre", Values: []EnumValue{{Name: "FICTION"}, {Name: "POETRY"}}}
+	author := &Object{Name: "Author"}
+	book := &Object{Name: "Book", Fields: []*Field{
+		{Name: "title", Type: &NonNull{OfType: String}},
+		{Name: "year", Type: Int},
+		{Name: "genre", Type: genre},
+		{Name: "author", Type: author, Resolve: func(p ResolveParams) (interface{}, error) {
+			return map[string]interface{}{"name": p.Source.(map[string]interface{})["author"]}, nil
+		}},
+		{Name: "isbn", Type: String, DeprecationReason: "Use id", Resolve: func(p ResolveParams) (interface{}, error) {
+			panic("unreachable")
+		}},
+	}}
+	author.Fields = []*Field{
+		{Name: "name", Type: &NonNull{OfType: String}},
+		{Name: "books", Type: &List{OfType: &NonNull{OfType: book}}, Resolve: func(p ResolveParams) (interface{}, error) {
+			return books(p.Source.(map[string]interface{})["name"], nil), nil
+		}},
+	}
+	query := &Object{Name: "Query", Fields: []*Field{
+		{Name: "books", Type: &NonNull{OfType: &List{OfType: &NonNull{OfType: book}}}, Args: []*Argument{
+			{Name: "genre", Type: genre},
+			{Name: "first", Type: Int, DefaultValue: 10},
+		}, Resolve: func(p ResolveParams) (interface{}, error) {
+			list := books(nil, p.Args["genre"])
+			if first := p.Args["first"].(int); first < len(list) {
+				list = list[:first]
+			}
+			return list, nil
+		}},
+		{Name: "book", Type: book, Args: []*Argument{{Name: "title", Type: &NonNull{OfType: String}}}, Resolve: func(p ResolveParams) (interface{}, error) {
+			for _, b := range books(nil, nil) {
+				if b["title"] == p.Args["title"] {
+					return b, nil
+				}
+			}
+			return nil, nil
+		}},
+		{Name: "broken", Type: &NonNull{OfType: String}, Resolve: func(p ResolveParams) (interface{}, error) {
+			return nil, nil
+		}},
+	}}
+	schema, err := NewSchema(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+// books returns the books of the test library, filtered by author and genre when not nil
+func books(author, genre interface{}) []map[string]interface{} {
+	all := []map[string]interface{}{
+		{"title": "边城", "year": 1934, "genre": "FICTION", "author": "沈从文"},
+		{"title": "长河", "year": 1945, "genre": "FICTION", "author": "沈从文"},
+		{"title": "再别康桥", "year": 1928, "genre": "POETRY", "author": "徐志摩"},
+	}
+	var list []map[string]interface{}
+	for _, b := range all {
+		if (author == nil || b["author"] == author) && (genre == nil || b["genre"] == genre) {
+			list = append(list, b)
+		}
+	}
+	return list
+}
+
+// execute runs a query and returns its result encoded as JSON
+func execute(t *testing.T, schema *Schema, req Request) string {
+	t.Helper()
+	data, err := json.Marshal(Execute(context.Background(), schema, req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestExecute(t *testing.T) {
+	schema := testSchema(t)
+	tests := []struct {
+		name  string
+		query string
+		vars  string
+		op    string
+		want  string
+	}{
+		{
+			name:  "fields in selection order",
+			query: `{ books(first: 1) { year title } }`,
+			want:  `{"data":{"books":[{"year":1934,"title":"边城"}]}}`,
+		},
+		{
+			name:  "aliases and enum arguments",
+			query: `query { poems: books(genre: POETRY) { title } count: books { t: title } }`,
+			want:  `{"data":{"poems":[{"title":"再别康桥"}],"count":[{"t":"边城"},{"t":"长河"},{"t":"再别康桥"}]}}`,
+		},
+		{
+			name:  "named and inline fragments",
+			query: `query Q { book(title: "边城") { ...Basic ... on Book { author { name } } ... { __typename } } } fragment Basic on Book { title year }`,
+			want:  `{"data":{"book":{"title":"边城","year":1934,"author":{"name":"沈从文"},"__typename":"Book"}}}`,
+		},
+		{
+			name:  "fragments on another type are skipped",
+			query: `{ book(title: "边城") { title ...A } } fragment A on Author { name }`,
+			want:  `{"data":{"book":{"title":"边城"}}}`,
+		},
+		{
+			name:  "variables with defaults",
+			query: `query ($title: String!, $first: Int = 1, $genre: Genre) { book(title: $title) { title } books(first: $first, genre: $genre) { title } }`,
+			vars:  `{"title": "长河", "genre": "FICTION"}`,
+			want:  `{"data":{"book":{"title":"长河"},"books":[{"title":"边城"}]}}`,
+		},
+		{
+			name:  "skip and include",
+			query: `query ($yes: Boolean!) { book(title: "边城") { title @skip(if: $yes) year @include(if: $yes) ... @include(if: false) { genre } } }`,
+			vars:  `{"yes": true}`,
+			want:  `{"data":{"book":{"year":1934}}}`,
+		},
+		{
+			name:  "operation name",
+			query: `query A { books(first: 1) { title } } query B { book(title: "再别康桥") { year } }`,
+			op:    "B",
+			want:  `{"data":{"book":{"year":1928}}}`,
+		},
+		{
+			name:  "null object",
+			query: `{ book(title: "不存在") { title } }`,
+			want:  `{"data":{"book":null}}`,
+		},
+		{
+			name:  "null for a non-null field nulls the parent",
+			query: `{ books(first: 1) { title } broken }`,
+			want:  `{"data":null,"errors":[{"message":"Cannot return null for non-nullable field \"broken\".","locations":[{"line":1,"column":29}],"path":["broken"]}]}`,
+		},
+		{
+			name:  "resolver panics become errors",
+			query: `{ book(title: "边城") { title isbn } }`,
+			want:  `{"data":{"book":{"title":"边城","isbn":null}},"errors":[{"message":"internal error","locations":[{"line":1,"column":29}],"path":["book","isbn"]}]}`,
+		},
+		{
+			name:  "unknown field",
+			query: `{ book(title: "边城") { pages } }`,
+			want:  `{"data":{"book":{"pages":null}},"errors":[{"message":"Cannot query field \"pages\" on type \"Book\".","locations":[{"line":1,"column":23}],"path":["book","pages"]}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Query: tt.query, OperationName: tt.op}
+			if tt.vars != "" {
+				if err := json.Unmarshal([]byte(tt.vars), &req.Variables); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := execute(t, schema, req); got != tt.want {
+				t.Errorf("\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Malformed and invalid requests fail with an error and no data
+func TestExecuteRejects(t *testing.T) {
+	schema := testSchema(t)
+	tests := []struct {
+		name  string
+		query string
+		vars  string
+		op    string
+		want  string // Substring of the error message
+	}{
+		{name: "empty document", query: ``, want: "Document contains no operations"},
+		{name: "unclosed selection", query: `{ books { title }`, want: "Syntax Error: Unexpected <EOF>"},
+		{name: "unexpected character", query: `{ books { title ^ } }`, want: "Unexpected character"},
+		{name: "unterminated string", query: `{ book(title: "边城) { title } }`, want: "Unterminated string"},
+		{name: "empty selection", query: `{ books { } }`, want: "Expected Name"},
+		{name: "mutation", query: `mutation { books { title } }`, want: "not configured to execute mutation"},
+		{name: "several operations without a name", query: `query A { broken } query B { broken }`, want: "Must provide operation name"},
+		{name: "unknown operation", query: `query A { broken }`, op: "B", want: `Unknown operation named "B"`},
+		{name: "duplicate fragment", query: `{ broken } fragment A on Query { broken } fragment A on Query { broken }`, want: "only one fragment"},
+		{name: "fragment spreading itself", query: `{ book(title: "边城") { ...A } } fragment A on Book { author { books { ...A } } }`, want: `Cannot spread fragment "A" within itself`},
+		{name: "fragment cycle", query: `{ book(title: "边城") { ...A } } fragment A on Book { ...B } fragment B on Book { ...A }`, want: "within itself"},
+		{name: "missing required variable", query: `query ($t: String!) { book(title: $t) { title } }`, want: `Variable "$t" of required type "String!" was not provided`},
+		{name: "variable of the wrong type", query: `query ($n: Int) { books(first: $n) { title } }`, vars: `{"n": "ten"}`, want: `Variable "$n" got invalid value`},
+		{name: "unknown variable type", query: `query ($m: Magazine) { broken }`, want: `Unknown type "Magazine"`},
+		{name: "object variable", query: `query ($b: Book) { broken }`, want: `Variable "$b" cannot be non-input type "Book"`},
+		{name: "unknown directive", query: `{ broken @cached }`, want: `Unknown directive "@cached"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := Request{Query: tt.query, OperationName: tt.op}
+			if tt.vars != "" {
+				if err := json.Unmarshal([]byte(tt.vars), &req.Variables); err != nil {
+					t.Fatal(err)
+				}
+			}
+			result := Execute(context.Background(), schema, req)
+			if len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Message, tt.want) {
+				t.Fatalf("errors = %v, want %q", result.Errors, tt.want)
+			}
+			if tt.name != "unknown directive" && result.Data != nil {
+				t.Errorf("data = %v, want none", result.Data)
+			}
+		})
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	schema := testSchema(t)
+	schema.SetMaxDepth(4)
+	tests := []struct {
+		name  string
+		query string
+		ok    bool
+	}{
+		{name: "at the limit", query: `{ book(title: "边城") { author { books { title } } } }`, ok: true},
+		{name: "too deep", query: `{ book(title: "边城") { author { books { author { name } } } } }`},
+		{name: "too deep through fragments", query: `{ book(title: "边城") { ...A } } fragment A on Book { author { ...B } } fragment B on Author { books { author { name } } }`},
+		{name: "too deep through inline fragments", query: `{ book(title: "边城") { ... { author { ... on Author { books { author { name } } } } } } }`},
+		{
+			// Each fragment spreads the next twice: walked naively this is 2^20 spreads
+			name:  "fragments spread many times",
+			query: `{ book(title: "边城") { ...F0 } }` + fragmentChain(20),
+			ok:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Execute(context.Background(), schema, Request{Query: tt.query})
+			if tt.ok && len(result.Errors) > 0 {
+				t.Fatalf("errors = %v", result.Errors)
+			}
+			if !tt.ok && (len(result.Errors) != 1 || result.Errors[0].Message != "Query is nested deeper than 4 levels." || result.Data != nil) {
+				t.Fatalf("result = %+v, want the depth error", result)
+			}
+		})
+	}
+}
+
+func TestMaxCost(t *testing.T) {
+	schema := testSchema(t)
+	schema.SetMaxCost(50)
+	tests := []struct {
+		name  string
+		query string
+		ok    bool
+	}{
+		{name: "list", query: `{ books { title author { name } } }`, ok: true}, // 1 + 10 * 3
+		{name: "introspection is free", query: `{ __schema { types { name fields { name args { name } } } } }`, ok: true},
+		{name: "aliases add up", query: `{ a: books { title } b: books { title } c: books { title } d: books { title } e: books { title } }`},
+		{name: "nested lists multiply", query: `{ books { author { books { title } } } }`},
+		{name: "repeated fragments add up", query: `{ books { ...F0 } }` + fragmentChain(20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Execute(context.Background(), schema, Request{Query: tt.query})
+			if tt.ok && len(result.Errors) > 0 {
+				t.Fatalf("errors = %v", result.Errors)
+			}
+			if !tt.ok && (len(result.Errors) != 1 || !strings.HasPrefix(result.Errors[0].Message, "Query costs ") || result.Data != nil) {
+				t.Fatalf("result = %+v, want the cost error", result)
+			}
+		})
+	}
+}
+
+// fragmentChain returns n fragments on Book, each spreading the next one twice
+func fragmentChain(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteString(" fragment F" + strconv.Itoa(i) + " on Book { title")
+		if i+1 < n {
+			b.WriteString(" ...F" + strconv.Itoa(i+1) + " ...F" + strconv.Itoa(i+1))
+		}
+		b.WriteString(" }")
+	}
+	return b.String()
+}
+
+func TestIntrospection(t *testing.T) {
+	schema := testSchema(t)
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "type by name",
+			query: `{ __type(name: "Genre") { kind name enumValues { name } } }`,
+			want:  `{"data":{"__type":{"kind":"ENUM","name":"Genre","enumValues":[{"name":"FICTION"},{"name":"POETRY"}]}}}`,
+		},
+		{
+			name:  "wrapped field types",
+			query: `{ __type(name: "Query") { fields { name type { kind ofType { kind ofType { kind ofType { name } } } } } } }`,
+			want:  `{"data":{"__type":{"fields":[{"name":"books","type":{"kind":"NON_NULL","ofType":{"kind":"LIST","ofType":{"kind":"NON_NULL","ofType":{"name":"Book"}}}}},{"name":"book","type":{"kind":"OBJECT","ofType":null}},{"name":"broken","type":{"kind":"NON_NULL","ofType":{"kind":"SCALAR","ofType":null}}}]}}}`,
+		},
+		{
+			name:  "arguments with defaults",
+			query: `{ __type(name: "Query") { fields { name args { name defaultValue } } } }`,
+			want:  `{"data":{"__type":{"fields":[{"name":"books","args":[{"name":"genre","defaultValue":null},{"name":"first","defaultValue":"10"}]},{"name":"book","args":[{"name":"title","defaultValue":null}]},{"name":"broken","args":[]}]}}}`,
+		},
+		{
+			name:  "deprecated fields",
+			query: `{ __type(name: "Book") { current: fields { name } all: fields(includeDeprecated: true) { name isDeprecated deprecationReason } } }`,
+			want:  `{"data":{"__type":{"current":[{"name":"title"},{"name":"year"},{"name":"genre"},{"name":"author"}],"all":[{"name":"title","isDeprecated":false,"deprecationReason":null},{"name":"year","isDeprecated":false,"deprecationReason":null},{"name":"genre","isDeprecated":false,"deprecationReason":null},{"name":"author","isDeprecated":false,"deprecationReason":null},{"name":"isbn","isDeprecated":true,"deprecationReason":"Use id"}]}}}`,
+		},
+		{
+			name:  "unknown type",
+			query: `{ __type(name: "Magazine") { name } }`,
+			want:  `{"data":{"__type":null}}`,
+		},
+		{
+			name:  "schema",
+			query: `{ __schema { queryType { name } mutationType { name } directives { name } } }`,
+			want:  `{"data":{"__schema":{"queryType":{"name":"Query"},"mutationType":null,"directives":[{"name":"include"},{"name":"skip"}]}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execute(t, schema, Request{Query: tt.query}); got != tt.want {
+				t.Errorf("\n got %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+
+	// The query GraphiQL and other clients send to load a schema
+	result := Execute(context.Background(), schema, Request{Query: IntrospectionQuery})
+	if len(result.Errors) > 0 {
+		t.Fatalf("introspection query errors = %v", result.Errors)
+	}
+	data, _ := json.Marshal(result.Data)
+	for _, want := range []string{`"name":"Book"`, `"name":"__Schema"`, `"name":"String"`, `"kind":"ENUM"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("introspection result lacks %s", want)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package graphql
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// directiveDef is a directive supported in queries
+type directiveDef struct {
+	Name        string
+	Description string
+	Locations   []string
+	Args        []*Argument
+}
+
+// directives are the directives supported in queries
+var directives = []*directiveDef{
+	{
+		Name:        "include",
+		Description: "Directs the executor to include this field or fragment only when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        []*Argument{{Name: "if", Description: "Included when true.", Type: &NonNull{OfType: Boolean}}},
+	},
+	{
+		Name:        "skip",
+		Description: "Directs the executor to skip this field or fragment when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:        []*Argument{{Name: "if", Description: "Skipped when true.", Type: &NonNull{OfType: Boolean}}},
+	},
+}
+
+func findDirective(name string) *directiveDef {
+	for _, d := range directives {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// Introspection types
+var (
+	schemaType     = &Object{Name: "__Schema", Description: "A GraphQL Schema defines the capabilities of a GraphQL server."}
+	typeType       = &Object{Name: "__Type", Description: "The fundamental unit of any GraphQL Schema is the type."}
+	fieldType      = &Object{Name: "__Field", Description: "Object and Interface types are described by a list of Fields, each of which has a name, potentially a list of arguments, and a return type."}
+	inputValueType = &Object{Name: "__InputValue", Description: "Arguments provided to Fields or Directives and the input fields of an InputObject are represented as Input Values which describe their type and optionally a default value."}
+	enumValueType  = &Object{Name: "__EnumValue", Description: "One possible value for a given Enum."}
+	directiveType  = &Object{Name: "__Directive", Description: "A Directive provides a way to describe alternate runtime execution and type validation behavior in a GraphQL document."}
+
+	typeKindEnum = &Enum{Name: "__TypeKind", Description: "An enum describing what kind of type a given `__Type` is.", Values: []EnumValue{
+		{Name: "SCALAR"}, {Name: "OBJECT"}, {Name: "INTERFACE"}, {Name: "UNION"},
+		{Name: "ENUM"}, {Name: "INPUT_OBJECT"}, {Name: "LIST"}, {Name: "NON_NULL"},
+	}}
+	directiveLocationEnum = &Enum{Name: "__DirectiveLocation", Description: "A Directive can be adjacent to many parts of the GraphQL language.", Values: []EnumValue{
+		{Name: "QUERY"}, {Name: "MUTATION"}, {Name: "SUBSCRIPTION"}, {Name: "FIELD"},
+		{Name: "FRAGMENT_DEFINITION"}, {Name: "FRAGMENT_SPREAD"}, {Name: "INLINE_FRAGMENT"}, {Name: "VARIABLE_DEFINITION"},
+	}}
+)
+
+// Meta fields available on every type (__typename) or the query type (__schema, __type)
+var (
+	typenameMetaField = &Field{Name: "__typename", Type: &NonNull{OfType: String}}
+	schemaMetaField   = &Field{
+		Name: "__schema",
+		Type: &NonNull{OfType: schemaType},
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source, nil
+		},
+	}
+	typeMetaField = &Field{
+		Name: "__type",
+		Type: typeType,
+		Args: []*Argument{{Name: "name", Type: &NonNull{OfType: String}}},
+		Resolve: func(p ResolveParams) (interface{}, error) {
+			t, ok := p.Source.(*Schema).types[p.Args["name"].(string)]
+			if !ok {
+				return nil, nil
+			}
+			return t, nil
+		},
+	}
+)
+
+// includeDeprecated is the argument of introspection fields listing fields, arguments and enum values
+var includeDeprecated = []*Argument{{Name: "includeDeprecated", Type: Boolean, DefaultValue: false}}
+
+func init() {
+	nonNullString := &NonNull{OfType: String}
+	nonNullBoolean := &NonNull{OfType: Boolean}
+	nonNullType := &NonNull{OfType: typeType}
+	listOf := func(t Type) Type { return &List{OfType: &NonNull{OfType: t}} }
+
+	schemaType.Fields = []*Field{
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "types", Type: &NonNull{OfType: listOf(typeType)}, Resolve: func(p ResolveParams) (interface{}, error) {
+			s := p.Source.(*Schema)
+			types := make([]Type, len(s.names))
+			for i, name := range s.names {
+				types[i] = s.types[name]
+			}
+			return types, nil
+		}},
+		{Name: "queryType", Type: nonNullType, Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source.(*Schema).query, nil
+		}},
+		{Name: "mutationType", Type: typeType, Resolve: func(p ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "subscriptionType", Type: typeType, Resolve: func(p ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "directives", Type: &NonNull{OfType: listOf(directiveType)}, Resolve: func(p ResolveParams) (interface{}, error) {
+			return directives, nil
+		}},
+	}
+
+	typeType.Fields = []*Field{
+		{Name: "kind", Type: &NonNull{OfType: typeKindEnum}, Resolve: func(p ResolveParams) (interface{}, error) {
+			switch p.Source.(type) {
+			case *Scalar:
+				return "SCALAR", nil
+			case *Enum:
+				return "ENUM", nil
+			case *Object:
+				return "OBJECT", nil
+			case *List:
+				return "LIST", nil
+			}
+			return "NON_NULL", nil
+		}},
+		{Name: "name", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			switch t := p.Source.(type) {
+			case *List, *NonNull:
+				return nil, nil
+			default:
+				return t.(Type).String(), nil
+			}
+		}},
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			switch t := p.Source.(type) {
+			case *Scalar:
+				return optional(t.Description), nil
+			case *Enum:
+				return optional(t.Description), nil
+			case *Object:
+				return optional(t.Description), nil
+			}
+			return nil, nil
+		}},
+		{Name: "specifiedByURL", Type: String, Resolve: func(p ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "fields", Type: listOf(fieldType), Args: includeDeprecated, Resolve: func(p ResolveParams) (interface{}, error) {
+			obj, ok := p.Source.(*Object)
+			if !ok {
+				return nil, nil
+			}
+			fields := []*Field{}
+			for _, f := range obj.Fields {
+				if f.DeprecationReason == "" || p.Args["includeDeprecated"].(bool) {
+					fields = append(fields, f)
+				}
+			}
+			return fields, nil
+		}},
+		{Name: "interfaces", Type: listOf(typeType), Resolve: func(p ResolveParams) (interface{}, error) {
+			if _, ok := p.Source.(*Object); ok {
+				return []Type{}, nil
+			}
+			return nil, nil
+		}},
+		{Name: "possibleTypes", Type: listOf(typeType), Resolve: func(p ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "enumValues", Type: listOf(enumValueType), Args: includeDeprecated, Resolve: func(p ResolveParams) (interface{}, error) {
+			if t, ok := p.Source.(*Enum); ok {
+				return t.Values, nil
+			}
+			return nil, nil
+		}},
+		{Name: "inputFields", Type: listOf(inputValueType), Args: includeDeprecated, Resolve: func(p ResolveParams) (interface{}, error) { return nil, nil }},
+		{Name: "ofType", Type: typeType, Resolve: func(p ResolveParams) (interface{}, error) {
+			switch t := p.Source.(type) {
+			case *List:
+				return t.OfType, nil
+			case *NonNull:
+				return t.OfType, nil
+			}
+			return nil, nil
+		}},
+		{Name: "isOneOf", Type: Boolean, Resolve: func(p ResolveParams) (interface{}, error) { return nil, nil }},
+	}
+
+	fieldType.Fields = []*Field{
+		{Name: "name", Type: nonNullString, Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(*Field).Name, nil }},
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (interface{}, error) { return optional(p.Source.(*Field).Description), nil }},
+		{Name: "args", Type: &NonNull{OfType: listOf(inputValueType)}, Args: includeDeprecated, Resolve: func(p ResolveParams) (interface{}, error) {
+			return append([]*Argument{}, p.Source.(*Field).Args...), nil
+		}},
+		{Name: "type", Type: nonNullType, Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(*Field).Type, nil }},
+		{Name: "isDeprecated", Type: nonNullBoolean, Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source.(*Field).DeprecationReason != "", nil
+		}},
+		{Name: "deprecationReason", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			return optional(p.Source.(*Field).DeprecationReason), nil
+		}},
+	}
+
+	inputValueType.Fields = []*Field{
+		{Name: "name", Type: nonNullString, Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(*Argument).Name, nil }},
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (interface{}, error) { return optional(p.Source.(*Argument).Description), nil }},
+		{Name: "type", Type: nonNullType, Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(*Argument).Type, nil }},
+		{Name: "defaultValue", Type: String, Resolve: func(p ResolveParams) (interface{}, error) {
+			if def := p.Source.(*Argument).DefaultValue; def != nil {
+				return formatLiteral(def), nil
+			}
+			return nil, nil
+		}},
+		{Name: "isDeprecated", Type: nonNullBoolean, Resolve: func(p ResolveParams) (interface{}, error) { return false, nil }},
+		{Name: "deprecationReason", Type: String, Resolve: func(p ResolveParams) (interface{}, error) { return nil, nil }},
+	}
+
+	enumValueType.Fields = []*Field{
+		{Name: "name", Type: nonNullString, Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(EnumValue).Name, nil }},
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (interface{}, error) { return optional(p.Source.(EnumValue).Description), nil }},
+		{Name: "isDeprecated", Type: nonNullBoolean, Resolve: func(p ResolveParams) (interface{}, error) { return false, nil }},
+		{Name: "deprecationReason", Type: String, Resolve: func(p ResolveParams) (interface{}, error) { return nil, nil }},
+	}
+
+	directiveType.Fields = []*Field{
+		{Name: "name", Type: nonNullString, Resolve: func(p ResolveParams) (interface{}, error) { return p.Source.(*directiveDef).Name, nil }},
+		{Name: "description", Type: String, Resolve: func(p ResolveParams) (interface{}, error) { return optional(p.Source.(*directiveDef).Description), nil }},
+		{Name: "locations", Type: &NonNull{OfType: listOf(directiveLocationEnum)}, Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source.(*directiveDef).Locations, nil
+		}},
+		{Name: "args", Type: &NonNull{OfType: listOf(inputValueType)}, Args: includeDeprecated, Resolve: func(p ResolveParams) (interface{}, error) {
+			return p.Source.(*directiveDef).Args, nil
+		}},
+		{Name: "isRepeatable", Type: nonNullBoolean, Resolve: func(p ResolveParams) (interface{}, error) { return false, nil }},
+	}
+}
+
+// optional returns nil for an empty description
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// formatLiteral formats a default value as a GraphQL literal
+func formatLiteral(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(x)
+	case int:
+		return strconv.Itoa(x)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case string:
+		b, _ := json.Marshal(x)
+		return string(b)
+	case []interface{}:
+		items := make([]string, len(x))
+		for i, item := range x {
+			items[i] = formatLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// IntrospectionQuery is the query GraphiQL and other clients send to load a schema; depth limits
+// (Schema.SetMaxDepth) must leave room for it
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      isRepeatable
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  specifiedByURL
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType {
+                kind
+                name
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
@@ -0,0 +1,791 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSelectionDepth bounds the nesting of selection sets; the standard introspection query needs about 13
+const maxSelectionDepth = 20
+
+// Location is a line and column in a query, counted from 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+// lexer splits a query into tokens, skipping whitespace, commas and comments
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	col  int
+}
+
+func (l *lexer) advance(n int) {
+	for i := 0; i < n && l.pos < len(l.src); i++ {
+		if l.src[l.pos] == '\n' {
+			l.line++
+			l.col = 1
+		} else if l.src[l.pos]&0xC0 != 0x80 {
+			l.col++
+		}
+		l.pos++
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			l.advance(1)
+		} else if c == '#' {
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.advance(1)
+			}
+		} else if strings.HasPrefix(l.src[l.pos:], "\ufeff") {
+			l.advance(3)
+		} else {
+			break
+		}
+	}
+	loc := Location{Line: l.line, Column: l.col}
+	if l.pos >= len(l.src) {
+		return token{kind: tokenEOF, loc: loc}, nil
+	}
+
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.advance(3)
+		return token{kind: tokenPunct, value: "...", loc: loc}, nil
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.advance(1)
+		return token{kind: tokenPunct, value: string(c), loc: loc}, nil
+	case c == '_' || isLetter(c):
+		start := l.pos
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.advance(1)
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], loc: loc}, nil
+	case c == '-' || isDigit(c):
+		return l.number(loc)
+	case c == '"':
+		if strings.HasPrefix(l.src[l.pos:], `"""`) {
+			return l.blockString(loc)
+		}
+		return l.string(loc)
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos:])
+	return token{}, &Error{Message: fmt.Sprintf("Syntax Error: Unexpected character %q.", r), Locations: []Location{loc}}
+}
+
+func (l *lexer) number(loc Location) (token, error) {
+	start := l.pos
+	float := false
+	if l.src[l.pos] == '-' {
+		l.advance(1)
+	}
+	digits := func() {
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.advance(1)
+		}
+	}
+	digits()
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		float = true
+		l.advance(1)
+		digits()
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		float = true
+		l.advance(1)
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.advance(1)
+		}
+		digits()
+	}
+	raw := l.src[start:l.pos]
+	if float {
+		if _, err := strconv.ParseFloat(raw, 64); err != nil {
+			return token{}, &Error{Message: fmt.Sprintf("Syntax Error: Invalid number %q.", raw), Locations: []Location{loc}}
+		}
+		return token{kind: tokenFloat, value: raw, loc: loc}, nil
+	}
+	if raw == "-" {
+		return token{}, &Error{Message: "Syntax Error: Invalid number \"-\".", Locations: []Location{loc}}
+	}
+	return token{kind: tokenInt, value: raw, loc: loc}, nil
+}
+
+func (l *lexer) string(loc Location) (token, error) {
+	l.advance(1)
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.advance(1)
+			return token{kind: tokenString, value: b.String(), loc: loc}, nil
+		case c == '\n' || c == '\r':
+			return token{}, &Error{Message: "Syntax Error: Unterminated string.", Locations: []Location{loc}}
+		case c == '\\' && l.pos+1 < len(l.src):
+			esc := l.src[l.pos+1]
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+6 > len(l.src) {
+					return token{}, &Error{Message: "Syntax Error: Invalid Unicode escape sequence.", Locations: []Location{loc}}
+				}
+				n, err := strconv.ParseUint(l.src[l.pos+2:l.pos+6], 16, 32)
+				if err != nil {
+					return token{}, &Error{Message: "Syntax Error: Invalid Unicode escape sequence.", Locations: []Location{loc}}
+				}
+				b.WriteRune(rune(n))
+				l.advance(4)
+			default:
+				return token{}, &Error{Message: fmt.Sprintf("Syntax Error: Invalid character escape sequence \\%c.", esc), Locations: []Location{loc}}
+			}
+			l.advance(2)
+		default:
+			b.WriteByte(c)
+			l.advance(1)
+		}
+	}
+	return token{}, &Error{Message: "Syntax Error: Unterminated string.", Locations: []Location{loc}}
+}
+
+func (l *lexer) blockString(loc Location) (token, error) {
+	l.advance(3)
+	end := strings.Index(l.src[l.pos:], `"""`)
+	if end < 0 {
+		return token{}, &Error{Message: "Syntax Error: Unterminated string.", Locations: []Location{loc}}
+	}
+	raw := strings.ReplaceAll(l.src[l.pos:l.pos+end], `\"""`, `"""`)
+	l.advance(end + 3)
+
+	// Remove the common indentation and leading/trailing blank lines
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && (indent < 0 || len(line)-len(trimmed) < indent) {
+			indent = len(line) - len(trimmed)
+		}
+	}
+	for i := 1; i < len(lines) && indent > 0; i++ {
+		if len(lines[i]) >= indent {
+			lines[i] = lines[i][indent:]
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return token{kind: tokenString, value: strings.Join(lines, "\n"), loc: loc}, nil
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+
+// document is a parsed executable document
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragmentDef
+}
+
+type operation struct {
+	kind string // query, mutation or subscription
+	name string
+	vars []*varDef
+	sel  []selection
+	loc  Location
+}
+
+type varDef struct {
+	name       string
+	typ        *typeRef
+	def        interface{}
+	hasDefault bool
+	loc        Location
+}
+
+// typeRef is a type as written in a query, e.g. [String!]
+type typeRef struct {
+	name    string
+	list    *typeRef
+	nonNull bool
+}
+
+func (t *typeRef) String() string {
+	s := t.name
+	if t.list != nil {
+		s = "[" + t.list.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type selection interface{}
+
+type field struct {
+	alias      string
+	name       string
+	args       []*argument
+	directives []*directive
+	sel        []selection
+	loc        Location
+}
+
+// responseKey is the key of the field in the result
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+	loc        Location
+}
+
+type inlineFragment struct {
+	typeCond   string
+	directives []*directive
+	sel        []selection
+}
+
+type fragmentDef struct {
+	name     string
+	typeCond string
+	sel      []selection
+}
+
+type argument struct {
+	name string
+	val  interface{}
+	loc  Location
+}
+
+type directive struct {
+	name string
+	args []*argument
+	loc  Location
+}
+
+// Literal values in a query besides int64, float64, string, bool, nil, []interface{} and map[string]interface{}
+type (
+	variable  string
+	enumValue string
+)
+
+// parser builds a document from tokens
+type parser struct {
+	lex   *lexer
+	tok   token
+	depth int
+}
+
+// parse parses an executable GraphQL document
+func parse(src string) (*document, error) {
+	p := &parser{lex: &lexer{src: src, line: 1, col: 1}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	doc := &document{fragments: make(map[string]*fragmentDef)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunct, "{"):
+			op := &operation{kind: "query", loc: p.tok.loc}
+			sel, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			op.sel = sel
+			doc.operations = append(doc.operations, op)
+		case p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.peek(tokenName, "fragment"):
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := doc.fragments[frag.name]; ok {
+				return nil, &Error{Message: fmt.Sprintf("There can be only one fragment named %q.", frag.name)}
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, &Error{Message: "Syntax Error: Document contains no operations."}
+	}
+	return doc, nil
+}
+
+// depth returns how deep the fields of a selection set nest, expanding fragments. Fragment
+// depths are memoized, so that fragments spread many times are walked once, and fragments
+// spreading themselves (being spread) are rejected.
+func (d *document) depth(sel []selection, memo map[string]int, spreading map[string]bool) (int, error) {
+	deepest := 0
+	for _, s := range sel {
+		n := 0
+		var err error
+		switch s := s.(type) {
+		case *field:
+			n = 1
+			if s.sel != nil {
+				var sub int
+				sub, err = d.depth(s.sel, memo, spreading)
+				n += sub
+			}
+		case *inlineFragment:
+			n, err = d.depth(s.sel, memo, spreading)
+		case *fragmentSpread:
+			frag, ok := d.fragments[s.name]
+			if !ok {
+				// Reported when the fragment is executed
+				continue
+			}
+			if spreading[s.name] {
+				return 0, &Error{Message: fmt.Sprintf("Cannot spread fragment %q within itself.", s.name), Locations: []Location{s.loc}}
+			}
+			var cached bool
+			if n, cached = memo[s.name]; !cached {
+				spreading[s.name] = true
+				n, err = d.depth(frag.sel, memo, spreading)
+				delete(spreading, s.name)
+				memo[s.name] = n
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+		if n > deepest {
+			deepest = n
+		}
+	}
+	return deepest, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) peek(kind tokenKind, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+func (p *parser) unexpected() error {
+	what := fmt.Sprintf("%q", p.tok.value)
+	if p.tok.kind == tokenEOF {
+		what = "<EOF>"
+	}
+	return &Error{Message: "Syntax Error: Unexpected " + what + ".", Locations: []Location{p.tok.loc}}
+}
+
+// expect consumes the given punctuator
+func (p *parser) expect(value string) error {
+	if !p.peek(tokenPunct, value) {
+		if p.tok.kind == tokenEOF {
+			return &Error{Message: fmt.Sprintf("Syntax Error: Expected %q, found <EOF>.", value), Locations: []Location{p.tok.loc}}
+		}
+		return &Error{Message: fmt.Sprintf("Syntax Error: Expected %q, found %q.", value, p.tok.value), Locations: []Location{p.tok.loc}}
+	}
+	return p.advance()
+}
+
+// skip consumes the given punctuator if present
+func (p *parser) skip(value string) (bool, error) {
+	if !p.peek(tokenPunct, value) {
+		return false, nil
+	}
+	return true, p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) operation() (*operation, error) {
+	op := &operation{kind: p.tok.value, loc: p.tok.loc}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if ok, err := p.skip("("); err != nil {
+		return nil, err
+	} else if ok {
+		for !p.peek(tokenPunct, ")") {
+			v, err := p.varDef()
+			if err != nil {
+				return nil, err
+			}
+			op.vars = append(op.vars, v)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.sel = sel
+	return op, nil
+}
+
+func (p *parser) varDef() (*varDef, error) {
+	v := &varDef{loc: p.tok.loc}
+	if err := p.expect("$"); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	v.name = name
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if v.typ, err = p.typeRef(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip("="); err != nil {
+		return nil, err
+	} else if ok {
+		if v.def, err = p.value(true); err != nil {
+			return nil, err
+		}
+		v.hasDefault = true
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (p *parser) typeRef() (*typeRef, error) {
+	t := &typeRef{}
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		inner, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		t.list = inner
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t.name = name
+	}
+	ok, err := p.skip("!")
+	t.nonNull = ok
+	return t, err
+}
+
+func (p *parser) fragment() (*fragmentDef, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, &Error{Message: "Syntax Error: Unexpected Name \"on\".", Locations: []Location{p.tok.loc}}
+	}
+	if !p.peek(tokenName, "on") {
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	typeCond, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	sel, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragmentDef{name: name, typeCond: typeCond, sel: sel}, nil
+}
+
+func (p *parser) selectionSet() ([]selection, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxSelectionDepth {
+		return nil, &Error{Message: fmt.Sprintf("Query is nested deeper than %d levels.", maxSelectionDepth), Locations: []Location{p.tok.loc}}
+	}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var sel []selection
+	for !p.peek(tokenPunct, "}") {
+		if p.tok.kind == tokenEOF {
+			return nil, p.unexpected()
+		}
+		s, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, s)
+	}
+	if len(sel) == 0 {
+		return nil, &Error{Message: "Syntax Error: Expected Name, found \"}\".", Locations: []Location{p.tok.loc}}
+	}
+	return sel, p.advance()
+}
+
+func (p *parser) selection() (selection, error) {
+	loc := p.tok.loc
+	if ok, err := p.skip("..."); err != nil {
+		return nil, err
+	} else if ok {
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			spread := &fragmentSpread{name: p.tok.value, loc: loc}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			dirs, err := p.directives()
+			spread.directives = dirs
+			return spread, err
+		}
+		inline := &inlineFragment{}
+		if p.peek(tokenName, "on") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			typeCond, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			inline.typeCond = typeCond
+		}
+		dirs, err := p.directives()
+		if err != nil {
+			return nil, err
+		}
+		inline.directives = dirs
+		if inline.sel, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+		return inline, nil
+	}
+
+	f := &field{loc: loc}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.alias = name
+		if name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	f.name = name
+	if f.args, err = p.arguments(false); err != nil {
+		return nil, err
+	}
+	if f.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.peek(tokenPunct, "{") {
+		if f.sel, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+func (p *parser) arguments(constant bool) ([]*argument, error) {
+	if ok, err := p.skip("("); err != nil || !ok {
+		return nil, err
+	}
+	var args []*argument
+	for !p.peek(tokenPunct, ")") {
+		arg := &argument{loc: p.tok.loc}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		arg.name = name
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.val, err = p.value(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	if len(args) == 0 {
+		return nil, p.unexpected()
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]*directive, error) {
+	var dirs []*directive
+	for p.peek(tokenPunct, "@") {
+		d := &directive{loc: p.tok.loc}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		d.name = name
+		if d.args, err = p.arguments(false); err != nil {
+			return nil, err
+		}
+		dirs = append(dirs, d)
+	}
+	return dirs, nil
+}
+
+// value parses a literal; constant values (variable defaults) cannot contain variables
+func (p *parser) value(constant bool) (interface{}, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		n, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			return nil, &Error{Message: fmt.Sprintf("Int cannot represent value %s.", tok.value), Locations: []Location{tok.loc}}
+		}
+		return n, p.advance()
+	case tokenFloat:
+		f, _ := strconv.ParseFloat(tok.value, 64)
+		return f, p.advance()
+	case tokenString:
+		return tok.value, p.advance()
+	case tokenName:
+		var v interface{}
+		switch tok.value {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = enumValue(tok.value)
+		}
+		return v, p.advance()
+	case tokenPunct:
+		switch tok.value {
+		case "$":
+			if constant {
+				return nil, p.unexpected()
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			return variable(name), err
+		case "[":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			list := []interface{}{}
+			for !p.peek(tokenPunct, "]") {
+				v, err := p.value(constant)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			return list, p.advance()
+		case "{":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			obj := map[string]interface{}{}
+			for !p.peek(tokenPunct, "}") {
+				name, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(":"); err != nil {
+					return nil, err
+				}
+				if obj[name], err = p.value(constant); err != nil {
+					return nil, err
+				}
+			}
+			return obj, p.advance()
+		}
+	}
+	return nil, p.unexpected()
+}
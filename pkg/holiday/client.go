@@ -1,9 +1,8 @@
 package holiday
 
 import (
-	"encoding/json"
 	"fmt"
-	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,6 +10,10 @@ import (
 	"go.uber.org/zap"
 )
 
+// providerCooldown is how long a failed provider is skipped while later providers answer, e.g.
+// to ride out the rate limit of a free API
+const providerCooldown = 10 * time.Minute
+
 // StatutoryHoliday represents a statutory holiday with vacation days
 type StatutoryHoliday struct {
 	Name        string    `json:"name"`
@@ -20,13 +23,28 @@ type StatutoryHoliday struct {
 	IsHoliday   bool      `json:"holiday"`
 }
 
-// Client is a Holiday API client
+// Provider is a source of mainland China statutory holidays
+type Provider interface {
+	// Name identifies the provider in logs
+	Name() string
+	// NextHoliday returns the next statutory holiday from a date
+	NextHoliday(date time.Time) (*StatutoryHoliday, error)
+	// YearHolidays returns every day off of the statutory holidays of a year
+	YearHolidays(year int) ([]StatutoryHoliday, error)
+	// IsWorkday reports whether a date is a working day, taking make-up working days (补班) and
+	// days off in lieu (调休) into account
+	IsWorkday(date time.Time) (bool, error)
+}
+
+// Client queries holiday providers in order, failing over to the next one when a provider errors
+// (rate limits, outages, unexpected responses) and caching the answers
 type Client struct {
-	baseURL    string
-	httpClient *http.Client
-	cache      map[string]*cacheEntry
-	cacheMu    sync.RWMutex
-	cacheTTL   time.Duration
+	providers []Provider
+	failedAt  map[string]time.Time // Provider name -> last failure
+	failedMu  sync.Mutex
+	cache     map[string]*cacheEntry
+	cacheMu   sync.RWMutex
+	cacheTTL  time.Duration
 }
 
 type cacheEntry struct {
@@ -34,57 +52,69 @@ type cacheEntry struct {
 	expiresAt time.Time
 }
 
-// APIResponse represents the API response structure
-type APIResponse struct {
-	Code    int              `json:"code"`
-	Holiday *HolidayData     `json:"holiday"`
-	Type    *HolidayTypeData `json:"type"`
-}
-
-// HolidayData represents holiday information from the API
-type HolidayData struct {
-	Holiday bool   `json:"holiday"`
-	Name    string `json:"name"`
-	Wage    int    `json:"wage"`
-	Date    string `json:"date"`
-	Rest    int    `json:"rest"`
-	After   *int   `json:"after"`
-	Target  string `json:"target"`
-}
-
-// HolidayTypeData represents holiday type information
-type HolidayTypeData struct {
-	Type int    `json:"type"` // 0=工作日, 1=周末, 2=节日, 3=调休放假, 4=补班
-	Name string `json:"name"`
-	Week int    `json:"week"`
-}
-
-// NextHolidayResponse represents the response for next holiday API
-type NextHolidayResponse struct {
-	Code    int          `json:"code"`
-	Holiday *HolidayData `json:"holiday"`
-	Workday *HolidayData `json:"workday"`
-}
-
-// YearHolidaysResponse represents the response for year holidays API
-type YearHolidaysResponse struct {
-	Code    int                     `json:"code"`
-	Holiday map[string]*HolidayData `json:"holiday"`
+// NewClient creates a new Holiday API client for a timor.tech-compatible API
+func NewClient(baseURL string, cacheTTL time.Duration) *Client {
+	return NewChain(cacheTTL, NewTimorProvider(baseURL))
 }
 
-// NewClient creates a new Holiday API client
-func NewClient(baseURL string, cacheTTL time.Duration) *Client {
+// NewChain creates a new client querying the providers in order
+func NewChain(cacheTTL time.Duration, providers ...Provider) *Client {
 	if cacheTTL == 0 {
 		cacheTTL = 24 * time.Hour
 	}
 	return &Client{
-		baseURL:    baseURL,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
-		cache:      make(map[string]*cacheEntry),
-		cacheTTL:   cacheTTL,
+		providers: providers,
+		failedAt:  make(map[string]time.Time),
+		cache:     make(map[string]*cacheEntry),
+		cacheTTL:  cacheTTL,
 	}
 }
 
+// query calls fn with each provider in turn until one succeeds. Providers that failed within
+// providerCooldown are tried last.
+func (c *Client) query(op string, fn func(p Provider) error) error {
+	now := time.Now()
+	var ready, cooling []Provider
+	c.failedMu.Lock()
+	for _, p := range c.providers {
+		if failedAt, ok := c.failedAt[p.Name()]; ok && now.Sub(failedAt) < providerCooldown {
+			cooling = append(cooling, p)
+		} else {
+			ready = append(ready, p)
+		}
+	}
+	c.failedMu.Unlock()
+
+	var errs []string
+	var lastErr error
+	for _, p := range append(ready, cooling...) {
+		err := fn(p)
+		c.failedMu.Lock()
+		if err == nil {
+			delete(c.failedAt, p.Name())
+		} else {
+			c.failedAt[p.Name()] = time.Now()
+		}
+		c.failedMu.Unlock()
+		if err == nil {
+			return nil
+		}
+		logger.Warn("Holiday provider failed",
+			zap.String("provider", p.Name()),
+			zap.String("op", op),
+			zap.Error(err))
+		errs = append(errs, fmt.Sprintf("%s: %v", p.Name(), err))
+		lastErr = err
+	}
+	switch len(errs) {
+	case 0:
+		return fmt.Errorf("no holiday provider configured")
+	case 1:
+		return lastErr
+	}
+	return fmt.Errorf("all holiday providers failed: %s", strings.Join(errs, "; "))
+}
+
 // GetNextHoliday retrieves the next statutory holiday from a given date
 func (c *Client) GetNextHoliday(date time.Time) (*StatutoryHoliday, error) {
 	dateStr := date.Format("2006-01-02")
@@ -102,47 +132,14 @@ func (c *Client) GetNextHoliday(date time.Time) (*StatutoryHoliday, error) {
 		}
 	}
 
-	url := fmt.Sprintf("%s/api/holiday/next/%s", c.baseURL, dateStr)
-	logger.Debug("Sending HTTP request",
-		zap.String("url", url),
-		zap.String("method", "GET"))
-
-	resp, err := c.httpClient.Get(url)
+	var holiday *StatutoryHoliday
+	err := c.query("next", func(p Provider) error {
+		var err error
+		holiday, err = p.NextHoliday(date)
+		return err
+	})
 	if err != nil {
-		logger.Error("HTTP request failed",
-			zap.String("url", url),
-			zap.Error(err),
-			zap.Duration("duration", time.Since(start)))
-		return nil, fmt.Errorf("failed to get next holiday: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	logger.Debug("HTTP response received",
-		zap.Int("status_code", resp.StatusCode),
-		zap.Duration("duration", time.Since(start)))
-
-	var apiResp NextHolidayResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		logger.Error("Failed to decode response",
-			zap.Error(err))
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	logger.Debug("Holiday API response",
-		zap.Int("code", apiResp.Code))
-
-	if apiResp.Code != 0 || apiResp.Holiday == nil {
-		logger.Warn("Holiday API error",
-			zap.Int("api_code", apiResp.Code))
-		return nil, fmt.Errorf("API returned error code: %d", apiResp.Code)
-	}
-
-	holidayDate, _ := time.Parse("2006-01-02", apiResp.Holiday.Date)
-	holiday := &StatutoryHoliday{
-		Name:      apiResp.Holiday.Name,
-		Date:      holidayDate,
-		DaysUntil: apiResp.Holiday.Rest,
-		IsHoliday: apiResp.Holiday.Holiday,
+		return nil, err
 	}
 
 	// Cache the result
@@ -171,53 +168,14 @@ func (c *Client) GetYearHolidays(year int) ([]StatutoryHoliday, error) {
 		}
 	}
 
-	url := fmt.Sprintf("%s/api/holiday/year/%d", c.baseURL, year)
-	logger.Debug("Sending HTTP request",
-		zap.String("url", url),
-		zap.String("method", "GET"))
-
-	resp, err := c.httpClient.Get(url)
-	if err != nil {
-		logger.Error("HTTP request failed",
-			zap.String("url", url),
-			zap.Error(err),
-			zap.Duration("duration", time.Since(start)))
-		return nil, fmt.Errorf("failed to get year holidays: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	logger.Debug("HTTP response received",
-		zap.Int("status_code", resp.StatusCode),
-		zap.Duration("duration", time.Since(start)))
-
-	var apiResp YearHolidaysResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		logger.Error("Failed to decode response",
-			zap.Error(err))
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	logger.Debug("Holiday API response",
-		zap.Int("code", apiResp.Code))
-
-	if apiResp.Code != 0 {
-		logger.Warn("Holiday API error",
-			zap.Int("api_code", apiResp.Code))
-		return nil, fmt.Errorf("API returned error code: %d", apiResp.Code)
-	}
-
 	var holidays []StatutoryHoliday
-	for _, h := range apiResp.Holiday {
-		if h == nil || !h.Holiday {
-			continue
-		}
-		holidayDate, _ := time.Parse("2006-01-02", h.Date)
-		holidays = append(holidays, StatutoryHoliday{
-			Name:      h.Name,
-			Date:      holidayDate,
-			DaysUntil: h.Rest,
-			IsHoliday: h.Holiday,
-		})
+	err := c.query("year", func(p Provider) error {
+		var err error
+		holidays, err = p.YearHolidays(year)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Cache the result
@@ -230,51 +188,27 @@ func (c *Client) GetYearHolidays(year int) ([]StatutoryHoliday, error) {
 	return holidays, nil
 }
 
-// GetDateInfo retrieves holiday information for a specific date
-func (c *Client) GetDateInfo(date time.Time) (*HolidayData, *HolidayTypeData, error) {
-	dateStr := date.Format("2006-01-02")
-	logger.Debug("Holiday.GetDateInfo called", zap.String("date", dateStr))
-	start := time.Now()
-
-	url := fmt.Sprintf("%s/api/holiday/info/%s", c.baseURL, dateStr)
-	logger.Debug("Sending HTTP request",
-		zap.String("url", url),
-		zap.String("method", "GET"))
-
-	resp, err := c.httpClient.Get(url)
-	if err != nil {
-		logger.Error("HTTP request failed",
-			zap.String("url", url),
-			zap.Error(err),
-			zap.Duration("duration", time.Since(start)))
-		return nil, nil, fmt.Errorf("failed to get date info: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	logger.Debug("HTTP response received",
-		zap.Int("status_code", resp.StatusCode),
-		zap.Duration("duration", time.Since(start)))
-
-	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		logger.Error("Failed to decode response",
-			zap.Error(err))
-		return nil, nil, fmt.Errorf("failed to decode response: %w", err)
+// IsWorkday reports whether a date is a working day in mainland China, taking make-up working
+// days (补班) and days off in lieu (调休) into account
+func (c *Client) IsWorkday(date time.Time) (bool, error) {
+	cacheKey := fmt.Sprintf("workday_%s", date.Format("2006-01-02"))
+	if cached := c.getFromCache(cacheKey); cached != nil {
+		if workday, ok := cached.(bool); ok {
+			return workday, nil
+		}
 	}
 
-	logger.Debug("Holiday API response",
-		zap.Int("code", apiResp.Code))
-
-	if apiResp.Code != 0 {
-		logger.Warn("Holiday API error",
-			zap.Int("api_code", apiResp.Code))
-		return nil, nil, fmt.Errorf("API returned error code: %d", apiResp.Code)
+	var workday bool
+	err := c.query("workday", func(p Provider) error {
+		var err error
+		workday, err = p.IsWorkday(date)
+		return err
+	})
+	if err != nil {
+		return false, err
 	}
-
-	logger.Debug("Date info retrieved",
-		zap.String("date", dateStr),
-		zap.Duration("duration", time.Since(start)))
-	return apiResp.Holiday, apiResp.Type, nil
+	c.setCache(cacheKey, workday)
+	return workday, nil
 }
 
 func (c *Client) getFromCache(key string) interface{} {
@@ -297,3 +231,33 @@ func (c *Client) setCache(key string, data interface{}) {
 		expiresAt: time.Now().Add(c.cacheTTL),
 	}
 }
+
+// dateLayouts are the date formats accepted in provider responses
+var dateLayouts = []string{"2006-01-02", "2006/01/02", "20060102", time.RFC3339}
+
+// parseDate parses a date of a provider response; dates without a year ("01-02", "0102") are
+// taken in year
+func parseDate(s string, year int) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return dayOf(t), true
+		}
+	}
+	for _, layout := range []string{"01-02", "0102", "1-2"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return time.Date(year, t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// dayOf returns the calendar day of t at midnight UTC, the way dates of the providers are parsed
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of calendar days from a to b
+func daysBetween(a, b time.Time) int {
+	return int(dayOf(b).Sub(dayOf(a)).Hours() / 24)
+}
@@ -0,0 +1,217 @@
+package holiday
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// datasetTTL is how long a downloaded year of the dataset is reused
+const datasetTTL = 24 * time.Hour
+
+// DatasetDay is a day of a holiday dataset: a day off of a statutory holiday, or a make-up
+// working day (补班) when IsOffDay is false
+type DatasetDay struct {
+	Name     string `json:"name"`
+	Date     string `json:"date"`
+	IsOffDay bool   `json:"isOffDay"`
+}
+
+// datasetYear is a downloaded year of the dataset
+type datasetYear struct {
+	days      map[string]DatasetDay // Date (YYYY-MM-DD) -> day
+	fetchedAt time.Time
+}
+
+// DatasetProvider reads a static JSON dataset with one file per year, such as holiday-cn
+// (https://github.com/NateScarlet/holiday-cn). The URL contains "{year}"; a file is either
+// {"days": [...]}, a bare array of days, or an object keyed by date, each day carrying name, date
+// and isOffDay.
+type DatasetProvider struct {
+	urlTemplate string
+	httpClient  *http.Client
+	mu          sync.Mutex
+	years       map[int]*datasetYear
+}
+
+// NewDatasetProvider creates a new DatasetProvider
+func NewDatasetProvider(urlTemplate string) *DatasetProvider {
+	return &DatasetProvider{
+		urlTemplate: urlTemplate,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+		years:       make(map[int]*datasetYear),
+	}
+}
+
+// Name implements Provider
+func (p *DatasetProvider) Name() string {
+	return "dataset"
+}
+
+// year returns the days of a year, downloading its file when it is not cached
+func (p *DatasetProvider) year(year int) (map[string]DatasetDay, error) {
+	p.mu.Lock()
+	cached, ok := p.years[year]
+	p.mu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < datasetTTL {
+		return cached.days, nil
+	}
+
+	url := strings.ReplaceAll(p.urlTemplate, "{year}", strconv.Itoa(year))
+	logger.Debug("Sending HTTP request",
+		zap.String("url", url),
+		zap.String("method", "GET"))
+	start := time.Now()
+
+	resp, err := p.httpClient.Get(url)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", url),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get holiday dataset: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	logger.Debug("HTTP response received",
+		zap.Int("status_code", resp.StatusCode),
+		zap.Duration("duration", time.Since(start)))
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("holiday dataset of %d returned HTTP %d", year, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read holiday dataset: %w", err)
+	}
+	list, err := decodeDataset(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode holiday dataset of %d: %w", year, err)
+	}
+
+	days := make(map[string]DatasetDay, len(list))
+	for _, day := range list {
+		date, ok := parseDate(day.Date, year)
+		if !ok {
+			continue
+		}
+		day.Date = date.Format("2006-01-02")
+		days[day.Date] = day
+	}
+	if len(days) == 0 {
+		return nil, fmt.Errorf("holiday dataset of %d has no days", year)
+	}
+
+	p.mu.Lock()
+	p.years[year] = &datasetYear{days: days, fetchedAt: time.Now()}
+	p.mu.Unlock()
+	logger.Debug("Holiday dataset loaded",
+		zap.Int("year", year),
+		zap.Int("days", len(days)))
+	return days, nil
+}
+
+// decodeDataset decodes the days of a dataset file in any of the supported shapes
+func decodeDataset(body []byte) ([]DatasetDay, error) {
+	var wrapped struct {
+		Days []DatasetDay `json:"days"`
+	}
+	if err := json.Unmarshal(body, &wrapped); err == nil && len(wrapped.Days) > 0 {
+		return wrapped.Days, nil
+	}
+	var list []DatasetDay
+	if err := json.Unmarshal(body, &list); err == nil {
+		return list, nil
+	}
+	var byDate map[string]DatasetDay
+	if err := json.Unmarshal(body, &byDate); err != nil {
+		return nil, err
+	}
+	for date, day := range byDate {
+		if day.Date == "" {
+			day.Date = date
+		}
+		list = append(list, day)
+	}
+	return list, nil
+}
+
+// offDays returns the days off of a year in date order, with the length of the holiday each
+// belongs to
+func (p *DatasetProvider) offDays(year int) ([]StatutoryHoliday, error) {
+	days, err := p.year(year)
+	if err != nil {
+		return nil, err
+	}
+	var holidays []StatutoryHoliday
+	for _, day := range days {
+		if !day.IsOffDay {
+			continue
+		}
+		date, _ := time.Parse("2006-01-02", day.Date)
+		holidays = append(holidays, StatutoryHoliday{Name: day.Name, Date: date, IsHoliday: true})
+	}
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date.Before(holidays[j].Date) })
+
+	// Consecutive days off with the same name form one holiday
+	for start := 0; start < len(holidays); {
+		end := start + 1
+		for end < len(holidays) && holidays[end].Name == holidays[start].Name &&
+			daysBetween(holidays[end-1].Date, holidays[end].Date) == 1 {
+			end++
+		}
+		for i := start; i < end; i++ {
+			holidays[i].HolidayDays = end - start
+		}
+		start = end
+	}
+	return holidays, nil
+}
+
+// NextHoliday implements Provider. It returns the first day of the next holiday starting on or
+// after date, looking into the following year when needed.
+func (p *DatasetProvider) NextHoliday(date time.Time) (*StatutoryHoliday, error) {
+	day := dayOf(date)
+	for year := date.Year(); year <= date.Year()+1; year++ {
+		holidays, err := p.offDays(year)
+		if err != nil {
+			return nil, err
+		}
+		for i, h := range holidays {
+			first := i == 0 || holidays[i-1].Name != h.Name || daysBetween(holidays[i-1].Date, h.Date) != 1
+			if first && !h.Date.Before(day) {
+				h.DaysUntil = daysBetween(day, h.Date)
+				return &h, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no holiday after %s in the dataset", date.Format("2006-01-02"))
+}
+
+// YearHolidays implements Provider
+func (p *DatasetProvider) YearHolidays(year int) ([]StatutoryHoliday, error) {
+	return p.offDays(year)
+}
+
+// IsWorkday implements Provider. Days missing from the dataset are working days from Monday to
+// Friday.
+func (p *DatasetProvider) IsWorkday(date time.Time) (bool, error) {
+	days, err := p.year(date.Year())
+	if err != nil {
+		return false, err
+	}
+	if day, ok := days[date.Format("2006-01-02")]; ok {
+		return !day.IsOffDay, nil
+	}
+	return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday, nil
+}
@@ -0,0 +1,232 @@
+package holiday
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// APIResponse represents the API response structure
+type APIResponse struct {
+	Code    int              `json:"code"`
+	Holiday *HolidayData     `json:"holiday"`
+	Type    *HolidayTypeData `json:"type"`
+}
+
+// HolidayData represents holiday information from the API
+type HolidayData struct {
+	Holiday bool   `json:"holiday"`
+	Name    string `json:"name"`
+	Wage    int    `json:"wage"`
+	Date    string `json:"date"`
+	Rest    int    `json:"rest"`
+	After   *int   `json:"after"`
+	Target  string `json:"target"`
+}
+
+// HolidayTypeData represents holiday type information
+type HolidayTypeData struct {
+	Type int    `json:"type"` // 0=工作日, 1=周末, 2=节日, 3=调休放假, 4=补班
+	Name string `json:"name"`
+	Week int    `json:"week"`
+}
+
+// NextHolidayResponse represents the response for next holiday API
+type NextHolidayResponse struct {
+	Code    int          `json:"code"`
+	Holiday *HolidayData `json:"holiday"`
+	Workday *HolidayData `json:"workday"`
+}
+
+// YearHolidaysResponse represents the response for year holidays API. Holiday is either an
+// object keyed by "MM-DD" or an array of days, depending on the API version.
+type YearHolidaysResponse struct {
+	Code    int             `json:"code"`
+	Holiday json.RawMessage `json:"holiday"`
+}
+
+// days decodes the days of the year, in date order
+func (r *YearHolidaysResponse) days() ([]*HolidayData, error) {
+	if len(r.Holiday) == 0 || string(r.Holiday) == "null" {
+		return nil, nil
+	}
+	var list []*HolidayData
+	if err := json.Unmarshal(r.Holiday, &list); err == nil {
+		return list, nil
+	}
+	var byDay map[string]*HolidayData
+	if err := json.Unmarshal(r.Holiday, &byDay); err != nil {
+		return nil, fmt.Errorf("unexpected holiday list: %w", err)
+	}
+	keys := make([]string, 0, len(byDay))
+	for key := range byDay {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		list = append(list, byDay[key])
+	}
+	return list, nil
+}
+
+// TimorProvider queries a timor.tech-compatible holiday API (/api/holiday/next, year and info)
+type TimorProvider struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewTimorProvider creates a new TimorProvider
+func NewTimorProvider(baseURL string) *TimorProvider {
+	return &TimorProvider{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name implements Provider
+func (p *TimorProvider) Name() string {
+	return "api"
+}
+
+// get fetches a path of the API and decodes its JSON response into out, failing on HTTP errors
+// (e.g. 429 when rate-limited) and responses that are not JSON
+func (p *TimorProvider) get(path string, out any) error {
+	url := p.baseURL + path
+	logger.Debug("Sending HTTP request",
+		zap.String("url", url),
+		zap.String("method", "GET"))
+	start := time.Now()
+
+	resp, err := p.httpClient.Get(url)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", url),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	logger.Debug("HTTP response received",
+		zap.Int("status_code", resp.StatusCode),
+		zap.Duration("duration", time.Since(start)))
+
+	if resp.StatusCode != http.StatusOK {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("API returned HTTP %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// NextHoliday implements Provider
+func (p *TimorProvider) NextHoliday(date time.Time) (*StatutoryHoliday, error) {
+	var apiResp NextHolidayResponse
+	if err := p.get("/api/holiday/next/"+date.Format("2006-01-02"), &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to get next holiday: %w", err)
+	}
+	logger.Debug("Holiday API response",
+		zap.Int("code", apiResp.Code))
+
+	if apiResp.Code != 0 || apiResp.Holiday == nil {
+		logger.Warn("Holiday API error",
+			zap.Int("api_code", apiResp.Code))
+		return nil, fmt.Errorf("API returned error code: %d", apiResp.Code)
+	}
+
+	holiday := &StatutoryHoliday{
+		Name:      apiResp.Holiday.Name,
+		DaysUntil: apiResp.Holiday.Rest,
+		IsHoliday: apiResp.Holiday.Holiday,
+	}
+	if holidayDate, ok := parseDate(apiResp.Holiday.Date, date.Year()); ok {
+		if holidayDate.Before(dayOf(date)) {
+			holidayDate = holidayDate.AddDate(1, 0, 0)
+		}
+		holiday.Date = holidayDate
+		if holiday.DaysUntil == 0 {
+			holiday.DaysUntil = daysBetween(date, holidayDate)
+		}
+	}
+	return holiday, nil
+}
+
+// YearHolidays implements Provider
+func (p *TimorProvider) YearHolidays(year int) ([]StatutoryHoliday, error) {
+	var apiResp YearHolidaysResponse
+	if err := p.get(fmt.Sprintf("/api/holiday/year/%d", year), &apiResp); err != nil {
+		return nil, fmt.Errorf("failed to get year holidays: %w", err)
+	}
+	logger.Debug("Holiday API response",
+		zap.Int("code", apiResp.Code))
+
+	if apiResp.Code != 0 {
+		logger.Warn("Holiday API error",
+			zap.Int("api_code", apiResp.Code))
+		return nil, fmt.Errorf("API returned error code: %d", apiResp.Code)
+	}
+	days, err := apiResp.days()
+	if err != nil {
+		return nil, err
+	}
+
+	var holidays []StatutoryHoliday
+	for _, h := range days {
+		if h == nil || !h.Holiday {
+			continue
+		}
+		holidayDate, ok := parseDate(h.Date, year)
+		if !ok {
+			logger.Debug("Skipping holiday with unknown date",
+				zap.String("name", h.Name),
+				zap.String("date", h.Date))
+			continue
+		}
+		holidays = append(holidays, StatutoryHoliday{
+			Name:      h.Name,
+			Date:      holidayDate,
+			DaysUntil: h.Rest,
+			IsHoliday: h.Holiday,
+		})
+	}
+	return holidays, nil
+}
+
+// DateInfo retrieves holiday information for a specific date
+func (p *TimorProvider) DateInfo(date time.Time) (*HolidayData, *HolidayTypeData, error) {
+	var apiResp APIResponse
+	if err := p.get("/api/holiday/info/"+date.Format("2006-01-02"), &apiResp); err != nil {
+		return nil, nil, fmt.Errorf("failed to get date info: %w", err)
+	}
+	logger.Debug("Holiday API response",
+		zap.Int("code", apiResp.Code))
+
+	if apiResp.Code != 0 {
+		logger.Warn("Holiday API error",
+			zap.Int("api_code", apiResp.Code))
+		return nil, nil, fmt.Errorf("API returned error code: %d", apiResp.Code)
+	}
+	return apiResp.Holiday, apiResp.Type, nil
+}
+
+// IsWorkday implements Provider
+func (p *TimorProvider) IsWorkday(date time.Time) (bool, error) {
+	_, dayType, err := p.DateInfo(date)
+	if err != nil {
+		return false, err
+	}
+	if dayType == nil {
+		return false, fmt.Errorf("no day type for %s", date.Format("2006-01-02"))
+	}
+	return dayType.Type == 0 || dayType.Type == 4, nil
+}
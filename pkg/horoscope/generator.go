@@ -0,0 +1,84 @@
+package horoscope
+
+import (
+	"context"
+	"math/rand"
+)
+
+// luckyColors are the colors a reading may suggest
+var luckyColors = []string{"红色", "橙色", "黄色", "绿色", "青色", "蓝色", "紫色", "白色", "黑色", "灰色", "粉色", "金色", "银色", "米色", "棕色"}
+
+// overallTexts are the openings of the summary, by overall rating
+var overallTexts = [5][]string{
+	{"今天状态略显低迷，凡事放慢节奏、量力而行。", "今天容易感到疲惫，重要决定不妨往后放一放。"},
+	{"今天小波折较多，保持耐心就能平稳度过。", "今天计划容易被打乱，多留一些余地。"},
+	{"今天整体平稳，按部就班就会有收获。", "今天运势平平，专注手头的事最踏实。"},
+	{"今天状态不错，适合推进搁置已久的计划。", "今天思路清晰，容易得到身边人的支持。"},
+	{"今天运势亮眼，大胆尝试新事物吧。", "今天精力充沛，是主动争取机会的好日子。"},
+}
+
+// aspectTips are the closing tips of the summary, by aspect and whether it is the day's best
+var aspectTips = map[string][2]string{
+	"love":   {"感情上多一些倾听，少一些计较。", "感情运佳，适合和重要的人好好聊聊。"},
+	"career": {"工作中注意细节，交付前多检查一遍。", "工作上容易有好表现，不妨主动承担。"},
+	"wealth": {"花钱前多想一想，避免冲动消费。", "财运不错，可以整理一下理财计划。"},
+	"health": {"注意作息，记得多喝水、早点休息。", "身体状态好，适合安排一次运动。"},
+}
+
+// Generator is the built-in provider: it derives the ratings, lucky color, lucky number and
+// summary from a random source seeded with Seed(sign, date), so readings need no external
+// service and never change within a day
+type Generator struct{}
+
+// NewGenerator creates a Generator
+func NewGenerator() *Generator {
+	return &Generator{}
+}
+
+// Daily implements Provider
+func (g *Generator) Daily(_ context.Context, sign Sign, date string) (*Reading, error) {
+	rng := rand.New(rand.NewSource(Seed(sign, date)))
+	rating := func() int { return 1 + rng.Intn(5) }
+
+	r := &Reading{
+		Sign:    sign,
+		Date:    date,
+		Love:    rating(),
+		Career:  rating(),
+		Wealth:  rating(),
+		Health:  rating(),
+		Overall: rating(),
+	}
+	r.LuckyColor = luckyColors[rng.Intn(len(luckyColors))]
+	r.LuckyNumber = 1 + rng.Intn(9)
+
+	// The summary opens with the overall mood and closes with a tip on the best or worst aspect
+	openings := overallTexts[r.Overall-1]
+	aspect, value := weakestOrStrongest(r, r.Overall >= 3)
+	r.Summary = openings[rng.Intn(len(openings))] + aspectTips[aspect][boolIndex(value >= 4)]
+	return r, nil
+}
+
+// weakestOrStrongest returns the strongest aspect of a reading when strongest is set, the weakest otherwise
+func weakestOrStrongest(r *Reading, strongest bool) (string, int) {
+	aspects := []struct {
+		name  string
+		value int
+	}{{"love", r.Love}, {"career", r.Career}, {"wealth", r.Wealth}, {"health", r.Health}}
+
+	best := aspects[0]
+	for _, a := range aspects[1:] {
+		if (strongest && a.value > best.value) || (!strongest && a.value < best.value) {
+			best = a
+		}
+	}
+	return best.name, best.value
+}
+
+// boolIndex returns 1 for true and 0 for false
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+// Package horoscope provides daily zodiac sign readings. Readings come from a Provider; the
+// built-in Generator derives them deterministically from the sign and date, so every user of a
+// sign sees the same reading all day.
+package horoscope
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Sign is a western zodiac sign, identified by its lowercase English name
+type Sign string
+
+// The twelve zodiac signs, in calendar order starting from Aries
+const (
+	Aries       Sign = "aries"
+	Taurus      Sign = "taurus"
+	Gemini      Sign = "gemini"
+	Cancer      Sign = "cancer"
+	Leo         Sign = "leo"
+	Virgo       Sign = "virgo"
+	Libra       Sign = "libra"
+	Scorpio     Sign = "scorpio"
+	Sagittarius Sign = "sagittarius"
+	Capricorn   Sign = "capricorn"
+	Aquarius    Sign = "aquarius"
+	Pisces      Sign = "pisces"
+)
+
+// signInfo describes a sign: its Chinese name, emoji and first day (month, day)
+type signInfo struct {
+	sign       Sign
+	name       string
+	emoji      string
+	startMonth int
+	startDay   int
+}
+
+// signs lists the signs in calendar order
+var signs = []signInfo{
+	{Aries, "白羊座", "♈", 3, 21},
+	{Taurus, "金牛座", "♉", 4, 20},
+	{Gemini, "双子座", "♊", 5, 21},
+	{Cancer, "巨蟹座", "♋", 6, 22},
+	{Leo, "狮子座", "♌", 7, 23},
+	{Virgo, "处女座", "♍", 8, 23},
+	{Libra, "天秤座", "♎", 9, 23},
+	{Scorpio, "天蝎座", "♏", 10, 24},
+	{Sagittarius, "射手座", "♐", 11, 23},
+	{Capricorn, "摩羯座", "♑", 12, 22},
+	{Aquarius, "水瓶座", "♒", 1, 20},
+	{Pisces, "双鱼座", "♓", 2, 19},
+}
+
+// Signs returns all signs in calendar order starting from Aries
+func Signs() []Sign {
+	result := make([]Sign, 0, len(signs))
+	for _, s := range signs {
+		result = append(result, s.sign)
+	}
+	return result
+}
+
+// info returns the description of a sign
+func (s Sign) info() (signInfo, bool) {
+	for _, info := range signs {
+		if info.sign == s {
+			return info, true
+		}
+	}
+	return signInfo{}, false
+}
+
+// Valid reports whether s is one of the twelve signs
+func (s Sign) Valid() bool {
+	_, ok := s.info()
+	return ok
+}
+
+// Name returns the Chinese name of the sign, e.g. 白羊座
+func (s Sign) Name() string {
+	if info, ok := s.info(); ok {
+		return info.name
+	}
+	return string(s)
+}
+
+// Emoji returns the symbol of the sign, e.g. ♈
+func (s Sign) Emoji() string {
+	if info, ok := s.info(); ok {
+		return info.emoji
+	}
+	return ""
+}
+
+// daysInMonth are the days of each month, allowing 29 February
+var daysInMonth = [12]int{31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// SignOf returns the sign of a birthday
+func SignOf(month, day int) (Sign, error) {
+	if month < 1 || month > 12 || day < 1 || day > daysInMonth[month-1] {
+		return "", fmt.Errorf("invalid date %d-%d", month, day)
+	}
+	date := month*100 + day
+	result := Capricorn // 22 December to 19 January wraps around the year
+	best := 0
+	for _, info := range signs {
+		start := info.startMonth*100 + info.startDay
+		if start <= date && start > best {
+			result, best = info.sign, start
+		}
+	}
+	return result, nil
+}
+
+// birthdayPattern matches birthdays like 3-25, 03/25, 3.25 or 3月25日
+var birthdayPattern = regexp.MustCompile(`^(\d{1,2})\s*[-/.月]\s*(\d{1,2})\s*日?$`)
+
+// Parse parses a sign from its Chinese name (with or without 座), English name or a birthday
+// such as 3-25 or 3月25日
+func Parse(s string) (Sign, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	for _, info := range signs {
+		if text == string(info.sign) || text == info.name || text == strings.TrimSuffix(info.name, "座") {
+			return info.sign, nil
+		}
+	}
+	// Common alternative names
+	switch text {
+	case "天平座", "天平":
+		return Libra, nil
+	case "山羊座", "山羊", "魔羯座", "魔羯":
+		return Capricorn, nil
+	}
+
+	if m := birthdayPattern.FindStringSubmatch(text); m != nil {
+		month, _ := strconv.Atoi(m[1])
+		day, _ := strconv.Atoi(m[2])
+		return SignOf(month, day)
+	}
+	return "", fmt.Errorf("unknown zodiac sign %q", s)
+}
+
+// Reading is a sign's fortune for one day. Ratings range from 1 to 5.
+type Reading struct {
+	Sign        Sign
+	Date        string // YYYY-MM-DD
+	Overall     int
+	Love        int
+	Career      int
+	Wealth      int
+	Health      int
+	LuckyColor  string
+	LuckyNumber int
+	Summary     string // One or two sentences of advice for the day
+}
+
+// Provider returns the daily reading of a sign
+type Provider interface {
+	Daily(ctx context.Context, sign Sign, date string) (*Reading, error)
+}
+
+// Seed returns the deterministic seed of a sign's reading on a date (YYYY-MM-DD)
+func Seed(sign Sign, date string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(string(sign) + "|" + date))
+	return int64(h.Sum64() &^ (1 << 63))
+}
+
+// Stars renders a rating as five stars, e.g. ★★★☆☆
+func Stars(rating int) string {
+	rating = max(0, min(5, rating))
+	return strings.Repeat("★", rating) + strings.Repeat("☆", 5-rating)
+}
@@ -0,0 +1,60 @@
+// Package ics fetches and parses iCalendar (RFC 5545) feeds, such as school timetables and shared
+// team calendars, and lists the events of a day
+package ics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/safehttp"
+)
+
+// MaxCalendarSize limits the calendar documents read
+const MaxCalendarSize = 1 << 20
+
+// Client fetches calendars over HTTP
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient creates a new calendar client with the given request timeout. Calendar URLs come
+// from users, so loopback and private addresses are refused unless allowPrivate is set.
+func NewClient(timeout time.Duration, allowPrivate bool) *Client {
+	return &Client{httpClient: safehttp.NewClient(timeout, allowPrivate)}
+}
+
+// Fetch downloads the calendar at url, returning the raw document. webcal:// URLs are fetched
+// over https.
+func (c *Client) Fetch(ctx context.Context, url string) ([]byte, error) {
+	if rest, ok := strings.CutPrefix(url, "webcal://"); ok {
+		url = "https://" + rest
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "daily-reminder-bot")
+	req.Header.Set("Accept", "text/calendar, */*")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calendar returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxCalendarSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	if len(data) > MaxCalendarSize {
+		return nil, fmt.Errorf("calendar is larger than %d bytes", MaxCalendarSize)
+	}
+	return data, nil
+}
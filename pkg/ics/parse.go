@@ -0,0 +1,310 @@
+package ics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Calendar is a parsed iCalendar document
+type Calendar struct {
+	Name   string // X-WR-CALNAME, "" when missing
+	Events []Event
+	// Skipped holds why events were left out: their recurrence rule is invalid or outside the
+	// supported subset (ErrUnsupportedRule)
+	Skipped []error
+}
+
+// Event is a VEVENT of a calendar
+type Event struct {
+	UID      string
+	Summary  string
+	Location string
+	Start    time.Time
+	End      time.Time // Exclusive; equals Start when the event has no duration
+	AllDay   bool      // Start and End are midnights of the calendar's location
+	Rule     *Rule     // nil when the event does not recur
+	ExDates  []time.Time
+	// RecurrenceID is the original start of the occurrence of the recurring event with the same
+	// UID that this event replaces (zero for ordinary events)
+	RecurrenceID time.Time
+	Cancelled    bool // STATUS:CANCELLED
+}
+
+// windowsZones maps the Windows time zone names used by Outlook calendars to IANA names
+var windowsZones = map[string]string{
+	"China Standard Time":       "Asia/Shanghai",
+	"Taipei Standard Time":      "Asia/Taipei",
+	"Tokyo Standard Time":       "Asia/Tokyo",
+	"Korea Standard Time":       "Asia/Seoul",
+	"Singapore Standard Time":   "Asia/Singapore",
+	"UTC":                       "UTC",
+	"GMT Standard Time":         "Europe/London",
+	"W. Europe Standard Time":   "Europe/Berlin",
+	"Eastern Standard Time":     "America/New_York",
+	"Pacific Standard Time":     "America/Los_Angeles",
+	"Central Standard Time":     "America/Chicago",
+	"AUS Eastern Standard Time": "Australia/Sydney",
+}
+
+// property is a content line of a calendar: NAME;PARAM=VALUE:value
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse parses an iCalendar document. Floating times and all-day dates are in loc, as are times
+// whose TZID is unknown. Events whose RRULE cannot be expanded are skipped (Calendar.Skipped).
+func Parse(data []byte, loc *time.Location) (*Calendar, error) {
+	lines := unfold(data)
+	cal := &Calendar{}
+	found := false
+	var event *Event
+	var ruleErr error // Why the current event's RRULE cannot be expanded
+	depth := 0        // Nesting of components inside the VEVENT (VALARM)
+	for _, line := range lines {
+		prop, ok := parseLine(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCALENDAR"):
+			found = true
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT") && event == nil:
+			event = &Event{}
+			ruleErr = nil
+		case prop.name == "BEGIN" && event != nil:
+			depth++
+		case prop.name == "END" && event != nil && depth > 0:
+			depth--
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT") && event != nil:
+			if ruleErr != nil {
+				cal.Skipped = append(cal.Skipped, ruleErr)
+			} else if !event.Start.IsZero() {
+				if event.End.Before(event.Start) {
+					event.End = event.Start
+					if event.AllDay {
+						event.End = event.Start.AddDate(0, 0, 1)
+					}
+				}
+				cal.Events = append(cal.Events, *event)
+			}
+			event = nil
+		case event == nil:
+			if prop.name == "X-WR-CALNAME" {
+				cal.Name = unescape(prop.value)
+			}
+		case depth == 0:
+			if err := event.set(prop, loc); err != nil {
+				if prop.name != "RRULE" {
+					return nil, err
+				}
+				ruleErr = err
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("not an iCalendar document")
+	}
+	return cal, nil
+}
+
+// set applies a property of the event
+func (e *Event) set(prop property, loc *time.Location) error {
+	switch prop.name {
+	case "UID":
+		e.UID = prop.value
+	case "SUMMARY":
+		e.Summary = unescape(prop.value)
+	case "LOCATION":
+		e.Location = unescape(prop.value)
+	case "STATUS":
+		e.Cancelled = strings.EqualFold(prop.value, "CANCELLED")
+	case "DTSTART":
+		start, allDay, err := parseTime(prop, loc)
+		if err != nil {
+			return fmt.Errorf("invalid DTSTART: %w", err)
+		}
+		e.Start, e.AllDay = start, allDay
+		if e.End.IsZero() {
+			e.End = start
+			if allDay {
+				e.End = start.AddDate(0, 0, 1)
+			}
+		}
+	case "DTEND":
+		end, _, err := parseTime(prop, loc)
+		if err != nil {
+			return fmt.Errorf("invalid DTEND: %w", err)
+		}
+		e.End = end
+	case "DURATION":
+		if d, ok := parseDuration(prop.value); ok && !e.Start.IsZero() {
+			e.End = e.Start.Add(d)
+		}
+	case "RRULE":
+		rule, err := parseRule(prop.value, loc)
+		if err != nil {
+			return err
+		}
+		e.Rule = rule
+	case "EXDATE":
+		for _, value := range strings.Split(prop.value, ",") {
+			date, _, err := parseTime(property{params: prop.params, value: value}, loc)
+			if err == nil {
+				e.ExDates = append(e.ExDates, date)
+			}
+		}
+	case "RECURRENCE-ID":
+		id, _, err := parseTime(prop, loc)
+		if err == nil {
+			e.RecurrenceID = id
+		}
+	}
+	return nil
+}
+
+// unfold splits a document into content lines, joining folded lines
+func unfold(data []byte) []string {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), MaxCalendarSize)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseLine splits a content line into its name, parameters and value
+func parseLine(line string) (property, bool) {
+	// The value starts at the first colon outside quoted parameter values
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return property{}, false
+	}
+	parts := strings.Split(line[:colon], ";")
+	prop := property{name: strings.ToUpper(parts[0]), value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		if prop.params == nil {
+			prop.params = make(map[string]string)
+		}
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return prop, true
+}
+
+// unescape decodes the escaped characters of a TEXT value
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return strings.TrimSpace(s)
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// parseTime parses a DATE or DATE-TIME value, reporting whether it is a date. UTC times end with
+// "Z"; other times are in their TZID, or floating (in loc).
+func parseTime(prop property, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(prop.value)
+	if prop.params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, zoneOf(prop.params["TZID"], loc))
+	return t, false, err
+}
+
+// zoneOf returns the location of a TZID, or loc when it is missing or unknown
+func zoneOf(tzid string, loc *time.Location) *time.Location {
+	if tzid == "" {
+		return loc
+	}
+	if name, ok := windowsZones[tzid]; ok {
+		tzid = name
+	}
+	zone, err := time.LoadLocation(tzid)
+	if err != nil {
+		return loc
+	}
+	return zone
+}
+
+// parseDuration parses a DURATION value such as PT1H30M or P1D
+func parseDuration(value string) (time.Duration, bool) {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	if value == "" || strings.HasPrefix(value, "-") {
+		return 0, false
+	}
+	var d time.Duration
+	inTime := false
+	n := 0
+	digits := false
+	for _, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			n = n*10 + int(r-'0')
+			digits = true
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		if !digits {
+			return 0, false
+		}
+		switch {
+		case r == 'W':
+			d += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D':
+			d += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			d += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			d += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			d += time.Duration(n) * time.Second
+		default:
+			return 0, false
+		}
+		n, digits = 0, false
+	}
+	return d, !digits
+}
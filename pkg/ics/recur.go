@@ -0,0 +1,449 @@
+package ics
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPeriods bounds the periods a recurrence is expanded over, so that a rule starting long ago
+// cannot stall a lookup
+const maxPeriods = 20000
+
+// Frequencies of a recurrence rule (Rule.Freq)
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+// ErrUnsupportedRule is returned for recurrence rules using parts outside the supported subset;
+// such events are left out of the calendar (Calendar.Skipped) rather than expanded wrongly
+var ErrUnsupportedRule = errors.New("unsupported RRULE")
+
+// weekdayCodes maps the BYDAY codes to weekdays
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Rule is the supported subset of an RRULE: a daily, weekly, monthly or yearly repetition with
+// INTERVAL, COUNT, UNTIL, WKST, BYMONTH, BYMONTHDAY, BYDAY (with ordinals such as 2MO or -1FR
+// for monthly and yearly rules) and, for monthly and yearly rules, BYSETPOS
+type Rule struct {
+	Freq       string
+	Interval   int
+	Count      int       // 0 = unlimited
+	Until      time.Time // Inclusive; zero = unlimited
+	WeekStart  time.Weekday
+	ByDay      []WeekdayNum
+	ByMonthDay []int // Negative days count from the end of the month
+	ByMonth    []time.Month
+	BySetPos   []int // Negative positions count from the end of the period
+}
+
+// WeekdayNum is a BYDAY entry: every given weekday of the period, or only the Nth (from the end
+// when negative)
+type WeekdayNum struct {
+	N       int // 0 = every
+	Weekday time.Weekday
+}
+
+// Occurrence is an event happening on a day
+type Occurrence struct {
+	Summary  string
+	Location string
+	Start    time.Time
+	End      time.Time
+	AllDay   bool
+}
+
+// parseRule parses an RRULE value. Rules with parts that are not supported (BYHOUR, BYWEEKNO,
+// ...) fail with ErrUnsupportedRule.
+func parseRule(value string, loc *time.Location) (*Rule, error) {
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+		key = strings.ToUpper(key)
+		val = strings.ToUpper(val)
+		switch key {
+		case "FREQ":
+			rule.Freq = val
+		case "INTERVAL":
+			if n, err := strconv.Atoi(val); err == nil && n > 0 {
+				rule.Interval = n
+			}
+		case "COUNT":
+			if n, err := strconv.Atoi(val); err == nil && n > 0 {
+				rule.Count = n
+			}
+		case "UNTIL":
+			until, allDay, err := parseTime(property{value: val}, loc)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE UNTIL: %w", err)
+			}
+			if allDay {
+				// A date includes the whole day
+				until = until.AddDate(0, 0, 1).Add(-time.Second)
+			}
+			rule.Until = until
+		case "WKST":
+			day, ok := weekdayCodes[val]
+			if !ok {
+				return nil, fmt.Errorf("invalid RRULE WKST %q", val)
+			}
+			rule.WeekStart = day
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				i := strings.LastIndexFunc(code, func(r rune) bool { return r < 'A' || r > 'Z' }) + 1
+				day, ok := weekdayCodes[code[i:]]
+				n := 0
+				var err error
+				if i > 0 {
+					n, err = strconv.Atoi(code[:i])
+				}
+				if !ok || err != nil || n < -53 || n > 53 || (i > 0 && n == 0) {
+					return nil, fmt.Errorf("invalid RRULE BYDAY %q", code)
+				}
+				rule.ByDay = append(rule.ByDay, WeekdayNum{N: n, Weekday: day})
+			}
+		case "BYMONTHDAY":
+			days, err := parseInts(val, 31)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE BYMONTHDAY: %w", err)
+			}
+			rule.ByMonthDay = days
+		case "BYMONTH":
+			months, err := parseInts(val, 12)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE BYMONTH: %w", err)
+			}
+			for _, month := range months {
+				if month < 0 {
+					return nil, fmt.Errorf("invalid RRULE BYMONTH %d", month)
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+			}
+		case "BYSETPOS":
+			positions, err := parseInts(val, 366)
+			if err != nil {
+				return nil, fmt.Errorf("invalid RRULE BYSETPOS: %w", err)
+			}
+			rule.BySetPos = positions
+		default:
+			return nil, fmt.Errorf("%w part %s", ErrUnsupportedRule, key)
+		}
+	}
+
+	switch rule.Freq {
+	case FreqDaily, FreqWeekly:
+		for _, day := range rule.ByDay {
+			if day.N != 0 {
+				return nil, fmt.Errorf("%w: BYDAY ordinals of %s rules", ErrUnsupportedRule, rule.Freq)
+			}
+		}
+		if len(rule.BySetPos) > 0 {
+			return nil, fmt.Errorf("%w: BYSETPOS of %s rules", ErrUnsupportedRule, rule.Freq)
+		}
+		if rule.Freq == FreqWeekly && len(rule.ByMonthDay) > 0 {
+			return nil, fmt.Errorf("%w: BYMONTHDAY of WEEKLY rules", ErrUnsupportedRule)
+		}
+	case FreqMonthly, FreqYearly:
+		for _, day := range rule.ByDay {
+			if rule.Freq == FreqMonthly && (day.N < -5 || day.N > 5) {
+				return nil, fmt.Errorf("invalid RRULE BYDAY ordinal %d of a MONTHLY rule", day.N)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w frequency %q", ErrUnsupportedRule, rule.Freq)
+	}
+	return rule, nil
+}
+
+// parseInts parses a comma-separated list of non-zero integers within [-max, max]
+func parseInts(value string, max int) ([]int, error) {
+	var values []int
+	for _, field := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimPrefix(field, "+"))
+		if err != nil || n == 0 || n < -max || n > max {
+			return nil, fmt.Errorf("%q is out of range", field)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// starts calls fn with the start of every occurrence of the event in order, until one starts
+// at or after end or fn returns false
+func (e *Event) starts(end time.Time, fn func(start time.Time) bool) {
+	if e.Rule == nil {
+		if e.Start.Before(end) {
+			fn(e.Start)
+		}
+		return
+	}
+	r := e.Rule
+	count := 0
+	emit := func(t time.Time) bool {
+		if t.Before(e.Start) {
+			return true
+		}
+		if !t.Before(end) || (!r.Until.IsZero() && t.After(r.Until)) || (r.Count > 0 && count >= r.Count) {
+			return false
+		}
+		count++
+		return fn(t)
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		for _, day := range r.days(e.Start, period*r.Interval) {
+			t := time.Date(day.Year(), day.Month(), day.Day(), e.Start.Hour(), e.Start.Minute(), e.Start.Second(), 0, e.Start.Location())
+			if !emit(t) {
+				return
+			}
+		}
+	}
+}
+
+// days returns the dates (UTC midnights) of the occurrences in the nth period (day, week, month
+// or year) after the one of start, in order
+func (r *Rule) days(start time.Time, n int) []time.Time {
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	var days []time.Time
+	switch r.Freq {
+	case FreqDaily:
+		day := first.AddDate(0, 0, n)
+		if r.matchesMonth(day) && r.matchesMonthDay(day) && r.matchesWeekday(day) {
+			days = append(days, day)
+		}
+		return days
+	case FreqWeekly:
+		weekStart := first.AddDate(0, 0, 7*n-(int(start.Weekday())-int(r.WeekStart)+7)%7)
+		weekdays := []WeekdayNum{{Weekday: start.Weekday()}}
+		if len(r.ByDay) > 0 {
+			weekdays = r.ByDay
+		}
+		for _, weekday := range weekdays {
+			day := weekStart.AddDate(0, 0, (int(weekday.Weekday)-int(r.WeekStart)+7)%7)
+			if r.matchesMonth(day) {
+				days = append(days, day)
+			}
+		}
+	case FreqMonthly:
+		month := time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, time.UTC)
+		if !r.matchesMonth(month) {
+			return nil
+		}
+		days = r.monthDays(month, start.Day())
+	case FreqYearly:
+		year := start.Year() + n
+		switch {
+		case len(r.ByMonth) > 0:
+			for _, m := range r.ByMonth {
+				days = append(days, r.monthDays(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC), start.Day())...)
+			}
+		case len(r.ByMonthDay) > 0:
+			for m := time.January; m <= time.December; m++ {
+				days = append(days, r.monthDays(time.Date(year, m, 1, 0, 0, 0, 0, time.UTC), start.Day())...)
+			}
+		case len(r.ByDay) > 0:
+			// Ordinals count the weekdays of the whole year
+			days = weekdaysOf(r.ByDay, time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(year+1, 1, 1, 0, 0, 0, 0, time.UTC))
+		default:
+			days = r.monthDays(time.Date(year, start.Month(), 1, 0, 0, 0, 0, time.UTC), start.Day())
+		}
+	}
+	days = sortedUnique(days)
+	if len(r.BySetPos) == 0 {
+		return days
+	}
+	var selected []time.Time
+	for _, pos := range r.BySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(days) + pos
+		}
+		if i >= 0 && i < len(days) {
+			selected = append(selected, days[i])
+		}
+	}
+	return sortedUnique(selected)
+}
+
+// monthDays returns the days of the month starting at month given by BYMONTHDAY and BYDAY (both
+// when both are set), or the day of the month of the event's start (none in months without it)
+func (r *Rule) monthDays(month time.Time, startDay int) []time.Time {
+	next := month.AddDate(0, 1, 0)
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		day := month.AddDate(0, 0, startDay-1)
+		if day.Before(next) {
+			return []time.Time{day}
+		}
+		return nil
+	}
+	if len(r.ByDay) > 0 {
+		var days []time.Time
+		for _, day := range weekdaysOf(r.ByDay, month, next) {
+			if r.matchesMonthDay(day) {
+				days = append(days, day)
+			}
+		}
+		return days
+	}
+	var days []time.Time
+	for _, d := range r.ByMonthDay {
+		day := month.AddDate(0, 0, d-1)
+		if d < 0 {
+			day = next.AddDate(0, 0, d)
+		}
+		if !day.Before(month) && day.Before(next) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// weekdaysOf returns the days in [from, to) given by BYDAY entries, whose ordinals count within
+// the range
+func weekdaysOf(byDay []WeekdayNum, from, to time.Time) []time.Time {
+	var days []time.Time
+	for _, weekday := range byDay {
+		var matches []time.Time
+		for day := from.AddDate(0, 0, (int(weekday.Weekday)-int(from.Weekday())+7)%7); day.Before(to); day = day.AddDate(0, 0, 7) {
+			matches = append(matches, day)
+		}
+		switch {
+		case weekday.N == 0:
+			days = append(days, matches...)
+		case weekday.N > 0 && weekday.N <= len(matches):
+			days = append(days, matches[weekday.N-1])
+		case weekday.N < 0 && -weekday.N <= len(matches):
+			days = append(days, matches[len(matches)+weekday.N])
+		}
+	}
+	return days
+}
+
+// matchesMonth reports whether a day is in a BYMONTH month (any month without BYMONTH)
+func (r *Rule) matchesMonth(day time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if day.Month() == m {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay reports whether a day is a BYMONTHDAY day (any day without BYMONTHDAY)
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, d := range r.ByMonthDay {
+		if day.Day() == d || day.Day() == last+1+d {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday reports whether a day is a BYDAY weekday (any day without BYDAY)
+func (r *Rule) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if day.Weekday() == weekday.Weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedUnique sorts days and removes duplicates
+func sortedUnique(days []time.Time) []time.Time {
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	unique := days[:0]
+	for i, day := range days {
+		if i == 0 || !day.Equal(days[i-1]) {
+			unique = append(unique, day)
+		}
+	}
+	return unique
+}
+
+// EventsOn returns the occurrences of the calendar's events overlapping the day of date (in
+// date's location): all-day events first, then by start time
+func (c *Calendar) EventsOn(date time.Time) []Occurrence {
+	dayStart := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	// Occurrences replaced by a RECURRENCE-ID event, keyed by UID
+	replaced := make(map[string][]time.Time)
+	for _, e := range c.Events {
+		if !e.RecurrenceID.IsZero() {
+			replaced[e.UID] = append(replaced[e.UID], e.RecurrenceID)
+		}
+	}
+
+	var occurrences []Occurrence
+	for i := range c.Events {
+		e := &c.Events[i]
+		if e.Cancelled {
+			continue
+		}
+		duration := e.End.Sub(e.Start)
+		e.starts(dayEnd, func(start time.Time) bool {
+			end := start.Add(duration)
+			if e.AllDay {
+				// Whole days keep their length across DST changes
+				end = start.AddDate(0, 0, int(duration.Round(24*time.Hour)/(24*time.Hour)))
+			}
+			overlaps := end.After(dayStart) || (duration == 0 && !start.Before(dayStart))
+			if !overlaps || (e.RecurrenceID.IsZero() && (containsTime(e.ExDates, start) || containsTime(replaced[e.UID], start))) {
+				return true
+			}
+			occurrences = append(occurrences, Occurrence{
+				Summary:  e.Summary,
+				Location: e.Location,
+				Start:    start,
+				End:      end,
+				AllDay:   e.AllDay,
+			})
+			return true
+		})
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		if occurrences[i].AllDay != occurrences[j].AllDay {
+			return occurrences[i].AllDay
+		}
+		return occurrences[i].Start.Before(occurrences[j].Start)
+	})
+	return occurrences
+}
+
+// containsTime reports whether times contains t
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, other := range times {
+		if other.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,232 @@
+package ics
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// expand returns the dates ("2006-01-02") of the first occurrences of an event starting at
+// dtstart (local "20060102T150405" at UTC+8) with an RRULE, before end
+func expand(t *testing.T, dtstart, rrule, end string, limit int) []string {
+	t.Helper()
+	loc := time.FixedZone("CST", 8*3600)
+	start, err := time.ParseInLocation("20060102T150405", dtstart, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	until, err := time.ParseInLocation("2006-01-02", end, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rule, err := parseRule(rrule, loc)
+	if err != nil {
+		t.Fatalf("parseRule(%q): %v", rrule, err)
+	}
+	e := &Event{Start: start, End: start.Add(time.Hour), Rule: rule}
+	var dates []string
+	e.starts(until, func(s time.Time) bool {
+		if s.Hour() != start.Hour() || s.Minute() != start.Minute() {
+			t.Errorf("occurrence %v lost the start time", s)
+		}
+		dates = append(dates, s.Format("2006-01-02"))
+		return len(dates) < limit
+	})
+	return dates
+}
+
+func TestRuleExpansion(t *testing.T) {
+	tests := []struct {
+		name    string
+		dtstart string
+		rrule   string
+		end     string
+		want    string
+	}{
+		{
+			name:    "monthly on the second Monday",
+			dtstart: "20250113T090000",
+			rrule:   "FREQ=MONTHLY;BYDAY=2MO;COUNT=4",
+			end:     "2026-01-01",
+			want:    "2025-01-13 2025-02-10 2025-03-10 2025-04-14",
+		},
+		{
+			name:    "monthly on the last Friday",
+			dtstart: "20250131T180000",
+			rrule:   "FREQ=MONTHLY;BYDAY=-1FR",
+			end:     "2025-06-01",
+			want:    "2025-01-31 2025-02-28 2025-03-28 2025-04-25 2025-05-30",
+		},
+		{
+			name:    "monthly on the 1st and 15th",
+			dtstart: "20250101T080000",
+			rrule:   "FREQ=MONTHLY;BYMONTHDAY=1,15",
+			end:     "2025-03-01",
+			want:    "2025-01-01 2025-01-15 2025-02-01 2025-02-15",
+		},
+		{
+			name:    "monthly on the last day",
+			dtstart: "20250131T080000",
+			rrule:   "FREQ=MONTHLY;BYMONTHDAY=-1",
+			end:     "2025-05-01",
+			want:    "2025-01-31 2025-02-28 2025-03-31 2025-04-30",
+		},
+		{
+			name:    "monthly on the last weekday",
+			dtstart: "20250131T080000",
+			rrule:   "FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1",
+			end:     "2025-07-01",
+			want:    "2025-01-31 2025-02-28 2025-03-31 2025-04-30 2025-05-30 2025-06-30",
+		},
+		{
+			name:    "Friday the 13th",
+			dtstart: "20250613T200000",
+			rrule:   "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13",
+			end:     "2027-01-01",
+			want:    "2025-06-13 2026-02-13 2026-03-13 2026-11-13",
+		},
+		{
+			name:    "monthly in some months",
+			dtstart: "20250110T080000",
+			rrule:   "FREQ=MONTHLY;BYMONTH=3,9",
+			end:     "2026-06-01",
+			want:    "2025-03-10 2025-09-10 2026-03-10",
+		},
+		{
+			name:    "monthly skips months without the day",
+			dtstart: "20250131T080000",
+			rrule:   "FREQ=MONTHLY",
+			end:     "2025-06-01",
+			want:    "2025-01-31 2025-03-31 2025-05-31",
+		},
+		{
+			name:    "yearly on the fourth Thursday of November",
+			dtstart: "20251127T120000",
+			rrule:   "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH",
+			end:     "2028-01-01",
+			want:    "2025-11-27 2026-11-26 2027-11-25",
+		},
+		{
+			name:    "yearly on the last Sunday of March and October",
+			dtstart: "20250330T010000",
+			rrule:   "FREQ=YEARLY;BYMONTH=3,10;BYDAY=-1SU",
+			end:     "2026-12-31",
+			want:    "2025-03-30 2025-10-26 2026-03-29 2026-10-25",
+		},
+		{
+			name:    "yearly on the 20th Monday of the year",
+			dtstart: "20250519T080000",
+			rrule:   "FREQ=YEARLY;BYDAY=20MO",
+			end:     "2027-12-31",
+			want:    "2025-05-19 2026-05-18 2027-05-17",
+		},
+		{
+			name:    "yearly on the first day of some months",
+			dtstart: "20250101T000000",
+			rrule:   "FREQ=YEARLY;BYMONTH=1,7;BYMONTHDAY=1;COUNT=3",
+			end:     "2030-01-01",
+			want:    "2025-01-01 2025-07-01 2026-01-01",
+		},
+		{
+			name:    "yearly on Feb 29",
+			dtstart: "20240229T080000",
+			rrule:   "FREQ=YEARLY",
+			end:     "2030-01-01",
+			want:    "2024-02-29 2028-02-29",
+		},
+		{
+			name:    "weekly every other week",
+			dtstart: "20250106T080000",
+			rrule:   "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;UNTIL=20250131T000000Z",
+			end:     "2025-12-31",
+			want:    "2025-01-06 2025-01-09 2025-01-20 2025-01-23",
+		},
+		{
+			// With weeks starting on Sunday the Sunday after the start is in the next (skipped) week
+			name:    "weekly with the week starting on Sunday",
+			dtstart: "20250106T080000",
+			rrule:   "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,SU;WKST=SU",
+			end:     "2025-01-25",
+			want:    "2025-01-06 2025-01-19 2025-01-20",
+		},
+		{
+			name:    "daily on weekdays of a month",
+			dtstart: "20250127T080000",
+			rrule:   "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;BYMONTH=1",
+			end:     "2026-01-03",
+			want:    "2025-01-27 2025-01-28 2025-01-29 2025-01-30 2025-01-31 2026-01-01 2026-01-02",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(expand(t, tt.dtstart, tt.rrule, tt.end, 50), " ")
+			if got != tt.want {
+				t.Errorf("%s\n got %s\nwant %s", tt.rrule, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRuleRejectsUnsupportedParts(t *testing.T) {
+	for _, rrule := range []string{
+		"FREQ=HOURLY",
+		"FREQ=DAILY;BYHOUR=9,17",
+		"FREQ=YEARLY;BYWEEKNO=20",
+		"FREQ=YEARLY;BYYEARDAY=100",
+		"FREQ=WEEKLY;BYDAY=2MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=DAILY;BYSETPOS=1",
+	} {
+		if _, err := parseRule(rrule, time.UTC); !errors.Is(err, ErrUnsupportedRule) {
+			t.Errorf("parseRule(%q) = %v, want ErrUnsupportedRule", rrule, err)
+		}
+	}
+	for _, rrule := range []string{
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=WEEKLY;BYDAY=XX",
+	} {
+		if _, err := parseRule(rrule, time.UTC); err == nil {
+			t.Errorf("parseRule(%q) succeeded", rrule)
+		}
+	}
+}
+
+// Events with unsupported rules are left out with the reason; the rest of the calendar is kept
+func TestParseSkipsUnsupportedRules(t *testing.T) {
+	doc := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:standup",
+		"SUMMARY:Standup",
+		"DTSTART:20250106T090000",
+		"RRULE:FREQ=MONTHLY;BYDAY=1MO",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:hourly",
+		"SUMMARY:Hourly",
+		"DTSTART:20250106T090000",
+		"RRULE:FREQ=DAILY;BYHOUR=9,10",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	cal, err := Parse([]byte(doc), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Events) != 1 || cal.Events[0].UID != "standup" {
+		t.Fatalf("events = %+v, want only standup", cal.Events)
+	}
+	if len(cal.Skipped) != 1 || !errors.Is(cal.Skipped[0], ErrUnsupportedRule) {
+		t.Fatalf("skipped = %v", cal.Skipped)
+	}
+
+	got := fmt.Sprint(len(cal.EventsOn(time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC))), len(cal.EventsOn(time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC))))
+	if got != "1 0" {
+		t.Errorf("events on the first and second Monday of February = %s, want 1 0", got)
+	}
+}
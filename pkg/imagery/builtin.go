@@ -0,0 +1,85 @@
+package imagery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// Size of the built-in images
+const (
+	builtinWidth  = 960
+	builtinHeight = 540
+)
+
+// palette is the sky (top) and ground (bottom) color of a built-in image
+type palette struct {
+	top, bottom color.RGBA
+}
+
+// builtinPalettes holds the colors of the built-in image of each condition
+var builtinPalettes = map[string]palette{
+	ConditionClear:    {color.RGBA{66, 145, 235, 255}, color.RGBA{255, 214, 140, 255}},
+	ConditionCloudy:   {color.RGBA{110, 160, 210, 255}, color.RGBA{220, 228, 236, 255}},
+	ConditionOvercast: {color.RGBA{120, 130, 145, 255}, color.RGBA{190, 195, 200, 255}},
+	ConditionRain:     {color.RGBA{70, 85, 105, 255}, color.RGBA{140, 160, 175, 255}},
+	ConditionStorm:    {color.RGBA{40, 40, 70, 255}, color.RGBA{110, 100, 140, 255}},
+	ConditionSnow:     {color.RGBA{180, 200, 225, 255}, color.RGBA{250, 252, 255, 255}},
+	ConditionFog:      {color.RGBA{185, 190, 195, 255}, color.RGBA{230, 232, 234, 255}},
+	ConditionHaze:     {color.RGBA{170, 160, 130, 255}, color.RGBA{215, 205, 175, 255}},
+	ConditionDust:     {color.RGBA{190, 150, 90, 255}, color.RGBA{235, 205, 150, 255}},
+}
+
+// BuiltinProvider draws a simple sky gradient for each condition, so that images work
+// without downloading anything. A sun is added on clear days.
+type BuiltinProvider struct{}
+
+// NewBuiltinProvider creates a new BuiltinProvider
+func NewBuiltinProvider() *BuiltinProvider {
+	return &BuiltinProvider{}
+}
+
+// Image renders the PNG image of a condition
+func (p *BuiltinProvider) Image(ctx context.Context, condition string) (*Image, error) {
+	pal, ok := builtinPalettes[condition]
+	if !ok {
+		pal = builtinPalettes[ConditionClear]
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, builtinWidth, builtinHeight))
+	for y := 0; y < builtinHeight; y++ {
+		c := blend(pal.top, pal.bottom, float64(y)/float64(builtinHeight-1))
+		for x := 0; x < builtinWidth; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+
+	if condition == ConditionClear {
+		sun := color.RGBA{255, 236, 150, 255}
+		cx, cy, r := builtinWidth*3/4, builtinHeight/4, builtinHeight/8
+		for y := cy - r; y <= cy+r; y++ {
+			for x := cx - r; x <= cx+r; x++ {
+				if (x-cx)*(x-cx)+(y-cy)*(y-cy) <= r*r {
+					img.SetRGBA(x, y, sun)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return &Image{Data: buf.Bytes(), Name: condition + ".png"}, nil
+}
+
+// blend linearly interpolates between two colors
+func blend(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 255}
+}
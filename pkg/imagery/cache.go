@@ -0,0 +1,54 @@
+package imagery
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// cachedImage is an image and the time it was fetched
+type cachedImage struct {
+	image     *Image
+	fetchedAt time.Time
+}
+
+// Cache keeps the image of each condition for a while, so every subscriber of the day
+// gets the same photo and the provider is called at most once per condition per TTL.
+// When a refresh fails, the stale image keeps being served.
+type Cache struct {
+	provider Provider
+	ttl      time.Duration
+
+	mu     sync.Mutex
+	images map[string]cachedImage
+}
+
+// NewCache creates a new Cache
+func NewCache(provider Provider, ttl time.Duration) *Cache {
+	return &Cache{
+		provider: provider,
+		ttl:      ttl,
+		images:   make(map[string]cachedImage),
+	}
+}
+
+// Image returns the cached image of the condition, fetching it when missing or expired
+func (c *Cache) Image(ctx context.Context, condition string) (*Image, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.images[condition]
+	if ok && time.Since(cached.fetchedAt) < c.ttl {
+		return cached.image, nil
+	}
+
+	img, err := c.provider.Image(ctx, condition)
+	if err != nil {
+		if ok {
+			return cached.image, nil
+		}
+		return nil, err
+	}
+	c.images[condition] = cachedImage{image: img, fetchedAt: time.Now()}
+	return img, nil
+}
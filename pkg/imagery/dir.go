@@ -0,0 +1,56 @@
+package imagery
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// imageExtensions are the file types picked up from an image directory
+var imageExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".webp": true}
+
+// DirProvider serves images from a local directory holding either <condition>.jpg files
+// or <condition>/ subdirectories, from which a random image is picked
+type DirProvider struct {
+	dir string
+}
+
+// NewDirProvider creates a new DirProvider
+func NewDirProvider(dir string) *DirProvider {
+	return &DirProvider{dir: dir}
+}
+
+// Image reads an image of the condition from the directory
+func (p *DirProvider) Image(ctx context.Context, condition string) (*Image, error) {
+	var candidates []string
+
+	entries, err := os.ReadDir(filepath.Join(p.dir, condition))
+	if err == nil {
+		for _, e := range entries {
+			if !e.IsDir() && imageExtensions[strings.ToLower(filepath.Ext(e.Name()))] {
+				candidates = append(candidates, filepath.Join(p.dir, condition, e.Name()))
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		for ext := range imageExtensions {
+			path := filepath.Join(p.dir, condition+ext)
+			if _, err := os.Stat(path); err == nil {
+				candidates = append(candidates, path)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no image for condition %s in %s", condition, p.dir)
+	}
+
+	path := candidates[rand.Intn(len(candidates))]
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	return &Image{Data: data, Name: filepath.Base(path)}, nil
+}
@@ -0,0 +1,83 @@
+package imagery
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// Weather conditions that images are matched to
+const (
+	ConditionClear    = "clear"
+	ConditionCloudy   = "cloudy"
+	ConditionOvercast = "overcast"
+	ConditionRain     = "rain"
+	ConditionStorm    = "storm"
+	ConditionSnow     = "snow"
+	ConditionFog      = "fog"
+	ConditionHaze     = "haze"
+	ConditionDust     = "dust"
+)
+
+// Conditions lists all conditions, e.g., for naming files of a local image set
+var Conditions = []string{
+	ConditionClear, ConditionCloudy, ConditionOvercast, ConditionRain, ConditionStorm,
+	ConditionSnow, ConditionFog, ConditionHaze, ConditionDust,
+}
+
+// Image is an image ready to be uploaded
+type Image struct {
+	Data []byte
+	Name string // File name, used as a hint for the image type
+}
+
+// Provider returns an image for a weather condition
+type Provider interface {
+	Image(ctx context.Context, condition string) (*Image, error)
+}
+
+// Condition maps a QWeather icon code (falling back to the weather text) to a condition
+func Condition(icon, text string) string {
+	if code, err := strconv.Atoi(icon); err == nil {
+		switch {
+		case code == 100 || code == 150 || code == 900 || code == 901:
+			return ConditionClear
+		case code >= 101 && code <= 103, code >= 151 && code <= 153:
+			return ConditionCloudy
+		case code == 104:
+			return ConditionOvercast
+		case code >= 302 && code <= 304:
+			return ConditionStorm
+		case code >= 300 && code < 400:
+			return ConditionRain
+		case code >= 400 && code < 500:
+			return ConditionSnow
+		case code == 502 || (code >= 511 && code <= 513):
+			return ConditionHaze
+		case code >= 503 && code <= 508:
+			return ConditionDust
+		case code >= 500 && code < 600:
+			return ConditionFog
+		}
+	}
+
+	switch {
+	case strings.Contains(text, "雷"):
+		return ConditionStorm
+	case strings.Contains(text, "雪"):
+		return ConditionSnow
+	case strings.Contains(text, "雨"):
+		return ConditionRain
+	case strings.Contains(text, "霾"):
+		return ConditionHaze
+	case strings.Contains(text, "沙"), strings.Contains(text, "尘"):
+		return ConditionDust
+	case strings.Contains(text, "雾"):
+		return ConditionFog
+	case strings.Contains(text, "阴"):
+		return ConditionOvercast
+	case strings.Contains(text, "云"):
+		return ConditionCloudy
+	}
+	return ConditionClear
+}
@@ -0,0 +1,66 @@
+package imagery
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// maxImageBytes is the largest image downloaded (Telegram accepts photos up to 10 MB)
+const maxImageBytes = 10 << 20
+
+// URLProvider downloads images from a URL template, e.g., an image CDN or a search API
+// returning an image. {condition} in the template is replaced with the condition.
+type URLProvider struct {
+	template string
+	client   *http.Client
+}
+
+// NewURLProvider creates a new URLProvider
+func NewURLProvider(template string, timeout time.Duration) *URLProvider {
+	return &URLProvider{
+		template: template,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Image downloads the image of the condition
+func (p *URLProvider) Image(ctx context.Context, condition string) (*Image, error) {
+	imageURL := strings.ReplaceAll(p.template, "{condition}", url.PathEscape(condition))
+	req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image server returned status %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "image/") {
+		return nil, fmt.Errorf("image server returned content type %q", ct)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, fmt.Errorf("image exceeds %d bytes", maxImageBytes)
+	}
+
+	name := path.Base(resp.Request.URL.Path)
+	if name == "/" || name == "." {
+		name = condition + ".jpg"
+	}
+	return &Image{Data: data, Name: name}, nil
+}
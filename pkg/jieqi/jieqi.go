@@ -0,0 +1,43 @@
+// Package jieqi holds a curated collection of seasonal care tips (养生) for the 24 solar terms and
+// picks the one shown on a day
+package jieqi
+
+import "hash/fnv"
+
+// Term is a solar term with its seasonal care tips
+type Term struct {
+	Name    string   // Chinese name, e.g. "谷雨"
+	Summary string   // What the term marks
+	Tips    []string // Health and diet tips
+}
+
+// Lookup returns the term of a Chinese name
+func Lookup(name string) (Term, bool) {
+	for _, term := range terms {
+		if term.Name == name {
+			return term, true
+		}
+	}
+	return Term{}, false
+}
+
+// Names returns the names of the 24 solar terms, from 立春 to 大寒
+func Names() []string {
+	names := make([]string, len(terms))
+	for i, term := range terms {
+		names[i] = term.Name
+	}
+	return names
+}
+
+// Pick returns the tip of a term for a date (YYYY-MM-DD), or "" for an unknown term. The choice
+// depends only on the term and date, so everyone sees the same tip that day.
+func Pick(name, date string) string {
+	term, ok := Lookup(name)
+	if !ok || len(term.Tips) == 0 {
+		return ""
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + "|" + date))
+	return term.Tips[h.Sum32()%uint32(len(term.Tips))]
+}
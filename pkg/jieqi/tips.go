@@ -0,0 +1,222 @@
+package jieqi
+
+// terms lists the solar terms in order from 立春. Keep each tip a single everyday suggestion of
+// at most about 50 characters, without medical claims; the AI may rephrase but never adds advice.
+var terms = []Term{
+	{
+		Name:    "立春",
+		Summary: "春季开始，万物复苏，但北方仍常有倒春寒。",
+		Tips: []string{
+			"春捂秋冻，立春后别急着减衣，尤其注意颈背和双脚保暖。",
+			"饮食可少酸多甘，适量吃些韭菜、春笋、菠菜等应季蔬菜。",
+			"春困来袭时，早睡早起、午间小憩一刻钟，比硬撑更有精神。",
+		},
+	},
+	{
+		Name:    "雨水",
+		Summary: "降雪渐少、降雨开始，空气湿度逐渐增加。",
+		Tips: []string{
+			"早晚仍凉，湿冷天气里注意下肢保暖，衣物被褥勤晾晒。",
+			"饮食宜清淡，可适量喝些山药粥、小米粥，少吃油腻生冷。",
+			"天气回暖但寒潮仍会反复，外出留意天气预报、备好外套。",
+		},
+	},
+	{
+		Name:    "惊蛰",
+		Summary: "春雷始鸣，蛰伏的昆虫开始苏醒。",
+		Tips: []string{
+			"气温起伏大，早晚添衣；有过敏史的人留意花粉和尘螨。",
+			"民间有惊蛰吃梨的习俗，梨润燥爽口，可生吃也可煮水。",
+			"白天渐长，适合出门散步、慢跑，让身体跟着春天舒展开。",
+		},
+	},
+	{
+		Name:    "春分",
+		Summary: "昼夜几乎等长，此后北半球白昼渐长。",
+		Tips: []string{
+			"作息可跟着昼长调整，早睡早起，保证七八个小时的睡眠。",
+			"饮食讲究寒热均衡，吃凉性的菜时可配些姜、葱等温性佐料。",
+			"春光正好，踏青放风筝既能活动筋骨，也能放松心情。",
+		},
+	},
+	{
+		Name:    "清明",
+		Summary: "气清景明，草木繁茂，正是踏青扫墓的时节。",
+		Tips: []string{
+			"踏青扫墓注意防火，山林间穿长袖长裤，防蚊虫和划伤。",
+			"清明前后雨水多，出门带伞，淋雨后及时擦干、换上干衣。",
+			"青团好吃但糯米不易消化，肠胃弱的人和老人孩子要少吃。",
+		},
+	},
+	{
+		Name:    "谷雨",
+		Summary: "雨生百谷，是春季最后一个节气，降雨明显增多。",
+		Tips: []string{
+			"谷雨湿气重，可适量吃些薏米、赤小豆、冬瓜，少吃生冷油腻。",
+			"春茶正当季，喝茶宜清淡适量，空腹和睡前少喝浓茶。",
+			"早晚温差仍大，别过早穿短袖；柳絮杨絮多时，过敏的人外出戴口罩。",
+		},
+	},
+	{
+		Name:    "立夏",
+		Summary: "夏季开始，气温明显升高，雷雨增多。",
+		Tips: []string{
+			"天气渐热，养成午间小憩的习惯，也别忘了主动喝水。",
+			"饮食宜清淡，多吃时令蔬果，冷饮解暑但别贪多。",
+			"户外运动避开正午，选在清晨或傍晚，运动后及时补水。",
+		},
+	},
+	{
+		Name:    "小满",
+		Summary: "北方麦类籽粒渐饱满，南方降雨增多。",
+		Tips: []string{
+			"天气湿热，衣物选透气吸汗的棉麻面料，出汗后及时换洗。",
+			"可适量吃些苦瓜、黄瓜、绿豆等清爽食物，少吃油炸烧烤。",
+			"湿热天气食物易变质，剩菜及时冷藏，隔夜的凉菜别再吃。",
+		},
+	},
+	{
+		Name:    "芒种",
+		Summary: "有芒的麦子可以收、有芒的稻子可以种，南方多进入梅雨季。",
+		Tips: []string{
+			"梅雨季注意除湿防霉，食物密封存放，发霉的不要吃。",
+			"天热人易乏，可吃些绿豆汤、冬瓜汤，饮食清淡好消化。",
+			"出汗多时适当补充淡盐水或电解质饮料，别只喝白开水。",
+		},
+	},
+	{
+		Name:    "夏至",
+		Summary: "北半球白昼最长，此后进入一年中最热的时段。",
+		Tips: []string{
+			"冬至饺子夏至面，清爽的凉面配黄瓜丝，开胃又解暑。",
+			"空调温度建议不低于 26℃，别对着人直吹，进出室内外缓一缓。",
+			"中午紫外线最强，外出戴帽、打伞、涂防晒，少在烈日下久留。",
+		},
+	},
+	{
+		Name:    "小暑",
+		Summary: "天气开始炎热，但还未到最热的时候。",
+		Tips: []string{
+			"高温天少量多次地喝水，别等口渴了才一次喝很多。",
+			"冰镇西瓜解暑，但刚从冰箱拿出的最好放一会儿再吃。",
+			"睡前用温水洗澡，卧室保持通风，有助于夏夜安睡。",
+		},
+	},
+	{
+		Name:    "大暑",
+		Summary: "一年中最热的时期，高温、雷暴和强降雨都较常见。",
+		Tips: []string{
+			"警惕中暑：头晕、恶心、大汗时立即到阴凉处休息、补水。",
+			"饮食宜清淡少油，可喝些绿豆汤、荷叶粥，少吃辛辣。",
+			"不要让老人和孩子独自留在密闭车内，几分钟就可能危险。",
+		},
+	},
+	{
+		Name:    "立秋",
+		Summary: "秋季开始，但暑热未消，常有「秋老虎」。",
+		Tips: []string{
+			"立秋后仍可能很热，防暑不能松懈，饮水依旧要充足。",
+			"民间有「贴秋膘」的说法，但暑热未退，进补别太油腻。",
+			"早晚开始转凉，睡觉时盖好腹部，空调别开整夜。",
+		},
+	},
+	{
+		Name:    "处暑",
+		Summary: "暑气至此而止，昼夜温差逐渐拉大。",
+		Tips: []string{
+			"早晚凉、中午热，出门带件薄外套，方便随时增减。",
+			"秋燥渐起，多喝水，可吃些梨、百合、银耳等润燥食物。",
+			"秋乏常见，保证睡眠，午后犯困时起身走动一下。",
+		},
+	},
+	{
+		Name:    "白露",
+		Summary: "夜间水汽凝结成露，天气明显转凉。",
+		Tips: []string{
+			"白露身不露，早晚别再光膀子、穿短裤，注意脚踝保暖。",
+			"天气干燥，多喝温水，可适量吃些梨、葡萄等时令水果。",
+			"秋高气爽适合登高、散步，运动量循序渐进，别突然加大。",
+		},
+	},
+	{
+		Name:    "秋分",
+		Summary: "昼夜再次几乎等长，此后北半球夜渐长。",
+		Tips: []string{
+			"气候干燥，室内可用加湿器，皮肤和嘴唇注意保湿。",
+			"饮食可多些润燥的食物，如百合、莲藕、山药，少吃辛辣。",
+			"秋分后昼短夜长，作息可略微提前，保证充足睡眠。",
+		},
+	},
+	{
+		Name:    "寒露",
+		Summary: "露水更凉，将要凝结成霜，深秋来临。",
+		Tips: []string{
+			"寒露脚不露，换上保暖的鞋袜，睡前可用温水泡脚。",
+			"气温骤降易感冒，及时增添衣物，室内定时开窗通风。",
+			"可适量吃些芝麻、核桃、蜂蜜等，缓解秋燥带来的不适。",
+		},
+	},
+	{
+		Name:    "霜降",
+		Summary: "秋季最后一个节气，北方开始出现初霜。",
+		Tips: []string{
+			"天气渐冷，关节怕凉的人注意膝盖保暖，晨练可适当推迟。",
+			"民间有霜降吃柿子的习俗，但别空腹吃，一次也别吃太多。",
+			"昼夜温差大，早晚出门戴好围巾，护住颈部。",
+		},
+	},
+	{
+		Name:    "立冬",
+		Summary: "冬季开始，天气渐寒，北方将陆续供暖。",
+		Tips: []string{
+			"冬令进补宜循序渐进，可吃些羊肉、萝卜、山药，别一下吃太补。",
+			"暖气房里空气干燥，多喝水、勤通风，必要时加湿。",
+			"冬季作息宜早睡晚起，等太阳出来后再晨练更合适。",
+		},
+	},
+	{
+		Name:    "小雪",
+		Summary: "北方开始降雪，但雪量不大。",
+		Tips: []string{
+			"天气阴冷，日照少，多出门晒晒太阳，有助于保持好心情。",
+			"雨雪天路面湿滑，出行放慢脚步，老人外出最好有人陪伴。",
+			"可多吃些白菜、萝卜等应季蔬菜，饮食温热为宜。",
+		},
+	},
+	{
+		Name:    "大雪",
+		Summary: "降雪量增多，气温显著下降。",
+		Tips: []string{
+			"注意头颈和双脚的保暖，出门戴帽子、手套和围巾。",
+			"使用取暖设备时注意通风，燃煤燃气取暖谨防一氧化碳中毒。",
+			"冬季进补可喝些热汤热粥，但火锅烧烤别太辣太油。",
+		},
+	},
+	{
+		Name:    "冬至",
+		Summary: "北半球白昼最短，此后白天渐长，民间有「冬至大如年」之说。",
+		Tips: []string{
+			"北方吃饺子、南方吃汤圆，热乎乎的一餐最应景，但汤圆别一次吃太多。",
+			"冬至后进入「数九」寒天，注意防寒保暖，尤其是心脑血管病人。",
+			"天冷也要适当运动，室内做做操、快走，别整天窝着不动。",
+		},
+	},
+	{
+		Name:    "小寒",
+		Summary: "天气寒冷，常是一年中最冷的时段之一。",
+		Tips: []string{
+			"严寒天早晚少外出，出门前做好保暖，避免冷风直吹头部。",
+			"可适量吃些温热的食物，如羊肉汤、姜枣茶，少吃生冷。",
+			"室内外温差大，进出时适当增减衣物，预防感冒。",
+		},
+	},
+	{
+		Name:    "大寒",
+		Summary: "二十四节气的最后一个，天寒地冻，年味渐浓。",
+		Tips: []string{
+			"天气严寒，注意防寒防冻，手脚冰凉的人可以睡前泡泡脚。",
+			"年前聚餐多，饮食有节制，少喝酒，多吃蔬菜。",
+			"年前忙碌也要规律作息，别熬夜，给身体留足休息时间。",
+		},
+	},
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// componentCore drops entries below the level configured for the component that logged them.
+// The component is the logger name (e.g. "gorm"), else the caller's file name without ".go"
+// (e.g. "scheduler"), else the caller's package directory (e.g. "qweather"). The caller is only
+// known when an entry is written, so the wrapped core must enable the lowest configured level.
+type componentCore struct {
+	zapcore.Core
+	base   zapcore.Level            // Level of components without their own
+	levels map[string]zapcore.Level // Component -> level
+	files  *sync.Map                // Caller file -> resolved level
+}
+
+// newComponentCore wraps core with the per-component levels; without any, core is returned as is
+func newComponentCore(core zapcore.Core, base zapcore.Level, levels map[string]zapcore.Level) zapcore.Core {
+	if len(levels) == 0 {
+		return core
+	}
+	return &componentCore{Core: core, base: base, levels: levels, files: &sync.Map{}}
+}
+
+// With adds structured context to the wrapped core
+func (c *componentCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.Core = c.Core.With(fields)
+	return &clone
+}
+
+// Check adds the core to the entry when the wrapped core enables its level
+func (c *componentCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry when it reaches the level of its component
+func (c *componentCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if ent.Level < c.levelOf(ent) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// levelOf returns the level of the component that logged an entry
+func (c *componentCore) levelOf(ent zapcore.Entry) zapcore.Level {
+	if ent.LoggerName != "" {
+		if level, ok := c.levels[ent.LoggerName]; ok {
+			return level
+		}
+	}
+	if !ent.Caller.Defined {
+		return c.base
+	}
+	if level, ok := c.files.Load(ent.Caller.File); ok {
+		return level.(zapcore.Level)
+	}
+	level := c.base
+	file := strings.TrimSuffix(filepath.Base(ent.Caller.File), ".go")
+	if l, ok := c.levels[file]; ok {
+		level = l
+	} else if l, ok := c.levels[filepath.Base(filepath.Dir(ent.Caller.File))]; ok {
+		level = l
+	}
+	c.files.Store(ent.Caller.File, level)
+	return level
+}
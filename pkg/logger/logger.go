@@ -2,6 +2,8 @@ package logger
 
 import (
 	"os"
+	"strings"
+	"time"
 
 	"github.com/cuichanghe/daily-reminder-bot/internal/config"
 	"go.uber.org/zap"
@@ -33,17 +35,51 @@ func Init(cfg *config.LoggerConfig) error {
 		encoder = zapcore.NewConsoleEncoder(encoderConfig)
 	}
 
-	level := zapcore.InfoLevel
-	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
-		level = zapcore.InfoLevel
+	level := parseLevel(cfg.Level, zapcore.InfoLevel)
+	levels := make(map[string]zapcore.Level, len(cfg.Levels))
+	lowest := level
+	for component, name := range cfg.Levels {
+		componentLevel := parseLevel(name, level)
+		levels[strings.ToLower(component)] = componentLevel
+		lowest = min(lowest, componentLevel)
 	}
 
-	core := zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), level)
+	setScrubbers(privacyScrubbers(cfg.Privacy.Enabled, cfg.Privacy.Fields))
+	out := zapcore.AddSync(os.Stdout)
+	var core zapcore.Core
+	if cfg.Sampling.Initial > 0 {
+		// Sample debug and info entries only; warnings and errors are always written
+		thereafter := cfg.Sampling.Thereafter
+		if thereafter <= 0 {
+			thereafter = 100
+		}
+		low := &scrubCore{Core: zapcore.NewCore(encoder, out, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= lowest && l < zapcore.WarnLevel
+		}))}
+		high := &scrubCore{Core: zapcore.NewCore(encoder.Clone(), out, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= lowest && l >= zapcore.WarnLevel
+		}))}
+		core = zapcore.NewTee(
+			zapcore.NewSamplerWithOptions(newComponentCore(low, level, levels), time.Second, cfg.Sampling.Initial, thereafter),
+			newComponentCore(high, level, levels),
+		)
+	} else {
+		core = newComponentCore(&scrubCore{Core: zapcore.NewCore(encoder, out, lowest)}, level, levels)
+	}
 	globalLogger = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
 
 	return nil
 }
 
+// parseLevel parses a level name, returning def for an empty or unknown name
+func parseLevel(name string, def zapcore.Level) zapcore.Level {
+	level := def
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return def
+	}
+	return level
+}
+
 // Get returns the global logger instance
 func Get() *zap.Logger {
 	if globalLogger == nil {
@@ -13,25 +13,15 @@ func MaskAPIKey(key string) string {
 	return key[:4] + "****" + key[len(key)-4:]
 }
 
+// sensitiveParams matches common sensitive query parameters (key, token, apikey, etc.)
+var sensitiveParams = regexp.MustCompile(`(?i)((?:key|token|apikey|api_key|secret|password)=)[^&]+`)
+
 // MaskURL masks sensitive parameters in a URL (key, token, apikey, etc.)
 func MaskURL(rawURL string) string {
-	// Pattern to match common sensitive query parameters
-	patterns := []string{
-		`(key=)[^&]+`,
-		`(token=)[^&]+`,
-		`(apikey=)[^&]+`,
-		`(api_key=)[^&]+`,
-		`(secret=)[^&]+`,
-		`(password=)[^&]+`,
-	}
-
-	result := rawURL
-	for _, pattern := range patterns {
-		re := regexp.MustCompile("(?i)" + pattern)
-		result = re.ReplaceAllString(result, "${1}***")
+	if !strings.Contains(rawURL, "=") {
+		return rawURL
 	}
-
-	return result
+	return sensitiveParams.ReplaceAllString(rawURL, "${1}***")
 }
 
 // MaskAuthHeader masks an authorization header value
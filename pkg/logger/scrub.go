@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Scrubber rewrites a log field before it is written, e.g. to mask personal data, returning the
+// new field and true, or false when it leaves the field unchanged
+type Scrubber func(field zapcore.Field) (zapcore.Field, bool)
+
+// Fields masked by the privacy scrubbers
+var (
+	// chatIDFields hold Telegram chat and user IDs
+	chatIDFields = map[string]bool{
+		"chat_id":      true,
+		"telegram_id":  true,
+		"from_chat_id": true,
+		"to_chat_id":   true,
+		"actor":        true,
+	}
+	// personalTextFields hold text written by users
+	personalTextFields = []string{"content", "label", "username", "caption", "payload", "args"}
+)
+
+var (
+	scrubbersMu sync.RWMutex
+	scrubbers   []Scrubber
+)
+
+// AddScrubber adds a scrubber applied to every field of every entry written from now on
+func AddScrubber(s Scrubber) {
+	scrubbersMu.Lock()
+	defer scrubbersMu.Unlock()
+	scrubbers = append(scrubbers, s)
+}
+
+// setScrubbers replaces the scrubbers
+func setScrubbers(list []Scrubber) {
+	scrubbersMu.Lock()
+	defer scrubbersMu.Unlock()
+	scrubbers = list
+}
+
+// scrub applies the scrubbers to fields, copying them only when one changes
+func scrub(fields []zapcore.Field) []zapcore.Field {
+	scrubbersMu.RLock()
+	defer scrubbersMu.RUnlock()
+	if len(scrubbers) == 0 {
+		return fields
+	}
+	var out []zapcore.Field
+	for i, field := range fields {
+		changed := false
+		for _, s := range scrubbers {
+			if scrubbed, ok := s(field); ok {
+				field, changed = scrubbed, true
+			}
+		}
+		if changed && out == nil {
+			out = append(make([]zapcore.Field, 0, len(fields)), fields[:i]...)
+		}
+		if out != nil {
+			out = append(out, field)
+		}
+	}
+	if out == nil {
+		return fields
+	}
+	return out
+}
+
+// ScrubSecrets masks sensitive URL parameters (key, token, ...) in string and error fields, so
+// that call sites need not remember MaskURL
+func ScrubSecrets(field zapcore.Field) (zapcore.Field, bool) {
+	switch field.Type {
+	case zapcore.StringType:
+		if masked := MaskURL(field.String); masked != field.String {
+			return zap.String(field.Key, masked), true
+		}
+	case zapcore.ErrorType:
+		if err, ok := field.Interface.(error); ok && err != nil {
+			if msg := err.Error(); MaskURL(msg) != msg {
+				return zap.String(field.Key, MaskURL(msg)), true
+			}
+		}
+	}
+	return field, false
+}
+
+// ScrubChatIDs masks Telegram chat and user ID fields, keeping the last 4 digits
+func ScrubChatIDs(field zapcore.Field) (zapcore.Field, bool) {
+	if !chatIDFields[field.Key] {
+		return field, false
+	}
+	switch field.Type {
+	case zapcore.Int64Type, zapcore.Int32Type:
+		return zap.String(field.Key, MaskChatID(field.Integer)), true
+	case zapcore.StringType:
+		return zap.String(field.Key, MaskString(field.String, 0)), true
+	}
+	return field, false
+}
+
+// ScrubFields returns a scrubber masking the string fields, and the elements of the array fields
+// (zap.Strings), with the given keys, keeping only their length
+func ScrubFields(keys ...string) Scrubber {
+	masked := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		masked[key] = true
+	}
+	return func(field zapcore.Field) (zapcore.Field, bool) {
+		if !masked[field.Key] {
+			return field, false
+		}
+		switch field.Type {
+		case zapcore.StringType:
+			return zap.String(field.Key, MaskText(field.String)), true
+		case zapcore.ArrayMarshalerType:
+			return zap.Strings(field.Key, maskArray(field)), true
+		}
+		return field, false
+	}
+}
+
+// maskArray masks the elements of an array field, keeping only their length
+func maskArray(field zapcore.Field) []string {
+	enc := zapcore.NewMapObjectEncoder()
+	field.AddTo(enc)
+	values, _ := enc.Fields[field.Key].([]interface{})
+	masked := make([]string, len(values))
+	for i, value := range values {
+		masked[i] = MaskText(fmt.Sprint(value))
+	}
+	return masked
+}
+
+// ScrubPersonalText masks text written by users: todo contents, subscription labels, usernames,
+// photo captions, payment payloads and command arguments
+func ScrubPersonalText() Scrubber {
+	return ScrubFields(personalTextFields...)
+}
+
+// MaskChatID masks a chat ID, keeping its last 4 digits (e.g. "***6789")
+func MaskChatID(id int64) string {
+	s := fmt.Sprint(id)
+	if len(s) <= 4 {
+		return "***"
+	}
+	return "***" + s[len(s)-4:]
+}
+
+// MaskText masks a text, keeping only its length in characters (e.g. "***(12)")
+func MaskText(s string) string {
+	if s == "" {
+		return ""
+	}
+	return fmt.Sprintf("***(%d)", utf8.RuneCountInString(s))
+}
+
+// scrubCore applies the scrubbers to the fields of the entries written to the wrapped core
+type scrubCore struct {
+	zapcore.Core
+}
+
+// With adds scrubbed structured context to the wrapped core
+func (c *scrubCore) With(fields []zapcore.Field) zapcore.Core {
+	return &scrubCore{Core: c.Core.With(scrub(fields))}
+}
+
+// Check adds the core to the entry when the wrapped core enables its level
+func (c *scrubCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry with scrubbed fields
+func (c *scrubCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, scrub(fields))
+}
+
+// privacyScrubbers returns the scrubbers of a privacy setting: secrets are always masked, chat
+// IDs and personal text only when privacy is enabled, and the extra fields in any case
+func privacyScrubbers(enabled bool, fields []string) []Scrubber {
+	list := []Scrubber{ScrubSecrets}
+	if enabled {
+		list = append(list, ScrubChatIDs, ScrubPersonalText())
+	}
+	if len(fields) > 0 {
+		list = append(list, ScrubFields(fields...))
+	}
+	return list
+}
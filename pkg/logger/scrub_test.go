@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// encode returns the values of fields as written by an encoder
+func encode(fields []zapcore.Field) map[string]interface{} {
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	return enc.Fields
+}
+
+func TestPrivacyScrubbers(t *testing.T) {
+	defer setScrubbers(nil)
+	fields := []zapcore.Field{
+		zap.Strings("args", []string{"add", "买牛奶"}),
+		zap.String("content", "交电费"),
+		zap.Int64("chat_id", 123456789),
+		zap.String("url", "https://api.example.com/v7?key=secret"),
+		zap.Strings("cities", []string{"北京"}),
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		want    map[string]string
+	}{
+		{
+			name:    "privacy enabled",
+			enabled: true,
+			want: map[string]string{
+				"args":    "[***(3) ***(3)]",
+				"content": "***(3)",
+				"chat_id": "***6789",
+				"url":     "https://api.example.com/v7?key=***",
+				"cities":  "[北京]",
+			},
+		},
+		{
+			name: "privacy disabled",
+			want: map[string]string{
+				"args":    "[add 买牛奶]",
+				"content": "交电费",
+				"chat_id": "123456789",
+				"url":     "https://api.example.com/v7?key=***",
+				"cities":  "[北京]",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setScrubbers(privacyScrubbers(tt.enabled, nil))
+			got := encode(scrub(fields))
+			for key, want := range tt.want {
+				if value := fmt.Sprint(got[key]); value != want {
+					t.Errorf("%s = %s, want %s", key, value, want)
+				}
+			}
+		})
+	}
+}
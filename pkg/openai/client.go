@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
@@ -20,8 +22,15 @@ type Client struct {
 	maxTokens   int
 	temperature float64
 	client      *http.Client
+	apiVersion  string // Azure OpenAI API version ("" = OpenAI-style API)
+	local       bool   // Local server (Ollama, llama.cpp): the model must be served for Ping to pass
+
+	contextWindow int // Context window of the model in tokens (0 = by model name)
 }
 
+// DefaultAzureAPIVersion is the Azure OpenAI API version used when none is configured
+const DefaultAzureAPIVersion = "2024-10-21"
+
 // NewClient creates a new OpenAI-compatible API client
 func NewClient(apiKey, baseURL, model string, maxTokens int, temperature float64, timeout time.Duration) *Client {
 	return &Client{
@@ -34,8 +43,78 @@ func NewClient(apiKey, baseURL, model string, maxTokens int, temperature float64
 	}
 }
 
+// WithModel returns a client sharing this client's settings that uses another model
+func (c *Client) WithModel(model string) *Client {
+	clone := *c
+	clone.model = model
+	return &clone
+}
+
+// WithAzure returns a client sharing this client's settings that talks to Azure OpenAI: the base
+// URL is the resource endpoint (e.g. https://my-resource.openai.azure.com), the model is the
+// deployment name, and requests carry the API version and the api-key header
+func (c *Client) WithAzure(apiVersion string) *Client {
+	if apiVersion == "" {
+		apiVersion = DefaultAzureAPIVersion
+	}
+	clone := *c
+	clone.baseURL = strings.TrimSuffix(c.baseURL, "/")
+	clone.apiVersion = apiVersion
+	return &clone
+}
+
+// WithLocal returns a client sharing this client's settings for a local OpenAI-compatible server
+// (Ollama, llama.cpp server): the API key is optional and Ping checks that the model is served
+func (c *Client) WithLocal() *Client {
+	clone := *c
+	clone.local = true
+	return &clone
+}
+
+// endpoint returns the URL of an API operation, e.g. "chat/completions"
+func (c *Client) endpoint(operation string) string {
+	if c.apiVersion == "" {
+		return fmt.Sprintf("%s/%s", c.baseURL, operation)
+	}
+	query := "?api-version=" + url.QueryEscape(c.apiVersion)
+	if operation == "models" {
+		return fmt.Sprintf("%s/openai/models%s", c.baseURL, query)
+	}
+	return fmt.Sprintf("%s/openai/deployments/%s/%s%s", c.baseURL, url.PathEscape(c.model), operation, query)
+}
+
+// authorize sets the API key header of a request; requests to servers without authentication
+// (no API key) carry none
+func (c *Client) authorize(req *http.Request) {
+	if c.apiKey == "" {
+		return
+	}
+	if c.apiVersion != "" {
+		req.Header.Set("api-key", c.apiKey)
+		return
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+}
+
+// WithTemperature returns a client sharing this client's settings that samples at another temperature
+func (c *Client) WithTemperature(temperature float64) *Client {
+	clone := *c
+	clone.temperature = temperature
+	return &clone
+}
+
+// Temperature returns the configured sampling temperature
+func (c *Client) Temperature() float64 {
+	return c.temperature
+}
+
 // ChatCompletion sends a chat completion request
 func (c *Client) ChatCompletion(ctx context.Context, messages []Message) (*ChatCompletionResponse, error) {
+	return c.chatCompletion(ctx, messages, nil)
+}
+
+// chatCompletion sends a chat completion request with an optional sampling seed
+func (c *Client) chatCompletion(ctx context.Context, messages []Message, seed *int64) (*ChatCompletionResponse, error) {
 	logger.Debug("OpenAI.ChatCompletion called",
 		zap.String("model", c.model),
 		zap.Int("message_count", len(messages)),
@@ -47,6 +126,7 @@ func (c *Client) ChatCompletion(ctx context.Context, messages []Message) (*ChatC
 		Messages:    messages,
 		MaxTokens:   c.maxTokens,
 		Temperature: c.temperature,
+		Seed:        seed,
 	}
 
 	logger.Debug("Request payload",
@@ -60,7 +140,7 @@ func (c *Client) ChatCompletion(ctx context.Context, messages []Message) (*ChatC
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/chat/completions", c.baseURL)
+	url := c.endpoint("chat/completions")
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		logger.Error("Failed to create request",
@@ -69,7 +149,7 @@ func (c *Client) ChatCompletion(ctx context.Context, messages []Message) (*ChatC
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	c.authorize(req)
 
 	logger.Debug("Sending HTTP request",
 		zap.String("url", url),
@@ -90,23 +170,32 @@ func (c *Client) ChatCompletion(ctx context.Context, messages []Message) (*ChatC
 		zap.Duration("duration", time.Since(start)))
 
 	var chatResp ChatCompletionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		logger.Error("Failed to decode response",
-			zap.Error(err))
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&chatResp)
 
-	if chatResp.Error != nil {
+	if resp.StatusCode != http.StatusOK || chatResp.Error != nil {
+		// Error bodies of gateways and proxies are often not JSON; the status still tells the cause
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header, time.Now()),
+		}
+		if chatResp.Error != nil {
+			apiErr.Message = chatResp.Error.Message
+			apiErr.Type = chatResp.Error.Type
+			apiErr.Code = chatResp.Error.Code
+		}
 		logger.Error("API returned error",
-			zap.String("error_message", chatResp.Error.Message),
-			zap.String("error_type", chatResp.Error.Type))
-		return nil, fmt.Errorf("API error: %s (type: %s)", chatResp.Error.Message, chatResp.Error.Type)
+			zap.Int("status_code", resp.StatusCode),
+			zap.String("error_message", apiErr.Message),
+			zap.String("error_type", apiErr.Type),
+			zap.Duration("retry_after", apiErr.RetryAfter),
+			zap.Bool("retryable", apiErr.Retryable()))
+		return nil, apiErr
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		logger.Error("API returned non-OK status",
-			zap.Int("status_code", resp.StatusCode))
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	if decodeErr != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(decodeErr))
+		return nil, fmt.Errorf("failed to decode response: %w", decodeErr)
 	}
 
 	// Log token usage if available
@@ -149,3 +238,95 @@ func (c *Client) GetContent(ctx context.Context, systemPrompt, userPrompt string
 		zap.Int("content_len", len(resp.Choices[0].Message.Content)))
 	return resp.Choices[0].Message.Content, nil
 }
+
+// GetSeededContent is GetContent with a sampling seed, so providers that support seeds return the
+// same content for the same prompt and seed as far as they can
+func (c *Client) GetSeededContent(ctx context.Context, systemPrompt, userPrompt string, seed int64) (string, error) {
+	messages := []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: userPrompt},
+	}
+
+	resp, err := c.chatCompletion(ctx, messages, &seed)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		logger.Warn("No choices in response")
+		return "", fmt.Errorf("no choices in response")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// GetContentWithImages sends a prompt together with images to a vision-capable model and
+// returns the generated content
+func (c *Client) GetContentWithImages(ctx context.Context, systemPrompt, userPrompt string, images ...ContentPart) (string, error) {
+	logger.Debug("OpenAI.GetContentWithImages called",
+		zap.Int("user_prompt_len", len(userPrompt)),
+		zap.Int("image_count", len(images)))
+
+	messages := []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Parts: append([]ContentPart{TextPart(userPrompt)}, images...)},
+	}
+
+	resp, err := c.ChatCompletion(ctx, messages)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		logger.Warn("No choices in response")
+		return "", fmt.Errorf("no choices in response")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// Ping checks that the API is reachable and accepts the API key by listing the models, without
+// spending tokens. Providers that do not implement the models endpoint count as reachable; local
+// servers must list the configured model.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint("models"), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	c.authorize(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("API key rejected (status %d)", resp.StatusCode)
+	case resp.StatusCode >= 500:
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	case c.local:
+		return c.checkModelListed(resp)
+	}
+	return nil
+}
+
+// checkModelListed checks that a models response lists the configured model. Ollama lists
+// models by their full tag (e.g. "qwen2.5:7b"), so a model without a tag matches ":latest".
+func (c *Client) checkModelListed(resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+	var models ModelList
+	if err := json.NewDecoder(resp.Body).Decode(&models); err != nil {
+		return fmt.Errorf("failed to decode models: %w", err)
+	}
+	for _, m := range models.Data {
+		if m.ID == c.model || m.ID == c.model+":latest" {
+			return nil
+		}
+	}
+	return fmt.Errorf("model %s is not served (%d models available)", c.model, len(models.Data))
+}
+
+// Model returns the configured model name
+func (c *Client) Model() string {
+	return c.model
+}
@@ -0,0 +1,89 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is an error response of the API
+type APIError struct {
+	StatusCode int           // HTTP status code
+	Message    string        // Error message of the response body, if any
+	Type       string        // Error type of the response body, e.g. "insufficient_quota"
+	Code       string        // Error code of the response body
+	RetryAfter time.Duration // Wait requested by the Retry-After headers (0 = none)
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API returned status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("API error: %s (status: %d, type: %s)", e.Message, e.StatusCode, e.Type)
+}
+
+// Retryable reports whether the request may succeed when sent again: rate limits, timeouts and
+// server errors are retryable, while rejected keys, bad requests and an exhausted quota are not
+func (e *APIError) Retryable() bool {
+	if e.Type == "insufficient_quota" || e.Code == "insufficient_quota" {
+		// Reported as 429 by OpenAI, but only paying fixes it
+		return false
+	}
+	switch {
+	case e.StatusCode == http.StatusRequestTimeout, e.StatusCode == http.StatusConflict,
+		e.StatusCode == http.StatusTooManyRequests, e.StatusCode >= 500:
+		return true
+	case e.StatusCode >= 400:
+		return false
+	}
+	// Errors in successful responses come from upstream models of proxies
+	return true
+}
+
+// IsRetryable reports whether a failed request may succeed when sent again. Network errors are
+// retryable; API errors are classified by APIError.Retryable, and cancelled requests are not.
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// RetryAfter returns the wait requested by the server with a failed request (0 = none)
+func RetryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// parseRetryAfter reads the wait requested by a response: the retry-after-ms header of OpenAI
+// and Azure OpenAI, or the standard Retry-After header in seconds or as an HTTP date
+func parseRetryAfter(header http.Header, now time.Time) time.Duration {
+	if v := strings.TrimSpace(header.Get("Retry-After-Ms")); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil && ms > 0 {
+			return time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+	v := strings.TrimSpace(header.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(v); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
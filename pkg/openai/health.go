@@ -0,0 +1,87 @@
+package openai
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// healthProbeTimeout bounds each health probe
+const healthProbeTimeout = 10 * time.Second
+
+// HealthProbe pings an API server in the background, so that callers can skip requests while it
+// is down instead of waiting for timeouts, which are long for local servers
+type HealthProbe struct {
+	client   *Client
+	interval time.Duration
+
+	mu      sync.RWMutex
+	lastErr error // Result of the last probe (nil = healthy)
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewHealthProbe creates a probe of the client's server, run every interval once started. The
+// server counts as healthy until a probe fails.
+func NewHealthProbe(client *Client, interval time.Duration) *HealthProbe {
+	return &HealthProbe{client: client, interval: interval}
+}
+
+// Start probes the server now and then in the background until Stop is called
+func (p *HealthProbe) Start() {
+	p.stop = make(chan struct{})
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.Check(context.Background())
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.Check(context.Background())
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops probing
+func (p *HealthProbe) Stop() {
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+	p.stop = nil
+}
+
+// Check pings the server once and records the result
+func (p *HealthProbe) Check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+	defer cancel()
+	err := p.client.Ping(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case err != nil && p.lastErr == nil:
+		logger.Warn("AI server unhealthy", zap.String("base_url", p.client.baseURL), zap.Error(err))
+	case err == nil && p.lastErr != nil:
+		logger.Info("AI server recovered", zap.String("base_url", p.client.baseURL))
+	}
+	p.lastErr = err
+	return err
+}
+
+// Err returns the failure of the last probe, or nil when the server is healthy
+func (p *HealthProbe) Err() error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.lastErr
+}
@@ -0,0 +1,127 @@
+package openai
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Per-message overhead of the chat format (role and separators) and of priming the reply, as
+// counted by OpenAI's tokenizers
+const (
+	messageOverheadTokens = 4
+	replyPrimingTokens    = 3
+)
+
+// defaultReplyTokens is reserved for the reply in the context window when max_tokens is unset
+const defaultReplyTokens = 1024
+
+// defaultContextWindow is assumed for models missing from contextWindows. Local servers often run
+// models with a small context (Ollama defaults to 2048-4096 tokens), so it is kept modest.
+const defaultContextWindow = 8192
+
+// contextWindows lists the context windows of common models by name prefix; longer prefixes
+// come first so that e.g. gpt-4o is not taken for gpt-4
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-4o", 128000},
+	{"gpt-4.1", 1000000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1", 128000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"deepseek", 64000},
+	{"glm-4", 128000},
+	{"qwen-long", 1000000},
+	{"qwen", 32768},
+	{"moonshot-v1-8k", 8192},
+	{"moonshot-v1-32k", 32768},
+	{"moonshot-v1-128k", 128000},
+}
+
+// EstimateTokens estimates how many tokens a text takes in the BPE tokenizers of OpenAI-style
+// models, erring on the high side: a Chinese character is about a token, an English word about
+// a token per four letters, and each punctuation mark, symbol or line break a token of its own
+func EstimateTokens(text string) int {
+	tokens, word := 0, 0
+	flushWord := func() {
+		if word > 0 {
+			tokens += (word + 3) / 4
+			word = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			word++
+		case r == ' ' || r == '\t':
+			// Leading spaces merge into the next word
+			flushWord()
+		case r <= unicode.MaxASCII:
+			flushWord()
+			tokens++
+		case unicode.Is(unicode.Han, r) || unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flushWord()
+			tokens++
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// Other scripts take a token per one or two letters
+			word += 3
+		default:
+			// Full-width punctuation, emoji and other symbols span several bytes, and tokens
+			flushWord()
+			tokens += 2
+		}
+	}
+	flushWord()
+	return tokens
+}
+
+// EstimateMessageTokens estimates the prompt tokens of a chat completion request
+func EstimateMessageTokens(messages []Message) int {
+	tokens := replyPrimingTokens
+	for _, m := range messages {
+		tokens += messageOverheadTokens + EstimateTokens(m.Content)
+	}
+	return tokens
+}
+
+// ContextWindow returns the context window of the client's model in tokens: the configured
+// window, or that of the model family, or a conservative default for unknown models
+func (c *Client) ContextWindow() int {
+	if c.contextWindow > 0 {
+		return c.contextWindow
+	}
+	model := strings.ToLower(c.model)
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		// Proxies prefix models with their vendor, e.g. "openai/gpt-4o-mini"
+		model = model[i+1:]
+	}
+	for _, w := range contextWindows {
+		if strings.HasPrefix(model, w.prefix) {
+			return w.tokens
+		}
+	}
+	return defaultContextWindow
+}
+
+// PromptLimit returns how many tokens a prompt may take: the context window less the tokens
+// reserved for the reply (max_tokens)
+func (c *Client) PromptLimit() int {
+	reply := c.maxTokens
+	if reply <= 0 {
+		reply = defaultReplyTokens
+	}
+	return max(c.ContextWindow()-reply, 0)
+}
+
+// WithContextWindow returns a client sharing this client's settings whose model has the given
+// context window in tokens (0 = look it up by model name)
+func (c *Client) WithContextWindow(tokens int) *Client {
+	clone := *c
+	clone.contextWindow = tokens
+	return &clone
+}
@@ -1,17 +1,65 @@
 package openai
 
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
 // ChatCompletionRequest represents a request to the chat completions API
 type ChatCompletionRequest struct {
 	Model       string    `json:"model"`
 	Messages    []Message `json:"messages"`
 	MaxTokens   int       `json:"max_tokens,omitempty"`
 	Temperature float64   `json:"temperature,omitempty"`
+	Seed        *int64    `json:"seed,omitempty"` // Sampling seed for repeatable output (not all providers honour it)
 }
 
 // Message represents a chat message
 type Message struct {
-	Role    string `json:"role"` // system, user, assistant
-	Content string `json:"content"`
+	Role    string        `json:"role"` // system, user, assistant
+	Content string        `json:"content"`
+	Parts   []ContentPart `json:"-"` // Multimodal content (text and images); sent instead of Content when set
+}
+
+// ContentPart is one part of a multimodal message
+type ContentPart struct {
+	Type     string    `json:"type"` // text, image_url
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL references an image by URL or base64 data URL
+type ImageURL struct {
+	URL    string `json:"url"`
+	Detail string `json:"detail,omitempty"` // low, high, auto
+}
+
+// TextPart creates a text content part
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: "text", Text: text}
+}
+
+// ImagePart creates an image content part embedding the image as a base64 data URL
+func ImagePart(data []byte, mime string) ContentPart {
+	return ContentPart{
+		Type: "image_url",
+		ImageURL: &ImageURL{
+			URL:    "data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(data),
+			Detail: "high",
+		},
+	}
+}
+
+// MarshalJSON encodes Parts as the content array of a multimodal message, or Content as a plain string
+func (m Message) MarshalJSON() ([]byte, error) {
+	if len(m.Parts) == 0 {
+		type plain Message
+		return json.Marshal(plain(m))
+	}
+	return json.Marshal(struct {
+		Role    string        `json:"role"`
+		Content []ContentPart `json:"content"`
+	}{m.Role, m.Parts})
 }
 
 // ChatCompletionResponse represents a response from the chat completions API
@@ -45,3 +93,10 @@ type Error struct {
 	Type    string `json:"type"`
 	Code    string `json:"code"`
 }
+
+// ModelList is the response of the models endpoint
+type ModelList struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
@@ -138,3 +138,96 @@ func (c *Client) GetAirDaily(locationID string) ([]AirDaily, error) {
 		zap.Duration("duration", time.Since(start)))
 	return airResp.Daily, nil
 }
+
+// GetAirStation retrieves the latest pollutant readings of an air quality monitoring station, using
+// the station ID listed in the current air quality response
+func (c *Client) GetAirStation(stationID string) (*AirStationResponse, error) {
+	logger.Debug("QWeather.GetAirStation called", zap.String("station_id", stationID))
+	start := time.Now()
+
+	requestURL := fmt.Sprintf("%s/airquality/v1/station/%s", c.baseURL, url.PathEscape(stationID))
+	maskedURL := logger.MaskURL(requestURL)
+
+	logger.Debug("Sending HTTP request",
+		zap.String("url", maskedURL),
+		zap.String("method", "GET"))
+
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", maskedURL),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get air station: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	logger.Debug("HTTP response received",
+		zap.Int("status_code", resp.StatusCode),
+		zap.Duration("duration", time.Since(start)))
+
+	var stationResp AirStationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stationResp); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to decode air station response: %w", err)
+	}
+	if len(stationResp.Pollutants) == 0 {
+		logger.Warn("Air station data not available",
+			zap.String("station_id", stationID))
+		return nil, fmt.Errorf("air station data not available: %s", stationID)
+	}
+
+	logger.Debug("Air station retrieved",
+		zap.String("station_id", stationID),
+		zap.Int("pollutant_count", len(stationResp.Pollutants)),
+		zap.Duration("duration", time.Since(start)))
+	return &stationResp, nil
+}
+
+// GetAirQualityHourly retrieves the hourly air quality forecast for the coming 24 hours using v1 API
+func (c *Client) GetAirQualityHourly(lat, lon string) ([]AirHourly, error) {
+	logger.Debug("QWeather.GetAirQualityHourly called", zap.String("lat", lat), zap.String("lon", lon))
+	start := time.Now()
+
+	requestURL := fmt.Sprintf("%s/airquality/v1/hourly/%s/%s", c.baseURL, lat, lon)
+	maskedURL := logger.MaskURL(requestURL)
+
+	logger.Debug("Sending HTTP request",
+		zap.String("url", maskedURL),
+		zap.String("method", "GET"))
+
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", maskedURL),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get air quality hourly forecast: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	logger.Debug("HTTP response received",
+		zap.Int("status_code", resp.StatusCode),
+		zap.Duration("duration", time.Since(start)))
+
+	var hourlyResp AirHourlyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&hourlyResp); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to decode air quality hourly forecast response: %w", err)
+	}
+	if len(hourlyResp.Hours) == 0 {
+		logger.Warn("Air quality hourly forecast not available",
+			zap.String("lat", lat),
+			zap.String("lon", lon))
+		return nil, fmt.Errorf("air quality hourly forecast not available")
+	}
+
+	logger.Debug("Air quality hourly forecast retrieved",
+		zap.String("lat", lat),
+		zap.String("lon", lon),
+		zap.Int("hours", len(hourlyResp.Hours)),
+		zap.Duration("duration", time.Since(start)))
+	return hourlyResp.Hours, nil
+}
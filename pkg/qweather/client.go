@@ -26,6 +26,7 @@ type Client struct {
 	projectID  string             // Project ID (for jwt mode)
 	baseURL    string
 	client     *http.Client
+	recorder   ResponseRecorder // Receives raw response bodies (nil = disabled)
 }
 
 // NewClient creates a new QWeather API client with API Key authentication
@@ -126,6 +127,11 @@ func (c *Client) generateJWT() (string, error) {
 	return jwt, nil
 }
 
+// SetTimeout bounds each API request, including reading the response body (0 = no timeout)
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.client.Timeout = timeout
+}
+
 // doRequest sends HTTP request with proper authentication
 func (c *Client) doRequest(requestURL string) (*http.Response, error) {
 	// For api_key mode, append key to URL
@@ -151,7 +157,12 @@ func (c *Client) doRequest(requestURL string) (*http.Response, error) {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	return c.client.Do(req)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	c.record(req, resp)
+	return resp, nil
 }
 
 // GetLocationID retrieves the location ID for a city name
@@ -374,13 +385,38 @@ func (c *Client) GetLifeIndices(locationID string) ([]LifeIndex, error) {
 
 // GetDailyForecast retrieves daily weather forecast for a location
 func (c *Client) GetDailyForecast(locationID string) (*DailyForecast, error) {
-	logger.Debug("QWeather.GetDailyForecast called", zap.String("location_id", locationID))
+	days, err := c.GetDailyForecasts(locationID)
+	if err != nil {
+		return nil, err
+	}
+	return &days[0], nil
+}
+
+// GetDailyForecasts retrieves the 3-day forecast for a location, starting today
+func (c *Client) GetDailyForecasts(locationID string) ([]DailyForecast, error) {
+	return c.getDailyForecasts(locationID, "3d")
+}
+
+// GetDailyForecasts7d retrieves the 7-day forecast for a location, starting today
+func (c *Client) GetDailyForecasts7d(locationID string) ([]DailyForecast, error) {
+	return c.getDailyForecasts(locationID, "7d")
+}
+
+// GetDailyForecasts15d retrieves the 15-day forecast for a location, starting today. The 15-day
+// forecast requires a paid QWeather plan.
+func (c *Client) GetDailyForecasts15d(locationID string) ([]DailyForecast, error) {
+	return c.getDailyForecasts(locationID, "15d")
+}
+
+// getDailyForecasts retrieves the daily forecast of a span ("3d", "7d" or "15d") for a location
+func (c *Client) getDailyForecasts(locationID, span string) ([]DailyForecast, error) {
+	logger.Debug("QWeather.GetDailyForecasts called", zap.String("location_id", locationID), zap.String("span", span))
 	start := time.Now()
 
 	params := url.Values{}
 	params.Add("location", locationID)
 
-	requestURL := fmt.Sprintf("%s/v7/weather/3d?%s", c.baseURL, params.Encode())
+	requestURL := fmt.Sprintf("%s/v7/weather/%s?%s", c.baseURL, span, params.Encode())
 	maskedURL := logger.MaskURL(requestURL)
 
 	logger.Debug("Sending HTTP request",
@@ -420,10 +456,67 @@ func (c *Client) GetDailyForecast(locationID string) (*DailyForecast, error) {
 
 	logger.Debug("Daily forecast retrieved",
 		zap.String("location_id", locationID),
+		zap.Int("days", len(forecastResp.Daily)),
 		zap.String("tempMax", forecastResp.Daily[0].TempMax),
 		zap.String("tempMin", forecastResp.Daily[0].TempMin),
 		zap.Duration("duration", time.Since(start)))
-	return &forecastResp.Daily[0], nil
+	return forecastResp.Daily, nil
+}
+
+// GetHourlyForecast retrieves the 24-hour forecast for a location
+func (c *Client) GetHourlyForecast(locationID string) ([]HourlyForecast, error) {
+	return c.getHourlyForecast(locationID, "24h")
+}
+
+// GetHourlyForecast72h retrieves the 72-hour forecast for a location
+func (c *Client) GetHourlyForecast72h(locationID string) ([]HourlyForecast, error) {
+	return c.getHourlyForecast(locationID, "72h")
+}
+
+// getHourlyForecast retrieves the hourly forecast of a span ("24h" or "72h") for a location
+func (c *Client) getHourlyForecast(locationID, span string) ([]HourlyForecast, error) {
+	logger.Debug("QWeather.GetHourlyForecast called", zap.String("location_id", locationID), zap.String("span", span))
+	start := time.Now()
+
+	params := url.Values{}
+	params.Add("location", locationID)
+
+	requestURL := fmt.Sprintf("%s/v7/weather/%s?%s", c.baseURL, span, params.Encode())
+	maskedURL := logger.MaskURL(requestURL)
+
+	logger.Debug("Sending HTTP request",
+		zap.String("url", maskedURL),
+		zap.String("method", "GET"))
+
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", maskedURL),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get hourly forecast: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var forecastResp HourlyForecastResponse
+	if err := json.NewDecoder(resp.Body).Decode(&forecastResp); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to decode hourly forecast response: %w", err)
+	}
+
+	if forecastResp.Code != "200" {
+		logger.Warn("Hourly forecast API error",
+			zap.String("location_id", locationID),
+			zap.String("api_code", forecastResp.Code))
+		return nil, fmt.Errorf("hourly forecast API returned code: %s", forecastResp.Code)
+	}
+
+	logger.Debug("Hourly forecast retrieved",
+		zap.String("location_id", locationID),
+		zap.Int("hours", len(forecastResp.Hourly)),
+		zap.Duration("duration", time.Since(start)))
+	return forecastResp.Hourly, nil
 }
 
 // GetAirQuality retrieves current air quality for a location
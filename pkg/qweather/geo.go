@@ -0,0 +1,109 @@
+package qweather
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// SearchCities retrieves up to number cities matching a keyword, ranked by relevance.
+// The keyword may be a partial name or pinyin (e.g. "beij").
+func (c *Client) SearchCities(keyword string, number int) ([]GeoLocation, error) {
+	logger.Debug("QWeather.SearchCities called", zap.String("keyword", keyword), zap.Int("number", number))
+	start := time.Now()
+
+	params := url.Values{}
+	params.Add("location", keyword)
+	params.Add("number", strconv.Itoa(number))
+
+	requestURL := fmt.Sprintf("%s/geo/v2/city/lookup?%s", c.baseURL, params.Encode())
+	maskedURL := logger.MaskURL(requestURL)
+
+	logger.Debug("Sending HTTP request",
+		zap.String("url", maskedURL),
+		zap.String("method", "GET"))
+
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", maskedURL),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to search cities: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var geoResp GeoLocationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&geoResp); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to decode city search response: %w", err)
+	}
+
+	// 404 means no match, which is an empty result rather than an error
+	if geoResp.Code == "404" {
+		return nil, nil
+	}
+	if geoResp.Code != "200" {
+		logger.Warn("City search API error",
+			zap.String("keyword", keyword),
+			zap.String("api_code", geoResp.Code))
+		return nil, fmt.Errorf("city search API returned code: %s", geoResp.Code)
+	}
+
+	logger.Debug("Cities found",
+		zap.String("keyword", keyword),
+		zap.Int("count", len(geoResp.Location)),
+		zap.Duration("duration", time.Since(start)))
+	return geoResp.Location, nil
+}
+
+// GetTopCities retrieves up to number popular cities in China
+func (c *Client) GetTopCities(number int) ([]GeoLocation, error) {
+	logger.Debug("QWeather.GetTopCities called", zap.Int("number", number))
+	start := time.Now()
+
+	params := url.Values{}
+	params.Add("range", "cn")
+	params.Add("number", strconv.Itoa(number))
+
+	requestURL := fmt.Sprintf("%s/geo/v2/city/top?%s", c.baseURL, params.Encode())
+	maskedURL := logger.MaskURL(requestURL)
+
+	logger.Debug("Sending HTTP request",
+		zap.String("url", maskedURL),
+		zap.String("method", "GET"))
+
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", maskedURL),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get top cities: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var topResp TopCityResponse
+	if err := json.NewDecoder(resp.Body).Decode(&topResp); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to decode top cities response: %w", err)
+	}
+
+	if topResp.Code != "200" {
+		logger.Warn("Top cities API error",
+			zap.String("api_code", topResp.Code))
+		return nil, fmt.Errorf("top cities API returned code: %s", topResp.Code)
+	}
+
+	logger.Debug("Top cities retrieved",
+		zap.Int("count", len(topResp.TopCityList)),
+		zap.Duration("duration", time.Since(start)))
+	return topResp.TopCityList, nil
+}
@@ -0,0 +1,124 @@
+package qweather
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// coordinates formats a latitude and longitude as the "lon,lat" location parameter
+func coordinates(lat, lon string) string {
+	return lon + "," + lat
+}
+
+// GetLocationByCoordinates retrieves the city (or district) containing the given coordinates
+func (c *Client) GetLocationByCoordinates(lat, lon string) (*GeoLocation, error) {
+	return c.GetLocation(coordinates(lat, lon))
+}
+
+// GetGridWeatherNow retrieves current grid weather (about 3-5km resolution) for the given coordinates.
+// Grid weather has no feels-like temperature; FeelsLike is left empty.
+func (c *Client) GetGridWeatherNow(lat, lon string) (*CurrentWeather, error) {
+	logger.Debug("QWeather.GetGridWeatherNow called", zap.String("lat", lat), zap.String("lon", lon))
+	start := time.Now()
+
+	params := url.Values{}
+	params.Add("location", coordinates(lat, lon))
+
+	requestURL := fmt.Sprintf("%s/v7/grid-weather/now?%s", c.baseURL, params.Encode())
+	maskedURL := logger.MaskURL(requestURL)
+
+	logger.Debug("Sending HTTP request",
+		zap.String("url", maskedURL),
+		zap.String("method", "GET"))
+
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", maskedURL),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get grid weather: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	logger.Debug("HTTP response received",
+		zap.Int("status_code", resp.StatusCode),
+		zap.Duration("duration", time.Since(start)))
+
+	var weatherResp WeatherResponse
+	if err := json.NewDecoder(resp.Body).Decode(&weatherResp); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to decode grid weather response: %w", err)
+	}
+
+	if weatherResp.Code != "200" {
+		logger.Warn("Grid weather API error",
+			zap.String("lat", lat),
+			zap.String("lon", lon),
+			zap.String("api_code", weatherResp.Code))
+		return nil, fmt.Errorf("grid weather API returned code: %s", weatherResp.Code)
+	}
+
+	logger.Debug("Grid weather retrieved",
+		zap.String("lat", lat),
+		zap.String("lon", lon),
+		zap.String("temp", weatherResp.Now.Temp),
+		zap.String("text", weatherResp.Now.Text),
+		zap.Duration("duration", time.Since(start)))
+	return &weatherResp.Now, nil
+}
+
+// GetGridHourlyForecast retrieves the 24-hour grid forecast for the given coordinates.
+// Grid forecasts have no probability of precipitation; Pop is left empty.
+func (c *Client) GetGridHourlyForecast(lat, lon string) ([]HourlyForecast, error) {
+	logger.Debug("QWeather.GetGridHourlyForecast called", zap.String("lat", lat), zap.String("lon", lon))
+	start := time.Now()
+
+	params := url.Values{}
+	params.Add("location", coordinates(lat, lon))
+
+	requestURL := fmt.Sprintf("%s/v7/grid-weather/24h?%s", c.baseURL, params.Encode())
+	maskedURL := logger.MaskURL(requestURL)
+
+	logger.Debug("Sending HTTP request",
+		zap.String("url", maskedURL),
+		zap.String("method", "GET"))
+
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", maskedURL),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get grid hourly forecast: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var forecastResp HourlyForecastResponse
+	if err := json.NewDecoder(resp.Body).Decode(&forecastResp); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to decode grid hourly forecast response: %w", err)
+	}
+
+	if forecastResp.Code != "200" {
+		logger.Warn("Grid hourly forecast API error",
+			zap.String("lat", lat),
+			zap.String("lon", lon),
+			zap.String("api_code", forecastResp.Code))
+		return nil, fmt.Errorf("grid hourly forecast API returned code: %s", forecastResp.Code)
+	}
+
+	logger.Debug("Grid hourly forecast retrieved",
+		zap.String("lat", lat),
+		zap.String("lon", lon),
+		zap.Int("hours", len(forecastResp.Hourly)),
+		zap.Duration("duration", time.Since(start)))
+	return forecastResp.Hourly, nil
+}
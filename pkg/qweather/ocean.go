@@ -0,0 +1,58 @@
+package qweather
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// GetTide retrieves the tide table of a tide station (a CSTA POI ID) for a date in yyyyMMdd format
+func (c *Client) GetTide(stationID, date string) (*TideResponse, error) {
+	logger.Debug("QWeather.GetTide called", zap.String("station_id", stationID), zap.String("date", date))
+	start := time.Now()
+
+	params := url.Values{}
+	params.Add("location", stationID)
+	params.Add("date", date)
+
+	requestURL := fmt.Sprintf("%s/v7/ocean/tide?%s", c.baseURL, params.Encode())
+	maskedURL := logger.MaskURL(requestURL)
+
+	logger.Debug("Sending HTTP request",
+		zap.String("url", maskedURL),
+		zap.String("method", "GET"))
+
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", maskedURL),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get tide: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var tideResp TideResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tideResp); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to decode tide response: %w", err)
+	}
+
+	if tideResp.Code != "200" {
+		logger.Warn("Tide API error",
+			zap.String("station_id", stationID),
+			zap.String("api_code", tideResp.Code))
+		return nil, fmt.Errorf("tide API returned code: %s", tideResp.Code)
+	}
+
+	logger.Debug("Tide retrieved",
+		zap.String("station_id", stationID),
+		zap.Int("extremes", len(tideResp.TideTable)),
+		zap.Duration("duration", time.Since(start)))
+	return &tideResp, nil
+}
@@ -0,0 +1,74 @@
+package qweather
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// POI types supported by the GeoAPI POI lookup
+const (
+	POITypeScenic  = "scenic" // Scenic spots
+	POITypeTide    = "CSTA"   // Tide stations
+	POITypeCurrent = "TSTA"   // Ocean current stations
+	POITypeAir     = "AQI"    // Air quality monitoring stations
+)
+
+// GetPOI retrieves the best matching point of interest (e.g. a scenic spot) for a keyword.
+// The returned location ID can be used with the weather APIs like a city ID.
+func (c *Client) GetPOI(keyword, poiType string) (*GeoLocation, error) {
+	logger.Debug("QWeather.GetPOI called", zap.String("keyword", keyword), zap.String("type", poiType))
+	start := time.Now()
+
+	params := url.Values{}
+	params.Add("location", keyword)
+	params.Add("type", poiType)
+	params.Add("number", "1")
+
+	requestURL := fmt.Sprintf("%s/geo/v2/poi/lookup?%s", c.baseURL, params.Encode())
+	maskedURL := logger.MaskURL(requestURL)
+
+	logger.Debug("Sending HTTP request",
+		zap.String("url", maskedURL),
+		zap.String("method", "GET"))
+
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", maskedURL),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get POI: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	logger.Debug("HTTP response received",
+		zap.Int("status_code", resp.StatusCode),
+		zap.Duration("duration", time.Since(start)))
+
+	var poiResp POIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&poiResp); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to decode POI response: %w", err)
+	}
+
+	if poiResp.Code != "200" || len(poiResp.POI) == 0 {
+		logger.Warn("POI not found",
+			zap.String("keyword", keyword),
+			zap.String("type", poiType),
+			zap.String("api_code", poiResp.Code))
+		return nil, fmt.Errorf("POI not found for keyword: %s", keyword)
+	}
+
+	logger.Debug("POI retrieved",
+		zap.String("keyword", keyword),
+		zap.String("name", poiResp.POI[0].Name),
+		zap.String("location_id", poiResp.POI[0].ID),
+		zap.Duration("duration", time.Since(start)))
+	return &poiResp.POI[0], nil
+}
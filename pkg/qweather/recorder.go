@@ -0,0 +1,71 @@
+package qweather
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ResponseRecorder receives the raw body of each successful API response
+type ResponseRecorder interface {
+	// RecordResponse is called with the request key (see RequestKey), its location and the body
+	RecordResponse(request, location string, body []byte)
+}
+
+// SetRecorder makes the client pass every successful response body to recorder
+func (c *Client) SetRecorder(recorder ResponseRecorder) {
+	c.recorder = recorder
+}
+
+// NewClientWithTransport creates an unauthenticated client that sends requests through transport,
+// e.g. to replay recorded responses
+func NewClientWithTransport(baseURL string, transport http.RoundTripper) *Client {
+	return &Client{
+		authMode: "none",
+		baseURL:  baseURL,
+		client:   &http.Client{Transport: transport},
+	}
+}
+
+// RequestKey identifies a request independently of host and credentials: the path plus the sorted
+// query without the API key, e.g. "/v7/weather/now?location=101010100". The location is the
+// "location" parameter, or the coordinates in the path of the air quality API.
+func RequestKey(u *url.URL) (request, location string) {
+	query := u.Query()
+	query.Del("key")
+	request = u.Path
+	if encoded := query.Encode(); encoded != "" {
+		request += "?" + encoded
+	}
+
+	location = query.Get("location")
+	if location == "" {
+		// e.g. /airquality/v1/current/{lat}/{lon}
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) >= 2 {
+			location = parts[len(parts)-1] + "," + parts[len(parts)-2]
+		}
+	}
+	return request, location
+}
+
+// record passes a successful response body to the recorder and restores it for decoding
+func (c *Client) record(req *http.Request, resp *http.Response) {
+	if c.recorder == nil || resp.StatusCode != http.StatusOK {
+		return
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		logger.Warn("Failed to read response for recording", zap.String("url", logger.MaskURL(req.URL.String())), zap.Error(err))
+		return
+	}
+	request, location := RequestKey(req.URL)
+	c.recorder.RecordResponse(request, location, body)
+}
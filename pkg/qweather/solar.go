@@ -0,0 +1,59 @@
+package qweather
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// GetSolarRadiation retrieves the 24-hour solar radiation forecast for the given coordinates
+func (c *Client) GetSolarRadiation(lat, lon string) ([]SolarRadiation, error) {
+	logger.Debug("QWeather.GetSolarRadiation called", zap.String("lat", lat), zap.String("lon", lon))
+	start := time.Now()
+
+	params := url.Values{}
+	params.Add("location", coordinates(lat, lon))
+
+	requestURL := fmt.Sprintf("%s/v7/solar-radiation/24h?%s", c.baseURL, params.Encode())
+	maskedURL := logger.MaskURL(requestURL)
+
+	logger.Debug("Sending HTTP request",
+		zap.String("url", maskedURL),
+		zap.String("method", "GET"))
+
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", maskedURL),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get solar radiation: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var radiationResp SolarRadiationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&radiationResp); err != nil {
+		logger.Error("Failed to decode response",
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to decode solar radiation response: %w", err)
+	}
+
+	if radiationResp.Code != "200" {
+		logger.Warn("Solar radiation API error",
+			zap.String("lat", lat),
+			zap.String("lon", lon),
+			zap.String("api_code", radiationResp.Code))
+		return nil, fmt.Errorf("solar radiation API returned code: %s", radiationResp.Code)
+	}
+
+	logger.Debug("Solar radiation retrieved",
+		zap.String("lat", lat),
+		zap.String("lon", lon),
+		zap.Int("hours", len(radiationResp.Radiation)),
+		zap.Duration("duration", time.Since(start)))
+	return radiationResp.Radiation, nil
+}
@@ -11,6 +11,7 @@ type CurrentWeather struct {
 	Temp      string `json:"temp"`      // Temperature in Celsius
 	FeelsLike string `json:"feelsLike"` // Feels like temperature
 	Text      string `json:"text"`      // Weather description
+	Icon      string `json:"icon"`      // Weather icon code (e.g., 100 = sunny)
 	Humidity  string `json:"humidity"`  // Humidity percentage
 	Wind360   string `json:"wind360"`   // Wind direction in degrees
 	WindDir   string `json:"windDir"`   // Wind direction description
@@ -70,6 +71,30 @@ type DailyForecast struct {
 	UvIndex        string `json:"uvIndex"`        // UV index
 }
 
+// HourlyForecastResponse represents the response from QWeather API for hourly forecast
+type HourlyForecastResponse struct {
+	Code   string           `json:"code"`
+	Hourly []HourlyForecast `json:"hourly"`
+}
+
+// HourlyForecast represents the forecast for one hour
+type HourlyForecast struct {
+	FxTime    string `json:"fxTime"`    // Forecast time (e.g., 2025-06-01T13:00+08:00)
+	Temp      string `json:"temp"`      // Temperature in Celsius
+	Icon      string `json:"icon"`      // Weather icon code
+	Text      string `json:"text"`      // Weather description
+	Wind360   string `json:"wind360"`   // Wind direction in degrees
+	WindDir   string `json:"windDir"`   // Wind direction description
+	WindScale string `json:"windScale"` // Wind scale
+	WindSpeed string `json:"windSpeed"` // Wind speed km/h
+	Humidity  string `json:"humidity"`  // Relative humidity
+	Pop       string `json:"pop"`       // Probability of precipitation percentage (may be empty)
+	Precip    string `json:"precip"`    // Precipitation amount mm
+	Pressure  string `json:"pressure"`  // Atmospheric pressure hPa
+	Cloud     string `json:"cloud"`     // Cloud cover percentage
+	Dew       string `json:"dew"`       // Dew point temperature
+}
+
 // GeoLocationResponse represents the response from QWeather GeoAPI
 type GeoLocationResponse struct {
 	Code     string        `json:"code"`
@@ -90,6 +115,54 @@ type GeoLocation struct {
 	Type      string `json:"type"`      // Location type
 }
 
+// TopCityResponse represents the response from QWeather GeoAPI top cities
+type TopCityResponse struct {
+	Code        string        `json:"code"`
+	TopCityList []GeoLocation `json:"topCityList"`
+}
+
+// TideResponse represents the response from QWeather API for tides
+type TideResponse struct {
+	Code       string        `json:"code"`
+	TideTable  []TideExtreme `json:"tideTable"`  // High and low tides of the day
+	TideHourly []TideHeight  `json:"tideHourly"` // Hourly tide heights
+}
+
+// TideExtreme represents a high or low tide
+type TideExtreme struct {
+	FxTime string `json:"fxTime"` // Time of the tide (e.g., 2025-06-01T05:12+08:00)
+	Height string `json:"height"` // Tide height in meters
+	Type   string `json:"type"`   // "H" for high tide, "L" for low tide
+}
+
+// TideHeight represents the tide height at a given hour
+type TideHeight struct {
+	FxTime string `json:"fxTime"` // Forecast time
+	Height string `json:"height"` // Tide height in meters
+}
+
+// SolarRadiationResponse represents the response from QWeather API for solar radiation
+type SolarRadiationResponse struct {
+	Code      string           `json:"code"`
+	Radiation []SolarRadiation `json:"radiation"`
+}
+
+// SolarRadiation represents the solar radiation forecast for one hour
+type SolarRadiation struct {
+	FxTime    string `json:"fxTime"`    // Forecast time
+	Net       string `json:"net"`       // Net radiation W/m²
+	Diffuse   string `json:"diffuse"`   // Diffuse horizontal irradiance W/m²
+	Direct    string `json:"direct"`    // Direct normal irradiance W/m²
+	Elevation string `json:"elevation"` // Solar elevation angle in degrees
+	Azimuth   string `json:"azimuth"`   // Solar azimuth angle in degrees
+}
+
+// POIResponse represents the response from QWeather GeoAPI POI lookup
+type POIResponse struct {
+	Code string        `json:"code"`
+	POI  []GeoLocation `json:"poi"`
+}
+
 // AirNowResponse represents the response from QWeather API for current air quality
 type AirNowResponse struct {
 	Code string `json:"code"`
@@ -192,6 +265,25 @@ type Station struct {
 	Name string `json:"name"`
 }
 
+// AirHourlyResponse represents the response from QWeather Air Quality API v1 for the hourly forecast
+type AirHourlyResponse struct {
+	Metadata Metadata    `json:"metadata"`
+	Hours    []AirHourly `json:"hours"`
+}
+
+// AirHourly represents the air quality forecast of one hour
+type AirHourly struct {
+	ForecastTime string            `json:"forecastTime"` // Forecast time, e.g. "2025-06-01T08:00Z"
+	Indexes      []AirQualityIndex `json:"indexes"`
+	Pollutants   []Pollutant       `json:"pollutants"`
+}
+
+// AirStationResponse represents the response from QWeather Air Quality API v1 for a monitoring station
+type AirStationResponse struct {
+	Metadata   Metadata    `json:"metadata"`
+	Pollutants []Pollutant `json:"pollutants"`
+}
+
 // AirDailyResponse represents the response from QWeather API for daily air quality forecast
 type AirDailyResponse struct {
 	Code  string     `json:"code"`
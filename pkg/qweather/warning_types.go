@@ -0,0 +1,112 @@
+package qweather
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// WarningType is an entry of QWeather's warning type catalog
+type WarningType struct {
+	Code string `json:"code"` // Warning type code, as in Warning.Type (e.g. "1003")
+	Name string `json:"name"` // Chinese name (e.g. "暴雨")
+}
+
+// WarningTypesResponse represents the response of the warning type catalog API
+type WarningTypesResponse struct {
+	Code  string        `json:"code"`
+	Types []WarningType `json:"types"`
+}
+
+// BuiltinWarningTypes is the catalog of warning types issued in mainland China, in code order,
+// used until the catalog has been fetched from the API
+var BuiltinWarningTypes = []WarningType{
+	{"1001", "台风"},
+	{"1002", "龙卷风"},
+	{"1003", "暴雨"},
+	{"1004", "暴雪"},
+	{"1005", "寒潮"},
+	{"1006", "大风"},
+	{"1007", "沙尘暴"},
+	{"1008", "低温冻害"},
+	{"1009", "高温"},
+	{"1010", "热浪"},
+	{"1011", "干热风"},
+	{"1012", "下击暴流"},
+	{"1013", "雪崩"},
+	{"1014", "雷电"},
+	{"1015", "冰雹"},
+	{"1016", "霜冻"},
+	{"1017", "大雾"},
+	{"1018", "低空风切变"},
+	{"1019", "霾"},
+	{"1020", "雷雨大风"},
+	{"1021", "道路结冰"},
+	{"1022", "干旱"},
+	{"1023", "海上大风"},
+	{"1024", "高温中暑"},
+	{"1025", "森林火险"},
+	{"1026", "草原火险"},
+	{"1027", "冰冻"},
+	{"1028", "空间天气"},
+	{"1029", "重污染"},
+	{"1030", "低温雨雪冰冻"},
+	{"1031", "强对流"},
+	{"1032", "臭氧"},
+	{"1033", "大雪"},
+	{"1034", "寒冷"},
+	{"1035", "连阴雨"},
+	{"1036", "渍涝风险"},
+	{"1037", "地质灾害气象风险"},
+	{"1038", "强降雨"},
+	{"1039", "强降温"},
+	{"1040", "雪灾"},
+	{"1041", "森林（草原）火险"},
+	{"1042", "雷暴"},
+	{"1043", "严寒"},
+	{"1044", "沙尘"},
+	{"1045", "海上雷雨大风"},
+	{"1046", "海上雷电"},
+	{"1047", "海上台风"},
+	{"1048", "低温"},
+}
+
+// GetWarningTypes retrieves the catalog of warning types
+func (c *Client) GetWarningTypes() ([]WarningType, error) {
+	logger.Debug("QWeather.GetWarningTypes called")
+	start := time.Now()
+
+	params := url.Values{}
+	params.Add("lang", "zh")
+	params.Add("key", c.apiKey)
+
+	requestURL := fmt.Sprintf("%s/v7/warning/types?%s", c.baseURL, params.Encode())
+	resp, err := c.doRequest(requestURL)
+	if err != nil {
+		logger.Error("HTTP request failed",
+			zap.String("url", logger.MaskURL(requestURL)),
+			zap.Error(err),
+			zap.Duration("duration", time.Since(start)))
+		return nil, fmt.Errorf("failed to get warning types: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var typesResp WarningTypesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&typesResp); err != nil {
+		logger.Error("Failed to decode response", zap.Error(err))
+		return nil, fmt.Errorf("failed to decode warning types response: %w", err)
+	}
+	if typesResp.Code != "200" {
+		logger.Warn("Warning types not available", zap.String("api_code", typesResp.Code))
+		return nil, fmt.Errorf("warning types not available: code %s", typesResp.Code)
+	}
+
+	logger.Debug("Warning types retrieved",
+		zap.Int("count", len(typesResp.Types)),
+		zap.Duration("duration", time.Since(start)))
+	return typesResp.Types, nil
+}
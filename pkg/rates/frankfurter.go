@@ -0,0 +1,122 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultFrankfurterURL is the public Frankfurter API, which serves the ECB reference rates
+const DefaultFrankfurterURL = "https://api.frankfurter.app"
+
+// frankfurterLookback is how far back the rate series is requested, to find the previous
+// business day across weekends and holidays
+const frankfurterLookback = 7 * 24 * time.Hour
+
+// Frankfurter fetches currency rates from a Frankfurter API (ECB reference rates, published on
+// business days around 16:00 CET)
+type Frankfurter struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewFrankfurter creates a Frankfurter provider ("" = the public API)
+func NewFrankfurter(baseURL string, timeout time.Duration) *Frankfurter {
+	if baseURL == "" {
+		baseURL = DefaultFrankfurterURL
+	}
+	return &Frankfurter{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// frankfurterSeries is the response of the time series endpoint
+type frankfurterSeries struct {
+	Base  string                        `json:"base"`
+	Rates map[string]map[string]float64 `json:"rates"` // Date -> currency -> rate
+}
+
+// Quotes implements Provider with one time series request per base currency
+func (f *Frankfurter) Quotes(ctx context.Context, pairs []Pair) ([]Quote, error) {
+	byBase := make(map[string][]string)
+	var bases []string
+	for _, p := range pairs {
+		if _, ok := byBase[p.Base]; !ok {
+			bases = append(bases, p.Base)
+		}
+		byBase[p.Base] = append(byBase[p.Base], p.Quote)
+	}
+
+	quotes := make(map[Pair]Quote, len(pairs))
+	for _, base := range bases {
+		series, err := f.series(ctx, base, byBase[base])
+		if err != nil {
+			return nil, err
+		}
+		for _, quote := range byBase[base] {
+			q, ok := latestTwo(series, quote)
+			if !ok {
+				return nil, fmt.Errorf("no rate for %s/%s", base, quote)
+			}
+			q.Pair = Pair{Base: base, Quote: quote}
+			quotes[q.Pair] = q
+		}
+	}
+
+	result := make([]Quote, 0, len(pairs))
+	for _, p := range pairs {
+		result = append(result, quotes[p])
+	}
+	return result, nil
+}
+
+// series requests the rates of a base currency over the lookback window
+func (f *Frankfurter) series(ctx context.Context, base string, symbols []string) (*frankfurterSeries, error) {
+	start := time.Now().Add(-frankfurterLookback).Format("2006-01-02")
+	url := fmt.Sprintf("%s/%s..?from=%s&to=%s", f.baseURL, start, base, strings.Join(symbols, ","))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rates API returned status %d for %s", resp.StatusCode, base)
+	}
+	var series frankfurterSeries
+	if err := json.NewDecoder(resp.Body).Decode(&series); err != nil {
+		return nil, fmt.Errorf("failed to decode rates: %w", err)
+	}
+	return &series, nil
+}
+
+// latestTwo returns the latest rate of a currency in a series, with the one before as Previous
+func latestTwo(series *frankfurterSeries, currency string) (Quote, bool) {
+	dates := make([]string, 0, len(series.Rates))
+	for date, rates := range series.Rates {
+		if _, ok := rates[currency]; ok {
+			dates = append(dates, date)
+		}
+	}
+	if len(dates) == 0 {
+		return Quote{}, false
+	}
+	sort.Strings(dates)
+
+	latest := dates[len(dates)-1]
+	q := Quote{Rate: series.Rates[latest][currency], Date: latest}
+	if len(dates) > 1 {
+		q.Previous = series.Rates[dates[len(dates)-2]][currency]
+	}
+	return q, true
+}
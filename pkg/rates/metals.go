@@ -0,0 +1,133 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultGoldAPIURL is the public gold-api.com API
+const DefaultGoldAPIURL = "https://api.gold-api.com"
+
+// gramsPerTroyOunce converts metal prices per troy ounce to per gram
+const gramsPerTroyOunce = 31.1034768
+
+// GoldAPI fetches spot precious metal prices in USD per troy ounce from a gold-api.com compatible
+// API. Prices in other currencies are converted with the fiat provider and quoted per gram, as
+// is usual for domestic markets (e.g. XAU/CNY in yuan per gram).
+type GoldAPI struct {
+	baseURL    string
+	httpClient *http.Client
+	fiat       Provider // Converts USD prices (nil = only XXX/USD pairs)
+}
+
+// NewGoldAPI creates a GoldAPI provider ("" = the public API)
+func NewGoldAPI(baseURL string, timeout time.Duration, fiat Provider) *GoldAPI {
+	if baseURL == "" {
+		baseURL = DefaultGoldAPIURL
+	}
+	return &GoldAPI{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+		fiat:       fiat,
+	}
+}
+
+// goldAPIPrice is the response of the price endpoint
+type goldAPIPrice struct {
+	Price     float64 `json:"price"`
+	UpdatedAt string  `json:"updatedAt"`
+}
+
+// Quotes implements Provider
+func (g *GoldAPI) Quotes(ctx context.Context, pairs []Pair) ([]Quote, error) {
+	// Fetch the USD conversion rates of all quote currencies at once
+	conversions := make(map[string]float64)
+	var fiatPairs []Pair
+	for _, p := range pairs {
+		usd := Pair{Base: "USD", Quote: p.Quote}
+		if p.Quote != "USD" && !containsPair(fiatPairs, usd) {
+			fiatPairs = append(fiatPairs, usd)
+		}
+	}
+	if len(fiatPairs) > 0 {
+		if g.fiat == nil {
+			return nil, fmt.Errorf("metal prices are only available in USD")
+		}
+		fiatQuotes, err := g.fiat.Quotes(ctx, fiatPairs)
+		if err != nil {
+			return nil, err
+		}
+		for _, q := range fiatQuotes {
+			conversions[q.Pair.Quote] = q.Rate
+		}
+	}
+
+	prices := make(map[string]goldAPIPrice)
+	quotes := make([]Quote, 0, len(pairs))
+	for _, p := range pairs {
+		price, ok := prices[p.Base]
+		if !ok {
+			var err error
+			if price, err = g.price(ctx, p.Base); err != nil {
+				return nil, err
+			}
+			prices[p.Base] = price
+		}
+
+		q := Quote{Pair: p, Rate: price.Price, Unit: "oz", Date: dateOf(price.UpdatedAt)}
+		if p.Quote != "USD" {
+			q.Rate = price.Price * conversions[p.Quote] / gramsPerTroyOunce
+			q.Unit = "g"
+		}
+		quotes = append(quotes, q)
+	}
+	return quotes, nil
+}
+
+// price requests the USD spot price per troy ounce of a metal
+func (g *GoldAPI) price(ctx context.Context, metal string) (goldAPIPrice, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"/price/"+metal, nil)
+	if err != nil {
+		return goldAPIPrice{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return goldAPIPrice{}, fmt.Errorf("failed to fetch %s price: %w", metal, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return goldAPIPrice{}, fmt.Errorf("metal price API returned status %d for %s", resp.StatusCode, metal)
+	}
+	var price goldAPIPrice
+	if err := json.NewDecoder(resp.Body).Decode(&price); err != nil {
+		return goldAPIPrice{}, fmt.Errorf("failed to decode %s price: %w", metal, err)
+	}
+	if price.Price <= 0 {
+		return goldAPIPrice{}, fmt.Errorf("no %s price", metal)
+	}
+	return price, nil
+}
+
+// containsPair reports whether pairs contains p
+func containsPair(pairs []Pair, p Pair) bool {
+	for _, candidate := range pairs {
+		if candidate == p {
+			return true
+		}
+	}
+	return false
+}
+
+// dateOf returns the date part of an RFC 3339 timestamp, or today's date when it is missing
+func dateOf(timestamp string) string {
+	if t, err := time.Parse(time.RFC3339, timestamp); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return time.Now().Format("2006-01-02")
+}
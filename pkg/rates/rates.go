@@ -0,0 +1,114 @@
+// Package rates fetches currency exchange rates and precious metal prices through pluggable
+// providers
+package rates
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// pairPattern matches a currency or metal pair such as USD/CNY or XAU/CNY
+var pairPattern = regexp.MustCompile(`^([A-Z]{3})/([A-Z]{3})$`)
+
+// Pair is a price of one unit of Base expressed in Quote
+type Pair struct {
+	Base  string // e.g. USD, XAU
+	Quote string // e.g. CNY
+}
+
+// ParsePair parses a pair like "USD/CNY" (case-insensitive)
+func ParsePair(s string) (Pair, error) {
+	m := pairPattern.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil {
+		return Pair{}, fmt.Errorf("invalid pair %q, expected e.g. USD/CNY", s)
+	}
+	if m[1] == m[2] {
+		return Pair{}, fmt.Errorf("invalid pair %q, currencies must differ", s)
+	}
+	return Pair{Base: m[1], Quote: m[2]}, nil
+}
+
+// String returns the pair as BASE/QUOTE
+func (p Pair) String() string {
+	return p.Base + "/" + p.Quote
+}
+
+// IsMetal reports whether the pair prices a precious metal
+func (p Pair) IsMetal() bool {
+	return IsMetal(p.Base)
+}
+
+// Quote is the latest price of a pair
+type Quote struct {
+	Pair     Pair
+	Rate     float64
+	Previous float64 // Previous day's rate, 0 when unknown
+	Unit     string  // Unit of the base for metals ("oz" or "g"), "" for currencies
+	Date     string  // Date of the rate (YYYY-MM-DD)
+}
+
+// Change returns the change from the previous day's rate and whether it is known
+func (q Quote) Change() (float64, bool) {
+	if q.Previous == 0 {
+		return 0, false
+	}
+	return q.Rate - q.Previous, true
+}
+
+// Provider fetches quotes. Pairs a provider does not support are reported as an error.
+type Provider interface {
+	Quotes(ctx context.Context, pairs []Pair) ([]Quote, error)
+}
+
+// metals are the ISO 4217 codes of the supported precious metals
+var metals = map[string]bool{"XAU": true, "XAG": true, "XPT": true, "XPD": true}
+
+// IsMetal reports whether a code is a precious metal (XAU gold, XAG silver, XPT platinum, XPD palladium)
+func IsMetal(code string) bool {
+	return metals[code]
+}
+
+// Router sends currency pairs to one provider and metal pairs to another
+type Router struct {
+	fiat   Provider
+	metals Provider // nil = metal pairs are unsupported
+}
+
+// NewRouter creates a provider that routes pairs by kind
+func NewRouter(fiat, metals Provider) *Router {
+	return &Router{fiat: fiat, metals: metals}
+}
+
+// Quotes implements Provider
+func (r *Router) Quotes(ctx context.Context, pairs []Pair) ([]Quote, error) {
+	var fiatPairs, metalPairs []Pair
+	for _, p := range pairs {
+		if p.IsMetal() {
+			metalPairs = append(metalPairs, p)
+		} else {
+			fiatPairs = append(fiatPairs, p)
+		}
+	}
+
+	var quotes []Quote
+	if len(fiatPairs) > 0 {
+		q, err := r.fiat.Quotes(ctx, fiatPairs)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, q...)
+	}
+	if len(metalPairs) > 0 {
+		if r.metals == nil {
+			return nil, fmt.Errorf("metal prices are not supported")
+		}
+		q, err := r.metals.Quotes(ctx, metalPairs)
+		if err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, q...)
+	}
+	return quotes, nil
+}
@@ -0,0 +1,58 @@
+// Package safehttp builds HTTP clients for user-supplied URLs (webhooks, calendars, feeds) that
+// can't be pointed at the bot's own host or its internal network
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// maxRedirects limits the redirects followed by clients that follow them
+const maxRedirects = 5
+
+// NewClient creates a client with the given request timeout whose connections to loopback,
+// private and link-local addresses fail unless allowPrivate is set. Proxies from the
+// environment are ignored, since they would connect on the client's behalf. Redirects to http(s)
+// URLs are followed (up to maxRedirects) and go through the same check.
+func NewClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		dialer.Control = DenyPrivateTargets
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return errors.New("redirect to a non-http(s) URL")
+			}
+			return nil
+		},
+	}
+}
+
+// DenyPrivateTargets blocks connections to loopback, private and link-local addresses.
+// It runs after DNS resolution, so it also covers hostnames that resolve to internal IPs.
+func DenyPrivateTargets(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+		return fmt.Errorf("target %s is not allowed", host)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package safehttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDenyPrivateTargets(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:443", true},
+		{"[2606:2800:220:1::]:443", true},
+		{"127.0.0.1:8080", false},
+		{"[::1]:80", false},
+		{"10.0.0.5:80", false},
+		{"192.168.1.1:80", false},
+		{"172.16.0.1:80", false},
+		{"169.254.169.254:80", false},
+		{"0.0.0.0:80", false},
+		{"[fe80::1]:80", false},
+	}
+	for _, tt := range tests {
+		err := DenyPrivateTargets("tcp", tt.address, nil)
+		if (err == nil) != tt.allowed {
+			t.Errorf("DenyPrivateTargets(%s) = %v, want allowed %v", tt.address, err, tt.allowed)
+		}
+	}
+}
+
+func TestClientBlocksLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second, false).Get(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("request to loopback: err = %v, want not allowed", err)
+	}
+	resp, err := NewClient(time.Second, true).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with private targets allowed: %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,239 @@
+package timeparse
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// period is a time-of-day qualifier such as 早上 or pm
+type period int
+
+const (
+	periodNone    period = iota
+	periodAM             // 12 means midnight (12am, 凌晨12点)
+	periodMorning        // 早上/上午: hour is kept as is
+	periodNoon           // 中午: 1-2 are afternoon hours
+	periodPM             // 下午/pm: hours before 12 are shifted by 12
+	periodNight          // 晚上/夜里: like pm, but 12 means midnight
+)
+
+// Presets map single-word inputs to default reminder times
+var Presets = map[string]string{
+	"早":  "07:00",
+	"早上": "07:00",
+	"早晨": "07:00",
+	"午":  "12:00",
+	"中午": "12:00",
+	"晚":  "20:00",
+	"晚上": "20:00",
+}
+
+// chinesePeriods are Chinese qualifiers, longest first so that prefixes match greedily
+var chinesePeriods = []struct {
+	word   string
+	period period
+}{
+	{"凌晨", periodAM},
+	{"清晨", periodMorning},
+	{"早晨", periodMorning},
+	{"早上", periodMorning},
+	{"上午", periodMorning},
+	{"中午", periodNoon},
+	{"午后", periodPM},
+	{"下午", periodPM},
+	{"傍晚", periodPM},
+	{"晚上", periodNight},
+	{"夜里", periodNight},
+	{"夜间", periodNight},
+	{"今晚", periodNight},
+	{"早", periodMorning},
+	{"晚", periodNight},
+}
+
+var (
+	clockPattern   = regexp.MustCompile(`^(\d{1,2})(?:[:.](\d{2}))?$`)
+	compactPattern = regexp.MustCompile(`^(\d{2})(\d{2})$`)
+	chinesePattern = regexp.MustCompile(`^([0-9零〇一二两三四五六七八九十]{1,3})[点點时時](?:(半|整|钟|鐘|一刻|三刻)|([0-9零〇一二两三四五六七八九十]{1,3})分?)?$`)
+)
+
+// normalizer converts full-width characters and English period markers to a canonical form
+var normalizer = strings.NewReplacer(
+	"：", ":", "．", ".", "。", ".",
+	"０", "0", "１", "1", "２", "2", "３", "3", "４", "4",
+	"５", "5", "６", "6", "７", "7", "８", "8", "９", "9",
+	"a.m.", "am", "p.m.", "pm",
+)
+
+// Parse converts a loosely formatted reminder time into HH:MM.
+// Accepted forms include "08:00", "8:30", "08.00", "0800", "8点", "8点半", "早上8点",
+// "晚上八点一刻", "8am", "8:30pm" and the presets 早/午/晚.
+func Parse(input string) (string, error) {
+	s := strings.ToLower(strings.Join(strings.Fields(input), ""))
+	s = normalizer.Replace(s)
+	if s == "" {
+		return "", fmt.Errorf("empty time")
+	}
+
+	if preset, ok := Presets[s]; ok {
+		return preset, nil
+	}
+
+	p := periodNone
+	switch {
+	case strings.HasSuffix(s, "am"):
+		p, s = periodAM, strings.TrimSuffix(s, "am")
+	case strings.HasSuffix(s, "pm"):
+		p, s = periodPM, strings.TrimSuffix(s, "pm")
+	default:
+		for _, cp := range chinesePeriods {
+			if strings.HasPrefix(s, cp.word) {
+				p, s = cp.period, strings.TrimPrefix(s, cp.word)
+				break
+			}
+		}
+	}
+
+	hour, minute, err := parseClock(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid time %q: %w", input, err)
+	}
+
+	hour, err = applyPeriod(hour, p)
+	if err != nil {
+		return "", fmt.Errorf("invalid time %q: %w", input, err)
+	}
+	if hour > 23 || minute > 59 {
+		return "", fmt.Errorf("invalid time %q: out of range", input)
+	}
+	return fmt.Sprintf("%02d:%02d", hour, minute), nil
+}
+
+// parseClock extracts hour and minute from the time without a period qualifier
+func parseClock(s string) (int, int, error) {
+	if m := clockPattern.FindStringSubmatch(s); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute := 0
+		if m[2] != "" {
+			minute, _ = strconv.Atoi(m[2])
+		}
+		return hour, minute, nil
+	}
+
+	if m := compactPattern.FindStringSubmatch(s); m != nil {
+		hour, _ := strconv.Atoi(m[1])
+		minute, _ := strconv.Atoi(m[2])
+		return hour, minute, nil
+	}
+
+	if m := chinesePattern.FindStringSubmatch(s); m != nil {
+		hour, ok := parseNumber(m[1])
+		if !ok {
+			return 0, 0, fmt.Errorf("unrecognized hour")
+		}
+		minute := 0
+		switch m[2] {
+		case "半":
+			minute = 30
+		case "一刻":
+			minute = 15
+		case "三刻":
+			minute = 45
+		}
+		if m[3] != "" {
+			if minute, ok = parseNumber(m[3]); !ok {
+				return 0, 0, fmt.Errorf("unrecognized minute")
+			}
+		}
+		return hour, minute, nil
+	}
+
+	return 0, 0, fmt.Errorf("unrecognized format")
+}
+
+// applyPeriod converts a 12-hour clock hour to 24-hour form according to the qualifier
+func applyPeriod(hour int, p period) (int, error) {
+	if p == periodNone {
+		return hour, nil
+	}
+	if hour > 12 {
+		// "下午15点" and similar already use the 24-hour clock
+		if p == periodPM || p == periodNight || p == periodNoon {
+			return hour, nil
+		}
+		return 0, fmt.Errorf("hour %d conflicts with a morning qualifier", hour)
+	}
+
+	switch p {
+	case periodAM:
+		if hour == 12 {
+			return 0, nil
+		}
+	case periodNoon:
+		if hour >= 1 && hour <= 2 {
+			return hour + 12, nil
+		}
+	case periodPM:
+		if hour < 12 {
+			return hour + 12, nil
+		}
+	case periodNight:
+		if hour == 12 {
+			return 0, nil
+		}
+		if hour >= 5 {
+			return hour + 12, nil
+		}
+		// 夜里1点 and similar stay in the small hours
+	}
+	return hour, nil
+}
+
+// parseNumber parses Arabic digits or a Chinese numeral from 0 to 99
+func parseNumber(s string) (int, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, true
+	}
+
+	digits := map[rune]int{
+		'零': 0, '〇': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4,
+		'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+	}
+	runes := []rune(s)
+	if len(runes) == 2 && (runes[0] == '零' || runes[0] == '〇') {
+		// 八点零五
+		runes = runes[1:]
+	}
+	tenIndex := -1
+	for i, r := range runes {
+		if r == '十' {
+			if tenIndex >= 0 {
+				return 0, false
+			}
+			tenIndex = i
+		} else if _, ok := digits[r]; !ok {
+			return 0, false
+		}
+	}
+
+	if tenIndex < 0 {
+		if len(runes) != 1 {
+			return 0, false
+		}
+		return digits[runes[0]], true
+	}
+	if tenIndex > 1 || len(runes)-tenIndex > 2 {
+		return 0, false
+	}
+
+	tens := 1
+	if tenIndex == 1 {
+		tens = digits[runes[0]]
+	}
+	units := 0
+	if tenIndex == len(runes)-2 {
+		units = digits[runes[tenIndex+1]]
+	}
+	return tens*10 + units, true
+}
@@ -0,0 +1,89 @@
+package trivia
+
+// facts lists the trivia by topic. Keep each fact a single checked sentence of at most about
+// 60 characters; the AI may rephrase but never adds facts.
+var facts = map[string][]string{
+	TopicThunder: {
+		"闪电通道的温度可达约 3 万摄氏度，是太阳表面温度的好几倍。",
+		"看到闪电后开始数秒，再除以 3，大约就是雷暴离你的公里数——雷声每 3 秒约传播 1 公里。",
+		"雷雨天躲在汽车里相对安全，靠的是金属车身把电流导向地面，而不是轮胎绝缘。",
+	},
+	TopicRain: {
+		"雨滴并不是泪珠形：小雨滴接近球形，大雨滴下落时底部被空气压扁，更像一个小汉堡。",
+		"雨后泥土的清香叫 petrichor，其中的土腥味主要来自土壤中放线菌产生的土臭素。",
+		"气象上 24 小时降水量不足 10 毫米算小雨，达到 50 毫米及以上就算暴雨。",
+	},
+	TopicSnow: {
+		"雪花基本都是六角形的，这源于水分子结冰时的六方晶格结构。",
+		"新雪蓬松、空隙多，能吸收声音，所以下雪天总显得格外安静。",
+		"冰晶本身近乎透明，是无数晶面对光的散射让积雪显得洁白。",
+	},
+	TopicFog: {
+		"雾和云本质上是一回事，贴着地面的云就是雾。",
+		"气象上水平能见度低于 1 公里才叫雾，低于 50 米就属于特强浓雾。",
+	},
+	TopicHaze: {
+		"雾是小水滴，多呈乳白色；霾是悬浮的干颗粒物，常带黄灰色，湿度高时二者常混在一起。",
+		"PM2.5 指直径不超过 2.5 微米的颗粒物，大约只有头发丝粗细的二三十分之一。",
+	},
+	TopicDust: {
+		"沙尘暴能把细小沙尘送上数千米高空，随高空气流飘到上千公里之外，甚至越过大洋。",
+		"扬沙和沙尘暴主要看能见度区分：扬沙时能见度在 1 至 10 公里，沙尘暴则低于 1 公里。",
+	},
+	TopicWind: {
+		"风向指风吹来的方向：北风是从北边吹来、往南吹的风。",
+		"蒲福风级由英国海军军官蒲福在 1805 年根据海面状况制定，后来才扩展到陆地。",
+		"6 级风的风速约为每秒 10.8 到 13.8 米，这时撑伞已经比较吃力。",
+	},
+	TopicSunny: {
+		"阳光从太阳出发到达地球大约需要 8 分 20 秒。",
+		"晴天的天空是蓝色的，因为空气分子对波长短的蓝光散射更强，这叫瑞利散射。",
+	},
+	TopicCloudy: {
+		"一朵普通的积云可重达数百吨，它能飘在空中是因为水滴极小、分散在大片上升气流里。",
+		"多云天仍有大部分紫外线能穿透薄云到达地面，户外活动别忘了防晒。",
+	},
+	TopicOvercast: {
+		"天空八成以上被云遮住才称得上「阴」，云量不足两成才算「晴」。",
+		"阴天也有相当比例的紫外线能穿透云层，防晒不能只看有没有太阳。",
+	},
+	TopicHot: {
+		"中国气象上把日最高气温达到或超过 35℃ 的日子称为高温日。",
+		"人体主要靠汗水蒸发散热，湿度大时汗液难以蒸发，所以闷热天比同温度的干热天更难受。",
+	},
+	TopicCold: {
+		"同样的气温下风越大，带走体表热量越快，人就觉得越冷，这就是风寒效应。",
+		"「三九」常是一年中最冷的时段：冬至后白天虽渐长，地面散失的热量仍多于得到的热量。",
+	},
+	TopicGeneral: {
+		"降水概率 70% 指出现降水的可能性为七成，并不是七成的时间或地区都在下雨。",
+		"气象站的温度计放在离地约 1.5 米、通风良好的百叶箱里，所以和晒着太阳的马路上感觉不同。",
+		"世界上第一颗气象卫星 TIROS-1 于 1960 年发射，中国的风云一号于 1988 年升空。",
+	},
+
+	// Solar terms
+	"立春": {"立春是二十四节气之首，民间有「咬春」的习俗，这天要吃春饼、春卷。"},
+	"雨水": {"雨水节气意味着降水开始增多，降水形式也逐渐由雪转为雨。"},
+	"惊蛰": {"惊蛰古称「启蛰」，汉代为避汉景帝刘启的名讳才改为「惊蛰」。"},
+	"春分": {"春分这天太阳直射赤道，全球各地昼夜几乎等长。"},
+	"清明": {"清明既是节气也是传统节日，古时的寒食节就在清明前一两天，后来两者逐渐融合。"},
+	"谷雨": {"谷雨是春季最后一个节气，取「雨生百谷」之意，民间有谷雨喝茶、赏牡丹的习俗。"},
+	"立夏": {"立夏有「称人」的旧俗：午饭后称一称体重，据说能保佑整个夏天不「疰夏」。"},
+	"小满": {"节气里有小暑大暑、小雪大雪、小寒大寒，唯独有「小满」没有「大满」，寓意满而不盈。"},
+	"芒种": {"芒种的「芒」指麦类等有芒的作物，「种」指播种，这时既要收麦又要种稻，是一年中最忙的农时之一。"},
+	"夏至": {"夏至这天北半球白昼最长，北京的白天约有 15 个小时。"},
+	"小暑": {"俗话说「小暑大暑，上蒸下煮」，小暑过后不久就要入伏了。"},
+	"大暑": {"大暑常与中伏相遇，是一年中最热的时段之一。"},
+	"立秋": {"立秋不等于入秋：气象上要连续 5 天的日平均气温低于 22℃ 才算真正入秋。"},
+	"处暑": {"处暑的「处」是终止的意思，表示炎热的暑天即将过去。"},
+	"白露": {"白露时昼夜温差变大，夜里水汽在草木上凝成露珠，古人以露色白而得名。"},
+	"秋分": {"秋分和春分一样昼夜平分；自 2018 年起，秋分这天也是中国农民丰收节。"},
+	"寒露": {"寒露比白露更冷，露水更凉，快要凝结成霜了。"},
+	"霜降": {"霜不是从天上降下来的，而是近地面的水汽遇冷在物体表面直接凝华成的冰晶。"},
+	"立冬": {"北方有「立冬补冬」的说法，不少地方这天要吃饺子。"},
+	"小雪": {"小雪节气不一定下雪，它表示气温下降、开始出现降雪的时节，此时雪量还不大。"},
+	"大雪": {"大雪节气表示降雪的可能和雪量增大，并不代表这天一定会下大雪。"},
+	"冬至": {"冬至这天北半球白昼最短，此后白天逐渐变长，古人称「冬至一阳生」。"},
+	"小寒": {"从气象统计看，北方的小寒往往比大寒还冷，民间有「小寒胜大寒」的说法。"},
+	"大寒": {"大寒是二十四节气的最后一个，过了大寒，又将迎来新一轮的立春。"},
+}
@@ -0,0 +1,101 @@
+// Package trivia holds a curated collection of weather and solar term trivia ("今日冷知识") and
+// picks the one matching a day's weather
+package trivia
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/qweather"
+)
+
+// Weather topics of the trivia; solar terms use their Chinese names (e.g. "立春") as topics
+const (
+	TopicThunder  = "thunder"
+	TopicRain     = "rain"
+	TopicSnow     = "snow"
+	TopicFog      = "fog"
+	TopicHaze     = "haze"
+	TopicDust     = "dust"
+	TopicWind     = "wind"
+	TopicSunny    = "sunny"
+	TopicCloudy   = "cloudy"
+	TopicOvercast = "overcast"
+	TopicHot      = "hot"
+	TopicCold     = "cold"
+	TopicGeneral  = "general"
+)
+
+// Thresholds of the wind and temperature topics
+const (
+	windyScale  = 6  // Wind scale from which the wind is the topic
+	hotTempMax  = 35 // Maximum temperature (°C) of a high temperature day
+	coldTempMin = -5 // Minimum temperature (°C) from which the cold is the topic
+)
+
+// Topic returns the topic of a day: its solar term when there is one, otherwise the most notable
+// weather phenomenon of the current weather or today's forecast (either may be nil)
+func Topic(solarTerm string, weather *qweather.CurrentWeather, today *qweather.DailyForecast) string {
+	if _, ok := facts[solarTerm]; ok {
+		return solarTerm
+	}
+
+	var text string
+	if weather != nil {
+		text = weather.Text
+	}
+	if today != nil {
+		text += " " + today.TextDay
+	}
+	switch {
+	case strings.Contains(text, "雷"), strings.Contains(text, "冰雹"):
+		return TopicThunder
+	case strings.Contains(text, "雪"):
+		return TopicSnow
+	case strings.Contains(text, "雨"):
+		return TopicRain
+	case strings.Contains(text, "沙"), strings.Contains(text, "尘"):
+		return TopicDust
+	case strings.Contains(text, "霾"):
+		return TopicHaze
+	case strings.Contains(text, "雾"):
+		return TopicFog
+	}
+
+	if weather != nil {
+		if scale, err := strconv.Atoi(weather.WindScale); err == nil && scale >= windyScale {
+			return TopicWind
+		}
+	}
+	if today != nil {
+		if high, err := strconv.Atoi(today.TempMax); err == nil && high >= hotTempMax {
+			return TopicHot
+		}
+		if low, err := strconv.Atoi(today.TempMin); err == nil && low <= coldTempMin {
+			return TopicCold
+		}
+	}
+	switch {
+	case strings.Contains(text, "晴"):
+		return TopicSunny
+	case strings.Contains(text, "多云"):
+		return TopicCloudy
+	case strings.Contains(text, "阴"):
+		return TopicOvercast
+	}
+	return TopicGeneral
+}
+
+// Pick returns the trivia of a topic for a date (YYYY-MM-DD). The choice depends only on the
+// topic and date, so everyone with the same topic sees the same trivia that day. Unknown topics
+// fall back to the general trivia.
+func Pick(topic, date string) string {
+	list, ok := facts[topic]
+	if !ok {
+		list = facts[TopicGeneral]
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(topic + "|" + date))
+	return list[h.Sum32()%uint32(len(list))]
+}
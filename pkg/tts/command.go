@@ -0,0 +1,69 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CommandSynthesizer synthesizes speech by running a local program such as edge-tts.
+// The {text} placeholder in the arguments is replaced with the text and {output} with the
+// path of a temporary file the program must write the audio to.
+type CommandSynthesizer struct {
+	args []string
+	mime string
+}
+
+// NewCommandSynthesizer creates a new CommandSynthesizer.
+// mime is the type of the audio the program writes (e.g., audio/mpeg for edge-tts).
+func NewCommandSynthesizer(args []string, mime string) (*CommandSynthesizer, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("tts command is empty")
+	}
+	hasOutput := false
+	for _, arg := range args {
+		if strings.Contains(arg, "{output}") {
+			hasOutput = true
+		}
+	}
+	if !hasOutput {
+		return nil, fmt.Errorf("tts command must contain an {output} placeholder")
+	}
+	return &CommandSynthesizer{args: args, mime: mime}, nil
+}
+
+// Synthesize runs the command and returns the audio it wrote
+func (s *CommandSynthesizer) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	dir, err := os.MkdirTemp("", "tts-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	output := filepath.Join(dir, "speech")
+
+	args := make([]string, len(s.args))
+	for i, arg := range s.args {
+		arg = strings.ReplaceAll(arg, "{output}", output)
+		args[i] = strings.ReplaceAll(arg, "{text}", text)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("tts command failed: %w: %s", err, truncate(strings.TrimSpace(stderr.String()), 200))
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tts output: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("tts command wrote empty audio")
+	}
+	return &Audio{Data: data, MIME: s.mime}, nil
+}
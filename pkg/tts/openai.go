@@ -0,0 +1,108 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cuichanghe/daily-reminder-bot/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxOpenAIInput is the maximum input length accepted by the OpenAI speech API
+const maxOpenAIInput = 4096
+
+// speechRequest is the request body of the OpenAI /audio/speech endpoint
+type speechRequest struct {
+	Model          string `json:"model"`
+	Input          string `json:"input"`
+	Voice          string `json:"voice"`
+	ResponseFormat string `json:"response_format"`
+}
+
+// OpenAISynthesizer synthesizes speech via an OpenAI-compatible /audio/speech endpoint
+// (OpenAI, or self-hosted Edge-TTS proxies exposing the same API)
+type OpenAISynthesizer struct {
+	apiKey  string
+	baseURL string
+	model   string
+	voice   string
+	client  *http.Client
+}
+
+// NewOpenAISynthesizer creates a new OpenAISynthesizer
+func NewOpenAISynthesizer(apiKey, baseURL, model, voice string, timeout time.Duration) *OpenAISynthesizer {
+	return &OpenAISynthesizer{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		model:   model,
+		voice:   voice,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Synthesize converts text to Ogg/Opus speech, which Telegram plays as a voice message
+func (s *OpenAISynthesizer) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	if runes := []rune(text); len(runes) > maxOpenAIInput {
+		text = string(runes[:maxOpenAIInput])
+	}
+
+	jsonData, err := json.Marshal(speechRequest{
+		Model:          s.model,
+		Input:          text,
+		Voice:          s.voice,
+		ResponseFormat: "opus",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/audio/speech", s.baseURL)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.apiKey))
+	}
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("speech API returned status %d: %s", resp.StatusCode, truncate(string(body), 200))
+	}
+	if len(body) == 0 {
+		return nil, fmt.Errorf("speech API returned empty audio")
+	}
+
+	logger.Debug("Speech synthesized",
+		zap.String("model", s.model),
+		zap.String("voice", s.voice),
+		zap.Int("input_chars", len([]rune(text))),
+		zap.Int("audio_bytes", len(body)),
+		zap.Duration("duration", time.Since(start)))
+
+	return &Audio{Data: body, MIME: "audio/ogg"}, nil
+}
+
+// truncate shortens s to at most n bytes for error messages
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
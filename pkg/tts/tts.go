@@ -0,0 +1,16 @@
+package tts
+
+import (
+	"context"
+)
+
+// Audio is synthesized speech
+type Audio struct {
+	Data []byte
+	MIME string // e.g., audio/ogg, audio/mpeg
+}
+
+// Synthesizer converts text to speech
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text string) (*Audio, error)
+}